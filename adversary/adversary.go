@@ -0,0 +1,103 @@
+// Package adversary turns honest replicas into Byzantine ones. A Byzantine replica runs the real replica code
+// of its algorithm, so it takes part in every phase and keeps the state an honest replica would, but whatever
+// it sends first passes through one or more misbehavior strategies: it may lie about the values it votes for,
+// tell different peers different things, hold its messages back, or coordinate its lies with other faulty
+// replicas. Because the strategies work on wire envelopes rather than on a particular algorithm's types, one
+// set of attacks applies to every replica that implements node.Replica — a PBFT replica, a Proof of Stake
+// validator or a delegate — instead of each package hand-coding its own faulty node.
+//
+// The wrapped replica is itself a node.Replica, so it runs inside a sim.Simulator or behind a node.Runner
+// like any other, and tests can put f, or more than f, of them in a cluster to see where an algorithm's
+// guarantees end.
+package adversary
+
+import (
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/wire"
+)
+
+// Output is what an honest replica sent in response to one input.
+type Output struct {
+    Node      int32            // Replica that produced the envelopes.
+    Tick      bool             // The input was a tick, rather than a delivered envelope or a proposal.
+    Envelopes []*wire.Envelope // Envelopes the honest replica sent, possibly none. They must not be modified.
+}
+
+// Strategy decides what a Byzantine replica sends in place of what its honest code produced.
+type Strategy interface {
+    // Rewrite returns the envelopes to send instead of out.Envelopes. It may drop, alter, add or hold back
+    // envelopes; it sees every input, including ticks on which the replica sends nothing, so held envelopes
+    // can be released later. Envelopes it alters must be copies.
+    Rewrite(out Output) []*wire.Envelope
+}
+
+// StrategyFunc adapts a function to the Strategy interface.
+type StrategyFunc func(out Output) []*wire.Envelope
+
+// Rewrite calls f(out).
+func (f StrategyFunc) Rewrite(out Output) []*wire.Envelope {
+    return f(out)
+}
+
+// Replica is a Byzantine replica: an honest replica whose outgoing envelopes are rewritten by strategies.
+type Replica struct {
+    node.Replica            // Honest replica that does the actual work.
+    strategies   []Strategy // Applied in order, each to what the previous one returned.
+}
+
+// Wrap makes replica Byzantine. Its envelopes pass through the strategies in the order given; with none,
+// the replica stays honest.
+func Wrap(replica node.Replica, strategies ...Strategy) *Replica {
+    return &Replica{Replica: replica, strategies: strategies}
+}
+
+// Unwrap returns the honest replica inside, e.g. to inspect its state.
+func (r *Replica) Unwrap() node.Replica {
+    return r.Replica
+}
+
+// Step processes an incoming envelope honestly and returns what the strategies make of the replica's response.
+func (r *Replica) Step(env *wire.Envelope) []*wire.Envelope {
+    return r.rewrite(false, r.Replica.Step(env))
+}
+
+// Tick advances the honest replica's clock and returns what the strategies make of its response.
+func (r *Replica) Tick() []*wire.Envelope {
+    return r.rewrite(true, r.Replica.Tick())
+}
+
+// Propose submits data to the honest replica and returns what the strategies make of its response.
+func (r *Replica) Propose(data string) ([]*wire.Envelope, error) {
+    out, err := r.Replica.Propose(data)
+    return r.rewrite(false, out), err
+}
+
+// Leader returns the leader the honest replica follows, or -1 if its algorithm has none.
+func (r *Replica) Leader() int32 {
+    if l, ok := r.Replica.(node.Leaderful); ok {
+        return l.Leader()
+    }
+    return -1
+}
+
+// rewrite passes the envelopes produced on one input through every strategy.
+func (r *Replica) rewrite(tick bool, envelopes []*wire.Envelope) []*wire.Envelope {
+    for _, s := range r.strategies {
+        envelopes = s.Rewrite(Output{Node: r.ID(), Tick: tick, Envelopes: envelopes})
+    }
+    return envelopes
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Honest Core, Faulty Mouth**: A Byzantine replica keeps running its algorithm's real code and only its
+//    messages are tampered with. The attacks are then realistic — a lying PBFT backup still knows the right
+//    digest, sequence and view, and lies about exactly the field that matters — and no algorithm needs a
+//    "faulty" flag of its own.
+//
+// 2. **Envelopes as the Attack Surface**: Strategies see wire envelopes, the one thing every replica shares.
+//    An attack written once applies to every algorithm whose messages it understands, and passes through
+//    those it does not.
+//
+// 3. **Composable Strategies**: Wrap takes a list, so attacks combine: a replica can equivocate and then delay
+//    the result, or a collusion member can also hold back what it tells the replicas outside the collusion.
@@ -0,0 +1,53 @@
+package adversary
+
+import (
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/wire"
+)
+
+// Censor is a replica that silently drops selected transactions. Unlike the strategies, which rewrite what a
+// replica sends, a censor filters what it is given: a transaction it censors is accepted from the client without
+// an error, as if it would be ordered, and never reaches the replica, nor does the same transaction forwarded by
+// a peer, such as a PBFT Request. Everything else, including its votes on blocks that others proposed with the
+// transaction in them, stays honest, so the censor is only noticed by the transactions that never commit.
+type Censor struct {
+    node.Replica                        // Honest replica that orders every other transaction.
+    censored     func(data string) bool // Reports whether a transaction is censored.
+    Dropped      int                    // Transactions dropped so far, counting every copy.
+}
+
+// NewCensor makes replica drop the transactions for which censored returns true.
+func NewCensor(replica node.Replica, censored func(data string) bool) *Censor {
+    return &Censor{Replica: replica, censored: censored}
+}
+
+// Unwrap returns the honest replica inside, e.g. to inspect its state.
+func (c *Censor) Unwrap() node.Replica {
+    return c.Replica
+}
+
+// Propose drops a censored transaction, reporting success, and submits any other to the replica.
+func (c *Censor) Propose(data string) ([]*wire.Envelope, error) {
+    if c.censored(data) {
+        c.Dropped++
+        return nil, nil
+    }
+    return c.Replica.Propose(data)
+}
+
+// Step drops a censored transaction forwarded by a peer and passes every other envelope to the replica.
+func (c *Censor) Step(env *wire.Envelope) []*wire.Envelope {
+    if req := env.GetRequest(); req != nil && c.censored(req.GetData()) {
+        c.Dropped++
+        return nil
+    }
+    return c.Replica.Step(env)
+}
+
+// Leader returns the leader the replica follows, or -1 if its algorithm has none.
+func (c *Censor) Leader() int32 {
+    if l, ok := c.Replica.(node.Leaderful); ok {
+        return l.Leader()
+    }
+    return -1
+}
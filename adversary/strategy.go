@@ -0,0 +1,162 @@
+package adversary
+
+import (
+    "slices"
+    "sync"
+
+    "google.golang.org/protobuf/proto"
+
+    "consensus-algorithms-edu/algorithms/dpos"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/wire"
+)
+
+// Hashes holds the block hash functions of the algorithms whose blocks a Forger can forge convincingly, by
+// name. A forged block hashed with its algorithm's function passes the receivers' integrity checks, so the
+// lie is caught only by voting, not by a hash mismatch.
+var Hashes = map[string]func(b *wire.Block) string{
+    "pbft": func(b *wire.Block) string { block := pbft.BlockFromWire(b); return block.CalculateHash().String() },
+    "pos":  func(b *wire.Block) string { block := pos.BlockFromWire(b); return block.CalculateHash().String() },
+    "dpos": func(b *wire.Block) string { block := dpos.BlockFromWire(b); return block.CalculateHash().String() },
+}
+
+// Forger makes up the conflicting values that lying replicas send. It remembers every block it forged, so
+// when replicas share a forger, a vote one of them forges for a block names the same forgery another one
+// proposed. A Forger is safe for concurrent use.
+type Forger struct {
+    // Hash recomputes the hash of a forged block. If nil, a forged block keeps the hash of the original and
+    // fails every integrity check.
+    Hash func(b *wire.Block) string
+
+    mu     sync.Mutex
+    forged map[string]string // Hash of each block forged so far, mapped to the hash of its forgery.
+}
+
+// NewForger creates a forger whose blocks are hashed with hash. Pass an entry of Hashes, or nil for crude
+// forgeries.
+func NewForger(hash func(b *wire.Block) string) *Forger {
+    return &Forger{Hash: hash, forged: make(map[string]string)}
+}
+
+// Block returns a forgery of b: the same block with different data, rehashed. Forging the same block twice
+// yields the same forgery.
+func (f *Forger) Block(b *wire.Block) *wire.Block {
+    forged := proto.Clone(b).(*wire.Block)
+    forged.Data = "forged: " + b.GetData()
+    forged.Certificate = nil
+    if f.Hash != nil {
+        forged.Hash = f.Hash(forged)
+    }
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.forged[b.GetHash()] = forged.GetHash()
+    return forged
+}
+
+// Digest returns the digest to vote for in place of digest: the hash of this forger's forgery of that block
+// if it forged one, or a digest that names no block at all otherwise.
+func (f *Forger) Digest(digest string) string {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if forged, ok := f.forged[digest]; ok {
+        return forged
+    }
+    return "forged:" + digest
+}
+
+// lie returns a copy of env with the value it carries replaced by a forgery, or env itself if it carries
+// nothing to lie about. Proposals carry a forged block; PBFT votes name the forged block, or a block that does
+// not exist.
+func lie(env *wire.Envelope, f *Forger) *wire.Envelope {
+    switch env.GetBody().(type) {
+    case *wire.Envelope_PrePrepare, *wire.Envelope_Prepare, *wire.Envelope_Commit, *wire.Envelope_BlockProposal:
+    default:
+        return env
+    }
+    forged := proto.Clone(env).(*wire.Envelope)
+    switch body := forged.GetBody().(type) {
+    case *wire.Envelope_PrePrepare:
+        body.PrePrepare.Block = f.Block(body.PrePrepare.GetBlock())
+        body.PrePrepare.Digest = body.PrePrepare.GetBlock().GetHash()
+    case *wire.Envelope_Prepare:
+        body.Prepare.Digest = f.Digest(body.Prepare.GetDigest())
+    case *wire.Envelope_Commit:
+        body.Commit.Digest = f.Digest(body.Commit.GetDigest())
+    case *wire.Envelope_BlockProposal:
+        body.BlockProposal.Block = f.Block(body.BlockProposal.GetBlock())
+    }
+    return forged
+}
+
+// Lie makes a replica lie to everyone: every proposal it sends carries a forged block and every vote names a
+// forged digest. Other messages pass unchanged.
+func Lie(f *Forger) Strategy {
+    return StrategyFunc(func(out Output) []*wire.Envelope {
+        lies := make([]*wire.Envelope, len(out.Envelopes))
+        for i, env := range out.Envelopes {
+            lies[i] = lie(env, f)
+        }
+        return lies
+    })
+}
+
+// Equivocate makes a replica tell two stories: the victims receive forgeries, as from Lie, while every other
+// replica receives the honest messages. A primary that equivocates proposes two different blocks for the same
+// sequence number, the attack PBFT's prepare phase exists to defeat.
+func Equivocate(f *Forger, victims ...int32) Strategy {
+    return StrategyFunc(func(out Output) []*wire.Envelope {
+        sent := make([]*wire.Envelope, len(out.Envelopes))
+        for i, env := range out.Envelopes {
+            sent[i] = env
+            if slices.Contains(victims, env.GetTo()) {
+                sent[i] = lie(env, f)
+            }
+        }
+        return sent
+    })
+}
+
+// Delay makes a replica hold back everything it sends for the given number of its own ticks, as a slow or
+// deliberately stalling node would. Envelopes are released in the order they were produced.
+func Delay(ticks int) Strategy {
+    type held struct {
+        envelopes []*wire.Envelope
+        release   int // Tick on which the envelopes are sent.
+    }
+    var queue []held
+    now := 0
+    return StrategyFunc(func(out Output) []*wire.Envelope {
+        if out.Tick {
+            now++
+        }
+        if len(out.Envelopes) > 0 {
+            queue = append(queue, held{envelopes: out.Envelopes, release: now + ticks})
+        }
+        var due []*wire.Envelope
+        for len(queue) > 0 && queue[0].release <= now {
+            due = append(due, queue[0].envelopes...)
+            queue = queue[1:]
+        }
+        return due
+    })
+}
+
+// Collusion is a group of Byzantine replicas that attack together. Its members share one Forger and one set
+// of victims, so the forged block one member proposes to the victims is the block the others vote for when
+// they talk to the victims, while everyone else sees a consistent honest run. With more than f members, the
+// forged votes alone can reach a PBFT quorum.
+type Collusion struct {
+    Forger  *Forger // Forgeries shared by the members.
+    Victims []int32 // Replicas the members lie to.
+}
+
+// NewCollusion creates a collusion against the victims, forging blocks with hash.
+func NewCollusion(hash func(b *wire.Block) string, victims ...int32) *Collusion {
+    return &Collusion{Forger: NewForger(hash), Victims: victims}
+}
+
+// Member returns the strategy of one member of the collusion.
+func (c *Collusion) Member() Strategy {
+    return Equivocate(c.Forger, c.Victims...)
+}
@@ -0,0 +1,187 @@
+package dpos
+
+import (
+    "log/slog"
+    "slices"
+    "time"
+
+    "consensus-algorithms-edu/blocktree"
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/cost"
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/logging"
+    "consensus-algorithms-edu/mempool"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/rotation"
+    "consensus-algorithms-edu/wire"
+)
+
+// DelegateConfig describes a single elected delegate and the network it belongs to.
+type DelegateConfig struct {
+    ID            int32            // Unique identifier of this delegate.
+    Peers         []int32          // Identifiers of every node blocks are exchanged with, including this one.
+    Elected       []int32          // Identifiers of the delegates that produce blocks, a subset of Peers; every peer if empty.
+    Rotation      rotation.Builder // Policy choosing the producer of each slot among the elected delegates; rotation.RoundRobin if nil.
+    SlotTicks     int              // Ticks per slot, i.e. between two scheduled blocks; defaults to 10.
+    Confirmations int              // Blocks that must follow a block before Committed reports it.
+    AntiEntropy   int              // Ticks between anti-entropy exchanges with a peer (see blocktree.Replica.Tick); none if 0.
+    Prune         int              // Blocks below the head whose data the replica keeps (see blocktree.ReplicaConfig.Prune); all of them if 0.
+    State         ledger.State     // State the data of the followed chain is applied to (see blocktree.Chain); blocks are not interpreted if nil.
+    Mempool       *mempool.Pool    // Holds proposed transfers, which blocks carry by fee (see blocktree.ReplicaConfig.Mempool); none if nil.
+    Clock         clock.Clock      // Source of block timestamps; the system clock is used if nil.
+    Logger        *slog.Logger     // Receives produced blocks and reorganizations, scoped to this delegate; silent if nil.
+}
+
+// Delegate is a message-driven Delegated Proof of Stake block producer.
+// The elected delegates take turns: slot s belongs to the delegate at position s mod n of the sorted delegate
+// list, unless DelegateConfig.Rotation chooses otherwise, and that delegate extends the head it follows and
+// announces the block. Delegates that cannot hear each
+// other skip the slots of the delegates on the other side, so each side builds its own branch, and after they
+// reconnect they follow the branch produced by more delegates, chosen by ForkChoice. The block tree and the
+// exchange of blocks are provided by the embedded blocktree.Replica.
+type Delegate struct {
+    *blocktree.Replica
+    delegates []int32         // Sorted delegate identifiers, among which the policy chooses producers.
+    producers map[string]bool // Names of every delegate elected so far, whose blocks are valid.
+    rotation  rotation.Builder
+    policy    rotation.Policy // Chooses the producer of each slot among the delegates; rebuilt at every election.
+    slotTicks int
+    ticks     int             // Ticks seen so far; the current slot is ticks / slotTicks.
+    slotStart time.Time       // When the current slot started, to tell whether its producer's block arrived.
+    clock     clock.Clock
+    logger    *slog.Logger
+}
+
+// ForkChoice returns the Delegated Proof of Stake fork-choice rule for the given delegates, named as they appear
+// as a block's producer, e.g. "node-2": follow the branch whose blocks since the fork were produced by more
+// distinct delegates, and the longer one on a tie. With round-robin scheduling, the side holding most delegates
+// also fills most slots, so it wins on both counts.
+func ForkChoice(delegates []string) blocktree.Rule {
+    return blocktree.HeaviestBranch(func(producer string) int {
+        if slices.Contains(delegates, producer) {
+            return 1
+        }
+        return 0
+    })
+}
+
+// GenesisBlock returns the genesis block shared by every delegate. It has no timestamp, so independently
+// started delegates agree on it.
+func GenesisBlock() Block {
+    block := Block{Index: 0, Data: "Genesis Block"}
+    block.Hash = block.CalculateHash()
+    return block
+}
+
+// NewDelegate creates a delegate whose chain holds only the genesis block.
+func NewDelegate(cfg DelegateConfig) *Delegate {
+    if cfg.SlotTicks <= 0 {
+        cfg.SlotTicks = 10
+    }
+    if len(cfg.Elected) == 0 {
+        cfg.Elected = cfg.Peers
+    }
+    if cfg.Rotation == nil {
+        cfg.Rotation = rotation.RoundRobin
+    }
+    d := &Delegate{
+        producers: make(map[string]bool),
+        rotation:  cfg.Rotation,
+        slotTicks: cfg.SlotTicks,
+        clock:     clock.Or(cfg.Clock),
+        logger:    logging.Scope(cfg.Logger, "dpos", cfg.ID),
+    }
+    d.Elect(cfg.Elected)
+    g := GenesisBlock()
+    d.Replica = blocktree.NewReplica(blocktree.ReplicaConfig{
+        ID:            cfg.ID,
+        Peers:         cfg.Peers,
+        Genesis:       g.ToWire(),
+        Rule:          blocktree.HeaviestBranch(d.weight), // ForkChoice, following Elect.
+        Verify:        func(w *wire.Block) bool { return verifyProduced(w, d.producers) },
+        Header:        func(w *wire.Block) wire.Header { b := BlockFromWire(w); return b.Header() },
+        Confirmations: cfg.Confirmations,
+        AntiEntropy:   cfg.AntiEntropy,
+        Prune:         cfg.Prune,
+        State:         cfg.State,
+        Mempool:       cfg.Mempool,
+        Logger:        d.logger,
+    })
+    return d
+}
+
+// verifyProduced checks a block received from a peer: its hash must match its contents and its producer must
+// be one of the delegates.
+func verifyProduced(w *wire.Block, delegates map[string]bool) bool {
+    cost.Count(cost.Verify, 1) // A deployment checks the producer's signature on the block (see package cost).
+    block := BlockFromWire(w)
+    return block.Hash == block.CalculateHash() && delegates[block.Delegate]
+}
+
+// Elect replaces the delegates that produce blocks from the next slot on, as a vote counted at the end of an
+// epoch does. Every delegate must be given the same list at the same slot, or they disagree on the schedule.
+// Blocks of delegates voted out stay valid, since the chain holds them; they only produce no more. The rotation
+// policy is built again for the new delegates, so a sticky policy starts over with the first of them.
+func (d *Delegate) Elect(delegates []int32) {
+    d.delegates = slices.Sorted(slices.Values(delegates))
+    for _, id := range d.delegates {
+        d.producers[node.Name(id)] = true
+    }
+    d.policy = d.rotation(d.delegates)
+}
+
+// Delegates returns the sorted identifiers of the delegates currently producing blocks.
+func (d *Delegate) Delegates() []int32 {
+    return slices.Clone(d.delegates)
+}
+
+// weight counts a block in ForkChoice if its producer was ever elected.
+func (d *Delegate) weight(producer string) int {
+    if d.producers[producer] {
+        return 1
+    }
+    return 0
+}
+
+// Producer returns the delegate scheduled to produce the block of the given slot.
+func (d *Delegate) Producer(slot int) int32 {
+    return d.policy.Leader(uint64(slot))
+}
+
+// Leader returns the delegate scheduled for the current slot.
+func (d *Delegate) Leader() int32 {
+    return d.Producer(d.ticks / d.slotTicks)
+}
+
+// filled reports whether the head is the block of the slot that started at slotStart and ends now: made by the
+// slot's producer, no earlier than half a slot before the slot started. The margin allows for peers whose ticks
+// are not aligned with this one's, whose blocks can arrive just before its slot starts.
+func (d *Delegate) filled(slot int, now time.Time) bool {
+    head := d.Head()
+    earliest := d.slotStart.Add(-now.Sub(d.slotStart) / 2)
+    return head.GetProducer() == node.Name(d.Producer(slot)) && !wire.Timestamp(head.GetTimestamp()).Time().Before(earliest)
+}
+
+// Tick advances the delegate's clock by one tick. At the start of each of its slots, the delegate produces a
+// block carrying the oldest pending data, or no data. At the start of every slot, it reports the last one to its
+// rotation policy as failed if that slot's producer's block did not become its head.
+func (d *Delegate) Tick() []*wire.Envelope {
+    out := d.Replica.Tick()
+    d.ticks++
+    if d.ticks%d.slotTicks != 0 {
+        return out
+    }
+    now := d.clock.Now()
+    if last := d.ticks/d.slotTicks - 1; last > 0 && !d.filled(last, now) {
+        d.policy.Fail(uint64(last))
+    }
+    d.slotStart = now
+    if d.Leader() != d.ID() {
+        return out
+    }
+    head := d.Head()
+    cost.Count(cost.Sign, 1) // A deployment signs the block it produces (see package cost).
+    block := NewBlockAt(d.NextData(), head.Sum(), int(head.GetIndex())+1, node.Name(d.ID()), d.clock.Now())
+    d.logger.Info("produced block", "slot", d.ticks/d.slotTicks, "index", block.Index, "hash", block.Hash.String())
+    return append(out, d.Produce(block.ToWire())...)
+}
@@ -0,0 +1,39 @@
+package dpos
+
+import (
+    "cmp"
+    "slices"
+)
+
+// Ballot is one voter's vote in a stake-weighted approval election, as deployed DPoS chains hold them: the voter
+// approves several candidates, and each of them receives the voter's whole stake. Blockchain.Vote gives every
+// voter one vote of equal weight instead.
+type Ballot struct {
+    Voter   string   // Who cast the ballot.
+    Stake   int      // Weight of the ballot.
+    Approve []string // Candidates the voter approves; a candidate listed twice counts once.
+}
+
+// Tally counts ballots and returns the active set: the seats candidates approved by the most stake, the most
+// approved first, ties going to the lower name so that every node computes the same set. Candidates no stake
+// approves are never elected, so the set may have fewer seats.
+func Tally(ballots []Ballot, seats int) []string {
+    approval := make(map[string]int)
+    for _, b := range ballots {
+        counted := make(map[string]bool, len(b.Approve))
+        for _, candidate := range b.Approve {
+            if !counted[candidate] && b.Stake > 0 {
+                counted[candidate] = true
+                approval[candidate] += b.Stake
+            }
+        }
+    }
+    elected := make([]string, 0, len(approval))
+    for candidate := range approval {
+        elected = append(elected, candidate)
+    }
+    slices.SortFunc(elected, func(a, b string) int {
+        return cmp.Or(cmp.Compare(approval[b], approval[a]), cmp.Compare(a, b))
+    })
+    return elected[:min(seats, len(elected))]
+}
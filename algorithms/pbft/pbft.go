@@ -7,27 +7,67 @@
 package pbft
 
 import (
-    "crypto/sha256"
+    "context"
+    "errors"
     "fmt"
+    "io"
+    "iter"
+    "log/slog"
+    "maps"
+    "slices"
     "strconv"
+    "sync"
     "time"
+
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/events"
+    "consensus-algorithms-edu/feed"
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/logging"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/quorum"
+    "consensus-algorithms-edu/storage"
+    "consensus-algorithms-edu/wire"
+)
+
+var (
+    // ErrNoQuorum is returned by RunPBFT when fewer than a quorum of nodes approved the primary's block.
+    ErrNoQuorum = errors.New("pbft: block lacked a quorum")
+
+    // ErrInvalidBlock is returned by AddBlock for a block that does not extend the chain: its index or previous
+    // hash does not follow the last block, or its hash does not match its contents. It is also returned for a
+    // block whose transactions break the rules of the state attached with AttachState, wrapping ledger.ErrInvalid.
+    ErrInvalidBlock = errors.New("pbft: block does not extend the chain")
 )
 
 // Block represents an individual block in the blockchain.
 // Each block contains essential information, including metadata, the cryptographic hash, and the data it stores.
 type Block struct {
-    Index     int    // The position of the block in the blockchain.
-    Timestamp string // The time when the block was created.
-    Data      string // The data contained in the block (e.g., transactions).
-    PrevHash  string // The hash of the previous block, establishing continuity of the chain.
-    Hash      string // The cryptographic hash of the current block's contents.
+    Index        int            // The position of the block in the blockchain.
+    Timestamp    wire.Timestamp // When the block was created, in nanoseconds since the Unix epoch.
+    Data         string         // The data contained in the block (e.g., transactions).
+    PrevHash     wire.Hash      // The hash of the previous block, establishing continuity of the chain.
+    Hash         wire.Hash      // The cryptographic hash of the current block's contents.
+    Certificate  []string       // Nodes that approved the block, proving it reached a quorum; not covered by the hash.
+    StateRoot    wire.Hash      // Root of the attached ledger state after the block; the zero Hash if none was attached.
+    ReceiptsRoot wire.Hash      // Root of the receipts of its transactions; the zero Hash if it has none or none was attached.
 }
 
 // Blockchain represents the distributed ledger, which is maintained by nodes.
 // It contains an ordered list of blocks, each of which is linked to its predecessor by cryptographic hash.
 type Blockchain struct {
-    Blocks []Block // A slice of all blocks in the blockchain.
-    Nodes  []Node  // A slice representing all nodes participating in PBFT consensus.
+    mu          sync.Mutex                // Guards every field, and the fields of the nodes, while a method runs.
+    Blocks      []Block                   // A slice of all blocks in the blockchain.
+    Nodes       []*Node                   // A slice representing all nodes participating in PBFT consensus.
+    blockStore  storage.BlockStore        // Optional block store that every appended block is written through to.
+    state       ledger.State              // Checks the transactions of appended blocks and applies them; blocks are not interpreted if nil.
+    receipts    map[string]ledger.Receipt // Receipts of the transactions of appended blocks by hash, if the state records them.
+    clock       clock.Clock               // Clock that timestamps appended blocks; the system clock if nil.
+    logger      *slog.Logger              // Logger that records consensus steps; silent if nil.
+    publisher   events.Publisher          // Receives votes and leader changes; nothing is published if nil.
+    quorum      quorum.Builder            // Decides which approvals suffice; quorum.Byzantine if nil.
+    subscribers feed.Feed[Block]          // Delivers every appended block to Subscribe's channels.
 }
 
 // Node represents an individual node participating in the PBFT protocol.
@@ -36,15 +76,28 @@ type Node struct {
     ID          int         // A unique identifier for the node.
     IsPrimary   bool        // A flag indicating if this node is the primary node (leader).
     State       string      // The state of the node (optional, for future implementation).
+    Faulty      bool        // A Byzantine node that rejects every proposal; set with options.WithFaulty.
     Blockchain  *Blockchain // Reference to the blockchain managed by the node.
+
+    // Restore, if set, is called by Recover to restore the node's state before it rejoins. It runs without
+    // the blockchain's lock, so it may call the blockchain.
+    Restore func(n *Node) error
+
+    status node.Status // Where the node stands in its crash-recovery lifecycle.
 }
 
 // NewBlock creates a new block given the data, index, and previous block hash.
 // It calculates the hash for the new block to ensure data integrity.
-func NewBlock(data string, prevHash string, index int) Block {
+func NewBlock(data string, prevHash wire.Hash, index int) Block {
+    return NewBlockAt(data, prevHash, index, clock.System.Now())
+}
+
+// NewBlockAt is NewBlock with an explicit creation time, for callers that keep their own clock such as a
+// simulation.
+func NewBlockAt(data string, prevHash wire.Hash, index int, at time.Time) Block {
     block := Block{
         Index:     index,
-        Timestamp: time.Now().String(), // Set the block's timestamp to the current time.
+        Timestamp: wire.TimestampOf(at),
         Data:      data,
         PrevHash:  prevHash,
     }
@@ -52,82 +105,401 @@ func NewBlock(data string, prevHash string, index int) Block {
     return block
 }
 
-// CalculateHash generates the SHA-256 hash of the block's contents.
+// CalculateHash generates the SHA-256 hash of the block, which is the hash of its header.
 // This ensures that each block is uniquely represented and immutable.
-func (b *Block) CalculateHash() string {
-    record := strconv.Itoa(b.Index) + b.Timestamp + b.Data + b.PrevHash
-    hash := sha256.New()                // Create a new SHA-256 hash object.
-    hash.Write([]byte(record))          // Write the concatenated block data to the hash.
-    hashed := hash.Sum(nil)             // Compute the hash.
-    return fmt.Sprintf("%x", hashed)    // Return the hash as a hexadecimal string.
+func (b *Block) CalculateHash() wire.Hash {
+    h := b.Header()
+    return h.Hash()
+}
+
+// Header returns the part of the block its hash covers, which commits to the data through its root.
+// The certificate is not part of it: replicas vote on the header, so the certificate can only be added afterwards.
+func (b *Block) Header() wire.Header {
+    return wire.Header{
+        Index:     int64(b.Index),
+        Timestamp: b.Timestamp,
+        Parent:    b.PrevHash,
+        Root:      wire.BodyRoot(b.Data),
+        State:     b.StateRoot,
+        Receipts:  b.ReceiptsRoot,
+    }
 }
 
-// AddBlock appends a new block to the blockchain.
-func (bc *Blockchain) AddBlock(block Block) {
-    bc.Blocks = append(bc.Blocks, block) // Append the new block to the blockchain.
+// ToWire converts the block into the algorithm-neutral wire format used for messaging and storage.
+func (b *Block) ToWire() *wire.Block {
+    return &wire.Block{
+        Index:        int64(b.Index),
+        Timestamp:    int64(b.Timestamp),
+        Data:         b.Data,
+        PrevHash:     b.PrevHash.String(),
+        Hash:         b.Hash.String(),
+        Certificate:  b.Certificate,
+        StateRoot:    b.StateRoot.String(),
+        ReceiptsRoot: b.ReceiptsRoot.String(),
+    }
+}
+
+// BlockFromWire converts a wire-format block back into this package's Block type.
+// The hash is copied as-is rather than recalculated, so callers can still detect tampered blocks.
+func BlockFromWire(w *wire.Block) Block {
+    return Block{
+        Index:        int(w.GetIndex()),
+        Timestamp:    wire.Timestamp(w.GetTimestamp()),
+        Data:         w.GetData(),
+        PrevHash:     w.ParentSum(),
+        Hash:         w.Sum(),
+        Certificate:  w.GetCertificate(),
+        StateRoot:    w.StateSum(),
+        ReceiptsRoot: w.ReceiptsSum(),
+    }
+}
+
+// AddBlock appends a new block to the blockchain. A block that does not extend the chain is refused with
+// ErrInvalidBlock. If a block store is attached, the block is written to it first and any storage error is returned.
+func (bc *Blockchain) AddBlock(block Block) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return bc.addBlock(context.Background(), block)
+}
+
+// addBlock is AddBlock for callers that hold the lock. Records are logged with ctx.
+func (bc *Blockchain) addBlock(ctx context.Context, block Block) error {
+    if tip := bc.head(); block.Index != tip.Index+1 || block.PrevHash != tip.Hash || block.Hash != block.CalculateHash() {
+        return fmt.Errorf("%w: block %d after block %d", ErrInvalidBlock, block.Index, tip.Index)
+    }
+    receipts, err := bc.checkBlock(block)
+    if err != nil {
+        return fmt.Errorf("%w: block %d: %w", ErrInvalidBlock, block.Index, err)
+    }
+    if bc.blockStore != nil {
+        if err := bc.blockStore.Put(block.ToWire()); err != nil {
+            return err // Leave the in-memory chain untouched if the store rejected the block.
+        }
+    }
+    bc.Blocks = append(bc.Blocks, block) // Append the new block to the chain.
+    if bc.state != nil {
+        bc.state.Apply(block.Data) // Checked above, under the same lock.
+        bc.indexReceipts(block.Index, receipts)
+    }
+    bc.subscribers.Publish(block)
+    logging.Or(bc.logger).InfoContext(ctx, "committed block", "index", block.Index, "hash", block.Hash.String())
+    return nil
 }
 
 // NewBlockchain initializes a new blockchain with a genesis block, which serves as the root of the chain.
 func NewBlockchain() *Blockchain {
-    genesisBlock := NewBlock("Genesis Block", "", 0) // Create the genesis block.
+    genesisBlock := NewBlock("Genesis Block", wire.Hash{}, 0) // Create the genesis block.
     return &Blockchain{
         Blocks: []Block{genesisBlock}, // Initialize with the genesis block.
-        Nodes:  []Node{},              // Initialize an empty list of nodes.
+        Nodes:  []*Node{},             // Initialize an empty list of nodes.
     }
 }
 
+// Snapshot returns a copy of the blocks in the chain, which stays unchanged while other goroutines add blocks.
+func (bc *Blockchain) Snapshot() []Block {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return append([]Block(nil), bc.Blocks...)
+}
+// Height returns the index of the chain's last block: the number of blocks added after the genesis block.
+func (bc *Blockchain) Height() int {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return len(bc.Blocks) - 1
+}
+
+// Head returns the chain's last block.
+func (bc *Blockchain) Head() Block {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return bc.head()
+}
+
+// head returns the chain's last block. The caller must hold bc.mu.
+func (bc *Blockchain) head() Block {
+    return bc.Blocks[len(bc.Blocks)-1]
+}
+
+// GetBlockByIndex returns the block at index, or false if the chain has no block there.
+func (bc *Blockchain) GetBlockByIndex(index int) (Block, bool) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    if index < 0 || index >= len(bc.Blocks) {
+        return Block{}, false
+    }
+    return bc.Blocks[index], true
+}
+
+// GetBlockByHash returns the block with the given hash, or false if the chain has none. The chain is searched
+// from its head, since recent blocks are the ones most often looked up.
+func (bc *Blockchain) GetBlockByHash(hash wire.Hash) (Block, bool) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    for i := len(bc.Blocks) - 1; i >= 0; i-- {
+        if bc.Blocks[i].Hash == hash {
+            return bc.Blocks[i], true
+        }
+    }
+    return Block{}, false
+}
+
+// All returns an iterator over the index and block of every block in the chain, starting at the genesis block.
+func (bc *Blockchain) All() iter.Seq2[int, Block] {
+    return bc.Range(0, -1)
+}
+
+// Range returns an iterator over the blocks from index from up to and including index to, or up to the head if
+// to is negative. It iterates over the blocks as they were when it started, so the loop body may add blocks.
+func (bc *Blockchain) Range(from, to int) iter.Seq2[int, Block] {
+    return func(yield func(int, Block) bool) {
+        bc.mu.Lock()
+        if to < 0 || to >= len(bc.Blocks) {
+            to = len(bc.Blocks) - 1
+        }
+        from := max(from, 0)
+        var blocks []Block
+        if from <= to {
+            blocks = slices.Clone(bc.Blocks[from : to+1])
+        }
+        bc.mu.Unlock()
+        for i, block := range blocks {
+            if !yield(from+i, block) {
+                return
+            }
+        }
+    }
+}
+
+// Subscribe returns a channel that receives every block appended to the chain from now on, in order, so that a
+// consumer can wait for new blocks rather than poll Blocks. Blocks replaced by Import are not delivered. Pass the
+// channel to Unsubscribe once done with it, which closes it.
+func (bc *Blockchain) Subscribe() <-chan Block {
+    return bc.subscribers.Subscribe()
+}
+
+// Unsubscribe stops delivering blocks to ch, a channel returned by Subscribe, and closes it.
+func (bc *Blockchain) Unsubscribe(ch <-chan Block) {
+    bc.subscribers.Unsubscribe(ch)
+}
+
+// String summarizes the chain in one line: its length and the block at its head.
+func (bc *Blockchain) String() string {
+    return wire.Summarize("pbft", bc.wireBlocks())
+}
+
+// Dump formats every block of the chain for printing, one line per block.
+func (bc *Blockchain) Dump() string {
+    return wire.Dump(bc.wireBlocks())
+}
+
+// Diff reports the first height at which chains a and b hold different blocks, or at which one of them ends.
+func Diff(a, b *Blockchain) wire.Divergence {
+    return wire.Diff(a.wireBlocks(), b.wireBlocks())
+}
+
+// wireBlocks converts the chain's blocks to the shared wire format.
+func (bc *Blockchain) wireBlocks() []*wire.Block {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    blocks := make([]*wire.Block, len(bc.Blocks))
+    for i := range bc.Blocks {
+        blocks[i] = bc.Blocks[i].ToWire()
+    }
+    return blocks
+}
+
+// Clone returns an independent copy of the chain and of its nodes, so that a simulation can fork a scenario mid-run and see
+// what happens next without changing the original: blocks added to either copy leave the other unchanged. The
+// copy keeps the original's clock, logger, event publisher and quorum but not its block store or its subscribers, and
+// gets its own copy of an attached state.
+func (bc *Blockchain) Clone() *Blockchain {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    c := &Blockchain{
+        Blocks:    slices.Clone(bc.Blocks),
+        Nodes:     make([]*Node, len(bc.Nodes)),
+        clock:     bc.clock,
+        logger:    bc.logger,
+        publisher: bc.publisher,
+        quorum:    bc.quorum,
+    }
+    for i, n := range bc.Nodes {
+        clone := *n
+        clone.Blockchain = c
+        c.Nodes[i] = &clone
+    }
+    if bc.state != nil {
+        c.state = bc.state.Clone()
+        c.receipts = maps.Clone(bc.receipts)
+    }
+    return c
+}
+
+// Clone returns the node's counterpart in a Clone of its blockchain, to fork a scenario from one node's point of
+// view. A node that is no longer a member of the network is copied on its own, attached to the clone.
+func (n *Node) Clone() *Node {
+    c := n.Blockchain.Clone()
+    for _, clone := range c.Nodes {
+        if clone.ID == n.ID {
+            return clone
+        }
+    }
+    clone := *n
+    clone.Blockchain = c
+    return &clone
+}
+
 // ProposeBlock allows the primary node to create a new block proposal.
 // It retrieves the latest block and proposes a new block with the given data.
 func (n *Node) ProposeBlock(data string) Block {
-    prevBlock := n.Blockchain.Blocks[len(n.Blockchain.Blocks)-1] // Get the last block in the chain.
-    newBlock := NewBlock(data, prevBlock.Hash, prevBlock.Index+1) // Create a new block based on the latest block.
+    n.Blockchain.mu.Lock()
+    defer n.Blockchain.mu.Unlock()
+    return n.proposeBlock(data)
+}
+
+func (n *Node) proposeBlock(data string) Block {
+    prevBlock := n.Blockchain.head() // Get the last block in the chain.
+    newBlock := NewBlockAt(data, prevBlock.Hash, prevBlock.Index+1, clock.Or(n.Blockchain.clock).Now()) // Create a new block based on the latest block.
+    if n.Blockchain.state != nil {
+        newBlock.StateRoot, newBlock.ReceiptsRoot = n.Blockchain.roots(data)
+        newBlock.Hash = newBlock.CalculateHash() // Replicas vote on the state and receipts the block leaves, too.
+    }
     return newBlock
 }
 
 // BroadcastBlock broadcasts a proposed block to all nodes in the network for verification.
-// A block is considered valid if at least 2/3 of nodes approve it.
+// A block is considered valid if a quorum of nodes approves it.
 func (bc *Blockchain) BroadcastBlock(block Block) bool {
-    approvals := 0
-    totalNodes := len(bc.Nodes)
-    
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    approvals, _ := bc.collectApprovals(context.Background(), block)
+    return bc.reached(approvals) // Return true if a quorum approves the block.
+}
+
+// Quorum returns the number of approvals a block needs: 2f+1 of n = 3f+1 nodes, i.e. more than 2/3 of them.
+// Any two quorums then share at least one honest node, so f Byzantine nodes cannot get two conflicting blocks
+// committed. Under a quorum set with options.WithQuorum, it is the weight the approvals must reach if the quorum
+// is a quorum.Weighted, and 0 if it is not, such as a grid.
+func (bc *Blockchain) Quorum() int {
+    if w, ok := bc.quorumOf().(*quorum.Weighted); ok {
+        return w.Need()
+    }
+    return 0
+}
+
+// reached reports whether the nodes that approved, by name, form a quorum of the current nodes: 2f+1 of them,
+// unless options.WithQuorum chose another.
+func (bc *Blockchain) reached(approvals []string) bool {
+    var approvers []int32
+    for _, n := range bc.Nodes {
+        if slices.Contains(approvals, n.Name()) {
+            approvers = append(approvers, int32(n.ID))
+        }
+    }
+    return bc.quorumOf().Reached(approvers)
+}
+
+// quorumOf builds the quorum of the current nodes.
+func (bc *Blockchain) quorumOf() quorum.Quorum {
+    members := make([]int32, len(bc.Nodes))
+    for i, n := range bc.Nodes {
+        members[i] = int32(n.ID)
+    }
+    if bc.quorum == nil {
+        return quorum.Byzantine(members)
+    }
+    return bc.quorum(members)
+}
+
+// collectApprovals asks every node to verify a proposed block and returns the names of the nodes that approved it.
+// It stops with the context's error once ctx is done.
+func (bc *Blockchain) collectApprovals(ctx context.Context, block Block) ([]string, error) {
+    var approvals []string
     for _, node := range bc.Nodes {
-        if node.VerifyBlock(block) {
-            approvals++ // Increment the count if the node approves the block.
+        if err := ctx.Err(); err != nil {
+            return nil, err
+        }
+        if node.verifyBlock(ctx, block) {
+            approvals = append(approvals, node.Name())
         }
     }
-    
-    // Return true if 2/3 or more nodes approve the block.
-    return approvals >= (2 * totalNodes / 3)
+    return approvals, nil
 }
 
 // VerifyBlock allows a node to verify the validity of a proposed block.
 // The node checks if the block's previous hash matches the last block in the chain and if the block hash is valid.
 func (n *Node) VerifyBlock(block Block) bool {
-    prevBlock := n.Blockchain.Blocks[len(n.Blockchain.Blocks)-1] // Retrieve the latest block in the chain.
+    n.Blockchain.mu.Lock()
+    defer n.Blockchain.mu.Unlock()
+    return n.verifyBlock(context.Background(), block)
+}
+
+func (n *Node) verifyBlock(ctx context.Context, block Block) bool {
+    prevBlock := n.Blockchain.head() // Retrieve the latest block in the chain.
     // Verify if the proposed block's previous hash matches the latest block's hash and if the block hash is valid.
-    if block.PrevHash == prevBlock.Hash {
-        return block.Hash == block.CalculateHash()
+    valid := !n.Faulty && n.status == node.Running && block.PrevHash == prevBlock.Hash && block.Hash == block.CalculateHash() // A faulty node rejects everything.
+    if valid {
+        _, err := n.Blockchain.checkBlock(block)
+        valid = err == nil // An honest node also rejects transactions that break the ledger's rules.
+    }
+    logging.Or(n.Blockchain.logger).DebugContext(ctx, "verified block", logging.NodeKey, n.ID, "index", block.Index, "valid", valid)
+    if valid {
+        n.Blockchain.publish(events.Event{Type: events.Vote, Node: n.Name(), Height: int64(block.Index), Hash: block.Hash.String()})
     }
-    return false
+    return valid
 }
 
 // CommitBlock adds a block to the blockchain, once it has been verified and approved by the network.
-func (n *Node) CommitBlock(block Block) {
-    n.Blockchain.AddBlock(block) // Append the verified block to the blockchain.
+func (n *Node) CommitBlock(block Block) error {
+    return n.Blockchain.AddBlock(block) // Append the verified block to the blockchain.
 }
 
 // RunPBFT initiates the Practical Byzantine Fault Tolerance consensus process.
-// The primary node proposes a new block, and if it receives approval from 2/3 of nodes, all nodes commit the block.
-func (bc *Blockchain) RunPBFT(data string) {
+// The primary node proposes a new block, and if it receives approval from a quorum, all nodes commit the block,
+// which is returned with its certificate. Without a quorum, ErrNoQuorum is returned and the chain is unchanged.
+// While the primary is crashed, node.ErrCrashed is returned. If a state is attached (see AttachState) and the
+// data breaks its rules, the primary proposes nothing and an error wrapping ledger.ErrInvalid is returned.
+func (bc *Blockchain) RunPBFT(data string) (Block, error) {
+    return bc.RunPBFTContext(context.Background(), data)
+}
+
+// RunPBFTContext is RunPBFT bounded by ctx: once the context is done, the nodes that have not yet verified the
+// block are not asked, and the round returns the context's error without committing. Records logged during the
+// round carry the context's trace, if it has one (see logging.WithTrace).
+func (bc *Blockchain) RunPBFTContext(ctx context.Context, data string) (Block, error) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return bc.runPBFT(ctx, data)
+}
+
+// runPBFT is RunPBFTContext for callers that hold the lock.
+func (bc *Blockchain) runPBFT(ctx context.Context, data string) (Block, error) {
     primary := bc.Nodes[0]                   // The first node is treated as the primary node (leader).
-    newBlock := primary.ProposeBlock(data)   // Primary node proposes a new block.
+    if primary.status != node.Running {
+        return Block{}, node.ErrCrashed // Without a view change, nobody else may propose.
+    }
+    if err := bc.checkState(data); err != nil {
+        return Block{}, err // An honest primary does not propose a block the replicas would reject.
+    }
+    newBlock := primary.proposeBlock(data)   // Primary node proposes a new block.
+    logging.Or(bc.logger).InfoContext(ctx, "pre-prepared block", logging.NodeKey, primary.ID, "index", newBlock.Index)
 
-    // Broadcast the proposed block for verification, and if approved, commit it across all nodes.
-    if bc.BroadcastBlock(newBlock) {
-        for _, node := range bc.Nodes {
-            node.CommitBlock(newBlock)       // Each node commits the approved block.
-        }
+    // Broadcast the proposed block for verification, and if approved, commit it with the approvals as its
+    // certificate. Every node shares the same Blockchain in this simulation, so the block is committed once on
+    // behalf of all of them.
+    approvals, err := bc.collectApprovals(ctx, newBlock)
+    if err != nil {
+        return Block{}, err
+    }
+    if !bc.reached(approvals) {
+        logging.Or(bc.logger).WarnContext(ctx, "block lacked a quorum", logging.NodeKey, primary.ID, "index", newBlock.Index, "approvals", len(approvals), "quorum", bc.Quorum())
+        return Block{}, ErrNoQuorum
+    }
+    newBlock.Certificate = approvals
+    if err := bc.addBlock(ctx, newBlock); err != nil {
+        return Block{}, err
     }
+    return newBlock, nil
 }
 
 // NewNode creates a new node with the given ID, assigns it as primary or follower, and links it to the blockchain.
@@ -139,18 +511,377 @@ func NewNode(id int, isPrimary bool, blockchain *Blockchain) *Node {
     }
 }
 
-// NewPBFTNetwork initializes a PBFT network with a specified number of nodes.
-// The first node is assigned as the primary node, and all nodes are linked to the blockchain.
-func NewPBFTNetwork(size int) *Blockchain {
+// Name returns the name the node is reported under in events, e.g. "node-3".
+func (n *Node) Name() string {
+    return "node-" + strconv.Itoa(n.ID)
+}
+
+// Status returns where the node stands in its crash-recovery lifecycle.
+func (n *Node) Status() node.Status {
+    n.Blockchain.mu.Lock()
+    defer n.Blockchain.mu.Unlock()
+    return n.status
+}
+
+// Crash stops a running node. Unlike a Byzantine node it sends nothing at all, which costs a quorum the same
+// approval; a crashed primary stops the network, as this simplified PBFT has no view change.
+func (n *Node) Crash() {
+    n.Blockchain.mu.Lock()
+    defer n.Blockchain.mu.Unlock()
+    if n.status != node.Running {
+        return
+    }
+    n.status = node.Crashed
+    logging.Or(n.Blockchain.logger).Info("crashed", logging.NodeKey, n.ID)
+}
+
+// Recover brings a crashed node back. The node is Recovering while its Restore hook runs and Running
+// afterwards; if Restore fails, the node stays crashed and the error is returned. Recovering a node that has
+// not crashed returns node.ErrNotCrashed.
+func (n *Node) Recover() error {
+    n.Blockchain.mu.Lock()
+    if n.status != node.Crashed {
+        n.Blockchain.mu.Unlock()
+        return node.ErrNotCrashed
+    }
+    n.status = node.Recovering
+    restore := n.Restore
+    n.Blockchain.mu.Unlock()
+
+    var err error
+    if restore != nil {
+        err = restore(n)
+    }
+    n.Blockchain.mu.Lock()
+    defer n.Blockchain.mu.Unlock()
+    if err != nil {
+        n.status = node.Crashed
+        logging.Or(n.Blockchain.logger).Warn("recovery failed", logging.NodeKey, n.ID, "error", err)
+        return err
+    }
+    n.status = node.Running
+    logging.Or(n.Blockchain.logger).Info("recovered", logging.NodeKey, n.ID)
+    return nil
+}
+
+// NewPBFTNetwork initializes a PBFT network of options.WithNodes nodes, 4 by default, the last
+// options.WithFaulty of which are Byzantine. The first node is assigned as the primary node, and all nodes
+// are linked to the blockchain. options.WithQuorum replaces the 2f+1 approvals a block needs. Other options are
+// ignored.
+func NewPBFTNetwork(opts ...options.Option) *Blockchain {
+    o := options.New(opts...)
     blockchain := NewBlockchain()              // Create a new blockchain instance with the genesis block.
-    nodes := make([]Node, size)                // Create an array of nodes.
-    for i := 0; i < size; i++ {
-        nodes[i] = *NewNode(i, i == 0, blockchain) // Initialize each node; the first node is set as primary.
+    nodes := make([]*Node, o.Nodes)            // Create an array of nodes.
+    for i := 0; i < o.Nodes; i++ {
+        nodes[i] = NewNode(i, i == 0, blockchain) // Initialize each node; the first node is set as primary.
+        nodes[i].Faulty = i >= o.Nodes-o.Faulty
     }
     blockchain.Nodes = nodes                   // Assign nodes to the blockchain.
+    blockchain.quorum = o.Quorum
     return blockchain
 }
 
+// AddNode adds a replica to the running network and returns its identifier, one higher than any current
+// node's. The change is agreed on like a block: the primary proposes a block recording it, and it takes effect
+// only once a quorum of the current replicas approved it. The quorum is recomputed from the new size, so
+// adding replicas raises the number of Byzantine faults tolerated, f, once the network reaches 3f+1 again.
+// ErrNoQuorum is returned if the quorum was not reached.
+func (bc *Blockchain) AddNode(ctx context.Context) (int, error) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    id := 0
+    for _, n := range bc.Nodes {
+        id = max(id, n.ID+1)
+    }
+    if _, err := bc.runPBFT(ctx, fmt.Sprintf("config: add node-%d", id)); err != nil {
+        return 0, err
+    }
+    bc.Nodes = append(bc.Nodes, NewNode(id, false, bc))
+    logging.Or(bc.logger).InfoContext(ctx, "changed membership", "change", "add", logging.NodeKey, id)
+    return id, nil
+}
+
+// RemoveNode removes the replica with the given identifier from the running network, agreeing on the change
+// like AddNode. If the primary is removed, the next replica becomes primary, as a view change would make it.
+// Removing a replica that is not a member returns node.ErrUnknownNode, and removing the last one
+// node.ErrLastNode.
+func (bc *Blockchain) RemoveNode(ctx context.Context, id int) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    i := slices.IndexFunc(bc.Nodes, func(n *Node) bool { return n.ID == id })
+    if i < 0 {
+        return fmt.Errorf("%w: %d", node.ErrUnknownNode, id)
+    }
+    if len(bc.Nodes) == 1 {
+        return node.ErrLastNode
+    }
+    if _, err := bc.runPBFT(ctx, fmt.Sprintf("config: remove node-%d", id)); err != nil {
+        return err
+    }
+    bc.Nodes = slices.Delete(bc.Nodes, i, i+1)
+    bc.Nodes[0].IsPrimary = true
+    logging.Or(bc.logger).InfoContext(ctx, "changed membership", "change", "remove", logging.NodeKey, id)
+    return nil
+}
+
+// AttachBlockStore connects a block store to the blockchain. Every block already in the chain is written
+// to the store immediately, and every block appended afterwards is written through to it as well.
+func (bc *Blockchain) AttachBlockStore(store storage.BlockStore) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return bc.attachBlockStore(store)
+}
+
+func (bc *Blockchain) attachBlockStore(store storage.BlockStore) error {
+    for i := range bc.Blocks {
+        if err := store.Put(bc.Blocks[i].ToWire()); err != nil {
+            return err
+        }
+    }
+    bc.blockStore = store
+    return nil
+}
+
+// AttachState makes the chain check the transactions of every block it appends against s, refusing a block that
+// breaks its rules with an error wrapping ledger.ErrInvalid, and apply them to s once the block is appended. s is
+// first rebuilt from the blocks already in the chain; if they break its rules, the error is returned and neither
+// s nor the chain changes.
+func (bc *Blockchain) AttachState(s ledger.State) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    receipts, err := replayState(s, bc.Blocks)
+    if err != nil {
+        return err
+    }
+    bc.state, bc.receipts = s, receipts
+    return nil
+}
+
+// checkState reports why a block carrying data would break the rules of the attached state, if there is one.
+func (bc *Blockchain) checkState(data string) error {
+    if bc.state == nil {
+        return nil
+    }
+    return bc.state.Check(data)
+}
+
+// checkBlock is checkState for a block, which must also commit to the state and receipts roots its data leads
+// to. It returns the receipts of the block's transactions.
+func (bc *Blockchain) checkBlock(block Block) ([]ledger.Receipt, error) {
+    if bc.state == nil {
+        return nil, nil
+    }
+    return ledger.CheckRoots(bc.state, block.Data, ledger.Roots{State: block.StateRoot, Receipts: block.ReceiptsRoot})
+}
+
+// roots returns the state and receipts roots a block carrying data commits to once it is appended: zero if no
+// state is attached, or if it computes no such root. The caller must have checked data.
+func (bc *Blockchain) roots(data string) (state, receipts wire.Hash) {
+    if bc.state == nil {
+        return wire.Hash{}, wire.Hash{}
+    }
+    r, _, _ := ledger.RootsAfter(bc.state, data)
+    return r.State, r.Receipts
+}
+
+// indexReceipts records the receipts of the block at index, so GetReceipt finds them. The caller must hold bc.mu.
+func (bc *Blockchain) indexReceipts(index int, receipts []ledger.Receipt) {
+    if len(receipts) > 0 && bc.receipts == nil {
+        bc.receipts = make(map[string]ledger.Receipt)
+    }
+    for _, r := range receipts {
+        r.Block = int64(index)
+        bc.receipts[r.Tx] = r
+    }
+}
+
+// GetReceipt returns the receipt of the transaction with the given ledger.TxHash, or false if no block of the
+// chain carries it or no state that records receipts is attached (see ledger.Executor).
+func (bc *Blockchain) GetReceipt(tx string) (ledger.Receipt, bool) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    r, ok := bc.receipts[tx]
+    return r, ok
+}
+
+// replayState rebuilds s, if it is not nil, from blocks, checking the roots each of them commits to, and returns
+// the receipts of their transactions by hash. If they break its rules, s is left unchanged.
+func replayState(s ledger.State, blocks []Block) (map[string]ledger.Receipt, error) {
+    if s == nil {
+        return nil, nil
+    }
+    data := make([]string, len(blocks))
+    roots := make([]ledger.Roots, len(blocks))
+    for i := range blocks {
+        data[i], roots[i] = blocks[i].Data, ledger.Roots{State: blocks[i].StateRoot, Receipts: blocks[i].ReceiptsRoot}
+    }
+    if _, err := ledger.ReplayRoots(s.Clone(), data, roots); err != nil {
+        return nil, err
+    }
+    receipts, err := ledger.ReplayRoots(s, data, roots)
+    if err != nil {
+        return nil, err
+    }
+    index := make(map[string]ledger.Receipt)
+    for i, block := range blocks {
+        for _, r := range receipts[i] {
+            r.Block = int64(block.Index)
+            index[r.Tx] = r
+        }
+    }
+    return index, nil
+}
+
+// AttachClock sets the clock that timestamps blocks appended from now on, in place of the system clock.
+func (bc *Blockchain) AttachClock(c clock.Clock) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    bc.clock = c
+}
+
+// AttachLogger sets the logger that records consensus steps from now on. Records are labelled with the
+// algorithm, and those about a single node carry the node's identifier as well.
+func (bc *Blockchain) AttachLogger(l *slog.Logger) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    bc.logger = logging.Algorithm(l, "pbft")
+}
+
+// AttachEvents sets the publisher that receives the network's votes and its primary from now on.
+// Proposals and commits are published by wrapping the network in an engine with engine.Observe.
+func (bc *Blockchain) AttachEvents(p events.Publisher) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    bc.publisher = p
+    for i := range bc.Nodes {
+        if bc.Nodes[i].IsPrimary {
+            bc.publish(events.Event{Type: events.LeaderChange, Node: bc.Nodes[i].Name(), Leader: bc.Nodes[i].Name()}) // The primary is fixed.
+        }
+    }
+}
+
+// publish labels an event with the algorithm and sends it to the attached publisher, if any.
+func (bc *Blockchain) publish(event events.Event) {
+    if bc.publisher == nil {
+        return
+    }
+    event.Algorithm = "pbft"
+    bc.publisher.Publish(event)
+}
+
+// Save persists the blockchain's blocks to the given store under name, so the chain can be reloaded
+// after the process restarts.
+func (bc *Blockchain) Save(store storage.Store, name string) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    chain := &wire.Chain{Algorithm: "pbft"}
+    for i := range bc.Blocks {
+        chain.Blocks = append(chain.Blocks, bc.Blocks[i].ToWire()) // Convert each block to the shared wire format.
+    }
+    return store.Save(name, chain)
+}
+
+// Load replaces the blockchain's blocks with the chain previously saved under name.
+// Every block's hash and its link to the previous block are verified first, so a corrupted
+// or tampered file is rejected instead of silently becoming the new chain.
+func (bc *Blockchain) Load(store storage.Store, name string) error {
+    chain, err := store.Load(name)
+    if err != nil {
+        return err
+    }
+    blocks, err := verifyChain(chain, fmt.Sprintf("chain %q", name))
+    if err != nil {
+        return err
+    }
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    receipts, err := replayState(bc.state, blocks)
+    if err != nil {
+        return err
+    }
+    bc.receipts = receipts
+    bc.Blocks = blocks // Only replace the chain once every block has been verified.
+    if bc.blockStore != nil {
+        return bc.attachBlockStore(bc.blockStore) // Re-index the reloaded blocks in the attached store.
+    }
+    return nil
+}
+
+// Export writes the chain and its replicas, with the primary and the replicas that are faulty or crashed to w as a snapshot, which Import reads back to resume the run
+// where it stopped.
+func (bc *Blockchain) Export(w io.Writer) error {
+    bc.mu.Lock()
+    snapshot := &wire.Snapshot{Chain: &wire.Chain{Algorithm: "pbft"}}
+    for i := range bc.Blocks {
+        snapshot.Chain.Blocks = append(snapshot.Chain.Blocks, bc.Blocks[i].ToWire())
+    }
+    for _, n := range bc.Nodes {
+        snapshot.Members = append(snapshot.Members, &wire.Member{Id: int32(n.ID), Leader: n.IsPrimary, Faulty: n.Faulty, Crashed: n.status != node.Running})
+    }
+    bc.mu.Unlock()
+    return wire.WriteSnapshot(w, snapshot)
+}
+
+// Import replaces the chain and its replicas, with the primary and the replicas that are faulty or crashed with a snapshot written by Export. As with Load, every block is
+// verified first and a snapshot without replicas is refused, and nothing changes if the snapshot is rejected.
+func (bc *Blockchain) Import(r io.Reader) error {
+    snapshot, err := wire.ReadSnapshot(r)
+    if err != nil {
+        return err
+    }
+    blocks, err := verifyChain(snapshot.GetChain(), "the snapshot")
+    if err != nil {
+        return err
+    }
+    if len(snapshot.GetMembers()) == 0 {
+        return errors.New("pbft: the snapshot has no replicas")
+    }
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    receipts, err := replayState(bc.state, blocks)
+    if err != nil {
+        return err
+    }
+    bc.receipts = receipts
+    bc.Blocks = blocks
+    bc.Nodes = make([]*Node, len(snapshot.GetMembers()))
+    for i, m := range snapshot.GetMembers() {
+        bc.Nodes[i] = NewNode(int(m.GetId()), m.GetLeader(), bc)
+        bc.Nodes[i].Faulty = m.GetFaulty()
+        if m.GetCrashed() {
+            bc.Nodes[i].status = node.Crashed // A node caught recovering is exported as crashed and recovers again.
+        }
+    }
+    if bc.blockStore != nil {
+        return bc.attachBlockStore(bc.blockStore) // Re-index the imported blocks in the attached store.
+    }
+    return nil
+}
+
+// verifyChain converts a chain read from source, such as a store or a snapshot, back into blocks, verifying
+// first that it was produced by pbft and that every block's hash and its link to the previous block are intact.
+func verifyChain(chain *wire.Chain, source string) ([]Block, error) {
+    if chain.GetAlgorithm() != "pbft" {
+        return nil, fmt.Errorf("pbft: %s was produced by %q", source, chain.GetAlgorithm())
+    }
+    if len(chain.GetBlocks()) == 0 {
+        return nil, fmt.Errorf("pbft: %s has no genesis block", source)
+    }
+
+    blocks := make([]Block, 0, len(chain.GetBlocks()))
+    for i, w := range chain.GetBlocks() {
+        block := BlockFromWire(w)
+        if block.Hash != block.CalculateHash() {
+            return nil, fmt.Errorf("pbft: block %d of %s has an invalid hash", i, source)
+        }
+        if i > 0 && block.PrevHash != blocks[i-1].Hash {
+            return nil, fmt.Errorf("pbft: block %d of %s does not link to its predecessor", i, source)
+        }
+        blocks = append(blocks, block)
+    }
+    return blocks, nil
+}
+
 // Footer: Security Considerations and Architectural Decisions
 //
 // This implementation of Practical Byzantine Fault Tolerance (PBFT) demonstrates how nodes in a distributed system
@@ -163,13 +894,16 @@ func NewPBFTNetwork(size int) *Blockchain {
 // 2. **Primary Node Role**: The primary node (or leader) is responsible for proposing new blocks. If the primary fails
 //    or acts maliciously, the network must implement a "view change" process to elect a new primary (not implemented here).
 //
-// 3. **2/3 Majority Consensus**: To tolerate Byzantine faults, a proposed block must receive approvals from at least 
-//    2/3 of the nodes before being committed. This ensures that even if some nodes act maliciously, they cannot compromise
-//    the consistency of the blockchain.
+// 3. **2/3 Majority Consensus**: To tolerate f Byzantine faults among n = 3f+1 nodes, a proposed block must receive
+//    approvals from 2f+1 nodes, more than 2/3 of them, before being committed. This ensures that even if some nodes act
+//    maliciously, they cannot compromise the consistency of the blockchain.
 //
 // 4. **Block Verification**: Each node verifies proposed blocks by checking both the previous hash link and recalculating
 //    the current block's hash. This two-step verification process ensures both continuity in the chain and data integrity.
 //
+// 5. **Crashes versus Byzantine Faults**: Nodes implement node.Lifecycle. A crashed node and a Byzantine one both withhold
+//    their approval, so they count against the same f; the difference is that a crashed node can recover and rejoin.
+//
 // This implementation is simplified for educational purposes and demonstrates the core principles of PBFT consensus.
 // In a production system, more sophisticated techniques for handling node failures, view changes, and message 
 // authentication would be required to maintain resilience and security in a real-world distributed network.
\ No newline at end of file
@@ -0,0 +1,672 @@
+package pbft
+
+import (
+    "log/slog"
+    "maps"
+    "math"
+    "slices"
+    "sort"
+    "strconv"
+
+    "google.golang.org/protobuf/proto"
+
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/cost"
+    "consensus-algorithms-edu/logging"
+    "consensus-algorithms-edu/quorum"
+    "consensus-algorithms-edu/rotation"
+    "consensus-algorithms-edu/wire"
+)
+
+// ReplicaConfig describes a single PBFT replica and the group it belongs to.
+type ReplicaConfig struct {
+    ID              int32            // Unique identifier of this replica.
+    Peers           []int32          // Every replica in the group, in order; the primary of view v is Peers[v mod n] by default.
+    Rotation        rotation.Builder // Policy choosing the primary of each view among Peers; rotation.RoundRobin if nil.
+    Quorum          quorum.Builder   // Replicas whose matching votes prepare or commit a block, or start a view; quorum.Byzantine if nil.
+    ViewChangeTicks int              // Ticks a replica waits for a pending request to execute before suspecting the primary.
+    Clock           clock.Clock      // Source of block timestamps; the system clock is used if nil.
+    Logger          *slog.Logger     // Receives the phases of each round and view changes, scoped to this replica; silent if nil.
+
+    // Backoff makes the view-change timeout adaptive: every view change the replica starts multiplies the ticks
+    // it waits by Backoff, and every request it waited for that executes multiplies them by Decay, down to
+    // ViewChangeTicks. A timeout too short for the network's latency grows across failed views until a view
+    // lasts long enough to execute a request, then creeps back down while requests execute, and grows again
+    // once it is too short. Timeouts are fixed at ViewChangeTicks if Backoff is 0 or 1.
+    Backoff            float64
+    Decay              float64 // Factor in (0, 1) an executed request shrinks an adaptive timeout by; 0.9 if 0.
+    MaxViewChangeTicks int     // Ceiling of an adaptive timeout; 16 times ViewChangeTicks if 0.
+}
+
+// slot tracks the agreement on one sequence number in the current view.
+type slot struct {
+    prePrepare *wire.PrePrepare  // The primary's assignment of a block to this sequence number.
+    prepares   map[int32]string  // Digest prepared by each replica.
+    commits    map[int32]string  // Digest committed by each replica.
+    prepared   bool              // Set once this replica has a prepared certificate and has sent Commit.
+}
+
+// Replica is a message-driven PBFT participant.
+// Like the Raft replica, it never performs I/O: Step and Tick return the envelopes to deliver, so the same
+// state machine runs behind a network transport or inside a simulator. The protocol follows the three
+// phases of Castro and Liskov's PBFT (pre-prepare, prepare, commit) plus a simplified view change.
+type Replica struct {
+    id              int32
+    peers           []int32
+    f               int             // Number of Byzantine replicas tolerated: n = 3f + 1.
+    policy          rotation.Policy // Chooses the primary of each view.
+    quorum          quorum.Quorum   // Replicas whose matching votes prepare or commit a block, or start a view.
+    viewChangeTicks int
+    backoff         float64 // Factor a view change grows the timeout by; 1 for a fixed timeout.
+    decay           float64 // Factor an executed request shrinks the timeout by.
+    maxTimeout      int     // Ceiling of the timeout, in ticks.
+    clock           clock.Clock
+    logger          *slog.Logger
+
+    view     uint64                     // Current view; determines the primary.
+    sequence int64                      // Primary only: last sequence number assigned.
+    chain    []*wire.Block              // Executed blocks; chain[i] was executed at sequence i.
+    log      map[int64]*slot            // Agreement state per sequence number.
+    pending  []string                   // Client requests seen but not yet executed.
+    prepared map[int64]*wire.PrePrepare // Latest PrePrepare prepared at each unexecuted sequence, kept across views.
+
+    timer        int                                  // Ticks since progress was last made on the oldest pending request.
+    timeout      int                                  // Ticks the timer may reach before the primary is suspected.
+    viewChanging bool                                 // Set while waiting for a NewView message.
+    targetView   uint64                               // View this replica is trying to move to.
+    viewChanges  map[uint64]map[int32]*wire.ViewChange // ViewChange messages received per proposed view.
+    future       []*wire.Envelope                     // Messages for views this replica has not entered yet.
+}
+
+// GenesisBlock returns the genesis block shared by every replica.
+// Replicas may run in different processes, so the genesis block cannot depend on the local clock.
+func GenesisBlock() Block {
+    genesis := Block{Index: 0, Data: "Genesis Block"}
+    genesis.Hash = genesis.CalculateHash()
+    return genesis
+}
+
+// NewReplica creates a replica in view 0 whose chain contains only the genesis block.
+func NewReplica(cfg ReplicaConfig) *Replica {
+    if cfg.ViewChangeTicks <= 0 {
+        cfg.ViewChangeTicks = 20
+    }
+    if cfg.Backoff < 1 {
+        cfg.Backoff = 1
+    }
+    if cfg.Decay <= 0 || cfg.Decay >= 1 {
+        cfg.Decay = 0.9
+    }
+    if cfg.MaxViewChangeTicks <= 0 {
+        cfg.MaxViewChangeTicks = 16 * cfg.ViewChangeTicks
+    }
+    if cfg.Rotation == nil {
+        cfg.Rotation = rotation.RoundRobin
+    }
+    if cfg.Quorum == nil {
+        cfg.Quorum = quorum.Byzantine
+    }
+    genesis := GenesisBlock()
+    return &Replica{
+        id:              cfg.ID,
+        peers:           cfg.Peers,
+        f:               (len(cfg.Peers) - 1) / 3,
+        policy:          cfg.Rotation(cfg.Peers),
+        quorum:          cfg.Quorum(cfg.Peers),
+        viewChangeTicks: cfg.ViewChangeTicks,
+        backoff:         cfg.Backoff,
+        decay:           cfg.Decay,
+        maxTimeout:      max(cfg.MaxViewChangeTicks, cfg.ViewChangeTicks),
+        timeout:         cfg.ViewChangeTicks,
+        clock:           clock.Or(cfg.Clock),
+        logger:          logging.Scope(cfg.Logger, "pbft", cfg.ID),
+        chain:           []*wire.Block{genesis.ToWire()},
+        log:             make(map[int64]*slot),
+        prepared:        make(map[int64]*wire.PrePrepare),
+        viewChanges:     make(map[uint64]map[int32]*wire.ViewChange),
+    }
+}
+
+// ID returns the replica's identifier.
+func (r *Replica) ID() int32 {
+    return r.id
+}
+
+// View returns the replica's current view.
+func (r *Replica) View() uint64 {
+    return r.view
+}
+
+// Primary returns the identifier of the primary of the replica's current view.
+func (r *Replica) Primary() int32 {
+    return r.primaryOf(r.view)
+}
+
+// Leader returns the primary the replica follows, or -1 while it is changing views and does not know the next one.
+func (r *Replica) Leader() int32 {
+    if r.viewChanging {
+        return noReplica
+    }
+    return r.Primary()
+}
+
+// Timeout returns the ticks the replica currently waits for a pending request to execute before suspecting the
+// primary: ViewChangeTicks, unless a Backoff has grown it across view changes that did not execute anything.
+func (r *Replica) Timeout() int {
+    return r.timeout
+}
+
+// IsPrimary reports whether this replica is the primary of its current view.
+func (r *Replica) IsPrimary() bool {
+    return r.Primary() == r.id
+}
+
+// Committed returns the executed blocks, starting with the genesis block.
+// The returned blocks are shared with the replica and must not be modified.
+func (r *Replica) Committed() []*wire.Block {
+    return r.chain
+}
+
+// Propose submits a client request. The primary assigns it a sequence number straight away;
+// a backup forwards it to the primary and starts its timer, so a silent primary is eventually replaced.
+// A request this replica has already ordered or executed is ignored.
+func (r *Replica) Propose(data string) ([]*wire.Envelope, error) {
+    if r.isOrdered(data) {
+        return nil, nil
+    }
+    r.addPending(data)
+    if r.IsPrimary() && !r.viewChanging {
+        return r.prePrepare(data), nil
+    }
+    request := &wire.Request{Data: data}
+    return []*wire.Envelope{{From: r.id, To: r.Primary(), Body: &wire.Envelope_Request{Request: request}}}, nil
+}
+
+// Tick advances the replica's logical clock. A replica with pending work that makes no progress
+// for Timeout ticks suspects the primary and asks for the next view.
+func (r *Replica) Tick() []*wire.Envelope {
+    if !r.viewChanging && len(r.pending) == 0 && !r.hasUnexecuted() {
+        r.timer = 0
+        return nil // Nothing to wait for, nothing to suspect.
+    }
+    r.timer++
+    if r.timer < r.timeout {
+        return nil
+    }
+    next := r.view + 1
+    if r.viewChanging {
+        next = r.targetView + 1 // The view change itself stalled: skip to the following primary.
+    }
+    return r.startViewChange(next)
+}
+
+// Step processes one envelope addressed to this replica and returns the envelopes to send in response.
+func (r *Replica) Step(env *wire.Envelope) []*wire.Envelope {
+    switch env.GetBody().(type) {
+    case *wire.Envelope_PrePrepare, *wire.Envelope_Prepare, *wire.Envelope_Commit, *wire.Envelope_ViewChange, *wire.Envelope_NewView:
+        cost.Count(cost.Verify, 1) // A deployment checks the sender's signature on every protocol message (see package cost).
+    }
+    switch body := env.GetBody().(type) {
+    case *wire.Envelope_Request:
+        return r.handleRequest(body.Request)
+    case *wire.Envelope_PrePrepare:
+        return r.deferOrHandle(env, body.PrePrepare.GetView(), func() []*wire.Envelope {
+            return r.handlePrePrepare(env.GetFrom(), body.PrePrepare)
+        })
+    case *wire.Envelope_Prepare:
+        return r.deferOrHandle(env, body.Prepare.GetView(), func() []*wire.Envelope {
+            return r.handlePrepare(env.GetFrom(), body.Prepare)
+        })
+    case *wire.Envelope_Commit:
+        return r.deferOrHandle(env, body.Commit.GetView(), func() []*wire.Envelope {
+            return r.handleCommit(env.GetFrom(), body.Commit)
+        })
+    case *wire.Envelope_ViewChange:
+        return r.handleViewChange(body.ViewChange)
+    case *wire.Envelope_NewView:
+        return r.handleNewView(env.GetFrom(), body.NewView)
+    }
+    return nil // Not a PBFT message; ignore it.
+}
+
+// deferOrHandle runs handle for messages of the current view, buffers messages of future views until
+// the replica enters them, and drops messages of past views.
+func (r *Replica) deferOrHandle(env *wire.Envelope, view uint64, handle func() []*wire.Envelope) []*wire.Envelope {
+    switch {
+    case view > r.view || (view == r.view && r.viewChanging):
+        r.future = append(r.future, env)
+        return nil
+    case view < r.view:
+        return nil
+    }
+    return handle()
+}
+
+// handleRequest lets the primary order a request forwarded by a backup.
+func (r *Replica) handleRequest(m *wire.Request) []*wire.Envelope {
+    if !r.IsPrimary() || r.viewChanging || r.isPending(m.GetData()) || r.isOrdered(m.GetData()) {
+        return nil // Only the primary orders requests, and each request only once, even when a backup retransmits it.
+    }
+    r.addPending(m.GetData())
+    return r.prePrepare(m.GetData())
+}
+
+// prePrepare assigns the next sequence number to a new block and broadcasts it to the backups.
+func (r *Replica) prePrepare(data string) []*wire.Envelope {
+    r.sequence++
+    prev := r.blockAt(r.sequence - 1)
+    block := NewBlockAt(data, prev.Sum(), int(r.sequence), r.clock.Now()) // Chain onto the block at the previous sequence.
+    m := &wire.PrePrepare{View: r.view, Sequence: r.sequence, Digest: block.Hash.String(), Block: block.ToWire()}
+
+    s := r.slotAt(r.sequence)
+    s.prePrepare = m
+    r.logger.Debug("sent pre-prepare", "view", r.view, "sequence", r.sequence, "digest", block.Hash.String())
+    return r.broadcast(&wire.Envelope{Body: &wire.Envelope_PrePrepare{PrePrepare: m}})
+}
+
+// handlePrePrepare accepts the primary's ordering if it is well formed and does not conflict with an
+// earlier PrePrepare for the same sequence, then broadcasts a Prepare for it.
+func (r *Replica) handlePrePrepare(from int32, m *wire.PrePrepare) []*wire.Envelope {
+    if from != r.Primary() || !r.validPrePrepare(m) {
+        return nil
+    }
+    s := r.slotAt(m.GetSequence())
+    if s.prePrepare != nil && s.prePrepare.GetDigest() != m.GetDigest() {
+        r.logger.Warn("primary equivocated", "primary", from, "view", r.view, "sequence", m.GetSequence())
+        return nil // The primary is equivocating; keep the first assignment and let the timer expire.
+    }
+    s.prePrepare = m
+    s.prepares[r.id] = m.GetDigest()
+
+    prepare := &wire.Prepare{View: r.view, Sequence: m.GetSequence(), Digest: m.GetDigest()}
+    out := r.broadcast(&wire.Envelope{Body: &wire.Envelope_Prepare{Prepare: prepare}})
+    return append(out, r.tryPrepared(m.GetSequence())...)
+}
+
+// validPrePrepare checks that the block hash matches the digest, that the block sits at the sequence
+// it was assigned to, and that it extends the block this replica knows at the previous sequence.
+func (r *Replica) validPrePrepare(m *wire.PrePrepare) bool {
+    block := BlockFromWire(m.GetBlock())
+    if m.GetBlock() == nil || block.Hash.String() != m.GetDigest() || block.Hash != block.CalculateHash() {
+        return false
+    }
+    if int64(block.Index) != m.GetSequence() || m.GetSequence() < 1 {
+        return false
+    }
+    if executed := r.executedAt(m.GetSequence()); executed != nil {
+        return executed.GetHash() == m.GetDigest() // Already executed: only the same block may be re-ordered.
+    }
+    if prev := r.blockAt(m.GetSequence() - 1); prev != nil && prev.Sum() != block.PrevHash {
+        return false
+    }
+    return true
+}
+
+// handlePrepare records a Prepare and checks whether the sequence is now prepared.
+func (r *Replica) handlePrepare(from int32, m *wire.Prepare) []*wire.Envelope {
+    s := r.slotAt(m.GetSequence())
+    s.prepares[from] = m.GetDigest()
+    return r.tryPrepared(m.GetSequence())
+}
+
+// tryPrepared sends a Commit once the replica holds the PrePrepare and matching Prepares from enough backups
+// that, with the primary, they form a quorum: 2f of them by default.
+func (r *Replica) tryPrepared(sequence int64) []*wire.Envelope {
+    s := r.slotAt(sequence)
+    if s.prePrepare == nil || s.prepared {
+        return nil
+    }
+    if !r.quorum.Reached(append(r.matching(s.prepares, s.prePrepare.GetDigest(), r.Primary()), r.Primary())) {
+        return nil
+    }
+    s.prepared = true
+    s.commits[r.id] = s.prePrepare.GetDigest()
+    r.prepared[sequence] = s.prePrepare
+    r.logger.Debug("prepared", "view", r.view, "sequence", sequence, "digest", s.prePrepare.GetDigest())
+
+    commit := &wire.Commit{View: r.view, Sequence: sequence, Digest: s.prePrepare.GetDigest()}
+    out := r.broadcast(&wire.Envelope{Body: &wire.Envelope_Commit{Commit: commit}})
+    return append(out, r.tryExecute()...)
+}
+
+// handleCommit records a Commit and executes every sequence that is now committed.
+func (r *Replica) handleCommit(from int32, m *wire.Commit) []*wire.Envelope {
+    s := r.slotAt(m.GetSequence())
+    s.commits[from] = m.GetDigest()
+    return r.tryExecute()
+}
+
+// tryExecute appends committed blocks to the chain strictly in sequence order.
+// A sequence is committed once it is prepared locally and a quorum, 2f+1 replicas by default, committed the same
+// digest.
+func (r *Replica) tryExecute() []*wire.Envelope {
+    for {
+        next := int64(len(r.chain))
+        s, ok := r.log[next]
+        if !ok || !s.prepared || !r.quorum.Reached(r.matching(s.commits, s.prePrepare.GetDigest(), noReplica)) {
+            return nil
+        }
+        block := s.prePrepare.GetBlock()
+        if block.GetPrevHash() != r.chain[next-1].GetHash() {
+            return nil // Never execute a block that does not extend our chain.
+        }
+        block = proto.Clone(block).(*wire.Block) // The pre-prepared block may be shared with other replicas.
+        block.Certificate = r.certificate(s.commits, s.prePrepare.GetDigest())
+        r.chain = append(r.chain, block)
+        delete(r.prepared, next) // From now on the executed block stands for the sequence.
+        r.logger.Info("executed block", "view", r.view, "sequence", next, "hash", block.GetHash())
+        waited := len(r.pending) > 0 && r.pending[0] == block.GetData()
+        r.removePending(block.GetData())
+        if waited || len(r.pending) == 0 {
+            r.timer = 0 // Progress was made on what we wait for; serving other clients would not count.
+        }
+        if waited {
+            r.adapt(r.decay) // The view works: try a little less patience next time.
+        }
+    }
+}
+
+// startViewChange stops accepting messages of the current view and broadcasts a ViewChange for view,
+// carrying every block this replica has executed or prepared so that committed blocks survive the change.
+// Entering a view discards the log, so both come from state that outlives it: the chain, and the prepared
+// certificates of any view. Without them a block committed by some replicas could be lost when the view
+// changes again before it is prepared anew, and a new primary would assign its sequence to another block.
+func (r *Replica) startViewChange(view uint64) []*wire.Envelope {
+    r.viewChanging = true
+    r.targetView = view
+    r.timer = 0
+    r.adapt(r.backoff) // The view failed, perhaps because the timeout was too short for the network.
+    r.logger.Info("started view change", "view", r.view, "new_view", view, "timeout", r.timeout)
+
+    vc := &wire.ViewChange{NewView: view, LastSequence: int64(len(r.chain) - 1), ReplicaId: r.id}
+    for sequence, block := range r.chain[1:] {
+        block = proto.Clone(block).(*wire.Block)
+        block.Certificate = nil // The certificate is local to this replica and not part of the ordering.
+        vc.Prepared = append(vc.Prepared, &wire.PrePrepare{View: r.view, Sequence: int64(sequence + 1), Digest: block.GetHash(), Block: block})
+    }
+    for _, sequence := range r.preparedSequences() {
+        vc.Prepared = append(vc.Prepared, r.prepared[sequence])
+    }
+
+    out := r.broadcast(&wire.Envelope{Body: &wire.Envelope_ViewChange{ViewChange: vc}})
+    return append(out, r.handleViewChange(vc)...) // Count our own ViewChange.
+}
+
+// handleViewChange collects ViewChange messages. f+1 requests for a higher view make this replica join the
+// view change; 2f+1 let the primary of the new view announce it.
+func (r *Replica) handleViewChange(m *wire.ViewChange) []*wire.Envelope {
+    view := m.GetNewView()
+    if view <= r.view {
+        return nil
+    }
+    if r.viewChanges[view] == nil {
+        r.viewChanges[view] = make(map[int32]*wire.ViewChange)
+    }
+    r.viewChanges[view][m.GetReplicaId()] = m
+
+    var out []*wire.Envelope
+    if (!r.viewChanging || r.targetView < view) && len(r.viewChanges[view]) >= r.f+1 {
+        // Enough replicas suspect the primary that one of them is correct; join them. This is not a quorum: it
+        // only needs to outnumber the faulty replicas, whatever the quorum.
+        out = append(out, r.startViewChange(view)...)
+    }
+    if r.viewChanging && r.targetView == view && r.quorum.Reached(slices.Collect(maps.Keys(r.viewChanges[view]))) && r.primaryOf(view) == r.id {
+        out = append(out, r.announceNewView(view)...)
+    }
+    return out
+}
+
+// announceNewView is run by the primary of the new view. It re-issues, in the new view, every block that
+// may have been committed in an earlier view, then orders any requests that are still pending.
+func (r *Replica) announceNewView(view uint64) []*wire.Envelope {
+    certificates := r.viewChanges[view]
+    m := &wire.NewView{View: view}
+    low := int64(-1)
+    prepared := make(map[int64]*wire.PrePrepare)
+    for _, replica := range r.peers { // Iterate in peer order so the announcement is deterministic.
+        vc, ok := certificates[replica]
+        if !ok {
+            continue
+        }
+        m.ViewChanges = append(m.ViewChanges, vc)
+        if low < 0 || vc.GetLastSequence() < low {
+            low = vc.GetLastSequence()
+        }
+        for _, pp := range vc.GetPrepared() {
+            if current, ok := prepared[pp.GetSequence()]; !ok || pp.GetView() > current.GetView() {
+                prepared[pp.GetSequence()] = pp // Prefer the certificate from the latest view.
+            }
+        }
+    }
+    prev := r.chain[low] // Every replica in the quorum, this one included, has executed up to low.
+    for sequence := low + 1; ; sequence++ {
+        pp, ok := prepared[sequence]
+        if !ok || pp.GetBlock().GetPrevHash() != prev.GetHash() {
+            // Sequences past a gap cannot have been executed anywhere, and neither can a block prepared in an
+            // older view that does not extend the block chosen before it: execution follows the chain.
+            break
+        }
+        m.PrePrepares = append(m.PrePrepares, &wire.PrePrepare{View: view, Sequence: sequence, Digest: pp.GetDigest(), Block: pp.GetBlock()})
+        prev = pp.GetBlock()
+    }
+
+    r.logger.Info("announced new view", "view", view, "reissued", len(m.GetPrePrepares()))
+    out := r.broadcast(&wire.Envelope{Body: &wire.Envelope_NewView{NewView: m}})
+    return append(out, r.enterView(view, m.GetPrePrepares())...)
+}
+
+// handleNewView moves a backup into the new view announced by its primary.
+func (r *Replica) handleNewView(from int32, m *wire.NewView) []*wire.Envelope {
+    var senders []int32
+    for _, vc := range m.GetViewChanges() {
+        senders = append(senders, vc.GetReplicaId())
+    }
+    if m.GetView() <= r.view || !r.quorum.Reached(senders) || from != r.primaryOf(m.GetView()) {
+        return nil
+    }
+    return r.enterView(m.GetView(), m.GetPrePrepares())
+}
+
+// enterView installs a new view: agreement state that did not lead to execution is discarded, the re-issued
+// PrePrepares are processed as if the new primary had just sent them, requests still pending are handed to
+// the new primary, and buffered messages are replayed.
+func (r *Replica) enterView(view uint64, prePrepares []*wire.PrePrepare) []*wire.Envelope {
+    r.view = view
+    r.viewChanging = false
+    r.timer = 0
+    r.logger.Info("entered view", "view", view, "primary", r.primaryOf(view))
+    r.log = make(map[int64]*slot)
+    for v := range r.viewChanges {
+        if v <= view {
+            delete(r.viewChanges, v)
+        }
+    }
+
+    var out []*wire.Envelope
+    r.sequence = int64(len(r.chain) - 1)
+    reissued := make(map[string]bool)
+    for _, pp := range prePrepares {
+        reissued[pp.GetBlock().GetData()] = true
+        r.sequence = max(r.sequence, pp.GetSequence())
+        if r.IsPrimary() {
+            r.slotAt(pp.GetSequence()).prePrepare = pp
+        } else {
+            out = append(out, r.handlePrePrepare(r.Primary(), pp)...)
+        }
+    }
+    for _, data := range append([]string(nil), r.pending...) {
+        switch {
+        case reissued[data]:
+        case r.IsPrimary():
+            out = append(out, r.prePrepare(data)...) // Order requests the old primary never got to.
+        default:
+            // The new primary may never have seen this request, e.g. if it was partitioned away when the
+            // request arrived; forward it as a client would retransmit it.
+            request := &wire.Request{Data: data}
+            out = append(out, &wire.Envelope{From: r.id, To: r.Primary(), Body: &wire.Envelope_Request{Request: request}})
+        }
+    }
+
+    future := r.future
+    r.future = nil
+    for _, env := range future {
+        out = append(out, r.Step(env)...)
+    }
+    return out
+}
+
+// adapt multiplies the timeout by factor, keeping it between ViewChangeTicks and the ceiling. A backoff rounds
+// up and a decay rounds down, so a short timeout still moves: rounding 1.25 ticks to the nearest would leave a
+// timeout of one tick at one tick however many views failed.
+func (r *Replica) adapt(factor float64) {
+    if r.backoff == 1 {
+        return
+    }
+    scaled := float64(r.timeout) * factor
+    timeout := int(math.Floor(scaled))
+    if factor > 1 {
+        timeout = int(math.Ceil(scaled))
+    }
+    r.timeout = min(max(timeout, r.viewChangeTicks), r.maxTimeout)
+}
+
+// noReplica is passed to matching when no replica should be excluded.
+const noReplica int32 = -1
+
+// matching returns the replicas (other than exclude) that voted for digest.
+func (r *Replica) matching(votes map[int32]string, digest string, exclude int32) []int32 {
+    var voters []int32
+    for replica, d := range votes {
+        if replica != exclude && d == digest {
+            voters = append(voters, replica)
+        }
+    }
+    return voters
+}
+
+// certificate returns the names of the replicas that committed digest, in order, as proof that a block reached
+// a quorum.
+func (r *Replica) certificate(commits map[int32]string, digest string) []string {
+    var names []string
+    for _, peer := range r.peers {
+        if commits[peer] == digest {
+            names = append(names, "node-"+strconv.Itoa(int(peer)))
+        }
+    }
+    return names
+}
+
+// broadcast sends the body of msg to every peer except this replica, one envelope per peer. Every message a
+// replica broadcasts is a protocol message a deployment signs.
+func (r *Replica) broadcast(msg *wire.Envelope) []*wire.Envelope {
+    cost.Count(cost.Sign, 1) // A deployment signs the body once, whatever the number of peers (see package cost).
+    var out []*wire.Envelope
+    for _, peer := range r.peers {
+        if peer != r.id {
+            out = append(out, &wire.Envelope{From: r.id, To: peer, Body: msg.GetBody()})
+        }
+    }
+    return out
+}
+
+// slotAt returns the agreement state for sequence, creating it on first use.
+func (r *Replica) slotAt(sequence int64) *slot {
+    s, ok := r.log[sequence]
+    if !ok {
+        s = &slot{prepares: make(map[int32]string), commits: make(map[int32]string)}
+        r.log[sequence] = s
+    }
+    return s
+}
+
+// preparedSequences returns the sequence numbers this replica holds a prepared certificate for, in ascending order.
+func (r *Replica) preparedSequences() []int64 {
+    sequences := make([]int64, 0, len(r.prepared))
+    for sequence := range r.prepared {
+        sequences = append(sequences, sequence)
+    }
+    sort.Slice(sequences, func(i, j int) bool { return sequences[i] < sequences[j] })
+    return sequences
+}
+
+// executedAt returns the executed block at sequence, or nil if it has not been executed.
+func (r *Replica) executedAt(sequence int64) *wire.Block {
+    if sequence >= 0 && sequence < int64(len(r.chain)) {
+        return r.chain[sequence]
+    }
+    return nil
+}
+
+// blockAt returns the block this replica knows at sequence: executed if possible, otherwise pre-prepared.
+func (r *Replica) blockAt(sequence int64) *wire.Block {
+    if block := r.executedAt(sequence); block != nil {
+        return block
+    }
+    if s, ok := r.log[sequence]; ok && s.prePrepare != nil {
+        return s.prePrepare.GetBlock()
+    }
+    return nil
+}
+
+// hasUnexecuted reports whether some accepted PrePrepare is still waiting to be executed.
+func (r *Replica) hasUnexecuted() bool {
+    for sequence, s := range r.log {
+        if s.prePrepare != nil && sequence >= int64(len(r.chain)) {
+            return true
+        }
+    }
+    return false
+}
+
+// primaryOf returns the primary of view. A replica only moves to a later view once every view before it failed,
+// so asking about one reports those views to the rotation policy first, which a sticky policy needs to hand over.
+func (r *Replica) primaryOf(view uint64) int32 {
+    for failed := r.view; failed < view; failed++ {
+        r.policy.Fail(failed)
+    }
+    return r.policy.Leader(view)
+}
+
+// isPending reports whether data is a request that has not been executed yet.
+func (r *Replica) isPending(data string) bool {
+    for _, p := range r.pending {
+        if p == data {
+            return true
+        }
+    }
+    return false
+}
+
+// isOrdered reports whether data is already executed or assigned a sequence number in the current view, so that
+// a request re-issued by a view change or retransmitted by a backup does not end up in the chain twice.
+func (r *Replica) isOrdered(data string) bool {
+    for _, block := range r.chain[1:] {
+        if block.GetData() == data {
+            return true
+        }
+    }
+    for _, s := range r.log {
+        if s.prePrepare != nil && s.prePrepare.GetBlock().GetData() == data {
+            return true
+        }
+    }
+    return false
+}
+
+// addPending records a request unless it is already pending.
+// Requests are identified by their data, so submitting identical data twice orders it only once.
+func (r *Replica) addPending(data string) {
+    if !r.isPending(data) {
+        r.pending = append(r.pending, data)
+    }
+}
+
+// removePending forgets a request once its block has been executed.
+func (r *Replica) removePending(data string) {
+    for i, p := range r.pending {
+        if p == data {
+            r.pending = append(r.pending[:i], r.pending[i+1:]...)
+            return
+        }
+    }
+}
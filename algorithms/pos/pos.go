@@ -7,39 +7,75 @@
 package pos
 
 import (
-    "crypto/sha256"
+    "context"
+    "errors"
     "fmt"
+    "io"
+    "iter"
+    "log/slog"
+    "maps"
     "math/rand"
-    "strconv"
+    "slices"
+    "sync"
     "time"
+
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/events"
+    "consensus-algorithms-edu/feed"
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/logging"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/storage"
+    "consensus-algorithms-edu/wire"
 )
 
+// ErrNoStake is returned by AddBlock when no validator holds any stake, so none can be selected.
+var ErrNoStake = errors.New("pos: no validator holds stake")
+
 // Block represents an individual block in the blockchain.
 // It contains critical information such as the block index, timestamp, data, cryptographic hashes,
 // and the validator who proposed the block.
 type Block struct {
-    Index     int    // The position of the block in the blockchain.
-    Timestamp string // The time when the block was created.
-    Data      string // The transaction or arbitrary data contained in the block.
-    PrevHash  string // The hash of the previous block to ensure immutability.
-    Hash      string // SHA-256 hash of the current block's contents.
-    Validator string // The validator responsible for validating and adding this block.
+    Index        int            // The position of the block in the blockchain.
+    Timestamp    wire.Timestamp // When the block was created, in nanoseconds since the Unix epoch.
+    Data         string         // The transaction or arbitrary data contained in the block.
+    PrevHash     wire.Hash      // The hash of the previous block to ensure immutability.
+    Hash         wire.Hash      // SHA-256 hash of the current block's contents.
+    Validator    string         // The validator responsible for validating and adding this block.
+    StateRoot    wire.Hash      // Root of the attached ledger state after the block; the zero Hash if none was attached.
+    ReceiptsRoot wire.Hash      // Root of the receipts of its transactions; the zero Hash if it has none or none was attached.
 }
 
 // Blockchain represents the state of the distributed ledger.
 // It contains the chain of blocks, a list of validators, and a map of stakes held by validators.
 type Blockchain struct {
-    Blocks     []Block          // A slice of all blocks in the blockchain.
-    Validators []string         // A list of validator nodes eligible to propose blocks.
-    Stakes     map[string]int   // A map of validators to their respective stake values.
+    mu          sync.Mutex                // Guards every field while a method runs.
+    Blocks      []Block                   // A slice of all blocks in the blockchain.
+    Validators  []string                  // A list of validator nodes eligible to propose blocks.
+    Stakes      map[string]int            // A map of validators to their respective stake values.
+    blockStore  storage.BlockStore        // Optional block store that every appended block is written through to.
+    state       ledger.State              // Checks the transactions of appended blocks and applies them; blocks are not interpreted if nil.
+    receipts    map[string]ledger.Receipt // Receipts of the transactions of appended blocks by hash, if the state records them.
+    clock       clock.Clock               // Clock that timestamps appended blocks; the system clock if nil.
+    logger      *slog.Logger              // Logger that records consensus steps; silent if nil.
+    publisher   events.Publisher          // Receives votes and leader changes; nothing is published if nil.
+    subscribers feed.Feed[Block]          // Delivers every appended block to Subscribe's channels.
+    random      *options.Rand             // Source of validator selection; the shared math/rand source if nil.
 }
 
 // NewBlock creates a new Block given data, the previous block's hash, the index, and the validator's ID.
 // It calculates the cryptographic hash of the block to ensure its integrity.
-func NewBlock(data string, prevHash string, index int, validator string) Block {
+func NewBlock(data string, prevHash wire.Hash, index int, validator string) Block {
+    return NewBlockAt(data, prevHash, index, validator, clock.System.Now())
+}
+
+// NewBlockAt is NewBlock with an explicit creation time, for callers that keep their own clock such as a
+// simulation.
+func NewBlockAt(data string, prevHash wire.Hash, index int, validator string, at time.Time) Block {
     block := Block{
         Index:     index,
-        Timestamp: time.Now().String(), // Set the block's timestamp to the current time.
+        Timestamp: wire.TimestampOf(at),
         Data:      data,
         PrevHash:  prevHash,
         Validator: validator,
@@ -48,42 +84,286 @@ func NewBlock(data string, prevHash string, index int, validator string) Block {
     return block
 }
 
-// CalculateHash generates the SHA-256 hash of the block's contents.
+// CalculateHash generates the SHA-256 hash of the block, which is the hash of its header.
 // This ensures immutability; any change to the block's contents results in a different hash.
-func (b *Block) CalculateHash() string {
-    record := strconv.Itoa(b.Index) + b.Timestamp + b.Data + b.PrevHash + b.Validator
-    hash := sha256.New()                // Create a new SHA-256 hash object.
-    hash.Write([]byte(record))          // Write the concatenated block data to the hash object.
-    hashed := hash.Sum(nil)             // Compute the final hash value.
-    return fmt.Sprintf("%x", hashed)    // Return the hash as a hexadecimal string.
+func (b *Block) CalculateHash() wire.Hash {
+    h := b.Header()
+    return h.Hash()
+}
+
+// Header returns the part of the block its hash covers, which commits to the data through its root.
+// The validator is committed to as its ProducerID, so a block cannot be credited to another validator.
+func (b *Block) Header() wire.Header {
+    return wire.Header{
+        Index:     int64(b.Index),
+        Timestamp: b.Timestamp,
+        Parent:    b.PrevHash,
+        Root:      wire.BodyRoot(b.Data),
+        Producer:  wire.ProducerID(b.Validator),
+        State:     b.StateRoot,
+        Receipts:  b.ReceiptsRoot,
+    }
+}
+
+// ToWire converts the block into the algorithm-neutral wire format used for messaging and storage.
+// The validator is carried in the Producer field.
+func (b *Block) ToWire() *wire.Block {
+    return &wire.Block{
+        Index:        int64(b.Index),
+        Timestamp:    int64(b.Timestamp),
+        Data:         b.Data,
+        PrevHash:     b.PrevHash.String(),
+        Hash:         b.Hash.String(),
+        Producer:     b.Validator,
+        StateRoot:    b.StateRoot.String(),
+        ReceiptsRoot: b.ReceiptsRoot.String(),
+    }
 }
 
-// AddBlock adds a new block to the blockchain.
+// BlockFromWire converts a wire-format block back into this package's Block type.
+// The hash is copied as-is rather than recalculated, so callers can still detect tampered blocks.
+func BlockFromWire(w *wire.Block) Block {
+    return Block{
+        Index:        int(w.GetIndex()),
+        Timestamp:    wire.Timestamp(w.GetTimestamp()),
+        Data:         w.GetData(),
+        PrevHash:     w.ParentSum(),
+        Hash:         w.Sum(),
+        Validator:    w.GetProducer(),
+        StateRoot:    w.StateSum(),
+        ReceiptsRoot: w.ReceiptsSum(),
+    }
+}
+
+// AddBlock adds a new block to the blockchain and returns it.
 // It selects a validator based on their stake, creates a new block, and appends it to the blockchain.
-func (bc *Blockchain) AddBlock(data string) {
-    prevBlock := bc.Blocks[len(bc.Blocks)-1]          // Retrieve the latest block in the blockchain.
-    validator := bc.SelectValidator()                 // Select a validator based on their stake.
-    newBlock := NewBlock(data, prevBlock.Hash, prevBlock.Index+1, validator) // Create the new block.
-    bc.Blocks = append(bc.Blocks, newBlock)           // Append the newly created block to the blockchain.
+// If no validator holds stake, ErrNoStake is returned. If a block store is attached, the block is written to it
+// first and any storage error is returned. If a state is attached (see AttachState) and the data breaks its rules,
+// an error wrapping ledger.ErrInvalid is returned and nothing is added.
+func (bc *Blockchain) AddBlock(data string) (Block, error) {
+    return bc.AddBlockContext(context.Background(), data)
+}
+
+// AddBlockContext is AddBlock that returns the context's error, adding nothing, if ctx is already done.
+// Records logged while the block is added carry the context's trace, if it has one (see logging.WithTrace).
+func (bc *Blockchain) AddBlockContext(ctx context.Context, data string) (Block, error) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    if err := ctx.Err(); err != nil {
+        return Block{}, err
+    }
+    if err := bc.checkState(data); err != nil {
+        return Block{}, err // Refuse the data before a validator is selected for it.
+    }
+    prevBlock := bc.head()                               // Retrieve the latest block in the blockchain.
+    validator := bc.selectValidator(ctx)                 // Select a validator based on their stake.
+    if validator == "" {
+        return Block{}, ErrNoStake
+    }
+    if validator != prevBlock.Validator {
+        bc.publish(events.Event{Type: events.LeaderChange, Node: validator, Leader: validator, Height: int64(prevBlock.Index + 1)})
+    }
+    newBlock := NewBlockAt(data, prevBlock.Hash, prevBlock.Index+1, validator, clock.Or(bc.clock).Now()) // Create the new block.
+    if bc.state != nil {
+        newBlock.StateRoot, newBlock.ReceiptsRoot = bc.roots(data)
+        newBlock.Hash = newBlock.CalculateHash() // The hash commits to the state and receipts the block leaves.
+    }
+    if err := bc.appendBlock(ctx, newBlock); err != nil {  // Append the new block, writing it through to the block store.
+        return Block{}, err
+    }
+    return newBlock, nil
+}
+
+// appendBlock writes the block through to the attached block store, if any, and appends it to the chain.
+// The block only becomes part of the in-memory chain once the store has accepted it.
+func (bc *Blockchain) appendBlock(ctx context.Context, block Block) error {
+    receipts, err := bc.checkBlock(block)
+    if err != nil {
+        return fmt.Errorf("block %d: %w", block.Index, err)
+    }
+    if bc.blockStore != nil {
+        if err := bc.blockStore.Put(block.ToWire()); err != nil {
+            return err
+        }
+    }
+    bc.Blocks = append(bc.Blocks, block)
+    if bc.state != nil {
+        bc.state.Apply(block.Data) // Checked above, under the same lock.
+        bc.indexReceipts(block.Index, receipts)
+    }
+    bc.subscribers.Publish(block)
+    logging.Or(bc.logger).InfoContext(ctx, "committed block", "index", block.Index, "hash", block.Hash.String())
+    return nil
+}
+
+// Snapshot returns a copy of the blocks in the chain, which stays unchanged while other goroutines add blocks.
+func (bc *Blockchain) Snapshot() []Block {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return append([]Block(nil), bc.Blocks...)
+}
+// Height returns the index of the chain's last block: the number of blocks added after the genesis block.
+func (bc *Blockchain) Height() int {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return len(bc.Blocks) - 1
+}
+
+// Head returns the chain's last block.
+func (bc *Blockchain) Head() Block {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return bc.head()
+}
+
+// head returns the chain's last block. The caller must hold bc.mu.
+func (bc *Blockchain) head() Block {
+    return bc.Blocks[len(bc.Blocks)-1]
+}
+
+// GetBlockByIndex returns the block at index, or false if the chain has no block there.
+func (bc *Blockchain) GetBlockByIndex(index int) (Block, bool) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    if index < 0 || index >= len(bc.Blocks) {
+        return Block{}, false
+    }
+    return bc.Blocks[index], true
+}
+
+// GetBlockByHash returns the block with the given hash, or false if the chain has none. The chain is searched
+// from its head, since recent blocks are the ones most often looked up.
+func (bc *Blockchain) GetBlockByHash(hash wire.Hash) (Block, bool) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    for i := len(bc.Blocks) - 1; i >= 0; i-- {
+        if bc.Blocks[i].Hash == hash {
+            return bc.Blocks[i], true
+        }
+    }
+    return Block{}, false
+}
+
+// All returns an iterator over the index and block of every block in the chain, starting at the genesis block.
+func (bc *Blockchain) All() iter.Seq2[int, Block] {
+    return bc.Range(0, -1)
+}
+
+// Range returns an iterator over the blocks from index from up to and including index to, or up to the head if
+// to is negative. It iterates over the blocks as they were when it started, so the loop body may add blocks.
+func (bc *Blockchain) Range(from, to int) iter.Seq2[int, Block] {
+    return func(yield func(int, Block) bool) {
+        bc.mu.Lock()
+        if to < 0 || to >= len(bc.Blocks) {
+            to = len(bc.Blocks) - 1
+        }
+        from := max(from, 0)
+        var blocks []Block
+        if from <= to {
+            blocks = slices.Clone(bc.Blocks[from : to+1])
+        }
+        bc.mu.Unlock()
+        for i, block := range blocks {
+            if !yield(from+i, block) {
+                return
+            }
+        }
+    }
+}
+
+// Subscribe returns a channel that receives every block appended to the chain from now on, in order, so that a
+// consumer can wait for new blocks rather than poll Blocks. Blocks replaced by Import are not delivered. Pass the
+// channel to Unsubscribe once done with it, which closes it.
+func (bc *Blockchain) Subscribe() <-chan Block {
+    return bc.subscribers.Subscribe()
+}
+
+// Unsubscribe stops delivering blocks to ch, a channel returned by Subscribe, and closes it.
+func (bc *Blockchain) Unsubscribe(ch <-chan Block) {
+    bc.subscribers.Unsubscribe(ch)
+}
+
+// String summarizes the chain in one line: its length and the block at its head.
+func (bc *Blockchain) String() string {
+    return wire.Summarize("pos", bc.wireBlocks())
+}
+
+// Dump formats every block of the chain for printing, one line per block.
+func (bc *Blockchain) Dump() string {
+    return wire.Dump(bc.wireBlocks())
+}
+
+// Diff reports the first height at which chains a and b hold different blocks, or at which one of them ends.
+func Diff(a, b *Blockchain) wire.Divergence {
+    return wire.Diff(a.wireBlocks(), b.wireBlocks())
+}
+
+// wireBlocks converts the chain's blocks to the shared wire format.
+func (bc *Blockchain) wireBlocks() []*wire.Block {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    blocks := make([]*wire.Block, len(bc.Blocks))
+    for i := range bc.Blocks {
+        blocks[i] = bc.Blocks[i].ToWire()
+    }
+    return blocks
+}
+
+// Clone returns an independent copy of the chain, with its own copies of the validators and their stakes, so that a simulation can fork a scenario mid-run and see
+// what happens next without changing the original: blocks added to either copy leave the other unchanged. The
+// copy keeps the original's clock, logger and event publisher but not its block store or its subscribers, and
+// gets its own copy of an attached state.
+func (bc *Blockchain) Clone() *Blockchain {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    c := &Blockchain{
+        Blocks:     slices.Clone(bc.Blocks),
+        Validators: slices.Clone(bc.Validators),
+        Stakes:     maps.Clone(bc.Stakes),
+        clock:      bc.clock,
+        logger:     bc.logger,
+        publisher:  bc.publisher,
+    }
+    if bc.random != nil {
+        c.random = bc.random.Clone() // A seeded copy selects the validators the original would have selected.
+    }
+    if bc.state != nil {
+        c.state = bc.state.Clone()
+        c.receipts = maps.Clone(bc.receipts)
+    }
+    return c
 }
 
 // SelectValidator selects a validator to propose the next block based on the stakes of each validator.
 // The probability of selection is directly proportional to the stake value.
 func (bc *Blockchain) SelectValidator() string {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return bc.selectValidator(context.Background())
+}
+
+func (bc *Blockchain) selectValidator(ctx context.Context) string {
     totalStake := 0
     // Calculate the total stake of all validators.
-    for _, stake := range bc.Stakes {
-        totalStake += stake
+    for _, validator := range bc.Validators {
+        totalStake += bc.Stakes[validator]
+    }
+
+    if totalStake <= 0 {
+        return "" // Nobody can be picked in proportion to no stake at all.
     }
 
     // Pick a random number in the range of [0, totalStake).
-    pick := rand.Intn(totalStake)
+    pick := bc.intn(totalStake)
     runningTotal := 0
 
-    // Iterate through the validators and accumulate their stakes until the random number is within a range.
-    for validator, stake := range bc.Stakes {
+    // Iterate through the validators in order, so a seeded network picks the same validators on every run,
+    // and accumulate their stakes until the random number is within a range.
+    for _, validator := range bc.Validators {
+        stake := bc.Stakes[validator]
         runningTotal += stake
         if runningTotal > pick {
+            logging.Or(bc.logger).InfoContext(ctx, "selected validator", logging.NodeKey, validator, "stake", stake, "total_stake", totalStake)
             return validator // The validator whose range contains 'pick' is selected.
         }
     }
@@ -91,15 +371,318 @@ func (bc *Blockchain) SelectValidator() string {
     return "" // This should never be reached if the logic above is correct.
 }
 
+// intn returns a random number in [0, n) from the network's own source if it was seeded.
+func (bc *Blockchain) intn(n int) int {
+    if bc.random == nil {
+        return rand.Intn(n)
+    }
+    return bc.random.Intn(n)
+}
+
 // NewBlockchain initializes a new blockchain with a list of validators and their respective stakes.
 // The blockchain starts with a genesis block, which is always the first block in the chain.
-func NewBlockchain(validators []string, stakes map[string]int) *Blockchain {
-    genesisBlock := NewBlock("Genesis Block", "", 0, validators[0]) // Create the genesis block.
-    return &Blockchain{
+// options.WithSeed makes the sequence of selected validators repeat from run to run; other options are ignored.
+func NewBlockchain(validators []string, stakes map[string]int, opts ...options.Option) *Blockchain {
+    o := options.New(opts...)
+    genesisBlock := NewBlock("Genesis Block", wire.Hash{}, 0, validators[0]) // Create the genesis block.
+    bc := &Blockchain{
         Blocks:     []Block{genesisBlock},  // Initialize with the genesis block.
         Validators: validators,             // Assign the provided list of validators.
         Stakes:     stakes,                 // Set up the validators' stakes.
     }
+    if o.Seeded {
+        bc.random = options.NewRand(o.Seed)
+    }
+    return bc
+}
+
+// AddValidator bonds stake for a new validator while the chain runs. There is no vote to hold: in Proof of
+// Stake, membership is the stake table, and the validator is eligible from the next block on, with a chance
+// of proposing it in proportion to its stake. A validator that is already bonded or a stake that is not
+// positive is refused.
+func (bc *Blockchain) AddValidator(validator string, stake int) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    if slices.Contains(bc.Validators, validator) {
+        return fmt.Errorf("pos: validator %q is already bonded", validator)
+    }
+    if stake <= 0 {
+        return fmt.Errorf("pos: stake of validator %q must be positive, got %d", validator, stake)
+    }
+    if bc.Stakes == nil {
+        bc.Stakes = make(map[string]int)
+    }
+    bc.Validators = append(bc.Validators, validator)
+    bc.Stakes[validator] = stake
+    logging.Or(bc.logger).Info("bonded validator", logging.NodeKey, validator, "stake", stake)
+    return nil
+}
+
+// RemoveValidator unbonds a validator's stake; it is not selected for any later block. Removing a validator
+// that is not bonded returns node.ErrUnknownNode, and removing the last one node.ErrLastNode.
+func (bc *Blockchain) RemoveValidator(validator string) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    i := slices.Index(bc.Validators, validator)
+    if i < 0 {
+        return fmt.Errorf("%w: %q", node.ErrUnknownNode, validator)
+    }
+    if len(bc.Validators) == 1 {
+        return node.ErrLastNode
+    }
+    bc.Validators = slices.Delete(slices.Clone(bc.Validators), i, i+1) // The slice may be shared with the caller of NewBlockchain.
+    delete(bc.Stakes, validator)
+    logging.Or(bc.logger).Info("unbonded validator", logging.NodeKey, validator)
+    return nil
+}
+
+// AttachBlockStore connects a block store to the blockchain. Every block already in the chain is written
+// to the store immediately, and every block appended afterwards is written through to it as well.
+func (bc *Blockchain) AttachBlockStore(store storage.BlockStore) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return bc.attachBlockStore(store)
+}
+
+func (bc *Blockchain) attachBlockStore(store storage.BlockStore) error {
+    for i := range bc.Blocks {
+        if err := store.Put(bc.Blocks[i].ToWire()); err != nil {
+            return err
+        }
+    }
+    bc.blockStore = store
+    return nil
+}
+
+// AttachState makes the chain check the transactions of every block it appends against s, refusing a block that
+// breaks its rules with an error wrapping ledger.ErrInvalid, and apply them to s once the block is appended. s is
+// first rebuilt from the blocks already in the chain; if they break its rules, the error is returned and neither
+// s nor the chain changes.
+func (bc *Blockchain) AttachState(s ledger.State) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    receipts, err := replayState(s, bc.Blocks)
+    if err != nil {
+        return err
+    }
+    bc.state, bc.receipts = s, receipts
+    return nil
+}
+
+// checkState reports why a block carrying data would break the rules of the attached state, if there is one.
+func (bc *Blockchain) checkState(data string) error {
+    if bc.state == nil {
+        return nil
+    }
+    return bc.state.Check(data)
+}
+
+// checkBlock is checkState for a block, which must also commit to the state and receipts roots its data leads
+// to. It returns the receipts of the block's transactions.
+func (bc *Blockchain) checkBlock(block Block) ([]ledger.Receipt, error) {
+    if bc.state == nil {
+        return nil, nil
+    }
+    return ledger.CheckRoots(bc.state, block.Data, ledger.Roots{State: block.StateRoot, Receipts: block.ReceiptsRoot})
+}
+
+// roots returns the state and receipts roots a block carrying data commits to once it is appended: zero if no
+// state is attached, or if it computes no such root. The caller must have checked data.
+func (bc *Blockchain) roots(data string) (state, receipts wire.Hash) {
+    if bc.state == nil {
+        return wire.Hash{}, wire.Hash{}
+    }
+    r, _, _ := ledger.RootsAfter(bc.state, data)
+    return r.State, r.Receipts
+}
+
+// indexReceipts records the receipts of the block at index, so GetReceipt finds them. The caller must hold bc.mu.
+func (bc *Blockchain) indexReceipts(index int, receipts []ledger.Receipt) {
+    if len(receipts) > 0 && bc.receipts == nil {
+        bc.receipts = make(map[string]ledger.Receipt)
+    }
+    for _, r := range receipts {
+        r.Block = int64(index)
+        bc.receipts[r.Tx] = r
+    }
+}
+
+// GetReceipt returns the receipt of the transaction with the given ledger.TxHash, or false if no block of the
+// chain carries it or no state that records receipts is attached (see ledger.Executor).
+func (bc *Blockchain) GetReceipt(tx string) (ledger.Receipt, bool) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    r, ok := bc.receipts[tx]
+    return r, ok
+}
+
+// replayState rebuilds s, if it is not nil, from blocks, checking the roots each of them commits to, and returns
+// the receipts of their transactions by hash. If they break its rules, s is left unchanged.
+func replayState(s ledger.State, blocks []Block) (map[string]ledger.Receipt, error) {
+    if s == nil {
+        return nil, nil
+    }
+    data := make([]string, len(blocks))
+    roots := make([]ledger.Roots, len(blocks))
+    for i := range blocks {
+        data[i], roots[i] = blocks[i].Data, ledger.Roots{State: blocks[i].StateRoot, Receipts: blocks[i].ReceiptsRoot}
+    }
+    if _, err := ledger.ReplayRoots(s.Clone(), data, roots); err != nil {
+        return nil, err
+    }
+    receipts, err := ledger.ReplayRoots(s, data, roots)
+    if err != nil {
+        return nil, err
+    }
+    index := make(map[string]ledger.Receipt)
+    for i, block := range blocks {
+        for _, r := range receipts[i] {
+            r.Block = int64(block.Index)
+            index[r.Tx] = r
+        }
+    }
+    return index, nil
+}
+
+// AttachClock sets the clock that timestamps blocks appended from now on, in place of the system clock.
+func (bc *Blockchain) AttachClock(c clock.Clock) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    bc.clock = c
+}
+
+// AttachLogger sets the logger that records consensus steps from now on. Records are labelled with the
+// algorithm, and those about a single node carry the node's identifier as well.
+func (bc *Blockchain) AttachLogger(l *slog.Logger) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    bc.logger = logging.Algorithm(l, "pos")
+}
+
+// AttachEvents sets the publisher that receives the network's validator changes from now on.
+// Proposals and commits are published by wrapping the network in an engine with engine.Observe.
+func (bc *Blockchain) AttachEvents(p events.Publisher) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    bc.publisher = p
+}
+
+// publish labels an event with the algorithm and sends it to the attached publisher, if any.
+func (bc *Blockchain) publish(event events.Event) {
+    if bc.publisher == nil {
+        return
+    }
+    event.Algorithm = "pos"
+    bc.publisher.Publish(event)
+}
+
+// Save persists the blockchain's blocks to the given store under name, so the chain can be reloaded
+// after the process restarts.
+func (bc *Blockchain) Save(store storage.Store, name string) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    chain := &wire.Chain{Algorithm: "pos"}
+    for i := range bc.Blocks {
+        chain.Blocks = append(chain.Blocks, bc.Blocks[i].ToWire()) // Convert each block to the shared wire format.
+    }
+    return store.Save(name, chain)
+}
+
+// Load replaces the blockchain's blocks with the chain previously saved under name.
+// Every block's hash and its link to the previous block are verified first, so a corrupted
+// or tampered file is rejected instead of silently becoming the new chain.
+func (bc *Blockchain) Load(store storage.Store, name string) error {
+    chain, err := store.Load(name)
+    if err != nil {
+        return err
+    }
+    blocks, err := verifyChain(chain, fmt.Sprintf("chain %q", name))
+    if err != nil {
+        return err
+    }
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    receipts, err := replayState(bc.state, blocks)
+    if err != nil {
+        return err
+    }
+    bc.receipts = receipts
+    bc.Blocks = blocks // Only replace the chain once every block has been verified.
+    if bc.blockStore != nil {
+        return bc.attachBlockStore(bc.blockStore) // Re-index the reloaded blocks in the attached store.
+    }
+    return nil
+}
+
+// Export writes the chain and its validators and their stakes to w as a snapshot, which Import reads back to resume the run
+// where it stopped.
+func (bc *Blockchain) Export(w io.Writer) error {
+    bc.mu.Lock()
+    snapshot := &wire.Snapshot{Chain: &wire.Chain{Algorithm: "pos"}}
+    for i := range bc.Blocks {
+        snapshot.Chain.Blocks = append(snapshot.Chain.Blocks, bc.Blocks[i].ToWire())
+    }
+    for _, validator := range bc.Validators {
+        snapshot.Stakes = append(snapshot.Stakes, &wire.Stake{Validator: validator, Amount: int64(bc.Stakes[validator])})
+    }
+    bc.mu.Unlock()
+    return wire.WriteSnapshot(w, snapshot)
+}
+
+// Import replaces the chain and its validators and their stakes with a snapshot written by Export. As with Load, every block is
+// verified first, and nothing changes if the snapshot is rejected.
+func (bc *Blockchain) Import(r io.Reader) error {
+    snapshot, err := wire.ReadSnapshot(r)
+    if err != nil {
+        return err
+    }
+    blocks, err := verifyChain(snapshot.GetChain(), "the snapshot")
+    if err != nil {
+        return err
+    }
+    validators := make([]string, len(snapshot.GetStakes()))
+    stakes := make(map[string]int, len(snapshot.GetStakes()))
+    for i, stake := range snapshot.GetStakes() {
+        validators[i] = stake.GetValidator()
+        stakes[validators[i]] = int(stake.GetAmount())
+    }
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    receipts, err := replayState(bc.state, blocks)
+    if err != nil {
+        return err
+    }
+    bc.receipts = receipts
+    bc.Blocks = blocks
+    bc.Validators, bc.Stakes = validators, stakes
+    if bc.blockStore != nil {
+        return bc.attachBlockStore(bc.blockStore) // Re-index the imported blocks in the attached store.
+    }
+    return nil
+}
+
+// verifyChain converts a chain read from source, such as a store or a snapshot, back into blocks, verifying
+// first that it was produced by pos and that every block's hash and its link to the previous block are intact.
+func verifyChain(chain *wire.Chain, source string) ([]Block, error) {
+    if chain.GetAlgorithm() != "pos" {
+        return nil, fmt.Errorf("pos: %s was produced by %q", source, chain.GetAlgorithm())
+    }
+    if len(chain.GetBlocks()) == 0 {
+        return nil, fmt.Errorf("pos: %s has no genesis block", source)
+    }
+
+    blocks := make([]Block, 0, len(chain.GetBlocks()))
+    for i, w := range chain.GetBlocks() {
+        block := BlockFromWire(w)
+        if block.Hash != block.CalculateHash() {
+            return nil, fmt.Errorf("pos: block %d of %s has an invalid hash", i, source)
+        }
+        if i > 0 && block.PrevHash != blocks[i-1].Hash {
+            return nil, fmt.Errorf("pos: block %d of %s does not link to its predecessor", i, source)
+        }
+        blocks = append(blocks, block)
+    }
+    return blocks, nil
 }
 
 // Footer: Security Considerations and Architectural Decisions
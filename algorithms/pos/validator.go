@@ -0,0 +1,324 @@
+package pos
+
+import (
+    "errors"
+    "fmt"
+    "log/slog"
+    "math"
+    "time"
+
+    "consensus-algorithms-edu/blocktree"
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/cost"
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/logging"
+    "consensus-algorithms-edu/mempool"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/rotation"
+    "consensus-algorithms-edu/wire"
+)
+
+// ValidatorConfig describes a single validator and the network it belongs to.
+type ValidatorConfig struct {
+    ID            int32            // Unique identifier of this validator.
+    Peers         []int32          // Identifiers of every validator in the network, including this one.
+    Stakes        map[int32]int    // Stake of every validator; a validator without stake never proposes.
+    Schedule      *Schedule        // Proposer schedule shared by every validator; built from Stakes if nil.
+    Rotation      rotation.Builder // Policy choosing the proposer of each slot among the validators with stake; the Schedule if nil.
+    SlotTicks     int              // Ticks per slot, i.e. between two scheduled proposals; defaults to 10.
+    SlashPercent  int              // Percentage of its stake a slashed validator loses; defaults to 100.
+    JailSlots     int              // Slots a slashed validator stays jailed before Unjail may release it; for good if 0.
+    Confirmations int              // Blocks that must follow a block before Committed reports it.
+    AntiEntropy   int              // Ticks between anti-entropy exchanges with a peer (see blocktree.Replica.Tick); none if 0.
+    Prune         int              // Blocks below the head whose data the replica keeps (see blocktree.ReplicaConfig.Prune); all of them if 0.
+    State         ledger.State     // State the data of the followed chain is applied to (see blocktree.Chain); blocks are not interpreted if nil.
+    Mempool       *mempool.Pool    // Holds proposed transfers, which blocks carry by fee (see blocktree.ReplicaConfig.Mempool); none if nil.
+    Clock         clock.Clock      // Source of block timestamps; the system clock is used if nil.
+    Logger        *slog.Logger     // Receives proposed blocks and reorganizations, scoped to this validator; silent if nil.
+}
+
+var (
+    // ErrNotJailed is returned by Unjail for a validator that is not jailed.
+    ErrNotJailed = errors.New("pos: validator is not jailed")
+
+    // ErrJailed is returned by Unjail for a validator whose jail time has not run out, or that is jailed for good.
+    ErrJailed = errors.New("pos: validator is still jailed")
+)
+
+// Validator is a message-driven Proof of Stake participant.
+// Time is divided into slots, and every validator computes the same proposer for each slot by a stake-weighted
+// draw seeded with the slot number, or by the rotation policy of ValidatorConfig.Rotation, so no messages are
+// needed to agree on who proposes. When its slot comes, the
+// proposer extends the head it follows and announces the block. Validators that cannot hear each other keep
+// proposing on their own side, and after they reconnect they follow the branch built by the most stake, chosen
+// by ForkChoice. The block tree and the exchange of blocks are provided by the embedded blocktree.Replica.
+type Validator struct {
+    *blocktree.Replica
+    schedule  *Schedule
+    rotation  rotation.Builder
+    policy    rotation.Policy // Chooses the proposer of each slot: the schedule, or what rotation built.
+    slotTicks int
+    ticks     int           // Ticks seen so far; the current slot is ticks / slotTicks.
+    slotStart time.Time     // When the current slot started, to tell whether its proposer's block arrived.
+    slotTime  time.Duration // Time between the starts of the last two slots, to tell the slot of a timestamp.
+    bonded    map[int32]int // Stake of every validator, jailed ones included; nil until the stakes first change.
+    jailed    map[int32]int // Slot from which each jailed validator may be released, or -1 if it never may.
+    slash     int           // Percentage of its stake a slashed validator loses.
+    jailSlots int
+    clock     clock.Clock
+    logger    *slog.Logger
+}
+
+// Schedule is the stake-weighted proposer schedule every validator computes: a rotation.Weighted draw over
+// the validators with stake. It never changes once built, so the validators of a large simulation can share one
+// through ValidatorConfig.Schedule instead of each keeping its own copy of every stake.
+type Schedule struct {
+    *rotation.Weighted
+    byName map[string]int // Stake of every validator with stake, keyed by the name that appears as a block's producer.
+}
+
+// NewSchedule returns the schedule of validators with the given stakes; those without stake never propose.
+func NewSchedule(stakes map[int32]int) *Schedule {
+    s := &Schedule{Weighted: rotation.NewWeighted(stakes), byName: make(map[string]int)}
+    for id, stake := range stakes {
+        if stake > 0 {
+            s.byName[node.Name(id)] = stake
+        }
+    }
+    return s
+}
+
+// Proposer returns the validator scheduled to propose in the given slot, or -1 if no validator holds stake.
+// The draw hashes the slot number, so every validator computes the same schedule independently. Package grinding
+// compares other seeds with Draw.
+func (s *Schedule) Proposer(slot int) int32 {
+    return s.Leader(uint64(slot))
+}
+
+// ForkChoice returns the Proof of Stake fork-choice rule for validators with the given stakes, keyed by the
+// name that appears as a block's producer, e.g. "node-2": follow the branch whose blocks since the fork were
+// proposed by the most stake, and the longer one on a tie. A side holding most of the stake wins a partition
+// however many blocks the other side produced.
+func ForkChoice(stakes map[string]int) blocktree.Rule {
+    return blocktree.HeaviestBranch(func(producer string) int { return stakes[producer] })
+}
+
+// GenesisBlock returns the genesis block shared by every validator. It has no timestamp, so independently
+// started validators agree on it.
+func GenesisBlock() Block {
+    block := Block{Index: 0, Data: "Genesis Block"}
+    block.Hash = block.CalculateHash()
+    return block
+}
+
+// NewValidator creates a validator whose chain holds only the genesis block.
+func NewValidator(cfg ValidatorConfig) *Validator {
+    if cfg.SlotTicks <= 0 {
+        cfg.SlotTicks = 10
+    }
+    if cfg.Schedule == nil {
+        cfg.Schedule = NewSchedule(cfg.Stakes)
+    }
+    if cfg.SlashPercent <= 0 || cfg.SlashPercent > 100 {
+        cfg.SlashPercent = 100
+    }
+    v := &Validator{
+        schedule:  cfg.Schedule,
+        rotation:  cfg.Rotation,
+        slotTicks: cfg.SlotTicks,
+        jailed:    make(map[int32]int),
+        slash:     cfg.SlashPercent,
+        jailSlots: cfg.JailSlots,
+        clock:     clock.Or(cfg.Clock),
+        logger:    logging.Scope(cfg.Logger, "pos", cfg.ID),
+    }
+    g := GenesisBlock()
+    v.Replica = blocktree.NewReplica(blocktree.ReplicaConfig{
+        ID:            cfg.ID,
+        Peers:         cfg.Peers,
+        Genesis:       g.ToWire(),
+        Rule:          blocktree.HeaviestBranch(func(producer string) int { return v.schedule.byName[producer] }), // ForkChoice, following Slash.
+        Verify:        func(w *wire.Block) bool { return verifyProposed(w, v.schedule.byName) },
+        Header:        func(w *wire.Block) wire.Header { b := BlockFromWire(w); return b.Header() },
+        Confirmations: cfg.Confirmations,
+        AntiEntropy:   cfg.AntiEntropy,
+        Prune:         cfg.Prune,
+        State:         cfg.State,
+        Mempool:       cfg.Mempool,
+        Logger:        v.logger,
+    })
+    v.rotate()
+    return v
+}
+
+// rotate chooses the policy for the validators of the schedule: the schedule itself, or the policy rotation
+// builds for them.
+func (v *Validator) rotate() {
+    v.policy = v.schedule
+    if v.rotation != nil {
+        v.policy = v.rotation(v.schedule.Candidates())
+    }
+}
+
+// verifyProposed checks a block received from a peer: its hash must match its contents and its proposer must
+// hold stake.
+func verifyProposed(w *wire.Block, stakes map[string]int) bool {
+    cost.Count(cost.Verify, 1) // A deployment checks the producer's signature on the block (see package cost).
+    block := BlockFromWire(w)
+    return block.Hash == block.CalculateHash() && stakes[block.Validator] > 0
+}
+
+// Slash punishes the validator that ev proves to have proposed two blocks for one slot (see package evidence):
+// it burns ValidatorConfig.SlashPercent of the validator's stake and jails it. A jailed validator is never
+// scheduled to propose, blocks it proposes are refused, and the blocks it already proposed no longer weigh in
+// the fork choice, until Unjail releases it. The validator stops sharing its schedule with others, which each
+// slash the offender once they hold the proof. The jail time runs from the slot of the offending block, which
+// its timestamp tells, rather than from when the proof arrived, so every validator releases the offender at the
+// same slot however late it learned of the offence. Slashing a validator without stake, or one already jailed,
+// does nothing.
+func (v *Validator) Slash(ev *wire.Evidence) {
+    id := ev.GetFirst().GetSigner()
+    stakes := v.stakes()
+    if _, jailed := v.jailed[id]; jailed || stakes[id] == 0 {
+        return
+    }
+    burned := stakes[id] * v.slash / 100
+    stakes[id] -= burned
+    v.jailed[id] = -1
+    if v.jailSlots > 0 {
+        v.jailed[id] = v.slotOf(wire.Timestamp(ev.GetFirst().GetRound())) + v.jailSlots // A block statement's round is its timestamp.
+    }
+    v.reschedule()
+    v.logger.Info("slashed validator", "validator", node.Name(id), "burned", burned, "stake", stakes[id])
+}
+
+// Unjail releases a jailed validator whose jail time has run out, putting its remaining stake back in the
+// schedule. On a real chain the validator asks for it in a transaction; here every validator must be told, as
+// every validator applies the blocks carrying that transaction. It returns ErrNotJailed for a validator that is
+// not jailed and ErrJailed for one that must stay.
+func (v *Validator) Unjail(id int32) error {
+    release, ok := v.jailed[id]
+    switch {
+    case !ok:
+        return fmt.Errorf("%w: %s", ErrNotJailed, node.Name(id))
+    case release < 0:
+        return fmt.Errorf("%w: %s is jailed for good", ErrJailed, node.Name(id))
+    case v.slot() < release:
+        return fmt.Errorf("%w: %s until slot %d", ErrJailed, node.Name(id), release)
+    }
+    delete(v.jailed, id)
+    v.reschedule()
+    v.logger.Info("unjailed validator", "validator", node.Name(id), "stake", v.stakes()[id])
+    return nil
+}
+
+// Jailed reports whether a validator is jailed.
+func (v *Validator) Jailed(id int32) bool {
+    _, ok := v.jailed[id]
+    return ok
+}
+
+// Bond adds stake to a validator, registering it if it held none; it is scheduled from the next slot on unless
+// it is jailed. Like Unjail, it stands for a transaction every validator applies, and every validator must be
+// told. A stake that is not positive is refused.
+func (v *Validator) Bond(id int32, stake int) error {
+    if stake <= 0 {
+        return fmt.Errorf("pos: stake of validator %q must be positive, got %d", node.Name(id), stake)
+    }
+    v.stakes()[id] += stake
+    v.reschedule()
+    v.logger.Info("bonded validator", "validator", node.Name(id), "stake", v.stakes()[id])
+    return nil
+}
+
+// Stake returns the stake a validator has bonded, whether or not it is jailed.
+func (v *Validator) Stake(id int32) int {
+    if v.bonded == nil {
+        return v.schedule.byName[node.Name(id)]
+    }
+    return v.bonded[id]
+}
+
+// stakes returns the stake of every validator, jailed ones included, copying it out of the schedule the first
+// time, so that validators sharing a schedule share it until their stakes change.
+func (v *Validator) stakes() map[int32]int {
+    if v.bonded == nil {
+        v.bonded = make(map[int32]int)
+        for _, id := range v.schedule.Candidates() {
+            v.bonded[id] = v.schedule.byName[node.Name(id)]
+        }
+    }
+    return v.bonded
+}
+
+// reschedule rebuilds the schedule from the bonded stakes, leaving out the jailed validators.
+func (v *Validator) reschedule() {
+    active := make(map[int32]int, len(v.bonded))
+    for id, stake := range v.stakes() {
+        if _, jailed := v.jailed[id]; !jailed {
+            active[id] = stake
+        }
+    }
+    v.schedule = NewSchedule(active)
+    v.rotate()
+}
+
+// slot returns the current slot.
+func (v *Validator) slot() int {
+    return v.ticks / v.slotTicks
+}
+
+// slotOf returns the slot a block timestamped at ts was proposed in: proposers stamp their blocks as their slot
+// starts, so it is the slot whose start ts is nearest to. It is the current slot until two slots have started.
+func (v *Validator) slotOf(ts wire.Timestamp) int {
+    if v.slotTime <= 0 {
+        return v.slot()
+    }
+    return v.slot() - int(math.Round(float64(v.slotStart.Sub(ts.Time()))/float64(v.slotTime)))
+}
+
+// Proposer returns the validator scheduled to propose in the given slot, or -1 if no validator holds stake.
+func (v *Validator) Proposer(slot int) int32 {
+    return v.policy.Leader(uint64(slot))
+}
+
+// Leader returns the proposer of the current slot.
+func (v *Validator) Leader() int32 {
+    return v.Proposer(v.slot())
+}
+
+// filled reports whether the head is the block of the slot that started at slotStart and ends now: made by the
+// slot's proposer, no earlier than half a slot before the slot started. The margin allows for peers whose ticks
+// are not aligned with this one's, whose blocks can arrive just before its slot starts.
+func (v *Validator) filled(slot int, now time.Time) bool {
+    head := v.Head()
+    earliest := v.slotStart.Add(-now.Sub(v.slotStart) / 2)
+    return head.GetProducer() == node.Name(v.Proposer(slot)) && !wire.Timestamp(head.GetTimestamp()).Time().Before(earliest)
+}
+
+// Tick advances the validator's clock by one tick. At the start of a slot it is scheduled for, the validator
+// proposes a block carrying the oldest pending data, or no data. At the start of every slot, it reports the last
+// one to its rotation policy as failed if that slot's proposer's block did not become its head.
+func (v *Validator) Tick() []*wire.Envelope {
+    out := v.Replica.Tick()
+    v.ticks++
+    if v.ticks%v.slotTicks != 0 {
+        return out
+    }
+    now := v.clock.Now()
+    if last := v.ticks/v.slotTicks - 1; last > 0 && !v.filled(last, now) {
+        v.policy.Fail(uint64(last))
+    }
+    if !v.slotStart.IsZero() {
+        v.slotTime = now.Sub(v.slotStart)
+    }
+    v.slotStart = now
+    if v.Leader() != v.ID() {
+        return out
+    }
+    head := v.Head()
+    cost.Count(cost.Sign, 1) // A deployment signs the block it produces (see package cost).
+    block := NewBlockAt(v.NextData(), head.Sum(), int(head.GetIndex())+1, node.Name(v.ID()), v.clock.Now())
+    v.logger.Info("proposed block", "slot", v.ticks/v.slotTicks, "index", block.Index, "hash", block.Hash.String())
+    return append(out, v.Produce(block.ToWire())...)
+}
@@ -0,0 +1,46 @@
+package pow
+
+import "math"
+
+// DefaultBombPeriod is the number of blocks over which a Bomb's component doubles if Bomb.Period is 0.
+const DefaultBombPeriod = 100
+
+// Bomb is an exponentially growing component of the mining difficulty, as Ethereum's difficulty bomb was: on top
+// of the base difficulty, which the network's hash power is matched to, every block's work gains a term that
+// doubles every Period blocks. It is negligible for a long time, then doubles the block time at Start, and soon
+// makes blocks so slow that the chain freezes. Its purpose is to make a hard fork unavoidable: only a new version
+// of the protocol, listed in Delays, can push the bomb back, so nodes that do not upgrade are left on a chain
+// nobody can extend.
+//
+// A Miner finds a block with a fixed chance per tick, which stands for its hash power, so the bomb divides that
+// chance by Factor rather than raising the number of leading zeros a hash needs.
+type Bomb struct {
+    Start  int     // Height at which the bomb's term equals the base difficulty; no bomb if 0.
+    Period int     // Blocks over which the term doubles; DefaultBombPeriod if 0.
+    Delays []Delay // Hard forks that pushed the bomb back, in any order.
+}
+
+// Delay is a hard fork that pushes a Bomb back: from block Height on, the bomb counts heights Blocks lower, as
+// Ethereum's forks computed the bomb from a fake block number.
+type Delay struct {
+    Height int // First block the fork applies to.
+    Blocks int // Blocks the bomb is pushed back by.
+}
+
+// Factor returns how many times the base work a block at height takes: 1 plus the bomb's term, 2^⌊(h−Start)/Period⌋
+// for the height h the bomb counts after every delay in effect.
+func (b Bomb) Factor(height int) float64 {
+    if b.Start <= 0 {
+        return 1
+    }
+    period := b.Period
+    if period <= 0 {
+        period = DefaultBombPeriod
+    }
+    for _, d := range b.Delays {
+        if height >= d.Height {
+            height -= d.Blocks
+        }
+    }
+    return 1 + math.Exp2(math.Floor(float64(height-b.Start)/float64(period)))
+}
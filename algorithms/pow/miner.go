@@ -0,0 +1,187 @@
+package pow
+
+import (
+    "log/slog"
+    "math/rand"
+    "strconv"
+    "sync"
+    "time"
+
+    "consensus-algorithms-edu/blocktree"
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/logging"
+    "consensus-algorithms-edu/mempool"
+    "consensus-algorithms-edu/wire"
+)
+
+// MinerConfig describes a single miner and the network it belongs to.
+type MinerConfig struct {
+    ID              int32          // Unique identifier of this miner.
+    Peers           []int32        // Identifiers of every miner in the network, including this one.
+    MineProbability float64        // Chance of finding a block on each tick, i.e. the miner's share of hash power; defaults to 0.05.
+    Difficulty      int            // Leading zeros the hash of every mined block must have; the package Difficulty if 0. The same for every miner of a network.
+    Bomb            Bomb           // Exponentially growing component of the difficulty, divided out of MineProbability; none if zero.
+    Retarget        Retarget       // Adjusts the difficulty to the block interval, divided out of MineProbability like the Bomb; fixed if zero.
+    Timestamps      TimestampRules // Rules the timestamps of the followed chain keep to; none if zero.
+    Stamp           Stamp          // Chooses the timestamps of the blocks the miner finds, as a dishonest miner would; the clock's time if nil. Kept within Timestamps.
+    Confirmations   int            // Blocks that must be mined on top of a block before Committed reports it.
+    AntiEntropy     int            // Ticks between anti-entropy exchanges with a peer (see blocktree.Replica.Tick); none if 0.
+    Prune           int            // Blocks below the head whose data the replica keeps (see blocktree.ReplicaConfig.Prune); all of them if 0.
+    State           ledger.State   // State the data of the followed chain is applied to (see blocktree.Chain); blocks are not interpreted if nil.
+    Mempool         *mempool.Pool  // Holds proposed transfers, which blocks carry by fee (see blocktree.ReplicaConfig.Mempool); none if nil.
+    Rand            *rand.Rand     // Source of randomness for mining; a time-seeded source is used if nil.
+    Clock           clock.Clock    // Source of block timestamps; the system clock is used if nil.
+    Logger          *slog.Logger   // Receives mined blocks and reorganizations, scoped to this miner; silent if nil.
+}
+
+// Miner is a message-driven Proof of Work participant.
+// On every tick it finds a block with probability MineProbability, extends its best chain with it and announces
+// it to its peers. Blocks received from peers are verified and adopted whenever they form a chain with more work
+// behind it, chosen by ForkChoice, which is how forks are resolved: when two miners extend the same parent, the
+// branch that grows first wins and the other side reorganizes onto it. The block tree, the pending data and the
+// exchange of blocks with peers are provided by the embedded blocktree.Replica; the miner only decides when a
+// block is found.
+type Miner struct {
+    *blocktree.Replica
+    mineProbability float64
+    difficulty      int
+    bomb            Bomb
+    retarget        Retarget
+    timestamps      TimestampRules
+    stamp           Stamp
+    work            float64 // Work of the block after workHead, which Retarget takes the whole chain to compute.
+    workHead        string
+    rand            *rand.Rand
+    clock           clock.Clock
+    logger          *slog.Logger
+}
+
+// ForkChoice returns the Proof of Work fork-choice rule for miners working under bomb and retarget: follow the
+// chain with the most work behind it. A block takes Bomb.Factor of its height times what Retarget.Work makes of
+// the chain it extends, so a short branch of hard blocks outweighs a long branch of easy ones, such as one whose
+// miner dated its blocks to make the work fall. Equal work goes to the longer branch. Without a bomb or a retarget
+// every block takes the same work, and the rule is blocktree.LongestChain.
+func ForkChoice(bomb Bomb, retarget Retarget) blocktree.Rule {
+    if bomb.Start <= 0 && retarget.Interval <= 0 {
+        return blocktree.LongestChain
+    }
+    return func(t *blocktree.Tree, a, b *wire.Block) bool {
+        _, afterA, afterB := t.Diverge(a, b)
+        wa, wb := branchWork(t, afterA, bomb, retarget), branchWork(t, afterB, bomb, retarget)
+        if wa != wb {
+            return wa > wb
+        }
+        return a.GetIndex() > b.GetIndex()
+    }
+}
+
+// branchWork sums the work of blocks, the blocks of a branch of t after a fork, oldest first. The blocks before
+// the fork are shared, so they weigh the same on both sides and are left out.
+func branchWork(t *blocktree.Tree, blocks []*wire.Block, bomb Bomb, retarget Retarget) float64 {
+    if len(blocks) == 0 {
+        return 0
+    }
+    chain := t.Branch(blocks[len(blocks)-1])
+    total := 0.0
+    for _, block := range blocks {
+        index := int(block.GetIndex())
+        total += bomb.Factor(index) * retarget.Work(chain[:index])
+    }
+    return total
+}
+
+// genesis is mined once and shared by every miner, so independently started miners agree on it.
+var genesis = sync.OnceValue(func() Block {
+    block := Block{Index: 0, Data: "Genesis Block"} // No timestamp: the genesis block must not depend on the local clock.
+    block.MineBlock()
+    return block
+})
+
+// GenesisBlock returns the genesis block shared by every miner.
+func GenesisBlock() Block {
+    return genesis()
+}
+
+// NewMiner creates a miner whose chain holds only the genesis block.
+func NewMiner(cfg MinerConfig) *Miner {
+    if cfg.MineProbability <= 0 {
+        cfg.MineProbability = 0.05
+    }
+    if cfg.Rand == nil {
+        cfg.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+    }
+    g := GenesisBlock()
+    logger := logging.Scope(cfg.Logger, "pow", cfg.ID)
+    c := clock.Or(cfg.Clock)
+    return &Miner{
+        Replica: blocktree.NewReplica(blocktree.ReplicaConfig{
+            ID:            cfg.ID,
+            Peers:         cfg.Peers,
+            Genesis:       g.ToWire(),
+            Rule:          cfg.Timestamps.rule(ForkChoice(cfg.Bomb, cfg.Retarget), c, logger),
+            Verify:        verifyMined(cfg.Difficulty),
+            Header:        func(w *wire.Block) wire.Header { b := BlockFromWire(w); return b.Header() },
+            Confirmations: cfg.Confirmations,
+            AntiEntropy:   cfg.AntiEntropy,
+            Prune:         cfg.Prune,
+            State:         cfg.State,
+            Mempool:       cfg.Mempool,
+            Logger:        logger,
+        }),
+        mineProbability: cfg.MineProbability,
+        difficulty:      cfg.Difficulty,
+        bomb:            cfg.Bomb,
+        retarget:        cfg.Retarget,
+        timestamps:      cfg.Timestamps,
+        stamp:           cfg.Stamp,
+        rand:            cfg.Rand,
+        clock:           c,
+        logger:          logger,
+    }
+}
+
+// verifyMined returns the check of a block received from a peer: its hash must match its contents and meet the
+// difficulty every miner works at, rather than a lower one the block claims for itself.
+func verifyMined(difficulty int) func(*wire.Block) bool {
+    if difficulty == 0 {
+        difficulty = Difficulty
+    }
+    return func(w *wire.Block) bool {
+        block := BlockFromWire(w)
+        return block.Hash == block.CalculateHash() && block.Target() == difficulty && block.MeetsDifficulty()
+    }
+}
+
+// Tick gives the miner one chance to find a block. A found block carries the oldest pending data, or no data.
+// With a Bomb or a Retarget, the chance falls as the next block's work grows.
+func (m *Miner) Tick() []*wire.Envelope {
+    out := m.Replica.Tick()
+    chain := m.Chain()
+    head := chain[len(chain)-1]
+    index := int(head.GetIndex()) + 1
+    if head.GetHash() != m.workHead {
+        m.work, m.workHead = m.retarget.Work(chain), head.GetHash()
+    }
+    if m.rand.Float64() >= m.mineProbability/(m.bomb.Factor(index)*m.work) {
+        return out
+    }
+    block := NewBlockWithDifficulty(m.NextData(), head.Sum(), index, m.difficulty, m.timestamp(chain, index)) // Perform the actual proof of work.
+    mined := block.ToWire()
+    mined.Producer = "node-" + strconv.Itoa(int(m.ID())) // Not covered by the hash; it only labels the block for display.
+    m.logger.Info("mined block", "index", block.Index, "hash", block.Hash.String(), "nonce", block.Nonce)
+    return append(out, m.Produce(mined)...)
+}
+
+// timestamp returns the timestamp of a block found at index on top of chain: the clock's time, or the time the
+// miner's Stamp chooses, moved within the bounds of its own rules so that it never mines a block it would
+// refuse. An honest miner whose clock is behind the median time past stamps just after it, as Bitcoin's miners do.
+func (m *Miner) timestamp(chain []*wire.Block, index int) time.Time {
+    now := m.clock.Now()
+    at := now
+    if m.stamp != nil {
+        at = m.stamp(index, now)
+    }
+    earliest, latest := m.timestamps.Bounds(chain, now)
+    return min(max(wire.TimestampOf(at), earliest), latest).Time()
+}
@@ -6,79 +6,651 @@
 package pow
 
 import (
-    "crypto/sha256"
+    "context"
+    "errors"
     "fmt"
-    "strconv"
+    "io"
+    "iter"
+    "log/slog"
+    "maps"
+    "slices"
+    "sync"
     "time"
+
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/feed"
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/logging"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/storage"
+    "consensus-algorithms-edu/wire"
 )
 
+// Difficulty is the number of leading zeros a block's hash must have to be valid.
+const Difficulty = 4
+
+// ErrMiningTimeout is returned by AddBlock when no valid hash was found within the chain's mining timeout.
+var ErrMiningTimeout = errors.New("pow: mining timed out")
+
 // Block represents an individual block in the blockchain.
 // It contains crucial information like index, timestamp, data, cryptographic hashes, and a nonce value used for mining.
 type Block struct {
-    Index     int    // Position of the block in the blockchain.
-    Timestamp string // The time when the block was created.
-    Data      string // The transaction or arbitrary data contained within the block.
-    PrevHash  string // The hash of the previous block to maintain immutability and chain linkage.
-    Hash      string // SHA-256 hash of the current block's contents.
-    Nonce     int    // Nonce is the number that miners adjust to find a valid hash under the set difficulty.
+    Index        int            // Position of the block in the blockchain.
+    Timestamp    wire.Timestamp // When the block was created, in nanoseconds since the Unix epoch.
+    Data         string         // The transaction or arbitrary data contained within the block.
+    PrevHash     wire.Hash      // The hash of the previous block to maintain immutability and chain linkage.
+    Hash         wire.Hash      // SHA-256 hash of the current block's contents.
+    Nonce        int            // Nonce is the number that miners adjust to find a valid hash under the set difficulty.
+    Difficulty   int            // Leading zeros the hash must have; the package Difficulty if 0. Covered by the hash when set.
+    StateRoot    wire.Hash      // Root of the attached ledger state after the block; the zero Hash if none was attached.
+    ReceiptsRoot wire.Hash      // Root of the receipts of its transactions; the zero Hash if it has none or none was attached.
 }
 
 // Blockchain represents the distributed ledger that consists of a chain of blocks.
 // Blocks are mined and added to this chain, ensuring that every block is valid and consistent with previous ones.
 type Blockchain struct {
-    Blocks []Block // A slice containing all blocks in the blockchain.
+    mu          sync.Mutex                // Guards every field while a method runs.
+    Blocks      []Block                   // A slice containing all blocks in the blockchain.
+    Difficulty  int                       // Leading zeros required of appended blocks; the package Difficulty if 0.
+    timeout     time.Duration             // How long AddBlock mines before giving up; no limit if 0.
+    blockStore  storage.BlockStore        // Optional block store that every appended block is written through to.
+    state       ledger.State              // Checks the transactions of appended blocks and applies them; blocks are not interpreted if nil.
+    receipts    map[string]ledger.Receipt // Receipts of the transactions of appended blocks by hash, if the state records them.
+    clock       clock.Clock               // Clock that timestamps appended blocks; the system clock if nil.
+    logger      *slog.Logger              // Logger that records consensus steps; silent if nil.
+    subscribers feed.Feed[Block]          // Delivers every appended block to Subscribe's channels.
 }
 
 // NewBlock creates a new block, initializes it with given data, and mines it to ensure it meets the difficulty criteria.
 // Mining involves adjusting the nonce until a hash with the correct number of leading zeros is found.
-func NewBlock(data string, prevHash string, index int) Block {
-    block := Block{
-        Index:     index,
-        Timestamp: time.Now().String(), // Record the time when the block is created.
-        Data:      data,
-        PrevHash:  prevHash,
-        Nonce:     0, // Initialize nonce to zero, which will be incremented during mining.
-    }
+func NewBlock(data string, prevHash wire.Hash, index int) Block {
+    return NewBlockAt(data, prevHash, index, clock.System.Now())
+}
+
+// NewBlockAt is NewBlock with an explicit creation time, for callers that keep their own clock such as a
+// simulation.
+func NewBlockAt(data string, prevHash wire.Hash, index int, at time.Time) Block {
+    return NewBlockWithDifficulty(data, prevHash, index, 0, at)
+}
+
+// NewBlockWithDifficulty is NewBlockAt for a chain mined at a difficulty other than the package default.
+// A difficulty of 0 means Difficulty.
+func NewBlockWithDifficulty(data string, prevHash wire.Hash, index int, difficulty int, at time.Time) Block {
+    block := unminedBlock(data, prevHash, index, difficulty, at)
     block.MineBlock() // Mine the block to find a valid hash that meets the difficulty requirement.
     return block
 }
 
-// CalculateHash generates a SHA-256 hash of the block's contents.
-// The hash includes the block's index, timestamp, data, previous hash, and nonce.
-func (b *Block) CalculateHash() string {
-    record := strconv.Itoa(b.Index) + b.Timestamp + b.Data + b.PrevHash + strconv.Itoa(b.Nonce)
-    hash := sha256.New()                // Create a new SHA-256 hash object.
-    hash.Write([]byte(record))          // Write the concatenated block data to the hash.
-    hashed := hash.Sum(nil)             // Compute the hash.
-    return fmt.Sprintf("%x", hashed)    // Return the hash as a hexadecimal string.
+// unminedBlock returns a block with its nonce at zero, ready to be mined.
+func unminedBlock(data string, prevHash wire.Hash, index int, difficulty int, at time.Time) Block {
+    return Block{
+        Index:      index,
+        Timestamp:  wire.TimestampOf(at),
+        Data:       data,
+        PrevHash:   prevHash,
+        Nonce:      0, // Initialize nonce to zero, which will be incremented during mining.
+        Difficulty: difficulty,
+    }
+}
+
+// CalculateHash generates the SHA-256 hash of the block, which is the hash of its header.
+// The header covers the block's index, timestamp, previous hash, nonce and difficulty, and its data through the
+// data's root, so neither the data nor the difficulty can be changed after mining.
+func (b *Block) CalculateHash() wire.Hash {
+    h := b.Header()
+    return h.Hash()
+}
+
+// Header returns the part of the block its hash covers. Its nonce is the only field mining changes.
+func (b *Block) Header() wire.Header {
+    return wire.Header{
+        Index:      int64(b.Index),
+        Timestamp:  b.Timestamp,
+        Parent:     b.PrevHash,
+        Root:       wire.BodyRoot(b.Data),
+        Nonce:      int64(b.Nonce),
+        Difficulty: int32(b.Difficulty),
+        State:      b.StateRoot,
+        Receipts:   b.ReceiptsRoot,
+    }
+}
+
+// ToWire converts the block into the algorithm-neutral wire format used for messaging and storage.
+// PoW-specific fields are preserved, including the nonce.
+func (b *Block) ToWire() *wire.Block {
+    return &wire.Block{
+        Index:        int64(b.Index),
+        Timestamp:    int64(b.Timestamp),
+        Data:         b.Data,
+        PrevHash:     b.PrevHash.String(),
+        Hash:         b.Hash.String(),
+        Nonce:        int64(b.Nonce),
+        Difficulty:   int32(b.Difficulty),
+        StateRoot:    b.StateRoot.String(),
+        ReceiptsRoot: b.ReceiptsRoot.String(),
+    }
+}
+
+// BlockFromWire converts a wire-format block back into this package's Block type.
+// The hash is copied as-is rather than recalculated, so callers can still detect tampered blocks.
+func BlockFromWire(w *wire.Block) Block {
+    return Block{
+        Index:        int(w.GetIndex()),
+        Timestamp:    wire.Timestamp(w.GetTimestamp()),
+        Data:         w.GetData(),
+        PrevHash:     w.ParentSum(),
+        Hash:         w.Sum(),
+        Nonce:        int(w.GetNonce()),
+        Difficulty:   int(w.GetDifficulty()),
+        StateRoot:    w.StateSum(),
+        ReceiptsRoot: w.ReceiptsSum(),
+    }
 }
 
 // MineBlock performs the Proof of Work mining process to find a valid hash for the block.
 // The mining difficulty is represented by the number of leading zeros in the hash.
 func (b *Block) MineBlock() {
-    difficulty := 4                     // Set the mining difficulty; 4 leading zeros are required.
-    target := "0000"                    // Target pattern that the hash must match (difficulty level of 4).
-    
+    header := b.Header()                // Only the nonce changes, so the data is hashed into the root once.
+    b.Hash = header.Hash()              // Hash the block once so the loop below has a value to check.
+
     // Increment the nonce and recalculate the hash until the hash has the required number of leading zeros.
-    for b.Hash[:difficulty] != target {
+    for !b.MeetsDifficulty() {
         b.Nonce++                       // Increment nonce to generate a new hash.
-        b.Hash = b.CalculateHash()      // Calculate the new hash with the updated nonce.
+        header.Nonce = int64(b.Nonce)
+        b.Hash = header.Hash()          // Calculate the new hash with the updated nonce.
     }
     // Once the valid hash is found, the block is ready to be added to the blockchain.
 }
 
-// AddBlock creates a new block with the given data, mines it, and appends it to the blockchain.
-func (bc *Blockchain) AddBlock(data string) {
-    prevBlock := bc.Blocks[len(bc.Blocks)-1]         // Retrieve the last block in the chain.
-    newBlock := NewBlock(data, prevBlock.Hash, prevBlock.Index+1) // Create a new block based on the previous block.
-    bc.Blocks = append(bc.Blocks, newBlock)          // Append the newly mined block to the blockchain.
+// deadlineCheckInterval is how many nonces MineContext tries between checks of its context.
+const deadlineCheckInterval = 1024
+
+// MineContext is MineBlock that gives up once ctx is done, returning the context's error. The nonce it stopped
+// at is kept, so calling it again resumes the search.
+func (b *Block) MineContext(ctx context.Context) error {
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+    header := b.Header()
+    b.Hash = header.Hash()
+    for !b.MeetsDifficulty() {
+        if b.Nonce%deadlineCheckInterval == 0 {
+            if err := ctx.Err(); err != nil {
+                return err
+            }
+        }
+        b.Nonce++
+        header.Nonce = int64(b.Nonce)
+        b.Hash = header.Hash()
+    }
+    return nil
+}
+
+// MineUntil is MineBlock that gives up once deadline has passed, reporting whether a valid hash was found.
+// The deadline is measured in real time, since mining takes real processor time whatever clock the chain
+// timestamps its blocks with.
+func (b *Block) MineUntil(deadline time.Time) bool {
+    ctx, cancel := context.WithDeadline(context.Background(), deadline)
+    defer cancel()
+    return b.MineContext(ctx) == nil
+}
+
+// MeetsDifficulty reports whether the block's stored hash starts with the required number of zeros.
+// It does not recompute the hash; compare Hash with CalculateHash to detect tampering.
+func (b *Block) MeetsDifficulty() bool {
+    return b.Hash.LeadingZeros() >= b.Target()
+}
+
+// Target returns the number of leading zeros the block's hash must have.
+func (b *Block) Target() int {
+    if b.Difficulty == 0 {
+        return Difficulty
+    }
+    return b.Difficulty
+}
+
+// AddBlock creates a new block with the given data, mines it, appends it to the blockchain and returns it.
+// If a block store is attached, the block is written to it first and any storage error is returned.
+// If the chain was built with options.WithTimeout and mining takes longer, ErrMiningTimeout is returned and
+// the chain is left unchanged. If a state is attached (see AttachState) and the data breaks its rules, an error
+// wrapping ledger.ErrInvalid is returned before anything is mined.
+//
+// Several goroutines may call AddBlock at once, like miners racing for the next block. Mining runs without the
+// lock; a miner whose parent was extended by another miner in the meantime discards its block and mines again
+// on the new tip, within the same timeout.
+func (bc *Blockchain) AddBlock(data string) (Block, error) {
+    return bc.AddBlockContext(context.Background(), data)
+}
+
+// AddBlockContext is AddBlock that stops mining once ctx is done and returns the context's error, leaving the
+// chain unchanged. The chain's own timeout still applies and is still reported as ErrMiningTimeout. Records
+// logged while the block is mined carry the context's trace, if it has one (see logging.WithTrace).
+func (bc *Blockchain) AddBlockContext(ctx context.Context, data string) (Block, error) {
+    if bc.timeout > 0 {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeoutCause(ctx, bc.timeout, ErrMiningTimeout)
+        defer cancel()
+    }
+    for {
+        bc.mu.Lock()
+        if err := bc.checkState(data); err != nil {
+            bc.mu.Unlock()
+            return Block{}, err // Mining a block that would be refused is wasted work.
+        }
+        prevBlock := bc.head() // Retrieve the last block in the chain.
+        newBlock := unminedBlock(data, prevBlock.Hash, prevBlock.Index+1, bc.Difficulty, clock.Or(bc.clock).Now()) // Create a new block based on the previous block.
+        newBlock.StateRoot, newBlock.ReceiptsRoot = bc.roots(data) // Mined over, so the hash commits to the state and receipts it leaves.
+        logger := logging.Or(bc.logger)
+        bc.mu.Unlock()
+
+        if err := newBlock.MineContext(ctx); err != nil {
+            err = context.Cause(ctx) // ErrMiningTimeout if the chain's own timeout expired first.
+            logger.WarnContext(ctx, "mining stopped", "index", newBlock.Index, "nonce", newBlock.Nonce, "error", err)
+            return Block{}, err
+        }
+
+        bc.mu.Lock()
+        if bc.head().Hash != prevBlock.Hash {
+            bc.mu.Unlock()
+            logger.InfoContext(ctx, "mined a stale block", "index", newBlock.Index, "hash", newBlock.Hash.String())
+            continue
+        }
+        err := bc.appendBlock(ctx, newBlock)         // Append the new block, writing it through to the block store.
+        bc.mu.Unlock()
+        if err != nil {
+            return Block{}, err
+        }
+        return newBlock, nil
+    }
+}
+
+// appendBlock writes the block through to the attached block store, if any, and appends it to the chain.
+// The block only becomes part of the in-memory chain once the store has accepted it.
+func (bc *Blockchain) appendBlock(ctx context.Context, block Block) error {
+    receipts, err := bc.checkBlock(block)
+    if err != nil {
+        return fmt.Errorf("block %d: %w", block.Index, err)
+    }
+    if bc.blockStore != nil {
+        if err := bc.blockStore.Put(block.ToWire()); err != nil {
+            return err
+        }
+    }
+    bc.Blocks = append(bc.Blocks, block)
+    if bc.state != nil {
+        bc.state.Apply(block.Data) // Checked above, under the same lock.
+        bc.indexReceipts(block.Index, receipts)
+    }
+    bc.subscribers.Publish(block)
+    logging.Or(bc.logger).InfoContext(ctx, "committed block", "index", block.Index, "hash", block.Hash.String(), "nonce", block.Nonce)
+    return nil
+}
+
+// Snapshot returns a copy of the blocks in the chain, which stays unchanged while other goroutines add blocks.
+func (bc *Blockchain) Snapshot() []Block {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return append([]Block(nil), bc.Blocks...)
+}
+// Height returns the index of the chain's last block: the number of blocks added after the genesis block.
+func (bc *Blockchain) Height() int {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return len(bc.Blocks) - 1
+}
+
+// Head returns the chain's last block.
+func (bc *Blockchain) Head() Block {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return bc.head()
+}
+
+// head returns the chain's last block. The caller must hold bc.mu.
+func (bc *Blockchain) head() Block {
+    return bc.Blocks[len(bc.Blocks)-1]
+}
+
+// GetBlockByIndex returns the block at index, or false if the chain has no block there.
+func (bc *Blockchain) GetBlockByIndex(index int) (Block, bool) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    if index < 0 || index >= len(bc.Blocks) {
+        return Block{}, false
+    }
+    return bc.Blocks[index], true
+}
+
+// GetBlockByHash returns the block with the given hash, or false if the chain has none. The chain is searched
+// from its head, since recent blocks are the ones most often looked up.
+func (bc *Blockchain) GetBlockByHash(hash wire.Hash) (Block, bool) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    for i := len(bc.Blocks) - 1; i >= 0; i-- {
+        if bc.Blocks[i].Hash == hash {
+            return bc.Blocks[i], true
+        }
+    }
+    return Block{}, false
+}
+
+// All returns an iterator over the index and block of every block in the chain, starting at the genesis block.
+func (bc *Blockchain) All() iter.Seq2[int, Block] {
+    return bc.Range(0, -1)
+}
+
+// Range returns an iterator over the blocks from index from up to and including index to, or up to the head if
+// to is negative. It iterates over the blocks as they were when it started, so the loop body may add blocks.
+func (bc *Blockchain) Range(from, to int) iter.Seq2[int, Block] {
+    return func(yield func(int, Block) bool) {
+        bc.mu.Lock()
+        if to < 0 || to >= len(bc.Blocks) {
+            to = len(bc.Blocks) - 1
+        }
+        from := max(from, 0)
+        var blocks []Block
+        if from <= to {
+            blocks = slices.Clone(bc.Blocks[from : to+1])
+        }
+        bc.mu.Unlock()
+        for i, block := range blocks {
+            if !yield(from+i, block) {
+                return
+            }
+        }
+    }
+}
+
+// Subscribe returns a channel that receives every block appended to the chain from now on, in order, so that a
+// consumer can wait for new blocks rather than poll Blocks. Blocks replaced by Import are not delivered. Pass the
+// channel to Unsubscribe once done with it, which closes it.
+func (bc *Blockchain) Subscribe() <-chan Block {
+    return bc.subscribers.Subscribe()
+}
+
+// Unsubscribe stops delivering blocks to ch, a channel returned by Subscribe, and closes it.
+func (bc *Blockchain) Unsubscribe(ch <-chan Block) {
+    bc.subscribers.Unsubscribe(ch)
+}
+
+// String summarizes the chain in one line: its length and the block at its head.
+func (bc *Blockchain) String() string {
+    return wire.Summarize("pow", bc.wireBlocks())
+}
+
+// Dump formats every block of the chain for printing, one line per block.
+func (bc *Blockchain) Dump() string {
+    return wire.Dump(bc.wireBlocks())
+}
+
+// Diff reports the first height at which chains a and b hold different blocks, or at which one of them ends.
+func Diff(a, b *Blockchain) wire.Divergence {
+    return wire.Diff(a.wireBlocks(), b.wireBlocks())
+}
+
+// wireBlocks converts the chain's blocks to the shared wire format.
+func (bc *Blockchain) wireBlocks() []*wire.Block {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    blocks := make([]*wire.Block, len(bc.Blocks))
+    for i := range bc.Blocks {
+        blocks[i] = bc.Blocks[i].ToWire()
+    }
+    return blocks
+}
+
+// Clone returns an independent copy of the chain, so that a simulation can fork a scenario mid-run and see
+// what happens next without changing the original: blocks added to either copy leave the other unchanged. The
+// copy keeps the original's clock and logger but not its block store or its subscribers, and gets its own
+// copy of an attached state.
+func (bc *Blockchain) Clone() *Blockchain {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    c := &Blockchain{
+        Blocks:     slices.Clone(bc.Blocks),
+        Difficulty: bc.Difficulty,
+        timeout:    bc.timeout,
+        clock:      bc.clock,
+        logger:     bc.logger,
+    }
+    if bc.state != nil {
+        c.state = bc.state.Clone()
+        c.receipts = maps.Clone(bc.receipts)
+    }
+    return c
 }
 
 // NewBlockchain initializes a new blockchain with a genesis block.
 // The genesis block serves as the first block in the blockchain, establishing the foundation of the chain.
-func NewBlockchain() *Blockchain {
-    genesisBlock := NewBlock("Genesis Block", "", 0) // Create the genesis block (index 0).
-    return &Blockchain{[]Block{genesisBlock}}        // Initialize blockchain with the genesis block.
+// options.WithTimeout bounds how long AddBlock mines each block; other options are ignored.
+func NewBlockchain(opts ...options.Option) *Blockchain {
+    o := options.New(opts...)
+    genesisBlock := NewBlock("Genesis Block", wire.Hash{}, 0) // Create the genesis block (index 0).
+    return &Blockchain{Blocks: []Block{genesisBlock}, timeout: o.Timeout} // Initialize blockchain with the genesis block.
+}
+
+// AttachBlockStore connects a block store to the blockchain. Every block already in the chain is written
+// to the store immediately, and every block appended afterwards is written through to it as well.
+func (bc *Blockchain) AttachBlockStore(store storage.BlockStore) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return bc.attachBlockStore(store)
+}
+
+func (bc *Blockchain) attachBlockStore(store storage.BlockStore) error {
+    for i := range bc.Blocks {
+        if err := store.Put(bc.Blocks[i].ToWire()); err != nil {
+            return err
+        }
+    }
+    bc.blockStore = store
+    return nil
+}
+
+// AttachState makes the chain check the transactions of every block it appends against s, refusing a block that
+// breaks its rules with an error wrapping ledger.ErrInvalid, and apply them to s once the block is appended. s is
+// first rebuilt from the blocks already in the chain; if they break its rules, the error is returned and neither
+// s nor the chain changes.
+func (bc *Blockchain) AttachState(s ledger.State) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    receipts, err := replayState(s, bc.Blocks)
+    if err != nil {
+        return err
+    }
+    bc.state, bc.receipts = s, receipts
+    return nil
+}
+
+// checkState reports why a block carrying data would break the rules of the attached state, if there is one.
+func (bc *Blockchain) checkState(data string) error {
+    if bc.state == nil {
+        return nil
+    }
+    return bc.state.Check(data)
+}
+
+// checkBlock is checkState for a block, which must also commit to the state and receipts roots its data leads
+// to. It returns the receipts of the block's transactions.
+func (bc *Blockchain) checkBlock(block Block) ([]ledger.Receipt, error) {
+    if bc.state == nil {
+        return nil, nil
+    }
+    return ledger.CheckRoots(bc.state, block.Data, ledger.Roots{State: block.StateRoot, Receipts: block.ReceiptsRoot})
+}
+
+// roots returns the state and receipts roots a block carrying data commits to once it is appended: zero if no
+// state is attached, or if it computes no such root. The caller must have checked data.
+func (bc *Blockchain) roots(data string) (state, receipts wire.Hash) {
+    if bc.state == nil {
+        return wire.Hash{}, wire.Hash{}
+    }
+    r, _, _ := ledger.RootsAfter(bc.state, data)
+    return r.State, r.Receipts
+}
+
+// indexReceipts records the receipts of the block at index, so GetReceipt finds them. The caller must hold bc.mu.
+func (bc *Blockchain) indexReceipts(index int, receipts []ledger.Receipt) {
+    if len(receipts) > 0 && bc.receipts == nil {
+        bc.receipts = make(map[string]ledger.Receipt)
+    }
+    for _, r := range receipts {
+        r.Block = int64(index)
+        bc.receipts[r.Tx] = r
+    }
+}
+
+// GetReceipt returns the receipt of the transaction with the given ledger.TxHash, or false if no block of the
+// chain carries it or no state that records receipts is attached (see ledger.Executor).
+func (bc *Blockchain) GetReceipt(tx string) (ledger.Receipt, bool) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    r, ok := bc.receipts[tx]
+    return r, ok
+}
+
+// replayState rebuilds s, if it is not nil, from blocks, checking the roots each of them commits to, and returns
+// the receipts of their transactions by hash. If they break its rules, s is left unchanged.
+func replayState(s ledger.State, blocks []Block) (map[string]ledger.Receipt, error) {
+    if s == nil {
+        return nil, nil
+    }
+    data := make([]string, len(blocks))
+    roots := make([]ledger.Roots, len(blocks))
+    for i := range blocks {
+        data[i], roots[i] = blocks[i].Data, ledger.Roots{State: blocks[i].StateRoot, Receipts: blocks[i].ReceiptsRoot}
+    }
+    if _, err := ledger.ReplayRoots(s.Clone(), data, roots); err != nil {
+        return nil, err
+    }
+    receipts, err := ledger.ReplayRoots(s, data, roots)
+    if err != nil {
+        return nil, err
+    }
+    index := make(map[string]ledger.Receipt)
+    for i, block := range blocks {
+        for _, r := range receipts[i] {
+            r.Block = int64(block.Index)
+            index[r.Tx] = r
+        }
+    }
+    return index, nil
+}
+
+// AttachClock sets the clock that timestamps blocks appended from now on, in place of the system clock.
+func (bc *Blockchain) AttachClock(c clock.Clock) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    bc.clock = c
+}
+
+// AttachLogger sets the logger that records consensus steps from now on. Records are labelled with the
+// algorithm, and those about a single node carry the node's identifier as well.
+func (bc *Blockchain) AttachLogger(l *slog.Logger) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    bc.logger = logging.Algorithm(l, "pow")
+}
+
+// Save persists the blockchain's blocks to the given store under name, so the chain can be reloaded
+// after the process restarts.
+func (bc *Blockchain) Save(store storage.Store, name string) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    chain := &wire.Chain{Algorithm: "pow"}
+    for i := range bc.Blocks {
+        chain.Blocks = append(chain.Blocks, bc.Blocks[i].ToWire()) // Convert each block to the shared wire format.
+    }
+    return store.Save(name, chain)
+}
+
+// Load replaces the blockchain's blocks with the chain previously saved under name.
+// Every block's hash and its link to the previous block are verified first, so a corrupted
+// or tampered file is rejected instead of silently becoming the new chain.
+func (bc *Blockchain) Load(store storage.Store, name string) error {
+    chain, err := store.Load(name)
+    if err != nil {
+        return err
+    }
+    blocks, err := verifyChain(chain, fmt.Sprintf("chain %q", name))
+    if err != nil {
+        return err
+    }
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    receipts, err := replayState(bc.state, blocks)
+    if err != nil {
+        return err
+    }
+    bc.receipts = receipts
+    bc.Blocks = blocks // Only replace the chain once every block has been verified.
+    if bc.blockStore != nil {
+        return bc.attachBlockStore(bc.blockStore) // Re-index the reloaded blocks in the attached store.
+    }
+    return nil
+}
+
+// Export writes the chain and the difficulty it is mined at to w as a snapshot, which Import reads back to resume the run
+// where it stopped.
+func (bc *Blockchain) Export(w io.Writer) error {
+    bc.mu.Lock()
+    snapshot := &wire.Snapshot{Chain: &wire.Chain{Algorithm: "pow"}}
+    for i := range bc.Blocks {
+        snapshot.Chain.Blocks = append(snapshot.Chain.Blocks, bc.Blocks[i].ToWire())
+    }
+    snapshot.Difficulty = int32(bc.Difficulty)
+    bc.mu.Unlock()
+    return wire.WriteSnapshot(w, snapshot)
+}
+
+// Import replaces the chain and the difficulty it is mined at with a snapshot written by Export. As with Load, every block is
+// verified first, and nothing changes if the snapshot is rejected.
+func (bc *Blockchain) Import(r io.Reader) error {
+    snapshot, err := wire.ReadSnapshot(r)
+    if err != nil {
+        return err
+    }
+    blocks, err := verifyChain(snapshot.GetChain(), "the snapshot")
+    if err != nil {
+        return err
+    }
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    receipts, err := replayState(bc.state, blocks)
+    if err != nil {
+        return err
+    }
+    bc.receipts = receipts
+    bc.Blocks = blocks
+    bc.Difficulty = int(snapshot.GetDifficulty())
+    if bc.blockStore != nil {
+        return bc.attachBlockStore(bc.blockStore) // Re-index the imported blocks in the attached store.
+    }
+    return nil
+}
+
+// verifyChain converts a chain read from source, such as a store or a snapshot, back into blocks, verifying
+// first that it was produced by pow and that every block's hash and its link to the previous block are intact.
+func verifyChain(chain *wire.Chain, source string) ([]Block, error) {
+    if chain.GetAlgorithm() != "pow" {
+        return nil, fmt.Errorf("pow: %s was produced by %q", source, chain.GetAlgorithm())
+    }
+    if len(chain.GetBlocks()) == 0 {
+        return nil, fmt.Errorf("pow: %s has no genesis block", source)
+    }
+
+    blocks := make([]Block, 0, len(chain.GetBlocks()))
+    for i, w := range chain.GetBlocks() {
+        block := BlockFromWire(w)
+        if block.Hash != block.CalculateHash() {
+            return nil, fmt.Errorf("pow: block %d of %s has an invalid hash", i, source)
+        }
+        if i > 0 && block.PrevHash != blocks[i-1].Hash {
+            return nil, fmt.Errorf("pow: block %d of %s does not link to its predecessor", i, source)
+        }
+        blocks = append(blocks, block)
+    }
+    return blocks, nil
 }
 
 // Footer: Security Considerations and Architectural Decisions
@@ -0,0 +1,82 @@
+package pow
+
+import (
+    "time"
+
+    "consensus-algorithms-edu/wire"
+)
+
+// RetargetWindow is the number of blocks between two retargets of a Retarget without a Window. Bitcoin retargets
+// every 2016 blocks, two weeks at its target interval; a simulation cannot run that long, so the default here is
+// much shorter.
+const RetargetWindow = 20
+
+// MaxRetargetFactor bounds how much one retarget of a Retarget without a MaxFactor may change the difficulty, up
+// or down, as Bitcoin bounds it.
+const MaxRetargetFactor = 4
+
+// BitcoinRetarget is Bitcoin's difficulty adjustment: a block every 10 minutes, retargeted every 2016 blocks by at
+// most 4 times either way.
+var BitcoinRetarget = Retarget{Interval: 10 * time.Minute, Window: 2016, MaxFactor: 4}
+
+// Retarget adjusts the mining difficulty so that blocks keep coming at a target interval whatever the network's
+// hash power, as Bitcoin's difficulty adjustment does. The blocks after the genesis block are split into periods
+// of Window blocks; at the end of each, the time between the timestamps of its first and last block is compared
+// with the time its blocks should have taken, and the work of every block of the next period is multiplied by how
+// much faster they came, at most MaxFactor times either way.
+//
+// The three settings trade against each other. A short window follows a change of hash power within a few blocks
+// but also follows the luck of those few blocks, since block times vary as much as they average; a long window
+// averages the luck out but leaves the chain too fast or too slow for longer. A tight bound keeps one lucky or
+// manipulated period from swinging the difficulty, and takes several periods to follow a real change.
+//
+// The measured time comes from timestamps that the miners choose themselves, which is what makes retargeting
+// worth attacking: a period that appears to have taken longer makes the next one easier. TimestampRules bound
+// how far the timestamps can stray.
+//
+// Like a Bomb, the work is divided out of MinerConfig.MineProbability rather than raising the number of leading
+// zeros a hash needs, which could only change the difficulty sixteenfold at a time.
+type Retarget struct {
+    Interval  time.Duration // Block interval the difficulty is adjusted toward; the difficulty is fixed if 0.
+    Window    int           // Blocks between two retargets; RetargetWindow if 0.
+    MaxFactor float64       // Most that one retarget multiplies or divides the work by; MaxRetargetFactor if 0, and at least 1.
+}
+
+// Work returns how many times the base work the block after chain takes, chain running from the genesis block to
+// the block it extends: the product of the adjustment at the end of every period before it. It is 1 without an
+// Interval and throughout the first period.
+func (r Retarget) Work(chain []*wire.Block) float64 {
+    work := 1.0
+    if r.Interval <= 0 {
+        return work
+    }
+    window, bound := r.window(), r.maxFactor()
+    expected := time.Duration(window-1) * r.Interval // Between the first and the last block of a period.
+    for end := 1 + window; end <= len(chain); end += window {
+        first, last := chain[end-window], chain[end-1]
+        actual := time.Duration(last.GetTimestamp() - first.GetTimestamp())
+        factor := bound // A period that took no time at all is as fast as can be.
+        if actual > 0 {
+            factor = min(max(float64(expected)/float64(actual), 1/bound), bound)
+        }
+        work *= factor
+    }
+    return work
+}
+
+// window returns Window, or RetargetWindow if it is not set. A window of one block has no interval to measure,
+// so it is taken as two.
+func (r Retarget) window() int {
+    if r.Window <= 0 {
+        return RetargetWindow
+    }
+    return max(r.Window, 2)
+}
+
+// maxFactor returns MaxFactor, or MaxRetargetFactor if it is not set.
+func (r Retarget) maxFactor() float64 {
+    if r.MaxFactor == 0 {
+        return MaxRetargetFactor
+    }
+    return max(r.MaxFactor, 1)
+}
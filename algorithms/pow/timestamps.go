@@ -0,0 +1,104 @@
+package pow
+
+import (
+    "errors"
+    "fmt"
+    "log/slog"
+    "math"
+    "slices"
+    "time"
+
+    "consensus-algorithms-edu/blocktree"
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/wire"
+)
+
+// ErrTimestampTooEarly is returned by TimestampRules.Check for a block not timestamped after the median time past.
+var ErrTimestampTooEarly = errors.New("pow: timestamp is not after the median time past")
+
+// ErrTimestampTooLate is returned by TimestampRules.Check for a block timestamped too far past the clock.
+var ErrTimestampTooLate = errors.New("pow: timestamp is too far in the future")
+
+// BitcoinTimestamps are the rules Bitcoin's nodes apply: after the median of the last 11 blocks, and no more than
+// two hours past the node's clock.
+var BitcoinTimestamps = TimestampRules{MedianSpan: 11, MaxFuture: 2 * time.Hour}
+
+// TimestampRules are the checks a miner makes on the timestamps of the blocks it follows. Miners do not agree on
+// the time, so a timestamp cannot be required to be exact; but left unchecked it is whatever the block's miner
+// wants, and Retarget believes it. The rules pin it between two bounds that no miner controls alone:
+//
+//   - Median time past: a block must be timestamped after the median timestamp of the MedianSpan blocks before
+//     it. The median moves forward however the timestamps of a few blocks lie, so timestamps keep increasing
+//     overall even though a block may be timestamped before its parent.
+//   - Future limit: a block timestamped more than MaxFuture past the miner's own clock is refused, so a miner
+//     cannot make time appear to pass faster than it does. A miner whose clock catches up may accept the block
+//     later, once another block extends it.
+type TimestampRules struct {
+    MedianSpan int           // Blocks whose median timestamp a block's must exceed; no such rule if 0.
+    MaxFuture  time.Duration // How far past the miner's clock a block's timestamp may be; no such rule if 0.
+}
+
+// Stamp chooses the timestamp of a block a miner found at index, given the time the miner's clock tells.
+type Stamp func(index int, now time.Time) time.Time
+
+// MedianTimePast returns the median of the timestamps of the last span blocks of chain, or of every block if it
+// has fewer. It returns the lowest Timestamp for an empty chain.
+func MedianTimePast(chain []*wire.Block, span int) wire.Timestamp {
+    if len(chain) == 0 || span <= 0 {
+        return math.MinInt64
+    }
+    var stamps []int64
+    for _, block := range chain[max(len(chain)-span, 0):] {
+        stamps = append(stamps, block.GetTimestamp())
+    }
+    slices.Sort(stamps)
+    return wire.Timestamp(stamps[len(stamps)/2])
+}
+
+// Bounds returns the earliest and the latest timestamp the rules allow for the block after chain, at time now.
+func (r TimestampRules) Bounds(chain []*wire.Block, now time.Time) (earliest, latest wire.Timestamp) {
+    earliest, latest = math.MinInt64, math.MaxInt64
+    if r.MedianSpan > 0 {
+        earliest = MedianTimePast(chain, r.MedianSpan) + 1
+    }
+    if r.MaxFuture > 0 {
+        latest = wire.TimestampOf(now.Add(r.MaxFuture))
+    }
+    return earliest, latest
+}
+
+// Check reports whether the timestamp of block, which extends chain, keeps to the rules at time now. It returns
+// ErrTimestampTooEarly or ErrTimestampTooLate, wrapped with the block's index, if it does not.
+func (r TimestampRules) Check(chain []*wire.Block, block *wire.Block, now time.Time) error {
+    earliest, latest := r.Bounds(chain, now)
+    switch ts := wire.Timestamp(block.GetTimestamp()); {
+    case ts < earliest:
+        return fmt.Errorf("block %d: %w: %v is not after %v", block.GetIndex(), ErrTimestampTooEarly, ts, earliest-1)
+    case ts > latest:
+        return fmt.Errorf("block %d: %w: %v is after %v", block.GetIndex(), ErrTimestampTooLate, ts, latest)
+    }
+    return nil
+}
+
+// rule wraps choose so that a tip is only followed if every block of its branch after the fork from the current
+// head keeps to the rules at the clock's time, much as blocktree.Replica checks a branch against its state.
+func (r TimestampRules) rule(choose blocktree.Rule, c clock.Clock, logger *slog.Logger) blocktree.Rule {
+    if r == (TimestampRules{}) {
+        return choose
+    }
+    return func(t *blocktree.Tree, a, b *wire.Block) bool {
+        if !choose(t, a, b) {
+            return false
+        }
+        _, branch, _ := t.Diverge(a, b)
+        chain := t.Branch(a)
+        now := c.Now()
+        for i := len(chain) - len(branch); i < len(chain); i++ {
+            if err := r.Check(chain[:i], chain[i], now); err != nil {
+                logger.Warn("refused branch", "tip", a.GetIndex(), "hash", a.GetHash(), "err", err)
+                return false
+            }
+        }
+        return true
+    }
+}
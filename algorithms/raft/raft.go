@@ -7,27 +7,62 @@
 package raft
 
 import (
-    "crypto/sha256"
+    "context"
+    "errors"
     "fmt"
+    "io"
+    "iter"
+    "log/slog"
+    "slices"
     "strconv"
+    "sync"
     "time"
+
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/events"
+    "consensus-algorithms-edu/feed"
+    "consensus-algorithms-edu/logging"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/quorum"
+    "consensus-algorithms-edu/storage"
+    "consensus-algorithms-edu/wire"
+)
+
+var (
+    // ErrNoQuorum is returned by Lead when a majority of the nodes did not approve the proposed block.
+    ErrNoQuorum = errors.New("raft: block rejected by the majority")
+
+    // ErrInvalidBlock is returned by AddBlock for a block that does not extend the chain: its index or previous
+    // hash does not follow the last block, or its hash does not match its contents.
+    ErrInvalidBlock = errors.New("raft: block does not extend the chain")
+
+    // ErrNoLeader is returned by AddNode and RemoveNode when the network has no leader to commit the change.
+    ErrNoLeader = errors.New("raft: no leader")
 )
 
 // Block represents an individual block in the blockchain.
 // It contains information such as the index, timestamp, data, and cryptographic hashes.
 type Block struct {
-    Index     int    // Position of the block in the blockchain.
-    Timestamp string // Time when the block was created.
-    Data      string // Data contained within the block (e.g., transactions).
-    PrevHash  string // Hash of the previous block to maintain immutability.
-    Hash      string // SHA-256 hash of the current block.
+    Index     int            // Position of the block in the blockchain.
+    Timestamp wire.Timestamp // When the block was created, in nanoseconds since the Unix epoch.
+    Data      string         // Data contained within the block (e.g., transactions).
+    PrevHash  wire.Hash      // Hash of the previous block to maintain immutability.
+    Hash      wire.Hash      // SHA-256 hash of the current block.
 }
 
 // Blockchain represents the distributed ledger that is managed by multiple nodes.
 type Blockchain struct {
-    Blocks []Block // A slice of all blocks in the blockchain.
-    Nodes  []Node  // A list of nodes participating in the Raft consensus network.
-    Leader *Node   // Pointer to the current leader node responsible for managing updates.
+    mu          sync.Mutex         // Guards every field, and the fields of the nodes, while a method runs.
+    Blocks      []Block            // A slice of all blocks in the blockchain.
+    Nodes       []*Node            // A list of nodes participating in the Raft consensus network.
+    Leader      *Node              // Pointer to the current leader node responsible for managing updates.
+    blockStore  storage.BlockStore // Optional block store that every appended block is written through to.
+    clock       clock.Clock        // Clock that timestamps appended blocks; the system clock if nil.
+    logger      *slog.Logger       // Logger that records consensus steps; silent if nil.
+    publisher   events.Publisher   // Receives votes and leader changes; nothing is published if nil.
+    quorum      quorum.Builder     // Decides which votes and approvals suffice; a majority if nil.
+    subscribers feed.Feed[Block]   // Delivers every appended block to Subscribe's channels.
 }
 
 // Node represents an individual node within the Raft network.
@@ -35,15 +70,28 @@ type Blockchain struct {
 type Node struct {
     ID         int         // Unique identifier for the node.
     IsLeader   bool        // Indicates if the node is the leader.
+    Faulty     bool        // A crashed node that neither votes nor approves blocks; set with options.WithFaulty.
     Blockchain *Blockchain // Reference to the blockchain managed by the node.
+
+    // Restore, if set, is called by Recover to restore the node's state before it rejoins, e.g. from a
+    // storage.Store it persisted to. It runs without the blockchain's lock, so it may call the blockchain.
+    Restore func(n *Node) error
+
+    status node.Status // Where the node stands in its crash-recovery lifecycle.
 }
 
 // NewBlock creates a new block given data, the previous block's hash, and the index.
 // It calculates the block's hash to ensure integrity.
-func NewBlock(data string, prevHash string, index int) Block {
+func NewBlock(data string, prevHash wire.Hash, index int) Block {
+    return NewBlockAt(data, prevHash, index, clock.System.Now())
+}
+
+// NewBlockAt is NewBlock with an explicit creation time, for callers that keep their own clock such as a
+// simulation.
+func NewBlockAt(data string, prevHash wire.Hash, index int, at time.Time) Block {
     block := Block{
         Index:     index,
-        Timestamp: time.Now().String(), // Set the current timestamp for the block.
+        Timestamp: wire.TimestampOf(at),
         Data:      data,
         PrevHash:  prevHash,
     }
@@ -51,60 +99,295 @@ func NewBlock(data string, prevHash string, index int) Block {
     return block
 }
 
-// CalculateHash generates the SHA-256 hash of the block's contents.
+// CalculateHash generates the SHA-256 hash of the block, which is the hash of its header.
 // This ensures that any change to the block's data will produce a completely different hash.
-func (b *Block) CalculateHash() string {
-    record := strconv.Itoa(b.Index) + b.Timestamp + b.Data + b.PrevHash
-    hash := sha256.New()                // Create a new SHA-256 hash object.
-    hash.Write([]byte(record))          // Write the concatenated block data to the hash.
-    hashed := hash.Sum(nil)             // Compute the hash value.
-    return fmt.Sprintf("%x", hashed)    // Return the hash as a hexadecimal string.
+func (b *Block) CalculateHash() wire.Hash {
+    h := b.Header()
+    return h.Hash()
+}
+
+// Header returns the part of the block its hash covers, which commits to the data through its root.
+func (b *Block) Header() wire.Header {
+    return wire.Header{
+        Index:     int64(b.Index),
+        Timestamp: b.Timestamp,
+        Parent:    b.PrevHash,
+        Root:      wire.BodyRoot(b.Data),
+    }
+}
+
+// ToWire converts the block into the algorithm-neutral wire format used for messaging and storage.
+func (b *Block) ToWire() *wire.Block {
+    return &wire.Block{
+        Index:     int64(b.Index),
+        Timestamp: int64(b.Timestamp),
+        Data:      b.Data,
+        PrevHash:  b.PrevHash.String(),
+        Hash:      b.Hash.String(),
+    }
+}
+
+// BlockFromWire converts a wire-format block back into this package's Block type.
+// The hash is copied as-is rather than recalculated, so callers can still detect tampered blocks.
+func BlockFromWire(w *wire.Block) Block {
+    return Block{
+        Index:     int(w.GetIndex()),
+        Timestamp: wire.Timestamp(w.GetTimestamp()),
+        Data:      w.GetData(),
+        PrevHash:  w.ParentSum(),
+        Hash:      w.Sum(),
+    }
 }
 
 // AddBlock appends a new block to the blockchain.
-// This function is called once a new block is validated and consensus is achieved.
-func (bc *Blockchain) AddBlock(block Block) {
-    bc.Blocks = append(bc.Blocks, block) // Append the new block to the blockchain.
+// This function is called once a new block is validated and consensus is achieved. A block that does not extend
+// the chain is refused with ErrInvalidBlock. If a block store is attached, the block is written to it first and
+// any storage error is returned.
+func (bc *Blockchain) AddBlock(block Block) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return bc.addBlock(context.Background(), block)
+}
+
+// addBlock is AddBlock for callers that hold the lock. Records are logged with ctx.
+func (bc *Blockchain) addBlock(ctx context.Context, block Block) error {
+    if tip := bc.head(); block.Index != tip.Index+1 || block.PrevHash != tip.Hash || block.Hash != block.CalculateHash() {
+        return fmt.Errorf("%w: block %d after block %d", ErrInvalidBlock, block.Index, tip.Index)
+    }
+    if bc.blockStore != nil {
+        if err := bc.blockStore.Put(block.ToWire()); err != nil {
+            return err // Leave the in-memory chain untouched if the store rejected the block.
+        }
+    }
+    bc.Blocks = append(bc.Blocks, block) // Append the new block to the chain.
+    bc.subscribers.Publish(block)
+    logging.Or(bc.logger).InfoContext(ctx, "committed block", "index", block.Index, "hash", block.Hash.String())
+    return nil
 }
 
 // NewBlockchain initializes a new blockchain with a genesis block.
 // The genesis block is the initial block that forms the foundation of the blockchain.
 func NewBlockchain() *Blockchain {
-    genesisBlock := NewBlock("Genesis Block", "", 0) // Create the genesis block (index 0).
+    genesisBlock := NewBlock("Genesis Block", wire.Hash{}, 0) // Create the genesis block (index 0).
     return &Blockchain{
         Blocks: []Block{genesisBlock}, // Initialize with the genesis block.
-        Nodes:  []Node{},              // Initialize an empty list of nodes.
+        Nodes:  []*Node{},             // Initialize an empty list of nodes.
+    }
+}
+
+// Snapshot returns a copy of the blocks in the chain, which stays unchanged while other goroutines add blocks.
+func (bc *Blockchain) Snapshot() []Block {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return append([]Block(nil), bc.Blocks...)
+}
+// Height returns the index of the chain's last block: the number of blocks added after the genesis block.
+func (bc *Blockchain) Height() int {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return len(bc.Blocks) - 1
+}
+
+// Head returns the chain's last block.
+func (bc *Blockchain) Head() Block {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return bc.head()
+}
+
+// head returns the chain's last block. The caller must hold bc.mu.
+func (bc *Blockchain) head() Block {
+    return bc.Blocks[len(bc.Blocks)-1]
+}
+
+// GetBlockByIndex returns the block at index, or false if the chain has no block there.
+func (bc *Blockchain) GetBlockByIndex(index int) (Block, bool) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    if index < 0 || index >= len(bc.Blocks) {
+        return Block{}, false
+    }
+    return bc.Blocks[index], true
+}
+
+// GetBlockByHash returns the block with the given hash, or false if the chain has none. The chain is searched
+// from its head, since recent blocks are the ones most often looked up.
+func (bc *Blockchain) GetBlockByHash(hash wire.Hash) (Block, bool) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    for i := len(bc.Blocks) - 1; i >= 0; i-- {
+        if bc.Blocks[i].Hash == hash {
+            return bc.Blocks[i], true
+        }
+    }
+    return Block{}, false
+}
+
+// All returns an iterator over the index and block of every block in the chain, starting at the genesis block.
+func (bc *Blockchain) All() iter.Seq2[int, Block] {
+    return bc.Range(0, -1)
+}
+
+// Range returns an iterator over the blocks from index from up to and including index to, or up to the head if
+// to is negative. It iterates over the blocks as they were when it started, so the loop body may add blocks.
+func (bc *Blockchain) Range(from, to int) iter.Seq2[int, Block] {
+    return func(yield func(int, Block) bool) {
+        bc.mu.Lock()
+        if to < 0 || to >= len(bc.Blocks) {
+            to = len(bc.Blocks) - 1
+        }
+        from := max(from, 0)
+        var blocks []Block
+        if from <= to {
+            blocks = slices.Clone(bc.Blocks[from : to+1])
+        }
+        bc.mu.Unlock()
+        for i, block := range blocks {
+            if !yield(from+i, block) {
+                return
+            }
+        }
     }
 }
 
+// Subscribe returns a channel that receives every block appended to the chain from now on, in order, so that a
+// consumer can wait for new blocks rather than poll Blocks. Blocks replaced by Import are not delivered. Pass the
+// channel to Unsubscribe once done with it, which closes it.
+func (bc *Blockchain) Subscribe() <-chan Block {
+    return bc.subscribers.Subscribe()
+}
+
+// Unsubscribe stops delivering blocks to ch, a channel returned by Subscribe, and closes it.
+func (bc *Blockchain) Unsubscribe(ch <-chan Block) {
+    bc.subscribers.Unsubscribe(ch)
+}
+
+// String summarizes the chain in one line: its length and the block at its head.
+func (bc *Blockchain) String() string {
+    return wire.Summarize("raft", bc.wireBlocks())
+}
+
+// Dump formats every block of the chain for printing, one line per block.
+func (bc *Blockchain) Dump() string {
+    return wire.Dump(bc.wireBlocks())
+}
+
+// Diff reports the first height at which chains a and b hold different blocks, or at which one of them ends.
+func Diff(a, b *Blockchain) wire.Divergence {
+    return wire.Diff(a.wireBlocks(), b.wireBlocks())
+}
+
+// wireBlocks converts the chain's blocks to the shared wire format.
+func (bc *Blockchain) wireBlocks() []*wire.Block {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    blocks := make([]*wire.Block, len(bc.Blocks))
+    for i := range bc.Blocks {
+        blocks[i] = bc.Blocks[i].ToWire()
+    }
+    return blocks
+}
+
+// Clone returns an independent copy of the chain and of its nodes, so that a simulation can fork a scenario mid-run and see
+// what happens next without changing the original: blocks added to either copy leave the other unchanged. The
+// copy keeps the original's clock, logger, event publisher and quorum but not its block store or its subscribers.
+func (bc *Blockchain) Clone() *Blockchain {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    c := &Blockchain{
+        Blocks:    slices.Clone(bc.Blocks),
+        Nodes:     make([]*Node, len(bc.Nodes)),
+        clock:     bc.clock,
+        logger:    bc.logger,
+        publisher: bc.publisher,
+        quorum:    bc.quorum,
+    }
+    for i, n := range bc.Nodes {
+        clone := *n
+        clone.Blockchain = c
+        c.Nodes[i] = &clone
+    }
+    c.setLeader(bc.leaderID())
+    return c
+}
+
+// Clone returns the node's counterpart in a Clone of its blockchain, to fork a scenario from one node's point of
+// view. A node that is no longer a member of the network is copied on its own, attached to the clone.
+func (n *Node) Clone() *Node {
+    c := n.Blockchain.Clone()
+    for _, clone := range c.Nodes {
+        if clone.ID == n.ID {
+            return clone
+        }
+    }
+    clone := *n
+    clone.Blockchain = c
+    return &clone
+}
+
 // ProposeBlock allows the leader node to create a new block proposal based on the latest block.
 func (n *Node) ProposeBlock(data string) Block {
-    prevBlock := n.Blockchain.Blocks[len(n.Blockchain.Blocks)-1] // Retrieve the latest block.
-    newBlock := NewBlock(data, prevBlock.Hash, prevBlock.Index+1) // Create a new block with the provided data.
+    n.Blockchain.mu.Lock()
+    defer n.Blockchain.mu.Unlock()
+    return n.proposeBlock(data)
+}
+
+func (n *Node) proposeBlock(data string) Block {
+    prevBlock := n.Blockchain.head() // Retrieve the latest block.
+    newBlock := NewBlockAt(data, prevBlock.Hash, prevBlock.Index+1, clock.Or(n.Blockchain.clock).Now()) // Create a new block with the provided data.
     return newBlock
 }
 
 // BroadcastBlock sends a proposed block to all nodes for verification.
 // A block is considered valid if more than half of the nodes approve it.
 func (bc *Blockchain) BroadcastBlock(block Block) bool {
-    approvals := 0
-    totalNodes := len(bc.Nodes)
-    
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    approved, _ := bc.broadcastBlock(context.Background(), block)
+    return approved
+}
+
+// broadcastBlock is BroadcastBlock that stops asking nodes for approval once ctx is done.
+func (bc *Blockchain) broadcastBlock(ctx context.Context, block Block) (bool, error) {
+    var approvals []int32
     for _, node := range bc.Nodes {
-        if node.VerifyBlock(block) {
-            approvals++ // Count nodes that approve the block.
+        if err := ctx.Err(); err != nil {
+            return false, err
+        }
+        if node.verifyBlock(block) {
+            approvals = append(approvals, int32(node.ID)) // Collect the nodes that approve the block.
         }
     }
     
-    return approvals > totalNodes/2 // Return true if a majority of nodes approve the block.
+    return bc.reached(approvals), nil // Report whether a quorum of nodes approve the block.
+}
+
+// reached reports whether the nodes that approved form a quorum of the current nodes: a majority, unless
+// options.WithQuorum chose another.
+func (bc *Blockchain) reached(approvers []int32) bool {
+    members := make([]int32, len(bc.Nodes))
+    for i, n := range bc.Nodes {
+        members[i] = int32(n.ID)
+    }
+    build := bc.quorum
+    if build == nil {
+        build = quorum.Majority
+    }
+    return build(members).Reached(approvers)
 }
 
 // VerifyBlock allows a node to verify the validity of a proposed block.
 // It checks if the previous hash matches the last block in the chain and if the block hash is correct.
 func (n *Node) VerifyBlock(block Block) bool {
-    prevBlock := n.Blockchain.Blocks[len(n.Blockchain.Blocks)-1] // Retrieve the latest block.
+    n.Blockchain.mu.Lock()
+    defer n.Blockchain.mu.Unlock()
+    return n.verifyBlock(block)
+}
+
+func (n *Node) verifyBlock(block Block) bool {
+    prevBlock := n.Blockchain.head() // Retrieve the latest block.
     // Check if the proposed block's previous hash matches the latest block and if the hash is valid.
-    if block.PrevHash == prevBlock.Hash {
+    if !n.Faulty && n.status == node.Running && block.PrevHash == prevBlock.Hash { // A crashed node never answers.
         return block.Hash == block.CalculateHash()
     }
     return false
@@ -112,48 +395,103 @@ func (n *Node) VerifyBlock(block Block) bool {
 
 // CommitBlock commits a verified block to the blockchain.
 // This function is called by all nodes once consensus has been achieved.
-func (n *Node) CommitBlock(block Block) {
-    n.Blockchain.AddBlock(block) // Append the verified block to the blockchain.
+func (n *Node) CommitBlock(block Block) error {
+    return n.Blockchain.AddBlock(block) // Append the verified block to the blockchain.
 }
 
 // RequestVote allows a node to request votes from other nodes during the leader election process.
 // If the node receives a majority of votes, it becomes the new leader.
 func (n *Node) RequestVote() bool {
-    votes := 0
-    totalNodes := len(n.Blockchain.Nodes)
-    
+    won, _ := n.RequestVoteContext(context.Background())
+    return won
+}
+
+// RequestVoteContext is RequestVote that abandons the election once ctx is done, returning the context's error.
+// Votes already granted stay granted, but the node does not become the leader. A node that is not running
+// cannot stand for election and returns node.ErrCrashed.
+func (n *Node) RequestVoteContext(ctx context.Context) (bool, error) {
+    n.Blockchain.mu.Lock()
+    defer n.Blockchain.mu.Unlock()
+    if n.status != node.Running {
+        return false, node.ErrCrashed
+    }
+    var votes []int32
+    n.Blockchain.publish(events.Event{Type: events.Election, Node: n.Name()})
+
     for _, node := range n.Blockchain.Nodes {
-        if node.VoteFor(n.ID) {
-            votes++ // Count votes received from other nodes.
+        if err := ctx.Err(); err != nil {
+            logging.Or(n.Blockchain.logger).InfoContext(ctx, "abandoned election", logging.NodeKey, n.ID, "votes", len(votes), "error", err)
+            return false, err
+        }
+        if node.voteFor(ctx, n.ID) {
+            votes = append(votes, int32(node.ID)) // Collect the nodes that voted for this one.
         }
     }
     
-    if votes > totalNodes/2 {
-        n.IsLeader = true            // Node becomes the leader if it receives a majority of votes.
+    if n.Blockchain.reached(votes) {
+        n.IsLeader = true            // Node becomes the leader if it receives a quorum of votes.
         n.Blockchain.Leader = n      // Update the blockchain's leader reference.
-        return true
+        logging.Or(n.Blockchain.logger).InfoContext(ctx, "became leader", logging.NodeKey, n.ID, "votes", len(votes))
+        n.Blockchain.publish(events.Event{Type: events.LeaderChange, Node: n.Name(), Leader: n.Name()})
+        return true, nil
     }
-    return false
+    logging.Or(n.Blockchain.logger).InfoContext(ctx, "lost election", logging.NodeKey, n.ID, "votes", len(votes))
+    return false, nil
 }
 
 // VoteFor allows a node to vote for a candidate during the leader election.
 // In this simplified version, nodes always vote for the requesting candidate.
 func (n *Node) VoteFor(candidateID int) bool {
+    n.Blockchain.mu.Lock()
+    defer n.Blockchain.mu.Unlock()
+    return n.voteFor(context.Background(), candidateID)
+}
+
+func (n *Node) voteFor(ctx context.Context, candidateID int) bool {
+    if n.Faulty || n.status != node.Running {
+        return false // A crashed node never answers.
+    }
+    logging.Or(n.Blockchain.logger).InfoContext(ctx, "granted vote", logging.NodeKey, n.ID, "candidate", candidateID)
+    n.Blockchain.publish(events.Event{Type: events.Vote, Node: n.Name()})
     return true // Simplified: Always vote in favor of the candidate.
 }
 
 // Lead allows the leader to propose and commit a new block to the blockchain.
-// The leader proposes a block, broadcasts it for approval, and if approved, commits it.
-func (n *Node) Lead(data string) {
-    if n.IsLeader {
-        newBlock := n.ProposeBlock(data) // Leader proposes a new block.
-        // Broadcast the proposed block and commit it if approved by the majority.
-        if n.Blockchain.BroadcastBlock(newBlock) {
-            for _, node := range n.Blockchain.Nodes {
-                node.CommitBlock(newBlock)
-            }
-        }
+// The leader proposes a block, broadcasts it for approval, and if approved, commits it and returns it. A node that
+// is not the leader returns ErrNotLeader, and a block the majority did not approve ErrNoQuorum.
+func (n *Node) Lead(data string) (Block, error) {
+    return n.LeadContext(context.Background(), data)
+}
+
+// LeadContext is Lead bounded by ctx. The context is checked before every node is asked to approve the block, and
+// once it is done the round stops with the context's error and commits nothing. Records logged during the round
+// carry the context's trace, if it has one (see logging.WithTrace).
+func (n *Node) LeadContext(ctx context.Context, data string) (Block, error) {
+    n.Blockchain.mu.Lock()
+    defer n.Blockchain.mu.Unlock()
+    return n.lead(ctx, data)
+}
+
+// lead is LeadContext for callers that hold the lock.
+func (n *Node) lead(ctx context.Context, data string) (Block, error) {
+    if !n.IsLeader {
+        return Block{}, ErrNotLeader
+    }
+    newBlock := n.proposeBlock(data) // Leader proposes a new block.
+    // Broadcast the proposed block and commit it if approved by the majority.
+    // Every node shares the same Blockchain in this simulation, so the block is committed once on their behalf.
+    approved, err := n.Blockchain.broadcastBlock(ctx, newBlock)
+    if err != nil {
+        return Block{}, err
+    }
+    if !approved {
+        logging.Or(n.Blockchain.logger).WarnContext(ctx, "block rejected by the majority", logging.NodeKey, n.ID, "index", newBlock.Index)
+        return Block{}, ErrNoQuorum
     }
+    if err := n.Blockchain.addBlock(ctx, newBlock); err != nil {
+        return Block{}, err
+    }
+    return newBlock, nil
 }
 
 // NewNode creates a new node with the given ID and associates it with a blockchain.
@@ -165,18 +503,320 @@ func NewNode(id int, blockchain *Blockchain) *Node {
     }
 }
 
-// NewRaftNetwork initializes a Raft network with the specified number of nodes.
-// The nodes collaborate to reach consensus and elect a leader to manage block proposals.
-func NewRaftNetwork(size int) *Blockchain {
+// Name returns the name the node is reported under in events, e.g. "node-3".
+func (n *Node) Name() string {
+    return "node-" + strconv.Itoa(n.ID)
+}
+
+// Status returns where the node stands in its crash-recovery lifecycle.
+func (n *Node) Status() node.Status {
+    n.Blockchain.mu.Lock()
+    defer n.Blockchain.mu.Unlock()
+    return n.status
+}
+
+// Crash stops a running node. It no longer votes or approves blocks, and a leader loses its leadership, as
+// leadership lives only in memory: the network has no leader until another node wins an election.
+func (n *Node) Crash() {
+    n.Blockchain.mu.Lock()
+    defer n.Blockchain.mu.Unlock()
+    if n.status != node.Running {
+        return
+    }
+    n.status = node.Crashed
+    if n.IsLeader {
+        n.IsLeader = false
+        if n.Blockchain.Leader != nil && n.Blockchain.Leader.ID == n.ID {
+            n.Blockchain.Leader = nil
+        }
+    }
+    logging.Or(n.Blockchain.logger).Info("crashed", logging.NodeKey, n.ID)
+}
+
+// Recover brings a crashed node back as a follower. The node is Recovering while its Restore hook runs and
+// Running afterwards; if Restore fails, the node stays crashed and the error is returned. Recovering a node
+// that has not crashed returns node.ErrNotCrashed.
+func (n *Node) Recover() error {
+    n.Blockchain.mu.Lock()
+    if n.status != node.Crashed {
+        n.Blockchain.mu.Unlock()
+        return node.ErrNotCrashed
+    }
+    n.status = node.Recovering
+    restore := n.Restore
+    n.Blockchain.mu.Unlock()
+
+    var err error
+    if restore != nil {
+        err = restore(n)
+    }
+    n.Blockchain.mu.Lock()
+    defer n.Blockchain.mu.Unlock()
+    if err != nil {
+        n.status = node.Crashed
+        logging.Or(n.Blockchain.logger).Warn("recovery failed", logging.NodeKey, n.ID, "error", err)
+        return err
+    }
+    n.status = node.Running
+    logging.Or(n.Blockchain.logger).Info("recovered", logging.NodeKey, n.ID)
+    return nil
+}
+
+// NewRaftNetwork initializes a Raft network of options.WithNodes nodes, 4 by default, the last
+// options.WithFaulty of which have crashed. The nodes collaborate to reach consensus and elect a leader to
+// manage block proposals; if a majority has crashed, no leader can be elected. options.WithQuorum replaces the
+// majority that elections and blocks need. Other options are ignored.
+func NewRaftNetwork(opts ...options.Option) *Blockchain {
+    o := options.New(opts...)
     blockchain := NewBlockchain()              // Create a new blockchain instance.
-    nodes := make([]Node, size)                // Create an array of nodes.
-    for i := 0; i < size; i++ {
-        nodes[i] = *NewNode(i, blockchain)     // Initialize each node and link it to the blockchain.
+    nodes := make([]*Node, o.Nodes)            // Create an array of nodes.
+    for i := 0; i < o.Nodes; i++ {
+        nodes[i] = NewNode(i, blockchain)      // Initialize each node and link it to the blockchain.
+        nodes[i].Faulty = i >= o.Nodes-o.Faulty
     }
     blockchain.Nodes = nodes                   // Assign the nodes to the blockchain.
+    blockchain.quorum = o.Quorum
+    if o.Faulty < o.Nodes {
+        blockchain.Nodes[0].RequestVote()      // Hold the initial election so the network starts with a leader.
+    }
     return blockchain
 }
 
+// AddNode adds a node to the running network and returns its identifier, one higher than any current node's.
+// As in Raft, the membership change is itself replicated: the leader commits a block recording it, approved
+// by a majority of the current nodes, and the new node counts from then on. Changing one node at a time keeps
+// every majority of the old membership overlapping every majority of the new one, so the two can never agree
+// on different things. ErrNoLeader is returned if there is no leader, and ErrNoQuorum if the majority refused.
+func (bc *Blockchain) AddNode(ctx context.Context) (int, error) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    id := 0
+    for _, n := range bc.Nodes {
+        id = max(id, n.ID+1)
+    }
+    if err := bc.changeMembership(ctx, "add", id); err != nil {
+        return 0, err
+    }
+    bc.Nodes = append(bc.Nodes, NewNode(id, bc))
+    return id, nil
+}
+
+// RemoveNode removes the node with the given identifier from the running network, committing the change
+// like AddNode. A leader that removes itself steps down once the change is committed, leaving the network to
+// elect a new one. Removing a node that is not a member returns node.ErrUnknownNode, and removing the last
+// node node.ErrLastNode.
+func (bc *Blockchain) RemoveNode(ctx context.Context, id int) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    i := slices.IndexFunc(bc.Nodes, func(n *Node) bool { return n.ID == id })
+    if i < 0 {
+        return fmt.Errorf("%w: %d", node.ErrUnknownNode, id)
+    }
+    if len(bc.Nodes) == 1 {
+        return node.ErrLastNode
+    }
+    if err := bc.changeMembership(ctx, "remove", id); err != nil {
+        return err
+    }
+    if bc.leaderID() == id {
+        bc.Leader = nil
+    }
+    bc.Nodes = slices.Delete(bc.Nodes, i, i+1)
+    return nil
+}
+
+// changeMembership has the leader commit a block recording that a node is added or removed.
+func (bc *Blockchain) changeMembership(ctx context.Context, change string, id int) error {
+    if bc.Leader == nil {
+        return ErrNoLeader
+    }
+    if _, err := bc.Leader.lead(ctx, fmt.Sprintf("config: %s node-%d", change, id)); err != nil {
+        return err
+    }
+    logging.Or(bc.logger).InfoContext(ctx, "changed membership", "change", change, logging.NodeKey, id)
+    return nil
+}
+
+// leaderID returns the identifier of the leader, or -1 if there is none.
+func (bc *Blockchain) leaderID() int {
+    if bc.Leader == nil {
+        return -1
+    }
+    return bc.Leader.ID
+}
+
+// setLeader points Leader at the node with the given identifier, or clears it if there is no such node.
+func (bc *Blockchain) setLeader(id int) {
+    bc.Leader = nil
+    for _, n := range bc.Nodes {
+        if n.ID == id {
+            bc.Leader = n
+        }
+    }
+}
+
+// AttachBlockStore connects a block store to the blockchain. Every block already in the chain is written
+// to the store immediately, and every block appended afterwards is written through to it as well.
+func (bc *Blockchain) AttachBlockStore(store storage.BlockStore) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    return bc.attachBlockStore(store)
+}
+
+func (bc *Blockchain) attachBlockStore(store storage.BlockStore) error {
+    for i := range bc.Blocks {
+        if err := store.Put(bc.Blocks[i].ToWire()); err != nil {
+            return err
+        }
+    }
+    bc.blockStore = store
+    return nil
+}
+
+// AttachClock sets the clock that timestamps blocks appended from now on, in place of the system clock.
+func (bc *Blockchain) AttachClock(c clock.Clock) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    bc.clock = c
+}
+
+// AttachLogger sets the logger that records consensus steps from now on. Records are labelled with the
+// algorithm, and those about a single node carry the node's identifier as well.
+func (bc *Blockchain) AttachLogger(l *slog.Logger) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    bc.logger = logging.Algorithm(l, "raft")
+}
+
+// AttachEvents sets the publisher that receives the network's elections, votes and leader changes from now on.
+// Proposals and commits are published by wrapping the network in an engine with engine.Observe.
+func (bc *Blockchain) AttachEvents(p events.Publisher) {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    bc.publisher = p
+    if bc.Leader != nil {
+        bc.publish(events.Event{Type: events.LeaderChange, Node: bc.Leader.Name(), Leader: bc.Leader.Name()}) // The initial election has already happened.
+    }
+}
+
+// publish labels an event with the algorithm and sends it to the attached publisher, if any.
+func (bc *Blockchain) publish(event events.Event) {
+    if bc.publisher == nil {
+        return
+    }
+    event.Algorithm = "raft"
+    bc.publisher.Publish(event)
+}
+
+// Save persists the blockchain's blocks to the given store under name, so the chain can be reloaded
+// after the process restarts.
+func (bc *Blockchain) Save(store storage.Store, name string) error {
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    chain := &wire.Chain{Algorithm: "raft"}
+    for i := range bc.Blocks {
+        chain.Blocks = append(chain.Blocks, bc.Blocks[i].ToWire()) // Convert each block to the shared wire format.
+    }
+    return store.Save(name, chain)
+}
+
+// Load replaces the blockchain's blocks with the chain previously saved under name.
+// Every block's hash and its link to the previous block are verified first, so a corrupted
+// or tampered file is rejected instead of silently becoming the new chain.
+func (bc *Blockchain) Load(store storage.Store, name string) error {
+    chain, err := store.Load(name)
+    if err != nil {
+        return err
+    }
+    blocks, err := verifyChain(chain, fmt.Sprintf("chain %q", name))
+    if err != nil {
+        return err
+    }
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    bc.Blocks = blocks // Only replace the chain once every block has been verified.
+    if bc.blockStore != nil {
+        return bc.attachBlockStore(bc.blockStore) // Re-index the reloaded blocks in the attached store.
+    }
+    return nil
+}
+
+// Export writes the chain and its nodes, with the leader and the nodes that are faulty or crashed to w as a snapshot, which Import reads back to resume the run
+// where it stopped.
+func (bc *Blockchain) Export(w io.Writer) error {
+    bc.mu.Lock()
+    snapshot := &wire.Snapshot{Chain: &wire.Chain{Algorithm: "raft"}}
+    for i := range bc.Blocks {
+        snapshot.Chain.Blocks = append(snapshot.Chain.Blocks, bc.Blocks[i].ToWire())
+    }
+    for _, n := range bc.Nodes {
+        snapshot.Members = append(snapshot.Members, &wire.Member{Id: int32(n.ID), Leader: n.IsLeader, Faulty: n.Faulty, Crashed: n.status != node.Running})
+    }
+    bc.mu.Unlock()
+    return wire.WriteSnapshot(w, snapshot)
+}
+
+// Import replaces the chain and its nodes, with the leader and the nodes that are faulty or crashed with a snapshot written by Export. As with Load, every block is
+// verified first and a snapshot without nodes is refused, and nothing changes if the snapshot is rejected.
+func (bc *Blockchain) Import(r io.Reader) error {
+    snapshot, err := wire.ReadSnapshot(r)
+    if err != nil {
+        return err
+    }
+    blocks, err := verifyChain(snapshot.GetChain(), "the snapshot")
+    if err != nil {
+        return err
+    }
+    if len(snapshot.GetMembers()) == 0 {
+        return errors.New("raft: the snapshot has no nodes")
+    }
+    bc.mu.Lock()
+    defer bc.mu.Unlock()
+    bc.Blocks = blocks
+    bc.Nodes = make([]*Node, len(snapshot.GetMembers()))
+    leader := -1
+    for i, m := range snapshot.GetMembers() {
+        bc.Nodes[i] = NewNode(int(m.GetId()), bc)
+        bc.Nodes[i].IsLeader = m.GetLeader()
+        bc.Nodes[i].Faulty = m.GetFaulty()
+        if m.GetCrashed() {
+            bc.Nodes[i].status = node.Crashed // A node caught recovering is exported as crashed and recovers again.
+        }
+        if m.GetLeader() {
+            leader = bc.Nodes[i].ID
+        }
+    }
+    bc.setLeader(leader)
+    if bc.blockStore != nil {
+        return bc.attachBlockStore(bc.blockStore) // Re-index the imported blocks in the attached store.
+    }
+    return nil
+}
+
+// verifyChain converts a chain read from source, such as a store or a snapshot, back into blocks, verifying
+// first that it was produced by raft and that every block's hash and its link to the previous block are intact.
+func verifyChain(chain *wire.Chain, source string) ([]Block, error) {
+    if chain.GetAlgorithm() != "raft" {
+        return nil, fmt.Errorf("raft: %s was produced by %q", source, chain.GetAlgorithm())
+    }
+    if len(chain.GetBlocks()) == 0 {
+        return nil, fmt.Errorf("raft: %s has no genesis block", source)
+    }
+
+    blocks := make([]Block, 0, len(chain.GetBlocks()))
+    for i, w := range chain.GetBlocks() {
+        block := BlockFromWire(w)
+        if block.Hash != block.CalculateHash() {
+            return nil, fmt.Errorf("raft: block %d of %s has an invalid hash", i, source)
+        }
+        if i > 0 && block.PrevHash != blocks[i-1].Hash {
+            return nil, fmt.Errorf("raft: block %d of %s does not link to its predecessor", i, source)
+        }
+        blocks = append(blocks, block)
+    }
+    return blocks, nil
+}
+
 // Footer: Security Considerations and Architectural Decisions
 //
 // This implementation of Raft demonstrates the basic principles of leader election, consensus, and block management.
@@ -196,6 +836,10 @@ func NewRaftNetwork(size int) *Blockchain {
 // 4. **Data Integrity**: Each block's hash is computed based on the previous hash, timestamp, data, and other block metadata.
 //    This hash linkage ensures immutability and consistency, as altering any data would require recalculating all subsequent blocks.
 //
+// 5. **Crash and Recovery**: Nodes implement node.Lifecycle. A crashed leader takes its leadership with it, so a failure
+//    scenario has to hold a new election, exactly the situation Raft's timeouts exist to detect. A recovered node
+//    rejoins as a follower after its Restore hook has reloaded whatever it persisted.
+//
 // Raft is a robust consensus mechanism that provides fault tolerance, making it suitable for distributed systems like databases and
 // cluster management tools. This implementation is a simplified educational version to help understand the key concepts
 // behind Raft's leader-based consensus model.
\ No newline at end of file
@@ -0,0 +1,696 @@
+package raft
+
+import (
+    "errors"
+    "log/slog"
+    "math/rand"
+    "slices"
+    "time"
+
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/logging"
+    "consensus-algorithms-edu/quorum"
+    "consensus-algorithms-edu/rotation"
+    "consensus-algorithms-edu/wire"
+)
+
+// Role is the part a replica currently plays in the Raft protocol.
+type Role int
+
+const (
+    Follower  Role = iota // Passively replicates the leader's log and votes in elections.
+    Candidate             // Has timed out waiting for a leader and is asking for votes.
+    Leader                // Accepts proposals and replicates them to the followers.
+)
+
+// String returns the human-readable name of the role.
+func (r Role) String() string {
+    switch r {
+    case Follower:
+        return "follower"
+    case Candidate:
+        return "candidate"
+    case Leader:
+        return "leader"
+    }
+    return "unknown"
+}
+
+// ErrNotLeader is returned by Replica.Propose and Node.Lead when the replica or node is not the current leader.
+var ErrNotLeader = errors.New("raft: not the leader")
+
+// ErrNoLease is returned by Replica.LeaseRead when the replica cannot vouch that its committed entries are the
+// latest: it holds no lease, its lease has expired, or it has not committed an entry of its own term yet.
+var ErrNoLease = errors.New("raft: no valid lease")
+
+// noNode marks an unknown leader or an unused vote.
+const noNode int32 = -1
+
+// ReplicaConfig describes a single replica and the cluster it belongs to.
+type ReplicaConfig struct {
+    ID             int32            // Unique identifier of this replica.
+    Peers          []int32          // Identifiers of every replica in the cluster, including this one.
+    ElectionTicks  int              // Minimum ticks without hearing from a leader before starting an election.
+    HeartbeatTicks int              // Ticks between heartbeats sent by the leader.
+    RequestTimeout int              // Ticks a follower waits for a proposal given to it to commit before it campaigns; followers refuse proposals if 0.
+    Rotation       rotation.Builder // Policy choosing which replica campaigns first for each term among Peers; any replica, at random, if nil.
+    Quorum         quorum.Builder   // Replicas whose copies of an entry commit it; a majority of Peers if nil.
+    ElectionQuorum quorum.Builder   // Replicas whose votes elect a leader; Quorum if nil. Must intersect itself and Quorum.
+    Witnesses      []int32          // Peers that vote and acknowledge entries but keep no blocks and never lead; see Replica.Witness.
+    LeaseDuration  time.Duration    // Time a replica that hears from a leader promises not to help elect another; no leases if 0. See LeaseRead.
+    MaxDrift       float64          // Bound on how far any clock's rate strays from real time, e.g. 0.05 for 5%, which leases allow for.
+    Rand           *rand.Rand       // Source of randomness for election timeouts; a time-seeded source is used if nil.
+    Clock          clock.Clock      // Source of block timestamps; the system clock is used if nil.
+    Logger         *slog.Logger     // Receives elections, votes and commits, scoped to this replica; silent if nil.
+}
+
+// Replica is a message-driven Raft participant.
+// Unlike Node, which calls its peers directly through a shared Blockchain, a Replica never talks to anyone:
+// it consumes envelopes in Step, advances logical time in Tick, and returns the envelopes it wants delivered.
+// This makes the same code usable behind a real network transport and inside a deterministic simulator.
+type Replica struct {
+    id             int32
+    peers          []int32
+    electionTicks  int
+    heartbeatTicks int
+    requestTimeout int
+    policy         rotation.Policy // Chooses the replica that campaigns first for each term; nil for none.
+    commitQuorum   quorum.Quorum   // Replicas holding an entry that commit it.
+    electionQuorum quorum.Quorum   // Replicas whose votes elect a leader.
+    witnesses      []int32         // Peers the leader sends the terms of its entries to, without the blocks.
+    witness        bool            // This replica is one of the witnesses.
+    leaseDuration  time.Duration
+    maxDrift       float64
+    rand           *rand.Rand
+    clock          clock.Clock
+    logger         *slog.Logger
+
+    role        Role
+    term        uint64        // Latest term this replica has seen.
+    votedFor    int32         // Candidate voted for in the current term, or noNode.
+    leader      int32         // Current leader as far as this replica knows, or noNode.
+    log         []*wire.Entry // Replicated log; log[0] holds the genesis block.
+    commitIndex int64         // Highest log index known to be committed.
+
+    votes      map[int32]bool  // Votes received while a candidate.
+    nextIndex  map[int32]int64 // Leader only: next log index to send to each peer.
+    matchIndex map[int32]int64 // Leader only: highest log index known to be replicated on each peer.
+
+    electionElapsed  int // Ticks since the last message from a leader or the last vote granted.
+    electionTimeout  int // Randomized timeout for the current election period.
+    heartbeatElapsed int // Leader only: ticks since the last heartbeat.
+
+    heardAt time.Time           // Time on the replica's clock it last heard from the leader of its term.
+    acked   map[int32]time.Time // Leader only: the send time of the newest AppendEntries each peer has answered.
+
+    watched      []string // Follower only: proposals given to this replica that have not committed, oldest first.
+    watchElapsed int      // Ticks since the oldest watched proposal was given, or since the last one committed.
+    watchTimeout int      // Randomized timeout for the oldest watched proposal.
+}
+
+// GenesisBlock returns the genesis block shared by every replica.
+// Replicas may run in different processes, so the genesis block cannot depend on the local clock.
+func GenesisBlock() Block {
+    genesis := Block{Index: 0, Data: "Genesis Block"}
+    genesis.Hash = genesis.CalculateHash()
+    return genesis
+}
+
+// NewReplica creates a follower replica whose log contains only the genesis block.
+func NewReplica(cfg ReplicaConfig) *Replica {
+    if cfg.ElectionTicks <= 0 {
+        cfg.ElectionTicks = 10 // Default election timeout of 10-20 ticks.
+    }
+    if cfg.HeartbeatTicks <= 0 {
+        cfg.HeartbeatTicks = 2 // Heartbeats must be well inside the election timeout.
+    }
+    if cfg.Rand == nil {
+        cfg.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+    }
+
+    genesis := GenesisBlock()
+    var policy rotation.Policy
+    if cfg.Rotation != nil {
+        policy = cfg.Rotation(cfg.Peers)
+    }
+    if cfg.Quorum == nil {
+        cfg.Quorum = quorum.Majority
+    }
+    if cfg.ElectionQuorum == nil {
+        cfg.ElectionQuorum = cfg.Quorum
+    }
+    r := &Replica{
+        id:             cfg.ID,
+        peers:          cfg.Peers,
+        electionTicks:  cfg.ElectionTicks,
+        heartbeatTicks: cfg.HeartbeatTicks,
+        requestTimeout: cfg.RequestTimeout,
+        policy:         policy,
+        commitQuorum:   cfg.Quorum(cfg.Peers),
+        electionQuorum: cfg.ElectionQuorum(cfg.Peers),
+        witnesses:      cfg.Witnesses,
+        witness:        slices.Contains(cfg.Witnesses, cfg.ID),
+        leaseDuration:  cfg.LeaseDuration,
+        maxDrift:       cfg.MaxDrift,
+        rand:           cfg.Rand,
+        clock:          clock.Or(cfg.Clock),
+        logger:         logging.Scope(cfg.Logger, "raft", cfg.ID),
+        role:           Follower,
+        votedFor:       noNode,
+        leader:         noNode,
+        log:            []*wire.Entry{{Term: 0, Block: genesis.ToWire()}},
+    }
+    r.resetElectionTimer()
+    return r
+}
+
+// ID returns the replica's identifier.
+func (r *Replica) ID() int32 {
+    return r.id
+}
+
+// Role returns the replica's current role.
+func (r *Replica) Role() Role {
+    return r.role
+}
+
+// Term returns the latest term the replica has seen.
+func (r *Replica) Term() uint64 {
+    return r.term
+}
+
+// Leader returns the identifier of the leader the replica currently follows, or -1 if it does not know one.
+func (r *Replica) Leader() int32 {
+    return r.leader
+}
+
+// Witness reports whether the replica is a witness. A witness votes and acknowledges entries like any follower,
+// so it counts towards both election and commit quorums, but it keeps only the term of each entry, never its
+// block, and never campaigns, having nothing to lead with. Two replicas and a witness then survive the loss of
+// any one of them at little more than the cost of two: the witness breaks the tie a pair could not. The terms it
+// keeps are what makes this safe: a candidate whose log lacks an entry the witness acknowledged does not get its
+// vote, so a leader is never elected without every committed entry, even when the only other copy was on a
+// replica that is down. Committed returns only the genesis block at a witness.
+func (r *Replica) Witness() bool {
+    return r.witness
+}
+
+// Lease returns the time, on the replica's clock, until which it holds a leader lease, or the zero time if it
+// holds none. See LeaseRead.
+//
+// Every follower that accepts AppendEntries from the leader promises, for LeaseDuration on its own clock, neither
+// to campaign nor to answer a RequestVote. The leader counts from the time it sent the newest message a commit
+// quorum of replicas has answered: none of them will help elect another leader for LeaseDuration after that, and
+// as every election quorum includes one of them, no other leader can exist meanwhile. Clocks measure that time
+// at different rates, so the leader shortens the lease by MaxDrift at both ends, to LeaseDuration ×
+// (1 − MaxDrift) / (1 + MaxDrift). An offset between clocks does no harm, as only durations are compared; a clock
+// that runs faster than MaxDrift allows ends a follower's promise while the leader still counts on it.
+func (r *Replica) Lease() time.Time {
+    if r.role != Leader || r.leaseDuration == 0 {
+        return time.Time{}
+    }
+    sent := make([]time.Time, 0, len(r.acked))
+    for _, at := range r.acked {
+        sent = append(sent, at)
+    }
+    slices.SortFunc(sent, func(a, b time.Time) int { return b.Compare(a) }) // Newest first.
+    for _, start := range sent {
+        holders := []int32{r.id}
+        for peer, at := range r.acked {
+            if !at.Before(start) {
+                holders = append(holders, peer)
+            }
+        }
+        if r.commitQuorum.Reached(holders) {
+            safe := float64(r.leaseDuration) * (1 - r.maxDrift) / (1 + r.maxDrift)
+            return start.Add(time.Duration(safe))
+        }
+    }
+    return time.Time{}
+}
+
+// LeaseRead returns the committed blocks for a read served locally by the leader, without the round trip to a
+// quorum that a read through the log or a read index takes. It fails with ErrNoLease unless the leader's lease
+// (see Lease) is still running on its clock, and it has committed an entry of its own term, without which it
+// cannot know that its commit index is the cluster's. Other replicas fail with ErrNotLeader.
+func (r *Replica) LeaseRead() ([]*wire.Block, error) {
+    if r.role != Leader {
+        return nil, ErrNotLeader
+    }
+    if !r.clock.Now().Before(r.Lease()) || r.log[r.commitIndex].GetTerm() != r.term {
+        return nil, ErrNoLease
+    }
+    return r.Committed(), nil
+}
+
+// LogLength returns the number of entries in the replica's log, committed or not, including the genesis block.
+func (r *Replica) LogLength() int {
+    return len(r.log)
+}
+
+// Committed returns the committed prefix of the log as blocks, starting with the genesis block.
+// The returned blocks are shared with the replica and must not be modified.
+func (r *Replica) Committed() []*wire.Block {
+    if r.witness {
+        return []*wire.Block{r.log[0].GetBlock()} // The entries of a witness carry no blocks.
+    }
+    blocks := make([]*wire.Block, 0, r.commitIndex+1)
+    for _, entry := range r.log[:r.commitIndex+1] {
+        blocks = append(blocks, entry.GetBlock())
+    }
+    return blocks
+}
+
+// Tick advances the replica's logical clock by one unit.
+// Followers and candidates start an election once their timeout expires; leaders send heartbeats.
+func (r *Replica) Tick() []*wire.Envelope {
+    if r.witness {
+        return nil // A witness never campaigns.
+    }
+    if r.role == Leader {
+        r.heartbeatElapsed++
+        if r.heartbeatElapsed >= r.heartbeatTicks {
+            return r.broadcastAppend() // Heartbeats are AppendEntries messages, possibly carrying new entries.
+        }
+        return nil
+    }
+
+    r.electionElapsed++
+    if r.electionElapsed >= r.electionTimeout && !r.promised() {
+        return r.campaign() // No word from a leader: try to become one.
+    }
+    if r.role == Follower && len(r.watched) > 0 {
+        r.watchElapsed++
+        if r.watchElapsed >= r.watchTimeout && !r.promised() {
+            r.logger.Info("suspects leader", "leader", r.leader, "data", r.watched[0])
+            r.resetWatchTimer()
+            return r.campaign() // The leader hears from us but does not serve us: replace it.
+        }
+    }
+    return nil
+}
+
+// Propose appends a new block carrying data to the leader's log and starts replicating it.
+// The block is committed once a majority of replicas have stored it; it then appears in Committed.
+// A follower refuses with ErrNotLeader, unless it has a RequestTimeout: it then watches the data, and campaigns
+// if the data does not commit in time, so a leader that ignores a client's request, as a censoring leader does,
+// is replaced by one that proposes it. A client that suspects censorship submits to every replica. A witness
+// always refuses.
+func (r *Replica) Propose(data string) ([]*wire.Envelope, error) {
+    if r.role != Leader {
+        if r.requestTimeout == 0 || r.witness {
+            return nil, ErrNotLeader
+        }
+        r.watch(data)
+        return nil, nil
+    }
+
+    last := BlockFromWire(r.log[r.lastIndex()].GetBlock())
+    block := NewBlockAt(data, last.Hash, last.Index+1, r.clock.Now()) // Chain the new block onto the last log entry.
+    r.log = append(r.log, &wire.Entry{Term: r.term, Block: block.ToWire()})
+    r.matchIndex[r.id] = r.lastIndex()
+    r.maybeCommit() // A single-replica cluster commits immediately.
+    return r.broadcastAppend(), nil
+}
+
+// Step processes one envelope addressed to this replica and returns the envelopes to send in response.
+// Messages from a newer term always demote the replica to follower first, as the Raft paper requires.
+func (r *Replica) Step(env *wire.Envelope) []*wire.Envelope {
+    if env.GetRequestVote() != nil && r.promised() {
+        return nil // Bound by its promise to the leader: the candidate does not even get to raise the term.
+    }
+    if term, ok := messageTerm(env); ok && term > r.term {
+        r.becomeFollower(term, noNode)
+    }
+
+    switch body := env.GetBody().(type) {
+    case *wire.Envelope_RequestVote:
+        return r.handleRequestVote(env.GetFrom(), body.RequestVote)
+    case *wire.Envelope_RequestVoteResponse:
+        return r.handleRequestVoteResponse(env.GetFrom(), body.RequestVoteResponse)
+    case *wire.Envelope_AppendEntries:
+        return r.handleAppendEntries(env.GetFrom(), body.AppendEntries)
+    case *wire.Envelope_AppendEntriesResponse:
+        return r.handleAppendEntriesResponse(env.GetFrom(), body.AppendEntriesResponse)
+    }
+    return nil // Not a Raft message; ignore it.
+}
+
+// campaign starts a new election: the replica votes for itself and asks every peer for its vote.
+func (r *Replica) campaign() []*wire.Envelope {
+    r.enterTerm(r.term + 1)
+    r.role = Candidate
+    r.votedFor = r.id
+    r.leader = noNode
+    r.votes = map[int32]bool{r.id: true}
+    r.resetElectionTimer()
+    r.logger.Info("started election", "term", r.term)
+
+    if r.elected() {
+        return r.becomeLeader() // A cluster of one elects itself.
+    }
+
+    request := &wire.RequestVote{
+        Term:         r.term,
+        CandidateId:  r.id,
+        LastLogIndex: r.lastIndex(),
+        LastLogTerm:  r.lastTerm(),
+    }
+    var out []*wire.Envelope
+    for _, peer := range r.peers {
+        if peer != r.id {
+            out = append(out, &wire.Envelope{From: r.id, To: peer, Body: &wire.Envelope_RequestVote{RequestVote: request}})
+        }
+    }
+    return out
+}
+
+// becomeLeader switches the replica to the leader role and immediately asserts leadership with a heartbeat.
+func (r *Replica) becomeLeader() []*wire.Envelope {
+    r.role = Leader
+    r.leader = r.id
+    r.nextIndex = make(map[int32]int64)
+    r.matchIndex = make(map[int32]int64)
+    for _, peer := range r.peers {
+        r.nextIndex[peer] = r.lastIndex() + 1 // Optimistically assume every follower is up to date.
+        r.matchIndex[peer] = 0
+    }
+    r.matchIndex[r.id] = r.lastIndex()
+    r.acked = make(map[int32]time.Time)
+    r.logger.Info("became leader", "term", r.term, "votes", len(r.votes))
+    for _, data := range r.watched {
+        if !r.contains(data) {
+            last := BlockFromWire(r.log[r.lastIndex()].GetBlock())
+            block := NewBlockAt(data, last.Hash, last.Index+1, r.clock.Now()) // Serve what the last leader ignored.
+            r.log = append(r.log, &wire.Entry{Term: r.term, Block: block.ToWire()})
+        }
+    }
+    r.watched = nil
+    r.matchIndex[r.id] = r.lastIndex()
+    r.maybeCommit()
+    return r.broadcastAppend()
+}
+
+// becomeFollower switches the replica to the follower role, adopting term if it is newer.
+func (r *Replica) becomeFollower(term uint64, leader int32) {
+    if r.role != Follower {
+        r.logger.Info("stepped down", "role", r.role.String(), "term", term)
+    }
+    if term > r.term {
+        r.enterTerm(term)
+        r.votedFor = noNode // A new term means a fresh vote.
+    }
+    if leader != noNode && leader != r.leader {
+        r.logger.Info("following leader", "leader", leader, "term", term)
+    }
+    r.role = Follower
+    r.leader = leader
+    r.resetElectionTimer()
+}
+
+// handleRequestVote grants the vote if the replica has not voted for someone else in this term
+// and the candidate's log is at least as up to date as its own (the election restriction).
+func (r *Replica) handleRequestVote(from int32, m *wire.RequestVote) []*wire.Envelope {
+    grant := m.GetTerm() == r.term &&
+        (r.votedFor == noNode || r.votedFor == m.GetCandidateId()) &&
+        r.isUpToDate(m.GetLastLogTerm(), m.GetLastLogIndex())
+    if grant {
+        r.votedFor = m.GetCandidateId()
+        r.electionElapsed = 0 // Granting a vote postpones our own election.
+        r.logger.Info("granted vote", "candidate", m.GetCandidateId(), "term", r.term)
+    } else {
+        r.logger.Debug("refused vote", "candidate", m.GetCandidateId(), "term", m.GetTerm(), "voted_for", r.votedFor)
+    }
+
+    response := &wire.RequestVoteResponse{Term: r.term, VoteGranted: grant}
+    return []*wire.Envelope{{From: r.id, To: from, Body: &wire.Envelope_RequestVoteResponse{RequestVoteResponse: response}}}
+}
+
+// handleRequestVoteResponse counts a vote and becomes leader once a majority has been reached.
+func (r *Replica) handleRequestVoteResponse(from int32, m *wire.RequestVoteResponse) []*wire.Envelope {
+    if r.role != Candidate || m.GetTerm() != r.term || !m.GetVoteGranted() {
+        return nil // Stale or negative responses do not count.
+    }
+    r.votes[from] = true
+    r.logger.Debug("received vote", "voter", from, "term", r.term, "votes", len(r.votes))
+    if r.elected() {
+        return r.becomeLeader()
+    }
+    return nil
+}
+
+// handleAppendEntries accepts entries from the leader if the log matches at PrevLogIndex,
+// overwriting any conflicting entries, and advances the commit index.
+func (r *Replica) handleAppendEntries(from int32, m *wire.AppendEntries) []*wire.Envelope {
+    var sent int64 // Echoed once the leader is recognized: a stale one is promised nothing.
+    reply := func(success bool, match int64) []*wire.Envelope {
+        response := &wire.AppendEntriesResponse{Term: r.term, Success: success, MatchIndex: match, Sent: sent}
+        return []*wire.Envelope{{From: r.id, To: from, Body: &wire.Envelope_AppendEntriesResponse{AppendEntriesResponse: response}}}
+    }
+
+    if m.GetTerm() < r.term {
+        return reply(false, r.lastIndex()) // Reject a stale leader; our term in the reply makes it step down.
+    }
+    r.becomeFollower(m.GetTerm(), from) // A valid leader exists for this term.
+    r.heardAt = r.clock.Now()
+    sent = m.GetSent()
+
+    prev := m.GetPrevLogIndex()
+    if prev > r.lastIndex() {
+        return reply(false, r.lastIndex()) // Missing entries: ask the leader to back up to our log's end.
+    }
+    if r.log[prev].GetTerm() != m.GetPrevLogTerm() {
+        return reply(false, prev-1) // Conflicting entry: ask the leader to back up one more.
+    }
+
+    for i, entry := range m.GetEntries() {
+        index := prev + 1 + int64(i)
+        if index <= r.lastIndex() {
+            if r.log[index].GetTerm() == entry.GetTerm() {
+                continue // Already have this entry.
+            }
+            r.log = r.log[:index] // Conflict: drop this entry and everything after it.
+        }
+        r.log = append(r.log, entry)
+    }
+
+    match := prev + int64(len(m.GetEntries()))
+    if commit := min(m.GetLeaderCommit(), match); commit > r.commitIndex {
+        r.commitIndex = commit
+        r.logger.Info("committed", "index", commit, "term", r.term)
+        r.unwatch()
+    }
+    return reply(true, match)
+}
+
+// handleAppendEntriesResponse updates the leader's view of a follower's log and retries on mismatch.
+func (r *Replica) handleAppendEntriesResponse(from int32, m *wire.AppendEntriesResponse) []*wire.Envelope {
+    if r.role != Leader || m.GetTerm() != r.term {
+        return nil
+    }
+    r.ack(from, wire.Timestamp(m.GetSent()))
+
+    if !m.GetSuccess() {
+        r.nextIndex[from] = max(1, min(r.nextIndex[from]-1, m.GetMatchIndex()+1)) // Back up and retry.
+        return []*wire.Envelope{r.appendTo(from)}
+    }
+
+    if m.GetMatchIndex() > r.matchIndex[from] {
+        r.matchIndex[from] = m.GetMatchIndex()
+    }
+    r.nextIndex[from] = r.matchIndex[from] + 1
+    r.maybeCommit()
+
+    if r.nextIndex[from] <= r.lastIndex() {
+        return []*wire.Envelope{r.appendTo(from)} // The follower is still behind; keep sending.
+    }
+    return nil
+}
+
+// maybeCommit advances the commit index to the highest entry of the current term stored on a commit quorum.
+// Entries from earlier terms are committed indirectly, as Raft's commitment rule requires.
+func (r *Replica) maybeCommit() {
+    for index := r.lastIndex(); index > r.commitIndex; index-- {
+        if r.log[index].GetTerm() != r.term {
+            break
+        }
+        var replicated []int32
+        for _, peer := range r.peers {
+            if r.matchIndex[peer] >= index {
+                replicated = append(replicated, peer)
+            }
+        }
+        if r.commitQuorum.Reached(replicated) {
+            r.commitIndex = index
+            r.logger.Info("committed", "index", index, "term", r.term, "replicas", len(replicated))
+            return
+        }
+    }
+}
+
+// broadcastAppend sends AppendEntries to every follower and restarts the heartbeat timer.
+func (r *Replica) broadcastAppend() []*wire.Envelope {
+    r.heartbeatElapsed = 0
+    var out []*wire.Envelope
+    for _, peer := range r.peers {
+        if peer != r.id {
+            out = append(out, r.appendTo(peer))
+        }
+    }
+    return out
+}
+
+// maxEntriesPerMessage bounds how many log entries a single AppendEntries carries.
+const maxEntriesPerMessage = 64
+
+// appendTo builds the AppendEntries message for one follower, starting at its next index. A witness is sent
+// the terms of the entries only.
+func (r *Replica) appendTo(peer int32) *wire.Envelope {
+    prev := r.nextIndex[peer] - 1
+    end := min(r.lastIndex()+1, prev+1+maxEntriesPerMessage)
+    entries := r.log[prev+1 : end]
+    if slices.Contains(r.witnesses, peer) {
+        entries = termsOf(entries)
+    }
+    request := &wire.AppendEntries{
+        Term:         r.term,
+        LeaderId:     r.id,
+        PrevLogIndex: prev,
+        PrevLogTerm:  r.log[prev].GetTerm(),
+        Entries:      entries,
+        LeaderCommit: r.commitIndex,
+        Sent:         int64(wire.TimestampOf(r.clock.Now())),
+    }
+    return &wire.Envelope{From: r.id, To: peer, Body: &wire.Envelope_AppendEntries{AppendEntries: request}}
+}
+
+// promised reports whether the replica is bound by a lease not to help elect a new leader: it leads, or it heard
+// from its leader less than LeaseDuration ago on its clock.
+func (r *Replica) promised() bool {
+    if r.leaseDuration == 0 {
+        return false
+    }
+    return r.role == Leader || r.leader != noNode && r.clock.Now().Sub(r.heardAt) < r.leaseDuration
+}
+
+// ack records that a peer answered the AppendEntries of the current term the leader sent at sent, which the
+// answer echoes; an answer without one promised nothing. Counting answers instead would let a duplicated answer
+// stand for a later message the peer never heard, and the lease would outlast its promise. Late, lost or
+// repeated answers only leave the time older.
+func (r *Replica) ack(peer int32, sent wire.Timestamp) {
+    if sent != 0 && sent.Time().After(r.acked[peer]) {
+        r.acked[peer] = sent.Time()
+    }
+}
+
+// termsOf returns entries without their blocks, as a witness keeps them.
+func termsOf(entries []*wire.Entry) []*wire.Entry {
+    terms := make([]*wire.Entry, len(entries))
+    for i, entry := range entries {
+        terms[i] = &wire.Entry{Term: entry.GetTerm()}
+    }
+    return terms
+}
+
+// isUpToDate reports whether a log ending at (lastTerm, lastIndex) is at least as up to date as ours.
+func (r *Replica) isUpToDate(lastTerm uint64, lastIndex int64) bool {
+    if lastTerm != r.lastTerm() {
+        return lastTerm > r.lastTerm()
+    }
+    return lastIndex >= r.lastIndex()
+}
+
+// elected reports whether the votes received elect this replica.
+func (r *Replica) elected() bool {
+    voters := make([]int32, 0, len(r.votes))
+    for voter := range r.votes {
+        voters = append(voters, voter)
+    }
+    return r.electionQuorum.Reached(voters)
+}
+
+// enterTerm moves the replica to a later term. A Raft term only ends when its leader fails or its election does,
+// so every term left behind is reported to the rotation policy as failed.
+func (r *Replica) enterTerm(term uint64) {
+    for failed := r.term; r.policy != nil && failed < term; failed++ {
+        r.policy.Fail(failed)
+    }
+    r.term = term
+}
+
+// resetElectionTimer restarts the election period with a new randomized timeout,
+// which makes split votes unlikely to repeat. With a rotation policy, replicas other than the one it chooses for
+// the next term wait a whole election period longer, so that one campaigns first and wins unless it is down.
+func (r *Replica) resetElectionTimer() {
+    r.electionElapsed = 0
+    r.electionTimeout = r.electionTicks + r.rand.Intn(r.electionTicks)
+    if r.policy != nil && r.policy.Leader(r.term+1) != r.id {
+        r.electionTimeout += r.electionTicks
+    }
+}
+
+// watch remembers data given to a follower, unless it is already watched or committed.
+func (r *Replica) watch(data string) {
+    if slices.Contains(r.watched, data) || r.committed(data) {
+        return
+    }
+    if len(r.watched) == 0 {
+        r.resetWatchTimer()
+    }
+    r.watched = append(r.watched, data)
+}
+
+// unwatch forgets watched data that has committed, restarting the timer if the oldest did: as in PBFT, progress
+// on other requests does not count, or a leader could ignore one client forever by serving the others.
+func (r *Replica) unwatch() {
+    if len(r.watched) == 0 {
+        return
+    }
+    oldest := r.watched[0]
+    r.watched = slices.DeleteFunc(r.watched, r.committed)
+    if len(r.watched) == 0 || r.watched[0] != oldest {
+        r.resetWatchTimer()
+    }
+}
+
+// resetWatchTimer restarts the wait for the oldest watched proposal with a new randomized timeout, so that the
+// followers a client gave the same proposal do not all campaign at once and split the vote.
+func (r *Replica) resetWatchTimer() {
+    r.watchElapsed = 0
+    r.watchTimeout = r.requestTimeout + r.rand.Intn(r.electionTicks)
+}
+
+// contains reports whether a block carrying data is in the log, committed or not.
+func (r *Replica) contains(data string) bool {
+    return slices.ContainsFunc(r.log[1:], func(e *wire.Entry) bool { return e.GetBlock().GetData() == data })
+}
+
+// committed reports whether a block carrying data has committed.
+func (r *Replica) committed(data string) bool {
+    return slices.ContainsFunc(r.log[1:r.commitIndex+1], func(e *wire.Entry) bool { return e.GetBlock().GetData() == data })
+}
+
+// lastIndex returns the index of the last log entry.
+func (r *Replica) lastIndex() int64 {
+    return int64(len(r.log) - 1)
+}
+
+// lastTerm returns the term of the last log entry.
+func (r *Replica) lastTerm() uint64 {
+    return r.log[r.lastIndex()].GetTerm()
+}
+
+// messageTerm extracts the term carried by any Raft message.
+func messageTerm(env *wire.Envelope) (uint64, bool) {
+    switch body := env.GetBody().(type) {
+    case *wire.Envelope_RequestVote:
+        return body.RequestVote.GetTerm(), true
+    case *wire.Envelope_RequestVoteResponse:
+        return body.RequestVoteResponse.GetTerm(), true
+    case *wire.Envelope_AppendEntries:
+        return body.AppendEntries.GetTerm(), true
+    case *wire.Envelope_AppendEntriesResponse:
+        return body.AppendEntriesResponse.GetTerm(), true
+    }
+    return 0, false
+}
@@ -0,0 +1,288 @@
+// Package api exposes a running consensus simulation over HTTP.
+// A Server wraps an engine.Engine and serves its chain, head block, status and participants as JSON, and
+// accepts new data to run consensus on. The Server is an ordinary http.Handler, so it can be mounted on any
+// mux next to an application's own routes, or served on its own with http.ListenAndServe. External tools,
+// scripts and browsers can then inspect a simulation while it runs, without linking against its Go code.
+package api
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "io"
+    "net/http"
+    "strconv"
+    "sync"
+
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/logging"
+    "consensus-algorithms-edu/wire"
+)
+
+// maxSubmitSize bounds the size of a submission request body, in bytes.
+const maxSubmitSize = 1 << 20
+
+// RequestIDHeader is the request header whose value, if present, traces a submission through the logs of the
+// consensus round it starts (see logging.WithTrace).
+const RequestIDHeader = "X-Request-Id"
+
+// Block is the JSON representation of a block. Unlike the wire format, every field is always present,
+// so the genesis block's index and prevHash appear explicitly.
+type Block struct {
+    Index       int64    `json:"index"`                 // Position of the block in the chain.
+    Timestamp   int64    `json:"timestamp"`             // Creation time in nanoseconds since the Unix epoch, as hashed.
+    Time        string   `json:"time"`                  // Creation time in RFC 3339, for display.
+    Data        string   `json:"data"`                  // Payload carried by the block.
+    PrevHash    string   `json:"prevHash"`              // Hash of the parent block.
+    Hash        string   `json:"hash"`                  // Hash of this block.
+    Nonce       int64    `json:"nonce,omitempty"`       // PoW only: the nonce that satisfies the difficulty target.
+    Producer    string   `json:"producer,omitempty"`    // PoS validator or DPoS delegate that produced the block.
+    Certificate []string `json:"certificate,omitempty"` // PBFT only: replicas whose commit votes made the block final.
+}
+
+// BlockFromWire converts a wire block into its JSON representation.
+func BlockFromWire(w *wire.Block) Block {
+    return Block{
+        Index:       w.GetIndex(),
+        Timestamp:   w.GetTimestamp(),
+        Time:        wire.Timestamp(w.GetTimestamp()).String(),
+        Data:        w.GetData(),
+        PrevHash:    w.GetPrevHash(),
+        Hash:        w.GetHash(),
+        Nonce:       w.GetNonce(),
+        Producer:    w.GetProducer(),
+        Certificate: w.GetCertificate(),
+    }
+}
+
+// SubmitRequest is the body accepted by POST /submit.
+type SubmitRequest struct {
+    Data string `json:"data"` // Data to run consensus on.
+}
+
+// AddressBlocks is the body of GET /addresses/{address}/blocks.
+type AddressBlocks struct {
+    Address    string  `json:"address"`    // Account that was looked up.
+    Candidates []int64 `json:"candidates"` // Indexes of the blocks whose bloom filter might contain the address.
+    Blocks     []Block `json:"blocks"`     // The candidates that do name the address; the rest were false positives.
+}
+
+// Receipt is the body of GET /receipts/{tx}: a ledger.Receipt and the index of the block that carried it.
+type Receipt struct {
+    ledger.Receipt
+    Block int64 `json:"block"` // Index of the block that carried the transaction.
+}
+
+// Error is the body of every error response.
+type Error struct {
+    Error string `json:"error"` // Human-readable description of what went wrong.
+}
+
+// Server serves one engine over HTTP. Routes:
+//
+//	GET  /blocks             every block, oldest first; ?from=N&limit=M selects a range
+//	GET  /blocks/{id}        one block, by index or by hash
+//	GET  /head               the most recent block
+//	GET  /status             engine.Status of the simulation
+//	GET  /participants       nodes, validators or delegates with their roles
+//	GET  /addresses/{a}/blocks  blocks whose transactions might touch account a, and those that do
+//	POST /submit             {"data": "..."}; runs consensus and returns the new head block
+type Server struct {
+    engine   engine.Engine
+    mux      *http.ServeMux
+    index    *ledger.BloomIndex // Bloom filters of the addresses in each block, extended as the chain grows.
+    indexing sync.Mutex         // Held while blocks are added to index, so each is added once.
+}
+
+// NewServer creates a server for e.
+func NewServer(e engine.Engine) *Server {
+    s := &Server{engine: e, mux: http.NewServeMux(), index: ledger.NewBloomIndex(0, 0)}
+    s.mux.HandleFunc("GET /blocks", s.handleBlocks)
+    s.mux.HandleFunc("GET /blocks/{id}", s.handleBlock)
+    s.mux.HandleFunc("GET /head", s.handleHead)
+    s.mux.HandleFunc("GET /status", s.handleStatus)
+    s.mux.HandleFunc("GET /participants", s.handleParticipants)
+    s.mux.HandleFunc("GET /addresses/{address}/blocks", s.handleAddressBlocks)
+    s.mux.HandleFunc("GET /receipts/{tx}", s.handleReceipt)
+    s.mux.HandleFunc("POST /submit", s.handleSubmit)
+    return s
+}
+
+// Handle registers an additional handler on the server's mux, so packages that extend the API
+// (for example with streaming endpoints) can share the same server.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+    s.mux.Handle(pattern, handler)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    s.mux.ServeHTTP(w, r)
+}
+
+// handleBlocks serves the whole chain or the range selected by the from and limit query parameters.
+func (s *Server) handleBlocks(w http.ResponseWriter, r *http.Request) {
+    blocks := s.engine.Blocks()
+    from, err := queryInt(r, "from", 0)
+    if err != nil || from < 0 {
+        writeError(w, http.StatusBadRequest, "from must be a non-negative integer")
+        return
+    }
+    limit, err := queryInt(r, "limit", len(blocks))
+    if err != nil || limit < 0 {
+        writeError(w, http.StatusBadRequest, "limit must be a non-negative integer")
+        return
+    }
+
+    out := []Block{} // Encode an empty range as [] rather than null.
+    for i := from; i < len(blocks) && len(out) < limit; i++ {
+        out = append(out, BlockFromWire(blocks[i]))
+    }
+    writeJSON(w, http.StatusOK, out)
+}
+
+// handleBlock serves a single block. A numeric id is treated as an index, anything else as a hash.
+func (s *Server) handleBlock(w http.ResponseWriter, r *http.Request) {
+    id := r.PathValue("id")
+    blocks := s.engine.Blocks()
+    if index, err := strconv.Atoi(id); err == nil {
+        if index >= 0 && index < len(blocks) {
+            writeJSON(w, http.StatusOK, BlockFromWire(blocks[index]))
+            return
+        }
+    } else {
+        for _, block := range blocks {
+            if block.GetHash() == id {
+                writeJSON(w, http.StatusOK, BlockFromWire(block))
+                return
+            }
+        }
+    }
+    writeError(w, http.StatusNotFound, "block not found")
+}
+
+// handleHead serves the most recent block.
+func (s *Server) handleHead(w http.ResponseWriter, r *http.Request) {
+    blocks := s.engine.Blocks()
+    writeJSON(w, http.StatusOK, BlockFromWire(blocks[len(blocks)-1]))
+}
+
+// handleStatus serves the engine's status.
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+    writeJSON(w, http.StatusOK, s.engine.Status())
+}
+
+// handleParticipants serves the engine's participants.
+func (s *Server) handleParticipants(w http.ResponseWriter, r *http.Request) {
+    writeJSON(w, http.StatusOK, s.engine.Participants())
+}
+
+// handleAddressBlocks serves the blocks that touch an account. The bloom index narrows the chain down to a few
+// candidates, which are then decoded to drop the false positives.
+func (s *Server) handleAddressBlocks(w http.ResponseWriter, r *http.Request) {
+    address := r.PathValue("address")
+    blocks := s.engine.Blocks()
+    s.indexBlocks(blocks)
+    out := AddressBlocks{Address: address, Candidates: []int64{}, Blocks: []Block{}}
+    for _, i := range s.index.Query(address) {
+        if i >= int64(len(blocks)) {
+            continue // Indexed by a concurrent request after this one read the chain.
+        }
+        out.Candidates = append(out.Candidates, i)
+        if ledger.Touches(blocks[i].GetData(), address) {
+            out.Blocks = append(out.Blocks, BlockFromWire(blocks[i]))
+        }
+    }
+    writeJSON(w, http.StatusOK, out)
+}
+
+// handleReceipt serves the receipt of a transaction, by its ledger.TxHash.
+func (s *Server) handleReceipt(w http.ResponseWriter, r *http.Request) {
+    receipt, ok := engine.GetReceipt(s.engine, r.PathValue("tx"))
+    if !ok {
+        writeError(w, http.StatusNotFound, "receipt not found")
+        return
+    }
+    writeJSON(w, http.StatusOK, Receipt{Receipt: receipt, Block: receipt.Block})
+}
+
+// indexBlocks adds the blocks the bloom index does not have yet. Blocks of the engine's chain never change once
+// appended, so the index only ever grows.
+func (s *Server) indexBlocks(blocks []*wire.Block) {
+    s.indexing.Lock()
+    defer s.indexing.Unlock()
+    for i := s.index.Len(); i < len(blocks); i++ {
+        s.index.Add(blocks[i].GetData())
+    }
+}
+
+// handleSubmit runs consensus on the submitted data and returns the new head block. The round runs under the
+// request's context, so it stops if the client goes away.
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+    var req SubmitRequest
+    if err := json.NewDecoder(io.LimitReader(r.Body, maxSubmitSize)).Decode(&req); err != nil {
+        writeError(w, http.StatusBadRequest, "body must be a JSON object with a data field")
+        return
+    }
+    if req.Data == "" {
+        writeError(w, http.StatusBadRequest, "data must not be empty")
+        return
+    }
+
+    ctx := r.Context()
+    if id := r.Header.Get(RequestIDHeader); id != "" {
+        ctx = logging.WithTrace(ctx, id)
+    }
+    if err := s.engine.Submit(ctx, req.Data); err != nil {
+        status := http.StatusInternalServerError
+        switch {
+        case errors.Is(err, engine.ErrRejected):
+            status = http.StatusConflict // Consensus was not reached; the request itself was fine.
+        case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+            status = http.StatusServiceUnavailable // The round was abandoned before it finished.
+        }
+        writeError(w, status, err.Error())
+        return
+    }
+    blocks := s.engine.Blocks()
+    writeJSON(w, http.StatusCreated, BlockFromWire(blocks[len(blocks)-1]))
+}
+
+// queryInt parses an integer query parameter, returning def when it is absent.
+func queryInt(r *http.Request, name string, def int) (int, error) {
+    value := r.URL.Query().Get(name)
+    if value == "" {
+        return def, nil
+    }
+    return strconv.Atoi(value)
+}
+
+// writeJSON writes v as an indented JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    encoder := json.NewEncoder(w)
+    encoder.SetIndent("", "  ")
+    encoder.Encode(v)
+}
+
+// writeError writes a JSON error response.
+func writeError(w http.ResponseWriter, status int, message string) {
+    writeJSON(w, status, Error{Error: message})
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Built on engine.Engine**: The server never touches an algorithm package directly. Anything that implements
+//    engine.Engine can be served, which keeps the HTTP layer independent of how consensus is reached.
+//
+// 2. **Embeddable by Design**: Server is a plain http.Handler with no listener of its own. Applications decide
+//    the address, TLS settings, timeouts and any middleware, and may mount the API under a prefix with
+//    http.StripPrefix. Handle lets other packages add routes to the same server.
+//
+// 3. **Stable JSON Types**: Responses use the api.Block type rather than encoding wire.Block directly. The wire
+//    type omits zero values and may gain fields as the protocol evolves, while the JSON shape seen by external
+//    tools should stay predictable.
+//
+// 4. **Bounded Input**: Submission bodies are limited in size, and submitted data must be non-empty. The server
+//    is meant for local experiments, but it should not be trivially crashable by a misbehaving client.
@@ -0,0 +1,57 @@
+package api
+
+import (
+    "net/http"
+    "strings"
+
+    "golang.org/x/net/websocket"
+
+    "consensus-algorithms-edu/events"
+)
+
+// eventBuffer is the number of events buffered per WebSocket client before events are dropped.
+const eventBuffer = 256
+
+// EventsHandler returns a handler that upgrades the request to a WebSocket and streams every event published
+// on stream to the client as a JSON text message, one event per message. The optional type query parameter
+// restricts the stream to a comma-separated list of event types, e.g. ?type=vote,commit. The connection stays
+// open until the client disconnects.
+//
+// The handler accepts connections from any origin, because it is meant for local visualizations that are
+// often opened straight from disk; put it behind an origin check before exposing it to untrusted networks.
+func EventsHandler(stream *events.Stream) http.Handler {
+    return websocket.Server{Handler: func(conn *websocket.Conn) {
+        defer conn.Close()
+        types := make(map[events.Type]bool)
+        for _, name := range strings.Split(conn.Request().URL.Query().Get("type"), ",") {
+            if name != "" {
+                types[events.Type(name)] = true
+            }
+        }
+
+        sub := stream.Subscribe(eventBuffer)
+        defer sub.Close()
+        closed := make(chan struct{})
+        go func() {
+            // The stream is one-way; reading only detects that the client has gone away.
+            var discard string
+            for websocket.Message.Receive(conn, &discard) == nil {
+            }
+            close(closed)
+        }()
+
+        for {
+            select {
+            case event := <-sub.Events():
+                if len(types) > 0 && !types[event.Type] {
+                    continue
+                }
+                if err := websocket.JSON.Send(conn, event); err != nil {
+                    return
+                }
+            case <-closed:
+                return
+            }
+        }
+    }}
+}
@@ -0,0 +1,238 @@
+package blocktree
+
+import (
+    "errors"
+    "fmt"
+    "slices"
+
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/profile"
+    "consensus-algorithms-edu/wire"
+)
+
+// Errors returned by Chain, wrapped with the height or block concerned.
+var (
+    ErrHeight      = errors.New("blocktree: height not on the chain")
+    ErrNotOnChain  = errors.New("blocktree: branch does not leave the chain at one of its blocks")
+    ErrPrunedState = errors.New("blocktree: state at that height was pruned")
+)
+
+// Chain is the chain a replica follows, from the genesis block to its head, together with the ledger state that
+// the data of its blocks leaves. It is the one place where a fork is handled: Rollback unwinds the chain and its
+// state to an earlier height, and ApplyForkSwitch replaces the blocks after a fork point with another branch,
+// checking each block of the branch against the state the block before it leaves. The chain keeps a copy of the
+// state after each of its blocks, so unwinding restores a copy rather than undoing transactions one by one.
+// Without a state, a Chain only tracks blocks. A Chain is not safe for concurrent use.
+type Chain struct {
+    blocks []*wire.Block  // The chain, genesis first, so that blocks[i] has index i.
+    states []ledger.State // Copy of the state after each block; nil once pruned, and always nil without a state.
+    state  ledger.State   // State after the head, which the next block is checked against; nil if none.
+}
+
+// NewChain returns a chain holding only genesis. If s is not nil, it is reset and the genesis block's data is
+// applied to it; an error wrapping ledger.ErrInvalid is returned if the data breaks s's rules. The chain then
+// owns s: after a rollback, State returns a restored copy in its place, and s is no longer updated.
+func NewChain(genesis *wire.Block, s ledger.State) (*Chain, error) {
+    c := &Chain{blocks: []*wire.Block{genesis}, state: s}
+    if s != nil {
+        s.Reset()
+        if err := s.Apply(genesis.GetData()); err != nil {
+            return nil, fmt.Errorf("genesis block: %w", err)
+        }
+    }
+    c.states = []ledger.State{c.snapshot()}
+    return c, nil
+}
+
+// snapshot returns a copy of the current state, or nil if there is none.
+func (c *Chain) snapshot() ledger.State {
+    if c.state == nil {
+        return nil
+    }
+    return c.state.Clone()
+}
+
+// Head returns the last block of the chain.
+func (c *Chain) Head() *wire.Block {
+    return c.blocks[len(c.blocks)-1]
+}
+
+// Height returns the index of the head.
+func (c *Chain) Height() int64 {
+    return int64(len(c.blocks) - 1)
+}
+
+// Block returns the block of the chain at index, if the chain is that long.
+func (c *Chain) Block(index int64) (*wire.Block, bool) {
+    if index < 0 || index > c.Height() {
+        return nil, false
+    }
+    return c.blocks[index], true
+}
+
+// Blocks returns the chain, from the genesis block to the head.
+func (c *Chain) Blocks() []*wire.Block {
+    return slices.Clone(c.blocks)
+}
+
+// view returns the chain without copying it, clipped so that appending to it never writes into the chain. The
+// blocks are shared with the chain and must not be changed.
+func (c *Chain) view() []*wire.Block {
+    return slices.Clip(c.blocks)
+}
+
+// State returns the state after the head, or nil if the chain has none. It must not be changed by the caller.
+func (c *Chain) State() ledger.State {
+    return c.state
+}
+
+// Append extends the chain with a block whose parent is the head, applying its data to the state. A block that
+// does not extend the head is refused with an error wrapping ErrNotOnChain, and one whose data breaks the
+// state's rules, or that commits to other state or receipts roots than its data leads to, with an error
+// wrapping ledger.ErrInvalid. The chain is unchanged if an error is returned.
+func (c *Chain) Append(block *wire.Block) error {
+    defer profile.Begin(profile.State).End()
+    if err := c.links(c.Head(), block); err != nil {
+        return err
+    }
+    if err := check(c.state, block); err != nil {
+        return err
+    }
+    if c.state != nil {
+        c.state.Apply(block.GetData()) // Checked above.
+    }
+    c.blocks = append(c.blocks, block)
+    c.states = append(c.states, c.snapshot())
+    return nil
+}
+
+// links reports whether block extends parent, with an error wrapping ErrNotOnChain if it does not.
+func (c *Chain) links(parent, block *wire.Block) error {
+    if block.GetPrevHash() != parent.GetHash() || block.GetIndex() != parent.GetIndex()+1 {
+        return fmt.Errorf("%w: block %d does not extend block %d", ErrNotOnChain, block.GetIndex(), parent.GetIndex())
+    }
+    return nil
+}
+
+// check reports why block could not be applied to s, which is left unchanged; nil if s is nil.
+func check(s ledger.State, block *wire.Block) error {
+    if s == nil {
+        return nil
+    }
+    roots := ledger.Roots{State: block.StateSum(), Receipts: block.ReceiptsSum()}
+    if _, err := ledger.CheckRoots(s, block.GetData(), roots); err != nil {
+        return fmt.Errorf("block %d: %w", block.GetIndex(), err)
+    }
+    return nil
+}
+
+// Rollback unwinds the chain to the block at toHeight: it removes every block after it, restores the state as
+// that block left it, and returns the removed blocks, oldest first. It returns an error wrapping ErrHeight if
+// the chain has no block at toHeight, or ErrPrunedState if Prune discarded the state there; the chain is then
+// unchanged.
+func (c *Chain) Rollback(toHeight int64) ([]*wire.Block, error) {
+    defer profile.Begin(profile.State).End()
+    if toHeight < 0 || toHeight > c.Height() {
+        return nil, fmt.Errorf("%w: %d, the head is at %d", ErrHeight, toHeight, c.Height())
+    }
+    if c.state != nil && c.states[toHeight] == nil {
+        return nil, fmt.Errorf("%w: %d", ErrPrunedState, toHeight)
+    }
+    if toHeight == c.Height() {
+        return nil, nil
+    }
+    removed := slices.Clone(c.blocks[toHeight+1:])
+    c.blocks = slices.Clip(c.blocks[:toHeight+1])
+    c.states = slices.Clip(c.states[:toHeight+1])
+    if c.state != nil {
+        c.state = c.states[toHeight].Clone() // The kept copy stays intact for a later rollback to the same height.
+    }
+    return removed, nil
+}
+
+// fork returns the height at which newBranch leaves the chain: the index of its first block's parent.
+func (c *Chain) fork(newBranch []*wire.Block) (int64, error) {
+    height := newBranch[0].GetIndex() - 1
+    if parent, ok := c.Block(height); !ok || parent.GetHash() != newBranch[0].GetPrevHash() {
+        return 0, fmt.Errorf("%w: parent of block %d is not on the chain", ErrNotOnChain, newBranch[0].GetIndex())
+    }
+    return height, nil
+}
+
+// CheckForkSwitch reports why ApplyForkSwitch would refuse newBranch, without changing the chain.
+func (c *Chain) CheckForkSwitch(newBranch []*wire.Block) error {
+    if len(newBranch) == 0 {
+        return nil
+    }
+    defer profile.Begin(profile.State).End()
+    height, err := c.fork(newBranch)
+    if err != nil {
+        return err
+    }
+    var s ledger.State
+    if c.state != nil {
+        if c.states[height] == nil {
+            return fmt.Errorf("%w: %d", ErrPrunedState, height)
+        }
+        s = c.states[height].Clone()
+    }
+    parent := c.blocks[height]
+    for _, block := range newBranch {
+        if err := c.links(parent, block); err != nil {
+            return err
+        }
+        if err := check(s, block); err != nil {
+            return err
+        }
+        if s != nil {
+            s.Apply(block.GetData())
+        }
+        parent = block
+    }
+    return nil
+}
+
+// ApplyForkSwitch switches the chain to another branch. newBranch holds the branch's blocks after the point where
+// it leaves the chain, oldest first, so that the parent of its first block is a block of the chain. The chain is
+// rolled back to that parent and the branch appended, and the blocks abandoned are returned, oldest first: none
+// if the branch extends the head. If the branch is refused, for the reasons CheckForkSwitch gives, the chain is
+// unchanged.
+func (c *Chain) ApplyForkSwitch(newBranch []*wire.Block) ([]*wire.Block, error) {
+    if err := c.CheckForkSwitch(newBranch); err != nil || len(newBranch) == 0 {
+        return nil, err
+    }
+    abandoned, _ := c.Rollback(newBranch[0].GetIndex() - 1) // The fork's state is known to be kept.
+    for _, block := range newBranch {
+        c.Append(block) // Checked above.
+    }
+    return abandoned, nil
+}
+
+// Prune discards the data of the blocks more than depth blocks below the head, and the copies of the state they
+// left, as a pruned replica does with its tree. The chain can then no longer be rolled back, or switched to a
+// branch that leaves it, below that depth; the state after the head is always kept.
+func (c *Chain) Prune(depth int) {
+    for i := range max(0, len(c.blocks)-1-max(depth, 0)) {
+        if c.states[i] == nil && c.blocks[i].GetData() == "" {
+            continue
+        }
+        c.states[i] = nil
+        c.blocks[i] = withoutData(c.blocks[i])
+    }
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **One Fork Handler**: Proof of Work, Proof of Stake and Delegated Proof of Stake differ only in which branch
+//    they choose. Unwinding the losing branch and applying the winning one is the same for all three, so it lives
+//    here, under Replica, rather than in each algorithm.
+//
+// 2. **Copies, Not Undo Logs**: The state after each block is kept as a copy, so a rollback is exact whatever the
+//    state's rules are, and a State needs no way to undo a transaction. The cost is memory in proportion to the
+//    chain's length, which pruning bounds.
+//
+// 3. **Check, Then Switch**: A branch is checked in full against a copy of the fork's state before the chain is
+//    touched, so a branch that turns out invalid halfway leaves the chain exactly as it was.
+//
+// 4. **Replaced, Not Rewound**: A ledger.State can only move forward, so a rollback makes a copy of the kept state
+//    the current one instead of rewinding the object the chain was given.
@@ -0,0 +1,407 @@
+package blocktree
+
+import (
+    "errors"
+    "fmt"
+    "log/slog"
+    "slices"
+
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/logging"
+    "consensus-algorithms-edu/mempool"
+    "consensus-algorithms-edu/wire"
+)
+
+// ErrConflictingCheckpoint is returned by Finalize for a checkpoint on a branch without the checkpoint finalized
+// before it. Two conflicting checkpoints can only both be finalized if a third of the committee that finalizes
+// them broke its rules.
+var ErrConflictingCheckpoint = errors.New("blocktree: checkpoint conflicts with the finalized one")
+
+// ReplicaConfig describes the algorithm-independent part of a block-producing replica.
+type ReplicaConfig struct {
+    ID            int32                               // Unique identifier of this replica.
+    Peers         []int32                             // Identifiers of every replica in the network, including this one.
+    Genesis       *wire.Block                         // Block every replica starts from.
+    Rule          Rule                                // Fork-choice rule; LongestChain is used if nil.
+    Verify        func(block *wire.Block) bool        // Algorithm-specific validity of a block received from a peer.
+    Header        func(block *wire.Block) wire.Header // The algorithm's header of a block; headers are not served if nil.
+    Confirmations int                                 // Blocks that must follow a block before Committed reports it.
+    AntiEntropy   int                                 // Ticks between anti-entropy exchanges with a peer (see Tick); none if 0.
+    Prune         int                                 // Blocks below the head whose data a pruned replica keeps; an archive replica, which keeps all, if 0.
+    State         ledger.State                        // State the data of the followed chain is applied to (see Chain); blocks are not interpreted if nil.
+    Mempool       *mempool.Pool                       // Holds proposed transfers, which blocks then carry by fee (see Propose); queued in order if nil.
+    Logger        *slog.Logger                        // Receives rejected blocks and reorganizations; silent if nil.
+}
+
+// Replica is the half of a message-driven replica that Proof of Work, Proof of Stake and Delegated Proof of
+// Stake share: it keeps the block tree, queues proposed data, verifies and adopts blocks announced by peers,
+// fetches missing parents, and re-queues the data of abandoned blocks after a reorganization. An algorithm
+// embeds a Replica and adds a Tick that decides when this replica produces a block, which it passes to Produce,
+// and that calls the Replica's own Tick for anti-entropy.
+type Replica struct {
+    id            int32
+    peers         []int32
+    tree          *Tree
+    chain         *Chain
+    verify        func(block *wire.Block) bool
+    header        func(block *wire.Block) wire.Header
+    confirmations int
+    antiEntropy   int
+    prune         int
+    mempool       *mempool.Pool
+    logger        *slog.Logger
+
+    finalized *wire.Block            // Latest checkpoint passed to Finalize; the genesis block until then.
+    headers   map[string]wire.Header // Headers of pruned blocks, which can no longer be computed from their data.
+    pending   []string               // Proposed data waiting to be included in a block.
+    reorgs    int                    // Number of times the head switched to a branch that did not extend it.
+    ticks     int                    // Ticks seen by Tick, which sends a ChainSummary every antiEntropy of them.
+    nextPeer  int                    // Index in peers of the peer the last ChainSummary went to; this replica's own at first.
+}
+
+// NewReplica creates a replica whose tree holds only the genesis block. With a State, a branch is only followed
+// if its data keeps to the state's rules: the fork-choice rule is not even asked about a branch that breaks them.
+// NewReplica panics if the genesis block's data breaks them.
+func NewReplica(cfg ReplicaConfig) *Replica {
+    chain, err := NewChain(cfg.Genesis, cfg.State)
+    if err != nil {
+        panic("blocktree: " + err.Error())
+    }
+    r := &Replica{
+        id:            cfg.ID,
+        peers:         cfg.Peers,
+        chain:         chain,
+        verify:        cfg.Verify,
+        header:        cfg.Header,
+        confirmations: cfg.Confirmations,
+        antiEntropy:   cfg.AntiEntropy,
+        prune:         max(cfg.Prune, 0),
+        mempool:       cfg.Mempool,
+        logger:        logging.Or(cfg.Logger),
+        finalized:     cfg.Genesis,
+        headers:       make(map[string]wire.Header),
+        nextPeer:      max(slices.Index(cfg.Peers, cfg.ID), 0), // Replicas start at different peers, not all at the first.
+    }
+    rule := cfg.Rule
+    if rule == nil {
+        rule = LongestChain
+    }
+    if choose := rule; cfg.State != nil {
+        rule = func(t *Tree, a, b *wire.Block) bool { return choose(t, a, b) && r.valid(t, a) }
+    }
+    choose := rule
+    rule = func(t *Tree, a, b *wire.Block) bool { return r.includesFinalized(t, a) && choose(t, a, b) }
+    r.tree = New(cfg.Genesis, rule)
+    return r
+}
+
+// includesFinalized reports whether the branch ending at tip includes the finalized checkpoint.
+func (r *Replica) includesFinalized(t *Tree, tip *wire.Block) bool {
+    block := tip
+    for block != nil && block.GetIndex() > r.finalized.GetIndex() {
+        block = t.Get(block.GetPrevHash())
+    }
+    return block.GetHash() == r.finalized.GetHash()
+}
+
+// valid reports whether the branch ending at tip keeps to the rules of the replica's state, as a candidate head
+// of the tree. The branch is checked from the point where it leaves the followed chain.
+func (r *Replica) valid(t *Tree, tip *wire.Block) bool {
+    _, branch, _ := t.Diverge(tip, r.chain.Head())
+    if err := r.chain.CheckForkSwitch(branch); err != nil {
+        r.logger.Warn("refused branch", "tip", tip.GetIndex(), "hash", tip.GetHash(), "err", err)
+        return false
+    }
+    return true
+}
+
+// ID returns the replica's identifier.
+func (r *Replica) ID() int32 { return r.id }
+
+// Tree returns the replica's block tree. Callers must not add blocks to it directly.
+func (r *Replica) Tree() *Tree { return r.tree }
+
+// Head returns the last block of the chain the replica follows.
+func (r *Replica) Head() *wire.Block { return r.tree.Head() }
+
+// Chain returns the chain the replica follows, including blocks that are not yet confirmed. The slice and its
+// blocks are shared with the replica and must not be modified.
+func (r *Replica) Chain() []*wire.Block { return r.chain.view() }
+
+// Blocks returns every block known to the replica, including those on abandoned branches, ordered by index.
+func (r *Replica) Blocks() []*wire.Block { return r.tree.Blocks() }
+
+// Stale returns how many blocks known to the replica are not on the chain it follows: blocks that lost a race to
+// a competing block at the same height, or were built on a branch the replica abandoned.
+func (r *Replica) Stale() int { return r.tree.Len() - len(r.chain.view()) }
+
+// State returns the state after the head of the followed chain, or nil if the replica was created without one.
+// A reorganization replaces it with a copy of the state at the fork point, so it must be asked for again after
+// every step rather than kept. It must not be changed by the caller.
+func (r *Replica) State() ledger.State { return r.chain.State() }
+
+// Reorgs returns how many times the replica abandoned its head for a branch that did not extend it.
+func (r *Replica) Reorgs() int { return r.reorgs }
+
+// Archive reports whether the replica keeps the data of every block, rather than pruning old blocks.
+func (r *Replica) Archive() bool { return r.prune == 0 }
+
+// Pruned returns how many blocks the replica has discarded the data of. It keeps their headers.
+func (r *Replica) Pruned() int { return len(r.headers) }
+
+// Committed returns the followed chain without its last Confirmations blocks, but never without the finalized
+// checkpoint. None of these algorithms has final commitment of its own: a deep enough reorganization can still
+// replace blocks reported here, up to the checkpoint a finality gadget passed to Finalize. The returned blocks are
+// shared with the replica and must not be modified.
+func (r *Replica) Committed() []*wire.Block {
+    chain := r.chain.view()
+    n := max(1, len(chain)-r.confirmations, int(r.finalized.GetIndex())+1) // The genesis block is always committed.
+    return chain[:n:n]
+}
+
+// Finalized returns the latest checkpoint passed to Finalize, or the genesis block if there was none.
+func (r *Replica) Finalized() *wire.Block { return r.finalized }
+
+// Finalize makes checkpoint final, as a finality gadget does once its committee agrees on it: from then on the
+// replica only follows branches that include the checkpoint and refuses every other, however heavy, so no
+// reorganization reaches below it. If the followed chain does not include it, the replica switches to the best
+// branch that does. A checkpoint at or below the finalized one on the same branch changes nothing; one that
+// conflicts with it is refused with ErrConflictingCheckpoint, and one the replica does not hold with an error.
+func (r *Replica) Finalize(checkpoint *wire.Block) error {
+    block := r.tree.Get(checkpoint.GetHash())
+    switch {
+    case block == nil:
+        return fmt.Errorf("blocktree: unknown checkpoint %d %.16s", checkpoint.GetIndex(), checkpoint.GetHash())
+    case block.GetIndex() <= r.finalized.GetIndex():
+        if r.tree.Branch(r.finalized)[block.GetIndex()].GetHash() != block.GetHash() {
+            return fmt.Errorf("%w: block %d", ErrConflictingCheckpoint, block.GetIndex())
+        }
+        return nil
+    case !r.includesFinalized(r.tree, block):
+        return fmt.Errorf("%w: block %d", ErrConflictingCheckpoint, block.GetIndex())
+    }
+    r.finalized = block
+    r.logger.Info("finalized checkpoint", "index", block.GetIndex(), "hash", block.GetHash())
+    if change := r.tree.Reselect(); change != nil {
+        r.follow(change)
+    }
+    return nil
+}
+
+// Propose queues data to be included in a block produced by this replica. With a Mempool, data carrying
+// transfers is split into them and each is added to the pool instead, which may turn them away: the error is
+// the first the pool returned.
+func (r *Replica) Propose(data string) ([]*wire.Envelope, error) {
+    if transfers, err := ledger.DecodeTransfers(data); r.mempool != nil && err == nil && transfers != nil {
+        var first error
+        for _, t := range transfers {
+            if err := r.mempool.Add(t); err != nil && first == nil {
+                first = err
+            }
+        }
+        return nil, first
+    }
+    r.pending = append(r.pending, data)
+    return nil, nil
+}
+
+// Mempool returns the pool proposed transfers wait in, or nil if the replica has none.
+func (r *Replica) Mempool() *mempool.Pool { return r.mempool }
+
+// NextData returns the oldest pending data, or "" if nothing is pending. With a State, data the state after the
+// head refuses is skipped, and stays pending in case a later block makes it valid, as a transfer waiting for the
+// one before it does. With a Mempool, once no other data is pending, it returns the transfers Select chooses
+// from the pool, checked against the state.
+func (r *Replica) NextData() string {
+    s := r.chain.State()
+    for _, data := range r.pending {
+        if s == nil || s.Check(data) == nil {
+            return data
+        }
+    }
+    if r.mempool == nil {
+        return ""
+    }
+    selected := r.mempool.Select(func(transfers []ledger.Transfer) bool {
+        return s == nil || s.Check(ledger.EncodeTransfers(transfers...)) == nil
+    })
+    if len(selected) == 0 {
+        return ""
+    }
+    return ledger.EncodeTransfers(selected...)
+}
+
+// Produce adds a block produced by this replica to its tree and announces it to every peer.
+func (r *Replica) Produce(block *wire.Block) []*wire.Envelope {
+    r.add(block)
+    return r.broadcast(&wire.Envelope{Body: &wire.Envelope_BlockProposal{BlockProposal: &wire.BlockProposal{Block: block}}})
+}
+
+// MaxHeaders is the most headers a replica sends in answer to one HeaderRequest.
+const MaxHeaders = 2000
+
+// Headers returns the headers of the followed chain from index from on, at most limit of them, or MaxHeaders if
+// limit is 0 or larger. It returns nil if the replica was created without a Header function.
+func (r *Replica) Headers(from int64, limit int) []wire.Header {
+    chain := r.chain.view()
+    if r.header == nil || from < 0 || from >= int64(len(chain)) {
+        return nil
+    }
+    if limit <= 0 || limit > MaxHeaders {
+        limit = MaxHeaders
+    }
+    chain = chain[from:min(int(from)+limit, len(chain))]
+    headers := make([]wire.Header, len(chain))
+    for i, block := range chain {
+        headers[i] = r.headerOf(block)
+    }
+    return headers
+}
+
+// headerOf returns the header of a block, kept from before its data was pruned if it was.
+func (r *Replica) headerOf(block *wire.Block) wire.Header {
+    if header, ok := r.headers[block.GetHash()]; ok {
+        return header
+    }
+    return r.header(block)
+}
+
+// Block returns the block of the followed chain at index, if the chain is that long and the replica has not
+// pruned the block's data.
+func (r *Replica) Block(index int64) (*wire.Block, bool) {
+    chain := r.chain.view()
+    if index < 0 || index >= int64(len(chain)) || r.tree.Pruned(chain[index].GetHash()) {
+        return nil, false
+    }
+    return chain[index], true
+}
+
+// Step processes an envelope from a peer: a block announcement, a request for a block, a request for headers,
+// or a summary of the peer's chain. A request for a block whose data was pruned goes unanswered, since the peer
+// could not verify the block without it.
+func (r *Replica) Step(env *wire.Envelope) []*wire.Envelope {
+    switch body := env.GetBody().(type) {
+    case *wire.Envelope_BlockProposal:
+        return r.handleBlock(env.GetFrom(), body.BlockProposal.GetBlock())
+    case *wire.Envelope_BlockRequest:
+        if block := r.tree.Get(body.BlockRequest.GetHash()); block != nil && !r.tree.Pruned(block.GetHash()) {
+            return []*wire.Envelope{{From: r.id, To: env.GetFrom(), Body: &wire.Envelope_BlockProposal{BlockProposal: &wire.BlockProposal{Block: block}}}}
+        }
+    case *wire.Envelope_HeaderRequest:
+        if headers := r.Headers(body.HeaderRequest.GetFrom(), int(body.HeaderRequest.GetLimit())); headers != nil {
+            return []*wire.Envelope{{From: r.id, To: env.GetFrom(), Body: &wire.Envelope_Headers{Headers: wire.EncodeHeaders(headers)}}}
+        }
+    case *wire.Envelope_ChainSummary:
+        return r.handleSummary(env.GetFrom(), body.ChainSummary)
+    }
+    return nil
+}
+
+// handleBlock verifies a block received from a peer. A block whose parent is unknown is kept aside and the
+// parent is requested from the same peer, which walks back along a branch produced during a partition until
+// it reaches a block the replica already has.
+func (r *Replica) handleBlock(from int32, block *wire.Block) []*wire.Envelope {
+    if block == nil || r.tree.Get(block.GetHash()) != nil {
+        return nil
+    }
+    if r.verify != nil && !r.verify(block) {
+        r.logger.Warn("rejected block", "from", from, "hash", block.GetHash())
+        return nil // A real node would also penalize the sender.
+    }
+    if r.mempool != nil && !r.mempool.Limits().Allow(block.GetData()) {
+        r.logger.Warn("rejected oversized block", "from", from, "hash", block.GetHash())
+        return nil
+    }
+    waiting := r.tree.Waiting(block.GetPrevHash())
+    if r.add(block).Orphaned && !waiting {
+        return []*wire.Envelope{{From: r.id, To: from, Body: &wire.Envelope_BlockRequest{BlockRequest: &wire.BlockRequest{Hash: block.GetPrevHash()}}}}
+    }
+    return nil
+}
+
+// add inserts a block into the tree and, if the head moved, switches the chain to the new head with
+// Chain.ApplyForkSwitch, which unwinds the state to the fork point and applies the adopted blocks. It then updates
+// the pending queue: data of abandoned blocks is queued again and data of adopted blocks is no longer pending. A
+// pruned replica finally prunes the blocks the head has left more than Prune blocks behind, keeping their headers.
+func (r *Replica) add(block *wire.Block) Result {
+    result := r.tree.Add(block)
+    if result.Head != nil {
+        r.follow(result.Head)
+    }
+    return result
+}
+
+// follow switches the chain to the tree's new head, as add describes.
+func (r *Replica) follow(change *HeadChange) {
+    defer r.pruneBodies()
+    abandoned, err := r.chain.ApplyForkSwitch(change.Adopted)
+    if err != nil {
+        // The tree only adopts branches the chain has checked, so this is a bug rather than a bad block.
+        r.logger.Error("chain did not follow the tree", "head", change.New.GetIndex(), "err", err)
+    }
+    for _, abandoned := range abandoned {
+        if abandoned.GetData() != "" && !r.pool(abandoned.GetData(), r.mempool.Add) {
+            r.pending = append(r.pending, abandoned.GetData())
+        }
+    }
+    for _, adopted := range change.Adopted {
+        if !r.pool(adopted.GetData(), func(t ledger.Transfer) error { r.mempool.Remove(t); return nil }) {
+            r.removePending(adopted.GetData())
+        }
+    }
+    if change.Reorg() {
+        r.reorgs++
+        r.logger.Info("reorganized", "fork", change.Fork.GetIndex(), "abandoned", len(change.Abandoned), "head", change.New.GetIndex())
+    }
+}
+
+// pruneBodies discards the data of blocks more than Prune blocks below the head, if the replica prunes.
+func (r *Replica) pruneBodies() {
+    if r.prune == 0 {
+        return
+    }
+    r.chain.Prune(r.prune)
+    for _, block := range r.tree.Prune(r.prune) {
+        if r.header != nil {
+            r.headers[block.GetHash()] = r.header(block)
+        } else {
+            r.headers[block.GetHash()] = wire.Header{}
+        }
+    }
+}
+
+// pool calls fn for every transfer data carries, if the replica has a Mempool and data carries transfers, and
+// reports whether it did. Errors are dropped: a transfer of an abandoned block the pool has no room for is lost,
+// as it would be had it arrived while the pool was full.
+func (r *Replica) pool(data string, fn func(ledger.Transfer) error) bool {
+    transfers, err := ledger.DecodeTransfers(data)
+    if r.mempool == nil || err != nil || transfers == nil {
+        return false
+    }
+    for _, t := range transfers {
+        fn(t)
+    }
+    return true
+}
+
+// removePending drops data that has been included in the followed chain from the pending queue.
+func (r *Replica) removePending(data string) {
+    for i, pending := range r.pending {
+        if pending == data {
+            r.pending = append(r.pending[:i], r.pending[i+1:]...)
+            return
+        }
+    }
+}
+
+// broadcast addresses a copy of msg to every other replica.
+func (r *Replica) broadcast(msg *wire.Envelope) []*wire.Envelope {
+    out := make([]*wire.Envelope, 0, len(r.peers)-1)
+    for _, peer := range r.peers {
+        if peer == r.id {
+            continue
+        }
+        out = append(out, &wire.Envelope{From: r.id, To: peer, Body: msg.GetBody()})
+    }
+    return out
+}
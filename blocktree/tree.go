@@ -0,0 +1,330 @@
+// Package blocktree keeps every block a node has seen as a tree rooted at the genesis block, and follows one
+// branch of it as the node's chain. Algorithms without final agreement — Proof of Work, Proof of Stake and
+// Delegated Proof of Stake — let several blocks extend the same parent when nodes produce at the same time or
+// cannot hear each other. Each such block starts a competing branch, and every node must pick the same branch
+// eventually for the network to converge. The tree records the branches, reports the competing tips, and asks a
+// fork-choice Rule supplied by the algorithm which tip to follow: the chain with the most work for Proof of Work,
+// the chain backed by the most stake for Proof of Stake, the chain signed by the most delegates for Delegated
+// Proof of Stake. Replica builds the message handling that every such algorithm needs on top of the tree.
+package blocktree
+
+import (
+    "sort"
+
+    "google.golang.org/protobuf/proto"
+
+    "consensus-algorithms-edu/wire"
+)
+
+// Rule is a fork-choice rule. It reports whether tip a should be followed instead of tip b, which the tree
+// currently follows. Returning false on a tie keeps the current head, so nodes do not switch back and forth
+// between equally good branches.
+type Rule func(t *Tree, a, b *wire.Block) bool
+
+// LongestChain prefers the tip with the higher index. It is Nakamoto's rule for chains whose blocks all take
+// the same work to produce.
+func LongestChain(t *Tree, a, b *wire.Block) bool {
+    return a.GetIndex() > b.GetIndex()
+}
+
+// HeaviestBranch returns a rule that compares the branches after the point where a and b diverge by the total
+// weight of the distinct producers that built them, so a producer who adds many blocks to a branch counts once.
+// Equal weights go to the longer branch. Proof of Stake weighs producers by stake and Delegated Proof of Stake
+// counts each delegate as one; a node then follows the branch that most of the network has been building.
+func HeaviestBranch(weight func(producer string) int) Rule {
+    return func(t *Tree, a, b *wire.Block) bool {
+        _, afterA, afterB := t.Diverge(a, b)
+        wa, wb := branchWeight(afterA, weight), branchWeight(afterB, weight)
+        if wa != wb {
+            return wa > wb
+        }
+        return a.GetIndex() > b.GetIndex()
+    }
+}
+
+// branchWeight sums the weight of every distinct producer of blocks.
+func branchWeight(blocks []*wire.Block, weight func(producer string) int) int {
+    seen := make(map[string]bool)
+    total := 0
+    for _, block := range blocks {
+        if producer := block.GetProducer(); !seen[producer] {
+            seen[producer] = true
+            total += weight(producer)
+        }
+    }
+    return total
+}
+
+// HeadChange describes how the followed chain changed when the tree switched to a new head.
+type HeadChange struct {
+    Old       *wire.Block   // Previous head.
+    New       *wire.Block   // New head.
+    Fork      *wire.Block   // Last block the old and new chains share.
+    Abandoned []*wire.Block // Blocks of the old chain after Fork, oldest first; empty if the new head extends the old.
+    Adopted   []*wire.Block // Blocks of the new chain after Fork, oldest first.
+}
+
+// Reorg reports whether the change abandoned blocks, rather than only extending the chain.
+func (c *HeadChange) Reorg() bool {
+    return len(c.Abandoned) > 0
+}
+
+// Result reports what happened to a block given to Add.
+type Result struct {
+    Connected []*wire.Block // The block and any orphans it connected, in the order they joined the tree.
+    Orphaned  bool          // The block's parent is unknown; the block is kept until the parent arrives.
+    Head      *HeadChange   // How the head moved, or nil if it did not.
+}
+
+// Tree is a tree of blocks rooted at a genesis block, with one tip chosen by a Rule as the head.
+// A Tree is not safe for concurrent use.
+type Tree struct {
+    rule     Rule
+    blocks   map[string]*wire.Block   // Every block connected to the tree, keyed by hash.
+    children map[string][]*wire.Block // Children of every block, keyed by the parent's hash, in arrival order.
+    orphans  map[string][]*wire.Block // Blocks whose parent is still unknown, keyed by the parent's hash.
+    order    []*wire.Block            // Connected blocks in arrival order.
+    pruned   map[string]bool          // Blocks whose data Prune discarded, keyed by hash.
+    head     *wire.Block
+}
+
+// New creates a tree holding only genesis, following tips chosen by rule; LongestChain is used if rule is nil.
+func New(genesis *wire.Block, rule Rule) *Tree {
+    if rule == nil {
+        rule = LongestChain
+    }
+    return &Tree{
+        rule:     rule,
+        blocks:   map[string]*wire.Block{genesis.GetHash(): genesis},
+        children: make(map[string][]*wire.Block),
+        orphans:  make(map[string][]*wire.Block),
+        order:    []*wire.Block{genesis},
+        pruned:   make(map[string]bool),
+        head:     genesis,
+    }
+}
+
+// Add inserts a block. A block whose parent is unknown is kept as an orphan and connected as soon as the parent
+// is added. Duplicates, and blocks whose index does not follow their parent's, are ignored. Every connected
+// block is offered to the rule as a candidate head.
+func (t *Tree) Add(block *wire.Block) Result {
+    var result Result
+    if t.blocks[block.GetHash()] != nil || t.isOrphan(block) {
+        return result
+    }
+    if _, ok := t.blocks[block.GetPrevHash()]; !ok {
+        t.orphans[block.GetPrevHash()] = append(t.orphans[block.GetPrevHash()], block)
+        result.Orphaned = true
+        return result
+    }
+
+    old := t.head
+    queue := []*wire.Block{block}
+    for len(queue) > 0 {
+        next := queue[0]
+        queue = queue[1:]
+        if next.GetIndex() != t.blocks[next.GetPrevHash()].GetIndex()+1 {
+            continue
+        }
+        t.blocks[next.GetHash()] = next
+        t.children[next.GetPrevHash()] = append(t.children[next.GetPrevHash()], next)
+        t.order = append(t.order, next)
+        result.Connected = append(result.Connected, next)
+        if t.rule(t, next, t.head) {
+            t.head = next
+        }
+        queue = append(queue, t.orphans[next.GetHash()]...)
+        delete(t.orphans, next.GetHash())
+    }
+    if t.head != old {
+        result.Head = t.change(old, t.head)
+    }
+    return result
+}
+
+// Reselect chooses the head again among every connected block, offering them to the rule in the order they
+// arrived as Add does, and returns the change, or nil if the head stays. It is for a replica whose rule has
+// tightened since the blocks arrived, such as one that finalized a checkpoint and now refuses branches without
+// it: the head may then be a tip the rule no longer accepts.
+func (t *Tree) Reselect() *HeadChange {
+    old, best := t.head, t.order[0]
+    for _, block := range t.order[1:] {
+        if t.rule(t, block, best) {
+            best = block
+        }
+    }
+    if best == old {
+        return nil
+    }
+    t.head = best
+    return t.change(old, best)
+}
+
+// isOrphan reports whether the block is already waiting for its parent.
+func (t *Tree) isOrphan(block *wire.Block) bool {
+    for _, orphan := range t.orphans[block.GetPrevHash()] {
+        if orphan.GetHash() == block.GetHash() {
+            return true
+        }
+    }
+    return false
+}
+
+// change describes the switch from head old to head new.
+func (t *Tree) change(old, new *wire.Block) *HeadChange {
+    fork, abandoned, adopted := t.Diverge(old, new)
+    return &HeadChange{Old: old, New: new, Fork: fork, Abandoned: abandoned, Adopted: adopted}
+}
+
+// Get returns the block with the given hash, or nil if it is not connected to the tree.
+func (t *Tree) Get(hash string) *wire.Block {
+    return t.blocks[hash]
+}
+
+// Waiting reports whether orphans are waiting for the block with the given hash, i.e. whether it has already
+// been found missing.
+func (t *Tree) Waiting(hash string) bool {
+    return len(t.orphans[hash]) > 0
+}
+
+// Len returns the number of blocks connected to the tree, including the genesis block.
+func (t *Tree) Len() int {
+    return len(t.order)
+}
+
+// Head returns the tip the tree currently follows.
+func (t *Tree) Head() *wire.Block {
+    return t.head
+}
+
+// Chain returns the followed chain, from the genesis block to the head.
+func (t *Tree) Chain() []*wire.Block {
+    return t.Branch(t.head)
+}
+
+// Branch returns the chain from the genesis block to tip.
+func (t *Tree) Branch(tip *wire.Block) []*wire.Block {
+    var chain []*wire.Block
+    for block := tip; block != nil; block = t.blocks[block.GetPrevHash()] {
+        chain = append(chain, block)
+    }
+    reverse(chain)
+    return chain
+}
+
+// Diverge returns the last block the chains ending at a and b share, and the blocks of each chain after it,
+// oldest first.
+func (t *Tree) Diverge(a, b *wire.Block) (fork *wire.Block, afterA, afterB []*wire.Block) {
+    for a.GetHash() != b.GetHash() {
+        if a.GetIndex() >= b.GetIndex() {
+            afterA = append(afterA, a)
+            a = t.blocks[a.GetPrevHash()]
+        } else {
+            afterB = append(afterB, b)
+            b = t.blocks[b.GetPrevHash()]
+        }
+    }
+    reverse(afterA)
+    reverse(afterB)
+    return a, afterA, afterB
+}
+
+// Tips returns every block without children: the head and the tips of competing branches, highest first.
+func (t *Tree) Tips() []*wire.Block {
+    var tips []*wire.Block
+    for _, block := range t.order {
+        if len(t.children[block.GetHash()]) == 0 {
+            tips = append(tips, block)
+        }
+    }
+    sort.SliceStable(tips, func(i, j int) bool { return tips[i].GetIndex() > tips[j].GetIndex() })
+    return tips
+}
+
+// Competing returns the tips other than the head. The network has not converged while any remain that are
+// not far behind the head; a tip many blocks behind is an abandoned branch.
+func (t *Tree) Competing() []*wire.Block {
+    var competing []*wire.Block
+    for _, tip := range t.Tips() {
+        if tip != t.head {
+            competing = append(competing, tip)
+        }
+    }
+    return competing
+}
+
+// Blocks returns every block connected to the tree, ordered by index and then by arrival.
+func (t *Tree) Blocks() []*wire.Block {
+    blocks := append([]*wire.Block(nil), t.order...)
+    sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].GetIndex() < blocks[j].GetIndex() })
+    return blocks
+}
+
+// Prune discards the data of every block, on any branch, more than depth blocks below the head, and returns the
+// blocks it pruned as they were before, oldest first. A pruned block stays in the tree with its index, hash and
+// links, so the tree still follows, compares and extends its branches; only the data is gone. Blocks given to Add
+// are never changed: a pruned block is replaced by a copy without its data.
+func (t *Tree) Prune(depth int) []*wire.Block {
+    below := t.head.GetIndex() - int64(depth)
+    replaced := make(map[string]*wire.Block)
+    var pruned []*wire.Block
+    for i, block := range t.order {
+        if block.GetIndex() >= below || t.pruned[block.GetHash()] {
+            continue
+        }
+        header := withoutData(block)
+        t.order[i] = header
+        t.blocks[block.GetHash()] = header
+        t.pruned[block.GetHash()] = true
+        replaced[block.GetHash()] = header
+        pruned = append(pruned, block)
+    }
+    for _, children := range t.children {
+        for i, c := range children {
+            if header := replaced[c.GetHash()]; header != nil {
+                children[i] = header
+            }
+        }
+    }
+    sort.SliceStable(pruned, func(i, j int) bool { return pruned[i].GetIndex() < pruned[j].GetIndex() })
+    return pruned
+}
+
+// withoutData returns a copy of block without its data.
+func withoutData(block *wire.Block) *wire.Block {
+    header := proto.Clone(block).(*wire.Block)
+    header.Data = ""
+    return header
+}
+
+// Pruned reports whether Prune discarded the data of the block with the given hash.
+func (t *Tree) Pruned(hash string) bool {
+    return t.pruned[hash]
+}
+
+// reverse reverses blocks in place.
+func reverse(blocks []*wire.Block) {
+    for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+        blocks[i], blocks[j] = blocks[j], blocks[i]
+    }
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **One Tree, Many Rules**: The tree knows nothing about work, stake or delegates. Everything an algorithm
+//    contributes to fork resolution is the Rule comparing two tips, which keeps the three algorithms' fork
+//    behavior side by side and makes the rule itself the thing to study and swap.
+//
+// 2. **Candidates Are Compared Pairwise**: Each newly connected block is compared only with the current head, and a
+//    tie keeps the head. Nodes that have seen the same blocks can still follow different tips of equal weight until
+//    the next block breaks the tie, which is exactly the temporary disagreement these algorithms accept.
+//
+// 3. **Orphans Are Kept, Not Dropped**: Blocks from a healed partition often arrive before their parents. Keeping
+//    them until the parent connects lets a node fetch a whole branch backwards, one missing parent at a time,
+//    without any separate synchronization protocol.
+//
+// 4. **No Finality of Its Own**: No block ever leaves the tree. Abandoned branches stay so they can be drawn and
+//    counted, and Replica.Committed only trails the head by a number of confirmations, since a long enough
+//    competing branch can always replace blocks that looked settled. Only a finality gadget outside the tree, such
+//    as package casper, makes a block final, through Replica.Finalize. Prune discards only data, never the links
+//    between blocks.
@@ -0,0 +1,287 @@
+// Package cartel plays a group of Delegated Proof of Stake candidates that collude to capture the active set of
+// block producers, with a briber buying the votes they lack, and measures how fast they succeed depending on how
+// many voters turn out. DPoS elects its producers by stake-weighted approval voting: every voter may approve
+// many candidates and each receives its whole stake. A cartel therefore needs no majority of the stake, only
+// more approval than the weakest honest producer, and the fewer voters take part, the less that is. Once seated,
+// the cartel earns block rewards it can pass on as bribes, which buys the next seat sooner.
+//
+// Run plays the same cartel and briber once for each participation rate, electing the active set with
+// dpos.Tally every round, and reports the rounds the cartel took to hold a majority and two thirds of the seats:
+// enough to decide which blocks are irreversible, and so to censor or rewrite what it likes.
+package cartel
+
+import (
+    "cmp"
+    "context"
+    "fmt"
+    "math/rand"
+    "slices"
+
+    "consensus-algorithms-edu/algorithms/dpos"
+)
+
+// Defaults used by Run for the zero values of Config.
+const (
+    DefaultVoters   = 1000
+    DefaultHonest   = 30
+    DefaultMembers  = 15
+    DefaultSeats    = 21
+    DefaultSlate    = 5
+    DefaultStake    = 0.03
+    DefaultBudget   = 20
+    DefaultKickback = 0.1
+    DefaultRounds   = 100
+)
+
+// DefaultParticipation are the shares of the voters Run lets vote if Config.Participation is empty.
+var DefaultParticipation = []float64{0.1, 0.25, 0.5, 0.9}
+
+// totalStake is the stake of all voters together, which Config.Stake is a share of.
+const totalStake = 1_000_000
+
+// reward is what a producer earns per round, in the unit bribes are paid in.
+const reward = 1000
+
+// maxPrice is the highest price per unit of stake at which a voter sells its vote. Each voter's price is drawn
+// uniformly below it, so a little stake is cheap and the rest grows dear.
+const maxPrice = 1
+
+// Config describes the cartel, the briber and the electorate.
+type Config struct {
+    Participation []float64 // Shares of the honest voters who vote, one run each; DefaultParticipation if empty.
+    Voters        int       // Honest voters; DefaultVoters if 0.
+    Honest        int       // Honest candidates; DefaultHonest if 0.
+    Members       int       // Candidates in the cartel; DefaultMembers if 0.
+    Seats         int       // Size of the active set of producers; DefaultSeats if 0.
+    Slate         int       // Honest candidates each honest voter approves; DefaultSlate if 0.
+    Stake         float64   // Cartel's own share of all stake, which always votes; DefaultStake if 0.
+    Budget        int       // Bribes the briber pays out of its own pocket per round; DefaultBudget if 0.
+    Kickback      float64   // Share of the cartel's block rewards passed on as bribes; DefaultKickback if 0.
+    Rounds        int       // Elections each run lasts; DefaultRounds if 0.
+    Seed          int64     // Seed of every run, so each participation rate meets the same voters and prices.
+}
+
+// Run plays the cartel once for each participation rate in cfg and reports the results in that order. It stops
+// early with ctx's error once ctx is done.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+    if len(cfg.Participation) == 0 {
+        cfg.Participation = DefaultParticipation
+    }
+    if cfg.Voters <= 0 {
+        cfg.Voters = DefaultVoters
+    }
+    if cfg.Honest <= 0 {
+        cfg.Honest = DefaultHonest
+    }
+    if cfg.Members <= 0 {
+        cfg.Members = DefaultMembers
+    }
+    if cfg.Seats <= 0 {
+        cfg.Seats = DefaultSeats
+    }
+    if cfg.Slate <= 0 {
+        cfg.Slate = min(DefaultSlate, cfg.Honest)
+    }
+    if cfg.Stake <= 0 || cfg.Stake >= 1 {
+        cfg.Stake = DefaultStake
+    }
+    if cfg.Budget <= 0 {
+        cfg.Budget = DefaultBudget
+    }
+    if cfg.Kickback <= 0 || cfg.Kickback > 1 {
+        cfg.Kickback = DefaultKickback
+    }
+    if cfg.Rounds <= 0 {
+        cfg.Rounds = DefaultRounds
+    }
+
+    report := &Report{Config: cfg}
+    for _, participation := range cfg.Participation {
+        res, err := newElectorate(cfg, participation).play(ctx)
+        if err != nil {
+            return nil, err
+        }
+        report.Results = append(report.Results, res)
+    }
+    return report, nil
+}
+
+// voter is an honest voter: the stake it holds, whether it turns out, the honest candidates it approves when it
+// does, and the price per unit of stake at which it votes for the cartel instead.
+type voter struct {
+    name   string
+    stake  int
+    votes  bool
+    slate  []string
+    price  float64
+    bribed bool
+}
+
+// cost returns what the voter's vote costs the briber.
+func (v *voter) cost() int {
+    return int(v.price*float64(v.stake) + 0.5)
+}
+
+// electorate is one run: the candidates, the voters and the briber's purse.
+type electorate struct {
+    cfg     Config
+    honest  []string // Names of the honest candidates.
+    members []string // Names of the cartel's candidates.
+    voters  []*voter
+    byPrice []*voter // The voters, cheapest per unit of stake first.
+    stake   int      // Cartel's own stake.
+    purse   int      // Bribes saved up and not yet paid.
+}
+
+// newElectorate draws the voters of a run. Stakes are drawn from an exponential distribution, so a few voters
+// hold much of the stake, as on deployed chains; which voters turn out, and their slates and prices, are drawn
+// from the seed alone, so runs of different participation rates differ only in the voters who stay home.
+func newElectorate(cfg Config, participation float64) *electorate {
+    rng := rand.New(rand.NewSource(cfg.Seed))
+    e := &electorate{cfg: cfg, stake: int(cfg.Stake * totalStake)}
+    for i := range cfg.Honest {
+        e.honest = append(e.honest, fmt.Sprintf("honest-%d", i))
+    }
+    for i := range cfg.Members {
+        e.members = append(e.members, fmt.Sprintf("cartel-%d", i))
+    }
+
+    weights, sum := make([]float64, cfg.Voters), 0.0
+    for i := range weights {
+        weights[i] = rng.ExpFloat64()
+        sum += weights[i]
+    }
+    for i, w := range weights {
+        v := &voter{name: fmt.Sprintf("voter-%d", i), stake: max(1, int(w/sum*float64(totalStake-e.stake))), price: rng.Float64() * maxPrice}
+        v.votes = rng.Float64() < participation
+        v.slate = e.pickSlate(rng)
+        e.voters = append(e.voters, v)
+    }
+    e.byPrice = slices.Clone(e.voters)
+    slices.SortStableFunc(e.byPrice, func(a, b *voter) int { return cmp.Compare(a.price, b.price) })
+    return e
+}
+
+// pickSlate draws the honest candidates a voter approves. Candidate i is chosen with weight 1/(i+1), as a few
+// well-known producers gather most votes on deployed chains, so honest candidates are spread from the popular to
+// the barely elected, and the cartel climbs past the weakest of them one by one.
+func (e *electorate) pickSlate(rng *rand.Rand) []string {
+    var slate []string
+    for len(slate) < e.cfg.Slate {
+        total := 0.0
+        for i, name := range e.honest {
+            if !slices.Contains(slate, name) {
+                total += 1 / float64(i+1)
+            }
+        }
+        x := rng.Float64() * total
+        for i, name := range e.honest {
+            if slices.Contains(slate, name) {
+                continue
+            }
+            if x -= 1 / float64(i+1); x < 0 {
+                slate = append(slate, name)
+                break
+            }
+        }
+    }
+    return slate
+}
+
+// play holds one election per round, with the briber buying votes before each, and returns when the cartel held
+// each share of the seats first.
+func (e *electorate) play(ctx context.Context) (Result, error) {
+    res := Result{Participation: e.participationShare()}
+    seats := 0
+    for round := 1; round <= e.cfg.Rounds; round++ {
+        if err := ctx.Err(); err != nil {
+            return Result{}, err
+        }
+        e.purse += e.cfg.Budget + int(e.cfg.Kickback*float64(seats*reward))
+        if seats < min(e.cfg.Members, e.cfg.Seats) {
+            res.Paid += e.bribe() // Votes are only worth buying while a member is left out.
+        }
+
+        seats = 0
+        for _, name := range dpos.Tally(e.ballots(), e.cfg.Seats) {
+            if slices.Contains(e.members, name) {
+                seats++
+            }
+        }
+        if seats > e.cfg.Seats/2 && res.Majority == 0 {
+            res.Majority = round
+        }
+        if seats > 2*e.cfg.Seats/3 && res.Capture == 0 {
+            res.Capture = round
+        }
+    }
+    res.Seats = seats
+    for _, v := range e.voters {
+        if v.bribed {
+            res.Bribed += float64(v.stake) / totalStake
+        }
+    }
+    return res, nil
+}
+
+// bribe spends the purse on the cheapest votes still for sale, per unit of stake, and saves the rest for the next
+// round once the cheapest costs more than it holds. It returns what it paid.
+func (e *electorate) bribe() int {
+    paid := 0
+    for _, v := range e.byPrice {
+        if v.bribed {
+            continue
+        }
+        if e.purse < v.cost() {
+            break
+        }
+        v.bribed = true
+        e.purse -= v.cost()
+        paid += v.cost()
+    }
+    return paid
+}
+
+// ballots returns the ballots of a round: the cartel's own stake and every bribed voter approve every member,
+// and the honest voters who turn out approve their slates.
+func (e *electorate) ballots() []dpos.Ballot {
+    ballots := []dpos.Ballot{{Voter: "cartel", Stake: e.stake, Approve: e.members}}
+    for _, v := range e.voters {
+        switch {
+        case v.bribed:
+            ballots = append(ballots, dpos.Ballot{Voter: v.name, Stake: v.stake, Approve: e.members})
+        case v.votes:
+            ballots = append(ballots, dpos.Ballot{Voter: v.name, Stake: v.stake, Approve: v.slate})
+        }
+    }
+    return ballots
+}
+
+// participationShare returns the share of the honest stake whose voters turn out.
+func (e *electorate) participationShare() float64 {
+    voting, total := 0, 0
+    for _, v := range e.voters {
+        total += v.stake
+        if v.votes {
+            voting += v.stake
+        }
+    }
+    return float64(voting) / float64(total)
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **The Real Tally, an Abstract Chain**: Every election goes through dpos.Tally, the stake-weighted approval
+//    count of deployed DPoS. The blocks between elections are abstracted to the reward each seat earns, which is
+//    all the cartel's economics depend on.
+//
+// 2. **Approval Voting Is the Lever**: An honest voter approves a few candidates and a bought one all of the
+//    cartel's, so each bribe lifts every member at once while honest stake is spread over thirty candidates.
+//    Counting one vote per voter, as Blockchain.CountVotes does, would blunt the attack and hide the point.
+//
+// 3. **A Vote Bought Stays Bought**: A DPoS vote stands until the voter changes it, so a bribe is paid once and
+//    the bought stake only grows. The kickback out of block rewards makes capture self-financing: each seat won
+//    pays for the votes that win the next, and the briber stops buying once every member is seated.
+//
+// 4. **Same Voters, Different Turnout**: Every run draws the same stakes, slates and prices from the seed; the
+//    participation rate only decides who stays home. Differences between rows are the effect of turnout alone.
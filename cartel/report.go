@@ -0,0 +1,49 @@
+package cartel
+
+import (
+    "fmt"
+    "io"
+    "text/tabwriter"
+)
+
+// Report is the outcome of the attack.
+type Report struct {
+    Config  Config   // The attack, with defaults filled in.
+    Results []Result // One result per participation rate, in the order of Config.Participation.
+}
+
+// Result is how far the cartel got at one participation rate.
+type Result struct {
+    Participation float64 // Share of the honest stake whose voters turned out.
+    Majority      int     // Round in which the cartel first held more than half of the seats, or 0 if it never did.
+    Capture       int     // Round in which the cartel first held more than two thirds of the seats, or 0 if it never did.
+    Seats         int     // Seats the cartel held after the last round.
+    Bribed        float64 // Share of all stake the briber had bought after the last round.
+    Paid          int     // Bribes paid over the whole run; a producer earns 1000 a round.
+}
+
+// Captured reports whether the cartel held more than two thirds of the seats at some round.
+func (r Result) Captured() bool {
+    return r.Capture > 0
+}
+
+// Print writes the report as a table with a row per participation rate.
+func (r *Report) Print(w io.Writer) error {
+    c := r.Config
+    fmt.Fprintf(w, "%d seats, %d cartel and %d honest candidates, cartel stake %.0f%%, bribes %d a round plus %.0f%% of rewards, %d rounds, seed %d\n\n",
+        c.Seats, c.Members, c.Honest, 100*c.Stake, c.Budget, 100*c.Kickback, c.Rounds, c.Seed)
+    tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintln(tw, "turnout\tmajority\tcapture\tseats\tstake bought\tbribes paid\t")
+    for _, res := range r.Results {
+        fmt.Fprintf(tw, "%.0f%%\t%s\t%s\t%d/%d\t%.1f%%\t%d\t\n", 100*res.Participation, round(res.Majority), round(res.Capture), res.Seats, c.Seats, 100*res.Bribed, res.Paid)
+    }
+    return tw.Flush()
+}
+
+// round writes the round an event happened in.
+func round(n int) string {
+    if n == 0 {
+        return "never"
+    }
+    return fmt.Sprintf("round %d", n)
+}
@@ -0,0 +1,237 @@
+// Package casper layers a finality gadget over a chain whose blocks are never final on their own, in the style of
+// Casper FFG, the Friendly Finality Gadget proposed to run on top of Ethereum's Proof of Work. Proof of Work gives
+// probabilistic finality: a block becomes harder to replace with every block mined on top of it, but a miner with
+// enough hash power can always replace it. The gadget adds absolute finality at intervals. Every Epoch blocks of
+// the chain form a checkpoint, and a committee of staked validators votes on them:
+//
+//   - A vote is a link from a source checkpoint, the latest one the validator knows to be justified, to a target
+//     checkpoint, the latest one buried Depth blocks deep in the validator's chain.
+//   - A checkpoint is justified once validators holding two thirds of the committee's stake, or another
+//     Config.Quorum, vote for links to it from the same justified source. The genesis block is justified from the
+//     start.
+//   - A justified checkpoint is finalized once the checkpoint of the next epoch is justified by links from it.
+//
+// Once a checkpoint is final, a node following the chain refuses every branch without it (see
+// blocktree.Replica.Finalize): a reorganization past the checkpoint is rejected however much work the competing
+// branch carries. Below the latest finalized checkpoint finality is absolute; above it, it is as probabilistic as
+// before.
+//
+// Overlay wraps any replica built on blocktree.Replica, such as a pow.Miner, and exchanges the committee's votes
+// as wire.CheckpointVote messages next to the blocks.
+package casper
+
+import (
+    "maps"
+    "slices"
+
+    "consensus-algorithms-edu/blocktree"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/quorum"
+    "consensus-algorithms-edu/wire"
+)
+
+// Defaults used by Overlay for the zero values of Config.
+const (
+    DefaultEpoch = 10 // Blocks between two checkpoints.
+    DefaultDepth = 2  // Blocks that must follow a checkpoint before validators vote for it.
+)
+
+// Follower is a replica following a chain a committee can finalize checkpoints of. Every replica embedding a
+// blocktree.Replica, such as pow.Miner, is one.
+type Follower interface {
+    node.Replica
+    Chain() []*wire.Block                  // The chain the replica follows, from the genesis block.
+    Tree() *blocktree.Tree                 // Every block the replica knows.
+    Finalize(checkpoint *wire.Block) error // Refuse every branch without checkpoint from now on.
+    Finalized() *wire.Block                // Latest finalized checkpoint.
+}
+
+// Config describes the committee and its checkpoints.
+type Config struct {
+    Committee map[int32]int  // Stake of each validator of the committee, by the identifier of the node it runs on.
+    Peers     []int32        // Every node following the chain, committee or not, to send votes to.
+    Epoch     int            // Blocks between two checkpoints: those at heights that are multiples of Epoch; DefaultEpoch if 0.
+    Depth     int            // Blocks that must follow a checkpoint before a validator votes for it; DefaultDepth if 0.
+    Quorum    quorum.Builder // Validators whose votes justify a checkpoint; two thirds of the Committee's stake if nil.
+}
+
+// checkpoint names a checkpoint by its height and hash.
+type checkpoint struct {
+    height int64
+    hash   string
+}
+
+// link is a vote's source and target.
+type link struct {
+    source, target checkpoint
+}
+
+// Replica is a follower with the finality gadget layered over it: it votes for checkpoints if its node is a
+// validator of the committee, counts the votes of the others, and finalizes the checkpoints they agree on.
+type Replica struct {
+    Follower
+    cfg       Config
+    quorum    quorum.Quorum           // Validators whose votes for a link justify its target.
+    votes     map[link]map[int32]bool // Validators that voted for each link.
+    justified map[checkpoint]bool     // Justified checkpoints.
+    final     []checkpoint            // Finalized checkpoints waiting for their block, lowest first.
+    voted     int64                   // Height of the last target this node's validator voted for.
+}
+
+// Overlay layers the finality gadget over follower. Every node of the network must be overlaid with the same
+// cfg, since the others ignore the votes of nodes outside the committee and act on none of the messages an
+// overlay does not send.
+func Overlay(follower Follower, cfg Config) *Replica {
+    if cfg.Epoch <= 0 {
+        cfg.Epoch = DefaultEpoch
+    }
+    if cfg.Depth <= 0 {
+        cfg.Depth = DefaultDepth
+    }
+    if cfg.Quorum == nil {
+        cfg.Quorum = quorum.AtLeast(cfg.Committee, 2, 3)
+    }
+    validators := slices.Sorted(maps.Keys(cfg.Committee))
+    r := &Replica{
+        Follower:  follower,
+        cfg:       cfg,
+        votes:     make(map[link]map[int32]bool),
+        justified: make(map[checkpoint]bool),
+        quorum:    cfg.Quorum(validators),
+    }
+    genesis := follower.Chain()[0]
+    r.justified[checkpoint{0, genesis.GetHash()}] = true
+    return r
+}
+
+// Unwrap returns the follower inside, e.g. to inspect its state.
+func (r *Replica) Unwrap() node.Replica {
+    return r.Follower
+}
+
+// Leader returns the leader the follower inside follows, or -1 if its algorithm has none.
+func (r *Replica) Leader() int32 {
+    if l, ok := r.Follower.(node.Leaderful); ok {
+        return l.Leader()
+    }
+    return -1
+}
+
+// Justified reports whether the block is a checkpoint the replica knows to be justified.
+func (r *Replica) Justified(block *wire.Block) bool {
+    return r.justified[checkpoint{block.GetIndex(), block.GetHash()}]
+}
+
+// Step counts a checkpoint vote, or passes any other envelope to the follower inside, and then votes if the
+// follower's chain has buried a new checkpoint.
+func (r *Replica) Step(env *wire.Envelope) []*wire.Envelope {
+    if body, ok := env.GetBody().(*wire.Envelope_CheckpointVote); ok {
+        if body.CheckpointVote.GetValidator() == env.GetFrom() { // A validator votes only for itself.
+            r.count(body.CheckpointVote)
+        }
+        return r.vote()
+    }
+    return append(r.Follower.Step(env), r.vote()...)
+}
+
+// Tick advances the follower inside and votes if its chain has buried a new checkpoint.
+func (r *Replica) Tick() []*wire.Envelope {
+    return append(r.Follower.Tick(), r.vote()...)
+}
+
+// vote returns this node's vote for the latest checkpoint buried Depth blocks deep in its chain, sent to every
+// peer, if its node is a validator and has not voted at that height or above. The source is the latest
+// justified checkpoint on the same chain. A validator never votes twice for one height, which is one of the two
+// rules whose breach Casper slashes.
+func (r *Replica) vote() []*wire.Envelope {
+    r.finalizeKnown()
+    if r.cfg.Committee[r.ID()] <= 0 {
+        return nil
+    }
+    chain := r.Chain()
+    epoch := int64(r.cfg.Epoch)
+    height := (int64(len(chain)) - 1 - int64(r.cfg.Depth)) / epoch * epoch
+    if height <= r.voted || height <= 0 {
+        return nil
+    }
+    var source checkpoint
+    for h := height - epoch; h >= 0; h -= epoch {
+        if c := (checkpoint{h, chain[h].GetHash()}); r.justified[c] {
+            source = c
+            break
+        }
+    }
+    r.voted = height
+    v := &wire.CheckpointVote{Validator: r.ID(), SourceHeight: source.height, SourceHash: source.hash, TargetHeight: height, TargetHash: chain[height].GetHash()}
+    r.count(v)
+    var out []*wire.Envelope
+    for _, peer := range r.cfg.Peers {
+        if peer != r.ID() {
+            out = append(out, &wire.Envelope{From: r.ID(), To: peer, Body: &wire.Envelope_CheckpointVote{CheckpointVote: v}})
+        }
+    }
+    return out
+}
+
+// count records a vote of a committee validator and justifies and finalizes what the votes so far allow.
+func (r *Replica) count(v *wire.CheckpointVote) {
+    if r.cfg.Committee[v.GetValidator()] <= 0 {
+        return
+    }
+    l := link{checkpoint{v.GetSourceHeight(), v.GetSourceHash()}, checkpoint{v.GetTargetHeight(), v.GetTargetHash()}}
+    if r.votes[l] == nil {
+        r.votes[l] = make(map[int32]bool)
+    }
+    r.votes[l][v.GetValidator()] = true
+    r.settle()
+}
+
+// settle justifies every target of a link from a justified source with a quorum of the committee behind it, and
+// finalizes every justified source whose link to the next checkpoint justified it, until nothing changes: a
+// justification can make the votes already counted for links from it decisive.
+func (r *Replica) settle() {
+    for changed := true; changed; {
+        changed = false
+        for l, voters := range r.votes {
+            if !r.justified[l.source] || r.justified[l.target] || !r.quorum.Reached(slices.Collect(maps.Keys(voters))) {
+                continue
+            }
+            r.justified[l.target] = true
+            changed = true
+            if l.target.height == l.source.height+int64(r.cfg.Epoch) {
+                r.final = append(r.final, l.source)
+            }
+        }
+    }
+    slices.SortFunc(r.final, func(a, b checkpoint) int { return int(a.height - b.height) })
+    r.finalizeKnown()
+}
+
+// finalizeKnown passes the finalized checkpoints whose blocks the follower holds to its Finalize, lowest first.
+// A checkpoint can be finalized by votes that arrive before its block does; it waits until the block arrives.
+func (r *Replica) finalizeKnown() {
+    for len(r.final) > 0 {
+        block := r.Tree().Get(r.final[0].hash)
+        if block == nil {
+            return
+        }
+        r.Finalize(block) // A conflicting checkpoint would mean a third of the committee broke the rules; it is ignored.
+        r.final = r.final[1:]
+    }
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **An Overlay, Not a New Algorithm**: The miners keep mining and choosing the longest chain exactly as before;
+//    the gadget only adds votes and, once a checkpoint is final, one restriction on the fork choice. That is how
+//    Casper FFG was proposed for Ethereum's Proof of Work, and it keeps the two kinds of finality separate enough
+//    to compare in one run.
+//
+// 2. **Votes From the Validator's Own Chain**: A validator votes for the checkpoint its own chain buried Depth
+//    blocks deep, and counts only stake, never hash power. While miners disagree about recent blocks, votes split
+//    between checkpoints and none is justified; the next epoch tries again once the chain has settled.
+//
+// 3. **Honest Committees Only**: Casper slashes validators that vote twice for one height or cast a vote that
+//    surrounds another of theirs, which is what makes conflicting finalized checkpoints cost a third of the stake.
+//    Validators here follow the rules, and the overlay does not check for breaches; package evidence shows how
+//    signed statements turn such breaches into proof.
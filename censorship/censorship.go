@@ -0,0 +1,373 @@
+// Package censorship plays a leader that silently leaves out one client's transactions, and measures whether
+// each algorithm notices. A censor is the cheapest attack on a live network: it stays within every rule an
+// honest node checks, keeps ordering everyone else's transactions, and votes on blocks like anyone else, so
+// neither safety nor liveness as the algorithms define them is violated. Only the victim can tell, by the
+// transactions of its own that never commit, and what it can then do depends on the algorithm:
+//
+//   - Raft: the leader alone appends to the log. A client that times out submits to every replica, whose
+//     followers, given a RequestTimeout, campaign if the transaction does not commit in time, and the new leader
+//     proposes what the old one ignored.
+//   - PBFT: the primary alone assigns sequence numbers. A client that times out submits to every replica, whose
+//     backups forward the request and start their view-change timers, and the next primary orders it.
+//   - DPoS: the delegates take turns, so a censor only delays a transaction until an honest delegate's slot.
+//     Voters who read the chain see a block that left out a transaction waiting since before its slot, and vote
+//     its producer out at the end of the epoch in favor of a standby delegate.
+//
+// Run plays each scheme without and with detection, the victim's client and all others submitting at a steady
+// rate, and reports how many of the victim's transactions were included and how late. Every run is a
+// message-driven simulation of package sim, so a report is repeatable from its seed and takes no real time.
+package censorship
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "slices"
+    "strings"
+    "time"
+
+    "consensus-algorithms-edu/adversary"
+    "consensus-algorithms-edu/algorithms/dpos"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+)
+
+// ErrUnknownScheme is returned by Run for a scheme it has no scenario for.
+var ErrUnknownScheme = errors.New("censorship: unknown scheme")
+
+// Scheme is an algorithm whose leader censors.
+type Scheme string
+
+const (
+    Raft Scheme = "raft" // The Raft leader censors; followers campaign against it.
+    PBFT Scheme = "pbft" // The PBFT primary censors; backups change the view.
+    DPoS Scheme = "dpos" // One DPoS delegate censors in its slots; voters vote it out.
+)
+
+// Schemes returns every scheme Run can play.
+func Schemes() []Scheme {
+    return []Scheme{Raft, PBFT, DPoS}
+}
+
+// Defaults used by Run for the zero values of Config.
+const (
+    DefaultNodes    = 4
+    DefaultVictim   = "alice"
+    DefaultTimeout  = 2 * time.Second
+    DefaultDuration = time.Minute
+)
+
+// tick is the virtual time between two ticks of a node.
+const tick = 10 * time.Millisecond
+
+// interval is the time between two transactions. It is a little longer than a DPoS slot, so that the victim's
+// transactions fall in the slots of every delegate in turn.
+const interval = 600 * time.Millisecond
+
+// slot is the length of a DPoS slot.
+const slot = 500 * time.Millisecond
+
+// latency is the delay of every message, and grace the time after which voters expect a transaction to have
+// reached every delegate.
+const (
+    latency = 5 * time.Millisecond
+    grace   = 10 * latency
+)
+
+// victimEvery makes every fourth transaction the victim's; the others belong to another client.
+const victimEvery = 4
+
+// censor and observer are the nodes that censor and that results are read from: the first leader, and a node
+// that stays honest.
+const (
+    censor   int32 = 0
+    observer int32 = 1
+)
+
+// Config describes the attack.
+type Config struct {
+    Schemes  []Scheme      // Schemes to attack; every scheme of Schemes() if empty.
+    Nodes    int           // Replicas, or elected delegates, including the censor; DefaultNodes if 0.
+    Victim   string        // Client whose transactions are censored, as the prefix of their data; DefaultVictim if empty.
+    Timeout  time.Duration // Time a client waits for a transaction, and a replica for a request, before suspecting the leader; DefaultTimeout if 0.
+    Duration time.Duration // Virtual time each run lasts; transactions are submitted in its first three quarters. DefaultDuration if 0.
+    Seed     int64         // Seed of every run.
+}
+
+// Run plays the attack against every scheme in cfg, first without detection and then with it, and reports the
+// results scheme by scheme. It stops early with ctx's error once ctx is done.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+    if len(cfg.Schemes) == 0 {
+        cfg.Schemes = Schemes()
+    }
+    for _, scheme := range cfg.Schemes {
+        if _, ok := scenarios[scheme]; !ok {
+            return nil, fmt.Errorf("%w %q", ErrUnknownScheme, scheme)
+        }
+    }
+    if cfg.Nodes <= 0 {
+        cfg.Nodes = DefaultNodes
+    }
+    if cfg.Victim == "" {
+        cfg.Victim = DefaultVictim
+    }
+    if cfg.Timeout <= 0 {
+        cfg.Timeout = DefaultTimeout
+    }
+    if cfg.Duration <= 0 {
+        cfg.Duration = DefaultDuration
+    }
+
+    report := &Report{Config: cfg}
+    for _, scheme := range cfg.Schemes {
+        for _, detection := range []bool{false, true} {
+            res, err := play(ctx, cfg, scheme, detection)
+            if err != nil {
+                return nil, err
+            }
+            report.Results = append(report.Results, res)
+        }
+    }
+    return report, nil
+}
+
+// network is a simulated network whose node 0 censors the victim's transactions.
+type network struct {
+    cfg       Config
+    sim       *sim.Simulator
+    detection bool
+    peers     []int32
+    censored  func(data string) bool
+    submitted map[string]time.Duration // Time each transaction was submitted at.
+    deposed   func() bool              // Reports whether the censor has lost its role.
+}
+
+// scenario adds the nodes of one scheme to n, with node 0 censoring, and returns the client: the function that
+// submits a transaction, and resubmits it if it has not been included after the timeout.
+type scenario func(n *network) func(data string)
+
+// scenarios holds the scenario of every scheme.
+var scenarios = map[Scheme]scenario{
+    Raft: raftScenario,
+    PBFT: pbftScenario,
+    DPoS: dposScenario,
+}
+
+// play runs one scheme against a censor, with or without detection.
+func play(ctx context.Context, cfg Config, scheme Scheme, detection bool) (Result, error) {
+    n := &network{
+        cfg:       cfg,
+        sim:       sim.New(sim.Config{Seed: cfg.Seed, TickInterval: tick, Network: sim.Link{Latency: sim.Constant(latency)}}),
+        detection: detection,
+        censored:  func(data string) bool { return strings.HasPrefix(data, cfg.Victim+":") },
+        submitted: make(map[string]time.Duration),
+    }
+    for id := range int32(cfg.Nodes) {
+        n.peers = append(n.peers, id)
+    }
+    submit := scenarios[scheme](n)
+
+    res := Result{Scheme: scheme, Detection: detection}
+    for k, at := 0, time.Second; at < cfg.Duration*3/4; k, at = k+1, at+interval {
+        client := "bob"
+        if k%victimEvery == 0 {
+            client = cfg.Victim
+        }
+        data := fmt.Sprintf("%s: payment %d", client, k)
+        n.sim.At(at, func() {
+            n.submitted[data] = n.sim.Now()
+            submit(data)
+        })
+    }
+    if err := n.sim.RunForContext(ctx, cfg.Duration); err != nil {
+        return Result{}, err
+    }
+
+    for data := range n.submitted {
+        if n.censored(data) {
+            res.Censored++
+        } else {
+            res.Others++
+        }
+    }
+    for _, block := range n.sim.Replica(observer).Committed()[1:] {
+        at, ok := n.submitted[block.GetData()]
+        if !ok {
+            continue // An empty block, or one already counted.
+        }
+        delete(n.submitted, block.GetData())
+        if !n.censored(block.GetData()) {
+            res.Served++
+            continue
+        }
+        res.Included++
+        res.Delay = max(res.Delay, time.Unix(0, block.GetTimestamp()).Sub(sim.Epoch.Add(at)))
+    }
+    res.Deposed = n.deposed()
+    return res, nil
+}
+
+// included reports whether the observer has committed a block carrying data.
+func (n *network) included(data string) bool {
+    for _, block := range n.sim.Replica(observer).Committed() {
+        if block.GetData() == data {
+            return true
+        }
+    }
+    return false
+}
+
+// leader returns the leader the observer follows, or the censor while it knows none.
+func (n *network) leader() int32 {
+    if l := n.sim.Replica(observer).(node.Leaderful).Leader(); l >= 0 {
+        return l
+    }
+    return censor
+}
+
+// client returns the client of a leader-based scheme: it submits a transaction to the leader the observer
+// follows and, with detection, to every replica if the transaction is not included after the timeout.
+func (n *network) client() func(data string) {
+    return func(data string) {
+        n.sim.Propose(n.leader(), data)
+        if !n.detection {
+            return
+        }
+        n.sim.At(n.sim.Now()+n.cfg.Timeout, func() {
+            if n.included(data) {
+                return
+            }
+            for _, id := range n.peers {
+                n.sim.Propose(id, data)
+            }
+        })
+    }
+}
+
+// timeout returns the configured timeout in ticks.
+func (n *network) timeout() int {
+    return int(n.cfg.Timeout / tick)
+}
+
+// raftScenario makes the censor the first leader by giving it the shortest election timeout. With detection,
+// followers watch the proposals a client gives them and campaign when one does not commit in time.
+func raftScenario(n *network) func(data string) {
+    for _, id := range n.peers {
+        cfg := raft.ReplicaConfig{ID: id, Peers: n.peers, ElectionTicks: 30, Rand: n.sim.NewRand(), Clock: n.sim.Clock()}
+        if id == censor {
+            cfg.ElectionTicks = 5
+        }
+        if n.detection {
+            cfg.RequestTimeout = n.timeout()
+        }
+        n.add(id, raft.NewReplica(cfg))
+    }
+    n.deposed = func() bool { return n.leader() != censor }
+    return n.client()
+}
+
+// pbftScenario makes the censor the primary of the first view. Backups forward a request a client gives them
+// and change the view when it is not executed in time.
+func pbftScenario(n *network) func(data string) {
+    for _, id := range n.peers {
+        n.add(id, pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: n.peers, ViewChangeTicks: n.timeout(), Clock: n.sim.Clock()}))
+    }
+    n.deposed = func() bool { return n.leader() != censor }
+    return n.client()
+}
+
+// dposScenario elects every node but one standby, which follows the chain until it replaces a delegate. Clients
+// submit to every node, as a transaction is gossiped. With detection, the voters audit the chain at the end of
+// each epoch and vote out the delegates whose blocks left out a transaction waiting since before their slot.
+func dposScenario(n *network) func(data string) {
+    standby := int32(len(n.peers))
+    elected := n.peers
+    n.peers = append(n.peers[:len(n.peers):len(n.peers)], standby)
+    delegates := make([]*dpos.Delegate, 0, len(n.peers))
+    for _, id := range n.peers {
+        d := dpos.NewDelegate(dpos.DelegateConfig{ID: id, Peers: n.peers, Elected: elected, SlotTicks: int(slot / tick), Confirmations: 2, Clock: n.sim.Clock()})
+        delegates = append(delegates, d)
+        n.add(id, d)
+    }
+    n.deposed = func() bool { return !slices.Contains(delegates[observer].Delegates(), censor) }
+
+    if n.detection {
+        epoch := time.Duration(len(elected)) * slot
+        var audit func()
+        audit = func() {
+            current := delegates[observer].Delegates()
+            for _, culprit := range n.culprits(delegates[observer].Chain()) {
+                if slices.Contains(current, culprit) && !slices.Contains(current, standby) {
+                    current = append(slices.DeleteFunc(current, func(id int32) bool { return id == culprit }), standby)
+                    for _, d := range delegates {
+                        d.Elect(current)
+                    }
+                }
+            }
+            n.sim.At(n.sim.Now()+epoch, audit)
+        }
+        n.sim.At(epoch, audit)
+    }
+    return func(data string) {
+        for _, id := range n.peers {
+            n.sim.Propose(id, data)
+        }
+    }
+}
+
+// culprits returns the producers of the blocks in chain that carry something other than the oldest transaction
+// not yet included, although it was submitted long enough before the block to have reached every delegate: only
+// a delegate that chose to leave it out would have.
+func (n *network) culprits(chain []*wire.Block) []int32 {
+    var culprits []int32
+    included := make(map[string]bool)
+    for _, block := range chain[1:] {
+        at := time.Unix(0, block.GetTimestamp()).Sub(sim.Epoch)
+        oldest, since := "", at-grace
+        for data, submitted := range n.submitted {
+            if !included[data] && submitted <= since {
+                oldest, since = data, submitted
+            }
+        }
+        if oldest != "" && block.GetData() != oldest {
+            for _, id := range n.peers {
+                if node.Name(id) == block.GetProducer() && !slices.Contains(culprits, id) {
+                    culprits = append(culprits, id)
+                }
+            }
+        }
+        included[block.GetData()] = true
+    }
+    return culprits
+}
+
+// add adds a replica to the simulation, wrapped in a Censor if it is the censor.
+func (n *network) add(id int32, replica node.Replica) {
+    if id == censor {
+        replica = adversary.NewCensor(replica, n.censored)
+    }
+    n.sim.Add(replica)
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **A Censor Breaks No Rule**: adversary.Censor drops what it is given and stays honest in everything it
+//    sends, so no check of a replica, no quorum and no certificate can catch it. What detects it must start at the
+//    victim's client, which is why every mechanism here begins with a timeout outside the replicas.
+//
+// 2. **Detection the Algorithms Already Have**: PBFT's view change and DPoS's vote are the algorithms' own ways of
+//    replacing a leader, and Raft's election is triggered the way a lost leader triggers it. Only the trigger is
+//    new: a Raft follower's RequestTimeout, and PBFT's timer restarting on the oldest request alone, without which
+//    a primary serving the other clients would keep its backups from ever suspecting it.
+//
+// 3. **Voters Read the Chain**: The DPoS audit decides from the observer's chain and the submission times, which
+//    any voter can see, and leaves a slot of grace for a transaction to reach every delegate. An honest delegate
+//    always produces the oldest transaction it has, so it is never voted out on a network whose latency stays
+//    within the grace.
+//
+// 4. **One Observer**: Results are read from honest node 1, and inclusion is measured to the timestamp of the
+//    block carrying a transaction, so the delay counts the censorship and the detection, not the confirmations
+//    that follow.
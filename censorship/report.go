@@ -0,0 +1,86 @@
+package censorship
+
+import (
+    "fmt"
+    "io"
+    "text/tabwriter"
+    "time"
+)
+
+// Report is the outcome of an attack.
+type Report struct {
+    Config  Config   // The attack, with defaults filled in.
+    Results []Result // One result per scheme, without detection and then with it.
+}
+
+// Result is what the censor achieved against one scheme.
+type Result struct {
+    Scheme    Scheme
+    Detection bool          // Whether clients resubmit to every node, and replicas or voters act on it.
+    Censored  int           // Transactions of the victim submitted.
+    Included  int           // Of those, transactions the observer committed.
+    Others    int           // Transactions of other clients submitted.
+    Served    int           // Of those, transactions the observer committed.
+    Delay     time.Duration // Longest time from submitting a transaction of the victim to the block including it.
+    Deposed   bool          // Whether the censor had lost its role by the end: leader, primary or delegate.
+}
+
+// Resists reports whether every transaction of the victim was included.
+func (r Result) Resists() bool {
+    return r.Included == r.Censored
+}
+
+// outcome describes what happened to the victim's transactions in a few words.
+func (r Result) outcome() string {
+    if !r.Resists() {
+        return "censored"
+    }
+    return fmt.Sprintf("delayed by up to %v", r.Delay.Round(10*time.Millisecond))
+}
+
+// Print writes the report as a table with a row per scheme and detection setting, followed by a verdict on each
+// scheme.
+func (r *Report) Print(w io.Writer) error {
+    fmt.Fprintf(w, "%d nodes, node 0 censors %s, client timeout %v, %v per run, seed %d\n\n", r.Config.Nodes, r.Config.Victim, r.Config.Timeout, r.Config.Duration, r.Config.Seed)
+    tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintf(tw, "scheme\tdetection\t%s included\tworst delay\tothers included\tcensor deposed\t\n", r.Config.Victim)
+    for _, res := range r.Results {
+        fmt.Fprintf(tw, "%s\t%s\t%d/%d\t%v\t%d/%d\t%s\t\n", res.Scheme, onOff(res.Detection), res.Included, res.Censored, res.Delay.Round(10*time.Millisecond), res.Served, res.Others, yesNo(res.Deposed))
+    }
+    if err := tw.Flush(); err != nil {
+        return err
+    }
+    fmt.Fprintln(w)
+    for _, scheme := range r.Config.Schemes {
+        var without, with *Result
+        for i := range r.Results {
+            if res := &r.Results[i]; res.Scheme == scheme && res.Detection {
+                with = res
+            } else if res.Scheme == scheme {
+                without = res
+            }
+        }
+        deposed := ""
+        if with.Deposed {
+            deposed = ", and the censor deposed"
+        }
+        fmt.Fprintf(w, "%s: %s without detection, %s with it%s\n", scheme, without.outcome(), with.outcome(), deposed)
+    }
+    return nil
+}
+
+// onOff writes a setting.
+func onOff(b bool) string {
+    if b {
+        return "on"
+    }
+    return "off"
+}
+
+// yesNo writes a fact.
+func yesNo(b bool) string {
+    if b {
+        return "yes"
+    }
+    return "no"
+}
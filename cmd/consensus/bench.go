@@ -0,0 +1,52 @@
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+    "text/tabwriter"
+    "time"
+
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/stats"
+)
+
+// benchCommand implements "consensus bench".
+func benchCommand(ctx context.Context, args []string) error {
+    flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+    algo := flags.String("algo", "all", "algorithm to benchmark, or all")
+    nodes := flags.Int("nodes", 4, "number of nodes, validators or delegates")
+    blocks := flags.Int("blocks", 100, "number of blocks to commit per algorithm")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+
+    algorithms := []string{*algo}
+    if *algo == "all" {
+        algorithms = engine.Algorithms()
+    }
+
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintln(w, "algorithm\tnodes\tblocks\ttotal\tblocks/s\tp50\tp90\tp99\tmax\t")
+    for _, name := range algorithms {
+        e, err := engine.New(name, engine.Config{Nodes: *nodes})
+        if err != nil {
+            return err
+        }
+        recorder := stats.NewRecorder()
+        e = engine.Measure(e, recorder)
+
+        for i := 1; i <= *blocks; i++ {
+            if err := e.Submit(ctx, fmt.Sprintf("Block %d data", i)); err != nil {
+                return fmt.Errorf("%s: block %d: %w", name, i, err)
+            }
+        }
+
+        summary := recorder.Summary()
+        latency := summary.Latency
+        fmt.Fprintf(w, "%s\t%d\t%d\t%v\t%.0f\t%v\t%v\t%v\t%v\t\n", name, e.Status().Nodes, summary.Blocks, summary.Elapsed.Round(time.Microsecond), summary.Throughput,
+            latency.Percentile(50).Round(time.Microsecond), latency.Percentile(90).Round(time.Microsecond), latency.Percentile(99).Round(time.Microsecond), latency.Max.Round(time.Microsecond))
+    }
+    return w.Flush()
+}
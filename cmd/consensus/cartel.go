@@ -0,0 +1,47 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+
+    "consensus-algorithms-edu/cartel"
+)
+
+// cartelCommand implements "consensus cartel".
+func cartelCommand(ctx context.Context, args []string) error {
+    flags := flag.NewFlagSet("cartel", flag.ContinueOnError)
+    turnout := flags.String("turnout", "0.1,0.25,0.5,0.9", "shares of the honest voters who vote, separated by commas, one run each")
+    seats := flags.Int("seats", cartel.DefaultSeats, "size of the active set of producers")
+    members := flags.Int("members", cartel.DefaultMembers, "candidates in the cartel")
+    honest := flags.Int("honest", cartel.DefaultHonest, "honest candidates")
+    stake := flags.Float64("stake", cartel.DefaultStake, "cartel's own share of all stake, between 0 and 1")
+    budget := flags.Int("budget", cartel.DefaultBudget, "bribes paid out of the briber's pocket per round")
+    kickback := flags.Float64("kickback", cartel.DefaultKickback, "share of the cartel's block rewards spent on bribes, between 0 and 1")
+    rounds := flags.Int("rounds", cartel.DefaultRounds, "elections each run lasts")
+    seed := flags.Int64("seed", 1, "seed of every run; the same seed draws the same voters")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if flags.NArg() != 0 {
+        return errors.New("usage: consensus cartel [-turnout=F,G] [-seats=N] [-members=N] [-honest=N] [-stake=F] [-budget=N] [-kickback=F] [-rounds=N] [-seed=N]")
+    }
+
+    cfg := cartel.Config{Seats: *seats, Members: *members, Honest: *honest, Stake: *stake, Budget: *budget, Kickback: *kickback, Rounds: *rounds, Seed: *seed}
+    for _, field := range strings.Split(*turnout, ",") {
+        p, err := strconv.ParseFloat(field, 64)
+        if err != nil || p < 0 || p > 1 {
+            return fmt.Errorf("invalid turnout %q", field)
+        }
+        cfg.Participation = append(cfg.Participation, p)
+    }
+    report, err := cartel.Run(ctx, cfg)
+    if err != nil {
+        return err
+    }
+    return report.Print(os.Stdout)
+}
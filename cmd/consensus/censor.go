@@ -0,0 +1,40 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "os"
+    "strings"
+
+    "consensus-algorithms-edu/censorship"
+)
+
+// censorCommand implements "consensus censor".
+func censorCommand(ctx context.Context, args []string) error {
+    flags := flag.NewFlagSet("censor", flag.ContinueOnError)
+    schemes := flags.String("scheme", "all", "algorithms whose leader censors, separated by commas, or all: raft, pbft, dpos")
+    nodes := flags.Int("nodes", censorship.DefaultNodes, "replicas, or elected delegates, including the censor")
+    victim := flags.String("victim", censorship.DefaultVictim, "client whose transactions are censored")
+    timeout := flags.Duration("timeout", censorship.DefaultTimeout, "time a client or replica waits for a transaction before suspecting the leader")
+    duration := flags.Duration("duration", censorship.DefaultDuration, "virtual time each run lasts")
+    seed := flags.Int64("seed", 1, "seed of every run; the same seed plays out the same attack")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if flags.NArg() != 0 {
+        return errors.New("usage: consensus censor [-scheme=A,B] [-nodes=N] [-victim=NAME] [-timeout=D] [-duration=D] [-seed=N]")
+    }
+
+    cfg := censorship.Config{Nodes: *nodes, Victim: *victim, Timeout: *timeout, Duration: *duration, Seed: *seed}
+    if *schemes != "all" {
+        for _, name := range strings.Split(*schemes, ",") {
+            cfg.Schemes = append(cfg.Schemes, censorship.Scheme(name))
+        }
+    }
+    report, err := censorship.Run(ctx, cfg)
+    if err != nil {
+        return err
+    }
+    return report.Print(os.Stdout)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "maps"
+    "net/http"
+    "os"
+    "slices"
+    "strings"
+    "time"
+
+    "consensus-algorithms-edu/compare"
+    "consensus-algorithms-edu/profile"
+    "consensus-algorithms-edu/sim"
+)
+
+// comparePresets are the named flag sets of "consensus compare -preset", by name. Flags given on the command line
+// take precedence over the preset's.
+var comparePresets = map[string]map[string]string{
+    // workload is the comparison of examples/comparison: 100 transactions through every algorithm, with costs.
+    "workload": {"algo": "all", "blocks": "100", "interval": "100ms", "cost": "true"},
+}
+
+// compareCommand implements "consensus compare".
+func compareCommand(ctx context.Context, args []string) error {
+    flags := flag.NewFlagSet("compare", flag.ContinueOnError)
+    algo := flags.String("algo", "all", "algorithms to compare, separated by commas, or all: "+strings.Join(compare.Algorithms(), ", "))
+    nodes := flags.Int("nodes", 4, "number of replicas in each cluster")
+    seed := flags.Int64("seed", 1, "seed of every cluster; the same seed plays out the same comparison")
+    blocks := flags.Int("blocks", 5, "number of transactions to submit, unless the script submits its own")
+    interval := flags.Duration("interval", 500*time.Millisecond, "virtual time between transactions")
+    script := flags.String("script", "", "play the events in this file, one per line, e.g. \"2s partition 0,1 | 2,3\"")
+    latency := flags.Duration("latency", 5*time.Millisecond, "mean message latency; each message takes between half and one and a half times as long")
+    drop := flags.Float64("drop", 0, "fraction of messages lost throughout, between 0 and 1")
+    settle := flags.Duration("settle", compare.DefaultSettle, "virtual time the clusters run after the last event")
+    fanout := flags.Int("gossip", 0, "spread blocks and votes by gossip, each node forwarding to this many peers, instead of sending them directly")
+    validate := flags.Duration("validate", 0, "time a node takes to check a gossiped message before forwarding it")
+    maxPeers := flags.Int("peers", 0, "have replicas discover each other from replica 0 and keep at most this many peers, instead of a full mesh")
+    costs := flags.Bool("cost", false, "count the hashes, messages and signatures each algorithm spends per finalized block, playing the clusters one at a time")
+    phases := flags.Bool("profile", false, "print the real time and memory spent simulating, handling messages, hashing and applying state")
+    pprofAddr := flags.String("pprof", "", "serve pprof profiles and the phase report on this address, e.g. localhost:6060, while the comparison runs")
+    preset := flags.String("preset", "", "start from a named set of flags, which flags given explicitly override: "+strings.Join(slices.Sorted(maps.Keys(comparePresets)), ", "))
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if *preset != "" {
+        values, ok := comparePresets[*preset]
+        if !ok {
+            return fmt.Errorf("unknown preset %q", *preset)
+        }
+        for name, value := range values {
+            if !isSet(flags, name) {
+                flags.Set(name, value)
+            }
+        }
+    }
+    if flags.NArg() != 0 {
+        return errors.New("usage: consensus compare [-preset=NAME] [-algo=A,B] [-nodes=N] [-seed=N] [-blocks=N] [-script=FILE]")
+    }
+
+    var algorithms []string
+    if *algo != "all" {
+        algorithms = strings.Split(*algo, ",")
+    }
+    events := compare.Transactions(*blocks, *interval)
+    if *script != "" {
+        f, err := os.Open(*script)
+        if err != nil {
+            return err
+        }
+        defer f.Close()
+        parsed, err := compare.ParseScript(f)
+        if err != nil {
+            return fmt.Errorf("%s: %w", *script, err)
+        }
+        // A script of faults alone is played against the -blocks transactions; one that submits its own is played as written.
+        if slices.ContainsFunc(parsed, func(e compare.Event) bool { return e.Kind == compare.Submit }) {
+            events = parsed
+        } else {
+            events = events.With(parsed...)
+        }
+    }
+
+    var profiler *profile.Profiler
+    if *phases || *pprofAddr != "" {
+        profiler = profile.New()
+        if err := profile.Start(profiler); err != nil {
+            return err
+        }
+        defer profile.Stop()
+    }
+    if *pprofAddr != "" {
+        server := &http.Server{Addr: *pprofAddr, Handler: profile.Handler()}
+        go server.ListenAndServe()
+        defer server.Close()
+        fmt.Fprintf(os.Stderr, "Serving pprof on http://%s/debug/pprof/ and phases on http://%s/debug/phases\n", *pprofAddr, *pprofAddr)
+    }
+
+    var gossip *sim.Gossip
+    if *fanout > 0 {
+        gossip = &sim.Gossip{Fanout: *fanout, Validate: sim.Constant(*validate)}
+    }
+    var discovery *sim.Discovery
+    if *maxPeers > 0 {
+        discovery = &sim.Discovery{MaxPeers: *maxPeers}
+    }
+    report, err := compare.Run(ctx, events, compare.Config{
+        Algorithms: algorithms,
+        Nodes:      *nodes,
+        Seed:       *seed,
+        Network:    sim.Link{Latency: sim.Uniform(*latency/2, *latency*3/2), Drop: *drop},
+        Gossip:     gossip,
+        Discovery:  discovery,
+        Settle:     *settle,
+        Cost:       *costs,
+    })
+    if err != nil {
+        return err
+    }
+    if err := report.Print(os.Stdout); err != nil {
+        return err
+    }
+    if *costs {
+        fmt.Println()
+        if err := report.PrintCost(os.Stdout); err != nil {
+            return err
+        }
+    }
+    if !*phases {
+        return nil
+    }
+    fmt.Println()
+    return profiler.Report().Print(os.Stdout)
+}
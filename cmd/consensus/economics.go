@@ -0,0 +1,81 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+
+    "consensus-algorithms-edu/economics"
+)
+
+// economicsCommand implements "consensus economics".
+func economicsCommand(ctx context.Context, args []string) error {
+    flags := flag.NewFlagSet("economics", flag.ContinueOnError)
+    schemes := flags.String("scheme", "all", "schemes to play, separated by commas, or all: pos, dpos")
+    validators := flags.Int("validators", economics.DefaultValidators, "number of validators")
+    delegators := flags.Int("delegators", economics.DefaultDelegators, "number of holders staking through a validator")
+    seats := flags.Int("seats", economics.DefaultSeats, "size of the DPoS active set")
+    blocks := flags.Int("blocks", economics.DefaultBlocks, "blocks each run lasts")
+    every := flags.Int("every", economics.DefaultEvery, "blocks between two samples written to the CSV")
+    selfStake := flags.Float64("self", economics.DefaultSelfStake, "validators' own share of all stake at the start, between 0 and 1")
+    rewards := flags.String("reward", "0.0001", "rewards per block as shares of the stake at the start, separated by commas, one run each")
+    commissions := flags.String("commission", "0.05,0.25", "shares of each reward its producer keeps, separated by commas, one run each")
+    seed := flags.Int64("seed", 1, "seed of every run; the same seed draws the same holders")
+    out := flags.String("csv", "", "write every sample as CSV to this file, or to standard output instead of the table if -")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if flags.NArg() != 0 {
+        return errors.New("usage: consensus economics [-scheme=A,B] [-validators=N] [-delegators=N] [-seats=N] [-blocks=N] [-every=N] [-self=F] [-reward=F,G] [-commission=F,G] [-seed=N] [-csv=FILE]")
+    }
+
+    cfg := economics.Config{Validators: *validators, Delegators: *delegators, Seats: *seats, Blocks: *blocks, Every: *every, SelfStake: *selfStake, Seed: *seed}
+    if *schemes != "all" {
+        for _, name := range strings.Split(*schemes, ",") {
+            cfg.Schemes = append(cfg.Schemes, economics.Scheme(name))
+        }
+    }
+    var err error
+    if cfg.Rewards, err = shares(*rewards, "reward"); err != nil {
+        return err
+    }
+    if cfg.Commissions, err = shares(*commissions, "commission"); err != nil {
+        return err
+    }
+    report, err := economics.Run(ctx, cfg)
+    if err != nil {
+        return err
+    }
+    switch *out {
+    case "":
+        return report.Print(os.Stdout)
+    case "-":
+        return report.WriteCSV(os.Stdout)
+    }
+    f, err := os.Create(*out)
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+    if err := report.WriteCSV(f); err != nil {
+        return err
+    }
+    return report.Print(os.Stdout)
+}
+
+// shares parses a list of shares between 0 and 1 separated by commas.
+func shares(list, what string) ([]float64, error) {
+    var parsed []float64
+    for _, field := range strings.Split(list, ",") {
+        f, err := strconv.ParseFloat(field, 64)
+        if err != nil || f < 0 || f > 1 {
+            return nil, fmt.Errorf("invalid %s %q", what, field)
+        }
+        parsed = append(parsed, f)
+    }
+    return parsed, nil
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "maps"
+    "os"
+    "slices"
+    "strconv"
+    "strings"
+    "text/tabwriter"
+
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/wire"
+)
+
+// exploreCommand implements "consensus explore FILE [BLOCK]".
+func exploreCommand(_ context.Context, args []string) error {
+    flags := flag.NewFlagSet("explore", flag.ContinueOnError)
+    from := flags.Int("from", 0, "list blocks from this height")
+    to := flags.Int("to", -1, "list blocks up to this height; the head if negative")
+    verify := flags.Bool("verify", false, "only verify every block's hash and link, and print the blocks that fail")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if flags.NArg() < 1 || flags.NArg() > 2 {
+        return errors.New("usage: consensus explore [-from=N] [-to=N] [-verify] FILE [HEIGHT|HASH]")
+    }
+    chain, err := loadChain(flags.Arg(0))
+    if err != nil {
+        return err
+    }
+    x := &explorer{algorithm: chain.GetAlgorithm(), blocks: chain.GetBlocks()}
+
+    switch {
+    case *verify:
+        return x.verifyAll()
+    case flags.NArg() == 2:
+        block, err := x.find(flags.Arg(1))
+        if err != nil {
+            return err
+        }
+        x.show(block)
+        return nil
+    default:
+        if *to < 0 || *to >= len(x.blocks) {
+            *to = len(x.blocks) - 1
+        }
+        return x.list(max(*from, 0), *to)
+    }
+}
+
+// loadChain reads a chain saved with "run -out", or the chain of a snapshot written with "run -export".
+func loadChain(path string) (*wire.Chain, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    chain, err := wire.DecodeChainJSON(data)
+    if err == nil {
+        return chain, nil
+    }
+    snapshot, snapshotErr := wire.DecodeSnapshotJSON(data)
+    if snapshotErr != nil {
+        return nil, fmt.Errorf("%s is neither a saved chain (%v) nor a snapshot (%v)", path, err, snapshotErr)
+    }
+    return snapshot.GetChain(), nil
+}
+
+// explorer answers questions about one chain.
+type explorer struct {
+    algorithm string
+    blocks    []*wire.Block
+}
+
+// problem returns what is wrong with the block at height i on its own and in relation to its predecessor, or
+// "" if its hash and link verify.
+func (x *explorer) problem(i int) string {
+    block := x.blocks[i]
+    hash, err := engine.Hash(x.algorithm, block)
+    switch {
+    case err != nil:
+        return err.Error()
+    case block.GetIndex() != int64(i):
+        return fmt.Sprintf("stored at height %d but says %d", i, block.GetIndex())
+    case hash != block.GetHash():
+        return fmt.Sprintf("hash does not match its contents, which hash to %.16s", hash)
+    case i > 0 && block.GetPrevHash() != x.blocks[i-1].GetHash():
+        return "does not link to the block before it"
+    }
+    return ""
+}
+
+// list prints the blocks between heights from and to, one per line.
+func (x *explorer) list(from, to int) error {
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(w, "height\thash\tproducer\tcheck\tdata")
+    bad := 0
+    for i := from; i <= to; i++ {
+        block := x.blocks[i]
+        check := "ok"
+        if x.problem(i) != "" {
+            check, bad = "FAILED", bad+1
+        }
+        fmt.Fprintf(w, "%d\t%.16s\t%s\t%s\t%s\n", i, block.GetHash(), block.GetProducer(), check, summarize(block))
+    }
+    if err := w.Flush(); err != nil {
+        return err
+    }
+    fmt.Printf("%s: %d blocks, showing %d to %d", x.algorithm, len(x.blocks), from, to)
+    if bad > 0 {
+        fmt.Printf(", %d failed verification (see -verify)\n", bad)
+    } else {
+        fmt.Println()
+    }
+    return nil
+}
+
+// find returns the block at a height, or the one whose hash is or starts with key.
+func (x *explorer) find(key string) (int, error) {
+    if height, err := strconv.Atoi(key); err == nil {
+        if height < 0 || height >= len(x.blocks) {
+            return 0, fmt.Errorf("no block at height %d: the chain has %d blocks", height, len(x.blocks))
+        }
+        return height, nil
+    }
+    var matches []int
+    for i, block := range x.blocks {
+        if strings.HasPrefix(block.GetHash(), key) {
+            matches = append(matches, i)
+        }
+    }
+    switch len(matches) {
+    case 0:
+        return 0, fmt.Errorf("no block hash starts with %q", key)
+    case 1:
+        return matches[0], nil
+    default:
+        return 0, fmt.Errorf("%d block hashes start with %q; give more of the hash", len(matches), key)
+    }
+}
+
+// show prints every field of the block at height i, its decoded data and whether it verifies.
+func (x *explorer) show(i int) {
+    block := x.blocks[i]
+    fmt.Printf("block %d of %d (%s)\n", i, len(x.blocks)-1, x.algorithm)
+    fmt.Printf("  hash         %s\n", block.GetHash())
+    if i > 0 {
+        fmt.Printf("  previous     %s\n", block.GetPrevHash())
+    }
+    if i+1 < len(x.blocks) {
+        fmt.Printf("  next         %s\n", x.blocks[i+1].GetHash())
+    }
+    fmt.Printf("  timestamp    %s\n", wire.Timestamp(block.GetTimestamp()))
+    if block.GetProducer() != "" {
+        fmt.Printf("  producer     %s\n", block.GetProducer())
+    }
+    if x.algorithm == "pow" {
+        fmt.Printf("  nonce        %d\n", block.GetNonce())
+        fmt.Printf("  difficulty   %d\n", block.GetDifficulty())
+    }
+    if len(block.GetCertificate()) > 0 {
+        fmt.Printf("  certificate  %s\n", strings.Join(block.GetCertificate(), ", "))
+    }
+    fmt.Printf("  data         %q\n", block.GetData())
+    kind, fields := decode(block)
+    fmt.Printf("  decoded      %s\n", kind)
+    for _, field := range fields {
+        fmt.Printf("               %s\n", field)
+    }
+    if problem := x.problem(i); problem != "" {
+        fmt.Printf("  check        FAILED: %s\n", problem)
+    } else {
+        fmt.Println("  check        ok: the hash matches the contents and the block links to its predecessor")
+    }
+}
+
+// verifyAll checks every block's hash and link, and the algorithm's own rules, printing every block that fails.
+func (x *explorer) verifyAll() error {
+    bad := 0
+    for i := range x.blocks {
+        if problem := x.problem(i); problem != "" {
+            fmt.Printf("#%-4d %.16s  %s\n", i, x.blocks[i].GetHash(), problem)
+            bad++
+        }
+    }
+    if bad > 0 {
+        return fmt.Errorf("%d of %d blocks failed verification", bad, len(x.blocks))
+    }
+    // Saved chains do not record the participants, so producers and certificates are checked for presence only.
+    if err := engine.Validate(x.algorithm, x.blocks, nil); err != nil {
+        return fmt.Errorf("verification failed: %w", err)
+    }
+    fmt.Printf("%s: all %d blocks verify (%s)\n", x.algorithm, len(x.blocks), engine.Checks(x.algorithm))
+    return nil
+}
+
+// decode interprets a block's data as one of the kinds of transactions this repository writes, returning the
+// kind and, for structured data, its fields: membership changes, JSON commands such as those of the key-value
+// store example, and plain text.
+func decode(block *wire.Block) (kind string, fields []string) {
+    data := block.GetData()
+    if block.GetIndex() == 0 {
+        return "genesis block", nil
+    }
+    if change, ok := strings.CutPrefix(data, "config: "); ok {
+        return "membership change", []string{change}
+    }
+    var object map[string]any
+    if json.Unmarshal([]byte(data), &object) == nil {
+        for _, key := range slices.Sorted(maps.Keys(object)) {
+            fields = append(fields, fmt.Sprintf("%s=%v", key, object[key]))
+        }
+        return "JSON object", fields
+    }
+    if data == "" {
+        return "empty, no transaction", nil
+    }
+    return fmt.Sprintf("text, %d bytes", len(data)), nil
+}
+
+// summarize describes a block's data in a few words for the block list.
+func summarize(block *wire.Block) string {
+    kind, fields := decode(block)
+    switch {
+    case kind == "JSON object":
+        return "{" + strings.Join(fields, " ") + "}"
+    case len(fields) > 0:
+        return kind + ": " + strings.Join(fields, " ")
+    case block.GetIndex() > 0 && block.GetData() == "":
+        return "(empty)"
+    }
+    data := block.GetData()
+    if len(data) > 48 {
+        data = data[:45] + "..."
+    }
+    return strconv.Quote(data)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+
+    "consensus-algorithms-edu/exercises"
+    "consensus-algorithms-edu/exercises/student"
+)
+
+// gradeCommand implements "consensus grade".
+func gradeCommand(_ context.Context, args []string) error {
+    flags := flag.NewFlagSet("grade", flag.ContinueOnError)
+    exercise := flags.String("exercise", "", "grade only these exercises, separated by commas")
+    list := flags.Bool("list", false, "list the exercises and the files to complete instead of grading")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if flags.NArg() != 0 {
+        return errors.New("usage: consensus grade [-exercise=NAME,...] [-list]")
+    }
+    if *list {
+        for _, e := range exercises.Exercises {
+            fmt.Printf("%-16s %-48s  %s\n", e.Name, e.Title, e.File)
+        }
+        return nil
+    }
+
+    var only []string
+    if *exercise != "" {
+        only = strings.Split(*exercise, ",")
+    }
+    report, err := exercises.Grade(student.Solution(), only...)
+    if err != nil {
+        return err
+    }
+    return report.Print(os.Stdout)
+}
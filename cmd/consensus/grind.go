@@ -0,0 +1,50 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+
+    "consensus-algorithms-edu/grinding"
+)
+
+// grindCommand implements "consensus grind".
+func grindCommand(ctx context.Context, args []string) error {
+    flags := flag.NewFlagSet("grind", flag.ContinueOnError)
+    schemes := flags.String("scheme", "all", "sources of randomness to attack, separated by commas, or all: slot, chain, beacon")
+    honest := flags.Int("honest", grinding.DefaultHonest, "number of honest validators")
+    share := flags.Float64("share", grinding.DefaultShare, "attacker's share of all stake, between 0 and 1")
+    attempts := flags.String("attempts", "1,10,100", "numbers of candidates the attacker tries for each choice, separated by commas")
+    slots := flags.Int("slots", grinding.DefaultSlots, "slots each run lasts")
+    epoch := flags.Int("epoch", grinding.DefaultEpoch, "slots between two registrations of stake and two beacon outputs")
+    seed := flags.Int64("seed", 1, "seed of every run; the same seed plays out the same attack")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if flags.NArg() != 0 {
+        return errors.New("usage: consensus grind [-scheme=A,B] [-honest=N] [-share=F] [-attempts=N,M] [-slots=N] [-epoch=N] [-seed=N]")
+    }
+
+    cfg := grinding.Config{Honest: *honest, Share: *share, Slots: *slots, Epoch: *epoch, Seed: *seed}
+    if *schemes != "all" {
+        for _, name := range strings.Split(*schemes, ",") {
+            cfg.Schemes = append(cfg.Schemes, grinding.Scheme(name))
+        }
+    }
+    for _, field := range strings.Split(*attempts, ",") {
+        n, err := strconv.Atoi(field)
+        if err != nil || n <= 0 {
+            return fmt.Errorf("invalid number of attempts %q", field)
+        }
+        cfg.Attempts = append(cfg.Attempts, n)
+    }
+    report, err := grinding.Run(ctx, cfg)
+    if err != nil {
+        return err
+    }
+    return report.Print(os.Stdout)
+}
@@ -0,0 +1,132 @@
+// Package main implements the consensus command-line tool.
+// It runs simulations of any algorithm in this repository, inspects and draws chains saved to disk, and
+// benchmarks the algorithms against each other, so experiments no longer require writing a Go program for each one. Every
+// subcommand is built on the engine package, which gives all six algorithms the same interface.
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+    "os/signal"
+    "path/filepath"
+    "strings"
+
+    "consensus-algorithms-edu/storage"
+)
+
+// command is one subcommand of the tool.
+type command struct {
+    name    string                                         // Name typed on the command line.
+    summary string                                         // One-line description shown in the usage message.
+    run     func(ctx context.Context, args []string) error // Parses the subcommand's flags and runs it until ctx is done.
+}
+
+// commands lists every subcommand in the order they are shown in the usage message.
+var commands = []command{
+    {"run", "run a simulation and print or save the resulting chain", runCommand},
+    {"inspect", "print and verify a chain saved with run --out", inspectCommand},
+    {"bench", "measure how fast each algorithm commits blocks", benchCommand},
+    {"viz", "draw saved chains, including forks between them, as SVG or DOT", vizCommand},
+    {"trace", "record every message and state transition of a simulation to a file", traceCommand},
+    {"replay", "step forward and backward through a recorded trace", replayCommand},
+    {"step", "run a simulation one message at a time from the keyboard", stepCommand},
+    {"tui", "watch a simulation live in the terminal: roles, terms, logs and messages of every node", tuiCommand},
+    {"shell", "poke a live cluster with typed commands: propose, crash 2, partition 0,1 / 2,3,4, step, show chain 3", shellCommand},
+    {"compare", "play the same transactions and faults against several algorithms and compare them", compareCommand},
+    {"scenario", "play simulations described in YAML files: algorithm, cluster, workload and a timeline of faults", scenarioCommand},
+    {"sybil", "flood several algorithms with attacker identities and measure what the attacker gains", sybilCommand},
+    {"grind", "bias the Proof of Stake proposer draw by grinding its inputs, under several sources of randomness", grindCommand},
+    {"censor", "let the leader of several algorithms censor one client, and measure whether detection deposes it", censorCommand},
+    {"cartel", "let a DPoS cartel buy votes to capture the active set, at several rates of voter turnout", cartelCommand},
+    {"economics", "play thousands of PoS and DPoS blocks and track how rewards and commissions redistribute stake", economicsCommand},
+    {"explore", "browse, decode and verify the blocks of a saved chain or snapshot", exploreCommand},
+    {"grade", "grade the exercises completed in exercises/student", gradeCommand},
+}
+
+func main() {
+    if len(os.Args) < 2 {
+        usage()
+        os.Exit(2)
+    }
+    // Ctrl+C cancels the context, which stops a simulation or benchmark between blocks, or in the middle of
+    // mining one, and shuts a server down.
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+    defer stop()
+    for _, cmd := range commands {
+        if cmd.name == os.Args[1] {
+            if err := cmd.run(ctx, os.Args[2:]); err != nil {
+                fmt.Fprintf(os.Stderr, "consensus %s: %v\n", cmd.name, err)
+                os.Exit(1)
+            }
+            return
+        }
+    }
+    usage()
+    os.Exit(2)
+}
+
+// usage prints the list of subcommands.
+func usage() {
+    fmt.Fprintln(os.Stderr, "Usage: consensus <command> [flags]")
+    fmt.Fprintln(os.Stderr)
+    fmt.Fprintln(os.Stderr, "Commands:")
+    for _, cmd := range commands {
+        fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+    }
+    fmt.Fprintln(os.Stderr)
+    fmt.Fprintln(os.Stderr, "Run 'consensus <command> -h' for the flags of a command.")
+}
+
+// chainFile splits the path of a chain file into a FileStore for its directory and the chain's name,
+// so "runs/raft.json" is stored as chain "raft" in directory "runs".
+func chainFile(path string) (*storage.FileStore, string, error) {
+    store, err := storage.NewFileStore(filepath.Dir(path))
+    if err != nil {
+        return nil, "", err
+    }
+    return store, strings.TrimSuffix(filepath.Base(path), ".json"), nil
+}
+
+// isSet reports whether the named flag was given on the command line, as opposed to left at its default.
+func isSet(flags *flag.FlagSet, name string) bool {
+    set := false
+    flags.Visit(func(f *flag.Flag) { set = set || f.Name == name })
+    return set
+}
+
+// Footer: Overview and Execution Flow
+//
+// 1. **run**: Builds a simulation with engine.New, submits the requested number of blocks, and prints the chain.
+//    With -genesis the simulation starts from a genesis spec instead of the built-in defaults. With -out the
+//    chain is saved as JSON for later inspection; with -serve the simulation stays up behind the live dashboard,
+//    the HTTP API and the WebSocket event stream so it can be explored with a browser or curl, and with
+//    -interval it keeps adding blocks while it is watched. With -log the algorithm's votes, elections and
+//    commits are logged to standard error while it runs.
+// 2. **inspect**: Loads a chain file, prints the blocks and validates them with engine.Validate under the rules of
+//    the algorithm that produced them.
+// 3. **bench**: Runs the same workload against one or all algorithms through engine.Measure and reports blocks
+//    per second and the percentiles of the time to commit a block.
+// 4. **viz**: Loads one or more chain files, merges them into a block tree and draws it with the viz package,
+//    coloring blocks by producer and marking the first file's chain as canonical.
+// 5. **trace**: Runs a simulation of replicas built by the trace package on the sim package's virtual network,
+//    proposes blocks at the leader, and saves every step the replicas took as a trace file.
+// 6. **replay**: Loads a trace and steps through it interactively, forward and backward or to an instant, printing
+//    each step and the state of every replica, and branches what-if continuations into the shell package, or with
+//    -check replays it to the end to confirm it is still reproduced.
+// 7. **step**: Runs a live simulation through a sim.Stepper, which pauses at every delivery, proposal and tick
+//    that sends something, so each step of an election or a view change can be shown as it happens. Blocks are
+//    proposed from the keyboard, and -out saves the session as a trace for replay.
+// 8. **tui**: Runs a live simulation inside the full-screen display of the tui package, which advances it a slice
+//    of virtual time per frame and shows every node's role, term, log and latest messages as they change.
+// 9. **shell**: Runs a live simulation behind the prompt of the shell package, which proposes, crashes and
+//    recovers nodes, partitions the network, steps or runs the cluster and shows any node's chain on command.
+// 10. **compare**: Plays the same transactions and faults, from -blocks or a script file, against a simulated
+//    cluster of several algorithms with the compare package and prints one row of results per algorithm.
+//    -preset starts from a named set of flags, such as the 100-transaction workload of examples/comparison.
+// 11. **scenario**: Loads scenario files with the scenario package, plays each one on a simulated cluster and
+//    prints the faults of its timeline and the state every replica ended in.
+// 12. **explore**: Loads a saved chain or the chain of a snapshot, lists its blocks, shows one by height or hash
+//    with its data decoded, and verifies every block's hash and link with engine.Hash.
+// 13. **grade**: Grades the functions of package exercises/student with exercises.Grade and prints the report.
@@ -0,0 +1,182 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "log"
+    "net"
+    "net/http"
+    "os"
+    "time"
+
+    "consensus-algorithms-edu/api"
+    "consensus-algorithms-edu/dashboard"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/events"
+    "consensus-algorithms-edu/genesis"
+    "consensus-algorithms-edu/logging"
+    "consensus-algorithms-edu/metrics"
+    "consensus-algorithms-edu/stats"
+    "consensus-algorithms-edu/wire"
+)
+
+// runCommand implements "consensus run".
+func runCommand(ctx context.Context, args []string) error {
+    flags := flag.NewFlagSet("run", flag.ContinueOnError)
+    algo := flags.String("algo", "raft", "algorithm to simulate: one of pow, pos, dpos, pbft, raft, paxos")
+    nodes := flags.Int("nodes", 4, "number of nodes, validators or delegates")
+    genesisFile := flags.String("genesis", "", "start from the genesis spec in this JSON or YAML file; its algorithm is the default for -algo, and its nodes, validators and delegates override -nodes")
+    blocks := flags.Int("blocks", 10, "number of blocks to add after the genesis block")
+    out := flags.String("out", "", "save the resulting chain to this JSON file")
+    resume := flags.String("resume", "", "continue the run saved with -export to this snapshot file instead of starting a new one; -algo, -nodes and -genesis are ignored")
+    export := flags.String("export", "", "write the resulting chain and the algorithm's state to this snapshot file, which -resume continues from")
+    serve := flags.String("serve", "", "after running, serve the dashboard, HTTP API, event stream and metrics on this address, e.g. :8080")
+    interval := flags.Duration("interval", 0, "with -serve, keep adding a block at this interval, e.g. 1s, so the dashboard shows the simulation running")
+    quiet := flags.Bool("quiet", false, "do not print the blocks")
+    logLevel := flags.String("log", "off", "log consensus steps to standard error at this level: debug, info, warn, error or off")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if *blocks < 0 {
+        return errors.New("-blocks must not be negative")
+    }
+
+    logger, err := logging.New(os.Stderr, *logLevel)
+    if err != nil {
+        return err
+    }
+    var spec *genesis.Spec
+    if *genesisFile != "" {
+        if spec, err = genesis.Load(*genesisFile); err != nil {
+            return err
+        }
+        if spec.Algorithm != "" && !isSet(flags, "algo") {
+            *algo = spec.Algorithm // A spec written for one algorithm runs it without repeating -algo.
+        }
+    }
+    stream := events.NewStream()
+    cfg := engine.Config{Nodes: *nodes, Logger: logger, Events: stream, Genesis: spec}
+    var e engine.Engine
+    if *resume != "" {
+        e, err = resumeRun(*resume, cfg)
+    } else {
+        e, err = engine.New(*algo, cfg)
+    }
+    if err != nil {
+        return err
+    }
+    m := metrics.New()
+    recorder := stats.NewRecorder()
+    e = engine.Measure(engine.Instrument(e, m), recorder)
+
+    first := int(e.Status().Height) + 1 // Blocks of a resumed run are numbered on from where it stopped.
+    for i := first; i < first+*blocks; i++ {
+        if err := e.Submit(ctx, fmt.Sprintf("Block %d data", i)); err != nil {
+            return fmt.Errorf("block %d: %w", i, err)
+        }
+    }
+
+    chain := e.Blocks()
+    if !*quiet {
+        printBlocks(chain)
+    }
+    status := e.Status()
+    fmt.Printf("%s: %d nodes, height %d, finalized %d, head %.16s\n", status.Algorithm, status.Nodes, status.Height, status.Finalized, status.Head)
+    if summary := recorder.Summary(); summary.Blocks > 0 {
+        fmt.Println(summary)
+    }
+
+    if *out != "" {
+        store, name, err := chainFile(*out)
+        if err != nil {
+            return err
+        }
+        if err := store.Save(name, &wire.Chain{Algorithm: e.Algorithm(), Blocks: chain}); err != nil {
+            return err
+        }
+        fmt.Printf("saved %d blocks to %s\n", len(chain), *out)
+    }
+    if *export != "" {
+        if err := exportRun(e, *export); err != nil {
+            return err
+        }
+        fmt.Printf("exported the %s run to %s\n", e.Algorithm(), *export)
+    }
+
+    if *serve != "" {
+        server := api.NewServer(e)
+        server.Handle("GET /{$}", dashboard.Handler())
+        server.Handle("GET /events", api.EventsHandler(stream))
+        server.Handle("GET /metrics", m)
+        if *interval > 0 {
+            go feed(ctx, e, first+*blocks, *interval)
+        }
+        log.Printf("serving the %s simulation on %s (open / for the dashboard, or try /status, /blocks, POST /submit, ws /events, /metrics)", e.Algorithm(), *serve)
+        return serveUntilDone(ctx, &http.Server{Addr: *serve, Handler: server})
+    }
+    return nil
+}
+
+// resumeRun builds an engine from the snapshot in the named file.
+func resumeRun(path string, cfg engine.Config) (engine.Engine, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    e, err := engine.Import(f, cfg)
+    if err != nil {
+        return nil, fmt.Errorf("%s: %w", path, err)
+    }
+    return e, nil
+}
+
+// exportRun writes a snapshot of e to the named file.
+func exportRun(e engine.Engine, path string) error {
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    if err := e.Export(f); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
+
+// feed submits a block every interval, numbering them from first, until ctx is done.
+func feed(ctx context.Context, e engine.Engine, first int, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for i := first; ; i++ {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+        if err := e.Submit(ctx, fmt.Sprintf("Block %d data", i)); err != nil && ctx.Err() == nil {
+            log.Printf("block %d: %v", i, err)
+        }
+    }
+}
+
+// serveUntilDone serves HTTP until ctx is done and then shuts the server down, letting requests in flight finish.
+// Requests are served under ctx, so submissions still running are cancelled as well.
+func serveUntilDone(ctx context.Context, server *http.Server) error {
+    server.BaseContext = func(net.Listener) context.Context { return ctx }
+    go func() {
+        <-ctx.Done()
+        server.Shutdown(context.Background())
+    }()
+    if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+        return err
+    }
+    return nil
+}
+
+// printBlocks prints one line per block.
+func printBlocks(blocks []*wire.Block) {
+    fmt.Print(wire.Dump(blocks))
+}
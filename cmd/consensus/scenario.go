@@ -0,0 +1,54 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+
+    "consensus-algorithms-edu/scenario"
+)
+
+// scenarioCommand implements "consensus scenario".
+func scenarioCommand(ctx context.Context, args []string) error {
+    flags := flag.NewFlagSet("scenario", flag.ContinueOnError)
+    algo := flags.String("algo", "", "play every scenario with this algorithm instead of the one it names")
+    seed := flags.Int64("seed", 0, "play every scenario with this seed instead of its own")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if flags.NArg() == 0 {
+        return errors.New("usage: consensus scenario [-algo=ALGO] [-seed=N] FILE...")
+    }
+
+    failed, checked := 0, 0
+    for i, path := range flags.Args() {
+        sc, err := scenario.Load(path)
+        if err != nil {
+            return err
+        }
+        if *algo != "" {
+            sc.Algorithm = *algo
+        }
+        if isSet(flags, "seed") {
+            sc.Seed = *seed
+        }
+        res, err := scenario.Run(ctx, sc)
+        if err != nil {
+            return fmt.Errorf("%s: %w", path, err)
+        }
+        if i > 0 {
+            fmt.Println()
+        }
+        if err := res.Print(os.Stdout); err != nil {
+            return err
+        }
+        failed += res.Failed()
+        checked += len(res.Checks)
+    }
+    if failed > 0 {
+        return fmt.Errorf("%d of %d expectations failed", failed, checked)
+    }
+    return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "math/rand"
+    "os"
+    "time"
+
+    "consensus-algorithms-edu/modelcheck"
+    "consensus-algorithms-edu/shell"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/trace"
+)
+
+// shellCommand implements "consensus shell".
+func shellCommand(ctx context.Context, args []string) error {
+    flags := flag.NewFlagSet("shell", flag.ContinueOnError)
+    algo := flags.String("algo", "raft", "algorithm to simulate: one of pow, pos, dpos, pbft, raft")
+    nodes := flags.Int("nodes", 5, "number of replicas, numbered from 0")
+    seed := flags.Int64("seed", 1, "seed of the simulation; the same seed and commands replay the same session")
+    latency := flags.Duration("latency", 5*time.Millisecond, "mean message latency; each message takes between half and one and a half times as long")
+    drop := flags.Float64("drop", 0, "fraction of messages lost, between 0 and 1")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if flags.NArg() != 0 {
+        return errors.New("usage: consensus shell [-algo=ALGO] [-nodes=N] [-seed=N] [-latency=DURATION] [-drop=RATE]")
+    }
+    if *nodes <= 0 {
+        return errors.New("-nodes must be positive")
+    }
+    build, ok := trace.Builders[*algo]
+    if !ok {
+        return fmt.Errorf("%w %q", trace.ErrUnknownAlgorithm, *algo)
+    }
+
+    s := sim.New(sim.Config{Seed: *seed, Network: sim.Link{Latency: sim.Uniform(*latency/2, *latency*3/2), Drop: *drop}})
+    peers := make([]int32, *nodes)
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    for _, id := range peers {
+        s.Add(build(id, peers, modelcheck.Env{Clock: s.Clock(), Rand: rand.New(rand.NewSource(*seed ^ int64(id)<<32))}))
+    }
+    return shell.New(s, *algo, os.Stdout).Run(ctx, os.Stdin)
+}
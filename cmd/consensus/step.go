@@ -0,0 +1,133 @@
+package main
+
+import (
+    "bufio"
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/trace"
+)
+
+// stepCommand implements "consensus step".
+func stepCommand(ctx context.Context, args []string) error {
+    flags := flag.NewFlagSet("step", flag.ContinueOnError)
+    algo := flags.String("algo", "raft", "algorithm to simulate: one of pow, pos, dpos, pbft, raft")
+    nodes := flags.Int("nodes", 3, "number of replicas")
+    seed := flags.Int64("seed", 1, "seed of the simulation; the same seed replays the same session")
+    latency := flags.Duration("latency", 5*time.Millisecond, "mean message latency; each message takes between half and one and a half times as long")
+    drop := flags.Float64("drop", 0, "fraction of messages lost, between 0 and 1")
+    all := flags.Bool("all", false, "also pause at ticks on which a replica sends nothing")
+    out := flags.String("out", "", "when the session ends, save everything that happened as a trace file for replay")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if flags.NArg() != 0 {
+        return errors.New("usage: consensus step [-algo=ALGO] [-nodes=N] [-seed=N] [-all] [-out=FILE]")
+    }
+    if *nodes <= 0 {
+        return errors.New("-nodes must be positive")
+    }
+
+    s := sim.New(sim.Config{Seed: *seed, Network: sim.Link{Latency: sim.Uniform(*latency/2, *latency*3/2), Drop: *drop}})
+    recorder, err := trace.Record(s, trace.Config{Algorithm: *algo, Nodes: *nodes, Seed: *seed})
+    if err != nil {
+        return fmt.Errorf("%w %q", err, *algo)
+    }
+    stepper := sim.NewStepper(s)
+    if *all {
+        stepper.Pause = func(sim.Transition) bool { return true }
+    }
+    replicas := recorder.Replicas()
+
+    fmt.Printf("%s with %d nodes. Commands: n(ext) [N], p(ropose) [DATA], s(tates), q(uit).\n", *algo, *nodes)
+    input := bufio.NewScanner(os.Stdin)
+    proposals := 0
+    for {
+        fmt.Printf("[%v] ", s.Now())
+        if !input.Scan() || ctx.Err() != nil {
+            break
+        }
+        fields := strings.Fields(input.Text())
+        if len(fields) == 0 {
+            fields = []string{"n"} // An empty line steps forward, like a debugger.
+        }
+        switch fields[0] {
+        case "n":
+            count := 1
+            if len(fields) == 2 {
+                var err error
+                if count, err = strconv.Atoi(fields[1]); err != nil {
+                    fmt.Println(err)
+                    continue
+                }
+            }
+            for i := 0; i < count; i++ {
+                t, ok := stepper.Next(time.Minute)
+                if !ok {
+                    fmt.Println("nothing happened for a minute of virtual time")
+                    break
+                }
+                state := trace.Describe(replicas[t.Node])
+                fmt.Printf("%v\n    %s now at height %d, leader %s\n", t, node.Name(t.Node), state.Height, leaderName(state.Leader))
+            }
+        case "p":
+            proposals++
+            data := fmt.Sprintf("Block %d data", proposals)
+            if len(fields) > 1 {
+                data = strings.Join(fields[1:], " ")
+            }
+            // Proposals go to the leader node 0 follows, since a Raft follower would refuse them.
+            target := int32(0)
+            if l, ok := replicas[0].(node.Leaderful); ok && l.Leader() >= 0 {
+                target = l.Leader()
+            }
+            if err := s.Propose(target, data); err != nil {
+                fmt.Printf("%s refused %q: %v\n", node.Name(target), data, err)
+            }
+        case "s":
+            states := make([]trace.State, len(replicas))
+            for i, replica := range replicas {
+                states[i] = trace.Describe(replica)
+            }
+            printStates(states)
+        case "q":
+            return saveSession(recorder, *out)
+        default:
+            fmt.Println("unknown command; use n [N], p [DATA], s or q")
+        }
+    }
+    fmt.Println()
+    if err := input.Err(); err != nil {
+        return err
+    }
+    return saveSession(recorder, *out)
+}
+
+// leaderName names the leader a replica follows, or returns "-" if it follows none.
+func leaderName(leader int32) string {
+    if leader < 0 {
+        return "-"
+    }
+    return node.Name(leader)
+}
+
+// saveSession saves the steps of a session as a trace file, if a path was given.
+func saveSession(recorder *trace.Recorder, path string) error {
+    if path == "" {
+        return nil
+    }
+    recorded := recorder.Trace()
+    if err := recorded.Save(path); err != nil {
+        return err
+    }
+    fmt.Printf("saved %d steps to %s\n", len(recorded.Steps), path)
+    return nil
+}
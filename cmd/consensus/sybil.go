@@ -0,0 +1,49 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+
+    "consensus-algorithms-edu/sybil"
+)
+
+// sybilCommand implements "consensus sybil".
+func sybilCommand(ctx context.Context, args []string) error {
+    flags := flag.NewFlagSet("sybil", flag.ContinueOnError)
+    schemes := flags.String("scheme", "all", "schemes to attack, separated by commas, or all: pow, pos, equal, pbft")
+    honest := flags.Int("honest", sybil.DefaultHonest, "number of honest nodes")
+    share := flags.Float64("share", sybil.DefaultShare, "attacker's share of all hash power or stake, between 0 and 1")
+    identities := flags.String("identities", "1,3,10,30", "numbers of identities the attacker splits its share across, separated by commas")
+    seed := flags.Int64("seed", 1, "seed of every run; the same seed plays out the same attack")
+    duration := flags.Duration("duration", sybil.DefaultDuration, "virtual time each run lasts")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if flags.NArg() != 0 {
+        return errors.New("usage: consensus sybil [-scheme=A,B] [-honest=N] [-share=F] [-identities=N,M] [-seed=N]")
+    }
+
+    cfg := sybil.Config{Honest: *honest, Share: *share, Seed: *seed, Duration: *duration}
+    if *schemes != "all" {
+        for _, name := range strings.Split(*schemes, ",") {
+            cfg.Schemes = append(cfg.Schemes, sybil.Scheme(name))
+        }
+    }
+    for _, field := range strings.Split(*identities, ",") {
+        n, err := strconv.Atoi(field)
+        if err != nil || n <= 0 {
+            return fmt.Errorf("invalid number of identities %q", field)
+        }
+        cfg.Identities = append(cfg.Identities, n)
+    }
+    report, err := sybil.Run(ctx, cfg)
+    if err != nil {
+        return err
+    }
+    return report.Print(os.Stdout)
+}
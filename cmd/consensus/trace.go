@@ -0,0 +1,255 @@
+package main
+
+import (
+    "bufio"
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/shell"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/trace"
+)
+
+// traceCommand implements "consensus trace".
+func traceCommand(ctx context.Context, args []string) error {
+    flags := flag.NewFlagSet("trace", flag.ContinueOnError)
+    algo := flags.String("algo", "raft", "algorithm to record: one of pow, pos, dpos, pbft, raft")
+    nodes := flags.Int("nodes", 4, "number of replicas")
+    seed := flags.Int64("seed", 1, "seed of the simulation; the same seed records the same run")
+    blocks := flags.Int("blocks", 3, "number of blocks to propose")
+    interval := flags.Duration("interval", 500*time.Millisecond, "virtual time before each proposal and after the last one")
+    latency := flags.Duration("latency", 5*time.Millisecond, "mean message latency; each message takes between half and one and a half times as long")
+    drop := flags.Float64("drop", 0, "fraction of messages lost, between 0 and 1")
+    out := flags.String("out", "", "write the trace to this file")
+    export := flags.String("export", "", "write the trace to this file as JSON for analysis outside Go (see trace/schema.json)")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if (*out == "" && *export == "") || flags.NArg() != 0 {
+        return errors.New("usage: consensus trace -out=FILE|-export=FILE [-algo=ALGO] [-nodes=N] [-seed=N] [-blocks=N]")
+    }
+    if *nodes <= 0 {
+        return errors.New("-nodes must be positive")
+    }
+
+    s := sim.New(sim.Config{Seed: *seed, Network: sim.Link{Latency: sim.Uniform(*latency/2, *latency*3/2), Drop: *drop}})
+    recorder, err := trace.Record(s, trace.Config{Algorithm: *algo, Nodes: *nodes, Seed: *seed})
+    if err != nil {
+        return fmt.Errorf("%w %q", err, *algo)
+    }
+    for i := 1; i <= *blocks; i++ {
+        if err := s.RunForContext(ctx, *interval); err != nil {
+            return err
+        }
+        // Proposals go to the leader node 0 follows, since a Raft follower would refuse them.
+        target := int32(0)
+        if l, ok := recorder.Replicas()[0].(node.Leaderful); ok && l.Leader() >= 0 {
+            target = l.Leader()
+        }
+        s.Propose(target, fmt.Sprintf("Block %d data", i))
+    }
+    if err := s.RunForContext(ctx, *interval); err != nil {
+        return err
+    }
+
+    recorded := recorder.Trace()
+    if *out != "" {
+        if err := recorded.Save(*out); err != nil {
+            return err
+        }
+        fmt.Printf("recorded %d steps of %s over %v to %s\n", len(recorded.Steps), *algo, s.Now(), *out)
+    }
+    if *export != "" {
+        if err := exportTrace(recorded, *export); err != nil {
+            return err
+        }
+        fmt.Printf("exported %d steps of %s over %v to %s\n", len(recorded.Steps), *algo, s.Now(), *export)
+    }
+    return nil
+}
+
+// exportTrace writes the analysis export of a trace to a file.
+func exportTrace(recorded *trace.Trace, path string) error {
+    e, err := recorded.Export()
+    if err != nil {
+        return err
+    }
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    w := bufio.NewWriter(f)
+    if err := e.Write(w); err != nil {
+        f.Close()
+        return err
+    }
+    if err := w.Flush(); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
+
+// replayCommand implements "consensus replay FILE".
+func replayCommand(ctx context.Context, args []string) error {
+    flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+    check := flags.Bool("check", false, "replay the whole trace, report whether it is reproduced, and exit")
+    seed := flags.Int64("seed", 1, "seed of the simulation a what-if branch continues in")
+    latency := flags.Duration("latency", 5*time.Millisecond, "mean message latency of a what-if branch; each message takes between half and one and a half times as long")
+    drop := flags.Float64("drop", 0, "fraction of messages a what-if branch loses, between 0 and 1")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if flags.NArg() != 1 {
+        return errors.New("usage: consensus replay [-check] [-seed=N] [-latency=DURATION] [-drop=RATE] FILE")
+    }
+    recorded, err := trace.Load(flags.Arg(0))
+    if err != nil {
+        return err
+    }
+    replay, err := trace.NewReplayer(recorded, nil)
+    if err != nil {
+        return err
+    }
+    if *check {
+        if err := replay.Seek(replay.Len()); err != nil {
+            return err
+        }
+        fmt.Printf("%s: %d steps replayed, every state matches the recording\n", recorded.Algorithm, replay.Len())
+        return nil
+    }
+
+    fmt.Printf("%s trace of %d nodes, %d steps. Commands: n(ext), b(ack), g(oto) N, t(ime) DURATION, s(tates), w(hat-if) [FILE], q(uit).\n", recorded.Algorithm, recorded.Nodes, replay.Len())
+    printStates(replay.States())
+    input := bufio.NewScanner(os.Stdin)
+    for {
+        fmt.Printf("[%d/%d] ", replay.Position(), replay.Len())
+        if !input.Scan() || ctx.Err() != nil {
+            break
+        }
+        fields := strings.Fields(input.Text())
+        if len(fields) == 0 {
+            fields = []string{"n"} // An empty line steps forward, like a debugger.
+        }
+        switch fields[0] {
+        case "n":
+            step, err := replay.Forward()
+            report(replay.Position()-1, step, err)
+        case "b":
+            step, err := replay.Back()
+            if err == nil {
+                fmt.Printf("undid #%d %v\n", replay.Position(), step)
+            } else {
+                fmt.Println(err)
+            }
+        case "g":
+            n := -1
+            if len(fields) == 2 {
+                var err error
+                if n, err = strconv.Atoi(fields[1]); err != nil {
+                    fmt.Println(err)
+                    continue
+                }
+            }
+            if err := replay.Seek(n); err != nil {
+                fmt.Println(err)
+                continue
+            }
+            printStates(replay.States())
+        case "t":
+            at := time.Duration(-1)
+            if len(fields) == 2 {
+                var err error
+                if at, err = time.ParseDuration(fields[1]); err != nil {
+                    fmt.Println(err)
+                    continue
+                }
+            }
+            if err := replay.SeekTime(at); err != nil {
+                fmt.Println(err)
+                continue
+            }
+            fmt.Printf("at %v, after step #%d\n", replay.At(), replay.Position()-1)
+            printStates(replay.States())
+        case "s":
+            printStates(replay.States())
+        case "w":
+            out := ""
+            if len(fields) == 2 {
+                out = fields[1]
+            }
+            link := sim.Link{Latency: sim.Uniform(*latency/2, *latency*3/2), Drop: *drop}
+            if err := whatIf(ctx, replay, sim.Config{Seed: *seed, Network: link}, input, out); err != nil {
+                return err
+            }
+        case "q":
+            return nil
+        default:
+            fmt.Println("unknown command; use n, b, g N, t DURATION, s, w [FILE] or q")
+        }
+    }
+    fmt.Println()
+    return input.Err()
+}
+
+// whatIf branches the replay at its current position into a live simulation, and carries out shell commands on
+// it, read from input, until quit. The branch, replayed steps and new ones together, is saved to out if given.
+func whatIf(ctx context.Context, replay *trace.Replayer, cfg sim.Config, input *bufio.Scanner, out string) error {
+    branch, err := replay.Branch(trace.BranchConfig{Sim: cfg})
+    if err != nil {
+        return err
+    }
+    algorithm := branch.Trace().Algorithm
+    fmt.Printf("branched at %v with %d messages in flight; shell commands run the what-if, quit returns to the replay.\n", branch.Sim.Now(), branch.InFlight)
+    sh := shell.New(branch.Sim, algorithm, os.Stdout)
+    for {
+        fmt.Printf("[%v] what-if %s> ", branch.Sim.Now().Round(time.Millisecond), algorithm)
+        if !input.Scan() || ctx.Err() != nil {
+            fmt.Println()
+            break
+        }
+        err := sh.Exec(input.Text())
+        if errors.Is(err, shell.ErrQuit) {
+            break
+        }
+        if err != nil {
+            fmt.Println(err)
+        }
+    }
+    if out == "" {
+        return nil
+    }
+    recorded := branch.Trace()
+    if err := recorded.Save(out); err != nil {
+        return err
+    }
+    fmt.Printf("saved the branch, %d steps, to %s\n", len(recorded.Steps), out)
+    return nil
+}
+
+// report prints a step taken forward, or why it could not be.
+func report(index int, step trace.Step, err error) {
+    if errors.Is(err, trace.ErrOutOfRange) {
+        fmt.Println("end of the trace")
+        return
+    }
+    fmt.Printf("#%d %v\n", index, step)
+    if err != nil {
+        fmt.Println(err)
+    }
+}
+
+// printStates prints one line per replica: its committed height and head, the leader it follows and the
+// fingerprint of its complete state.
+func printStates(states []trace.State) {
+    for _, state := range states {
+        fmt.Printf("  %-8s height %-4d head %.16s  leader %-8s state %s\n", node.Name(state.Node), state.Height, state.Head, leaderName(state.Leader), state.Fingerprint)
+    }
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "math/rand"
+    "time"
+
+    "consensus-algorithms-edu/modelcheck"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/trace"
+    "consensus-algorithms-edu/tui"
+)
+
+// tuiCommand implements "consensus tui".
+func tuiCommand(ctx context.Context, args []string) error {
+    flags := flag.NewFlagSet("tui", flag.ContinueOnError)
+    algo := flags.String("algo", "raft", "algorithm to simulate: one of pow, pos, dpos, pbft, raft")
+    nodes := flags.Int("nodes", 5, "number of replicas, at most 10 so that each can be crashed with a digit key")
+    seed := flags.Int64("seed", 1, "seed of the simulation")
+    latency := flags.Duration("latency", 5*time.Millisecond, "mean message latency; each message takes between half and one and a half times as long")
+    drop := flags.Float64("drop", 0, "fraction of messages lost, between 0 and 1")
+    speed := flags.Duration("speed", tui.DefaultSpeed, "virtual time simulated every tenth of a second")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if flags.NArg() != 0 {
+        return errors.New("usage: consensus tui [-algo=ALGO] [-nodes=N] [-seed=N] [-speed=DURATION]")
+    }
+    if *nodes <= 0 || *nodes > 10 {
+        return errors.New("-nodes must be between 1 and 10")
+    }
+    build, ok := trace.Builders[*algo]
+    if !ok {
+        return fmt.Errorf("%w %q", trace.ErrUnknownAlgorithm, *algo)
+    }
+
+    s := sim.New(sim.Config{Seed: *seed, Network: sim.Link{Latency: sim.Uniform(*latency/2, *latency*3/2), Drop: *drop}})
+    peers := make([]int32, *nodes)
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    for _, id := range peers {
+        s.Add(build(id, peers, modelcheck.Env{Clock: s.Clock(), Rand: rand.New(rand.NewSource(*seed ^ int64(id)<<32))}))
+    }
+    return tui.Run(ctx, tui.New(s, tui.Config{Algorithm: *algo, Speed: *speed}))
+}
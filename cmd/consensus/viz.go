@@ -0,0 +1,66 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+
+    "consensus-algorithms-edu/viz"
+    "consensus-algorithms-edu/wire"
+)
+
+// vizCommand implements "consensus viz FILE...".
+func vizCommand(_ context.Context, args []string) error {
+    flags := flag.NewFlagSet("viz", flag.ContinueOnError)
+    format := flags.String("format", "svg", "output format: svg or dot")
+    out := flags.String("out", "", "write the drawing to this file instead of standard output")
+    title := flags.String("title", "", "caption drawn above the chain (default: the algorithm's name)")
+    data := flags.Bool("data", false, "show each block's data")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if flags.NArg() == 0 {
+        return errors.New("usage: consensus viz [-format=svg|dot] [-out=FILE] [-title=TITLE] [-data] FILE...")
+    }
+
+    render := viz.SVG
+    switch *format {
+    case "svg":
+    case "dot":
+        render = viz.DOT
+    default:
+        return fmt.Errorf("unknown format %q", *format)
+    }
+
+    // Chains saved from different nodes are merged, so blocks they disagree on show up as forks. The first
+    // file's chain is drawn as the canonical one.
+    var chains [][]*wire.Block
+    for _, path := range flags.Args() {
+        store, name, err := chainFile(path)
+        if err != nil {
+            return err
+        }
+        chain, err := store.Load(name)
+        if err != nil {
+            return err
+        }
+        if *title == "" {
+            *title = chain.GetAlgorithm()
+        }
+        chains = append(chains, chain.GetBlocks())
+    }
+
+    var w io.Writer = os.Stdout
+    if *out != "" {
+        f, err := os.Create(*out)
+        if err != nil {
+            return err
+        }
+        defer f.Close()
+        w = f
+    }
+    return render(w, viz.Merge(chains...), viz.Options{Title: *title, Canonical: chains[0], Data: *data})
+}
@@ -0,0 +1,162 @@
+// Package main runs a single Raft or PBFT node as its own operating-system process.
+// Nodes talk to each other over gRPC or plain TCP, so starting one process per node turns the in-process simulations of this
+// repository into a real (local) distributed system: each process has its own memory, its own clock, and can be
+// killed independently. Lines typed on standard input are proposed as new blocks, and every block the node
+// commits is printed as soon as consensus is reached.
+package main
+
+import (
+    "bufio"
+    "context"
+    "flag"
+    "fmt"
+    "log"
+    "net/http"
+    "os"
+    "os/signal"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/logging"
+    "consensus-algorithms-edu/metrics"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/transport"
+    "consensus-algorithms-edu/wire"
+)
+
+func main() {
+    algo := flag.String("algo", "raft", "consensus algorithm to run: raft or pbft")
+    id := flag.Int("id", 0, "identifier of this node; must appear in -peers")
+    peersFlag := flag.String("peers", "0=127.0.0.1:7000,1=127.0.0.1:7001,2=127.0.0.1:7002", "comma-separated id=host:port list of every node, including this one")
+    network := flag.String("transport", "grpc", "transport between nodes: grpc or tcp")
+    tick := flag.Duration("tick", 50*time.Millisecond, "duration of one logical tick")
+    metricsAddr := flag.String("metrics", "", "serve Prometheus metrics at /metrics on this address, e.g. :9100")
+    logLevel := flag.String("log", "off", "log consensus steps to standard error at this level: debug, info, warn, error or off")
+    flag.Parse()
+
+    logger, err := logging.New(os.Stderr, *logLevel)
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    peers, err := parsePeers(*peersFlag)
+    if err != nil {
+        log.Fatal(err)
+    }
+    self := int32(*id)
+    listen, ok := peers[self]
+    if !ok {
+        log.Fatalf("node %d does not appear in -peers", self)
+    }
+
+    // Build the replica for the requested algorithm. Every node must be started with the same peer list.
+    ids := sortedIDs(peers)
+    var replica node.Replica
+    switch *algo {
+    case "raft":
+        replica = raft.NewReplica(raft.ReplicaConfig{ID: self, Peers: ids, Logger: logger})
+    case "pbft":
+        replica = pbft.NewReplica(pbft.ReplicaConfig{ID: self, Peers: ids, Logger: logger})
+    default:
+        log.Fatalf("unknown algorithm %q (want raft or pbft)", *algo)
+    }
+
+    runner := node.NewRunner(replica)
+    runner.Algorithm = *algo
+    runner.OnCommit = func(block *wire.Block) {
+        fmt.Printf("[node %d] committed block %d: %q (hash %.12s...)\n", self, block.GetIndex(), block.GetData(), block.GetHash())
+    }
+
+    var t transport.Transport
+    switch *network {
+    case "grpc":
+        t, err = transport.NewGRPCTransport(transport.GRPCConfig{ID: self, Listen: listen, Peers: peers}, runner.Handle)
+    case "tcp":
+        t, err = transport.NewTCPTransport(transport.TCPConfig{ID: self, Listen: listen, Peers: peers}, runner.Handle)
+    default:
+        err = fmt.Errorf("unknown transport %q (want grpc or tcp)", *network)
+    }
+    if err != nil {
+        log.Fatal(err)
+    }
+    defer t.Close()
+    runner.Attach(t)
+
+    if *metricsAddr != "" {
+        runner.Metrics = metrics.New()
+        mux := http.NewServeMux()
+        mux.Handle("GET /metrics", runner.Metrics)
+        go func() { log.Fatal(http.ListenAndServe(*metricsAddr, mux)) }()
+    }
+
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+    defer stop()
+    go runner.Run(ctx, *tick)
+    go readProposals(runner, self)
+
+    fmt.Printf("[node %d] running %s over %s on %s with %d nodes; type a line to propose a block\n", self, *algo, *network, listen, len(ids))
+    <-ctx.Done()
+}
+
+// readProposals proposes every line read from standard input.
+func readProposals(runner *node.Runner, self int32) {
+    scanner := bufio.NewScanner(os.Stdin)
+    for scanner.Scan() {
+        data := strings.TrimSpace(scanner.Text())
+        if data == "" {
+            continue
+        }
+        if err := runner.Propose(data); err != nil {
+            fmt.Printf("[node %d] proposal rejected: %v\n", self, err) // E.g. a Raft follower; try the leader's terminal.
+        }
+    }
+}
+
+// parsePeers parses "0=host:port,1=host:port" into a map from node ID to address.
+func parsePeers(s string) (map[int32]string, error) {
+    peers := make(map[int32]string)
+    for _, part := range strings.Split(s, ",") {
+        idText, addr, ok := strings.Cut(strings.TrimSpace(part), "=")
+        if !ok {
+            return nil, fmt.Errorf("invalid peer %q: want id=host:port", part)
+        }
+        id, err := strconv.Atoi(idText)
+        if err != nil {
+            return nil, fmt.Errorf("invalid peer id %q: %w", idText, err)
+        }
+        peers[int32(id)] = addr
+    }
+    return peers, nil
+}
+
+// sortedIDs returns the peer IDs in ascending order, so every node derives the same PBFT primary order.
+func sortedIDs(peers map[int32]string) []int32 {
+    ids := make([]int32, 0, len(peers))
+    for id := range peers {
+        ids = append(ids, id)
+    }
+    sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+    return ids
+}
+
+// Footer: Overview and Execution Flow
+//
+// 1. **Configuration**: Every node is started with the same -peers list and its own -id. The list fixes both the
+//    gRPC addresses and, for PBFT, the order in which replicas become primary.
+// 2. **Replica and Runner**: The node builds a message-driven raft.Replica or pbft.Replica and wraps it in a
+//    node.Runner, which ticks the replica every -tick and serializes message handling.
+// 3. **Transport**: Envelopes produced by the replica are sent to peers through transport.GRPCTransport or, with
+//    -transport=tcp, transport.TCPTransport, and envelopes received from peers are handed back to the runner.
+// 4. **Proposals and Commits**: Each line on standard input is proposed. Raft followers reject proposals (only the
+//    leader may propose), while PBFT backups forward them to the primary. Committed blocks are printed as they arrive.
+// 5. **Metrics**: With -metrics, the runner records messages, elections, commits and round durations, and the node
+//    serves them at /metrics for Prometheus to scrape.
+// 6. **Logging**: With -log=info (or debug), the replica logs elections, votes, view changes and commits to standard
+//    error as key=value lines labelled with the algorithm and node, e.g. `msg="granted vote" node=1 candidate=0 term=3`.
+//
+// Killing the leader's process (Raft) or the primary's process (PBFT) demonstrates fault tolerance: the remaining
+// nodes elect a new leader or change view and continue committing blocks.
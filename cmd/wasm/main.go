@@ -0,0 +1,151 @@
+//go:build js && wasm
+
+// Package main is the WebAssembly build of the simulations, for browser-based teaching tools that run them
+// without a server. Built with GOOS=js GOARCH=wasm and loaded through Go's wasm_exec.js, it installs a global
+// consensus object:
+//
+//  const net = consensus.createNetwork("raft", {nodes: 5, seed: 1})
+//  if (net instanceof Error) throw net
+//  const off = net.subscribe(event => console.log(event.type, event.node))
+//  const block = await net.addBlock("Block 1 data")
+//  console.log(net.blocks(), net.status(), net.participants())
+//  off()
+//
+// Every network is an engine.Engine, so all six algorithms behave as they do in the command-line tool, and
+// blocks, statuses, participants and events have exactly the JSON shape the HTTP API (package api) and its
+// event stream use. A page can therefore talk to a simulation in the browser or on a server with the same code.
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "syscall/js"
+
+    "consensus-algorithms-edu/api"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/events"
+    "consensus-algorithms-edu/options"
+)
+
+func main() {
+    js.Global().Set("consensus", js.ValueOf(map[string]any{
+        "algorithms":    js.FuncOf(algorithms),
+        "createNetwork": js.FuncOf(createNetwork),
+    }))
+    select {} // Keep the program alive to serve calls from JavaScript.
+}
+
+// algorithms implements consensus.algorithms(), which lists the algorithms createNetwork accepts.
+func algorithms(js.Value, []js.Value) any {
+    return toJS(engine.Algorithms())
+}
+
+// createNetwork implements consensus.createNetwork(algorithm, {nodes, seed}). It returns a network object, or
+// an Error if the algorithm is unknown or its network cannot be built.
+func createNetwork(_ js.Value, args []js.Value) any {
+    if len(args) == 0 || args[0].Type() != js.TypeString {
+        return jsError(errors.New("createNetwork: the first argument must be an algorithm name"))
+    }
+    cfg := engine.Config{Events: events.NewBus()}
+    if len(args) > 1 && args[1].Type() == js.TypeObject {
+        if nodes := args[1].Get("nodes"); nodes.Type() == js.TypeNumber {
+            cfg.Nodes = nodes.Int()
+        }
+        if seed := args[1].Get("seed"); seed.Type() == js.TypeNumber {
+            cfg.Options = append(cfg.Options, options.WithSeed(int64(seed.Int())))
+        }
+    }
+    e, err := engine.New(args[0].String(), cfg)
+    if err != nil {
+        return jsError(err)
+    }
+    return newNetwork(e, cfg.Events.(*events.Bus))
+}
+
+// newNetwork wraps an engine in the object createNetwork returns.
+func newNetwork(e engine.Engine, bus *events.Bus) js.Value {
+    return js.ValueOf(map[string]any{
+        "algorithm": e.Algorithm(),
+        "addBlock": js.FuncOf(func(_ js.Value, args []js.Value) any {
+            if len(args) == 0 || args[0].Type() != js.TypeString {
+                return jsError(errors.New("addBlock: the argument must be the block's data"))
+            }
+            return addBlock(e, args[0].String())
+        }),
+        "blocks": js.FuncOf(func(js.Value, []js.Value) any {
+            blocks := e.Blocks()
+            out := make([]api.Block, len(blocks))
+            for i, block := range blocks {
+                out[i] = api.BlockFromWire(block)
+            }
+            return toJS(out)
+        }),
+        "status":       js.FuncOf(func(js.Value, []js.Value) any { return toJS(e.Status()) }),
+        "participants": js.FuncOf(func(js.Value, []js.Value) any { return toJS(e.Participants()) }),
+        "subscribe": js.FuncOf(func(_ js.Value, args []js.Value) any {
+            if len(args) == 0 || args[0].Type() != js.TypeFunction {
+                return jsError(errors.New("subscribe: the argument must be a function"))
+            }
+            callback := args[0]
+            remove := bus.OnEvent(func(event events.Event) { callback.Invoke(toJS(event)) })
+            var unsubscribe js.Func
+            unsubscribe = js.FuncOf(func(js.Value, []js.Value) any {
+                remove()
+                unsubscribe.Release()
+                return nil
+            })
+            return unsubscribe
+        }),
+    })
+}
+
+// addBlock runs consensus on data and returns a Promise of the new head block. Consensus runs on its own
+// goroutine: a call from JavaScript that blocked, as mining a Proof of Work block does, would freeze the page.
+func addBlock(e engine.Engine, data string) js.Value {
+    var executor js.Func
+    executor = js.FuncOf(func(_ js.Value, args []js.Value) any {
+        resolve, reject := args[0], args[1]
+        go func() {
+            defer executor.Release()
+            if err := e.Submit(context.Background(), data); err != nil {
+                reject.Invoke(jsError(err))
+                return
+            }
+            blocks := e.Blocks()
+            resolve.Invoke(toJS(api.BlockFromWire(blocks[len(blocks)-1])))
+        }()
+        return nil
+    })
+    return js.Global().Get("Promise").New(executor)
+}
+
+// toJS converts v to a JavaScript value through its JSON encoding, so objects handed to JavaScript have the
+// same field names as the HTTP API's responses.
+func toJS(v any) js.Value {
+    encoded, err := json.Marshal(v)
+    if err != nil {
+        return jsError(err)
+    }
+    return js.Global().Get("JSON").Call("parse", string(encoded))
+}
+
+// jsError converts err to a JavaScript Error. Go cannot throw into JavaScript, so functions return the Error
+// in place of their result, and callers check for it with instanceof.
+func jsError(err error) js.Value {
+    return js.Global().Get("Error").New(err.Error())
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **The Engine Is the API**: The bindings expose engine.Engine and nothing else, rather than each
+//    algorithm's own types. Six algorithms cost one set of bindings, and anything added to the engines reaches
+//    the browser without touching this file.
+//
+// 2. **JSON Shapes Shared with the Server**: Values cross into JavaScript through their JSON encoding, using the
+//    api package's Block and the events package's Event. A teaching page written against the HTTP API and its
+//    WebSocket stream needs no changes to run against the WebAssembly build instead.
+//
+// 3. **Promises for Consensus Rounds**: addBlock runs the round on a goroutine and returns a Promise, because Go
+//    functions called from JavaScript run on the browser's event loop. Events are delivered to subscribers as the
+//    round publishes them, in between.
@@ -0,0 +1,245 @@
+// Package compare runs several consensus algorithms side by side on the same script and reports how each fared.
+// A Script is a list of events on a virtual timeline: transactions to submit, and faults such as a partition,
+// an isolated node or a lossy network, followed by the heal that ends them. Run builds one simulated cluster per
+// algorithm, all of the same size, seed and network, plays the identical script against every one of them at
+// the same time, and collects a Report: how many blocks each finalized and how long that took, how many messages
+// it cost, and whether its replicas ever disagreed about a block.
+//
+// Watching Raft stall in a minority partition while PoW miners fork on both sides and reorganize after the heal
+// makes the trade-offs between the algorithms concrete in a way the individual packages cannot, which is what
+// the comparison is for: a lecture shows one table instead of five separate runs. The clusters are the
+// message-driven replicas of package sim, so a comparison is repeatable from its seed and takes no real time.
+package compare
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "math/rand"
+    "slices"
+    "sort"
+    "sync"
+    "time"
+
+    "consensus-algorithms-edu/cost"
+    "consensus-algorithms-edu/modelcheck"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/stats"
+    "consensus-algorithms-edu/trace"
+    "consensus-algorithms-edu/wire"
+)
+
+// ErrUnknownAlgorithm is returned by Run for an algorithm without a message-driven replica.
+var ErrUnknownAlgorithm = errors.New("compare: unknown algorithm")
+
+// DefaultSettle is how long Run lets the clusters run after the last event of the script.
+const DefaultSettle = 5 * time.Second
+
+// Config describes the clusters a script is played against.
+type Config struct {
+    Algorithms []string       // Algorithms to compare; every algorithm of Algorithms() if empty.
+    Nodes      int            // Replicas in each cluster; 4 if 0.
+    Seed       int64          // Seed of every cluster, so each algorithm meets the same latencies and losses.
+    Network    sim.Link       // Default link between replicas; a constant 5ms latency if zero.
+    Gossip     *sim.Gossip    // Spreads broadcasts hop by hop over the network; every message travels a direct link if nil.
+    Discovery  *sim.Discovery // Builds a sparse topology broadcasts spread over; a full mesh if nil.
+    Settle     time.Duration  // Virtual time the clusters run after the last event; DefaultSettle if 0.
+    Cost       bool           // Counts each cluster's hashes and signatures with a cost.Meter, playing the clusters one at a time.
+}
+
+// Algorithms returns the names of the algorithms Run can compare, in alphabetical order: those with a
+// message-driven replica. Paxos only exists as an in-process simulation and cannot take part.
+func Algorithms() []string {
+    names := make([]string, 0, len(trace.Builders))
+    for name := range trace.Builders {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// Run plays script against a cluster of every algorithm in cfg, each in its own goroutine, and reports the
+// results in the order of cfg.Algorithms. With cfg.Cost, the clusters play one after another instead, since a
+// cost.Meter counts the operations of the whole process, and Run fails with cost.ErrActive if another meter is
+// active. It stops early with ctx's error once ctx is done.
+func Run(ctx context.Context, script Script, cfg Config) (*Report, error) {
+    algorithms := cfg.Algorithms
+    if len(algorithms) == 0 {
+        algorithms = Algorithms()
+    }
+    for _, algorithm := range algorithms {
+        if _, ok := trace.Builders[algorithm]; !ok {
+            return nil, fmt.Errorf("%w %q", ErrUnknownAlgorithm, algorithm)
+        }
+    }
+    if cfg.Nodes <= 0 {
+        cfg.Nodes = 4
+    }
+    if cfg.Network.Latency == nil {
+        cfg.Network.Latency = sim.Constant(5 * time.Millisecond)
+    }
+    if cfg.Settle <= 0 {
+        cfg.Settle = DefaultSettle
+    }
+    if err := script.Validate(cfg.Nodes); err != nil {
+        return nil, err
+    }
+
+    report := &Report{Script: script, Nodes: cfg.Nodes, Seed: cfg.Seed, Results: make([]Result, len(algorithms))}
+    errs := make([]error, len(algorithms))
+    var wg sync.WaitGroup
+    for i, algorithm := range algorithms {
+        if cfg.Cost {
+            report.Results[i], errs[i] = play(ctx, algorithm, script, cfg)
+            continue
+        }
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            report.Results[i], errs[i] = play(ctx, algorithm, script, cfg)
+        }()
+    }
+    wg.Wait()
+    if err := errors.Join(errs...); err != nil {
+        return nil, err
+    }
+    return report, nil
+}
+
+// run is the state of one algorithm's cluster while the script plays.
+type run struct {
+    sim       *sim.Simulator
+    replicas  []node.Replica
+    submitted map[string]time.Duration   // Virtual time each transaction was submitted.
+    commits   map[string][]time.Duration // Virtual times at which replicas committed each block, by hash.
+    heights   map[int64]map[string]bool  // Blocks committed at each height, by hash.
+    data      map[string]string          // Data of each committed block, by hash.
+}
+
+// play runs script against a fresh cluster of algorithm.
+func play(ctx context.Context, algorithm string, script Script, cfg Config) (Result, error) {
+    r := &run{
+        sim:       sim.New(sim.Config{Seed: cfg.Seed, Network: cfg.Network, Gossip: cfg.Gossip, Discovery: cfg.Discovery}),
+        submitted: make(map[string]time.Duration),
+        commits:   make(map[string][]time.Duration),
+        heights:   make(map[int64]map[string]bool),
+        data:      make(map[string]string),
+    }
+    build := trace.Builders[algorithm]
+    peers := make([]int32, cfg.Nodes)
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    for _, id := range peers {
+        env := modelcheck.Env{Clock: r.sim.Clock(), Rand: rand.New(rand.NewSource(cfg.Seed ^ int64(id)<<32))}
+        replica := build(id, peers, env)
+        r.replicas = append(r.replicas, replica)
+        r.sim.Add(replica)
+    }
+    r.sim.OnCommit = r.commit
+
+    end := cfg.Settle
+    for _, event := range script {
+        r.sim.At(event.At, func() { r.apply(event) })
+        end = max(end, event.At+cfg.Settle)
+    }
+    var meter *cost.Meter
+    if cfg.Cost {
+        meter = cost.New() // Started once the cluster is built, so the genesis blocks count for nothing.
+        if err := cost.Start(meter); err != nil {
+            return Result{}, err
+        }
+        defer cost.Stop()
+    }
+    start := time.Now()
+    if err := r.sim.RunForContext(ctx, end); err != nil {
+        return Result{}, err
+    }
+    res := r.result(algorithm, time.Since(start))
+    if meter != nil {
+        res.Cost = meter.Counts()
+    }
+    return res, nil
+}
+
+// apply carries out one event of the script, noting when transactions were submitted. A transaction that is
+// refused and submitted again keeps its first time, so the wait counts toward its latency.
+func (r *run) apply(event Event) {
+    if event.Kind == Submit {
+        r.submitted[event.Data] = r.sim.Now()
+    }
+    event.Apply(r.sim)
+}
+
+// commit records a block a replica committed.
+func (r *run) commit(id int32, block *wire.Block) {
+    r.commits[block.GetHash()] = append(r.commits[block.GetHash()], r.sim.Now())
+    if r.heights[block.GetIndex()] == nil {
+        r.heights[block.GetIndex()] = make(map[string]bool)
+    }
+    r.heights[block.GetIndex()][block.GetHash()] = true
+    r.data[block.GetHash()] = block.GetData()
+}
+
+// result summarizes the run. A block counts as finalized once a majority of the replicas committed it, and
+// only as part of an unbroken prefix of such blocks, since a block is worth nothing without its ancestors.
+// Finalized blocks also count the empty ones PoS and DPoS seal in every slot; Confirmed counts only those that
+// carry a submitted transaction.
+func (r *run) result(algorithm string, wall time.Duration) Result {
+    res := Result{Algorithm: algorithm, Submitted: len(r.submitted), Messages: r.sim.Stats(), Wall: wall}
+    majority := len(r.replicas)/2 + 1
+    var latencies []time.Duration
+    for height := int64(1); ; height++ {
+        hash := r.agreed(height, majority)
+        if hash == "" {
+            break
+        }
+        times := slices.Sorted(slices.Values(r.commits[hash]))
+        finalized := times[majority-1] // When the majority was reached.
+        res.Finalized++
+        res.Time = max(res.Time, finalized)
+        if submitted, ok := r.submitted[r.data[hash]]; ok {
+            res.Confirmed++
+            latencies = append(latencies, finalized-submitted)
+        }
+    }
+    res.Latency = stats.Summarize(latencies)
+    for _, replica := range r.replicas {
+        res.Height = max(res.Height, len(replica.Committed())-1)
+    }
+    for height := range r.heights {
+        if len(r.heights[height]) > 1 {
+            res.Divergence++
+        }
+    }
+    return res
+}
+
+// agreed returns the hash of the block a majority of the replicas committed at height, or "" if none did.
+func (r *run) agreed(height int64, majority int) string {
+    for hash := range r.heights[height] {
+        if len(r.commits[hash]) >= majority {
+            return hash
+        }
+    }
+    return ""
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Message-Driven Replicas Only**: The comparison runs the replicas of package sim rather than the engines,
+//    because faults only mean something where messages travel: a partition cannot split an in-process engine.
+//    Messages are counted by the simulator and are comparable across algorithms for the same reason.
+//
+// 2. **One Seed for Every Cluster**: Each cluster gets the same seed and network, so the latencies and losses
+//    differ only where the algorithms send different messages. A difference in the report is a difference
+//    between the algorithms, not between two rolls of the dice.
+//
+// 3. **Majority Finality**: A block is counted once a majority of replicas committed it. Requiring every
+//    replica would make a single isolated node zero out every algorithm's score, and a lecture wants to see
+//    that Raft carries on without it while a partitioned PBFT group does not.
+//
+// 4. **Divergence Over Time**: Divergence counts heights at which replicas ever committed different blocks, not
+//    only those still in conflict at the end. A PoW fork that was later resolved by a reorganization is exactly
+//    what the comparison should surface next to the finality-providing algorithms that never fork.
@@ -0,0 +1,68 @@
+package compare
+
+import (
+    "fmt"
+    "io"
+    "text/tabwriter"
+    "time"
+
+    "consensus-algorithms-edu/cost"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/stats"
+)
+
+// Report is the outcome of a comparison.
+type Report struct {
+    Script  Script   // Events every cluster went through.
+    Nodes   int      // Replicas in each cluster.
+    Seed    int64    // Seed every cluster was built with.
+    Results []Result // One result per algorithm, in the order they were asked for.
+}
+
+// Result is how one algorithm fared.
+type Result struct {
+    Algorithm  string
+    Submitted  int           // Transactions the script submitted.
+    Finalized  int           // Blocks after the genesis block committed by a majority of the replicas.
+    Confirmed  int           // Submitted transactions carried by a finalized block.
+    Height     int           // Highest block any replica committed, finalized or not.
+    Time       time.Duration // Virtual time at which the last finalized block reached its majority.
+    Latency    stats.Latency // Virtual time from submitting each confirmed transaction to its finalization.
+    Messages   sim.Stats     // Messages the replicas sent, and what the network did with them.
+    Divergence int           // Heights at which two replicas committed different blocks at some point.
+    Wall       time.Duration // Real time the simulation took.
+    Cost       cost.Counts   // Hashes and signatures the replicas computed; zero unless Config.Cost was set.
+}
+
+// Print writes the report as a table, one row per algorithm.
+func (r *Report) Print(w io.Writer) error {
+    fmt.Fprintf(w, "%d events, %d replicas per cluster, seed %d\n\n", len(r.Script), r.Nodes, r.Seed)
+    tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintln(tw, "algorithm\tsubmitted\tconfirmed\tfinalized\theight\ttime\tp50\tp99\tmessages\tdropped\tdivergence\twall\t")
+    for _, res := range r.Results {
+        fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%v\t%v\t%v\t%d\t%d\t%d\t%v\t\n", res.Algorithm, res.Submitted, res.Confirmed, res.Finalized, res.Height,
+            res.Time.Round(time.Millisecond), res.Latency.Percentile(50).Round(time.Millisecond), res.Latency.Percentile(99).Round(time.Millisecond), res.Messages.Sent, res.Messages.Dropped, res.Divergence, res.Wall.Round(time.Microsecond))
+    }
+    return tw.Flush()
+}
+
+// PrintCost writes the cost profile of the comparison as a table: the hashes, messages and signatures each
+// algorithm spent per finalized block, read from Result.Cost and Result.Messages. It needs a report of a run with
+// Config.Cost.
+func (r *Report) PrintCost(w io.Writer) error {
+    tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintln(tw, "algorithm\tfinalized\thashes/block\tmessages/block\tsignatures/block\tverifications/block\t")
+    for _, res := range r.Results {
+        fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t\n", res.Algorithm, res.Finalized, perBlock(res.Cost.Hashes, res.Finalized),
+            perBlock(int64(res.Messages.Sent), res.Finalized), perBlock(res.Cost.Signatures, res.Finalized), perBlock(res.Cost.Verifications, res.Finalized))
+    }
+    return tw.Flush()
+}
+
+// perBlock writes n divided among blocks, or "-" if no block was finalized.
+func perBlock(n int64, blocks int) string {
+    if blocks == 0 {
+        return "-"
+    }
+    return fmt.Sprintf("%.1f", float64(n)/float64(blocks))
+}
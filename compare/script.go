@@ -0,0 +1,248 @@
+package compare
+
+import (
+    "bufio"
+    "cmp"
+    "fmt"
+    "io"
+    "slices"
+    "strconv"
+    "strings"
+    "time"
+
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+)
+
+// Kind is what an event of a script does.
+type Kind int
+
+const (
+    Submit    Kind = iota // Submit a transaction to the leader, or to replica 0 if the algorithm has none.
+    Partition             // Split the replicas into groups that cannot reach each other.
+    Isolate               // Cut one replica off from all the others, as if it had crashed.
+    Lossy                 // Lose a share of all messages.
+    Heal                  // Restore every cut link and end the message loss.
+)
+
+// kinds names every Kind as it is written in a script file.
+var kinds = []string{"submit", "partition", "isolate", "lossy", "heal"}
+
+// String returns the name of the kind as it is written in a script file, e.g. "isolate".
+func (k Kind) String() string {
+    if k < 0 || int(k) >= len(kinds) {
+        return "unknown"
+    }
+    return kinds[k]
+}
+
+// Event is one step of a script, carried out at virtual time At.
+type Event struct {
+    At     time.Duration // Virtual time from the start of the run.
+    Kind   Kind
+    Data   string    // Submit: the transaction.
+    Groups [][]int32 // Partition: the groups of replicas that keep talking among themselves.
+    Node   int32     // Isolate: the replica cut off.
+    Rate   float64   // Lossy: the probability in [0, 1] that a message is lost.
+}
+
+// String writes the event as a line of a script file, e.g. "2s partition 0,1 | 2,3".
+func (e Event) String() string {
+    line := e.At.String() + " " + e.Kind.String()
+    switch e.Kind {
+    case Submit:
+        line += " " + e.Data
+    case Partition:
+        groups := make([]string, len(e.Groups))
+        for i, group := range e.Groups {
+            ids := make([]string, len(group))
+            for j, id := range group {
+                ids[j] = strconv.Itoa(int(id))
+            }
+            groups[i] = strings.Join(ids, ",")
+        }
+        line += " " + strings.Join(groups, " | ")
+    case Isolate:
+        line += " " + strconv.Itoa(int(e.Node))
+    case Lossy:
+        line += " " + strconv.FormatFloat(e.Rate, 'g', -1, 64)
+    }
+    return line
+}
+
+// retryInterval is how long a refused transaction waits before it is submitted again: one tick of a replica.
+const retryInterval = 10 * time.Millisecond
+
+// Apply carries out the event on a simulation now: a transaction is submitted to the leader most replicas
+// follow, or to replica 0 for algorithms without one, and faults change s.Network. A transaction the replica
+// refuses, such as while no leader has been elected, is submitted again every retryInterval until one takes it,
+// as a client would.
+func (e Event) Apply(s *sim.Simulator) {
+    network := s.Network
+    switch e.Kind {
+    case Submit:
+        e.submit(s)
+    case Partition:
+        network.Partition(e.Groups...)
+    case Isolate:
+        for _, other := range s.Nodes() {
+            if other != e.Node {
+                network.Disconnect(e.Node, other)
+            }
+        }
+    case Lossy:
+        network.Inject(sim.Fault{Drop: e.Rate})
+    case Heal:
+        network.Heal()
+        network.ClearFaults()
+    }
+}
+
+// submit proposes the transaction to Target, and again every retryInterval for as long as it is refused.
+func (e Event) submit(s *sim.Simulator) {
+    if s.Propose(Target(s), e.Data) != nil {
+        s.At(s.Now()+retryInterval, func() { e.submit(s) })
+    }
+}
+
+// Target returns the replica transactions are submitted to: the leader most replicas of s follow, for
+// algorithms that have one, and replica 0 otherwise.
+func Target(s *sim.Simulator) int32 {
+    votes := make(map[int32]int)
+    for _, id := range s.Nodes() {
+        if l, ok := s.Replica(id).(node.Leaderful); ok && l.Leader() >= 0 {
+            votes[l.Leader()]++
+        }
+    }
+    target, best := int32(0), 0
+    for leader, n := range votes {
+        if n > best || (n == best && leader < target) {
+            target, best = leader, n
+        }
+    }
+    return target
+}
+
+// Script is the sequence of events a comparison plays, in order of time.
+type Script []Event
+
+// Transactions returns a script that submits n transactions, "Transaction 1" to "Transaction n", one every
+// interval, starting after the first interval so leaders have been elected.
+func Transactions(n int, interval time.Duration) Script {
+    script := make(Script, n)
+    for i := range script {
+        script[i] = Event{At: time.Duration(i+1) * interval, Kind: Submit, Data: fmt.Sprintf("Transaction %d", i+1)}
+    }
+    return script
+}
+
+// With returns the events of s and more, sorted by time. Events at the same time keep their order, with those
+// of s first, so a fault can be scheduled at the same moment as a transaction and still follow it.
+func (s Script) With(more ...Event) Script {
+    merged := append(slices.Clone(s), more...)
+    slices.SortStableFunc(merged, func(a, b Event) int { return cmp.Compare(a.At, b.At) })
+    return merged
+}
+
+// Validate reports the first event that cannot be played against a cluster of n replicas: one that names a
+// replica outside it, a loss rate outside [0, 1], a negative time, or events out of order.
+func (s Script) Validate(n int) error {
+    for i, e := range s {
+        switch {
+        case e.At < 0:
+            return fmt.Errorf("compare: event %d (%v) is at a negative time", i+1, e)
+        case i > 0 && e.At < s[i-1].At:
+            return fmt.Errorf("compare: event %d (%v) comes before the event preceding it", i+1, e)
+        case e.Kind == Isolate && (e.Node < 0 || int(e.Node) >= n):
+            return fmt.Errorf("compare: event %d (%v) isolates replica %d of %d", i+1, e, e.Node, n)
+        case e.Kind == Lossy && (e.Rate < 0 || e.Rate > 1):
+            return fmt.Errorf("compare: event %d (%v) has a loss rate outside [0, 1]", i+1, e)
+        }
+        for _, group := range e.Groups {
+            for _, id := range group {
+                if id < 0 || int(id) >= n {
+                    return fmt.Errorf("compare: event %d (%v) names replica %d of %d", i+1, e, id, n)
+                }
+            }
+        }
+    }
+    return nil
+}
+
+// ParseScript reads a script written one event per line, as Event.String writes them: a virtual time in Go
+// duration syntax, the kind of event, and its argument. Blank lines and lines starting with # are skipped, and
+// the events are sorted by time.
+//
+//  # Cut the leader off for two seconds in the middle of the run.
+//  500ms submit Transaction 1
+//  1s    isolate 0
+//  1.5s  submit Transaction 2
+//  3s    heal
+//  3.5s  partition 0,1 | 2,3
+//  4s    lossy 0.2
+func ParseScript(r io.Reader) (Script, error) {
+    var script Script
+    scanner := bufio.NewScanner(r)
+    for line := 1; scanner.Scan(); line++ {
+        text := strings.TrimSpace(scanner.Text())
+        if text == "" || strings.HasPrefix(text, "#") {
+            continue
+        }
+        event, err := ParseEvent(text)
+        if err != nil {
+            return nil, fmt.Errorf("compare: line %d: %w", line, err)
+        }
+        script = append(script, event)
+    }
+    if err := scanner.Err(); err != nil {
+        return nil, fmt.Errorf("compare: read script: %w", err)
+    }
+    return Script(nil).With(script...), nil
+}
+
+// ParseEvent parses one line of a script, such as "1s isolate 0".
+func ParseEvent(text string) (Event, error) {
+    fields := strings.Fields(text)
+    if len(fields) < 2 {
+        return Event{}, fmt.Errorf("want a time and an event, got %q", text)
+    }
+    at, err := time.ParseDuration(fields[0])
+    if err != nil {
+        return Event{}, err
+    }
+    event := Event{At: at, Kind: Kind(slices.Index(kinds, fields[1]))}
+    arg := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(text, fields[0])), fields[1]))
+    switch event.Kind {
+    case Submit:
+        if arg == "" {
+            return Event{}, fmt.Errorf("submit needs a transaction")
+        }
+        event.Data = arg
+    case Partition:
+        for _, part := range strings.Split(arg, "|") {
+            var group []int32
+            for _, field := range strings.Split(part, ",") {
+                id, err := strconv.Atoi(strings.TrimSpace(field))
+                if err != nil {
+                    return Event{}, fmt.Errorf("partition groups are replica numbers separated by commas and groups by |, got %q", arg)
+                }
+                group = append(group, int32(id))
+            }
+            event.Groups = append(event.Groups, group)
+        }
+    case Isolate:
+        id, err := strconv.Atoi(arg)
+        if err != nil {
+            return Event{}, fmt.Errorf("isolate needs a replica number, got %q", arg)
+        }
+        event.Node = int32(id)
+    case Lossy:
+        if event.Rate, err = strconv.ParseFloat(arg, 64); err != nil {
+            return Event{}, fmt.Errorf("lossy needs a loss rate, got %q", arg)
+        }
+    case Heal:
+    default:
+        return Event{}, fmt.Errorf("unknown event %q, want one of %s", fields[1], strings.Join(kinds, ", "))
+    }
+    return event, nil
+}
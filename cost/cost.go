@@ -0,0 +1,112 @@
+// Package cost counts the operations that make consensus expensive, so the algorithms can be compared by what
+// they spend rather than by how long a simulation takes. Proof of Work spends hash evaluations, searching for a
+// nonce; Byzantine fault tolerant protocols such as PBFT spend messages and the signatures that authenticate
+// them; Proof of Stake and Delegated Proof of Stake spend a signature per block and little else. Code that
+// performs such an operation marks it with Count, and the active Meter adds it up.
+//
+// Package wire and package trie mark every hash of a block header, block body or trie node, and package
+// evidence marks the signatures it makes and checks. The replicas of this repository trust the sender named by
+// the simulated network instead of signing their messages, so PBFT, PoS and DPoS mark the signatures a deployed
+// implementation makes at the same points: PBFT signs every protocol message it broadcasts and verifies every
+// one it receives, and a PoS validator or DPoS delegate signs every block it produces, which every peer verifies.
+// Raft assumes crash faults and authenticated channels, and a PoW block is authenticated by its work, so neither
+// signs anything. Messages are counted by the simulator itself (see sim.Stats), and compare.Config.Cost puts the
+// counts side by side per committed block.
+package cost
+
+import (
+    "errors"
+    "sync/atomic"
+)
+
+// ErrActive is returned by Start if a Meter is already active.
+var ErrActive = errors.New("cost: a meter is already active")
+
+// Operation is a kind of work a Meter counts.
+type Operation string
+
+// The operations this repository marks.
+const (
+    Hash   Operation = "hash"   // One evaluation of a hash function, such as SHA-256 over a block header.
+    Sign   Operation = "sign"   // Creating a signature.
+    Verify Operation = "verify" // Checking a signature.
+)
+
+// Counts is the number of operations of each kind a Meter counted.
+type Counts struct {
+    Hashes        int64
+    Signatures    int64
+    Verifications int64
+}
+
+// Meter adds up the operations marked while it is active. It is safe for concurrent use, but counts the
+// operations of the whole process: two simulations running at once while it is active share its counts.
+type Meter struct {
+    hashes        atomic.Int64
+    signatures    atomic.Int64
+    verifications atomic.Int64
+}
+
+// New returns a meter that has counted nothing.
+func New() *Meter {
+    return &Meter{}
+}
+
+// active is the meter the operations of this process are counted by, or nil if none is.
+var active atomic.Pointer[Meter]
+
+// Start makes m the meter every operation marked from now on is counted by, until Stop. Only one meter is active
+// at a time; ErrActive is returned if another one is.
+func Start(m *Meter) error {
+    if !active.CompareAndSwap(nil, m) {
+        return ErrActive
+    }
+    return nil
+}
+
+// Stop deactivates the active meter, if any.
+func Stop() {
+    active.Store(nil)
+}
+
+// Count marks n operations of a kind. It does nothing while no meter is active, beyond an atomic load.
+func Count(op Operation, n int) {
+    m := active.Load()
+    if m == nil {
+        return
+    }
+    switch op {
+    case Hash:
+        m.hashes.Add(int64(n))
+    case Sign:
+        m.signatures.Add(int64(n))
+    case Verify:
+        m.verifications.Add(int64(n))
+    }
+}
+
+// Counts returns the operations counted so far.
+func (m *Meter) Counts() Counts {
+    return Counts{Hashes: m.hashes.Load(), Signatures: m.signatures.Load(), Verifications: m.verifications.Load()}
+}
+
+// Reset forgets the operations counted so far.
+func (m *Meter) Reset() {
+    m.hashes.Store(0)
+    m.signatures.Store(0)
+    m.verifications.Store(0)
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Counts, Not Time**: How long a simulation takes to hash says more about the machine running it than about
+//    the algorithm; package profile answers that question. The number of hashes, messages and signatures per
+//    committed block is a property of the algorithm, and the one that decides its energy and bandwidth bill.
+//
+// 2. **One Active Meter**: Hashes are evaluated deep inside package wire, which cannot be handed a meter by every
+//    caller, so a process-wide active meter counts them, as the active profiler of package profile does. Marking
+//    an operation costs one atomic load while nothing is measured.
+//
+// 3. **Modeled Signatures**: Signing every simulated message would slow every run down for no change in
+//    behavior, since the simulated network cannot forge senders. The replicas mark the signatures they would
+//    make instead, and the count is the same as if they had made them.
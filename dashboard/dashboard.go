@@ -0,0 +1,40 @@
+// Package dashboard serves a live web view of a running simulation.
+// The page is a single self-contained HTML file embedded in the binary. It shows every node with its role, the
+// current leader or primary, the messages nodes exchange as they vote and elect, and the chain growing block by
+// block. It reads the simulation through the HTTP API of the api package and follows it in real time through the
+// WebSocket event stream, so it works with any engine and needs no JavaScript tooling or assets on disk.
+package dashboard
+
+import (
+    _ "embed"
+    "net/http"
+)
+
+//go:embed index.html
+var page []byte
+
+// Handler returns a handler that serves the dashboard page. The page requests /status, /participants, /blocks,
+// /events and /submit relative to its own URL, so mount it next to an api.Server with the event stream on
+// /events, for example at "GET /{$}" of the same server.
+func Handler() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "text/html; charset=utf-8")
+        w.Header().Set("Cache-Control", "no-cache")
+        w.Write(page)
+    })
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **A Client of the Public API**: The page uses only the routes any other tool can use: the JSON endpoints for
+//    snapshots and the event stream for changes. Nothing in the dashboard needs access to engine internals, so
+//    every feature it shows is also available to scripts, and a simulation served by another process can be
+//    watched the same way.
+//
+// 2. **Embedded, Single File**: The HTML, styles and script live in one file compiled into the binary with
+//    go:embed. There is no build step and nothing to install, which matters for a teaching tool that students
+//    run with `go run`.
+//
+// 3. **Snapshots Plus Events**: Events may be dropped when the browser falls behind, so the page treats them as
+//    hints: it animates them as they arrive and re-reads the status, participants and chain after commits and
+//    leader changes. A dropped event can delay the display, but never leave it wrong.
@@ -0,0 +1,112 @@
+package economics
+
+import (
+    "encoding/csv"
+    "fmt"
+    "io"
+    "slices"
+    "strconv"
+    "text/tabwriter"
+)
+
+// Report is the outcome of the runs.
+type Report struct {
+    Config  Config   // The runs, with defaults filled in.
+    Results []Result // One result per scheme, reward and commission, scheme by scheme.
+}
+
+// Result is how stake moved over one run.
+type Result struct {
+    Scheme     Scheme
+    Reward     float64  // Reward per block, as a share of the stake at the start.
+    Commission float64  // Share of each reward its producer kept.
+    Samples    []Sample // The distribution before the first block and every Config.Every blocks after.
+}
+
+// Sample is the distribution of stake after a number of blocks.
+type Sample struct {
+    Block         int     // Blocks produced so far.
+    Total         int     // Stake of everyone together.
+    Gini          float64 // Gini coefficient of every holder's stake, validators and delegators alike.
+    ValidatorGini float64 // Gini coefficient of the stake behind each validator, its own and its delegators'.
+    TopShare      float64 // Share of all stake behind the validator with the most.
+    SelfShare     float64 // Share of all stake that validators hold themselves rather than their delegators.
+}
+
+// sample measures the distribution of stake after block.
+func (e *economy) sample(block int) Sample {
+    holders := make([]float64, 0, len(e.self)+len(e.delegators))
+    backings := make([]float64, len(e.self))
+    total, self := 0, 0
+    for v, stake := range e.self {
+        holders = append(holders, float64(stake))
+        backings[v] = float64(e.backing(v))
+        self += stake
+    }
+    for _, d := range e.delegators {
+        holders = append(holders, float64(d.stake))
+    }
+    for _, b := range backings {
+        total += int(b)
+    }
+    return Sample{
+        Block:         block,
+        Total:         total,
+        Gini:          Gini(holders),
+        ValidatorGini: Gini(backings),
+        TopShare:      slices.Max(backings) / float64(total),
+        SelfShare:     float64(self) / float64(total),
+    }
+}
+
+// Gini returns the Gini coefficient of values: 0 if they are all equal, approaching 1 as one of them holds
+// everything. It returns 0 for no values or values that sum to 0.
+func Gini(values []float64) float64 {
+    sorted := slices.Clone(values)
+    slices.Sort(sorted)
+    var sum, weighted float64
+    for i, v := range sorted {
+        sum += v
+        weighted += float64(i+1) * v
+    }
+    if sum == 0 {
+        return 0
+    }
+    n := float64(len(sorted))
+    return 2*weighted/(n*sum) - (n+1)/n
+}
+
+// Print writes the report as a table with a row per run, comparing the distribution at its start and end.
+func (r *Report) Print(w io.Writer) error {
+    fmt.Fprintf(w, "%d validators, %d delegators, %d DPoS seats, %d blocks, validators holding %.0f%% themselves, seed %d\n\n", r.Config.Validators, r.Config.Delegators, r.Config.Seats, r.Config.Blocks, 100*r.Config.SelfStake, r.Config.Seed)
+    tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintln(tw, "scheme\treward\tcommission\tstake\tgini\tvalidator gini\ttop validator\tvalidators' own\t")
+    for _, res := range r.Results {
+        first, last := res.Samples[0], res.Samples[len(res.Samples)-1]
+        fmt.Fprintf(tw, "%s\t%.4f%%\t%.0f%%\t%.2fx\t%.3f → %.3f\t%.3f → %.3f\t%.1f%% → %.1f%%\t%.1f%% → %.1f%%\t\n", res.Scheme, 100*res.Reward, 100*res.Commission,
+            float64(last.Total)/float64(first.Total), first.Gini, last.Gini, first.ValidatorGini, last.ValidatorGini, 100*first.TopShare, 100*last.TopShare, 100*first.SelfShare, 100*last.SelfShare)
+    }
+    return tw.Flush()
+}
+
+// WriteCSV writes every sample of every run as CSV, one row per sample with the run's parameters, under a
+// header row, ready for a spreadsheet or a plotting library.
+func (r *Report) WriteCSV(w io.Writer) error {
+    out := csv.NewWriter(w)
+    out.Write([]string{"scheme", "reward", "commission", "block", "total", "gini", "validator_gini", "top_share", "self_share"})
+    for _, res := range r.Results {
+        for _, s := range res.Samples {
+            out.Write([]string{
+                string(res.Scheme), formatFloat(res.Reward), formatFloat(res.Commission), strconv.Itoa(s.Block), strconv.Itoa(s.Total),
+                formatFloat(s.Gini), formatFloat(s.ValidatorGini), formatFloat(s.TopShare), formatFloat(s.SelfShare),
+            })
+        }
+    }
+    out.Flush()
+    return out.Error()
+}
+
+// formatFloat writes a float in the shortest form that reads back the same.
+func formatFloat(f float64) string {
+    return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,632 @@
+package engine
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "maps"
+    "slices"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+
+    "consensus-algorithms-edu/algorithms/dpos"
+    "consensus-algorithms-edu/algorithms/paxos"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/wire"
+)
+
+// toWire converts a chain of any algorithm's blocks into wire blocks.
+func toWire[B any, P interface {
+    *B
+    ToWire() *wire.Block
+}](blocks []B) []*wire.Block {
+    out := make([]*wire.Block, len(blocks))
+    for i := range blocks {
+        out[i] = P(&blocks[i]).ToWire()
+    }
+    return out
+}
+
+// rejected wraps err in ErrRejected if it is one of the causes by which an algorithm reports that the network did
+// not agree, so callers can tell a refusal from a failure such as a broken block store, which is returned as is.
+func rejected(err error, causes ...error) error {
+    for _, cause := range causes {
+        if errors.Is(err, cause) {
+            return fmt.Errorf("%w: %w", ErrRejected, err)
+        }
+    }
+    return err
+}
+
+// nodeID parses the participant identifier of a Raft, PBFT or Paxos node, e.g. "node-3".
+func nodeID(id string) (int, error) {
+    n, err := strconv.Atoi(strings.TrimPrefix(id, "node-"))
+    if err != nil || !strings.HasPrefix(id, "node-") {
+        return 0, fmt.Errorf("%w: %q", node.ErrUnknownNode, id)
+    }
+    return n, nil
+}
+
+// powEngine wraps a Proof of Work chain with a single miner.
+type powEngine struct {
+    mu    sync.Mutex
+    chain *pow.Blockchain
+    subscriptions[pow.Block, *pow.Block] // Subscribe and Unsubscribe, fed by chain.
+}
+
+func newPoW(cfg Config) Engine {
+    chain := pow.NewBlockchain(cfg.networkOptions()...)
+    if g := cfg.Genesis; g != nil {
+        chain.Difficulty = g.Difficulty
+        chain.Blocks[0] = pow.NewBlockWithDifficulty(g.GenesisData(), wire.Hash{}, 0, g.Difficulty, g.Timestamp)
+    }
+    chain.AttachClock(cfg.Clock)
+    chain.AttachLogger(cfg.Logger)
+    if cfg.Ledger != nil {
+        chain.AttachState(cfg.Ledger) // The chain holds only its genesis block, which carries no transactions.
+    }
+    return &powEngine{chain: chain, subscriptions: subscriptions[pow.Block, *pow.Block]{chain: chain}}
+}
+
+func (e *powEngine) Algorithm() string { return "pow" }
+
+func (e *powEngine) Submit(ctx context.Context, data string) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    _, err := e.chain.AddBlockContext(ctx, data)
+    return rejected(err, ledger.ErrInvalid)
+}
+
+func (e *powEngine) Export(w io.Writer) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return e.chain.Export(w)
+}
+
+func (e *powEngine) importFrom(r io.Reader) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return e.chain.Import(r)
+}
+
+func (e *powEngine) Blocks() []*wire.Block {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return toWire(e.chain.Blocks)
+}
+
+func (e *powEngine) Status() Status {
+    return statusOf("pow", e.finality(), 1, "")
+}
+
+func (e *powEngine) Participants() []Participant {
+    return []Participant{{ID: "miner", Role: "miner"}}
+}
+
+func (e *powEngine) GetReceipt(tx string) (ledger.Receipt, bool) {
+    return e.chain.GetReceipt(tx)
+}
+
+// posEngine wraps a Proof of Stake chain whose validators hold increasing stakes, unless a genesis spec lists them.
+type posEngine struct {
+    mu     sync.Mutex
+    chain  *pos.Blockchain
+    former []Participant // Validators removed by RemoveNode, with the stake they had.
+    subscriptions[pos.Block, *pos.Block] // Subscribe and Unsubscribe, fed by chain.
+}
+
+func newPoS(cfg Config) Engine {
+    validators := make([]string, cfg.Nodes)
+    stakes := make(map[string]int)
+    for i := range validators {
+        validators[i] = fmt.Sprintf("validator-%d", i)
+        stakes[validators[i]] = 10 * (i + 1) // Unequal stakes make the weighted selection visible.
+    }
+    g := cfg.Genesis
+    if g != nil && len(g.Validators) > 0 {
+        validators, stakes = g.Stakes()
+    }
+    chain := pos.NewBlockchain(validators, stakes, cfg.networkOptions()...)
+    if g != nil {
+        chain.Blocks[0] = pos.NewBlockAt(g.GenesisData(), wire.Hash{}, 0, validators[0], g.Timestamp)
+    }
+    chain.AttachClock(cfg.Clock)
+    chain.AttachLogger(cfg.Logger)
+    if cfg.Ledger != nil {
+        chain.AttachState(cfg.Ledger) // The chain holds only its genesis block, which carries no transactions.
+    }
+    chain.AttachEvents(cfg.Events)
+    return &posEngine{chain: chain, subscriptions: subscriptions[pos.Block, *pos.Block]{chain: chain}}
+}
+
+func (e *posEngine) Algorithm() string { return "pos" }
+
+func (e *posEngine) Submit(ctx context.Context, data string) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    _, err := e.chain.AddBlockContext(ctx, data)
+    return rejected(err, pos.ErrNoStake, ledger.ErrInvalid)
+}
+
+func (e *posEngine) AddNode(ctx context.Context) (Participant, error) {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    i := len(e.chain.Validators)
+    for slices.Contains(e.chain.Validators, fmt.Sprintf("validator-%d", i)) {
+        i++
+    }
+    validator, stake := fmt.Sprintf("validator-%d", i), 10*(i+1) // Stakes keep growing as in newPoS.
+    if err := e.chain.AddValidator(validator, stake); err != nil {
+        return Participant{}, err
+    }
+    return Participant{ID: validator, Role: "validator", Stake: stake}, nil
+}
+
+func (e *posEngine) RemoveNode(ctx context.Context, id string) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    stake := e.chain.Stakes[id]
+    if err := e.chain.RemoveValidator(id); err != nil {
+        return err
+    }
+    e.former = append(e.former, Participant{ID: id, Role: "validator", Stake: stake})
+    return nil
+}
+
+func (e *posEngine) Former() []Participant {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return slices.Clone(e.former)
+}
+
+func (e *posEngine) Export(w io.Writer) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return e.chain.Export(w)
+}
+
+func (e *posEngine) importFrom(r io.Reader) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return e.chain.Import(r)
+}
+
+func (e *posEngine) Blocks() []*wire.Block {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return toWire(e.chain.Blocks)
+}
+
+func (e *posEngine) Status() Status {
+    return statusOf("pos", e.finality(), len(e.Participants()), "")
+}
+
+func (e *posEngine) Participants() []Participant {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    participants := make([]Participant, len(e.chain.Validators))
+    for i, validator := range e.chain.Validators {
+        participants[i] = Participant{ID: validator, Role: "validator", Stake: e.chain.Stakes[validator]}
+    }
+    return participants
+}
+
+func (e *posEngine) GetReceipt(tx string) (ledger.Receipt, bool) {
+    return e.chain.GetReceipt(tx)
+}
+
+// dposEngine wraps a Delegated Proof of Stake chain. Unless a genesis spec casts the votes, every delegate
+// starts with one vote, cast by a voter of the same number, so the delegate set is non-empty from the start.
+type dposEngine struct {
+    mu     sync.Mutex
+    chain  *dpos.Blockchain
+    former []Participant // Delegates removed by RemoveNode.
+    subscriptions[dpos.Block, *dpos.Block] // Subscribe and Unsubscribe, fed by chain.
+}
+
+func newDPoS(cfg Config) Engine {
+    delegates := make([]string, cfg.Nodes)
+    for i := range delegates {
+        delegates[i] = fmt.Sprintf("delegate-%d", i)
+    }
+    g := cfg.Genesis
+    if g != nil && len(g.Delegates) > 0 {
+        delegates = g.Delegates
+    }
+    chain := dpos.NewBlockchain(delegates, make(map[string]string), cfg.networkOptions()...)
+    if g != nil {
+        chain.Blocks[0] = dpos.NewBlockAt(g.GenesisData(), wire.Hash{}, 0, delegates[0], g.Timestamp)
+    }
+    if g != nil && len(g.Votes) > 0 {
+        for _, voter := range slices.Sorted(maps.Keys(g.Votes)) {
+            chain.Vote(voter, g.Votes[voter]) // Sorted so the votes are cast, and published, in the same order every run.
+        }
+    } else {
+        for i, delegate := range delegates {
+            chain.Vote(fmt.Sprintf("voter-%d", i), delegate)
+        }
+    }
+    chain.CountVotes()
+    chain.AttachClock(cfg.Clock)
+    chain.AttachLogger(cfg.Logger)
+    chain.AttachEvents(cfg.Events)
+    return &dposEngine{chain: chain, subscriptions: subscriptions[dpos.Block, *dpos.Block]{chain: chain}}
+}
+
+func (e *dposEngine) Algorithm() string { return "dpos" }
+
+func (e *dposEngine) Submit(ctx context.Context, data string) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    _, err := e.chain.AddBlockContext(ctx, data)
+    return rejected(err, dpos.ErrNoDelegates)
+}
+
+func (e *dposEngine) AddNode(ctx context.Context) (Participant, error) {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    i := len(e.chain.Delegates)
+    for {
+        _, voted := e.chain.Voters[fmt.Sprintf("voter-%d", i)]
+        if !voted && !slices.Contains(e.chain.Delegates, fmt.Sprintf("delegate-%d", i)) {
+            break
+        }
+        i++
+    }
+    // A new voter elects the new delegate, as every delegate is elected in newDPoS.
+    delegate := fmt.Sprintf("delegate-%d", i)
+    e.chain.Vote(fmt.Sprintf("voter-%d", i), delegate)
+    e.chain.CountVotes()
+    return Participant{ID: delegate, Role: "delegate", Votes: 1}, nil
+}
+
+func (e *dposEngine) RemoveNode(ctx context.Context, id string) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    if err := e.chain.RemoveDelegate(id); err != nil {
+        return err
+    }
+    e.former = append(e.former, Participant{ID: id, Role: "delegate"})
+    return nil
+}
+
+func (e *dposEngine) Former() []Participant {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return slices.Clone(e.former)
+}
+
+func (e *dposEngine) Export(w io.Writer) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return e.chain.Export(w)
+}
+
+func (e *dposEngine) importFrom(r io.Reader) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return e.chain.Import(r)
+}
+
+func (e *dposEngine) Blocks() []*wire.Block {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return toWire(e.chain.Blocks)
+}
+
+func (e *dposEngine) Status() Status {
+    return statusOf("dpos", e.finality(), len(e.Participants()), "")
+}
+
+func (e *dposEngine) Participants() []Participant {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    votes := make(map[string]int)
+    for _, delegate := range e.chain.Voters {
+        votes[delegate]++
+    }
+    participants := make([]Participant, len(e.chain.Delegates))
+    for i, delegate := range e.chain.Delegates {
+        participants[i] = Participant{ID: delegate, Role: "delegate", Votes: votes[delegate]}
+    }
+    sort.Slice(participants, func(i, j int) bool { return participants[i].ID < participants[j].ID })
+    return participants
+}
+
+// pbftEngine wraps a PBFT network whose first node is the primary.
+type pbftEngine struct {
+    mu    sync.Mutex
+    chain *pbft.Blockchain
+    subscriptions[pbft.Block, *pbft.Block] // Subscribe and Unsubscribe, fed by chain.
+}
+
+func newPBFT(cfg Config) Engine {
+    chain := pbft.NewPBFTNetwork(cfg.networkOptions()...)
+    if g := cfg.Genesis; g != nil {
+        chain.Blocks[0] = pbft.NewBlockAt(g.GenesisData(), wire.Hash{}, 0, g.Timestamp)
+    }
+    chain.AttachClock(cfg.Clock)
+    chain.AttachLogger(cfg.Logger)
+    if cfg.Ledger != nil {
+        chain.AttachState(cfg.Ledger) // The chain holds only its genesis block, which carries no transactions.
+    }
+    chain.AttachEvents(cfg.Events)
+    return &pbftEngine{chain: chain, subscriptions: subscriptions[pbft.Block, *pbft.Block]{chain: chain}}
+}
+
+func (e *pbftEngine) Algorithm() string { return "pbft" }
+
+func (e *pbftEngine) Submit(ctx context.Context, data string) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    _, err := e.chain.RunPBFTContext(ctx, data)
+    return rejected(err, pbft.ErrNoQuorum, node.ErrCrashed, ledger.ErrInvalid)
+}
+
+func (e *pbftEngine) AddNode(ctx context.Context) (Participant, error) {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    id, err := e.chain.AddNode(ctx)
+    if err != nil {
+        return Participant{}, rejected(err, pbft.ErrNoQuorum, node.ErrCrashed)
+    }
+    return Participant{ID: "node-" + strconv.Itoa(id), Role: "replica"}, nil
+}
+
+func (e *pbftEngine) RemoveNode(ctx context.Context, id string) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    n, err := nodeID(id)
+    if err != nil {
+        return err
+    }
+    return rejected(e.chain.RemoveNode(ctx, n), pbft.ErrNoQuorum, node.ErrCrashed)
+}
+
+func (e *pbftEngine) Export(w io.Writer) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return e.chain.Export(w)
+}
+
+func (e *pbftEngine) importFrom(r io.Reader) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return e.chain.Import(r)
+}
+
+// Former returns nil: the PBFT chain records every removal in a config block, which Validate rewinds.
+func (e *pbftEngine) Former() []Participant { return nil }
+
+func (e *pbftEngine) Blocks() []*wire.Block {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return toWire(e.chain.Blocks)
+}
+
+func (e *pbftEngine) Status() Status {
+    participants := e.Participants()
+    return statusOf("pbft", e.finality(), len(participants), participants[0].ID) // The first replica is the primary.
+}
+
+func (e *pbftEngine) Participants() []Participant {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    participants := make([]Participant, len(e.chain.Nodes))
+    for i, n := range e.chain.Nodes {
+        role := "replica"
+        if n.IsPrimary {
+            role = "primary"
+        }
+        participants[i] = Participant{ID: "node-" + strconv.Itoa(n.ID), Role: role}
+    }
+    return participants
+}
+
+func (e *pbftEngine) GetReceipt(tx string) (ledger.Receipt, bool) {
+    return e.chain.GetReceipt(tx)
+}
+
+// raftEngine wraps a Raft network and submits data through its leader.
+type raftEngine struct {
+    mu    sync.Mutex
+    chain *raft.Blockchain
+    subscriptions[raft.Block, *raft.Block] // Subscribe and Unsubscribe, fed by chain.
+}
+
+func newRaft(cfg Config) Engine {
+    chain := raft.NewRaftNetwork(cfg.networkOptions()...)
+    if g := cfg.Genesis; g != nil {
+        chain.Blocks[0] = raft.NewBlockAt(g.GenesisData(), wire.Hash{}, 0, g.Timestamp)
+    }
+    chain.AttachClock(cfg.Clock)
+    chain.AttachLogger(cfg.Logger)
+    chain.AttachEvents(cfg.Events)
+    return &raftEngine{chain: chain, subscriptions: subscriptions[raft.Block, *raft.Block]{chain: chain}}
+}
+
+func (e *raftEngine) Algorithm() string { return "raft" }
+
+func (e *raftEngine) Submit(ctx context.Context, data string) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    if e.chain.Leader == nil {
+        return ErrRejected
+    }
+    _, err := e.chain.Leader.LeadContext(ctx, data)
+    return rejected(err, raft.ErrNotLeader, raft.ErrNoQuorum)
+}
+
+func (e *raftEngine) AddNode(ctx context.Context) (Participant, error) {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    id, err := e.chain.AddNode(ctx)
+    if err != nil {
+        return Participant{}, rejected(err, raft.ErrNoLeader, raft.ErrNoQuorum)
+    }
+    return Participant{ID: "node-" + strconv.Itoa(id), Role: "follower"}, nil
+}
+
+func (e *raftEngine) RemoveNode(ctx context.Context, id string) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    n, err := nodeID(id)
+    if err != nil {
+        return err
+    }
+    return rejected(e.chain.RemoveNode(ctx, n), raft.ErrNoLeader, raft.ErrNoQuorum)
+}
+
+func (e *raftEngine) Export(w io.Writer) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return e.chain.Export(w)
+}
+
+func (e *raftEngine) importFrom(r io.Reader) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return e.chain.Import(r)
+}
+
+// Former returns nil: the Raft chain records every removal in a config block, which Validate rewinds.
+func (e *raftEngine) Former() []Participant { return nil }
+
+func (e *raftEngine) Blocks() []*wire.Block {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return toWire(e.chain.Blocks)
+}
+
+func (e *raftEngine) Status() Status {
+    e.mu.Lock()
+    leader := ""
+    if e.chain.Leader != nil {
+        leader = "node-" + strconv.Itoa(e.chain.Leader.ID)
+    }
+    nodes := len(e.chain.Nodes)
+    e.mu.Unlock()
+    return statusOf("raft", e.finality(), nodes, leader)
+}
+
+func (e *raftEngine) Participants() []Participant {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    participants := make([]Participant, len(e.chain.Nodes))
+    for i, n := range e.chain.Nodes {
+        role := "follower"
+        if n.IsLeader {
+            role = "leader"
+        }
+        participants[i] = Participant{ID: "node-" + strconv.Itoa(n.ID), Role: role}
+    }
+    return participants
+}
+
+// paxosEngine wraps a Paxos network whose first node proposes every value with an increasing proposal ID.
+type paxosEngine struct {
+    mu         sync.Mutex
+    chain      *paxos.Blockchain
+    proposalID int // ID of the most recent proposal.
+    subscriptions[paxos.Block, *paxos.Block] // Subscribe and Unsubscribe, fed by chain.
+}
+
+func newPaxos(cfg Config) Engine {
+    chain := paxos.NewPaxosNetwork(cfg.networkOptions()...)
+    if g := cfg.Genesis; g != nil {
+        chain.Blocks[0] = paxos.NewBlockAt(g.GenesisData(), wire.Hash{}, 0, g.Timestamp)
+    }
+    chain.AttachClock(cfg.Clock)
+    chain.AttachLogger(cfg.Logger)
+    chain.AttachEvents(cfg.Events)
+    return &paxosEngine{chain: chain, subscriptions: subscriptions[paxos.Block, *paxos.Block]{chain: chain}}
+}
+
+func (e *paxosEngine) Algorithm() string { return "paxos" }
+
+func (e *paxosEngine) Submit(ctx context.Context, data string) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    e.proposalID++
+    _, err := e.chain.RunPaxosContext(ctx, data, e.proposalID)
+    return rejected(err, paxos.ErrNoQuorum, node.ErrCrashed)
+}
+
+func (e *paxosEngine) AddNode(ctx context.Context) (Participant, error) {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    e.proposalID++
+    id, err := e.chain.AddNode(ctx, e.proposalID)
+    if err != nil {
+        return Participant{}, rejected(err, paxos.ErrNoQuorum, node.ErrCrashed)
+    }
+    return Participant{ID: "node-" + strconv.Itoa(id), Role: "acceptor"}, nil
+}
+
+func (e *paxosEngine) RemoveNode(ctx context.Context, id string) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    n, err := nodeID(id)
+    if err != nil {
+        return err
+    }
+    e.proposalID++
+    return rejected(e.chain.RemoveNode(ctx, n, e.proposalID), paxos.ErrNoQuorum, node.ErrCrashed)
+}
+
+func (e *paxosEngine) Export(w io.Writer) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return e.chain.Export(w)
+}
+
+func (e *paxosEngine) importFrom(r io.Reader) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    if err := e.chain.Import(r); err != nil {
+        return err
+    }
+    e.proposalID = 0
+    for _, n := range e.chain.Nodes {
+        for _, p := range n.Proposals {
+            e.proposalID = max(e.proposalID, p.ProposalID) // The next proposal must outnumber every one made before.
+        }
+    }
+    return nil
+}
+
+// Former returns nil: the Paxos chain records every removal in a config block, which Validate rewinds.
+func (e *paxosEngine) Former() []Participant { return nil }
+
+func (e *paxosEngine) Blocks() []*wire.Block {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return toWire(e.chain.Blocks)
+}
+
+func (e *paxosEngine) Status() Status {
+    participants := e.Participants()
+    return statusOf("paxos", e.finality(), len(participants), participants[0].ID) // The first node is the proposer.
+}
+
+func (e *paxosEngine) Participants() []Participant {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    participants := make([]Participant, len(e.chain.Nodes))
+    for i, n := range e.chain.Nodes {
+        role := "acceptor"
+        if i == 0 {
+            role = "proposer"
+        }
+        participants[i] = Participant{ID: "node-" + strconv.Itoa(n.ID), Role: role}
+    }
+    return participants
+}
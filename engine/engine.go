@@ -0,0 +1,219 @@
+// Package engine gives every consensus algorithm in this repository the same small interface.
+// The algorithm packages expose different APIs — Proof of Work mines blocks directly, Raft routes data through
+// an elected leader, Delegated Proof of Stake needs votes counted first — which is natural for studying each
+// one on its own but awkward for tools that want to drive or inspect any of them. An Engine wraps one running
+// simulation behind a common, concurrency-safe interface, so servers, command-line tools and comparisons can
+// treat all six algorithms alike.
+package engine
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "log/slog"
+    "slices"
+    "sort"
+
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/events"
+    "consensus-algorithms-edu/genesis"
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/wire"
+)
+
+var (
+    // ErrUnknownAlgorithm is returned by New for an algorithm name it does not recognize.
+    ErrUnknownAlgorithm = errors.New("engine: unknown algorithm")
+
+    // ErrRejected is returned by Submit when the network did not reach consensus on the data. It wraps the
+    // algorithm's own reason, such as pbft.ErrNoQuorum, which errors.Is finds as well.
+    ErrRejected = errors.New("engine: data was not agreed upon")
+
+    // ErrFixedMembership is returned by AddNode and RemoveNode for an engine whose participants cannot change,
+    // such as Proof of Work with its single simulated miner.
+    ErrFixedMembership = errors.New("engine: membership cannot change")
+)
+
+// Engine is a running consensus simulation that data can be submitted to and whose state can be inspected.
+// Every method is safe for concurrent use. Finality follows the algorithm: PBFT, Raft and Paxos blocks are final
+// once appended, Proof of Work blocks once Confirmations blocks follow them, Proof of Stake blocks once
+// validators holding two thirds of the stake built on them, and Delegated Proof of Stake blocks once more than
+// two thirds of the delegates did.
+type Engine interface {
+    Algorithm() string                             // Short algorithm name, e.g. "raft".
+    Submit(ctx context.Context, data string) error // Run consensus on data and append the resulting block; stops with ctx's error once ctx is done.
+    Blocks() []*wire.Block                         // Snapshot of the chain, starting with the genesis block.
+    Status() Status                                // Summary of the chain and the network.
+    Participants() []Participant                   // Nodes, validators or delegates taking part in consensus.
+    Export(w io.Writer) error                      // Write the chain and the algorithm's state as a snapshot that Import resumes.
+    Subscribe() <-chan *wire.Block                 // Channel receiving every block appended from now on, in order, until passed to Unsubscribe.
+    Unsubscribe(ch <-chan *wire.Block)             // Stop delivering blocks to a channel returned by Subscribe and close it.
+    FinalizedHeight() int64                        // Height of the highest block the algorithm considers final; 0 for the genesis block alone.
+    IsFinal(block *wire.Block) bool                // Whether the chain holds block at or below FinalizedHeight.
+}
+
+// Membership is implemented by engines whose participants can join and leave while consensus runs. Each
+// algorithm applies a change the way it would in practice: Raft, Paxos and PBFT agree on it and record it as a
+// block, a Proof of Stake validator bonds or unbonds its stake, and Delegated Proof of Stake voters elect a new
+// delegate or drop one. A change the network refuses is reported with ErrRejected, like a refused Submit.
+type Membership interface {
+    AddNode(ctx context.Context) (Participant, error) // Admit a new participant and return it.
+    RemoveNode(ctx context.Context, id string) error  // Remove the participant with the given Participant.ID.
+    Former() []Participant                            // Removed participants whose earlier blocks stay valid, if the chain does not record them.
+}
+
+// AddNode admits a new participant to e while it runs, or returns ErrFixedMembership if e's algorithm has no
+// way to change its membership.
+func AddNode(ctx context.Context, e Engine) (Participant, error) {
+    m, ok := e.(Membership)
+    if !ok {
+        return Participant{}, fmt.Errorf("%w: %s", ErrFixedMembership, e.Algorithm())
+    }
+    return m.AddNode(ctx)
+}
+
+// RemoveNode removes the participant with the given identifier from e while it runs, or returns
+// ErrFixedMembership if e's algorithm has no way to change its membership.
+func RemoveNode(ctx context.Context, e Engine, id string) error {
+    m, ok := e.(Membership)
+    if !ok {
+        return fmt.Errorf("%w: %s", ErrFixedMembership, e.Algorithm())
+    }
+    return m.RemoveNode(ctx, id)
+}
+
+// Receipts is implemented by engines whose chain keeps the receipts of the transactions it applies: Proof of
+// Work, Proof of Stake and PBFT, once Config.Ledger is a ledger.Executor.
+type Receipts interface {
+    GetReceipt(tx string) (ledger.Receipt, bool) // Receipt of the transaction with the given ledger.TxHash, if a block carries it.
+}
+
+// GetReceipt returns the receipt of the transaction with the given ledger.TxHash from e's chain, or false if no
+// block carries it or e keeps no receipts.
+func GetReceipt(e Engine, tx string) (ledger.Receipt, bool) {
+    r, ok := e.(Receipts)
+    if !ok {
+        return ledger.Receipt{}, false
+    }
+    return r.GetReceipt(tx)
+}
+
+// Status summarizes the state of a simulation.
+type Status struct {
+    Algorithm string `json:"algorithm"`        // Short algorithm name.
+    Height    int64  `json:"height"`           // Index of the head block; the genesis block has height 0.
+    Finalized int64  `json:"finalized"`        // Index of the highest final block (see Engine.FinalizedHeight).
+    Head      string `json:"head"`             // Hash of the head block.
+    Nodes     int    `json:"nodes"`            // Number of participants.
+    Leader    string `json:"leader,omitempty"` // Current leader, primary or proposer, for leader-based algorithms.
+}
+
+// Participant describes one node, validator or delegate.
+type Participant struct {
+    ID    string `json:"id"`              // Identifier within the simulation.
+    Role  string `json:"role"`            // Algorithm-specific role, e.g. "leader", "validator" or "delegate".
+    Stake int    `json:"stake,omitempty"` // PoS only: the participant's stake.
+    Votes int    `json:"votes,omitempty"` // DPoS only: the number of votes the delegate received.
+}
+
+// Config describes the simulation New builds.
+type Config struct {
+    Nodes   int              // Number of nodes, validators or delegates; defaults to 4. Ignored by PoW, which has a single miner.
+    Clock   clock.Clock      // Clock that timestamps submitted blocks; defaults to the system clock.
+    Logger  *slog.Logger     // Logger that records the algorithm's consensus steps; silent if nil.
+    Events  events.Publisher // Receives proposals, votes, elections, leader changes and commits; nothing is published if nil.
+    Genesis *genesis.Spec    // Genesis block, participants and chain parameters; the built-in defaults if nil.
+    Ledger  ledger.State     // PoW, PoS and PBFT only: state that submitted transactions are checked against and applied to; data is not interpreted if nil.
+    Options []options.Option // Further options for the algorithm's constructor, such as options.WithSeed; Nodes takes precedence over options.WithNodes.
+}
+
+// networkOptions returns the options handed to the algorithm's constructor.
+func (cfg Config) networkOptions() []options.Option {
+    return append(slices.Clone(cfg.Options), options.WithNodes(cfg.Nodes))
+}
+
+// constructors maps each algorithm name to the function that builds its engine.
+var constructors = map[string]func(cfg Config) Engine{
+    "pow":   newPoW,
+    "pos":   newPoS,
+    "dpos":  newDPoS,
+    "pbft":  newPBFT,
+    "raft":  newRaft,
+    "paxos": newPaxos,
+}
+
+// Algorithms returns the names accepted by New, in alphabetical order.
+func Algorithms() []string {
+    names := make([]string, 0, len(constructors))
+    for name := range constructors {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// New builds a fresh simulation of the named algorithm. With a genesis spec, the chain starts from the spec's
+// genesis block and the spec's nodes, validators, delegates and difficulty take the place of the defaults;
+// a spec written for another algorithm is rejected.
+func New(algorithm string, cfg Config) (Engine, error) {
+    construct, ok := constructors[algorithm]
+    if !ok {
+        return nil, fmt.Errorf("%w %q", ErrUnknownAlgorithm, algorithm)
+    }
+    if g := cfg.Genesis; g != nil {
+        if g.Algorithm != "" && g.Algorithm != algorithm {
+            return nil, fmt.Errorf("engine: genesis spec is for %q, not %q", g.Algorithm, algorithm)
+        }
+        if err := g.Validate(); err != nil {
+            return nil, err
+        }
+        if g.Nodes > 0 {
+            cfg.Nodes = g.Nodes
+        }
+    }
+    if cfg.Nodes <= 0 {
+        cfg.Nodes = 4
+    }
+    e := construct(cfg)
+    if cfg.Events != nil {
+        e = Observe(e, cfg.Events) // The algorithms publish their votes and leaders; proposals and commits are seen here.
+    }
+    return e, nil
+}
+
+// statusOf builds a Status from a chain snapshot and its final height.
+func statusOf(algorithm string, f finality, nodes int, leader string) Status {
+    head := f.blocks[len(f.blocks)-1]
+    return Status{
+        Algorithm: algorithm,
+        Height:    head.GetIndex(),
+        Finalized: f.height,
+        Head:      head.GetHash(),
+        Nodes:     nodes,
+        Leader:    leader,
+    }
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Adapters, Not Rewrites**: Each engine wraps an algorithm package's own Blockchain and calls its public API.
+//    The algorithms stay exactly as they are documented in their own packages, and the engine only translates
+//    between their types and the shared wire format.
+//
+// 2. **Snapshots Across the Boundary**: Blocks and Participants return freshly built values. Callers such as an
+//    HTTP server can hold on to them and encode them at leisure without racing against the next Submit.
+//
+// 3. **One Lock per Engine**: Every engine method takes the engine's mutex, on top of the lock each Blockchain
+//    keeps for itself, so that a method reading several of the chain's fields sees them at one moment. Consensus
+//    inside a simulation is synchronous, which keeps the locking trivial.
+//
+// 4. **Contexts on Submit Only**: Submit is the one method that can take long, mining a block or running a round,
+//    so it is the one that takes a context. It is handed to the algorithm's ...Context method, which stops at its
+//    next check once the context is done, and a cancelled submission is returned with the context's error rather
+//    than ErrRejected, since the network never refused it.
+//
+// 5. **Optional Membership**: Changing participants is a separate interface reached through AddNode and RemoveNode,
+//    not part of Engine, because not every algorithm can do it. The decorators from Observe and Instrument pass
+//    changes through, so the blocks some algorithms commit to record a change are published and counted.
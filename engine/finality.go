@@ -0,0 +1,124 @@
+package engine
+
+import (
+    "slices"
+
+    "consensus-algorithms-edu/quorum"
+    "consensus-algorithms-edu/wire"
+)
+
+// Confirmations is the number of blocks that must follow a Proof of Work block before FinalizedHeight counts
+// it final, the six confirmations Bitcoin wallets wait for. A PoW block is never final for certain, but the
+// chance that a branch of an attacker holding a minority of the hash power overtakes six blocks is negligible.
+const Confirmations = 6
+
+// finality is a snapshot of a chain and the height of its highest final block.
+type finality struct {
+    blocks []*wire.Block
+    height int64
+}
+
+// includes reports whether block is final: the chain holds it, at or below the final height.
+func (f finality) includes(block *wire.Block) bool {
+    i := block.GetIndex()
+    return i >= 0 && i <= f.height && i < int64(len(f.blocks)) && f.blocks[i].GetHash() == block.GetHash()
+}
+
+// committed makes every block of a chain final at once, as a chain that only appends committed blocks is.
+func committed(blocks []*wire.Block) finality {
+    return finality{blocks: blocks, height: int64(len(blocks) - 1)}
+}
+
+// buried makes the blocks of a chain final once depth blocks follow them.
+func buried(blocks []*wire.Block, depth int) finality {
+    return finality{blocks: blocks, height: max(0, int64(len(blocks)-1-depth))}
+}
+
+// attested makes a block final once the producers that vouched for it reach a quorum: its own producer and those
+// of the blocks after it, since producing a block on top of another endorses it. The quorum is built over the
+// producers, each numbered by its position among them; blocks of anyone else do not count. The genesis block is
+// always final.
+func attested(blocks []*wire.Block, producers []string, build quorum.Builder) finality {
+    members := make([]int32, len(producers))
+    for i := range members {
+        members[i] = int32(i)
+    }
+    q := build(members)
+    var vouched []int32
+    for i := len(blocks) - 1; i > 0; i-- {
+        if p := slices.Index(producers, blocks[i].GetProducer()); p >= 0 {
+            vouched = append(vouched, int32(p))
+        }
+        if q.Reached(vouched) {
+            return finality{blocks: blocks, height: int64(i)}
+        }
+    }
+    return finality{blocks: blocks}
+}
+
+func (e *powEngine) finality() finality {
+    return buried(e.Blocks(), Confirmations)
+}
+
+// finality counts a PoS block final once validators holding two thirds of the stake have attested to it, a
+// validator attesting by proposing the block or one after it, as Casper FFG finalizes a checkpoint.
+func (e *posEngine) finality() finality {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    var validators []string
+    stakes := make(map[int32]int, len(e.chain.Stakes))
+    for validator, stake := range e.chain.Stakes {
+        stakes[int32(len(validators))] = stake
+        validators = append(validators, validator)
+    }
+    return attested(toWire(e.chain.Blocks), validators, quorum.AtLeast(stakes, 2, 3))
+}
+
+// finality counts a DPoS block irreversible once more than two thirds of the delegates have produced it or a
+// block after it, as BitShares and EOS advance their last irreversible block.
+func (e *dposEngine) finality() finality {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    var delegates []string
+    for _, delegate := range e.chain.Delegates {
+        if !slices.Contains(delegates, delegate) {
+            delegates = append(delegates, delegate)
+        }
+    }
+    return attested(toWire(e.chain.Blocks), delegates, quorum.MoreThan(nil, 2, 3))
+}
+
+func (e *pbftEngine) finality() finality  { return committed(e.Blocks()) }
+func (e *raftEngine) finality() finality  { return committed(e.Blocks()) }
+func (e *paxosEngine) finality() finality { return committed(e.Blocks()) }
+
+func (e *powEngine) FinalizedHeight() int64   { return e.finality().height }
+func (e *posEngine) FinalizedHeight() int64   { return e.finality().height }
+func (e *dposEngine) FinalizedHeight() int64  { return e.finality().height }
+func (e *pbftEngine) FinalizedHeight() int64  { return e.finality().height }
+func (e *raftEngine) FinalizedHeight() int64  { return e.finality().height }
+func (e *paxosEngine) FinalizedHeight() int64 { return e.finality().height }
+
+func (e *powEngine) IsFinal(block *wire.Block) bool   { return e.finality().includes(block) }
+func (e *posEngine) IsFinal(block *wire.Block) bool   { return e.finality().includes(block) }
+func (e *dposEngine) IsFinal(block *wire.Block) bool  { return e.finality().includes(block) }
+func (e *pbftEngine) IsFinal(block *wire.Block) bool  { return e.finality().includes(block) }
+func (e *raftEngine) IsFinal(block *wire.Block) bool  { return e.finality().includes(block) }
+func (e *paxosEngine) IsFinal(block *wire.Block) bool { return e.finality().includes(block) }
+
+// Footer: Architectural Decisions
+//
+// 1. **One Question, Four Answers**: Every engine answers whether a block is final, but each algorithm means
+//    something different by it. PBFT, Raft and Paxos append a block only once a quorum committed it, so their
+//    blocks are final at once. Proof of Work never is, and a block counts as final once Confirmations blocks
+//    bury it. Proof of Stake waits for validators with two thirds of the stake to attest, and Delegated Proof of
+//    Stake for more than two thirds of the delegates to build on it. Callers that only want the answer ask
+//    IsFinal; the semantics stay with the algorithm.
+//
+// 2. **Blocks as Attestations**: The simulations behind the engines exchange no votes after a PoS or DPoS block is
+//    produced, so the proposals that follow a block stand in for attestations to it: a validator that builds
+//    on a block has accepted it. Deployed chains send separate attestations, which finalize sooner, but by the
+//    same two-thirds rule.
+//
+// 3. **Snapshot Consistency**: FinalizedHeight and IsFinal each compute the final height from one snapshot of
+//    the chain, so IsFinal never compares a block with a chain other than the one its height was computed for.
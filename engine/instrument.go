@@ -0,0 +1,91 @@
+package engine
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "consensus-algorithms-edu/metrics"
+    "consensus-algorithms-edu/stats"
+)
+
+// instrumented decorates an Engine with metrics, commit latency statistics, or both.
+type instrumented struct {
+    Engine
+    mu      sync.Mutex // Serializes Submit so each new block is counted exactly once.
+    metrics *metrics.Metrics
+    stats   *stats.Recorder
+}
+
+// Instrument returns an engine that behaves like e and records its activity in m: every committed block, and
+// the duration of every submission as one consensus round. Like Observe, it only sees whole submissions;
+// message counts and elections are recorded for message-driven replicas by node.Runner.
+func Instrument(e Engine, m *metrics.Metrics) Engine {
+    return &instrumented{Engine: e, metrics: m}
+}
+
+// Measure returns an engine that behaves like e and records in r the latency of every block it commits, from
+// the start of the submission that committed it, in wall-clock time. r.Summary then reports the percentiles
+// and the throughput of everything submitted through the returned engine.
+func Measure(e Engine, r *stats.Recorder) Engine {
+    return &instrumented{Engine: e, stats: r}
+}
+
+// Submit submits data, timing the round and counting every block it committed.
+func (i *instrumented) Submit(ctx context.Context, data string) error {
+    i.mu.Lock()
+    defer i.mu.Unlock()
+
+    before := len(i.Blocks())
+    start := time.Now()
+    err := i.Engine.Submit(ctx, data)
+    i.countCommits(before, start)
+    return err
+}
+
+// AddNode admits a participant, counting the blocks that recorded the change as a consensus round.
+func (i *instrumented) AddNode(ctx context.Context) (Participant, error) {
+    i.mu.Lock()
+    defer i.mu.Unlock()
+    before, start := len(i.Blocks()), time.Now()
+    p, err := AddNode(ctx, i.Engine)
+    i.countCommits(before, start)
+    return p, err
+}
+
+// RemoveNode removes a participant, counting the blocks that recorded the change as a consensus round.
+func (i *instrumented) RemoveNode(ctx context.Context, id string) error {
+    i.mu.Lock()
+    defer i.mu.Unlock()
+    before, start := len(i.Blocks()), time.Now()
+    err := RemoveNode(ctx, i.Engine, id)
+    i.countCommits(before, start)
+    return err
+}
+
+// Former returns the participants the wrapped engine has removed.
+func (i *instrumented) Former() []Participant {
+    if m, ok := i.Engine.(Membership); ok {
+        return m.Former()
+    }
+    return nil
+}
+
+// countCommits records every block appended after the first before blocks, and the round that started at
+// start if there were any.
+func (i *instrumented) countCommits(before int, start time.Time) {
+    end := time.Now()
+    algorithm := i.Algorithm()
+    committed := i.Blocks()[before:]
+    for _, block := range committed {
+        i.stats.Record(start, end)
+        node := block.GetProducer()
+        if node == "" {
+            node = algorithm // Blocks without a producer were agreed on by the whole network.
+        }
+        i.metrics.BlockCommitted(algorithm, node)
+    }
+    if len(committed) > 0 {
+        i.metrics.RoundCompleted(algorithm, end.Sub(start))
+    }
+}
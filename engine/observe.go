@@ -0,0 +1,92 @@
+package engine
+
+import (
+    "context"
+    "sync"
+
+    "consensus-algorithms-edu/events"
+)
+
+// observed decorates an Engine with event publication.
+type observed struct {
+    Engine
+    mu     sync.Mutex // Serializes Submit so each new block is reported exactly once.
+    stream events.Publisher
+}
+
+// Observe returns an engine that behaves like e and publishes its activity to stream: a Proposal event for
+// every submission and a Commit event for every block appended as a result. The simulations wrapped by New
+// reach consensus inside a single call, so Observe cannot see their individual votes; set Config.Events
+// instead to have the algorithms publish those too, in which case New applies Observe itself.
+func Observe(e Engine, stream events.Publisher) Engine {
+    return &observed{Engine: e, stream: stream}
+}
+
+// Submit publishes the proposal, submits it, and publishes every block the submission committed.
+func (o *observed) Submit(ctx context.Context, data string) error {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+
+    proposer := o.proposer()
+    o.stream.Publish(events.Event{Type: events.Proposal, Algorithm: o.Algorithm(), Node: proposer, Data: data})
+
+    before := len(o.Blocks())
+    err := o.Engine.Submit(ctx, data)
+    o.publishCommits(before, proposer)
+    return err
+}
+
+// AddNode admits a participant and publishes the blocks that recorded the change, for the algorithms that agree
+// on membership through the chain.
+func (o *observed) AddNode(ctx context.Context) (Participant, error) {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    proposer, before := o.proposer(), len(o.Blocks())
+    p, err := AddNode(ctx, o.Engine)
+    o.publishCommits(before, proposer)
+    return p, err
+}
+
+// RemoveNode removes a participant and publishes the blocks that recorded the change, like AddNode.
+func (o *observed) RemoveNode(ctx context.Context, id string) error {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    proposer, before := o.proposer(), len(o.Blocks())
+    err := RemoveNode(ctx, o.Engine, id)
+    o.publishCommits(before, proposer)
+    return err
+}
+
+// Former returns the participants the wrapped engine has removed.
+func (o *observed) Former() []Participant {
+    if m, ok := o.Engine.(Membership); ok {
+        return m.Former()
+    }
+    return nil
+}
+
+// proposer names the participant that proposes the next block.
+func (o *observed) proposer() string {
+    if leader := o.Status().Leader; leader != "" {
+        return leader
+    }
+    return o.Algorithm() // Leaderless algorithms propose on behalf of the whole network.
+}
+
+// publishCommits publishes a Commit event for every block appended after the first before blocks.
+func (o *observed) publishCommits(before int, proposer string) {
+    for _, block := range o.Blocks()[before:] {
+        node := block.GetProducer()
+        if node == "" {
+            node = proposer
+        }
+        o.stream.Publish(events.Event{
+            Type:      events.Commit,
+            Algorithm: o.Algorithm(),
+            Node:      node,
+            Height:    block.GetIndex(),
+            Hash:      block.GetHash(),
+            Data:      block.GetData(),
+        })
+    }
+}
@@ -0,0 +1,46 @@
+package engine
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+
+    "consensus-algorithms-edu/wire"
+)
+
+// importer is implemented by every engine New builds: it replaces the engine's state with a snapshot.
+type importer interface {
+    importFrom(r io.Reader) error
+}
+
+// Import resumes a run from a snapshot written by Engine.Export, or by an algorithm's Blockchain.Export. It
+// builds an engine of the snapshot's algorithm from cfg and replaces its chain and participants with the
+// snapshot's, so cfg.Nodes and cfg.Genesis are ignored; its clock, logger and events still apply.
+// Every block is verified before the engine is returned.
+func Import(r io.Reader, cfg Config) (Engine, error) {
+    data, err := io.ReadAll(r)
+    if err != nil {
+        return nil, fmt.Errorf("engine: read snapshot: %w", err)
+    }
+    snapshot, err := wire.DecodeSnapshotJSON(data)
+    if err != nil {
+        return nil, err
+    }
+    algorithm := snapshot.GetChain().GetAlgorithm()
+    construct, ok := constructors[algorithm]
+    if !ok {
+        return nil, fmt.Errorf("%w %q", ErrUnknownAlgorithm, algorithm)
+    }
+    cfg.Genesis = nil
+    if cfg.Nodes <= 0 {
+        cfg.Nodes = 4 // Replaced by the snapshot's participants; only needed to build the engine.
+    }
+    e := construct(cfg)
+    if err := e.(importer).importFrom(bytes.NewReader(data)); err != nil {
+        return nil, err
+    }
+    if cfg.Events != nil {
+        e = Observe(e, cfg.Events)
+    }
+    return e, nil
+}
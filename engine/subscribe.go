@@ -0,0 +1,64 @@
+package engine
+
+import (
+    "sync"
+
+    "consensus-algorithms-edu/wire"
+)
+
+// chainFeed is a chain's block subscriptions, as the algorithm packages' Blockchain types offer them.
+type chainFeed[B any] interface {
+    Subscribe() <-chan B
+    Unsubscribe(ch <-chan B)
+}
+
+// subscriptions gives an engine Subscribe and Unsubscribe by converting the blocks its chain delivers to wire
+// blocks, one forwarding goroutine per subscriber.
+type subscriptions[B any, P interface {
+    *B
+    ToWire() *wire.Block
+}] struct {
+    mu    sync.Mutex
+    chain chainFeed[B]
+    stops map[<-chan *wire.Block]chan struct{} // Closed to end the forwarding goroutine of each subscriber.
+}
+
+// Subscribe returns a channel that receives every block the engine's chain appends from now on.
+func (s *subscriptions[B, P]) Subscribe() <-chan *wire.Block {
+    in, out, stop := s.chain.Subscribe(), make(chan *wire.Block), make(chan struct{})
+    s.mu.Lock()
+    if s.stops == nil {
+        s.stops = make(map[<-chan *wire.Block]chan struct{})
+    }
+    s.stops[out] = stop
+    s.mu.Unlock()
+
+    go func() {
+        defer close(out)
+        defer s.chain.Unsubscribe(in)
+        for {
+            select {
+            case block := <-in:
+                select {
+                case out <- P(&block).ToWire():
+                case <-stop:
+                    return
+                }
+            case <-stop:
+                return
+            }
+        }
+    }()
+    return out
+}
+
+// Unsubscribe stops delivering blocks to ch and closes it.
+func (s *subscriptions[B, P]) Unsubscribe(ch <-chan *wire.Block) {
+    s.mu.Lock()
+    stop, ok := s.stops[ch]
+    delete(s.stops, ch)
+    s.mu.Unlock()
+    if ok {
+        close(stop)
+    }
+}
@@ -0,0 +1,221 @@
+package engine
+
+import (
+    "errors"
+    "fmt"
+    "slices"
+
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/wire"
+)
+
+// ErrUnverified is returned by Checkpoint.Verify and FastSync for a checkpoint, header or block that fails
+// verification. The error names what failed.
+var ErrUnverified = errors.New("engine: sync data not verified")
+
+// Checkpoint is a recent block that a joining node trusts in place of the chain before it. For PBFT the block's
+// certificate is what earns that trust: a quorum of replicas the node already knows committed it. For the other
+// algorithms the checkpoint's hash must come from somewhere the node trusts, such as a release or a friend, just
+// as a genesis hash would.
+type Checkpoint struct {
+    Algorithm string      // Algorithm that produced the block.
+    Block     *wire.Block // The block, with its certificate for PBFT.
+}
+
+// NewCheckpoint returns a checkpoint of e's block at index, or of e's head if index is negative.
+func NewCheckpoint(e Engine, index int64) (*Checkpoint, error) {
+    blocks := e.Blocks()
+    if index < 0 {
+        index = int64(len(blocks) - 1)
+    }
+    if index >= int64(len(blocks)) {
+        return nil, fmt.Errorf("engine: no block %d to checkpoint in a chain of %d", index, len(blocks))
+    }
+    return &Checkpoint{Algorithm: e.Algorithm(), Block: blocks[index]}, nil
+}
+
+// Header returns the header of the checkpointed block.
+func (cp *Checkpoint) Header() (wire.Header, error) {
+    return Header(cp.Algorithm, cp.Block)
+}
+
+// Verify checks that the checkpointed block carries the hash its header has and, for PBFT, that a quorum of
+// members certified it. It returns an error wrapping ErrUnverified if it does not. members are the participants
+// the joining node trusts; with nil, a PBFT certificate need only be present.
+func (cp *Checkpoint) Verify(members []Participant) error {
+    r, ok := rules[cp.Algorithm]
+    if !ok {
+        return fmt.Errorf("%w %q", ErrUnknownAlgorithm, cp.Algorithm)
+    }
+    if cp.Block.GetHash() != r.hash(cp.Block) {
+        return fmt.Errorf("%w: checkpoint %d has an invalid hash", ErrUnverified, cp.Block.GetIndex())
+    }
+    if cp.Algorithm == "pbft" && cp.Block.GetIndex() > 0 {
+        if reason := certified(cp.Block, members); reason != "" {
+            return fmt.Errorf("%w: checkpoint %d %s", ErrUnverified, cp.Block.GetIndex(), reason)
+        }
+    }
+    return nil
+}
+
+// Peer is a node a joining node syncs from. A blocktree.Replica serves headers; PeerOf serves an engine's.
+type Peer interface {
+    Headers(from int64, limit int) []wire.Header // Headers from index from on, at most limit of them if limit is positive.
+    Block(index int64) (*wire.Block, bool)       // The block at index, if the peer has it.
+}
+
+// PeerOf returns a Peer that serves e's chain.
+func PeerOf(e Engine) Peer {
+    return enginePeer{e}
+}
+
+// enginePeer serves an engine's chain as a Peer.
+type enginePeer struct {
+    e Engine
+}
+
+// Headers returns the headers of the engine's blocks from index from on.
+func (p enginePeer) Headers(from int64, limit int) []wire.Header {
+    blocks := p.e.Blocks()
+    if from < 0 || from >= int64(len(blocks)) {
+        return nil
+    }
+    blocks = blocks[from:]
+    if limit > 0 && limit < len(blocks) {
+        blocks = blocks[:limit]
+    }
+    headers := make([]wire.Header, len(blocks))
+    for i, block := range blocks {
+        headers[i], _ = Header(p.e.Algorithm(), block)
+    }
+    return headers
+}
+
+// Block returns the engine's block at index.
+func (p enginePeer) Block(index int64) (*wire.Block, bool) {
+    blocks := p.e.Blocks()
+    if index < 0 || index >= int64(len(blocks)) {
+        return nil, false
+    }
+    return blocks[index], true
+}
+
+// SyncBatch is the number of headers FastSync asks a peer for at once.
+const SyncBatch = 500
+
+// Synced is what a fast sync leaves a joining node with: the checkpoint, the verified headers from it to the
+// peer's head, and the head block itself. Bodies of the blocks in between can be fetched on demand and checked
+// with VerifyBlock.
+type Synced struct {
+    Algorithm  string
+    Checkpoint *wire.Block   // The trusted block the sync started from.
+    Headers    []wire.Header // Headers from the checkpoint's to the head's, each linked to its predecessor.
+    Head       *wire.Block   // The block of the last header.
+}
+
+// Height returns the number of blocks in the chain the node synced to, the checkpoint's predecessors included.
+func (s *Synced) Height() int64 {
+    return s.Head.GetIndex() + 1
+}
+
+// VerifyBlock checks that block, fetched from any peer, is the block the synced headers name at its index. It
+// returns an error wrapping ErrUnverified if the node has no header at that index or the block does not match it.
+func (s *Synced) VerifyBlock(block *wire.Block) error {
+    i := block.GetIndex() - s.Checkpoint.GetIndex()
+    if i < 0 || i >= int64(len(s.Headers)) {
+        return fmt.Errorf("%w: no synced header for block %d", ErrUnverified, block.GetIndex())
+    }
+    h, err := Header(s.Algorithm, block)
+    if err != nil {
+        return err
+    }
+    if h.Hash() != s.Headers[i].Hash() {
+        return fmt.Errorf("%w: block %d does not match its header", ErrUnverified, block.GetIndex())
+    }
+    return nil
+}
+
+// FastSync brings a joining node up to date from a trusted checkpoint rather than from the genesis block. It
+// verifies the checkpoint against members (see Checkpoint.Verify), downloads the headers after it from peer in
+// batches and verifies that they form a chain from the checkpoint, and that each carries its work for Proof of
+// Work and names a participant as its producer for Proof of Stake and Delegated Proof of Stake. Finally it
+// fetches the head block, checks it against the last header and, for PBFT, that a quorum of members certified
+// it, since a header carries no certificate of its own. Only the head's body is downloaded, against every body
+// for a full replay. Members that changed since the checkpoint call for a newer checkpoint.
+func FastSync(cp *Checkpoint, members []Participant, peer Peer) (*Synced, error) {
+    if err := cp.Verify(members); err != nil {
+        return nil, err
+    }
+    first, _ := cp.Header()
+    headers := []wire.Header{first}
+    for {
+        batch := peer.Headers(headers[len(headers)-1].Index+1, SyncBatch)
+        if len(batch) == 0 {
+            break
+        }
+        headers = append(headers, batch...)
+    }
+    if err := wire.VerifyHeaders(headers); err != nil {
+        return nil, fmt.Errorf("%w: %w", ErrUnverified, err)
+    }
+    for _, h := range headers[1:] {
+        if reason := checkHeader(cp.Algorithm, &h, &first, members); reason != "" {
+            return nil, fmt.Errorf("%w: header %d %s", ErrUnverified, h.Index, reason)
+        }
+    }
+
+    s := &Synced{Algorithm: cp.Algorithm, Checkpoint: cp.Block, Headers: headers, Head: cp.Block}
+    if len(headers) > 1 {
+        head, ok := peer.Block(headers[len(headers)-1].Index)
+        if !ok {
+            return nil, fmt.Errorf("%w: peer has no head block %d", ErrUnverified, headers[len(headers)-1].Index)
+        }
+        if err := s.VerifyBlock(head); err != nil {
+            return nil, err
+        }
+        if cp.Algorithm == "pbft" {
+            if reason := certified(head, members); reason != "" {
+                return nil, fmt.Errorf("%w: head %d %s", ErrUnverified, head.GetIndex(), reason)
+            }
+        }
+        s.Head = head
+    }
+    return s, nil
+}
+
+// checkHeader returns why h, a header after the checkpoint's header first, breaks the named algorithm's rules, or
+// "" if it does not. Only what a header commits to can be checked: the work of a PoW header and the producer of
+// a PoS or DPoS one. Without members, producers are not checked.
+func checkHeader(algorithm string, h, first *wire.Header, members []Participant) string {
+    switch algorithm {
+    case "pow":
+        target := int(h.Difficulty)
+        if target == 0 {
+            target = pow.Difficulty
+        }
+        if h.Difficulty != first.Difficulty {
+            return fmt.Sprintf("has difficulty %d, but the checkpoint has %d", h.Difficulty, first.Difficulty)
+        }
+        if h.Hash().LeadingZeros() < target {
+            return "does not meet the difficulty target"
+        }
+    case "pos", "dpos":
+        if members != nil && !slices.ContainsFunc(members, func(p Participant) bool { return wire.ProducerID(p.ID) == h.Producer }) {
+            return "was produced by none of the participants"
+        }
+    }
+    return ""
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Trust the Checkpoint, Verify the Rest**: Everything after the checkpoint is checked, but the checkpoint
+//    itself is where trust enters, as the genesis block is for a full sync. For PBFT a certificate from a quorum
+//    of known replicas replaces that trust; the other algorithms produce nothing a newcomer could check it with.
+//
+// 2. **Headers, Then One Body**: Headers are fixed-size and link by hash, so a chain of them proves the order of
+//    the bodies they commit to without downloading any. The head's body is fetched because PBFT certifies blocks
+//    rather than headers, and every other body can be fetched and checked when the node needs it.
+//
+// 3. **Peers as an Interface**: A Peer serves headers and blocks, which both an engine and a blocktree.Replica
+//    can do, so a joining node syncs the same way from a simulation in process or from a node across a network.
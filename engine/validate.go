@@ -0,0 +1,254 @@
+package engine
+
+import (
+    "fmt"
+    "slices"
+    "strings"
+
+    "consensus-algorithms-edu/algorithms/dpos"
+    "consensus-algorithms-edu/algorithms/paxos"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/quorum"
+    "consensus-algorithms-edu/wire"
+)
+
+// ChainError reports the first block of a chain that failed validation.
+type ChainError struct {
+    Algorithm string // Algorithm whose rules the chain was checked against.
+    Index     int    // Position of the invalid block in the chain.
+    Reason    string // What is wrong with the block.
+}
+
+func (e *ChainError) Error() string {
+    return fmt.Sprintf("%s: block %d %s", e.Algorithm, e.Index, e.Reason)
+}
+
+// rule is the algorithm-specific part of chain validation.
+type rule struct {
+    checks string                                                                  // Short description of the extra checks, for reports.
+    header func(block *wire.Block) wire.Header                                     // Rebuilds a block's header, whose hash is the block's, with the algorithm's rules.
+    check  func(chain []*wire.Block, index int, participants []Participant) string // Why chain[index] is invalid, or "" if it is not.
+}
+
+// hash recomputes a block's hash, as it is written in wire.Block.
+func (r rule) hash(block *wire.Block) string {
+    h := r.header(block)
+    return h.Hash().String()
+}
+
+// rules maps each algorithm name to its validation rule.
+var rules = map[string]rule{
+    "pow": {
+        checks: "proof of work",
+        header:   func(w *wire.Block) wire.Header { b := pow.BlockFromWire(w); return b.Header() },
+        check:  checkDifficulty,
+    },
+    "pos": {
+        checks: "eligible validators",
+        header:   func(w *wire.Block) wire.Header { b := pos.BlockFromWire(w); return b.Header() },
+        check:  checkValidator,
+    },
+    "dpos": {
+        checks: "elected delegates",
+        header:   func(w *wire.Block) wire.Header { b := dpos.BlockFromWire(w); return b.Header() },
+        check:  checkDelegate,
+    },
+    "pbft": {
+        checks: "quorum certificates",
+        header:   func(w *wire.Block) wire.Header { b := pbft.BlockFromWire(w); return b.Header() },
+        check:  checkCertificate,
+    },
+    "raft": {
+        header: func(w *wire.Block) wire.Header { b := raft.BlockFromWire(w); return b.Header() },
+    },
+    "paxos": {
+        header: func(w *wire.Block) wire.Header { b := paxos.BlockFromWire(w); return b.Header() },
+    },
+}
+
+// Checks returns a short description of what Validate checks for the named algorithm, e.g.
+// "hashes, indexes, links, proof of work".
+func Checks(algorithm string) string {
+    checks := "hashes, indexes, links"
+    if r := rules[algorithm]; r.checks != "" {
+        checks += ", " + r.checks
+    }
+    return checks
+}
+
+// Hash recomputes the hash of a block produced by the named algorithm, with that algorithm's hashing rules. A
+// block whose Hash field differs from it was altered after it was produced.
+func Hash(algorithm string, block *wire.Block) (string, error) {
+    r, ok := rules[algorithm]
+    if !ok {
+        return "", fmt.Errorf("%w %q", ErrUnknownAlgorithm, algorithm)
+    }
+    return r.hash(block), nil
+}
+
+// Header rebuilds the header of a block produced by the named algorithm, the part of the block its hash covers.
+// A node that holds only headers can check them with wire.VerifyHeaders and a block fetched later against its
+// header's Root.
+func Header(algorithm string, block *wire.Block) (wire.Header, error) {
+    r, ok := rules[algorithm]
+    if !ok {
+        return wire.Header{}, fmt.Errorf("%w %q", ErrUnknownAlgorithm, algorithm)
+    }
+    return r.header(block), nil
+}
+
+// ValidateChain checks e's chain with Validate, taking e's participants as the eligible producers and voters,
+// along with the participants it has removed that its chain does not record, so their earlier blocks stay valid.
+func ValidateChain(e Engine) error {
+    participants := e.Participants()
+    if m, ok := e.(Membership); ok {
+        participants = append(participants, m.Former()...)
+    }
+    return Validate(e.Algorithm(), e.Blocks(), participants)
+}
+
+// Validate checks a chain produced by the named algorithm and returns a *ChainError for the first invalid block.
+// Every chain must start with a genesis block at index 0, every block must sit at the index after its
+// predecessor's, link to it through PrevHash, and carry the hash the algorithm computes for its contents. On top of
+// that, PoW blocks must meet the difficulty target, PoS blocks must be produced by a validator with stake, DPoS
+// blocks by a delegate, and PBFT blocks after the genesis block must carry a certificate from a quorum of 2f+1
+// replicas, counted among the replicas of the time: blocks recording a membership change are rewound to find
+// them. A PBFT network built with another quorum through options.WithQuorum is still held to 2f+1. Participants
+// that are not known, such as for a chain loaded from disk, may be passed as nil: producers and certificates are
+// then only required to be present, not to name participants.
+func Validate(algorithm string, blocks []*wire.Block, participants []Participant) error {
+    r, ok := rules[algorithm]
+    if !ok {
+        return fmt.Errorf("%w %q", ErrUnknownAlgorithm, algorithm)
+    }
+    if len(blocks) == 0 {
+        return &ChainError{Algorithm: algorithm, Index: 0, Reason: "is missing: the chain has no genesis block"}
+    }
+    for i, block := range blocks {
+        reason := ""
+        switch {
+        case block.GetIndex() != int64(i):
+            reason = fmt.Sprintf("has index %d", block.GetIndex())
+        case i == 0 && block.GetPrevHash() != "":
+            reason = "is a genesis block with a parent"
+        case i > 0 && block.GetPrevHash() != blocks[i-1].GetHash():
+            reason = "does not link to its predecessor"
+        case block.GetHash() != r.hash(block):
+            reason = "has an invalid hash"
+        case r.check != nil:
+            reason = r.check(blocks, i, participants)
+        }
+        if reason != "" {
+            return &ChainError{Algorithm: algorithm, Index: i, Reason: reason}
+        }
+    }
+    return nil
+}
+
+// checkDifficulty requires the block's hash to meet the Proof of Work difficulty target, and the target to be the
+// one the genesis block set for the whole chain.
+func checkDifficulty(chain []*wire.Block, index int, participants []Participant) string {
+    w := chain[index]
+    if b := pow.BlockFromWire(w); !b.MeetsDifficulty() {
+        return "does not meet the difficulty target"
+    }
+    if w.GetDifficulty() != chain[0].GetDifficulty() {
+        return fmt.Sprintf("has difficulty %d, but the chain was started at %d", w.GetDifficulty(), chain[0].GetDifficulty())
+    }
+    return ""
+}
+
+// checkValidator requires the block to be produced by a validator with a positive stake.
+func checkValidator(chain []*wire.Block, index int, participants []Participant) string {
+    w := chain[index]
+    if w.GetProducer() == "" {
+        return "has no validator"
+    }
+    if participants == nil {
+        return ""
+    }
+    i := slices.IndexFunc(participants, func(p Participant) bool { return p.ID == w.GetProducer() })
+    if i < 0 || participants[i].Stake <= 0 {
+        return fmt.Sprintf("was produced by %q, who is not a validator with stake", w.GetProducer())
+    }
+    return ""
+}
+
+// checkDelegate requires the block to be produced by one of the delegates.
+func checkDelegate(chain []*wire.Block, index int, participants []Participant) string {
+    w := chain[index]
+    if w.GetProducer() == "" {
+        return "has no delegate"
+    }
+    if participants == nil {
+        return ""
+    }
+    if !slices.ContainsFunc(participants, func(p Participant) bool { return p.ID == w.GetProducer() }) {
+        return fmt.Sprintf("was produced by %q, who is not a delegate", w.GetProducer())
+    }
+    return ""
+}
+
+// checkCertificate requires every block after the genesis block to be certified by 2f+1 distinct replicas,
+// where n = 3f+1 is the number of replicas at the time the block was committed.
+func checkCertificate(chain []*wire.Block, index int, participants []Participant) string {
+    w := chain[index]
+    if index == 0 {
+        return "" // The genesis block is agreed on in advance, not voted on.
+    }
+    return certified(w, membersAt(chain, index, participants))
+}
+
+// certified requires w to be certified by 2f+1 distinct participants, where n = 3f+1 is their number, or to carry
+// a certificate at all if participants is nil.
+func certified(w *wire.Block, participants []Participant) string {
+    var signers []int32 // Positions of the signers among participants.
+    for _, signer := range w.GetCertificate() {
+        i := slices.IndexFunc(participants, func(p Participant) bool { return p.ID == signer })
+        if participants != nil && i < 0 {
+            return fmt.Sprintf("is certified by %q, who is not a replica", signer)
+        }
+        signers = append(signers, int32(i))
+    }
+    if len(participants) == 0 { // Without the participants, a certificate must at least be present.
+        if len(signers) == 0 {
+            return "is certified by 0 replicas, fewer than the quorum of 1"
+        }
+        return ""
+    }
+    members := make([]int32, len(participants))
+    for i := range members {
+        members[i] = int32(i)
+    }
+    q := quorum.Byzantine(members).(*quorum.Weighted)
+    if !q.Reached(signers) {
+        return fmt.Sprintf("is certified by %d replicas, fewer than the quorum of %d", q.Weight(signers), q.Need())
+    }
+    return ""
+}
+
+// membersAt rewinds participants, the members after the last block of chain, to the members that voted on
+// chain[index], by undoing the "config: add node-N" and "config: remove node-N" blocks committed since. A change
+// takes effect once its block is committed, so the members that voted on a config block are the ones before it.
+func membersAt(chain []*wire.Block, index int, participants []Participant) []Participant {
+    if participants == nil {
+        return nil
+    }
+    members := slices.Clone(participants)
+    for i := len(chain) - 1; i >= index; i-- {
+        change, id, ok := strings.Cut(strings.TrimPrefix(chain[i].GetData(), "config: "), " ")
+        if !ok || !strings.HasPrefix(chain[i].GetData(), "config: ") {
+            continue
+        }
+        switch change {
+        case "add":
+            members = slices.DeleteFunc(members, func(p Participant) bool { return p.ID == id })
+        case "remove":
+            members = append(members, Participant{ID: id})
+        }
+    }
+    return members
+}
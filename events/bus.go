@@ -0,0 +1,95 @@
+package events
+
+import (
+    "sync"
+
+    "consensus-algorithms-edu/clock"
+)
+
+// Bus dispatches published events to observer hooks. Hooks are registered for one event type with OnProposal,
+// OnVote, OnElection, OnLeaderChange, OnViewChange and OnCommit, or for every event with OnEvent, and are called
+// synchronously, in registration order, on the goroutine that publishes. Unlike a Stream, a Bus never drops an
+// event, so hooks must return quickly and must not block on the code that publishes.
+type Bus struct {
+    Clock clock.Clock // Stamps events published without a time; defaults to the system clock.
+
+    mu    sync.Mutex
+    seq   uint64
+    next  uint64 // Identifier of the next registered hook.
+    hooks []hook
+}
+
+// hook is one registered observer.
+type hook struct {
+    id   uint64
+    typ  Type // Event type the hook observes, or "" for every event.
+    call func(Event)
+}
+
+// NewBus creates a bus without hooks.
+func NewBus() *Bus {
+    return &Bus{}
+}
+
+// Publish assigns the event its sequence number and time and calls every hook registered for its type.
+// Hooks registered or removed by a hook take effect from the next event.
+func (b *Bus) Publish(event Event) {
+    b.mu.Lock()
+    b.seq++
+    event.Seq = b.seq
+    if event.Time.IsZero() {
+        event.Time = clock.Or(b.Clock).Now()
+    }
+    hooks := b.hooks
+    b.mu.Unlock()
+
+    for _, h := range hooks {
+        if h.typ == "" || h.typ == event.Type {
+            h.call(event)
+        }
+    }
+}
+
+// On registers fn for events of type t and returns a function that removes it.
+func (b *Bus) On(t Type, fn func(Event)) (remove func()) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    b.next++
+    id := b.next
+    b.hooks = append(b.hooks[:len(b.hooks):len(b.hooks)], hook{id: id, typ: t, call: fn}) // Never share a backing array with a running Publish.
+    return func() { b.remove(id) }
+}
+
+// OnEvent registers fn for every event, e.g. to forward them to a Stream, and returns a function that removes it.
+func (b *Bus) OnEvent(fn func(Event)) (remove func()) { return b.On("", fn) }
+
+// OnProposal registers fn for Proposal events and returns a function that removes it.
+func (b *Bus) OnProposal(fn func(Event)) (remove func()) { return b.On(Proposal, fn) }
+
+// OnVote registers fn for Vote events and returns a function that removes it.
+func (b *Bus) OnVote(fn func(Event)) (remove func()) { return b.On(Vote, fn) }
+
+// OnElection registers fn for Election events and returns a function that removes it.
+func (b *Bus) OnElection(fn func(Event)) (remove func()) { return b.On(Election, fn) }
+
+// OnLeaderChange registers fn for LeaderChange events and returns a function that removes it.
+func (b *Bus) OnLeaderChange(fn func(Event)) (remove func()) { return b.On(LeaderChange, fn) }
+
+// OnViewChange registers fn for ViewChange events and returns a function that removes it.
+func (b *Bus) OnViewChange(fn func(Event)) (remove func()) { return b.On(ViewChange, fn) }
+
+// OnCommit registers fn for Commit events and returns a function that removes it.
+func (b *Bus) OnCommit(fn func(Event)) (remove func()) { return b.On(Commit, fn) }
+
+// remove unregisters the hook with the given identifier. Removing a hook twice is harmless.
+func (b *Bus) remove(id uint64) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    hooks := make([]hook, 0, len(b.hooks))
+    for _, h := range b.hooks {
+        if h.id != id {
+            hooks = append(hooks, h)
+        }
+    }
+    b.hooks = hooks
+}
@@ -0,0 +1,190 @@
+// Package events describes consensus activity as a stream of structured events.
+// Proposals, votes, elections, leader changes, view changes and committed blocks are reported as Event values and
+// published to a Publisher: a Stream, which any number of subscribers can follow in real time over channels, or a
+// Bus, which calls typed observer hooks such as OnVote and OnCommit synchronously. Events are plain JSON-friendly
+// structs, so the same activity can drive a log, a test assertion or a browser-based visualization. Message-driven
+// replicas have their events derived from the messages they exchange, which means those algorithms report their
+// activity without any change to their logic.
+package events
+
+import (
+    "sync"
+    "time"
+
+    "google.golang.org/protobuf/proto"
+
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/wire"
+)
+
+// Type identifies the kind of activity an event reports.
+type Type string
+
+// Event types.
+const (
+    Proposal     Type = "proposal"      // A node accepted new data to be agreed upon.
+    Vote         Type = "vote"          // A node voted: a granted Raft vote, a PBFT prepare or commit, a Paxos promise or acceptance, a DPoS vote.
+    Election     Type = "election"      // A Raft candidate started an election.
+    LeaderChange Type = "leader_change" // A node started following a new leader, primary, validator or delegate.
+    ViewChange   Type = "view_change"   // A PBFT replica asked for, or announced, a new view.
+    Commit       Type = "commit"        // A node committed a block.
+)
+
+// Event is one piece of consensus activity.
+type Event struct {
+    Seq       uint64    `json:"seq"`                 // Position in the stream, assigned on publication; gaps mean events were dropped.
+    Time      time.Time `json:"time"`                // When the event was published.
+    Type      Type      `json:"type"`                // Kind of activity.
+    Algorithm string    `json:"algorithm,omitempty"` // Algorithm of the node that produced the event.
+    Node      string    `json:"node"`                // Node, validator or delegate that produced the event.
+    Message   string    `json:"message,omitempty"`   // Wire message the event was derived from, if any.
+    Round     int64     `json:"round,omitempty"`     // Raft term, PBFT view or Paxos ballot the event belongs to.
+    Height    int64     `json:"height,omitempty"`    // Block index or PBFT sequence number.
+    Hash      string    `json:"hash,omitempty"`      // Block hash or PBFT digest.
+    Data      string    `json:"data,omitempty"`      // Proposed or committed data.
+    Leader    string    `json:"leader,omitempty"`    // LeaderChange only: the new leader, primary, validator or delegate.
+}
+
+// Publisher accepts events. Stream and Bus both implement it, so event sources such as node.Runner, sim.Simulator
+// and the engines do not depend on how their events are consumed.
+type Publisher interface {
+    Publish(event Event)
+}
+
+// FromEnvelopes derives events from the envelopes a node sent in one step. Broadcasts produce one envelope
+// per recipient; they are reported once, as a single event.
+func FromEnvelopes(algorithm, node string, out []*wire.Envelope) []Event {
+    var events []Event
+    seen := make(map[string]bool)
+    for _, env := range out {
+        event, ok := fromEnvelope(env)
+        if !ok {
+            continue
+        }
+        body := proto.Clone(env).(*wire.Envelope)
+        body.To = 0 // Copies of a broadcast differ only in the recipient.
+        key, err := proto.MarshalOptions{Deterministic: true}.Marshal(body)
+        if err != nil || seen[string(key)] {
+            continue
+        }
+        seen[string(key)] = true
+        event.Algorithm = algorithm
+        event.Node = node
+        event.Message = env.Kind()
+        events = append(events, event)
+    }
+    return events
+}
+
+// fromEnvelope classifies a single envelope. Messages that only carry replication traffic, such as Raft
+// AppendEntries or PBFT pre-prepares, do not produce events of their own.
+func fromEnvelope(env *wire.Envelope) (Event, bool) {
+    switch body := env.GetBody().(type) {
+    case *wire.Envelope_RequestVote:
+        return Event{Type: Election, Round: int64(body.RequestVote.GetTerm())}, true
+    case *wire.Envelope_RequestVoteResponse:
+        if !body.RequestVoteResponse.GetVoteGranted() {
+            return Event{}, false
+        }
+        return Event{Type: Vote, Round: int64(body.RequestVoteResponse.GetTerm())}, true
+    case *wire.Envelope_Prepare:
+        m := body.Prepare
+        return Event{Type: Vote, Round: int64(m.GetView()), Height: m.GetSequence(), Hash: m.GetDigest()}, true
+    case *wire.Envelope_Commit:
+        m := body.Commit
+        return Event{Type: Vote, Round: int64(m.GetView()), Height: m.GetSequence(), Hash: m.GetDigest()}, true
+    case *wire.Envelope_ViewChange:
+        return Event{Type: ViewChange, Round: int64(body.ViewChange.GetNewView())}, true
+    case *wire.Envelope_NewView:
+        return Event{Type: ViewChange, Round: int64(body.NewView.GetView())}, true
+    case *wire.Envelope_PaxosPromise:
+        return Event{Type: Vote, Round: body.PaxosPromise.GetBallot(), Height: body.PaxosPromise.GetSlot()}, true
+    case *wire.Envelope_PaxosAccepted:
+        return Event{Type: Vote, Round: body.PaxosAccepted.GetBallot(), Height: body.PaxosAccepted.GetSlot()}, true
+    case *wire.Envelope_DelegateVote:
+        return Event{Type: Vote, Data: body.DelegateVote.GetDelegate()}, true // Data names the delegate voted for.
+    }
+    return Event{}, false
+}
+
+// Stream fans published events out to every subscriber. Publishing never blocks: a subscriber that falls
+// behind by more than its buffer loses events, which it can detect from gaps in Seq.
+type Stream struct {
+    Clock clock.Clock // Stamps events published without a time; defaults to the system clock.
+
+    mu   sync.Mutex
+    seq  uint64
+    subs map[*Subscription]struct{}
+}
+
+// NewStream creates a stream without subscribers.
+func NewStream() *Stream {
+    return &Stream{subs: make(map[*Subscription]struct{})}
+}
+
+// Publish assigns the event its sequence number and time and delivers it to every subscriber.
+func (s *Stream) Publish(event Event) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.seq++
+    event.Seq = s.seq
+    if event.Time.IsZero() {
+        event.Time = clock.Or(s.Clock).Now()
+    }
+    for sub := range s.subs {
+        select {
+        case sub.events <- event:
+        default: // The subscriber is too slow; drop the event rather than stall consensus.
+        }
+    }
+}
+
+// Subscribe registers a subscriber whose channel buffers up to buffer events.
+func (s *Stream) Subscribe(buffer int) *Subscription {
+    sub := &Subscription{stream: s, events: make(chan Event, buffer)}
+    s.mu.Lock()
+    s.subs[sub] = struct{}{}
+    s.mu.Unlock()
+    return sub
+}
+
+// Subscription is one subscriber's view of a Stream.
+type Subscription struct {
+    stream *Stream
+    events chan Event
+    once   sync.Once
+}
+
+// Events returns the channel events are delivered on. It is closed by Close.
+func (sub *Subscription) Events() <-chan Event {
+    return sub.events
+}
+
+// Close unsubscribes from the stream and closes the events channel.
+func (sub *Subscription) Close() {
+    sub.once.Do(func() {
+        sub.stream.mu.Lock()
+        delete(sub.stream.subs, sub)
+        sub.stream.mu.Unlock()
+        close(sub.events)
+    })
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Derived from Messages**: Votes, elections and view changes of message-driven replicas are read off the
+//    envelopes a node sends rather than reported by the replicas themselves, and leader changes off the replica's
+//    Leader method. The replicas stay free of observability code, and any new algorithm that speaks the wire
+//    protocol produces events automatically. The in-process legacy blockchains exchange no messages, so they
+//    publish their votes and leader changes directly to an attached Publisher.
+//
+// 2. **Lossy Fan-Out**: Stream.Publish never waits for a subscriber. A visualization that cannot keep up must not slow
+//    down the protocol it is visualizing, so slow subscribers lose events and can detect the loss from Seq.
+//
+// 3. **One Event per Broadcast**: A broadcast is sent as one envelope per peer. Reporting each copy would make a
+//    single vote look like many, so copies that differ only in the recipient are collapsed into one event.
+//
+// 4. **Two Ways to Consume**: A Stream decouples fast producers from slow consumers through buffered channels,
+//    which suits network clients. A Bus calls hooks on the publisher's goroutine, which suits tests and metrics
+//    that must see every event and may inspect state at the moment it happened. A Bus hook can forward to a
+//    Stream, so one source can feed both.
@@ -0,0 +1,147 @@
+package evidence
+
+import (
+    "fmt"
+    "io"
+    "slices"
+
+    "consensus-algorithms-edu/wire"
+)
+
+// Violation is a breach of safety: two nodes committed different blocks at the same height.
+type Violation struct {
+    Height int64
+    Nodes  [2]int32       // The two nodes, lowest ID first.
+    Blocks [2]*wire.Block // The block each of them committed.
+}
+
+// Report is the outcome of a forensic investigation: the safety violations found among the investigated nodes,
+// and the evidence that convicts the nodes responsible.
+type Report struct {
+    Violations []Violation
+    Evidence   []*wire.Evidence // One piece of evidence per culprit, by culprit.
+}
+
+// Investigate runs the forensic analysis that follows a safety violation. It collects the transcripts of the
+// watched replicas — every signed statement each of them received — and their committed chains, normally from
+// the nodes that still behave honestly after the fact. The chains reveal the violation; the transcripts, merged,
+// reveal who caused it. A Byzantine node that broke safety had to tell the two sides different things, each
+// side alone saw one consistent story, and only together do they hold both signatures for the same slot.
+//
+// A BFT algorithm like PBFT is accountable in this sense: its quorums of 2f+1 out of 3f+1 overlap in at least
+// f+1 nodes, and two conflicting commits in the same view need every one of those to have signed both. So a
+// violation, which takes more than f faulty nodes, convicts at least f+1 of them; see Report.Accountable.
+func Investigate(keys Keyring, replicas ...*Replica) *Report {
+    report := &Report{}
+    for i, a := range replicas {
+        for _, b := range replicas[i+1:] {
+            if v, ok := diverge(a, b); ok && !slices.ContainsFunc(report.Violations, v.same) {
+                report.Violations = append(report.Violations, v)
+            }
+        }
+    }
+    merged := NewDetector(keys)
+    for _, r := range replicas {
+        for _, st := range r.detector.Statements() {
+            merged.Observe(st) // Every transcript was verified as it was received.
+        }
+        for _, ev := range r.detector.Evidence() {
+            merged.Convict(ev)
+        }
+    }
+    report.Evidence = merged.Evidence()
+    return report
+}
+
+// diverge returns the first height at which two replicas committed different blocks, if there is one.
+func diverge(a, b *Replica) (Violation, bool) {
+    x, y := a.Committed(), b.Committed()
+    for h := range min(len(x), len(y)) {
+        if x[h].GetHash() != y[h].GetHash() {
+            v := Violation{Height: int64(h), Nodes: [2]int32{a.ID(), b.ID()}, Blocks: [2]*wire.Block{x[h], y[h]}}
+            if v.Nodes[0] > v.Nodes[1] {
+                v.Nodes[0], v.Nodes[1] = v.Nodes[1], v.Nodes[0]
+                v.Blocks[0], v.Blocks[1] = v.Blocks[1], v.Blocks[0]
+            }
+            return v, true
+        }
+    }
+    return Violation{}, false
+}
+
+// same reports whether two violations are the same conflict between two blocks, seen by different nodes.
+func (v Violation) same(other Violation) bool {
+    return v.Height == other.Height && v.Blocks[0].GetHash() == other.Blocks[0].GetHash() && v.Blocks[1].GetHash() == other.Blocks[1].GetHash()
+}
+
+// Safe reports whether the investigated nodes agree on every block they all committed.
+func (r *Report) Safe() bool {
+    return len(r.Violations) == 0
+}
+
+// Culprits returns the nodes convicted by the evidence, in order.
+func (r *Report) Culprits() []int32 {
+    culprits := make([]int32, len(r.Evidence))
+    for i, ev := range r.Evidence {
+        culprits[i] = Offender(ev)
+    }
+    return culprits
+}
+
+// Accountable reports whether the investigation lived up to the accountability guarantee of a group that
+// tolerates f faults: either safety held, or at least f+1 nodes, more than the group was built to tolerate,
+// are convicted. A violation blamed on fewer would let the culprits deny it was their doing.
+func (r *Report) Accountable(f int) bool {
+    return r.Safe() || len(r.Evidence) > f
+}
+
+// Print writes the report: each violation, then each culprit with the two statements that convict it.
+func (r *Report) Print(w io.Writer) error {
+    if r.Safe() {
+        fmt.Fprintln(w, "no safety violation: the investigated nodes agree on every committed block")
+    }
+    for _, v := range r.Violations {
+        fmt.Fprintf(w, "safety violation at height %d: node %d committed %s %q, node %d committed %s %q\n",
+            v.Height, v.Nodes[0], short(v.Blocks[0].GetHash()), v.Blocks[0].GetData(), v.Nodes[1], short(v.Blocks[1].GetHash()), v.Blocks[1].GetData())
+    }
+    if len(r.Evidence) == 0 {
+        _, err := fmt.Fprintln(w, "no culprit: the transcripts hold no conflicting signed statements")
+        return err
+    }
+    culprits := "culprits, each"
+    if len(r.Evidence) == 1 {
+        culprits = "culprit,"
+    }
+    fmt.Fprintf(w, "%d %s convicted by two statements it signed:\n", len(r.Evidence), culprits)
+    for _, ev := range r.Evidence {
+        a, b := ev.GetFirst(), ev.GetSecond()
+        values := []string{a.GetValue(), b.GetValue()}
+        slices.Sort(values)
+        _, err := fmt.Fprintf(w, "  node %d: %s at height %d, round %d, for both %s and %s\n",
+            Offender(ev), a.GetKind(), a.GetHeight(), a.GetRound(), short(values[0]), short(values[1]))
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// short abbreviates a hash or digest for printing.
+func short(value string) string {
+    if len(value) > 12 {
+        return value[:12]
+    }
+    return value
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Transcripts, Not Logs**: The investigation uses only what the nodes received signed, never what they say
+//    happened. An honest node's transcript can be handed to anyone, and a Byzantine node's claims are not needed.
+//
+// 2. **Blame Follows From Signatures Alone**: A node is convicted only by two statements it signed for one slot,
+//    so an honest node is never blamed, however the transcripts were combined or who collected them.
+//
+// 3. **Safety Is Checked Separately From Blame**: Violations come from the committed chains and culprits from the
+//    transcripts. A report can show equivocation without a violation, when the honest quorum held, which is the
+//    case the f bound is about.
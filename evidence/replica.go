@@ -0,0 +1,221 @@
+package evidence
+
+import (
+    "crypto/ed25519"
+    "slices"
+
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/wire"
+)
+
+// Slasher is implemented by replicas that can punish a node proven to have equivocated, such as pos.Validator,
+// which takes away its stake. Slash is given the evidence rather than the offender alone, so a punishment that
+// lasts a while can be dated from the offence, which every replica holding the proof agrees on.
+type Slasher interface {
+    Slash(ev *wire.Evidence)
+}
+
+// Jailer is implemented by slashers that may release a punished node after a while, such as a pos.Validator
+// that unjails it. Once Jailed no longer reports a convicted node, a watched replica listens to it again.
+type Jailer interface {
+    Slasher
+    Jailed(id int32) bool
+}
+
+// Config configures a watched replica.
+type Config struct {
+    Key   ed25519.PrivateKey // Key the replica signs its statements with.
+    Keys  Keyring            // Public keys of every node, to check the statements and evidence it receives.
+    Peers []int32            // Every node of the cluster, the replica included, to gossip evidence to.
+
+    // Relay makes the replica pass every signed statement it receives on to its other peers, once. An
+    // equivocating node tells each of its victims one story, so without relaying a node sees both only if it
+    // was sent both; with it, every node sees every statement, at the cost of a message per peer for each.
+    Relay bool
+
+    // OnEvidence, if set, is called with each piece of evidence the replica finds or accepts, once per offender.
+    OnEvidence func(ev *wire.Evidence)
+}
+
+// Replica is a replica whose statements are signed and whose peers' statements are checked for equivocation.
+type Replica struct {
+    node.Replica           // Replica that does the actual work.
+    cfg          Config
+    detector     *Detector
+}
+
+// Watch wraps replica so that every proposal and vote it sends is signed with cfg.Key, and every one it receives
+// is checked: unsigned or forged statements are dropped before they reach it, and a statement that conflicts
+// with one its signer made before convicts the signer. The evidence is gossiped to every peer, passed to
+// cfg.OnEvidence, and, if replica or the replica it wraps is a Slasher, used to slash the offender. From then on
+// the offender's messages are dropped, unless the slasher is a Jailer and releases it.
+//
+// Every replica of a cluster must be watched, as its peers drop the statements it does not sign. A Byzantine
+// replica is watched around its adversary.Wrap, so the lies it tells are signed like the truth.
+func Watch(replica node.Replica, cfg Config) *Replica {
+    return &Replica{Replica: replica, cfg: cfg, detector: NewDetector(cfg.Keys)}
+}
+
+// Unwrap returns the replica inside, e.g. to inspect its state.
+func (r *Replica) Unwrap() node.Replica {
+    return r.Replica
+}
+
+// Detector returns the replica's detector, which holds the evidence it found or accepted.
+func (r *Replica) Detector() *Detector {
+    return r.detector
+}
+
+// Leader returns the leader the replica inside follows, or -1 if its algorithm has none.
+func (r *Replica) Leader() int32 {
+    if l, ok := r.Replica.(node.Leaderful); ok {
+        return l.Leader()
+    }
+    return -1
+}
+
+// Step checks an incoming envelope for equivocation and passes it to the replica inside unless its sender is
+// convicted and not released, or it makes a statement its sender did not sign. Evidence and relayed statements are consumed here.
+func (r *Replica) Step(env *wire.Envelope) []*wire.Envelope {
+    switch body := env.GetBody().(type) {
+    case *wire.Envelope_Evidence:
+        if convicted, err := r.detector.Convict(body.Evidence); err != nil || !convicted {
+            return nil
+        }
+        return r.act(body.Evidence)
+    case *wire.Envelope_Statement:
+        return r.observe(body.Statement, env.GetFrom())
+    }
+    if r.ignored(env.GetFrom()) {
+        return nil
+    }
+    var out []*wire.Envelope
+    if st, ok := StatementOf(env); ok {
+        st.Signature = env.GetSignature()
+        if r.cfg.Keys.Verify(st) != nil {
+            return nil
+        }
+        out = r.observe(st, env.GetFrom())
+        if r.ignored(env.GetFrom()) {
+            return out // The message that gave its sender away is not acted on either.
+        }
+    }
+    return append(out, r.sign(r.Replica.Step(env))...)
+}
+
+// Tick advances the replica inside and signs what it sends.
+func (r *Replica) Tick() []*wire.Envelope {
+    return r.sign(r.Replica.Tick())
+}
+
+// Propose submits data to the replica inside and signs what it sends.
+func (r *Replica) Propose(data string) ([]*wire.Envelope, error) {
+    out, err := r.Replica.Propose(data)
+    return r.sign(out), err
+}
+
+// observe records a statement received from a peer, relays it if it is new and cfg.Relay is set, and acts on
+// the evidence it completes.
+func (r *Replica) observe(st *wire.Statement, from int32) []*wire.Envelope {
+    first, seen := r.detector.seen[slotOf(st)]
+    fresh := !(seen && first.GetValue() == st.GetValue()) && !r.detector.Convicted(st.GetSigner())
+    ev, err := r.detector.Observe(st)
+    if err != nil {
+        return nil
+    }
+    var out []*wire.Envelope
+    if r.cfg.Relay && fresh {
+        out = r.broadcast(&wire.Envelope{Body: &wire.Envelope_Statement{Statement: st}}, from, st.GetSigner())
+    }
+    if ev != nil {
+        out = append(out, r.act(ev)...)
+    }
+    return out
+}
+
+// act reports evidence, slashes its offender if the replica can, and gossips it to every peer.
+func (r *Replica) act(ev *wire.Evidence) []*wire.Envelope {
+    if r.cfg.OnEvidence != nil {
+        r.cfg.OnEvidence(ev)
+    }
+    if s := r.slasher(); s != nil {
+        s.Slash(ev)
+    }
+    return r.broadcast(&wire.Envelope{Body: &wire.Envelope_Evidence{Evidence: ev}})
+}
+
+// slasher returns the replica inside that can punish offenders, unwrapping as far as needed, or nil if none can.
+func (r *Replica) slasher() Slasher {
+    var inner node.Replica = r.Replica
+    for inner != nil {
+        if s, ok := inner.(Slasher); ok {
+            return s
+        }
+        u, ok := inner.(interface{ Unwrap() node.Replica })
+        if !ok {
+            return nil
+        }
+        inner = u.Unwrap()
+    }
+    return nil
+}
+
+// ignored reports whether the messages of a node are dropped: it is convicted, and not released since by a
+// Jailer that punished it.
+func (r *Replica) ignored(id int32) bool {
+    if !r.detector.Convicted(id) {
+        return false
+    }
+    j, ok := r.slasher().(Jailer)
+    return !ok || j.Jailed(id)
+}
+
+// broadcast addresses the body of msg to every peer but the replica itself and the given nodes.
+func (r *Replica) broadcast(msg *wire.Envelope, except ...int32) []*wire.Envelope {
+    var out []*wire.Envelope
+    for _, peer := range r.cfg.Peers {
+        if peer != r.ID() && !slices.Contains(except, peer) {
+            out = append(out, &wire.Envelope{From: r.ID(), To: peer, Body: msg.GetBody()})
+        }
+    }
+    return out
+}
+
+// sign returns out with a signature on every envelope that makes a statement. Envelopes of a broadcast share
+// their body, so each body is signed once, and the signed copies keep sharing it.
+func (r *Replica) sign(out []*wire.Envelope) []*wire.Envelope {
+    signatures := make(map[any][]byte)
+    signed := make([]*wire.Envelope, len(out))
+    for i, env := range out {
+        signature, ok := signatures[env.GetBody()]
+        if !ok {
+            if st, ok := StatementOf(env); ok {
+                Sign(r.cfg.Key, st)
+                signature = st.GetSignature()
+            }
+            signatures[env.GetBody()] = signature
+        }
+        signed[i] = env
+        if signature != nil {
+            signed[i] = &wire.Envelope{From: env.GetFrom(), To: env.GetTo(), Signature: signature, Body: env.GetBody()}
+        }
+    }
+    return signed
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **A Wrapper, Like the Adversary**: Signing and checking happen around a replica, not inside it, the same way
+//    package adversary's lies do. No algorithm changes to become accountable, and an adversary wrapped inside a
+//    watcher signs its lies with its own key, as a real Byzantine node would have to.
+//
+// 2. **Signatures Beside the Body**: The signature travels in the envelope, not in each algorithm's message, so
+//    the messages keep their schema and a broadcast still shares one body between its envelopes, which the
+//    simulator's gossip relies on to recognize it.
+//
+// 3. **Relaying Is Opt-In**: Equivocation is only visible to a node that hears both stories. Relaying every
+//    statement makes that certain but multiplies traffic, so it is a choice made per cluster, not a default.
+//
+// 4. **Punishment Belongs to the Algorithm**: The watcher only convicts. What a conviction costs is up to the
+//    algorithm — stake for a validator through Slasher — and beyond that the offender is simply ignored, for
+//    good or, through Jailer, until the algorithm lets it back.
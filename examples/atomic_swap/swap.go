@@ -0,0 +1,163 @@
+// Package main demonstrates an atomic swap between two independent chains with hash time-locked contracts.
+// Alice holds coins on a Proof of Work chain and Bob holds coins on a Proof of Stake chain; neither chain knows
+// about the other, and neither party trusts the other to pay first. Alice locks the PoW coins to Bob under the
+// hash of a secret only Alice knows, and Bob locks the PoS coins to Alice under the same hash. Alice can only
+// take Bob's coins by revealing the secret on the PoS chain, and the moment that happens, Bob can read it there
+// and take Alice's. If
+// either party walks away before that, the locks expire and both get their coins back: the swap happens
+// entirely or not at all.
+package main
+
+import (
+    "errors"
+    "fmt"
+
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/options"
+)
+
+// The locks on each chain. Bob's expires sooner, in blocks of the PoS chain, so that once Alice has claimed Bob's
+// coins before that lock expires, Bob still has time to claim Alice's with the revealed secret before Alice's
+// lock expires.
+const (
+    aliceLock   = "alice-to-bob"
+    bobLock     = "bob-to-alice"
+    aliceExpiry = 6 // Blocks after Alice's lock.
+    bobExpiry   = 3 // Blocks after Bob's lock.
+)
+
+// chains are the two chains and the accounts their blocks update.
+type chains struct {
+    pow      *pow.Blockchain
+    pos      *pos.Blockchain
+    powState *ledger.Accounts
+    posState *ledger.Accounts
+}
+
+// newChains returns the chains before the swap: Alice holds 100 coins on the PoW chain, Bob 50 on the PoS chain.
+func newChains() chains {
+    c := chains{
+        pow:      pow.NewBlockchain(),
+        pos:      pos.NewBlockchain([]string{"Validator1", "Validator2"}, map[string]int{"Validator1": 60, "Validator2": 40}, options.WithSeed(1)),
+        powState: ledger.NewAccounts(map[string]uint64{"alice": 100}),
+        posState: ledger.NewAccounts(map[string]uint64{"bob": 50}),
+    }
+    c.pow.AttachState(c.powState)
+    c.pos.AttachState(c.posState)
+    return c
+}
+
+// print shows both parties' balances on both chains.
+func (c chains) print() {
+    fmt.Printf("  PoW chain: alice %3d  bob %3d\n", c.powState.Balance("alice"), c.powState.Balance("bob"))
+    fmt.Printf("  PoS chain: alice %3d  bob %3d\n", c.posState.Balance("alice"), c.posState.Balance("bob"))
+}
+
+// lock has both parties lock their coins: Alice first, then Bob if Bob goes ahead, under the hash Alice's lock
+// shows him.
+func (c chains) lock(secret string, bobGoesAhead bool) error {
+    alice := ledger.Lock{ID: aliceLock, From: "alice", To: "bob", Amount: 100, Hash: ledger.HashLock(secret), Expiry: c.powState.Height() + aliceExpiry}
+    if _, err := c.pow.AddBlock(ledger.EncodeLock(alice)); err != nil {
+        return err
+    }
+    fmt.Printf("Alice locks 100 on the PoW chain until block %d\n", alice.Expiry)
+    if !bobGoesAhead {
+        fmt.Println("Bob never locks anything")
+        return nil
+    }
+
+    seen, _ := c.powState.HTLC(aliceLock) // Bob checks Alice's lock before locking anything.
+    bob := ledger.Lock{ID: bobLock, From: "bob", To: "alice", Amount: 50, Hash: seen.Hash, Expiry: c.posState.Height() + bobExpiry}
+    if _, err := c.pos.AddBlock(ledger.EncodeLock(bob)); err != nil {
+        return err
+    }
+    fmt.Printf("Bob locks 50 on the PoS chain until block %d\n", bob.Expiry)
+    return nil
+}
+
+// revealedSecret returns the secret that a committed block of the PoS chain revealed by claiming Bob's lock.
+func (c chains) revealedSecret() (string, bool) {
+    for _, block := range c.pos.All() {
+        if op, _ := ledger.DecodeHTLC(block.Data); op != nil && op.Claim == bobLock {
+            return op.Secret, true
+        }
+    }
+    return "", false
+}
+
+// swap runs the swap to completion: Alice claims Bob's coins, revealing the secret, and Bob claims Alice's with it.
+func swap() error {
+    c := newChains()
+    if err := c.lock("correct horse battery staple", true); err != nil {
+        return err
+    }
+
+    if _, err := c.pos.AddBlock(ledger.EncodeClaim(bobLock, "correct horse battery staple")); err != nil {
+        return err
+    }
+    fmt.Println("Alice claims Bob's 50, revealing the secret on the PoS chain")
+
+    secret, ok := c.revealedSecret()
+    if !ok {
+        return errors.New("no claim on the PoS chain")
+    }
+    if _, err := c.pow.AddBlock(ledger.EncodeClaim(aliceLock, secret)); err != nil {
+        return err
+    }
+    fmt.Println("Bob reads the secret from the PoS chain and claims Alice's 100")
+    c.print()
+    return nil
+}
+
+// refund runs the swap with Bob walking away: Alice cannot take the coins back until the lock expires, then does.
+func refund() error {
+    c := newChains()
+    if err := c.lock("correct horse battery staple", false); err != nil {
+        return err
+    }
+
+    _, err := c.pow.AddBlock(ledger.EncodeRefund(aliceLock))
+    fmt.Println("Alice asks for a refund at once:", err)
+    lock, _ := c.powState.HTLC(aliceLock)
+    for c.powState.Height() < lock.Expiry { // Blocks other users mine meanwhile.
+        if _, err := c.pow.AddBlock("Unrelated block"); err != nil {
+            return err
+        }
+    }
+    if _, err := c.pow.AddBlock(ledger.EncodeRefund(aliceLock)); err != nil {
+        return err
+    }
+    fmt.Printf("At block %d the lock has expired and Alice takes the 100 back\n", c.powState.Height()-1)
+    c.print()
+    return nil
+}
+
+func main() {
+    fmt.Println("Atomic swap:")
+    if err := swap(); err != nil {
+        fmt.Println("Swap failed:", err)
+        return
+    }
+    fmt.Println()
+    fmt.Println("Refund after Bob walks away:")
+    if err := refund(); err != nil {
+        fmt.Println("Refund failed:", err)
+    }
+}
+
+// Footer: Overview and Execution Flow
+//
+// The example runs the protocol twice on fresh chains: once to completion and once with Bob walking away.
+//
+// Key Steps:
+// 1. **Locking**: Alice locks the PoW coins to Bob under the hash of the secret. Bob, having seen that lock on
+//    the PoW chain, locks the PoS coins to Alice under the same hash, with an earlier expiry.
+// 2. **Claiming**: Alice claims Bob's coins, which takes putting the secret in a PoS block. Bob reads it there
+//    and claims Alice's coins with it on the PoW chain before Alice's lock expires.
+// 3. **Refunding**: If Bob never locks, Alice has nothing to claim and the secret stays secret; once Alice's
+//    lock expires, at a height of the PoW chain, Alice takes the coins back.
+//
+// Neither chain checks anything about the other. Atomicity comes only from the shared hash and the expiries:
+// Alice cannot take Bob's coins without handing Bob the means to take Alice's.
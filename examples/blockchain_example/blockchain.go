@@ -13,16 +13,17 @@ func main() {
     // Initialize a new blockchain using the Proof of Work algorithm.
     blockchain := pow.NewBlockchain()
 
-    // Add new blocks with specific data to the blockchain.
-    blockchain.AddBlock("First block data")
-    blockchain.AddBlock("Second block data")
-    blockchain.AddBlock("Third block data")
-
-    // Iterate over each block in the blockchain and print the block's details.
-    for _, block := range blockchain.Blocks {
-        fmt.Printf("Index: %d\nTimestamp: %s\nData: %s\nPrevious Hash: %s\nHash: %s\n\n", 
-            block.Index, block.Timestamp, block.Data, block.PrevHash, block.Hash)
+    // Add new blocks with specific data to the blockchain, stopping at the first one that cannot be added.
+    for _, data := range []string{"First block data", "Second block data", "Third block data"} {
+        if _, err := blockchain.AddBlock(data); err != nil {
+            fmt.Println("Failed to add block:", err)
+            return
+        }
     }
+
+    // Print the chain: a summary line, then one line per block.
+    fmt.Println(blockchain)
+    fmt.Print(blockchain.Dump())
 }
 
 // Footer: Overview and Execution Flow
@@ -35,7 +36,7 @@ func main() {
 // 1. **Blockchain Initialization**: The blockchain is initialized using `pow.NewBlockchain()`, which creates the Genesis block.
 // 2. **Block Addition**: New blocks are added using the `AddBlock()` function, which mines each block before adding it to the blockchain.
 // 3. **Block Mining**: Each block requires a valid hash to be found through a Proof of Work computation, which ensures the blockchain's immutability.
-// 4. **Block Data Display**: After the blockchain is constructed, the details of each block, such as index, timestamp, data, previous hash, and current hash, are printed.
+// 4. **Block Data Display**: After the blockchain is constructed, `Dump()` prints each block's index, hash, previous hash and data, one line per block.
 //
 // The primary purpose of this example is to demonstrate how the Proof of Work consensus mechanism ensures that each new block
 // added to the blockchain is computationally verified, making the blockchain secure and immutable.
@@ -0,0 +1,128 @@
+// Package main shows how a difficulty bomb forces a hard fork. The bomb is a component of the Proof of Work
+// difficulty that doubles every few blocks regardless of hash power: for a long time it is too small to notice,
+// then block times double, then they grow so fast that the chain freezes. Ethereum shipped one to make sure
+// miners would move to Proof of Stake rather than stay on the old chain, and pushed it back with a hard fork
+// whenever the move was not ready. The example mines the same network three times — without a bomb, with one,
+// and with one a fork delays — and prints the average block time over each stretch of the chain.
+package main
+
+import (
+    "fmt"
+    "os"
+    "text/tabwriter"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+)
+
+const (
+    miners      = 5
+    probability = 0.0002 // Each miner's chance per 10ms tick: a block every 10s across the network.
+    target      = 200    // Height each run mines to.
+    window      = 20     // Blocks averaged in each row.
+    limit       = 4 * time.Hour
+)
+
+// scenarios are the bombs compared. The bomb goes off at block 60, where blocks take twice as long, and doubles
+// every 20 blocks; the fork at block 100 pushes it back by 60 blocks, as Ethereum's Muir Glacier and Gray Glacier
+// forks pushed back theirs.
+var scenarios = []struct {
+    name string
+    bomb pow.Bomb
+}{
+    {"no bomb", pow.Bomb{}},
+    {"bomb", pow.Bomb{Start: 60, Period: 20}},
+    {"bomb, fork at 100", pow.Bomb{Start: 60, Period: 20, Delays: []pow.Delay{{Height: 100, Blocks: 60}}}},
+}
+
+// run mines until the first miner's chain reaches target, or for limit, with every miner applying bomb. It
+// returns the time each block of that chain was mined at, from the start of the run.
+func run(bomb pow.Bomb) []time.Duration {
+    s := sim.New(sim.Config{Seed: 1, Network: sim.Link{Latency: sim.Uniform(10*time.Millisecond, 50*time.Millisecond)}})
+    peers := make([]int32, miners)
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    var observer *pow.Miner
+    for _, id := range peers {
+        m := pow.NewMiner(pow.MinerConfig{ID: id, Peers: peers, MineProbability: probability, Bomb: bomb, Rand: s.NewRand(), Clock: s.Clock()})
+        if observer == nil {
+            observer = m
+        }
+        s.Add(m)
+    }
+    s.RunUntil(func() bool { return observer.Head().GetIndex() >= target }, limit)
+    var mined []time.Duration
+    for _, block := range observer.Chain()[1:] { // The genesis block was not mined.
+        mined = append(mined, wire.Timestamp(block.GetTimestamp()).Time().Sub(sim.Epoch))
+    }
+    return mined
+}
+
+func main() {
+    fmt.Printf("%d miners, a block every 10s without the bomb, mining to block %d for at most %v\n\n", miners, target, limit)
+    var runs [][]time.Duration
+    for _, sc := range scenarios {
+        runs = append(runs, run(sc.bomb))
+    }
+
+    tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprint(tw, "blocks\t")
+    for _, sc := range scenarios {
+        fmt.Fprintf(tw, "%s\t", sc.name)
+    }
+    fmt.Fprintln(tw)
+    for from := 0; from < target; from += window {
+        fmt.Fprintf(tw, "%d-%d\t", from+1, from+window)
+        for _, mined := range runs {
+            fmt.Fprintf(tw, "%s\t", average(mined, from))
+        }
+        fmt.Fprintln(tw)
+    }
+    fmt.Fprint(tw, "reached\t")
+    for _, mined := range runs {
+        if len(mined) >= target {
+            fmt.Fprintf(tw, "%v\t", mined[target-1].Round(time.Minute))
+        } else {
+            fmt.Fprintf(tw, "block %d\t", len(mined))
+        }
+    }
+    fmt.Fprintln(tw)
+    tw.Flush()
+}
+
+// average returns the average time between the blocks from+1 to from+window of a chain, counting the first from
+// its parent, or "-" if the chain has none of them. A window the run stopped in is marked with a "+": the time
+// since its last block does not count, so the true average is higher.
+func average(mined []time.Duration, from int) string {
+    if from >= len(mined) {
+        return "-"
+    }
+    start := time.Duration(0)
+    if from > 0 {
+        start = mined[from-1]
+    }
+    to := min(from+window, len(mined))
+    avg := ((mined[to-1] - start) / time.Duration(to-from)).Round(time.Second)
+    if to < from+window {
+        return fmt.Sprintf("%v+", avg)
+    }
+    return avg.String()
+}
+
+// Footer: Overview and Execution Flow
+//
+// 1. **Same Network, Three Protocols**: Every run mines with the same seed, miners and latencies; only the bomb in
+//    pow.MinerConfig differs. A miner of package pow finds a block with a fixed chance per tick, which stands for
+//    its hash power, so the bomb divides that chance by pow.Bomb.Factor of the next height: twice the work, half
+//    the chance.
+//
+// 2. **Block Times from the Chain**: The times come from the timestamps of the first miner's chain once the run
+//    ends, so blocks that lost a fork are not counted, as a block explorer would not count them.
+//
+// 3. **The Fork Is a Config Change**: Every miner of the third run applies the delay from block 100 on, as if all
+//    of them upgraded in time. A miner that did not would keep the old bomb and, once the chains differed in
+//    difficulty, mine a chain of its own; the abstract miners here do not check each other's work, so the
+//    example cannot show that split.
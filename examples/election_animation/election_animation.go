@@ -0,0 +1,188 @@
+// Package main animates a Raft election, one frame per step. Five replicas start as followers without a leader;
+// the example steps through the simulation with a sim.Stepper, which stops at every step another replica can
+// see — an election timeout firing, a vote requested, granted or refused, a heartbeat — and draws each step with
+// the viz package. Every frame is printed to the terminal as text and written as an SVG image, so the election
+// can be read in a terminal or flipped through like slides: the timeouts firing, the votes flowing back to the
+// candidate, and the leader emerging once a majority has voted for it and its first heartbeats arrive.
+package main
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/viz"
+    "consensus-algorithms-edu/wire"
+)
+
+const (
+    replicas  = 5
+    maxFrames = 60                // Frames drawn at most, should the election drag on.
+    framesDir = "election_frames" // Directory the SVG frames are written to.
+    within    = 2 * time.Second   // Virtual time a step may take to come before the example gives up.
+)
+
+// cluster is the simulation, its replicas and the votes each candidate has been granted in its current term.
+type cluster struct {
+    *sim.Simulator
+    replicas []*raft.Replica
+    votes    map[int32]int
+}
+
+func newCluster() *cluster {
+    s := sim.New(sim.Config{Seed: 11, Network: sim.Link{Latency: sim.Uniform(5*time.Millisecond, 15*time.Millisecond)}})
+    c := &cluster{Simulator: s, votes: make(map[int32]int)}
+    peers := make([]int32, replicas)
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    for _, id := range peers {
+        r := raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: peers, Rand: s.NewRand(), Clock: s.Clock()})
+        c.replicas = append(c.replicas, r)
+        s.Add(r)
+    }
+    return c
+}
+
+func main() {
+    c := newCluster()
+    stepper := sim.NewStepper(c.Simulator)
+    if err := os.MkdirAll(framesDir, 0o755); err != nil {
+        fmt.Println("Error:", err)
+        os.Exit(1)
+    }
+
+    frames := 0
+    draw := func(caption string, arrows []viz.Arrow) {
+        f := c.frame(caption, arrows)
+        fmt.Printf("\n── frame %d\n", frames)
+        viz.FrameText(os.Stdout, f, viz.Options{})
+        path := filepath.Join(framesDir, fmt.Sprintf("frame-%02d.svg", frames))
+        if err := writeSVG(path, f, fmt.Sprintf("Raft election, frame %d", frames)); err != nil {
+            fmt.Println("Error:", err)
+            os.Exit(1)
+        }
+        frames++
+    }
+
+    draw(fmt.Sprintf("%v  %d followers, no leader; each waits out its own randomized election timeout", at(c.Now()), replicas), nil)
+    for frames < maxFrames && !c.settled() {
+        t, ok := stepper.Next(within)
+        if !ok {
+            fmt.Println("the cluster went quiet without electing a leader")
+            os.Exit(1)
+        }
+        caption := c.narrate(t)
+        arrows := make([]viz.Arrow, len(t.Output))
+        for i, env := range t.Output {
+            arrows[i] = viz.Arrow{From: env.GetFrom(), To: env.GetTo(), Label: env.Kind()}
+        }
+        draw(fmt.Sprintf("%v  %s", at(t.At), caption), arrows)
+    }
+    if !c.settled() {
+        fmt.Printf("\nno leader was followed by every replica within %d frames\n", maxFrames)
+        os.Exit(1)
+    }
+    leader := c.replicas[0].Leader()
+    fmt.Printf("\n%s leads term %d and every replica follows it; %d frames written to %s/\n", node.Name(leader), c.replicas[leader].Term(), frames, framesDir)
+}
+
+// narrate describes a step of the election in a sentence, counting the votes each candidate receives.
+func (c *cluster) narrate(t sim.Transition) string {
+    r := c.replicas[t.Node]
+    name := node.Name(t.Node)
+    switch t.Input {
+    case sim.TickInput:
+        switch t.Output[0].GetBody().(type) {
+        case *wire.Envelope_RequestVote:
+            c.votes[t.Node] = 1 // A candidate votes for itself.
+            return fmt.Sprintf("%s's election timeout fires: it becomes a candidate for term %d, votes for itself and asks the others", name, r.Term())
+        case *wire.Envelope_AppendEntries:
+            return fmt.Sprintf("%s sends heartbeats to keep its followers from timing out", name)
+        }
+    case sim.DeliverInput:
+        from := node.Name(t.Envelope.GetFrom())
+        switch body := t.Envelope.GetBody().(type) {
+        case *wire.Envelope_RequestVote:
+            if len(t.Output) > 0 {
+                if response := t.Output[0].GetRequestVoteResponse(); response.GetVoteGranted() {
+                    return fmt.Sprintf("%s grants %s its vote for term %d", name, from, body.RequestVote.GetTerm())
+                }
+            }
+            return fmt.Sprintf("%s refuses %s its vote for term %d: it has voted already, or knows a later term", name, from, body.RequestVote.GetTerm())
+        case *wire.Envelope_RequestVoteResponse:
+            if !body.RequestVoteResponse.GetVoteGranted() {
+                return fmt.Sprintf("%s learns %s refused it", name, from)
+            }
+            if body.RequestVoteResponse.GetTerm() == r.Term() {
+                c.votes[t.Node]++
+            }
+            if r.Role() == raft.Leader && len(t.Output) > 0 {
+                return fmt.Sprintf("%s has %d of %d votes, a majority: it leads term %d and announces itself with heartbeats", name, c.votes[t.Node], replicas, r.Term())
+            }
+            return fmt.Sprintf("%s counts %s's vote: %d of %d", name, from, c.votes[t.Node], replicas)
+        case *wire.Envelope_AppendEntries:
+            return fmt.Sprintf("%s hears from %s, the leader of term %d, and follows it", name, from, body.AppendEntries.GetTerm())
+        case *wire.Envelope_AppendEntriesResponse:
+            return fmt.Sprintf("%s hears %s acknowledge it as leader", name, from)
+        }
+    }
+    return t.String()
+}
+
+// frame draws the cluster as it stands, with the messages just sent.
+func (c *cluster) frame(caption string, arrows []viz.Arrow) viz.Frame {
+    f := viz.Frame{Caption: caption, Arrows: arrows}
+    for _, r := range c.replicas {
+        label := fmt.Sprintf("term %d", r.Term())
+        if r.Role() == raft.Candidate {
+            label += fmt.Sprintf(", %d votes", c.votes[r.ID()])
+        }
+        f.Members = append(f.Members, viz.Member{ID: r.ID(), Role: r.Role().String(), Label: label, Down: c.Crashed(r.ID())})
+    }
+    return f
+}
+
+// settled reports whether every replica follows the same leader, which has been elected.
+func (c *cluster) settled() bool {
+    leader := c.replicas[0].Leader()
+    if leader < 0 || c.replicas[leader].Role() != raft.Leader {
+        return false
+    }
+    for _, r := range c.replicas {
+        if r.Leader() != leader {
+            return false
+        }
+    }
+    return true
+}
+
+// writeSVG writes a frame to a file as an SVG image.
+func writeSVG(path string, f viz.Frame, title string) error {
+    out, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    defer out.Close()
+    return viz.FrameSVG(out, f, viz.Options{Title: title})
+}
+
+// at formats a virtual time to the millisecond.
+func at(t time.Duration) time.Duration {
+    return t.Round(time.Millisecond)
+}
+
+// Footer: Overview and Execution Flow
+//
+// 1. **Stepping**: sim.Stepper stops at every significant transition: deliveries, and ticks that send something.
+//    Ticks that only count a timer down are skipped, so the animation starts with the first timeout to fire.
+// 2. **Drawing**: Each step becomes a viz.Frame — every replica's role and term, the votes of a candidate, and an
+//    arrow for each message the step sent — printed with viz.FrameText and saved with viz.FrameSVG.
+// 3. **Narration**: The caption reads the step: a timeout starting a campaign, a vote granted or refused, a
+//    candidate counting votes until it has a majority, and the first heartbeats of the new leader.
+// 4. **The End**: The animation stops once every replica follows the same elected leader, which is when the
+//    election is over for the whole cluster and not only for the winner.
@@ -0,0 +1,167 @@
+// Package main compares a node that fails slow with one that crashes, in Raft and in PBFT. A crashed leader is
+// the failure both algorithms are built to detect: it goes silent, its followers time out, and a new leader takes
+// over within an election or a view change. A leader that is merely slow — a process stalled by garbage
+// collection, a failing disk, an overloaded host — still sends heartbeats and votes, only late, and neither
+// algorithm is sure whether to replace it. The example measures what that costs: how often the leadership
+// changes and how long transactions take to commit.
+package main
+
+import (
+    "fmt"
+    "os"
+    "text/tabwriter"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/stats"
+    "consensus-algorithms-edu/wire"
+)
+
+const (
+    replicas = 5
+    failAt   = 2 * time.Second        // When a replica fails.
+    interval = 250 * time.Millisecond // Between two transactions.
+    duration = 30 * time.Second
+)
+
+// processing is how long a slow replica takes for each message and transaction it handles: 20ms on average,
+// about what it takes a healthy leader to hear back from its followers.
+var processing = sim.Uniform(0, 40*time.Millisecond)
+
+// condition is what happens at failAt to the leader, or to the replica after it.
+type condition struct {
+    name string
+    fail func(s *sim.Simulator, leader int32)
+}
+
+var conditions = []condition{
+    {"healthy", func(*sim.Simulator, int32) {}},
+    {"crashed leader", func(s *sim.Simulator, leader int32) { s.Network.Partition([]int32{leader}, others(leader)) }},
+    {"slow leader", func(s *sim.Simulator, leader int32) { s.Slow(leader, processing) }},
+    {"slow follower", func(s *sim.Simulator, leader int32) { s.Slow((leader+1)%replicas, processing) }},
+}
+
+// algorithms builds the replicas of each algorithm compared.
+var algorithms = []struct {
+    name  string
+    build func(s *sim.Simulator, id int32, peers []int32) node.Replica
+}{
+    {"raft", func(s *sim.Simulator, id int32, peers []int32) node.Replica {
+        return raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: peers, Rand: s.NewRand(), Clock: s.Clock()})
+    }},
+    {"pbft", func(s *sim.Simulator, id int32, peers []int32) node.Replica {
+        return pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: peers, Clock: s.Clock()})
+    }},
+}
+
+// result is how a cluster fared.
+type result struct {
+    changes   int // Times the leader most replicas follow changed after the failure.
+    submitted int
+    committed int
+    latency   stats.Latency // From submitting a transaction to its commit by a majority.
+}
+
+// run submits a transaction every interval to the leader most replicas follow, fails that leader at failAt and
+// measures the cluster until duration.
+func run(build func(s *sim.Simulator, id int32, peers []int32) node.Replica, fail func(s *sim.Simulator, leader int32)) result {
+    s := sim.New(sim.Config{Seed: 3, Network: sim.Link{Latency: sim.Uniform(2*time.Millisecond, 8*time.Millisecond)}})
+    peers := others(-1)
+    for _, id := range peers {
+        s.Add(build(s, id, peers))
+    }
+
+    var res result
+    submitted := map[string]time.Duration{}
+    commits := map[string]int{}
+    var latencies []time.Duration
+    s.OnCommit = func(id int32, block *wire.Block) {
+        data := block.GetData()
+        if at, ok := submitted[data]; ok {
+            if commits[data]++; commits[data] == replicas/2+1 {
+                latencies = append(latencies, s.Now()-at)
+            }
+        }
+    }
+    leader := int32(-1)
+    var watch func()
+    watch = func() {
+        if l := majorityLeader(s); l >= 0 && l != leader {
+            if leader >= 0 && s.Now() > failAt {
+                res.changes++
+            }
+            leader = l
+        }
+        s.At(s.Now()+10*time.Millisecond, watch)
+    }
+    s.At(0, watch)
+    for at := interval; at < duration-5*time.Second; at += interval {
+        data := fmt.Sprintf("tx %d", res.submitted+1)
+        res.submitted++
+        s.At(at, func() {
+            submitted[data] = s.Now()
+            for _, id := range s.Nodes() {
+                s.Propose(id, data) // A Raft follower refuses it; a PBFT backup forwards it to the primary and watches it.
+            }
+        })
+    }
+    s.At(failAt, func() { fail(s, leader) })
+    s.RunFor(duration)
+    res.committed = len(latencies)
+    res.latency = stats.Summarize(latencies)
+    return res
+}
+
+// majorityLeader returns the leader a majority of the replicas follow, or -1 if there is none.
+func majorityLeader(s *sim.Simulator) int32 {
+    votes := map[int32]int{}
+    for _, id := range s.Nodes() {
+        if l := s.Replica(id).(node.Leaderful).Leader(); l >= 0 {
+            if votes[l]++; votes[l] > replicas/2 {
+                return l
+            }
+        }
+    }
+    return -1
+}
+
+// others returns every replica but id.
+func others(id int32) []int32 {
+    var ids []int32
+    for i := range int32(replicas) {
+        if i != id {
+            ids = append(ids, i)
+        }
+    }
+    return ids
+}
+
+func main() {
+    fmt.Printf("%d replicas, one fails at %v, a transaction every %v for %v\n\n", replicas, failAt, interval, duration-5*time.Second)
+    tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintln(tw, "algorithm\tcondition\tleader changes\tcommitted\tp50\tp99\tmax\t")
+    for _, algorithm := range algorithms {
+        for _, c := range conditions {
+            res := run(algorithm.build, c.fail)
+            fmt.Fprintf(tw, "%s\t%s\t%d\t%d/%d\t%v\t%v\t%v\t\n", algorithm.name, c.name, res.changes, res.committed, res.submitted,
+                res.latency.Percentile(50).Round(time.Millisecond), res.latency.Percentile(99).Round(time.Millisecond), res.latency.Max.Round(time.Millisecond))
+        }
+    }
+    tw.Flush()
+}
+
+// Footer: Overview and Execution Flow
+//
+// 1. **Same Run, Three Leaders**: Every cluster gets the same transactions on the same network; only what happens
+//    at failAt differs: nothing, a crashed leader (a partition cuts it off), or a slow leader or follower
+//    (sim.Simulator.Slow), which handles each message and transaction in 20ms on average but ticks on time.
+//
+// 2. **Watching the Leadership**: Every 10ms of virtual time, the example asks each replica whom it follows, and
+//    counts a change whenever the leader a majority follows is a different replica than before.
+//
+// 3. **Latency**: A transaction's latency runs from its submission to the moment a majority of replicas committed
+//    it. Every transaction is submitted to every replica, as a client that does not know the leader would: Raft
+//    followers refuse it, while PBFT backups forward it to the primary and start the timer that suspects it.
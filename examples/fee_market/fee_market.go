@@ -0,0 +1,170 @@
+// Package main simulates a fee market. Proof of Work miners build blocks of at most ten transfers from their
+// mempools, taking the highest fees first, while users send transfers with fees they choose at random. Demand
+// starts below what the blocks carry, rises above it for a few minutes and falls back. The example prints, per
+// minute, how many transfers were sent and included, the lowest fee that still got into a block, and how long
+// transfers waited, and then how long each range of fees waited over the whole run.
+package main
+
+import (
+    "fmt"
+    "math/rand"
+    "os"
+    "text/tabwriter"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/mempool"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/stats"
+    "consensus-algorithms-edu/wire"
+)
+
+const (
+    miners      = 4
+    probability = 0.00125 // Each miner's chance per 10ms tick: a block every 2s across the network.
+    perBlock    = 10      // Transfers a block's gas limit allows.
+    capacity    = 150     // Transfers each mempool holds.
+    maxFee      = 100     // Fees are drawn uniformly from 1 to maxFee.
+    duration    = 12 * time.Minute
+)
+
+// demand is the number of transfers users send per second, by minute. The blocks carry 5 per second, so minutes
+// 3 to 6 are congested.
+var demand = []int{3, 3, 8, 8, 8, 8, 3, 3, 3, 3}
+
+// sent is a transfer a user sent.
+type sent struct {
+    transfer ledger.Transfer
+    at       time.Duration // When it was sent.
+    rejected bool          // Whether the first miner's full mempool turned it away.
+}
+
+func main() {
+    s := sim.New(sim.Config{Seed: 1, Network: sim.Link{Latency: sim.Uniform(10*time.Millisecond, 30*time.Millisecond)}})
+    rng := rand.New(rand.NewSource(1))
+
+    var txs []*sent
+    for minute, rate := range demand {
+        for i := range rate * 60 {
+            at := time.Duration(minute)*time.Minute + time.Duration(i)*time.Minute/time.Duration(rate*60) + time.Duration(rng.Intn(1000))*time.Microsecond
+            t := ledger.Transfer{From: fmt.Sprintf("user-%d", len(txs)), To: "shop", Amount: 10, Fee: uint64(1 + rng.Intn(maxFee))}
+            txs = append(txs, &sent{transfer: t, at: at})
+        }
+    }
+
+    peers := make([]int32, miners)
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    var observer *pow.Miner
+    for _, id := range peers {
+        m := pow.NewMiner(pow.MinerConfig{
+            ID: id, Peers: peers, MineProbability: probability, Rand: s.NewRand(), Clock: s.Clock(),
+            Mempool: mempool.New(mempool.Config{Capacity: capacity, Limits: mempool.Limits{Gas: perBlock * mempool.TransferGas}}),
+        })
+        if observer == nil {
+            observer = m
+        }
+        s.Add(m)
+    }
+    for _, tx := range txs {
+        s.At(tx.at, func() {
+            for _, id := range peers {
+                if err := s.Propose(id, ledger.EncodeTransfers(tx.transfer)); err != nil && id == 0 {
+                    tx.rejected = true
+                }
+            }
+        })
+    }
+    s.RunFor(duration)
+
+    included := map[string]time.Duration{} // When the block carrying each user's transfer was mined.
+    for _, block := range observer.Chain()[1:] {
+        transfers, _ := ledger.DecodeTransfers(block.GetData())
+        for _, t := range transfers {
+            included[t.From] = wire.Timestamp(block.GetTimestamp()).Time().Sub(sim.Epoch)
+        }
+    }
+
+    fmt.Printf("%d miners, a block of up to %d transfers every 2s, mempools of %d, fees from 1 to %d\n\n", miners, perBlock, capacity, maxFee)
+    tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintln(tw, "minute\tsent/s\tsent\tturned away\tincluded\tlowest fee included\tmedian wait\t")
+    for minute := range time.Duration(len(demand)) {
+        from, to := minute*time.Minute, (minute+1)*time.Minute
+        var n, rejected, in int
+        var waits []time.Duration
+        for _, tx := range txs {
+            if tx.at >= from && tx.at < to {
+                n++
+                if tx.rejected {
+                    rejected++
+                }
+                if at, ok := included[tx.transfer.From]; ok {
+                    in++
+                    waits = append(waits, at-tx.at)
+                }
+            }
+        }
+        lowest := uint64(0)
+        for _, tx := range txs {
+            if at, ok := included[tx.transfer.From]; ok && at >= from && at < to && (lowest == 0 || tx.transfer.Fee < lowest) {
+                lowest = tx.transfer.Fee
+            }
+        }
+        fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\t%s\t%s\t\n", minute+1, demand[minute], n, rejected, in, orDash(lowest), median(waits))
+    }
+    tw.Flush()
+
+    fmt.Println()
+    tw = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintln(tw, "fee\tsent\tincluded\tmedian wait\tp90 wait\t")
+    for low := uint64(1); low <= maxFee; low += maxFee / 4 {
+        high := low + maxFee/4 - 1
+        var n int
+        var waits []time.Duration
+        for _, tx := range txs {
+            if tx.transfer.Fee >= low && tx.transfer.Fee <= high {
+                n++
+                if at, ok := included[tx.transfer.From]; ok {
+                    waits = append(waits, at-tx.at)
+                }
+            }
+        }
+        latency := stats.Summarize(waits)
+        fmt.Fprintf(tw, "%d-%d\t%d\t%d\t%s\t%v\t\n", low, high, n, len(waits), median(waits), latency.Percentile(90).Round(time.Second))
+    }
+    tw.Flush()
+}
+
+// median returns the median of waits, rounded to the second, or "-" if there are none.
+func median(waits []time.Duration) string {
+    if len(waits) == 0 {
+        return "-"
+    }
+    return stats.Summarize(waits).Percentile(50).Round(time.Second).String()
+}
+
+// orDash writes a fee, or "-" for none.
+func orDash(fee uint64) string {
+    if fee == 0 {
+        return "-"
+    }
+    return fmt.Sprint(fee)
+}
+
+// Footer: Overview and Execution Flow
+//
+// 1. **One Transfer per User**: Every transfer comes from a user of its own, so no transfer waits behind an
+//    earlier one of its sender and the fee alone decides its place. Users send to every miner, as a wallet
+//    broadcasting to the network would; a transfer counts as turned away if the first miner's mempool was full
+//    and paid more for everything it held.
+//
+// 2. **Blocks Choose**: Each miner's blocktree.Replica hands proposed transfers to its mempool.Pool, and every
+//    block it mines carries what mempool.Pool.Select picks: the ten highest fees. Transfers leave every pool once
+//    a block carrying them is adopted. No ledger.Accounts is attached: every user can afford its transfer, and
+//    computing the state root of thousands of accounts for every block would only slow the example down.
+//
+// 3. **Waits from the Chain**: A transfer's wait runs from its sending to the timestamp of the block of the first
+//    miner's chain that carries it, once the run ends, so transfers of blocks that lost a fork count only when
+//    a later block carried them again.
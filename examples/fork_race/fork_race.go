@@ -0,0 +1,144 @@
+// Package main races two Proof of Work miners against each other on a split network. While the partition lasts,
+// neither hears of the other's blocks, so each extends its own branch from the last block they shared and the
+// chain forks. Once the partition heals, the miners exchange their branches, the longest chain rule picks the
+// longer one, and the miner on the shorter branch reorganizes: it abandons its own blocks, puts their data back
+// in its queue, and mines on top of the winner. The example prints every block as it is mined, the block tree
+// each miner knows before and after the partition heals, and the reorganization.
+package main
+
+import (
+    "fmt"
+    "os"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/viz"
+    "consensus-algorithms-edu/wire"
+)
+
+const (
+    together = 1 * time.Second // Mining on one network before the split.
+    split    = 3 * time.Second // Mining on both sides of the partition.
+    after    = 3 * time.Second // Mining once the partition healed.
+    payment  = "alice pays bob 5"
+)
+
+// power is each miner's chance of finding a block on a tick of 10ms: node-0 has twice the hash power of node-1.
+var power = []float64{0.02, 0.01}
+
+func main() {
+    s := sim.New(sim.Config{Seed: 2, Network: sim.Link{Latency: sim.Constant(20 * time.Millisecond)}})
+    peers := []int32{0, 1}
+    miners := make([]*pow.Miner, len(peers))
+    for _, id := range peers {
+        miners[id] = pow.NewMiner(pow.MinerConfig{ID: id, Peers: peers, MineProbability: power[id], AntiEntropy: 20, Rand: s.NewRand(), Clock: s.Clock()})
+        s.Add(miners[id])
+    }
+
+    // Print every block as it is mined, and every reorganization as it happens with the blocks it abandoned.
+    chains := make([][]*wire.Block, len(miners))
+    s.OnTransition = func(t sim.Transition) {
+        m := miners[t.Node]
+        if t.Input == sim.TickInput {
+            for _, env := range t.Output {
+                if block := env.GetBlockProposal().GetBlock(); block != nil && block.GetProducer() == node.Name(t.Node) {
+                    fmt.Printf("%7v  %s mined #%d %.8s on top of %.8s%s\n", at(t.At), node.Name(t.Node), block.GetIndex(), block.GetHash(), block.GetPrevHash(), carrying(block))
+                    break
+                }
+            }
+        }
+        chain := m.Chain()
+        if d := wire.Diff(chains[t.Node], chain); d.Conflicting() {
+            abandoned := chains[t.Node][d.Height:]
+            fmt.Printf("%7v  %s reorganized onto #%d %.8s, abandoning #%d to #%d of its own branch\n", at(t.At), node.Name(t.Node),
+                m.Head().GetIndex(), m.Head().GetHash(), abandoned[0].GetIndex(), abandoned[len(abandoned)-1].GetIndex())
+        }
+        chains[t.Node] = chain
+    }
+
+    fmt.Printf("node-0 has %.0f%% and node-1 %.0f%% chance of a block every 10ms; 20ms between them\n", power[0]*100, power[1]*100)
+    section(fmt.Sprintf("mining together for %v", together))
+    s.RunFor(together)
+
+    section(fmt.Sprintf("partitioned for %v", split))
+    fork := miners[0].Head().GetIndex() // Last block both miners have.
+    s.Network.Partition([]int32{0}, []int32{1})
+    s.Propose(1, payment)
+    fmt.Printf("%7v  %q is submitted to node-1\n", at(s.Now()), payment)
+    s.RunFor(split)
+    for _, m := range miners {
+        fmt.Printf("%7v  %s follows #%d %.8s\n", at(s.Now()), node.Name(m.ID()), m.Head().GetIndex(), m.Head().GetHash())
+    }
+    tree(miners, fmt.Sprintf("block tree from #%d, node-0's chain first:", fork), fork)
+
+    section("healed")
+    s.Network.Heal()
+    s.RunFor(after)
+    tree(miners, fmt.Sprintf("block tree from #%d after healing:", fork), fork)
+
+    chain := miners[0].Chain()
+    for _, m := range miners[1:] {
+        if d := wire.Diff(chain, m.Chain()); !d.Same() && d.Height < len(chain)-1 {
+            fmt.Printf("\n%s and %s still disagree below their heads: %v\n", node.Name(miners[0].ID()), node.Name(m.ID()), d)
+            os.Exit(1)
+        }
+    }
+    fmt.Printf("\nboth miners follow one chain of %d blocks; %d blocks were orphaned\n", len(chain)-1, len(viz.Merge(miners[0].Blocks(), miners[1].Blocks()))-len(chain))
+    for _, block := range chain {
+        if block.GetData() == payment {
+            fmt.Printf("%q, abandoned with its block, is in the chain again at #%d\n", payment, block.GetIndex())
+        }
+    }
+}
+
+// carrying describes the data a block carries, if any.
+func carrying(block *wire.Block) string {
+    if block.GetData() == "" {
+        return ""
+    }
+    return fmt.Sprintf(" with %q", block.GetData())
+}
+
+// at formats a virtual time to the millisecond.
+func at(t time.Duration) time.Duration {
+    return t.Round(time.Millisecond)
+}
+
+// tree prints the block tree that all the miners know together from the block before the fork on, with node-0's
+// chain as the canonical one.
+func tree(miners []*pow.Miner, title string, from int64) {
+    var blocks []*wire.Block
+    for _, m := range miners {
+        for _, block := range m.Blocks() {
+            if block.GetIndex() >= from {
+                blocks = viz.Merge(blocks, []*wire.Block{block})
+            }
+        }
+    }
+    fmt.Println()
+    if err := viz.Text(os.Stdout, blocks, viz.Options{Title: title, Canonical: miners[0].Chain(), Data: true}); err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+    }
+}
+
+// section prints the heading of a phase of the race.
+func section(title string) {
+    fmt.Printf("\n== %s\n", title)
+}
+
+// Footer: Overview and Execution Flow
+//
+// 1. **The Partition**: sim.Network.Partition cuts the two miners off from each other one second in, once they
+//    share a few blocks. Each keeps mining on its own head, so both extend the last block they shared and the
+//    chain forks there; the payment submitted to node-1 lands in node-1's branch only.
+// 2. **Watching Reorganizations**: After every transition the example compares a miner's chain with the one it
+//    had before using wire.Diff. A conflicting divergence means the miner switched branches; the blocks of its
+//    old chain above the divergence are the ones it abandoned.
+// 3. **Healing**: After sim.Network.Heal the miners' anti-entropy exchange reveals the other branch. node-0's
+//    branch is longer, since it has twice the hash power, so node-1 reorganizes onto it and mines on top of it
+//    again, with the payment back in its queue.
+// 4. **The Block Tree**: viz.Text prints every block both miners know from the fork on, node-0's chain in the
+//    first column and the orphaned branch beside it, once before the partition heals and once after.
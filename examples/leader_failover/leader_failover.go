@@ -0,0 +1,220 @@
+// Package main walks through a Raft leader failover step by step. Five replicas elect a leader and commit a few
+// entries; then the leader crashes while it is replicating one more, with its AppendEntries already on the wire.
+// The example prints the election that follows — who times out, who votes for whom, who wins — and then checks
+// the one promise failover must keep: every entry committed before the crash is still committed, at the same
+// index, on every surviving replica, and the entry the old leader was replicating is either kept or forgotten
+// everywhere alike. Finally the old leader restarts, learns it was replaced and catches up.
+package main
+
+import (
+    "fmt"
+    "os"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+)
+
+const replicas = 5
+
+// cluster is the simulation and its replicas, with what the walkthrough prints as it goes.
+type cluster struct {
+    *sim.Simulator
+    replicas []*raft.Replica
+    watching bool           // Print campaigns, votes and new leaders as they happen.
+    leading  map[int32]bool // Replicas that lead, as of their last transition.
+}
+
+func main() {
+    c := newCluster()
+    fmt.Printf("%d Raft replicas, 5 to 15ms apart, a tick every 10ms\n", replicas)
+
+    section("electing the first leader")
+    old := c.elect()
+
+    section(fmt.Sprintf("committing entries under %s", node.Name(old.ID())))
+    for i := 1; i <= 4; i++ {
+        c.commit(old, fmt.Sprintf("set x = %d", i))
+    }
+    before := old.Committed() // What the cluster has promised to keep.
+
+    section(fmt.Sprintf("crashing %s mid-replication", node.Name(old.ID())))
+    inFlight := "set x = 5"
+    c.Propose(old.ID(), inFlight)
+    c.Crash(old.ID())
+    fmt.Printf("%9v  %s proposed %q, sent its AppendEntries and crashed\n", at(c.Now()), node.Name(old.ID()), inFlight)
+    if err := c.Propose(old.ID(), "set x = 6"); err != nil {
+        fmt.Printf("%9v  a client's next command to %s fails: %v\n", at(c.Now()), node.Name(old.ID()), err)
+    }
+
+    section("electing a new leader")
+    leader := c.elect()
+
+    section(fmt.Sprintf("committing entries under %s", node.Name(leader.ID())))
+    c.commit(leader, "set y = 1")
+
+    section("checking that nothing committed was lost")
+    ok := c.verify(old, before, inFlight)
+
+    section(fmt.Sprintf("restarting %s", node.Name(old.ID())))
+    c.Recover(old.ID())
+    c.RunUntil(func() bool { return len(old.Committed()) == len(leader.Committed()) }, c.Now()+5*time.Second)
+    fmt.Printf("%9v  %s is a %s in term %d following %s, with %d committed entries like everyone else\n",
+        at(c.Now()), node.Name(old.ID()), old.Role(), old.Term(), node.Name(old.Leader()), len(old.Committed())-1)
+    if d := wire.Diff(old.Committed(), leader.Committed()); !d.Same() {
+        fmt.Printf("%s and %s: %v\n", node.Name(old.ID()), node.Name(leader.ID()), d)
+        ok = false
+    }
+    if !ok {
+        os.Exit(1)
+    }
+}
+
+// newCluster builds the replicas in a simulation and prints the election traffic while watching is set.
+func newCluster() *cluster {
+    s := sim.New(sim.Config{Seed: 4, Network: sim.Link{Latency: sim.Uniform(5*time.Millisecond, 15*time.Millisecond)}})
+    c := &cluster{Simulator: s, leading: make(map[int32]bool)}
+    peers := make([]int32, replicas)
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    for _, id := range peers {
+        r := raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: peers, Rand: s.NewRand(), Clock: s.Clock()})
+        c.replicas = append(c.replicas, r)
+        s.Add(r)
+    }
+    s.OnTransition = c.watch
+    return c
+}
+
+// watch prints the campaigns, granted votes and election wins in a transition.
+func (c *cluster) watch(t sim.Transition) {
+    r := c.replicas[t.Node]
+    if leads := r.Role() == raft.Leader; leads != c.leading[t.Node] {
+        c.leading[t.Node] = leads
+        if leads && c.watching {
+            fmt.Printf("%9v  %s wins term %d and leads\n", at(t.At), node.Name(t.Node), r.Term())
+        }
+    }
+    if !c.watching || len(t.Output) == 0 {
+        return
+    }
+    switch body := t.Output[0].GetBody().(type) {
+    case *wire.Envelope_RequestVote:
+        fmt.Printf("%9v  %s hears from no leader and campaigns for term %d\n", at(t.At), node.Name(t.Node), body.RequestVote.GetTerm())
+    case *wire.Envelope_RequestVoteResponse:
+        if body.RequestVoteResponse.GetVoteGranted() {
+            fmt.Printf("%9v  %s votes for %s in term %d\n", at(t.At), node.Name(t.Node), node.Name(t.Output[0].GetTo()), body.RequestVoteResponse.GetTerm())
+        }
+    }
+}
+
+// elect runs the simulation until a majority follows one leader, printing the election, and returns the leader.
+func (c *cluster) elect() *raft.Replica {
+    c.watching = true
+    defer func() { c.watching = false }()
+    var leader *raft.Replica
+    elected := func() bool {
+        followers := map[int32]int{}
+        for _, r := range c.replicas {
+            if !c.Crashed(r.ID()) && r.Leader() >= 0 {
+                followers[r.Leader()]++
+            }
+        }
+        for id, n := range followers {
+            if n > replicas/2 && !c.Crashed(id) && c.replicas[id].Role() == raft.Leader {
+                leader = c.replicas[id]
+                return true
+            }
+        }
+        return false
+    }
+    if !c.RunUntil(elected, c.Now()+10*time.Second) {
+        fmt.Println("no leader was elected")
+        os.Exit(1)
+    }
+    return leader
+}
+
+// commit proposes data to the leader and runs until every running replica has committed it.
+func (c *cluster) commit(leader *raft.Replica, data string) {
+    c.Propose(leader.ID(), data)
+    height := len(leader.Committed()) + 1 // Entries of earlier terms commit along with it.
+    committed := func() bool {
+        for _, r := range c.replicas {
+            if !c.Crashed(r.ID()) && len(r.Committed()) < height {
+                return false
+            }
+        }
+        return true
+    }
+    c.RunUntil(committed, c.Now()+5*time.Second)
+    block := leader.Committed()[len(leader.Committed())-1]
+    fmt.Printf("%9v  %q committed at index %d in term %d\n", at(c.Now()), block.GetData(), block.GetIndex(), leader.Term())
+}
+
+// verify checks that every surviving replica still holds the blocks committed before the crash, at the same
+// indexes, and that the survivors agree on what came after, including the fate of the entry in flight.
+func (c *cluster) verify(crashed *raft.Replica, before []*wire.Block, inFlight string) bool {
+    ok := true
+    var survivors []*raft.Replica
+    for _, r := range c.replicas {
+        if r == crashed {
+            continue
+        }
+        survivors = append(survivors, r)
+        committed := r.Committed()
+        if len(committed) < len(before) {
+            fmt.Printf("  %s has only %d of the %d entries committed before the crash\n", node.Name(r.ID()), len(committed)-1, len(before)-1)
+            ok = false
+            continue
+        }
+        if d := wire.Diff(before, committed[:len(before)]); !d.Same() {
+            fmt.Printf("  %s lost a committed entry: %v\n", node.Name(r.ID()), d)
+            ok = false
+        }
+    }
+    if ok {
+        fmt.Printf("  all %d entries committed before the crash are on every survivor, at the same indexes\n", len(before)-1)
+    }
+    for _, r := range survivors[1:] {
+        if d := wire.Diff(survivors[0].Committed(), r.Committed()); !d.Same() {
+            fmt.Printf("  %s and %s disagree: %v\n", node.Name(survivors[0].ID()), node.Name(r.ID()), d)
+            ok = false
+        }
+    }
+    for _, block := range survivors[0].Committed()[len(before):] {
+        if block.GetData() == inFlight {
+            fmt.Printf("  %q, still uncommitted when its leader crashed, was committed at index %d by the new leader\n", inFlight, block.GetIndex())
+            return ok
+        }
+    }
+    fmt.Printf("  %q, still uncommitted when its leader crashed, was forgotten by every survivor\n", inFlight)
+    return ok
+}
+
+// at formats a virtual time to the microsecond.
+func at(t time.Duration) time.Duration {
+    return t.Round(time.Microsecond)
+}
+
+// section prints the heading of a step of the walkthrough.
+func section(title string) {
+    fmt.Printf("\n== %s\n", title)
+}
+
+// Footer: Overview and Execution Flow
+//
+// 1. **A Real Crash**: sim.Simulator.Crash stops the leader outright: it takes no more ticks or messages and
+//    refuses proposals with node.ErrCrashed. A leader merely cut off from the network would keep running and
+//    keep accepting proposals it can never commit; a crashed one simply goes silent.
+// 2. **Crashing Mid-Replication**: The leader crashes right after proposing one more entry, once its
+//    AppendEntries for it are on their way. Followers that receive them store the entry without knowing whether
+//    it will ever commit, and the election restriction makes one of them the next leader if a majority has it.
+// 3. **Committing an Old Entry**: A Raft leader never counts replicas of an entry from an earlier term to
+//    commit it. The entry the old leader was replicating therefore commits only together with the first entry
+//    of the new leader's own term, which is why the new leader commits "set y = 1" before the check.
+// 4. **The Check**: wire.Diff compares the blocks committed before the crash with each survivor's chain, the
+//    survivors' chains with each other, and finally the restarted leader's chain with the new leader's.
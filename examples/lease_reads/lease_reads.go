@@ -0,0 +1,181 @@
+// Package main shows Raft lease reads, and how clocks that drift too far apart break them. A leader with a lease
+// answers reads from its own committed entries, without asking a quorum first: the followers that acknowledged
+// its messages have promised not to help elect anyone else for the lease's duration, measured on their clocks.
+// The example cuts the leader off from the rest of a three-replica cluster, and keeps reading from it while the
+// other two elect a new leader and write a new value. It runs twice. With clocks that drift within the 5% the
+// lease allows for, the old leader's lease runs out before the others can elect anyone, and every read it serves
+// is current. With the followers' clocks running twice as fast as they should, their promises end early, a new
+// leader commits x = 2, and the old leader, still trusting its lease, keeps answering x = 1: stale reads.
+package main
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+)
+
+const (
+    replicas = 3
+    lease    = 500 * time.Millisecond // Time a follower's promise lasts on its own clock.
+    maxDrift = 0.05                   // Drift of clock rates the lease allows for.
+    every    = 25 * time.Millisecond  // Time between two reads from the old leader.
+)
+
+// cluster is a simulation of three replicas, each on its own clock.
+type cluster struct {
+    *sim.Simulator
+    replicas []*raft.Replica
+}
+
+// newCluster builds the replicas, node-i's clock running rates[i] times as fast as the simulation's.
+func newCluster(rates []float64) *cluster {
+    s := sim.New(sim.Config{Seed: 8, Network: sim.Link{Latency: sim.Uniform(2*time.Millisecond, 8*time.Millisecond)}})
+    c := &cluster{Simulator: s}
+    peers := make([]int32, replicas)
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    for _, id := range peers {
+        r := raft.NewReplica(raft.ReplicaConfig{
+            ID: id, Peers: peers, LeaseDuration: lease, MaxDrift: maxDrift,
+            Rand: s.NewRand(), Clock: clock.Drift(s.Clock(), rates[id]),
+        })
+        c.replicas = append(c.replicas, r)
+        s.Add(r)
+    }
+    return c
+}
+
+// leader returns the replica, other than except, that leads and is followed by a majority, if any.
+func (c *cluster) leader(except int32) (*raft.Replica, bool) {
+    for _, r := range c.replicas {
+        if r.ID() == except || r.Role() != raft.Leader {
+            continue
+        }
+        followers := 0
+        for _, other := range c.replicas {
+            if other.Leader() == r.ID() {
+                followers++
+            }
+        }
+        if followers > replicas/2 {
+            return r, true
+        }
+    }
+    return nil, false
+}
+
+// latest returns the newest value committed by any replica.
+func (c *cluster) latest() string {
+    value, height := "", -1
+    for _, r := range c.replicas {
+        if committed := r.Committed(); len(committed) > height {
+            value, height = committed[len(committed)-1].GetData(), len(committed)
+        }
+    }
+    return value
+}
+
+// run plays the scenario on clocks running at the given rates and returns the number of stale reads served.
+func run(rates []float64) int {
+    c := newCluster(rates)
+    at := func(format string, args ...any) {
+        fmt.Printf("%8v  %s\n", c.Now().Round(time.Millisecond), fmt.Sprintf(format, args...))
+    }
+    var old *raft.Replica
+    elected := func() bool {
+        var ok bool
+        old, ok = c.leader(-1)
+        return ok
+    }
+    if !c.RunUntil(elected, 10*time.Second) {
+        at("no leader was elected")
+        os.Exit(1)
+    }
+    c.Propose(old.ID(), "x = 1")
+    c.RunFor(200 * time.Millisecond)
+    if blocks, err := old.LeaseRead(); err == nil {
+        at("%s leads and reads %s from its lease, without asking anyone", node.Name(old.ID()), blocks[len(blocks)-1].GetData())
+    }
+
+    others := make([]int32, 0, replicas-1)
+    for _, r := range c.replicas {
+        if r != old {
+            others = append(others, r.ID())
+        }
+    }
+    c.Network.Partition([]int32{old.ID()}, others)
+    at("%s is cut off from %s and %s; its lease runs until %v", node.Name(old.ID()), node.Name(others[0]), node.Name(others[1]),
+        old.Lease().Sub(sim.Epoch).Round(time.Millisecond))
+
+    stale, served := 0, 0
+    var fresh *raft.Replica
+    for deadline := c.Now() + 2*time.Second; c.Now() < deadline; {
+        c.RunFor(every)
+        if fresh == nil {
+            if r, ok := c.leader(old.ID()); ok {
+                fresh = r
+                c.Propose(fresh.ID(), "x = 2")
+                at("%s and %s elect %s, which writes x = 2", node.Name(others[0]), node.Name(others[1]), node.Name(fresh.ID()))
+            }
+        }
+        blocks, err := old.LeaseRead()
+        if errors.Is(err, raft.ErrNoLease) {
+            at("%s's lease has expired: it refuses to read, after serving %d reads, %d of them stale", node.Name(old.ID()), served, stale)
+            break
+        }
+        if err != nil {
+            continue
+        }
+        served++
+        if value := blocks[len(blocks)-1].GetData(); value != c.latest() {
+            if stale == 0 {
+                at("%s still trusts its lease and reads %s, but %s is committed: a stale read", node.Name(old.ID()), value, c.latest())
+            }
+            stale++
+        }
+    }
+    if fresh == nil {
+        c.RunFor(lease)
+        if r, ok := c.leader(old.ID()); ok {
+            at("only then can %s and %s elect %s", node.Name(others[0]), node.Name(others[1]), node.Name(r.ID()))
+        }
+    }
+    return stale
+}
+
+func main() {
+    fmt.Printf("3 Raft replicas with %v leases that allow for %.0f%% clock drift\n", lease, maxDrift*100)
+
+    fmt.Println("\n== clocks within the allowed drift: 1.00, 1.04 and 0.97 times real time")
+    if stale := run([]float64{1.00, 1.04, 0.97}); stale != 0 {
+        fmt.Printf("%d stale reads despite clocks within the allowed drift\n", stale)
+        os.Exit(1)
+    }
+
+    fmt.Println("\n== the followers' clocks run twice as fast: 1.00, 2.00 and 2.00 times real time")
+    if stale := run([]float64{1.00, 2.00, 2.00}); stale == 0 {
+        fmt.Println("no stale read was served; the drift did not break the lease this time")
+    } else {
+        fmt.Printf("\n%d stale reads: the lease assumed clocks drift at most %.0f%%, and they drifted 100%%\n", stale, maxDrift*100)
+    }
+}
+
+// Footer: Overview and Execution Flow
+//
+// 1. **The Promise**: raft.ReplicaConfig.LeaseDuration makes every replica that accepts AppendEntries refuse to
+//    campaign or vote for LeaseDuration on its own clock. The leader counts its lease from the newest message a
+//    majority has answered, shortened by MaxDrift at both ends, so it ends before any follower's promise.
+// 2. **Reading**: raft.Replica.LeaseRead answers from the leader's committed entries while the lease runs, with no
+//    message at all: a read through the log, as in examples/kvstore, costs a round trip to a majority.
+// 3. **Within the Drift**: Cut off, the old leader's lease ends about 450ms after the last answer it got; its
+//    followers' promises end about 500ms after it, so they only elect a new leader once reads are refused.
+// 4. **Beyond It**: Clocks are given by clock.Drift. Running twice as fast, the followers' promises end after
+//    250ms of real time, and they elect a new leader and commit x = 2 while the old leader still reads x = 1. The
+//    lease is only as safe as the bound on drift; an offset between clocks, by contrast, would not matter.
@@ -0,0 +1,105 @@
+// Package main runs Proof of Work miners of which half are archive nodes, keeping the data of every block, and
+// half are pruned nodes, keeping the data of only the last few blocks and the headers of the rest. It shows that
+// the pruned miners follow the same chain as the archive miners and store a fraction of the data, and that a
+// miner joining late still catches up, fetching the old blocks from the archive miners.
+package main
+
+import (
+    "fmt"
+    "strconv"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/sim"
+)
+
+const (
+    miners = 8
+    keep   = 6 // Blocks below the head whose data a pruned miner keeps.
+)
+
+// stored returns the number of bytes of block data a miner holds.
+func stored(m *pow.Miner) int {
+    n := 0
+    for _, block := range m.Blocks() {
+        n += len(block.GetData())
+    }
+    return n
+}
+
+// describe prints a miner's mode, head and the data it stores.
+func describe(m *pow.Miner) {
+    mode := "archive"
+    if !m.Archive() {
+        mode = "pruned"
+    }
+    fmt.Printf("  miner %d (%-7s): head %3d, %3d blocks pruned, %5d bytes of data\n", m.ID(), mode, m.Head().GetIndex(), m.Pruned(), stored(m))
+}
+
+func main() {
+    s := sim.New(sim.Config{Seed: 11, Network: sim.Link{Latency: sim.Uniform(5*time.Millisecond, 30*time.Millisecond)}})
+    peers := make([]int32, miners+1) // The last miner joins late.
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    var all []*pow.Miner
+    for _, id := range peers {
+        prune := 0
+        if id%2 == 1 || id == miners {
+            prune = keep
+        }
+        all = append(all, pow.NewMiner(pow.MinerConfig{ID: id, Peers: peers, MineProbability: 0.001, Confirmations: keep, Prune: prune, AntiEntropy: 20, Rand: s.NewRand(), Clock: s.Clock()}))
+    }
+    for _, m := range all[:miners] {
+        s.Add(m)
+    }
+    for i := range 60 {
+        s.At(time.Duration(i)*time.Second, func() {
+            s.Propose(int32(i%miners), "Payment "+strconv.Itoa(i)+": alice pays bob "+strconv.Itoa(i+1))
+        })
+    }
+    s.RunFor(60 * time.Second)
+
+    fmt.Println("After 60 seconds:")
+    for _, m := range all[:miners] {
+        describe(m)
+    }
+    fmt.Println("Every miner has committed the same chain:", agree(all[:miners]))
+
+    late := all[miners]
+    s.Add(late)
+    s.RunFor(20 * time.Second)
+    fmt.Println("After a pruned miner joins late and 20 more seconds:")
+    describe(late)
+    fmt.Println("Every miner has committed the same chain:", agree(all))
+}
+
+// agree reports whether the miners have committed the same blocks: whether, up to the shortest of their
+// committed chains, every miner's chain holds the same block. The last few blocks may still differ while
+// competing miners can replace them.
+func agree(all []*pow.Miner) bool {
+    depth := len(all[0].Committed())
+    for _, m := range all[1:] {
+        depth = min(depth, len(m.Committed()))
+    }
+    for _, m := range all[1:] {
+        if m.Chain()[depth-1].GetHash() != all[0].Chain()[depth-1].GetHash() {
+            return false
+        }
+    }
+    return true
+}
+
+// Footer: Overview and Execution Flow
+//
+// The example mines for 60 seconds of virtual time with eight miners, proposing a payment every second, then adds
+// a ninth.
+//
+// Key Steps:
+// 1. **Archive and Pruned Miners**: Even miners keep every block's data; odd miners discard the data of blocks
+//    more than six below their head, keeping their headers.
+// 2. **Consensus**: Every miner verifies the blocks it receives before pruning them, so the pruned miners follow
+//    the same longest chain as the archive miners, and commit the same blocks once six more follow them.
+// 3. **Storage**: The archive miners hold the data of every block, the pruned miners that of the last few.
+// 4. **Late Join**: The ninth miner, a pruned one, learns of the chain from new blocks and anti-entropy, and fetches the blocks it
+//    lacks from whichever peer still has their data: only the archive miners can serve the old ones.
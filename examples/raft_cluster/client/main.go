@@ -0,0 +1,227 @@
+// Package main is the client of the Raft cluster example. It knows the addresses of every node but not which
+// one leads: it asks any node, follows the node's pointer to the leader, and when the leader stops answering,
+// as when its process is killed, it tries the others until a new leader has been elected and accepts the
+// command. Commands are submitted one at a time, each waiting until the cluster has committed it.
+//
+//  client status               every node's role, term, leader and committed height
+//  client submit set x = 1     submit one command
+//  client load -count 50       submit numbered commands one after another, reporting each
+//  client log                  the committed log of the first node that answers
+package main
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "log"
+    "net/http"
+    "os"
+    "os/signal"
+    "strings"
+    "text/tabwriter"
+    "time"
+
+    raftcluster "consensus-algorithms-edu/examples/raft_cluster"
+)
+
+// retryPause is how long the client waits before trying again after no node accepted a command.
+const retryPause = 200 * time.Millisecond
+
+// client submits commands to a cluster laid out by layout.
+type client struct {
+    layout  raftcluster.Layout
+    http    *http.Client
+    leader  string // Address of the node that accepted the last command, tried first next time.
+    session string // Prefix of command IDs, unique per run of the client.
+    sent    int    // Commands submitted so far.
+}
+
+func main() {
+    nodes := flag.Int("nodes", 3, "number of nodes in the cluster")
+    host := flag.String("host", raftcluster.DefaultHost, "host every node runs on")
+    apiPort := flag.Int("api-port", raftcluster.DefaultAPIPort, "port node 0 serves clients on; node i uses this port + i")
+    timeout := flag.Duration("timeout", 20*time.Second, "how long to keep retrying a command before giving up")
+    flag.Usage = func() {
+        fmt.Fprintln(os.Stderr, "usage: client [flags] status | submit TEXT... | load [-count N] [-every DURATION] | log")
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+    if flag.NArg() == 0 {
+        flag.Usage()
+        os.Exit(2)
+    }
+
+    c := &client{
+        layout:  raftcluster.Layout{Host: *host, Nodes: *nodes, APIPort: *apiPort},
+        http:    &http.Client{Timeout: 5 * time.Second},
+        session: fmt.Sprintf("%d-%d", os.Getpid(), time.Now().Unix()),
+    }
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+    defer stop()
+    var err error
+    switch args := flag.Args(); args[0] {
+    case "status":
+        err = c.status()
+    case "submit":
+        if len(args) < 2 {
+            log.Fatal("usage: client submit TEXT...")
+        }
+        err = c.report(ctx, strings.Join(args[1:], " "), *timeout)
+    case "load":
+        load := flag.NewFlagSet("load", flag.ExitOnError)
+        count := load.Int("count", 20, "number of commands to submit")
+        every := load.Duration("every", 500*time.Millisecond, "pause between one command's commit and the next command")
+        load.Parse(args[1:])
+        for i := 1; i <= *count && ctx.Err() == nil; i++ {
+            if err = c.report(ctx, fmt.Sprintf("command %d", i), *timeout); err != nil {
+                break
+            }
+            time.Sleep(*every)
+        }
+    case "log":
+        err = c.log()
+    default:
+        flag.Usage()
+        os.Exit(2)
+    }
+    if err != nil {
+        log.Fatal(err)
+    }
+}
+
+// report submits a command and prints who committed it and how long it took.
+func (c *client) report(ctx context.Context, text string, timeout time.Duration) error {
+    start := time.Now()
+    submitted, tries, err := c.submit(ctx, text, timeout)
+    if err != nil {
+        return err
+    }
+    fmt.Printf("%-12s committed at index %-4d by node-%d in %v", text, submitted.Index, submitted.Node, time.Since(start).Round(time.Millisecond))
+    if tries > 1 {
+        fmt.Printf(" after %d tries", tries)
+    }
+    fmt.Println()
+    return nil
+}
+
+// submit sends a command to the leader until one commits it or timeout passes. It starts with the node that
+// accepted the last command, follows redirects to the leader, and moves on to the next node whenever one does
+// not answer or knows no leader. It returns the answer and the number of requests it took.
+func (c *client) submit(ctx context.Context, text string, timeout time.Duration) (raftcluster.Submitted, int, error) {
+    c.sent++
+    body, _ := json.Marshal(raftcluster.Command{ID: fmt.Sprintf("%s-%d", c.session, c.sent), Text: text})
+    deadline := time.Now().Add(timeout)
+    next, tries := 0, 0
+    address := c.leader
+    for time.Now().Before(deadline) && ctx.Err() == nil {
+        if address == "" {
+            address = c.layout.APIAddress(int32(next % c.layout.Nodes))
+            next++
+        }
+        tries++
+        resp, err := c.http.Post("http://"+address+"/commands", "application/json", bytes.NewReader(body))
+        if err == nil {
+            switch resp.StatusCode {
+            case http.StatusOK:
+                var submitted raftcluster.Submitted
+                err := json.NewDecoder(resp.Body).Decode(&submitted)
+                resp.Body.Close()
+                c.leader = address
+                return submitted, tries, err
+            case http.StatusMisdirectedRequest:
+                var redirect raftcluster.Redirect
+                json.NewDecoder(resp.Body).Decode(&redirect)
+                resp.Body.Close()
+                if redirect.Address != "" && redirect.Address != address {
+                    address = redirect.Address
+                    continue
+                }
+            default:
+                resp.Body.Close() // The leader timed out waiting for a majority; the command may still commit.
+            }
+        }
+        // The node is down, knows no leader or could not commit: try the next one.
+        address = ""
+        if next%c.layout.Nodes == 0 {
+            time.Sleep(retryPause) // Every node was tried: give the cluster time to elect a leader.
+        }
+    }
+    if err := ctx.Err(); err != nil {
+        return raftcluster.Submitted{}, tries, err
+    }
+    return raftcluster.Submitted{}, tries, fmt.Errorf("%q was not committed within %v; is a majority of the nodes running?", text, timeout)
+}
+
+// status prints the status of every node, or why it did not answer.
+func (c *client) status() error {
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(w, "node\taddress\trole\tterm\tleader\tlog\theight")
+    for id := range int32(c.layout.Nodes) {
+        var status raftcluster.Status
+        if err := c.get(c.layout.APIAddress(id), "/status", &status); err != nil {
+            fmt.Fprintf(w, "node-%d\t%s\tunreachable\t\t\t\t\n", id, c.layout.APIAddress(id))
+            continue
+        }
+        leader := "-"
+        if status.Leader >= 0 {
+            leader = fmt.Sprintf("node-%d", status.Leader)
+        }
+        fmt.Fprintf(w, "node-%d\t%s\t%s\t%d\t%s\t%d\t%d\n", id, c.layout.APIAddress(id), status.Role, status.Term, leader, status.Log, status.Height)
+    }
+    return w.Flush()
+}
+
+// log prints the committed log of the first node that answers. A command the client retried after its leader
+// failed may appear twice; the second copy is marked.
+func (c *client) log() error {
+    for id := range int32(c.layout.Nodes) {
+        var entries []raftcluster.Entry
+        if err := c.get(c.layout.APIAddress(id), "/log", &entries); err != nil {
+            continue
+        }
+        fmt.Printf("log of node-%d, %d entries\n", id, len(entries))
+        seen := make(map[string]int64)
+        for _, entry := range entries {
+            fmt.Printf("%5d  %s", entry.Index, entry.Command)
+            if first, ok := seen[entry.ID]; ok && entry.ID != "" {
+                fmt.Printf("  (retried; first committed at %d)", first)
+            } else {
+                seen[entry.ID] = entry.Index
+            }
+            fmt.Println()
+        }
+        return nil
+    }
+    return errors.New("no node answered")
+}
+
+// get fetches a path from a node and decodes its JSON answer into v.
+func (c *client) get(address, path string, v any) error {
+    resp, err := c.http.Get("http://" + address + path)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        return fmt.Errorf("%s%s: %s", address, path, resp.Status)
+    }
+    return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// Footer: Overview and Execution Flow
+//
+// 1. **Finding the Leader**: submit starts with the node that accepted the last command, or node 0. A follower
+//    answers with the leader's address, which the client tries next.
+//
+// 2. **Failover**: A node that does not answer, knows no leader or times out waiting for a majority is skipped.
+//    After a full round of the nodes the client pauses for retryPause, giving the cluster time to elect a new
+//    leader, and keeps going until -timeout passes.
+//
+// 3. **One Command at a Time**: report waits for each command to be committed before the next is sent, so the
+//    time it prints is the full latency a client sees, failovers included.
+//
+// 4. **Reading the Cluster**: status and log ask the nodes' HTTP APIs directly, and log marks any command that
+//    was committed twice because the client retried it after its leader failed.
@@ -0,0 +1,93 @@
+// Package raftcluster holds what the two programs of the Raft cluster example share: the addresses of the nodes
+// and the JSON a client and a node exchange. The node program in node/ runs one Raft replica per process, talking
+// to the others over gRPC and serving clients over HTTP; the client program in client/ finds the leader, submits
+// commands to it, and keeps submitting while leaders are killed and replaced.
+package raftcluster
+
+import (
+    "net"
+    "strconv"
+)
+
+// Addresses of a local cluster: node i listens for its peers on RaftPort+i and for clients on APIPort+i.
+const (
+    DefaultHost     = "127.0.0.1"
+    DefaultRaftPort = 7000
+    DefaultAPIPort  = 8000
+)
+
+// Layout places the nodes of a cluster on one host, each on its own pair of ports.
+type Layout struct {
+    Host     string // Host every node runs on.
+    Nodes    int    // Nodes in the cluster, with identifiers 0 to Nodes-1.
+    RaftPort int    // Port node 0 listens on for its peers; node i uses RaftPort+i.
+    APIPort  int    // Port node 0 serves clients on; node i uses APIPort+i.
+}
+
+// RaftAddress returns the address node id listens on for its peers.
+func (l Layout) RaftAddress(id int32) string {
+    return net.JoinHostPort(l.Host, strconv.Itoa(l.RaftPort+int(id)))
+}
+
+// APIAddress returns the address node id serves clients on.
+func (l Layout) APIAddress(id int32) string {
+    return net.JoinHostPort(l.Host, strconv.Itoa(l.APIPort+int(id)))
+}
+
+// Peers returns the gRPC address of every node, as transport.GRPCConfig takes them.
+func (l Layout) Peers() map[int32]string {
+    peers := make(map[int32]string, l.Nodes)
+    for id := range int32(l.Nodes) {
+        peers[id] = l.RaftAddress(id)
+    }
+    return peers
+}
+
+// Command is what a client submits, and what a committed block holds.
+type Command struct {
+    ID   string `json:"id"`   // Unique per command, so a command that was retried and committed twice can be told apart.
+    Text string `json:"text"` // The command itself; the example gives it no meaning.
+}
+
+// Submitted answers a command the leader committed.
+type Submitted struct {
+    Node  int32 `json:"node"`  // Leader that committed the command.
+    Index int64 `json:"index"` // Index of the block holding it.
+}
+
+// Redirect answers a command sent to a node that is not the leader.
+type Redirect struct {
+    Error   string `json:"error"`
+    Leader  int32  `json:"leader"`            // Leader the node follows, or -1 if it knows none, e.g. during an election.
+    Address string `json:"address,omitempty"` // Client address of that leader.
+}
+
+// Status describes one node.
+type Status struct {
+    Node   int32  `json:"node"`
+    Role   string `json:"role"`   // "leader", "candidate" or "follower".
+    Term   uint64 `json:"term"`
+    Leader int32  `json:"leader"` // Leader the node follows, or -1.
+    Log    int    `json:"log"`    // Entries in the node's log, committed or not.
+    Height int64  `json:"height"` // Index of the last committed block.
+}
+
+// Entry is one committed block of a node's log.
+type Entry struct {
+    Index   int64  `json:"index"`
+    Command string `json:"command"` // Text of the command, or the raw data of a block that holds none.
+    ID      string `json:"id,omitempty"`
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **One Package for Both Programs**: The node and the client are separate programs, built and started
+//    separately, but they must agree on where every node listens and on the JSON they exchange. Both import this
+//    package, so a change to either is made once.
+//
+// 2. **Addresses From Identifiers**: A node's ports are its identifier added to a base port, so every process
+//    derives the whole cluster from -nodes and the base ports alone, and no configuration file is needed.
+//
+// 3. **Commands Carry an ID**: A client that retries a command after its leader died cannot know whether the
+//    first attempt was committed. The ID lets the log show a command committed twice rather than hide it; the
+//    example leaves deduplication, which a real state machine would do on execution, out.
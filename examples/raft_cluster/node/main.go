@@ -0,0 +1,210 @@
+// Package main runs one node of the Raft cluster example as its own process. The node talks Raft to the other
+// nodes over gRPC and serves clients over HTTP: the leader proposes the commands clients send and answers once
+// they are committed, and a follower points the client to the leader. Start one process per node, kill any of
+// them, the leader included, and the others carry on.
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "log"
+    "net/http"
+    "os"
+    "os/signal"
+    "sync"
+    "syscall"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/raft"
+    raftcluster "consensus-algorithms-edu/examples/raft_cluster"
+    "consensus-algorithms-edu/logging"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/transport"
+    "consensus-algorithms-edu/wire"
+)
+
+// commitTimeout is how long the leader waits for a command to commit before it gives up and lets the client
+// retry, e.g. because it lost its majority or its leadership while waiting.
+const commitTimeout = 3 * time.Second
+
+// server answers clients on behalf of one node.
+type server struct {
+    id      int32
+    layout  raftcluster.Layout
+    runner  *node.Runner
+    mu      sync.Mutex
+    waiting map[string]chan int64 // Commands proposed here and not yet committed, by their data.
+}
+
+func main() {
+    id := flag.Int("id", 0, "identifier of this node, from 0 to -nodes - 1")
+    nodes := flag.Int("nodes", 3, "number of nodes in the cluster; every node must be started with the same number")
+    host := flag.String("host", raftcluster.DefaultHost, "host every node runs on")
+    raftPort := flag.Int("raft-port", raftcluster.DefaultRaftPort, "port node 0 listens on for its peers; node i uses this port + i")
+    apiPort := flag.Int("api-port", raftcluster.DefaultAPIPort, "port node 0 serves clients on; node i uses this port + i")
+    tick := flag.Duration("tick", 50*time.Millisecond, "duration of one logical tick; an election timeout is 10 to 20 ticks")
+    logLevel := flag.String("log", "off", "log elections, votes and commits to standard error at this level: debug, info, warn, error or off")
+    flag.Parse()
+
+    layout := raftcluster.Layout{Host: *host, Nodes: *nodes, RaftPort: *raftPort, APIPort: *apiPort}
+    self := int32(*id)
+    if self < 0 || int(self) >= layout.Nodes {
+        log.Fatalf("-id must be between 0 and %d", layout.Nodes-1)
+    }
+    logger, err := logging.New(os.Stderr, *logLevel)
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    peers := make([]int32, layout.Nodes)
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    s := &server{id: self, layout: layout, waiting: make(map[string]chan int64)}
+    s.runner = node.NewRunner(raft.NewReplica(raft.ReplicaConfig{ID: self, Peers: peers, Logger: logger}))
+    s.runner.Algorithm = "raft"
+    s.runner.OnCommit = s.committed
+
+    t, err := transport.NewGRPCTransport(transport.GRPCConfig{ID: self, Listen: layout.RaftAddress(self), Peers: layout.Peers()}, s.runner.Handle)
+    if err != nil {
+        log.Fatal(err)
+    }
+    defer t.Close()
+    s.runner.Attach(t)
+
+    mux := http.NewServeMux()
+    mux.HandleFunc("POST /commands", s.handleCommand)
+    mux.HandleFunc("GET /status", s.handleStatus)
+    mux.HandleFunc("GET /log", s.handleLog)
+    api := &http.Server{Addr: layout.APIAddress(self), Handler: mux}
+
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+    go s.runner.Run(ctx, *tick)
+    go func() {
+        if err := api.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+            log.Fatal(err)
+        }
+    }()
+    log.Printf("%s: raft on %s, clients on %s, %d nodes", node.Name(self), layout.RaftAddress(self), layout.APIAddress(self), layout.Nodes)
+    <-ctx.Done()
+    api.Close()
+}
+
+// committed prints a block the node committed and answers the client waiting for it, if any. It runs under the
+// runner's lock.
+func (s *server) committed(block *wire.Block) {
+    if block.GetIndex() == 0 {
+        return
+    }
+    log.Printf("%s: committed %d: %s", node.Name(s.id), block.GetIndex(), describe(block).Command)
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if done, ok := s.waiting[block.GetData()]; ok {
+        done <- block.GetIndex()
+        delete(s.waiting, block.GetData())
+    }
+}
+
+// handleCommand proposes a client's command if this node leads, and answers once it is committed. Any other node
+// answers 421 Misdirected Request with the leader it follows, for the client to try next.
+func (s *server) handleCommand(w http.ResponseWriter, r *http.Request) {
+    var cmd raftcluster.Command
+    if err := json.NewDecoder(r.Body).Decode(&cmd); err != nil || cmd.Text == "" {
+        writeJSON(w, http.StatusBadRequest, raftcluster.Redirect{Error: "want a JSON command with an id and a text", Leader: -1})
+        return
+    }
+    data, _ := json.Marshal(cmd)
+    done := make(chan int64, 1)
+    s.mu.Lock()
+    s.waiting[string(data)] = done
+    s.mu.Unlock()
+    defer func() {
+        s.mu.Lock()
+        delete(s.waiting, string(data))
+        s.mu.Unlock()
+    }()
+
+    if err := s.runner.Propose(string(data)); err != nil {
+        leader := s.leader()
+        redirect := raftcluster.Redirect{Error: err.Error(), Leader: leader}
+        if leader >= 0 {
+            redirect.Address = s.layout.APIAddress(leader)
+        }
+        writeJSON(w, http.StatusMisdirectedRequest, redirect)
+        return
+    }
+    select {
+    case index := <-done:
+        writeJSON(w, http.StatusOK, raftcluster.Submitted{Node: s.id, Index: index})
+    case <-time.After(commitTimeout):
+        writeJSON(w, http.StatusGatewayTimeout, raftcluster.Redirect{Error: "not committed in time; the leader may have lost its majority", Leader: s.leader()})
+    case <-r.Context().Done():
+    }
+}
+
+// handleStatus describes the node.
+func (s *server) handleStatus(w http.ResponseWriter, r *http.Request) {
+    var status raftcluster.Status
+    s.runner.Inspect(func(replica node.Replica) {
+        rr := replica.(*raft.Replica)
+        committed := rr.Committed()
+        status = raftcluster.Status{Node: s.id, Role: rr.Role().String(), Term: rr.Term(), Leader: rr.Leader(), Log: rr.LogLength(), Height: committed[len(committed)-1].GetIndex()}
+    })
+    writeJSON(w, http.StatusOK, status)
+}
+
+// handleLog lists the blocks the node committed, genesis excluded.
+func (s *server) handleLog(w http.ResponseWriter, r *http.Request) {
+    entries := []raftcluster.Entry{}
+    for _, block := range s.runner.Committed()[1:] {
+        entries = append(entries, describe(block))
+    }
+    writeJSON(w, http.StatusOK, entries)
+}
+
+// leader returns the leader the node follows, or -1.
+func (s *server) leader() int32 {
+    leader := int32(-1)
+    s.runner.Inspect(func(replica node.Replica) { leader = replica.(node.Leaderful).Leader() })
+    return leader
+}
+
+// describe returns the log entry of a committed block.
+func describe(block *wire.Block) raftcluster.Entry {
+    entry := raftcluster.Entry{Index: block.GetIndex(), Command: block.GetData()}
+    var cmd raftcluster.Command
+    if json.Unmarshal([]byte(block.GetData()), &cmd) == nil {
+        entry.Command, entry.ID = cmd.Text, cmd.ID
+    }
+    return entry
+}
+
+// writeJSON writes v as the JSON body of a response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    if err := json.NewEncoder(w).Encode(v); err != nil {
+        fmt.Fprintln(os.Stderr, err)
+    }
+}
+
+// Footer: Overview and Execution Flow
+//
+// 1. **Startup**: The node builds a raft.Replica, wraps it in a node.Runner and attaches a
+//    transport.GRPCTransport that listens on its Raft port and dials its peers. The runner ticks the replica every
+//    -tick and hands it every message from its peers, one at a time.
+//
+// 2. **Commands**: handleCommand proposes the command through the runner. A follower refuses the proposal, and the
+//    client is told the leader it follows; the leader registers the command in waiting and blocks until
+//    committed sees the block holding it, or until commitTimeout passes.
+//
+// 3. **Commits**: The runner calls committed for every block the replica commits, on the leader and the followers
+//    alike; each node logs it, and the leader answers the client waiting for it with the block's index.
+//
+// 4. **Shutdown**: An interrupt or SIGTERM cancels the runner's context and closes the HTTP server and the
+//    transport. Nothing is kept on disk, so a restarted node rejoins with an empty log and the leader sends it
+//    everything it missed.
@@ -0,0 +1,164 @@
+// Package main compares difficulty retargeting policies on the same workload. A Proof of Work network adjusts
+// its difficulty so that blocks keep coming at a target interval whatever its hash power, and how it adjusts is a
+// policy with three settings: the block interval it aims for, how many blocks it measures between adjustments, and
+// how far one adjustment may go. The example mines one workload — a network that loses three quarters of its hash
+// power, then gets it back — under five policies, and prints the average block time of every stretch of the run
+// for each, so the trade between reacting quickly and reacting to noise can be read off one table.
+package main
+
+import (
+    "fmt"
+    "math"
+    "os"
+    "text/tabwriter"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+)
+
+const (
+    miners      = 4
+    probability = 0.001 / miners   // Each miner's chance per 10ms tick at the base work: a block every 10s in all.
+    interval    = 10 * time.Second // Block interval every retargeting policy aims for.
+    leave       = 20 * time.Minute // Three of the four miners stop.
+    rejoin      = 60 * time.Minute // They mine again.
+    duration    = 90 * time.Minute
+    stretch     = 10 * time.Minute // Rows of the table.
+    difficulty  = 1                // Leading zeros of a block hash: probability decides when blocks are found, so more would only cost time.
+    antiEntropy = 1000             // Ticks between a miner's anti-entropy exchanges, which catch up the miners that rejoin.
+)
+
+// policies are the retargeting policies compared, all aiming for the same interval.
+var policies = []struct {
+    name     string
+    retarget pow.Retarget
+}{
+    {"fixed", pow.Retarget{}},
+    {"20 blocks, 4x", pow.Retarget{Interval: interval, Window: 20, MaxFactor: 4}},
+    {"5 blocks, 4x", pow.Retarget{Interval: interval, Window: 5, MaxFactor: 4}},
+    {"80 blocks, 4x", pow.Retarget{Interval: interval, Window: 80, MaxFactor: 4}},
+    {"20 blocks, 1.25x", pow.Retarget{Interval: interval, Window: 20, MaxFactor: 1.25}},
+}
+
+// run mines the workload with every miner retargeting by retarget, and returns the time each block of the first
+// miner's chain was mined at, from the start of the run.
+func run(retarget pow.Retarget) []time.Duration {
+    s := sim.New(sim.Config{Seed: 5, Network: sim.Link{Latency: sim.Uniform(10*time.Millisecond, 50*time.Millisecond)}})
+    peers := make([]int32, miners)
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    var observer *pow.Miner
+    for _, id := range peers {
+        m := pow.NewMiner(pow.MinerConfig{ID: id, Peers: peers, MineProbability: probability, Difficulty: difficulty, Retarget: retarget, AntiEntropy: antiEntropy, Rand: s.NewRand(), Clock: s.Clock()})
+        if observer == nil {
+            observer = m
+        }
+        s.Add(m)
+    }
+    for _, id := range peers[1:] {
+        s.At(leave, func() { s.Crash(id) })
+        s.At(rejoin, func() { s.Recover(id) })
+    }
+    s.RunFor(duration)
+
+    var mined []time.Duration
+    for _, block := range observer.Chain()[1:] { // The genesis block was not mined.
+        mined = append(mined, wire.Timestamp(block.GetTimestamp()).Time().Sub(sim.Epoch))
+    }
+    return mined
+}
+
+// average returns the average time between the blocks mined during [from, from+stretch), or "-" if there were
+// none.
+func average(mined []time.Duration, from time.Duration) string {
+    n := 0
+    for _, at := range mined {
+        if at >= from && at < from+stretch {
+            n++
+        }
+    }
+    if n == 0 {
+        return "-"
+    }
+    return (stretch / time.Duration(n)).Round(100 * time.Millisecond).String()
+}
+
+// deviation returns how far, on average, the blocks of each stretch came from the target interval: the root mean
+// square of the logarithm of their ratio, as a factor. 1 means every stretch was on target.
+func deviation(mined []time.Duration) float64 {
+    sum, stretches := 0.0, 0
+    for from := time.Duration(0); from < duration; from += stretch {
+        n := 0
+        for _, at := range mined {
+            if at >= from && at < from+stretch {
+                n++
+            }
+        }
+        ratio := float64(stretch) / float64(max(n, 1)) / float64(interval)
+        sum += math.Log(ratio) * math.Log(ratio)
+        stretches++
+    }
+    return math.Exp(math.Sqrt(sum / float64(stretches)))
+}
+
+func main() {
+    fmt.Printf("%d miners, a block every %v at first; 3 stop at %v and mine again at %v\n\n", miners, interval, leave, rejoin)
+    var runs [][]time.Duration
+    for _, p := range policies {
+        runs = append(runs, run(p.retarget))
+    }
+
+    tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprint(tw, "minutes\thash power\t")
+    for _, p := range policies {
+        fmt.Fprintf(tw, "%s\t", p.name)
+    }
+    fmt.Fprintln(tw)
+    for from := time.Duration(0); from < duration; from += stretch {
+        power := "100%"
+        if from >= leave && from < rejoin {
+            power = "25%"
+        }
+        fmt.Fprintf(tw, "%d-%d\t%s\t", int(from.Minutes()), int((from + stretch).Minutes()), power)
+        for _, mined := range runs {
+            fmt.Fprintf(tw, "%s\t", average(mined, from))
+        }
+        fmt.Fprintln(tw)
+    }
+    fmt.Fprint(tw, "blocks\t\t")
+    for _, mined := range runs {
+        fmt.Fprintf(tw, "%d\t", len(mined))
+    }
+    fmt.Fprint(tw, "\noff target\t\t")
+    for _, mined := range runs {
+        fmt.Fprintf(tw, "%.2fx\t", deviation(mined))
+    }
+    fmt.Fprintln(tw)
+    tw.Flush()
+    fmt.Printf("\n%q is the typical factor between a stretch's block time and %v; the target is %d blocks.\n",
+        "off target", interval, int(duration/interval))
+}
+
+// Footer: Overview and Execution Flow
+//
+// 1. **One Workload**: Every run mines with the same seed, miners and latencies, and crashes and recovers the same
+//    three miners at the same times; only pow.MinerConfig.Retarget differs.
+//
+// 2. **Fixed**: Without retargeting, blocks take four times as long while three quarters of the hash power is
+//    gone, which is what the other policies are measured against.
+//
+// 3. **Window**: A 5-block window follows the loss within a couple of minutes, but it measures only four
+//    intervals, and a short run of lucky blocks raises the work more than an unlucky one lowers it: it settles
+//    around a third slower than the target even while the hash power is steady. An 80-block window measures
+//    enough blocks to land on the target, but finishes the period under way at four times the interval, and
+//    after the miners return it is four times too fast until its next adjustment.
+//
+// 4. **Bound**: A bound of 1.25 keeps every adjustment small, so following a fourfold change takes six periods;
+//    the network is still slow when the hash power returns, and then too fast for a while.
+//
+// 5. **Block Times from the Chain**: The times come from the timestamps of the first miner's chain once the run
+//    ends, which are honest here, so blocks that lost a fork are not counted. Forks are settled by pow.ForkChoice,
+//    which weighs every block by the work the policy gave it, so each policy also decides which branch wins.
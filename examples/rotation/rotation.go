@@ -0,0 +1,188 @@
+// Package main compares leader rotation policies. Seven nodes run Raft, PBFT, Proof of Stake and Delegated Proof
+// of Stake in turn, under each policy of package rotation: round-robin, stake-weighted, VRF and sticky. After one
+// minute and again after two, the node most others follow as leader crashes for good, so every policy must hand
+// over to someone else, and the slot-based algorithms must keep scheduling the crashed node or stop. The example
+// prints, per algorithm and policy, the blocks committed, the rounds that failed, the longest wait for a block,
+// the share of blocks the busiest producer produced, and which nodes crashed.
+package main
+
+import (
+    "fmt"
+    "maps"
+    "os"
+    "slices"
+    "text/tabwriter"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/dpos"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/rotation"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+)
+
+const (
+    nodes    = 7
+    duration = 3 * time.Minute
+    requests = time.Second // Time between two client requests to Raft and PBFT.
+)
+
+// crashes are the times the node leading at the time crashes, for good: two crashes, as many as PBFT tolerates
+// with seven replicas.
+var crashes = []time.Duration{time.Minute, 2 * time.Minute}
+
+// stakes weigh the stake-weighted and VRF policies. Raft, PBFT and DPoS only use them for that; PoS also
+// weighs its fork choice by them.
+var stakes = map[int32]int{0: 7, 1: 6, 2: 5, 3: 4, 4: 3, 5: 2, 6: 1}
+
+// algorithm builds one node of a network running an algorithm under a rotation policy.
+type algorithm struct {
+    name   string
+    build  func(s *sim.Simulator, id int32, peers []int32, policy rotation.Builder) node.Replica
+    failed func(r node.Replica, blocks int) int // Rounds that failed, given the blocks committed.
+}
+
+var algorithms = []algorithm{
+    {"raft", func(s *sim.Simulator, id int32, peers []int32, policy rotation.Builder) node.Replica {
+        return raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: peers, Rotation: policy, Rand: s.NewRand(), Clock: s.Clock()})
+    }, func(r node.Replica, _ int) int { return int(r.(*raft.Replica).Term()) - 1 }}, // Every term after the first.
+    {"pbft", func(s *sim.Simulator, id int32, peers []int32, policy rotation.Builder) node.Replica {
+        return pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: peers, Rotation: policy, Clock: s.Clock()})
+    }, func(r node.Replica, _ int) int { return int(r.(*pbft.Replica).View()) }}, // Every view after the first.
+    {"pos", func(s *sim.Simulator, id int32, peers []int32, policy rotation.Builder) node.Replica {
+        return pos.NewValidator(pos.ValidatorConfig{ID: id, Peers: peers, Stakes: stakes, Rotation: policy, Clock: s.Clock()})
+    }, slotsMissed},
+    {"dpos", func(s *sim.Simulator, id int32, peers []int32, policy rotation.Builder) node.Replica {
+        return dpos.NewDelegate(dpos.DelegateConfig{ID: id, Peers: peers, Rotation: policy, Clock: s.Clock()})
+    }, slotsMissed},
+}
+
+// slotsMissed returns the slots of a PoS or DPoS run, 100ms each from the first, that left no block.
+func slotsMissed(_ node.Replica, blocks int) int {
+    return int(duration/(100*time.Millisecond)) - blocks
+}
+
+// outcome is what one run left behind.
+type outcome struct {
+    blocks  int           // Blocks committed, without the genesis block.
+    failed  int           // Rounds that failed.
+    longest time.Duration // Longest time between two committed blocks.
+    top     float64       // Share of the blocks produced by the node that produced the most; 0 if blocks do not record it.
+    crashed []int32       // Nodes that crashed, in order.
+}
+
+// run plays the crashes against a network of algo under policy and reports the chain of the node that
+// committed the most.
+func run(algo algorithm, policy rotation.Builder) outcome {
+    s := sim.New(sim.Config{Seed: 1, Network: sim.Link{Latency: sim.Uniform(5*time.Millisecond, 20*time.Millisecond)}})
+    peers := make([]int32, nodes)
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    var replicas []node.Replica
+    for _, id := range peers {
+        r := algo.build(s, id, peers, policy)
+        replicas = append(replicas, r)
+        s.Add(r)
+    }
+
+    var out outcome
+    for _, at := range crashes {
+        s.At(at, func() {
+            leader := leaderOf(replicas, out.crashed)
+            out.crashed = append(out.crashed, leader)
+            for _, other := range peers {
+                s.Network.Disconnect(leader, other) // Nothing it sends arrives, nor anything sent to it: it is down.
+            }
+        })
+    }
+    for at, i := requests, 0; at < duration; at, i = at+requests, i+1 {
+        s.At(at, func() {
+            for _, id := range peers {
+                s.Propose(id, fmt.Sprintf("request %d", i)) // Only the leader accepts it in Raft; PBFT forwards it.
+            }
+        })
+    }
+    s.RunFor(duration)
+
+    var best node.Replica
+    for _, r := range replicas {
+        if !slices.Contains(out.crashed, r.ID()) && (best == nil || len(r.Committed()) > len(best.Committed())) {
+            best = r
+        }
+    }
+    chain := best.Committed()
+    out.blocks = len(chain) - 1
+    out.failed = algo.failed(best, out.blocks)
+    produced := make(map[string]int)
+    last := time.Duration(0)
+    for _, block := range chain[1:] {
+        at := wire.Timestamp(block.GetTimestamp()).Time().Sub(sim.Epoch)
+        out.longest = max(out.longest, at-last)
+        last = at
+        if block.GetProducer() != "" {
+            produced[block.GetProducer()]++
+        }
+    }
+    out.longest = max(out.longest, duration-last)
+    if len(produced) > 0 {
+        out.top = float64(slices.Max(slices.Collect(maps.Values(produced)))) / float64(out.blocks)
+    }
+    return out
+}
+
+// leaderOf returns the leader most replicas that have not crashed follow, or the first of them if none follows
+// one.
+func leaderOf(replicas []node.Replica, crashed []int32) int32 {
+    votes := make(map[int32]int)
+    for _, r := range replicas {
+        if l, ok := r.(node.Leaderful); ok && !slices.Contains(crashed, r.ID()) && l.Leader() >= 0 && !slices.Contains(crashed, l.Leader()) {
+            votes[l.Leader()]++
+        }
+    }
+    leader, most := int32(-1), 0
+    for _, r := range replicas {
+        if leader < 0 && !slices.Contains(crashed, r.ID()) {
+            leader = r.ID()
+        }
+    }
+    for id, n := range votes {
+        if n > most || n == most && id < leader {
+            leader, most = id, n
+        }
+    }
+    return leader
+}
+
+func main() {
+    fmt.Printf("%d nodes with stakes %v, the leader crashing at %v, for %v\n\n", nodes, stakes, crashes, duration)
+    tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintln(tw, "algorithm\tpolicy\tblocks\tfailed rounds\tlongest wait\ttop producer\tcrashed\t")
+    for _, algo := range algorithms {
+        for _, name := range rotation.Names() {
+            o := run(algo, rotation.Named(name, stakes))
+            top := "-"
+            if o.top > 0 {
+                top = fmt.Sprintf("%.0f%%", 100*o.top)
+            }
+            fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%v\t%s\t%v\t\n", algo.name, name, o.blocks, o.failed, o.longest.Round(100*time.Millisecond), top, o.crashed)
+        }
+    }
+    tw.Flush()
+}
+
+// Footer: Overview and Execution Flow
+//
+// 1. **Crashing the Leader**: Each crash disconnects, for good, the leader most surviving replicas name through
+//    node.Leaderful: the Raft leader, the PBFT primary, the proposer or producer of the current slot. Two crashes
+//    out of seven is as many as PBFT survives, and leaves Raft and the slot-based algorithms a majority.
+//
+// 2. **Failed Rounds**: A round is a Raft term, a PBFT view or a 100ms slot. Raft and PBFT start a new one only
+//    when the leader of the last one failed, so every term or view after the first counts; PoS and DPoS start one
+//    every slot, so every slot that left no block on the chain counts.
+//
+// 3. **One Chain per Run**: The figures come from the surviving replica that committed the most, and every run
+//    uses the same seed, so differences between the rows of one algorithm are the policy's alone.
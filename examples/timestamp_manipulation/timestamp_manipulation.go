@@ -0,0 +1,151 @@
+// Package main shows why Proof of Work checks block timestamps. Difficulty retargeting measures how long the last
+// period of blocks took from the timestamps of its first and last block, and those are whatever the miners of the
+// two blocks wrote: a miner that dates the first block of a period early and the last one late makes the period
+// look slow, and the next one easier. The example mines the same network three times, with a miner holding 40% of
+// the hash power that tells the truth, then lies with nothing to stop it, then lies under the median-time-past and
+// future-limit rules, and prints how fast blocks came and how much work they took, period by period.
+package main
+
+import (
+    "fmt"
+    "os"
+    "text/tabwriter"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/sim"
+)
+
+const (
+    honest      = 3
+    share       = 0.4              // The liar's share of the hash power.
+    probability = 0.001            // Chance per 10ms tick that someone finds a block: one every 10s.
+    interval    = 10 * time.Second // Block interval the difficulty is adjusted toward.
+    periods     = 10               // Retarget periods each run mines.
+    height      = periods * pow.RetargetWindow
+    limit       = 2 * time.Hour
+)
+
+// rules are the timestamp rules of the third run. Bitcoin checks against the median of 11 blocks and allows two
+// hours into the future, next to a two-week period; a period here lasts about 200 seconds, so the future limit is
+// scaled down with it.
+var rules = pow.TimestampRules{MedianSpan: 11, MaxFuture: 2 * time.Second}
+
+// lie dates the first block of a period an hour early and the last one an hour late, the blocks Retarget measures
+// a period between. A miner keeps a lie within its own timestamp rules, so under rules it goes as far as they let
+// it and no further.
+func lie(index int, now time.Time) time.Time {
+    switch index % pow.RetargetWindow {
+    case 1:
+        return now.Add(-time.Hour)
+    case 0:
+        return now.Add(time.Hour)
+    }
+    return now
+}
+
+var scenarios = []struct {
+    name  string
+    stamp pow.Stamp
+    rules pow.TimestampRules
+}{
+    {"truthful", nil, pow.TimestampRules{}},
+    {"lying, unchecked", lie, pow.TimestampRules{}},
+    {"lying, checked", lie, rules},
+}
+
+// period is a retarget period of a chain as mined.
+type period struct {
+    took time.Duration // Simulated time from the end of the previous period to the end of this one.
+    work float64       // Work each of its blocks took, relative to the first period.
+}
+
+// run mines until the first honest miner's chain is height blocks long, or for limit, and returns its periods,
+// timed by when the chain first grew to their last height: the timestamps may lie, but the simulation does not.
+func run(stamp pow.Stamp, timestamps pow.TimestampRules) []period {
+    s := sim.New(sim.Config{Seed: 3, Network: sim.Link{Latency: sim.Uniform(10*time.Millisecond, 50*time.Millisecond)}})
+    peers := make([]int32, honest+1)
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    var observer *pow.Miner
+    for _, id := range peers {
+        cfg := pow.MinerConfig{ID: id, Peers: peers, MineProbability: probability * (1 - share) / honest, Retarget: pow.Retarget{Interval: interval}, Timestamps: timestamps, Rand: s.NewRand(), Clock: s.Clock()}
+        if int(id) == honest {
+            cfg.MineProbability, cfg.Stamp = probability*share, stamp
+        }
+        m := pow.NewMiner(cfg)
+        if observer == nil {
+            observer = m
+        }
+        s.Add(m)
+    }
+    reached := make(map[int64]time.Duration) // When the chain first grew to each height, in simulated time.
+    s.RunUntil(func() bool {
+        h := observer.Head().GetIndex()
+        if _, ok := reached[h]; !ok {
+            reached[h] = s.Now()
+        }
+        return h >= height
+    }, limit)
+
+    chain := observer.Chain()
+    retarget := pow.Retarget{Interval: interval}
+    var periods []period
+    start := time.Duration(0)
+    for end := pow.RetargetWindow; end < len(chain); end += pow.RetargetWindow {
+        at := reached[int64(end)]
+        periods = append(periods, period{took: at - start, work: retarget.Work(chain[:end-pow.RetargetWindow+1])})
+        start = at
+    }
+    return periods
+}
+
+func main() {
+    fmt.Printf("%d honest miners and one with %.0f%% of the hash power, a block every %v, retargeting every %d blocks\n\n",
+        honest, share*100, interval, pow.RetargetWindow)
+    var runs [][]period
+    for _, sc := range scenarios {
+        runs = append(runs, run(sc.stamp, sc.rules))
+    }
+
+    tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprint(tw, "\t")
+    for _, sc := range scenarios {
+        fmt.Fprintf(tw, "%s\t\t", sc.name)
+    }
+    fmt.Fprint(tw, "\nperiod\t")
+    for range scenarios {
+        fmt.Fprint(tw, "block time\twork\t")
+    }
+    fmt.Fprintln(tw)
+    for i := range periods {
+        fmt.Fprintf(tw, "%d\t", i+1)
+        for _, r := range runs {
+            if i >= len(r) {
+                fmt.Fprint(tw, "-\t-\t")
+                continue
+            }
+            fmt.Fprintf(tw, "%v\t%.3g\t", (r[i].took / pow.RetargetWindow).Round(100*time.Millisecond), r[i].work)
+        }
+        fmt.Fprintln(tw)
+    }
+    tw.Flush()
+    fmt.Printf("\nThe checked liar can date a block no earlier than just after the median of the last %d, and no later than %v ahead.\n",
+        rules.MedianSpan, rules.MaxFuture)
+}
+
+// Footer: Overview and Execution Flow
+//
+// 1. **The Measurement**: pow.Retarget.Work multiplies the work of every block of a period by how much faster than
+//    Interval the previous period came, judged by the timestamps of its first and last block, at most 4 times
+//    either way. The run prints the work of each period and the time it really took, which the simulation knows
+//    even where the timestamps lie.
+// 2. **Truthful**: The work wanders around 1 with the luck of each period, and blocks come about every 10 seconds.
+// 3. **Unchecked**: Whenever the liar mines the first or the last block of a period, the period looks hours long,
+//    so the next one takes a quarter of the work. Honest periods at the faster rate bring the work back up, but
+//    every period the liar reaches lowers it again, and blocks come far faster than the network intended.
+// 4. **Checked**: pow.MinerConfig.Timestamps makes every miner refuse blocks dated before the median time past or
+//    more than MaxFuture ahead of its clock, and the liar clamps its dates so its blocks are still followed. Its
+//    first blocks can only be dated back a few blocks, and its last ones a moment ahead, so it gains a fraction of
+//    a period rather than a factor of 4.
@@ -0,0 +1,195 @@
+// Package main follows one Proof of Stake validator through its whole life. node-4 joins the network without
+// stake and only follows the chain; it bonds stake and starts proposing. Then node-2's operator makes the classic
+// mistake of running a second signer with the same key, and node-2 proposes two different blocks for one slot.
+// The other validators catch the double-sign (package evidence), slash part of node-2's stake and jail it: it is
+// left out of the schedule and its blocks are refused. Asking to be released too early fails; once its jail
+// time has run out, node-2 unjails and proposes again with what is left of its stake.
+package main
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "sort"
+    "strings"
+    "time"
+
+    "consensus-algorithms-edu/adversary"
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/evidence"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+)
+
+const (
+    newcomer     = 4  // Joins without stake and bonds later.
+    offender     = 2  // Double-signs, is slashed and jailed, and unjails.
+    slashPercent = 10 // Share of its stake a double-signer loses.
+    jailSlots    = 20 // Slots a slashed validator stays jailed.
+)
+
+// network is the simulation, its validators, and whether the offender is running a second signer.
+type network struct {
+    *sim.Simulator
+    validators []*pos.Validator
+    careless   bool // node-2's second signer is running, and every block it proposes is signed twice.
+}
+
+func main() {
+    n := newNetwork()
+    fmt.Printf("5 Proof of Stake validators, a slot every 100ms; a double-signer loses %d%% of its stake and is jailed for %d slots\n", slashPercent, jailSlots)
+    n.stakes()
+
+    section("node-4 registers without stake")
+    n.phase(time.Second)
+
+    section("node-4 bonds 40")
+    for _, v := range n.validators {
+        v.Bond(newcomer, 40) // A bonding transaction, which every validator applies.
+    }
+    n.stakes()
+    n.phase(time.Second)
+
+    section("node-2 starts a second signer with the same key")
+    n.careless = true
+    jailed := func() bool {
+        for _, v := range n.validators {
+            if !v.Jailed(offender) {
+                return false
+            }
+        }
+        return true
+    }
+    if !n.RunUntil(jailed, n.Now()+5*time.Second) {
+        fmt.Println("node-2 was not jailed by every validator")
+        os.Exit(1)
+    }
+    fmt.Printf("%8v  every validator has slashed and jailed node-2\n", at(n.Now()))
+    n.stakes()
+
+    section("node-2 asks to be released at once")
+    if err := n.validators[0].Unjail(offender); errors.Is(err, pos.ErrJailed) {
+        fmt.Printf("%8v  refused: %v\n", at(n.Now()), err)
+    }
+    n.phase(3 * time.Second)
+
+    section("node-2 unjails")
+    for _, v := range n.validators {
+        if err := v.Unjail(offender); err != nil { // An unjail transaction, which every validator applies.
+            fmt.Printf("%s refused: %v\n", node.Name(v.ID()), err)
+            os.Exit(1)
+        }
+    }
+    fmt.Printf("%8v  node-2 is back in the schedule\n", at(n.Now()))
+    n.stakes()
+    n.phase(2 * time.Second)
+
+    section("checking the chains")
+    n.RunFor(time.Second) // Let the last blocks spread.
+    ok := true
+    for _, v := range n.validators[1:] {
+        if d := wire.Diff(n.validators[0].Chain(), v.Chain()); !d.Same() {
+            fmt.Printf("  node-0 and %s: %v\n", node.Name(v.ID()), d)
+            ok = false
+        }
+    }
+    if !ok {
+        os.Exit(1)
+    }
+    fmt.Printf("  every validator follows the same %d blocks\n", len(n.validators[0].Chain())-1)
+}
+
+// newNetwork builds five validators, each behind an evidence watcher that relays what it hears, with node-2
+// equivocating towards node-3 and node-4 while careless is set. The first conviction stands for the operator
+// noticing and shutting the second signer down.
+func newNetwork() *network {
+    s := sim.New(sim.Config{Seed: 3, Network: sim.Link{Latency: sim.Uniform(2*time.Millisecond, 10*time.Millisecond)}})
+    n := &network{Simulator: s}
+    peers := []int32{0, 1, 2, 3, 4}
+    stakes := map[int32]int{0: 30, 1: 30, 2: 30, 3: 30} // node-4 is registered, but has bonded nothing.
+    private, keys := evidence.GenerateKeys(1, peers)
+    lie := adversary.Equivocate(adversary.NewForger(adversary.Hashes["pos"]), 3, 4)
+    secondSigner := adversary.StrategyFunc(func(out adversary.Output) []*wire.Envelope {
+        if !n.careless {
+            return out.Envelopes
+        }
+        return lie.Rewrite(out)
+    })
+    for _, id := range peers {
+        v := pos.NewValidator(pos.ValidatorConfig{ID: id, Peers: peers, Stakes: stakes, SlashPercent: slashPercent, JailSlots: jailSlots, Clock: s.Clock()})
+        n.validators = append(n.validators, v)
+        var r node.Replica = v
+        if id == offender {
+            r = adversary.Wrap(v, secondSigner)
+        }
+        s.Add(evidence.Watch(r, evidence.Config{Key: private[id], Keys: keys, Peers: peers, Relay: true, OnEvidence: func(ev *wire.Evidence) {
+            if n.careless {
+                n.careless = false
+                fmt.Printf("%8v  %s holds two blocks node-2 signed for one slot; node-2's operator stops the second signer\n", at(n.Now()), node.Name(id))
+            }
+        }}))
+    }
+    return n
+}
+
+// phase runs the network for d and prints how many blocks of node-0's chain each validator proposed meanwhile.
+func (n *network) phase(d time.Duration) {
+    from := n.Clock().Now()
+    n.RunFor(d)
+    to := n.Clock().Now()
+    proposed := map[string]int{}
+    for _, block := range n.validators[0].Chain()[1:] {
+        if t := wire.Timestamp(block.GetTimestamp()).Time(); !t.Before(from) && t.Before(to) {
+            proposed[block.GetProducer()]++
+        }
+    }
+    producers := make([]string, 0, len(proposed))
+    for producer := range proposed {
+        producers = append(producers, producer)
+    }
+    sort.Strings(producers)
+    counts := make([]string, len(producers))
+    for i, producer := range producers {
+        counts[i] = fmt.Sprintf("%s %d", producer, proposed[producer])
+    }
+    fmt.Printf("%8v  blocks proposed: %s\n", at(n.Now()), strings.Join(counts, ", "))
+}
+
+// stakes prints every validator's bonded stake as node-0 sees it, marking the jailed ones.
+func (n *network) stakes() {
+    v := n.validators[0]
+    parts := make([]string, len(n.validators))
+    for i := range n.validators {
+        id := int32(i)
+        parts[i] = fmt.Sprintf("%s %d", node.Name(id), v.Stake(id))
+        if v.Jailed(id) {
+            parts[i] += " (jailed)"
+        }
+    }
+    fmt.Printf("%8v  stakes: %s\n", at(n.Now()), strings.Join(parts, ", "))
+}
+
+// at formats a virtual time to the millisecond.
+func at(t time.Duration) time.Duration {
+    return t.Round(time.Millisecond)
+}
+
+// section prints the heading of a stage of the validator's life.
+func section(title string) {
+    fmt.Printf("\n== %s\n", title)
+}
+
+// Footer: Overview and Execution Flow
+//
+// 1. **Registering and Bonding**: A validator without stake is a full node: it receives and checks blocks but
+//    is never scheduled. pos.Validator.Bond gives it stake, and the schedule draws it from the next slot on, in
+//    proportion to its stake. Bonding is a transaction every validator applies; the example tells each of them.
+// 2. **Double-Signing**: The second signer is an adversary.Equivocate strategy that forges a different block for
+//    node-3 and node-4 in each of node-2's slots. Every validator signs what it proposes (package evidence), so
+//    the two versions are two signatures of node-2 for one slot: proof that anyone can check.
+// 3. **Slashing and Jailing**: Each watcher that holds the proof passes it on and calls Slash, which burns 10%
+//    of node-2's stake and jails it for 20 slots. A jailed validator is out of the schedule, its blocks are
+//    refused, and the watchers drop its messages.
+// 4. **Unjailing**: Unjail fails with pos.ErrJailed until the jail time has run out. After that it puts node-2
+//    back in the schedule with its remaining stake, and the watchers, through evidence.Jailer, listen to it again.
@@ -29,14 +29,16 @@ func main() {
     blockchain.CountVotes()
 
     // Add blocks to the blockchain using the elected delegates.
-    blockchain.AddBlock("First voting data")
-    blockchain.AddBlock("Second voting data")
-
-    // Iterate over each block in the blockchain and print the block's details.
-    for _, block := range blockchain.Blocks {
-        fmt.Printf("Index: %d\nTimestamp: %s\nData: %s\nPrevious Hash: %s\nHash: %s\nDelegate: %s\n\n", 
-            block.Index, block.Timestamp, block.Data, block.PrevHash, block.Hash, block.Delegate)
+    for _, data := range []string{"First voting data", "Second voting data"} {
+        if _, err := blockchain.AddBlock(data); err != nil {
+            fmt.Println("Failed to add block:", err)
+            return
+        }
     }
+
+    // Print the chain: a summary line, then one line per block.
+    fmt.Println(blockchain)
+    fmt.Print(blockchain.Dump())
 }
 
 // Footer: Overview and Execution Flow
@@ -51,8 +53,8 @@ func main() {
 //    using `CountVotes()`, which sorts the delegates based on votes received.
 // 3. **Block Addition**: New blocks are added to the blockchain by the elected delegates using the `AddBlock()` function.
 //    Each block includes information about the delegate who validated and added the block to the chain.
-// 4. **Blockchain Display**: Finally, details of each block, such as index, timestamp, data, previous hash, current hash, 
-//    and the delegate, are printed to show how the blockchain has evolved.
+// 4. **Blockchain Display**: Finally, `Dump()` prints each block's index, hash, previous hash, data
+//    and delegate, one line per block, to show how the blockchain has evolved.
 //
 // DPoS is a highly efficient consensus mechanism, making it suitable for environments where scalability and low latency are
 // critical. This implementation illustrates how decentralized governance can be achieved by delegating responsibilities to
@@ -0,0 +1,217 @@
+// Package main shows what a Raft witness buys. A witness votes and acknowledges entries like any replica but
+// keeps only their terms, not their blocks, and never leads. The example compares three deployments — two
+// replicas, three replicas, and two replicas with a witness — by crashing each kind of member in turn and trying
+// to commit an entry: two replicas stall whichever one is lost, while a witness lets two replicas survive any
+// single failure, as three would, with only two copies of the data. It then shows the price: with one replica
+// down, entries are committed on a single copy, and if that replica is lost too, the witness refuses to elect the
+// other one, which lacks them, so the cluster waits for the copy to come back rather than lose it.
+package main
+
+import (
+    "fmt"
+    "os"
+    "slices"
+    "strings"
+    "text/tabwriter"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+)
+
+// patience is the virtual time an entry is given to commit, a new leader included, before the cluster is
+// considered stalled.
+const patience = 3 * time.Second
+
+// deployment is a cluster layout: its members, and which of them are witnesses.
+type deployment struct {
+    name      string
+    peers     []int32
+    witnesses []int32
+}
+
+var deployments = []deployment{
+    {name: "2 replicas", peers: []int32{0, 1}},
+    {name: "3 replicas", peers: []int32{0, 1, 2}},
+    {name: "2 replicas + witness", peers: []int32{0, 1, 2}, witnesses: []int32{2}},
+}
+
+// cluster is a simulated deployment.
+type cluster struct {
+    *sim.Simulator
+    replicas []*raft.Replica
+}
+
+func (d deployment) build() *cluster {
+    s := sim.New(sim.Config{Seed: 5, Network: sim.Link{Latency: sim.Uniform(2*time.Millisecond, 10*time.Millisecond)}})
+    c := &cluster{Simulator: s}
+    for _, id := range d.peers {
+        r := raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: d.peers, Witnesses: d.witnesses, Rand: s.NewRand(), Clock: s.Clock()})
+        c.replicas = append(c.replicas, r)
+        s.Add(r)
+    }
+    return c
+}
+
+// leader returns the running replica that leads and that a majority of the members follow, if any.
+func (c *cluster) leader() (*raft.Replica, bool) {
+    followers := map[int32]int{}
+    for _, r := range c.replicas {
+        if !c.Crashed(r.ID()) && r.Leader() >= 0 {
+            followers[r.Leader()]++
+        }
+    }
+    for id, n := range followers {
+        if n > len(c.replicas)/2 && !c.Crashed(id) && c.replicas[id].Role() == raft.Leader {
+            return c.replicas[id], true
+        }
+    }
+    return nil, false
+}
+
+// commit waits for a leader, proposes data to it and reports whether it committed within patience.
+func (c *cluster) commit(data string) bool {
+    deadline := c.Now() + patience
+    var leader *raft.Replica
+    elected := func() bool {
+        var ok bool
+        leader, ok = c.leader()
+        return ok
+    }
+    if !c.RunUntil(elected, deadline) || c.Propose(leader.ID(), data) != nil {
+        return false
+    }
+    committed := func() bool {
+        return slices.ContainsFunc(leader.Committed(), func(b *wire.Block) bool { return b.GetData() == data })
+    }
+    return c.RunUntil(committed, deadline)
+}
+
+// data returns the replicas of a cluster that keep blocks.
+func (c *cluster) data() []*raft.Replica {
+    return slices.DeleteFunc(slices.Clone(c.replicas), (*raft.Replica).Witness)
+}
+
+// victim returns the member of a kind to crash — "leader", "follower" or "witness" — or false if the cluster has
+// none of that kind.
+func (c *cluster) victim(kind string) (int32, bool) {
+    leader, _ := c.leader()
+    for _, r := range c.replicas {
+        switch {
+        case kind == "leader" && r == leader,
+            kind == "follower" && r != leader && !r.Witness(),
+            kind == "witness" && r.Witness():
+            return r.ID(), true
+        }
+    }
+    return -1, false
+}
+
+// survives crashes one member of a kind in a fresh deployment, after a first entry has committed, and describes
+// whether the next entry still commits.
+func survives(d deployment, kind string) string {
+    c := d.build()
+    if !c.commit("Before the crash") {
+        return "no leader"
+    }
+    id, ok := c.victim(kind)
+    if !ok {
+        return "-"
+    }
+    c.Crash(id)
+    if c.commit("After the crash") {
+        return "keeps committing"
+    }
+    return "stalls"
+}
+
+func main() {
+    fmt.Println("Losing one member of each deployment, then proposing an entry:")
+    fmt.Println()
+    tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(tw, "deployment\tcopies of each block\tleader lost\tfollower lost\twitness lost")
+    for _, d := range deployments {
+        copies := len(d.peers) - len(d.witnesses)
+        fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n", d.name, copies, survives(d, "leader"), survives(d, "follower"), survives(d, "witness"))
+    }
+    tw.Flush()
+
+    fmt.Println()
+    fmt.Println("The price of a witness: an entry committed on a single copy")
+    c := deployments[2].build()
+    step := func(format string, args ...any) {
+        fmt.Printf("%8v  %s\n", c.Now().Round(time.Millisecond), fmt.Sprintf(format, args...))
+    }
+    if !c.commit("Entry 1") {
+        step("no leader was elected")
+        os.Exit(1)
+    }
+    leader, _ := c.leader()
+    follower, _ := c.victim("follower")
+    step("%s leads; %q is committed on both replicas", node.Name(leader.ID()), "Entry 1")
+    c.Crash(follower)
+    if !c.commit("Entry 2") {
+        step("%q did not commit without %s", "Entry 2", node.Name(follower))
+        os.Exit(1)
+    }
+    step("%s crashes; %q commits with the acknowledgements of %s and the witness: one copy of its block exists", node.Name(follower), "Entry 2", node.Name(leader.ID()))
+    // Entries the leader sent the follower before crashing are still on the wire, and would reach it once it is back;
+    // cutting the link between them drops them, as if they had been lost with the leader.
+    c.Network.Disconnect(leader.ID(), follower)
+    c.Crash(leader.ID())
+    c.Recover(follower)
+    step("%s crashes too, and %s comes back without %q", node.Name(leader.ID()), node.Name(follower), "Entry 2")
+    if c.commit("Entry 3") {
+        step("%s was elected without %q: a committed entry was lost", node.Name(follower), "Entry 2")
+        os.Exit(1)
+    }
+    step("no leader for %v: the witness knows %s's log ends before an entry it acknowledged, and refuses its vote", patience, node.Name(follower))
+    c.Network.Connect(leader.ID(), follower)
+    c.Recover(leader.ID())
+    if !c.commit("Entry 3") {
+        step("the cluster did not recover")
+        os.Exit(1)
+    }
+    now, _ := c.leader()
+    caughtUp := func() bool {
+        for _, r := range c.data() {
+            if len(r.Committed()) != len(now.Committed()) {
+                return false
+            }
+        }
+        return true
+    }
+    c.RunUntil(caughtUp, c.Now()+time.Second)
+    for _, r := range c.data() {
+        if d := wire.Diff(r.Committed(), now.Committed()); !d.Same() {
+            step("%s and %s: %v", node.Name(r.ID()), node.Name(now.ID()), d)
+            os.Exit(1)
+        }
+    }
+    step("%s comes back; %s leads, and both replicas commit %s", node.Name(leader.ID()), node.Name(now.ID()), blocks(now))
+}
+
+// blocks lists the data of a replica's committed blocks after the genesis block.
+func blocks(r *raft.Replica) string {
+    var data []string
+    for _, b := range r.Committed()[1:] {
+        data = append(data, fmt.Sprintf("%q", b.GetData()))
+    }
+    return strings.Join(data, ", ")
+}
+
+// Footer: Overview and Execution Flow
+//
+// 1. **Deployments**: Every deployment is built fresh for each failure, commits a first entry, loses one member
+//    — its leader, a replica that follows, or its witness — and is given 3 seconds of virtual time to elect a
+//    leader if needed and commit a second entry.
+// 2. **Two Replicas**: A majority of two is two, so either loss stalls the cluster: a pair cannot break a tie.
+// 3. **A Witness**: With raft.ReplicaConfig.Witnesses, the third member votes and acknowledges entries but keeps
+//    only their terms. A majority of three is two, so any single loss is survived, as with three replicas, while
+//    only two members store blocks.
+// 4. **The Price**: With a replica down, the leader and the witness commit entries the other replica lacks. If
+//    the leader is lost as well, the witness, which kept the terms of those entries, refuses to elect the
+//    replica without them. Raft's election restriction holds, and the cluster waits for the only copy instead
+//    of electing a leader that would overwrite it.
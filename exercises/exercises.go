@@ -0,0 +1,92 @@
+// Package exercises turns the core rules of the algorithms in this repository into graded assignments. Each
+// Exercise asks a student to write one small function — the rule by which a Raft replica grants its vote, the
+// size of a PBFT quorum, the Proof of Work difficulty check, stake-weighted PoS selection, or a Paxos acceptor's
+// promise — in the skeleton files of package exercises/student, where the function body is a TODO.
+//
+// Grade runs a Solution against a scenario suite per exercise: each scenario sets up a situation the rule must
+// handle, such as a candidate from an older term or a validator without stake, and checks the answer. The suites
+// live in this package rather than next to the skeletons, so a student works from the description of the rule,
+// not from the cases it will be tested on, and the Report says which scenarios failed without giving away the
+// expected answers. `consensus grade` grades the student package from the command line.
+package exercises
+
+import (
+    "consensus-algorithms-edu/wire"
+)
+
+// TODO is what a skeleton panics with until it is implemented. Grade reports an exercise whose every scenario
+// panicked with it as not attempted rather than failed.
+const TODO = "TODO: not implemented yet"
+
+// NoVote is the Voter.VotedFor of a replica that has not voted in its current term.
+const NoVote int32 = -1
+
+// Voter is the state of a Raft replica that is asked for its vote.
+type Voter struct {
+    Term         uint64 // Latest term the replica has seen.
+    VotedFor     int32  // Candidate it voted for in Term, or NoVote.
+    LastLogIndex int64  // Index of the last entry in its log.
+    LastLogTerm  uint64 // Term of the last entry in its log.
+}
+
+// Acceptor is the state of a single-decree Paxos acceptor.
+type Acceptor struct {
+    Promised       int64       // Highest ballot the acceptor has promised; 0 if none.
+    AcceptedBallot int64       // Ballot of the value it accepted; 0 if none.
+    AcceptedValue  *wire.Block // Value it accepted, or nil.
+}
+
+// Solution holds a student's implementation of every exercise. A nil field is an exercise that was not attempted.
+type Solution struct {
+    // RaftVote reports whether a replica in state v grants its vote to the candidate that sent req.
+    RaftVote func(v Voter, req *wire.RequestVote) bool
+
+    // PBFTQuorum returns how many of n replicas must agree for PBFT to make progress while tolerating as many
+    // Byzantine replicas as n allows.
+    PBFTQuorum func(n int) int
+
+    // PoWMeetsDifficulty reports whether a hex-encoded hash meets a Proof of Work difficulty target of
+    // difficulty leading zeros.
+    PoWMeetsDifficulty func(hash string, difficulty int) bool
+
+    // PoSSelect returns the index of the validator selected by pick, a number drawn uniformly from
+    // [0, total stake), so that each validator is selected in proportion to its stake.
+    PoSSelect func(stakes []int, pick int) int
+
+    // PaxosPrepare answers a phase 1a prepare request: it returns the promise an acceptor in state a sends, or
+    // nil if it refuses, and records any promise it makes in a.
+    PaxosPrepare func(a *Acceptor, m *wire.PaxosPrepare) *wire.PaxosPromise
+}
+
+// Exercise describes one assignment.
+type Exercise struct {
+    Name  string // Short identifier, e.g. "raft-vote".
+    Title string // What to do, e.g. "Implement the Raft vote rule".
+    File  string // Skeleton file the student completes.
+    Field string // Field of Solution the exercise grades.
+
+    suite []scenario // The scenarios Grade runs; kept unexported so students do not work to the test.
+}
+
+// Exercises lists every exercise, in the order a course would assign them.
+var Exercises = []Exercise{
+    {Name: "pow-difficulty", Title: "Check a Proof of Work difficulty target", File: "exercises/student/pow.go", Field: "PoWMeetsDifficulty", suite: powSuite},
+    {Name: "pos-select", Title: "Select a validator in proportion to its stake", File: "exercises/student/pos.go", Field: "PoSSelect", suite: posSuite},
+    {Name: "pbft-quorum", Title: "Size a PBFT quorum", File: "exercises/student/pbft.go", Field: "PBFTQuorum", suite: pbftSuite},
+    {Name: "raft-vote", Title: "Implement the Raft vote rule", File: "exercises/student/raft.go", Field: "RaftVote", suite: raftSuite},
+    {Name: "paxos-prepare", Title: "Implement a Paxos acceptor's promise", File: "exercises/student/paxos.go", Field: "PaxosPrepare", suite: paxosSuite},
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **One Function per Exercise**: Each exercise is a single rule with plain inputs, not a whole replica. A
+//    student can reason about the vote rule without first understanding timers and transports, and a failed
+//    scenario points at one decision rather than at an election that went wrong somewhere.
+//
+// 2. **Real Message Types**: The Raft and Paxos exercises take and return the messages of package wire, so a
+//    finished exercise reads like the corresponding handler in the algorithm packages and can be compared with
+//    it afterwards.
+//
+// 3. **Suites Beside the Grader**: The scenarios are unexported and kept out of the student package. An
+//    instructor who wants suites students have never seen can edit scenarios.go before a course; the report
+//    names what each failed scenario was about, never the expected value.
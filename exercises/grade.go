@@ -0,0 +1,126 @@
+package exercises
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "reflect"
+    "slices"
+)
+
+// ErrUnknownExercise is returned by Grade for a name that is not one of Exercises.
+var ErrUnknownExercise = errors.New("exercises: unknown exercise")
+
+// scenario is one situation an exercise's rule must handle.
+type scenario struct {
+    name  string                  // What the scenario checks, shown in the report.
+    check func(s Solution) string // Runs the student's rule and returns why it failed, or "" if it passed.
+}
+
+// Outcome is the result of one scenario.
+type Outcome struct {
+    Scenario string
+    Passed   bool
+    Detail   string // Why the scenario failed: a wrong answer or a panic.
+}
+
+// Result is how a solution did on one exercise.
+type Result struct {
+    Exercise  Exercise
+    Attempted bool      // False for a nil Solution field, or a skeleton that still panics with TODO everywhere.
+    Outcomes  []Outcome // One per scenario; empty if not attempted.
+    Score     int       // Scenarios passed.
+    Max       int       // Scenarios in the suite.
+}
+
+// Report is the graded solution.
+type Report struct {
+    Results []Result
+    Score   int // Scenarios passed over every exercise.
+    Max     int // Scenarios in every suite.
+}
+
+// Percent returns the score as a percentage of the maximum, or 0 for an empty report.
+func (r *Report) Percent() float64 {
+    if r.Max == 0 {
+        return 0
+    }
+    return 100 * float64(r.Score) / float64(r.Max)
+}
+
+// Grade runs s against the scenario suite of every exercise, or only of the exercises named in only. Every
+// scenario counts one point. A scenario that panics fails without stopping the others.
+func Grade(s Solution, only ...string) (*Report, error) {
+    for _, name := range only {
+        if !slices.ContainsFunc(Exercises, func(e Exercise) bool { return e.Name == name }) {
+            return nil, fmt.Errorf("%w %q", ErrUnknownExercise, name)
+        }
+    }
+    report := &Report{}
+    for _, exercise := range Exercises {
+        if len(only) > 0 && !slices.Contains(only, exercise.Name) {
+            continue
+        }
+        result := grade(exercise, s)
+        report.Results = append(report.Results, result)
+        report.Score += result.Score
+        report.Max += result.Max
+    }
+    return report, nil
+}
+
+// grade runs one exercise's suite.
+func grade(exercise Exercise, s Solution) Result {
+    result := Result{Exercise: exercise, Max: len(exercise.suite)}
+    if reflect.ValueOf(s).FieldByName(exercise.Field).IsNil() {
+        return result
+    }
+    todo := 0
+    for _, sc := range exercise.suite {
+        outcome, unimplemented := run(sc, s)
+        if unimplemented {
+            todo++
+        }
+        if outcome.Passed {
+            result.Score++
+        }
+        result.Outcomes = append(result.Outcomes, outcome)
+    }
+    if todo == len(exercise.suite) {
+        return Result{Exercise: exercise, Max: len(exercise.suite)} // The skeleton was handed in unchanged.
+    }
+    result.Attempted = true
+    return result
+}
+
+// run runs one scenario, turning a panic into a failure. It reports whether the panic was the skeleton's TODO.
+func run(sc scenario, s Solution) (outcome Outcome, todo bool) {
+    outcome.Scenario = sc.name
+    defer func() {
+        if r := recover(); r != nil {
+            outcome.Passed, outcome.Detail, todo = false, fmt.Sprintf("panicked: %v", r), r == TODO
+        }
+    }()
+    outcome.Detail = sc.check(s)
+    outcome.Passed = outcome.Detail == ""
+    return outcome, false
+}
+
+// Print writes the report: a line per exercise with its score, the scenarios it failed, and the total.
+func (r *Report) Print(w io.Writer) error {
+    for _, result := range r.Results {
+        e := result.Exercise
+        if !result.Attempted {
+            fmt.Fprintf(w, "%-16s %-48s  not attempted: complete %s in %s\n", e.Name, e.Title, e.Field, e.File)
+            continue
+        }
+        fmt.Fprintf(w, "%-16s %-48s  %d/%d\n", e.Name, e.Title, result.Score, result.Max)
+        for _, outcome := range result.Outcomes {
+            if !outcome.Passed {
+                fmt.Fprintf(w, "    FAILED %s: %s\n", outcome.Scenario, outcome.Detail)
+            }
+        }
+    }
+    _, err := fmt.Fprintf(w, "score %d/%d (%.0f%%)\n", r.Score, r.Max, r.Percent())
+    return err
+}
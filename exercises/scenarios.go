@@ -0,0 +1,148 @@
+package exercises
+
+import (
+    "fmt"
+
+    "consensus-algorithms-edu/wire"
+)
+
+// powSuite checks the difficulty target: the hash must start with at least difficulty zeros.
+var powSuite = []scenario{
+    {"accepts a hash with exactly the required zeros", func(s Solution) string { return want(s.PoWMeetsDifficulty("0000a3f1", 4), true) }},
+    {"accepts a hash with more zeros than required", func(s Solution) string { return want(s.PoWMeetsDifficulty("000000b7", 4), true) }},
+    {"rejects a hash one zero short", func(s Solution) string { return want(s.PoWMeetsDifficulty("000a3f10", 4), false) }},
+    {"rejects zeros that are not leading", func(s Solution) string { return want(s.PoWMeetsDifficulty("a0000000", 4), false) }},
+    {"accepts any hash at difficulty 0", func(s Solution) string { return want(s.PoWMeetsDifficulty("ffffffff", 0), true) }},
+    {"rejects a hash shorter than the target", func(s Solution) string { return want(s.PoWMeetsDifficulty("00", 4), false) }},
+}
+
+// posSuite checks stake-weighted selection: validator i owns the picks from the sum of the stakes before it up
+// to, but not including, that sum plus its own stake.
+var posSuite = []scenario{
+    {"selects the first validator for pick 0", func(s Solution) string { return want(s.PoSSelect([]int{10, 20, 30}, 0), 0) }},
+    {"selects the first validator up to the end of its stake", func(s Solution) string { return want(s.PoSSelect([]int{10, 20, 30}, 9), 0) }},
+    {"selects the next validator right after a stake ends", func(s Solution) string { return want(s.PoSSelect([]int{10, 20, 30}, 10), 1) }},
+    {"selects the last validator for the highest pick", func(s Solution) string { return want(s.PoSSelect([]int{10, 20, 30}, 59), 2) }},
+    {"never selects a validator without stake", func(s Solution) string { return want(s.PoSSelect([]int{0, 5, 0, 5}, 5), 3) }},
+    {"selects every validator in proportion to its stake", func(s Solution) string {
+        stakes := []int{3, 1, 4, 1, 5}
+        counts := make([]int, len(stakes))
+        total := 0
+        for _, stake := range stakes {
+            total += stake
+        }
+        for pick := 0; pick < total; pick++ {
+            if i := s.PoSSelect(stakes, pick); i >= 0 && i < len(stakes) {
+                counts[i]++
+            }
+        }
+        return want(fmt.Sprint(counts), fmt.Sprint(stakes))
+    }},
+}
+
+// pbftSuite checks the quorum: 2f+1 out of n replicas, with f = (n-1)/3 the Byzantine replicas n tolerates.
+var pbftSuite = []scenario{
+    {"needs every replica of a single-replica network", func(s Solution) string { return want(s.PBFTQuorum(1), 1) }},
+    {"tolerates one fault with four replicas", func(s Solution) string { return want(s.PBFTQuorum(4), 3) }},
+    {"gains no tolerance from a fifth or sixth replica", func(s Solution) string {
+        return want(fmt.Sprint(s.PBFTQuorum(5), s.PBFTQuorum(6)), "3 3")
+    }},
+    {"tolerates two faults with seven replicas", func(s Solution) string { return want(s.PBFTQuorum(7), 5) }},
+    {"makes any two quorums of 3f+1 replicas share a correct replica", func(s Solution) string {
+        for f := 0; f <= 16; f++ {
+            n, q := 3*f+1, s.PBFTQuorum(3*f+1)
+            if 2*q-n <= f {
+                return fmt.Sprintf("two quorums of %d out of %d replicas may share only faulty ones", q, n)
+            }
+        }
+        return ""
+    }},
+    {"never waits for the replicas that may be faulty", func(s Solution) string {
+        for n := 1; n <= 50; n++ {
+            if f, q := (n-1)/3, s.PBFTQuorum(n); q > n-f {
+                return fmt.Sprintf("a quorum of %d out of %d replicas stalls when %d are faulty", q, n, f)
+            }
+        }
+        return ""
+    }},
+}
+
+// raftSuite checks the vote rule: a replica grants its vote if the candidate's term is not older than its own,
+// it has not voted for another candidate in that term, and the candidate's log is at least as up to date.
+var raftSuite = []scenario{
+    {"grants an up-to-date candidate in a fresh term", func(s Solution) string {
+        return want(s.RaftVote(Voter{Term: 2, VotedFor: NoVote, LastLogIndex: 3, LastLogTerm: 1}, vote(2, 1, 3, 1)), true)
+    }},
+    {"refuses a candidate from an older term", func(s Solution) string {
+        return want(s.RaftVote(Voter{Term: 3, VotedFor: NoVote}, vote(2, 1, 0, 0)), false)
+    }},
+    {"refuses a second candidate in the same term", func(s Solution) string {
+        return want(s.RaftVote(Voter{Term: 2, VotedFor: 4}, vote(2, 1, 0, 0)), false)
+    }},
+    {"grants a repeated request from the candidate it voted for", func(s Solution) string {
+        return want(s.RaftVote(Voter{Term: 2, VotedFor: 1}, vote(2, 1, 0, 0)), true)
+    }},
+    {"votes again in a newer term", func(s Solution) string {
+        return want(s.RaftVote(Voter{Term: 2, VotedFor: 4}, vote(3, 1, 0, 0)), true)
+    }},
+    {"refuses a candidate whose last entry has an older term", func(s Solution) string {
+        return want(s.RaftVote(Voter{Term: 5, VotedFor: NoVote, LastLogIndex: 2, LastLogTerm: 4}, vote(5, 1, 9, 3)), false)
+    }},
+    {"refuses a candidate with the same last term and a shorter log", func(s Solution) string {
+        return want(s.RaftVote(Voter{Term: 5, VotedFor: NoVote, LastLogIndex: 7, LastLogTerm: 4}, vote(5, 1, 6, 4)), false)
+    }},
+    {"grants a candidate with a newer last term and a shorter log", func(s Solution) string {
+        return want(s.RaftVote(Voter{Term: 5, VotedFor: NoVote, LastLogIndex: 7, LastLogTerm: 3}, vote(5, 1, 2, 4)), true)
+    }},
+}
+
+// paxosSuite checks phase 1b: an acceptor promises a ballot higher than any it promised before, records the
+// promise, and reports the value it accepted, if any, so the proposer can adopt it.
+var paxosSuite = []scenario{
+    {"promises the first ballot it sees", func(s Solution) string {
+        a := &Acceptor{}
+        return want(s.PaxosPrepare(a, &wire.PaxosPrepare{Ballot: 1}) != nil, true)
+    }},
+    {"records the ballot it promised", func(s Solution) string {
+        a := &Acceptor{Promised: 2}
+        s.PaxosPrepare(a, &wire.PaxosPrepare{Ballot: 5})
+        return want(a.Promised, int64(5))
+    }},
+    {"refuses a ballot lower than its promise", func(s Solution) string {
+        a := &Acceptor{Promised: 5}
+        return want(s.PaxosPrepare(a, &wire.PaxosPrepare{Ballot: 3}) != nil, false)
+    }},
+    {"keeps its promise after refusing a lower ballot", func(s Solution) string {
+        a := &Acceptor{Promised: 5}
+        s.PaxosPrepare(a, &wire.PaxosPrepare{Ballot: 3})
+        return want(a.Promised, int64(5))
+    }},
+    {"reports the value it accepted in the promise", func(s Solution) string {
+        a := &Acceptor{Promised: 4, AcceptedBallot: 4, AcceptedValue: &wire.Block{Index: 1, Data: "Chosen value"}}
+        promise := s.PaxosPrepare(a, &wire.PaxosPrepare{Ballot: 6})
+        if promise == nil {
+            return "refused a higher ballot"
+        }
+        return want(fmt.Sprint(promise.GetAcceptedBallot(), " ", promise.GetAcceptedValue().GetData()), "4 Chosen value")
+    }},
+    {"answers with the ballot and slot of the request", func(s Solution) string {
+        promise := s.PaxosPrepare(&Acceptor{}, &wire.PaxosPrepare{Ballot: 7, Slot: 2})
+        if promise == nil {
+            return "refused the first ballot"
+        }
+        return want(fmt.Sprint(promise.GetBallot(), promise.GetSlot()), "7 2")
+    }},
+}
+
+// vote builds a RequestVote message.
+func vote(term uint64, candidate int32, lastIndex int64, lastTerm uint64) *wire.RequestVote {
+    return &wire.RequestVote{Term: term, CandidateId: candidate, LastLogIndex: lastIndex, LastLogTerm: lastTerm}
+}
+
+// want returns "" if got equals expected, and otherwise a failure that shows the answer without the expected one.
+func want[T comparable](got, expected T) string {
+    if got == expected {
+        return ""
+    }
+    return fmt.Sprintf("wrong answer %v", got)
+}
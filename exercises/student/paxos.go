@@ -0,0 +1,19 @@
+package student
+
+import (
+    "consensus-algorithms-edu/exercises"
+    "consensus-algorithms-edu/wire"
+)
+
+// PaxosPrepare answers a prepare request (phase 1a) for ballot m.GetBallot(). It returns the promise (phase 1b)
+// the acceptor in state a sends back, or nil if it refuses, and records in a any promise it makes.
+//
+// By promising a ballot, an acceptor undertakes never to accept a value under a lower one. Its promise also
+// carries the value it has already accepted, if any, with the ballot it was accepted under: a proposer that
+// hears of an accepted value must propose that value instead of its own, which is what keeps a chosen value
+// chosen.
+func PaxosPrepare(a *exercises.Acceptor, m *wire.PaxosPrepare) *wire.PaxosPromise {
+    // TODO: Refuse a ballot that is not higher than a.Promised. Otherwise raise a.Promised and return a
+    //       promise for the same ballot and slot that reports a.AcceptedBallot and a.AcceptedValue.
+    panic(exercises.TODO)
+}
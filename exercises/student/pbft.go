@@ -0,0 +1,15 @@
+package student
+
+import (
+    "consensus-algorithms-edu/exercises"
+)
+
+// PBFTQuorum returns how many of n replicas must agree before PBFT commits a block.
+//
+// PBFT tolerates f Byzantine replicas, which may lie or stay silent, as long as n >= 3f + 1. A quorum must be
+// small enough to be reached without the f faulty replicas, and large enough that any two quorums overlap in at
+// least one correct replica, so two conflicting blocks can never both be committed.
+func PBFTQuorum(n int) int {
+    // TODO: Work out the largest f that n replicas tolerate, then the quorum size in terms of f.
+    panic(exercises.TODO)
+}
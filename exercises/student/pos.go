@@ -0,0 +1,17 @@
+package student
+
+import (
+    "consensus-algorithms-edu/exercises"
+)
+
+// PoSSelect returns the index in stakes of the validator that produces the next block. pick is a random number
+// drawn uniformly from [0, total stake).
+//
+// In Proof of Stake, a validator's chance of being selected is proportional to its stake. Lay the stakes out
+// one after another on a line from 0 to the total: each validator owns a segment as long as its stake, and the
+// validator whose segment contains pick is selected.
+func PoSSelect(stakes []int, pick int) int {
+    // TODO: Walk through stakes, keeping a running total, and return the index of the first validator whose
+    //       segment ends after pick. Which validators can never be selected?
+    panic(exercises.TODO)
+}
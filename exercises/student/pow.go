@@ -0,0 +1,16 @@
+package student
+
+import (
+    "consensus-algorithms-edu/exercises"
+)
+
+// PoWMeetsDifficulty reports whether hash, a hex-encoded SHA-256 hash, meets a difficulty target of difficulty
+// leading zeros.
+//
+// A miner tries nonce after nonce until the hash of its block starts with enough zeros. Each extra zero makes
+// a valid hash sixteen times rarer, which is how the network tunes how much work a block costs.
+func PoWMeetsDifficulty(hash string, difficulty int) bool {
+    // TODO: Return true only if the first difficulty characters of hash are all '0'.
+    //       What should happen when hash is shorter than difficulty?
+    panic(exercises.TODO)
+}
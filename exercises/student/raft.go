@@ -0,0 +1,21 @@
+package student
+
+import (
+    "consensus-algorithms-edu/exercises"
+    "consensus-algorithms-edu/wire"
+)
+
+// RaftVote reports whether a replica in state v grants its vote to the candidate that sent req.
+//
+// Raft elects at most one leader per term because each replica votes at most once per term. The election
+// restriction adds a second condition: a replica only votes for a candidate whose log is at least as up to date
+// as its own, so a new leader always holds every committed entry. A log is more up to date if its last entry
+// has a later term, or the same last term and a higher index.
+func RaftVote(v exercises.Voter, req *wire.RequestVote) bool {
+    // TODO: Implement the Raft vote rule.
+    //  1. Refuse a candidate whose term (req.GetTerm()) is older than v.Term.
+    //  2. In a newer term than v.Term the replica has not voted yet; in the same term, it may only vote for the
+    //     candidate it already voted for (v.VotedFor), if any.
+    //  3. Compare the candidate's last log term and index with v.LastLogTerm and v.LastLogIndex.
+    panic(exercises.TODO)
+}
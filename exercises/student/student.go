@@ -0,0 +1,20 @@
+// Package student is the handout of package exercises: one file per exercise, each with a function whose body
+// is a TODO. Replace each panic(exercises.TODO) with an implementation of the rule its comment describes, then
+// run `go run ./cmd/consensus grade` to see how many of the hidden scenarios it passes. The algorithm packages
+// implement every rule too; try not to look until the grader is happy.
+package student
+
+import (
+    "consensus-algorithms-edu/exercises"
+)
+
+// Solution returns the functions of this package for grading.
+func Solution() exercises.Solution {
+    return exercises.Solution{
+        RaftVote:           RaftVote,
+        PBFTQuorum:         PBFTQuorum,
+        PoWMeetsDifficulty: PoWMeetsDifficulty,
+        PoSSelect:          PoSSelect,
+        PaxosPrepare:       PaxosPrepare,
+    }
+}
@@ -0,0 +1,115 @@
+// Package feed delivers values to subscribers over channels. The chains use it to hand every block they append
+// to whoever is waiting for one, so that a consumer ranges over a channel instead of polling the chain for new
+// blocks. Unlike an events.Stream, a Feed never drops a value: each subscriber has its own queue, drained into its
+// channel by a goroutine, so a slow subscriber falls behind without slowing down the code that publishes or
+// losing what was published.
+package feed
+
+import (
+    "sync"
+)
+
+// Feed fans published values out to its subscribers. The zero Feed is ready to use, and every method is safe for
+// concurrent use. A Feed must not be copied after first use.
+type Feed[T any] struct {
+    mu   sync.Mutex
+    subs map[<-chan T]*subscriber[T]
+}
+
+// subscriber is one subscription: the values published since it subscribed that it has not received yet.
+type subscriber[T any] struct {
+    out   chan T
+    wake  chan struct{} // Signalled, without blocking, when queue gains a value.
+    done  chan struct{} // Closed by Unsubscribe.
+    mu    sync.Mutex
+    queue []T
+}
+
+// Subscribe returns a channel that receives every value published from now on, in the order they were
+// published. Pass it to Unsubscribe once done with it; a subscriber that stops reading without unsubscribing
+// holds on to everything published afterwards.
+func (f *Feed[T]) Subscribe() <-chan T {
+    s := &subscriber[T]{out: make(chan T), wake: make(chan struct{}, 1), done: make(chan struct{})}
+    f.mu.Lock()
+    if f.subs == nil {
+        f.subs = make(map[<-chan T]*subscriber[T])
+    }
+    f.subs[s.out] = s
+    f.mu.Unlock()
+    go s.run()
+    return s.out
+}
+
+// Unsubscribe stops delivering values to ch, a channel returned by Subscribe, and closes it. Values published
+// but not yet received are discarded. Unsubscribing a channel twice, or one the feed did not return, does nothing.
+func (f *Feed[T]) Unsubscribe(ch <-chan T) {
+    f.mu.Lock()
+    s, ok := f.subs[ch]
+    delete(f.subs, ch)
+    f.mu.Unlock()
+    if ok {
+        close(s.done)
+    }
+}
+
+// Publish queues v for every subscriber. It never blocks on a subscriber.
+func (f *Feed[T]) Publish(v T) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    for _, s := range f.subs {
+        s.mu.Lock()
+        s.queue = append(s.queue, v)
+        s.mu.Unlock()
+        select {
+        case s.wake <- struct{}{}:
+        default: // Already signalled; run will find v after the values before it.
+        }
+    }
+}
+
+// Len returns the number of subscribers.
+func (f *Feed[T]) Len() int {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return len(f.subs)
+}
+
+// run delivers the subscriber's queue to its channel until it unsubscribes, then closes the channel.
+func (s *subscriber[T]) run() {
+    defer close(s.out)
+    for {
+        s.mu.Lock()
+        if len(s.queue) == 0 {
+            s.mu.Unlock()
+            select {
+            case <-s.wake:
+                continue
+            case <-s.done:
+                return
+            }
+        }
+        v := s.queue[0]
+        var zero T
+        s.queue[0] = zero // Let the queue's backing array forget delivered values.
+        s.queue = s.queue[1:]
+        s.mu.Unlock()
+
+        select {
+        case s.out <- v:
+        case <-s.done:
+            return
+        }
+    }
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Unbounded Queues**: A chain publishes while holding its own lock, so Publish must never wait for a
+//    subscriber. Dropping values as events.Stream does would leave gaps in a chain that a consumer could only
+//    fill by polling again; queueing costs memory only for the subscribers that fall behind.
+//
+// 2. **One Goroutine per Subscriber**: Each subscriber drains its own queue, so one that stops reading delays
+//    nobody else, and Unsubscribe can have the channel closed by the only goroutine that sends on it.
+//
+// 3. **Channels as Keys**: Unsubscribe takes the channel Subscribe returned, so callers keep one value rather
+//    than a channel and a handle to close it.
@@ -0,0 +1,279 @@
+// Package grinding measures how far a Proof of Stake validator can bias the proposer draw in its favor by trying
+// many inputs to it and keeping the best. The draw is a hash, and no validator can choose a hash's output; but
+// whoever chooses its input can compute the output of a thousand candidates and pick one, which turns computing
+// power back into proposing power, the very thing stake was meant to replace. How much it gains depends only on
+// where the draw takes its randomness from, and Run compares three sources:
+//
+//   - Slot, the draw of pos.Schedule.Proposer: the hash of the slot number. Nothing in it can be changed, but every
+//     seed is known forever in advance, so an attacker grinds the one thing it does control, the key it registers
+//     its stake under, which decides where its stake falls in the draw.
+//   - Chain: the hash of the slot number and the block it follows, as early Proof of Stake chains drew. A proposer
+//     grinds its own block, trying contents until the next slot's draw falls to it again.
+//   - Beacon: the hash of the slot number and a randomness beacon revealed each epoch, after stake is registered,
+//     as RANDAO with a delay function or a VRF provides. Neither the key nor any block enters the draw before the
+//     beacon is known, so there is nothing to try twice.
+//
+// The draws are those of the real schedule over real pos blocks, but the chain around them is abstracted away:
+// every slot has a proposer, every block is built on the one before, and the run takes milliseconds.
+package grinding
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/binary"
+    "errors"
+    "fmt"
+    "math/rand"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/wire"
+)
+
+// ErrUnknownScheme is returned by Run for a scheme it has no scenario for.
+var ErrUnknownScheme = errors.New("grinding: unknown scheme")
+
+// Scheme is a source of randomness for the proposer draw.
+type Scheme string
+
+const (
+    Slot   Scheme = "slot"   // The slot number alone, as pos.Schedule.Proposer draws: public forever, grindable through keys.
+    Chain  Scheme = "chain"  // The slot number and the previous block's hash: grindable through block contents.
+    Beacon Scheme = "beacon" // The slot number and an epoch's beacon output, revealed after stake is registered.
+)
+
+// Schemes returns every scheme Run can play, the current one first.
+func Schemes() []Scheme {
+    return []Scheme{Slot, Chain, Beacon}
+}
+
+// Defaults used by Run for the zero values of Config.
+const (
+    DefaultHonest = 10
+    DefaultShare  = 0.1
+    DefaultSlots  = 10000
+    DefaultEpoch  = 32
+)
+
+// DefaultAttempts are the numbers of candidates Run lets the attacker try if Config.Attempts is empty.
+var DefaultAttempts = []int{1, 10, 100}
+
+// totalStake is the stake of all validators together, which Config.Share divides.
+const totalStake = 1_000_000
+
+// slotDuration is the time between two slots, which gives blocks their timestamps.
+const slotDuration = time.Second
+
+// Config describes the attack.
+type Config struct {
+    Schemes  []Scheme // Schemes to attack; every scheme of Schemes() if empty.
+    Honest   int      // Honest validators, holding equal stakes; DefaultHonest if 0.
+    Share    float64  // Attacker's share of all stake, below 1; DefaultShare if 0.
+    Attempts []int    // Numbers of candidate keys or blocks the attacker tries for each choice, one run each; DefaultAttempts if empty.
+    Slots    int      // Slots each run lasts; DefaultSlots if 0.
+    Epoch    int      // Slots between two registrations of stake and two beacon outputs; DefaultEpoch if 0.
+    Seed     int64    // Seed of every run, so each scheme meets the same honest validators and draws.
+}
+
+// Run plays the attack against every scheme in cfg, once for each number of attempts, and reports the results
+// scheme by scheme. It stops early with ctx's error once ctx is done.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+    if len(cfg.Schemes) == 0 {
+        cfg.Schemes = Schemes()
+    }
+    for _, scheme := range cfg.Schemes {
+        if _, ok := scenarios[scheme]; !ok {
+            return nil, fmt.Errorf("%w %q", ErrUnknownScheme, scheme)
+        }
+    }
+    if cfg.Honest <= 0 {
+        cfg.Honest = DefaultHonest
+    }
+    if cfg.Share <= 0 || cfg.Share >= 1 {
+        cfg.Share = DefaultShare
+    }
+    if len(cfg.Attempts) == 0 {
+        cfg.Attempts = DefaultAttempts
+    }
+    if cfg.Slots <= 0 {
+        cfg.Slots = DefaultSlots
+    }
+    if cfg.Epoch <= 0 {
+        cfg.Epoch = DefaultEpoch
+    }
+
+    report := &Report{Config: cfg}
+    for _, scheme := range cfg.Schemes {
+        for _, attempts := range cfg.Attempts {
+            l := newLottery(cfg)
+            won, err := scenarios[scheme](ctx, l, max(attempts, 1))
+            if err != nil {
+                return nil, err
+            }
+            report.Results = append(report.Results, Result{
+                Scheme:   scheme,
+                Attempts: max(attempts, 1),
+                Share:    float64(l.stake) / totalStake,
+                Won:      float64(won) / float64(cfg.Slots),
+                Slots:    cfg.Slots,
+            })
+        }
+    }
+    return report, nil
+}
+
+// lottery is the proposer draw of one run: honest validators under random keys, and an attacker's stake.
+type lottery struct {
+    cfg    Config
+    rng    *rand.Rand
+    honest map[int32]int // Stake of each honest validator, by ID.
+    stake  int           // Attacker's stake.
+}
+
+// newLottery returns the draw of a run, with honest validators whose IDs, like addresses derived from keys,
+// are spread at random.
+func newLottery(cfg Config) *lottery {
+    l := &lottery{cfg: cfg, rng: rand.New(rand.NewSource(cfg.Seed)), honest: make(map[int32]int)}
+    l.stake = int(cfg.Share * totalStake)
+    for len(l.honest) < cfg.Honest {
+        l.honest[l.key()] = (totalStake - l.stake) / cfg.Honest
+    }
+    return l
+}
+
+// key returns the ID of a freshly generated key, distinct from every honest validator's.
+func (l *lottery) key() int32 {
+    for {
+        if id := l.rng.Int31(); l.honest[id] == 0 {
+            return id
+        }
+    }
+}
+
+// schedule returns the draw with the attacker's stake registered under id.
+func (l *lottery) schedule(id int32) *pos.Schedule {
+    stakes := make(map[int32]int, len(l.honest)+1)
+    for other, stake := range l.honest {
+        stakes[other] = stake
+    }
+    stakes[id] = l.stake
+    return pos.NewSchedule(stakes)
+}
+
+// seed returns the seed of a slot's draw mixed with an input: a block hash, or a beacon output.
+func seed(slot int, input wire.Hash) [32]byte {
+    return sha256.Sum256(append(binary.BigEndian.AppendUint64(nil, uint64(slot)), input[:]...))
+}
+
+// scenario plays one scheme against an attacker that tries the given number of candidates for each choice,
+// and returns the number of slots the attacker was drawn for.
+type scenario func(ctx context.Context, l *lottery, attempts int) (int, error)
+
+var scenarios = map[Scheme]scenario{
+    Slot:   slotScenario,
+    Chain:  chainScenario,
+    Beacon: beaconScenario,
+}
+
+// slotScenario registers the attacker's stake at each epoch under the best of several fresh keys: since the draw
+// of every slot is known in advance, the attacker counts the slots each key would win and keeps the best.
+func slotScenario(ctx context.Context, l *lottery, attempts int) (int, error) {
+    won := 0
+    for start := 0; start < l.cfg.Slots; start += l.cfg.Epoch {
+        if err := ctx.Err(); err != nil {
+            return 0, err
+        }
+        best := -1
+        for range attempts {
+            id := l.key()
+            schedule, wins := l.schedule(id), 0
+            for slot := start; slot < min(start+l.cfg.Epoch, l.cfg.Slots); slot++ {
+                if schedule.Proposer(slot) == id {
+                    wins++
+                }
+            }
+            best = max(best, wins)
+        }
+        won += best
+    }
+    return won, nil
+}
+
+// chainScenario builds a chain in which each slot's draw is seeded with the previous block. Whenever the
+// attacker proposes, it tries several contents for its block and publishes the first whose hash draws it for the
+// next slot too, or the last it tried.
+func chainScenario(ctx context.Context, l *lottery, attempts int) (int, error) {
+    attacker := l.key()
+    schedule := l.schedule(attacker)
+    prev := pos.GenesisBlock()
+    won := 0
+    for slot := range l.cfg.Slots {
+        if slot%l.cfg.Epoch == 0 {
+            if err := ctx.Err(); err != nil {
+                return 0, err
+            }
+        }
+        at := time.Unix(0, 0).Add(time.Duration(slot+1) * slotDuration)
+        proposer := schedule.Draw(seed(slot, prev.Hash))
+        if proposer != attacker {
+            prev = pos.NewBlockAt(fmt.Sprintf("Block of slot %d", slot), prev.Hash, prev.Index+1, node.Name(proposer), at)
+            continue
+        }
+        won++
+        var block pos.Block
+        for attempt := range attempts {
+            block = pos.NewBlockAt(fmt.Sprintf("Block of slot %d, attempt %d", slot, attempt), prev.Hash, prev.Index+1, node.Name(attacker), at)
+            if schedule.Draw(seed(slot+1, block.Hash)) == attacker {
+                break
+            }
+        }
+        prev = block
+    }
+    return won, nil
+}
+
+// beaconScenario registers the attacker's stake at each epoch before the epoch's beacon output is revealed. The
+// attacker may generate as many keys as it likes, but cannot tell which will be lucky, so it keeps the first.
+func beaconScenario(ctx context.Context, l *lottery, attempts int) (int, error) {
+    won := 0
+    for start := 0; start < l.cfg.Slots; start += l.cfg.Epoch {
+        if err := ctx.Err(); err != nil {
+            return 0, err
+        }
+        var id int32
+        for attempt := range attempts {
+            if key := l.key(); attempt == 0 {
+                id = key // Without the beacon, every key is as good as the first.
+            }
+        }
+        var beacon wire.Hash
+        l.rng.Read(beacon[:])
+        schedule := l.schedule(id)
+        for slot := start; slot < min(start+l.cfg.Epoch, l.cfg.Slots); slot++ {
+            if schedule.Draw(seed(slot, beacon)) == id {
+                won++
+            }
+        }
+    }
+    return won, nil
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **The Real Draw, an Abstract Chain**: Every draw goes through pos.Schedule and every block is a pos block
+//    hashed as validators hash it, so the advantage measured is that of this repository's code. Everything that
+//    does not bear on the draw — latency, forks, other proposers' strategies — is left out, so the numbers show
+//    grinding alone.
+//
+// 2. **One Attacker, a Greedy Strategy**: The attacker in the chain scheme looks one slot ahead. Looking further,
+//    or withholding blocks to shift whose hash seeds the draw, gains more, so the table is a lower bound on what
+//    grinding the chain can achieve.
+//
+// 3. **Attempts Are the Attacker's Cost**: Each scheme spends its attempts on the one input the attacker controls
+//    — keys or block contents — and a beacon spends them for nothing. Comparing rows of equal attempts compares
+//    what the same computing power buys under each source of randomness.
+//
+// 4. **Registration per Epoch**: The attacker moves its stake to a new key at each epoch boundary, as bonding and
+//    unbonding allow in deployed Proof of Stake. pos.Validator itself fixes stakes for a whole run, which only
+//    leaves the attacker one choice of key instead of one per epoch.
@@ -0,0 +1,62 @@
+package grinding
+
+import (
+    "fmt"
+    "io"
+    "text/tabwriter"
+)
+
+// Report is the outcome of an attack.
+type Report struct {
+    Config  Config   // The attack, with defaults filled in.
+    Results []Result // One result per scheme and number of attempts, scheme by scheme.
+}
+
+// Result is what the attacker won against one scheme with one number of attempts.
+type Result struct {
+    Scheme   Scheme
+    Attempts int     // Candidates the attacker tried for each choice.
+    Share    float64 // Attacker's share of all stake.
+    Won      float64 // Attacker's share of the slots it was drawn to propose in.
+    Slots    int     // Slots the run lasted.
+}
+
+// Advantage returns how many times its share of the stake the attacker's share of the slots is.
+func (r Result) Advantage() float64 {
+    return r.Won / r.Share
+}
+
+// Resists reports whether the attacker's share of the slots stayed within a quarter of its share of the stake
+// for every number of attempts it tried against scheme: a margin the luck of the draw stays within over the
+// default number of slots, and that grinding overshoots with a few attempts.
+func (r *Report) Resists(scheme Scheme) bool {
+    for _, res := range r.Results {
+        if res.Scheme == scheme && res.Advantage() > 1.25 {
+            return false
+        }
+    }
+    return true
+}
+
+// Print writes the report as a table with a row per scheme and number of attempts, followed by a verdict on each
+// scheme.
+func (r *Report) Print(w io.Writer) error {
+    fmt.Fprintf(w, "%d honest validators, attacker holds %.0f%% of the stake, %d slots in epochs of %d, seed %d\n\n", r.Config.Honest, 100*r.Config.Share, r.Config.Slots, r.Config.Epoch, r.Config.Seed)
+    tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintln(tw, "scheme\tattempts\tstake\tslots won\tadvantage\t")
+    for _, res := range r.Results {
+        fmt.Fprintf(tw, "%s\t%d\t%.1f%%\t%.1f%%\t%.2fx\t\n", res.Scheme, res.Attempts, 100*res.Share, 100*res.Won, res.Advantage())
+    }
+    if err := tw.Flush(); err != nil {
+        return err
+    }
+    fmt.Fprintln(w)
+    for _, scheme := range r.Config.Schemes {
+        verdict := "is grindable"
+        if r.Resists(scheme) {
+            verdict = "resists grinding"
+        }
+        fmt.Fprintf(w, "%s %s\n", scheme, verdict)
+    }
+    return nil
+}
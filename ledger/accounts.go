@@ -0,0 +1,195 @@
+package ledger
+
+import (
+    "encoding/json"
+    "fmt"
+    "maps"
+    "strings"
+    "sync"
+)
+
+// TransferPrefix starts the data of a block that carries Transfers.
+const TransferPrefix = "transfers:"
+
+// Transfer moves an amount from one account to another, for a fee that buys its place in a block (see mempool).
+// Nonce numbers the sender's transfers from 0, so each can be applied only once and only in order: a transfer
+// that is submitted again carries a nonce the sender has already used and is rejected, which is what stops the
+// same money from being spent twice.
+type Transfer struct {
+    From   string `json:"from"`          // Account the amount is taken from.
+    To     string `json:"to"`            // Account the amount is credited to; created if it does not exist.
+    Amount uint64 `json:"amount"`        // Amount moved.
+    Nonce  uint64 `json:"nonce"`         // Number of transfers From has made before this one.
+    Fee    uint64 `json:"fee,omitempty"` // Paid by From on top of Amount for the transfer to be included; burned.
+}
+
+// EncodeTransfers returns the data of a block carrying transfers, to be submitted like any other block data.
+func EncodeTransfers(transfers ...Transfer) string {
+    data, _ := json.Marshal(transfers)
+    return TransferPrefix + string(data)
+}
+
+// DecodeTransfers returns the transfers a block's data carries: none if the data does not start with
+// TransferPrefix, and an error wrapping ErrMalformed if it does but the rest does not decode.
+func DecodeTransfers(data string) ([]Transfer, error) {
+    rest, ok := strings.CutPrefix(data, TransferPrefix)
+    if !ok {
+        return nil, nil
+    }
+    var transfers []Transfer
+    if err := json.Unmarshal([]byte(rest), &transfers); err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+    }
+    return transfers, nil
+}
+
+// Account is the state of one account.
+type Account struct {
+    Balance uint64 // Amount the account holds.
+    Nonce   uint64 // Nonce the account's next transfer must carry: the number of transfers it has made.
+}
+
+// Accounts is the account-based State: every account has a balance, and blocks carry Transfers between them or
+// operations on hash time-locked contracts (see Lock). Money is not created after genesis and only destroyed as
+// fees, so the balances, the amounts still locked and the fees paid always add up to the genesis supply.
+type Accounts struct {
+    mu       sync.Mutex
+    genesis  map[string]uint64  // Balances before the first block, which Reset returns to.
+    accounts map[string]Account // Accounts that have held money or sent a transfer.
+    locks    map[string]HTLC    // Every lock ever created, by ID.
+    height   uint64             // Number of blocks applied, which locks expire by.
+}
+
+// changes is what applying a block changes: the new state of every account and lock it touches.
+type changes struct {
+    accounts map[string]Account
+    locks    map[string]HTLC
+}
+
+// NewAccounts returns the state before the first block, with the given genesis balances.
+func NewAccounts(balances map[string]uint64) *Accounts {
+    a := &Accounts{genesis: maps.Clone(balances)}
+    a.Reset()
+    return a
+}
+
+// Account returns the named account; an account nobody has paid yet is empty.
+func (a *Accounts) Account(name string) Account {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    return a.accounts[name]
+}
+
+// Balance returns the amount the named account holds.
+func (a *Accounts) Balance(name string) uint64 {
+    return a.Account(name).Balance
+}
+
+// Check reports whether the transfers in a block carrying data could be applied: every sender holds what it
+// sends and numbers its transfers in order. Transfers in one block apply in order, so a sender may spend what an
+// earlier transfer of the same block paid it. A block carrying an HTLCOp is checked against the rules of locks
+// at the height of the next block.
+func (a *Accounts) Check(data string) error {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    _, err := a.execute(data)
+    return err
+}
+
+// Apply applies the transfers in a block carrying data, or none of them if Check fails. Every block applied,
+// whatever its data, advances the height that locks expire by.
+func (a *Accounts) Apply(data string) error {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    c, err := a.execute(data)
+    if err != nil {
+        return err
+    }
+    maps.Copy(a.accounts, c.accounts)
+    maps.Copy(a.locks, c.locks)
+    a.height++
+    return nil
+}
+
+// Reset returns every account to its genesis balance and forgets its transfers and locks.
+func (a *Accounts) Reset() {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    a.accounts = make(map[string]Account, len(a.genesis))
+    for name, balance := range a.genesis {
+        a.accounts[name] = Account{Balance: balance}
+    }
+    a.locks = make(map[string]HTLC)
+    a.height = 0
+}
+
+// Clone returns an independent copy of the accounts.
+func (a *Accounts) Clone() State {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    return &Accounts{
+        genesis:  a.genesis, // The genesis balances never change.
+        accounts: maps.Clone(a.accounts),
+        locks:    maps.Clone(a.locks),
+        height:   a.height,
+    }
+}
+
+// execute returns what a block carrying data changes, without changing a.
+func (a *Accounts) execute(data string) (changes, error) {
+    if strings.HasPrefix(data, HTLCPrefix) {
+        return a.htlc(data)
+    }
+    changed, err := a.transfer(data)
+    return changes{accounts: changed}, err
+}
+
+// transfer returns the accounts the transfers in data change, with their new state, without changing a.
+func (a *Accounts) transfer(data string) (map[string]Account, error) {
+    transfers, err := DecodeTransfers(data)
+    if err != nil {
+        return nil, err
+    }
+    changed := make(map[string]Account)
+    get := func(name string) Account {
+        if account, ok := changed[name]; ok {
+            return account
+        }
+        return a.accounts[name]
+    }
+    for i, t := range transfers {
+        from := get(t.From)
+        switch {
+        case t.Nonce < from.Nonce:
+            return nil, fmt.Errorf("transfer %d: %w: %s already sent transfer %d", i, ErrReplay, t.From, t.Nonce)
+        case t.Nonce > from.Nonce:
+            return nil, fmt.Errorf("transfer %d: %w: %s sent transfer %d, next is %d", i, ErrNonceGap, t.From, t.Nonce, from.Nonce)
+        case t.Amount > from.Balance || t.Fee > from.Balance-t.Amount:
+            return nil, fmt.Errorf("transfer %d: %w: %s holds %d and spends %d", i, ErrOverdraft, t.From, from.Balance, t.Amount+t.Fee)
+        }
+        from.Balance -= t.Amount + t.Fee
+        from.Nonce++
+        changed[t.From] = from
+        to := get(t.To) // After the sender's update, in case an account pays itself.
+        to.Balance += t.Amount
+        changed[t.To] = to
+    }
+    return changed, nil
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Nonces Against Replays**: A transfer carries its sender's nonce rather than a unique identifier, so the
+//    state needs one counter per account rather than a record of every transfer ever applied to recognize one
+//    it has seen, and a sender's transfers apply in the order it numbered them.
+//
+// 2. **Check, Then Apply**: The chains check a block before they write it to a block store and apply it only
+//    once it is appended, so a block the store refuses never changes the balances. Both compute the same set of
+//    changed accounts, which Apply copies over the state in one step; a block applies entirely or not at all.
+//
+// 3. **Fees Are Burned**: A block's data does not name its producer, so the state cannot pay it the fees of the
+//    transfers it included. Burning them, as Ethereum burns its base fee, still makes a sender pay for priority
+//    (see mempool), which is what fee markets are about; who earns the fees is left to the reward schemes.
+//
+// 4. **No Signatures**: Anyone may submit a transfer from any account. Signatures would make the examples
+//    longer without changing what they show about double spending, which the nonces alone prevent.
@@ -0,0 +1,177 @@
+package ledger
+
+import (
+    "crypto/sha256"
+    "encoding/binary"
+    "math"
+    "slices"
+    "sync"
+)
+
+// Sizes of the bloom filter of a block unless a BloomIndex is given others. Ethereum's per-block log bloom has
+// the same shape: 2048 bits, set by three hashes of each item.
+const (
+    BloomBits   = 2048
+    BloomHashes = 3
+)
+
+// maxBloomHashes is the most hashes a Bloom can use: one per four bytes of a SHA-256.
+const maxBloomHashes = sha256.Size / 4
+
+// Bloom is a bloom filter over strings: a set that can answer "definitely not" or "maybe" in a fixed number of
+// bits, however many items it holds. Adding an item sets a few bits chosen by its hash; an item whose bits are
+// not all set was never added, while one whose bits are all set may have been, or may share them by chance.
+type Bloom struct {
+    bits   []uint64
+    size   uint32 // Number of bits.
+    hashes int    // Bits set by each item.
+}
+
+// NewBloom returns an empty filter of the given number of bits, rounded up to a multiple of 64, whose items each
+// set the given number of bits. Zero selects BloomBits and BloomHashes; hashes is at most 8.
+func NewBloom(bits, hashes int) *Bloom {
+    if bits <= 0 {
+        bits = BloomBits
+    }
+    if hashes <= 0 {
+        hashes = BloomHashes
+    }
+    words := (bits + 63) / 64
+    return &Bloom{bits: make([]uint64, words), size: uint32(words * 64), hashes: min(hashes, maxBloomHashes)}
+}
+
+// positions returns the bits item sets: each taken from four bytes of its SHA-256.
+func (b *Bloom) positions(item string) []uint32 {
+    sum := sha256.Sum256([]byte(item))
+    positions := make([]uint32, b.hashes)
+    for i := range positions {
+        positions[i] = binary.BigEndian.Uint32(sum[4*i:]) % b.size
+    }
+    return positions
+}
+
+// Add puts item into the filter.
+func (b *Bloom) Add(item string) {
+    for _, p := range b.positions(item) {
+        b.bits[p/64] |= 1 << (p % 64)
+    }
+}
+
+// MayContain reports whether item may have been added. False means it certainly was not.
+func (b *Bloom) MayContain(item string) bool {
+    for _, p := range b.positions(item) {
+        if b.bits[p/64]&(1<<(p%64)) == 0 {
+            return false
+        }
+    }
+    return true
+}
+
+// FalsePositiveRate returns the chance that MayContain reports an item that was not added, once the filter holds
+// n items: (1 - e^(-kn/m))^k for m bits and k hashes.
+func (b *Bloom) FalsePositiveRate(n int) float64 {
+    return math.Pow(1-math.Exp(-float64(b.hashes*n)/float64(b.size)), float64(b.hashes))
+}
+
+// Addresses returns the accounts a block's data names as senders or recipients, in order and without duplicates:
+// both sides of every Transfer and of a new Lock, and the owners of the outputs of UTXO transactions. Claims and
+// refunds name only a lock, and UTXO inputs only an outpoint, so the accounts they touch are known only to the
+// state they are applied to; such data names no address here. Malformed data names none either.
+func Addresses(data string) []string {
+    var addresses []string
+    if transfers, _ := DecodeTransfers(data); transfers != nil {
+        for _, t := range transfers {
+            addresses = append(addresses, t.From, t.To)
+        }
+    }
+    if op, _ := DecodeHTLC(data); op != nil && op.Lock != nil {
+        addresses = append(addresses, op.Lock.From, op.Lock.To)
+    }
+    if txs, _ := DecodeTxs(data); txs != nil {
+        for _, tx := range txs {
+            for _, out := range tx.Outputs {
+                addresses = append(addresses, out.Owner)
+            }
+        }
+    }
+    var unique []string
+    for _, a := range addresses {
+        if !slices.Contains(unique, a) {
+            unique = append(unique, a)
+        }
+    }
+    return unique
+}
+
+// BlockBloom returns a filter of the given size holding the Addresses of a block's data.
+func BlockBloom(data string, bits, hashes int) *Bloom {
+    b := NewBloom(bits, hashes)
+    for _, a := range Addresses(data) {
+        b.Add(a)
+    }
+    return b
+}
+
+// BloomIndex keeps a bloom filter of the addresses of every block of a chain, so that finding the blocks that
+// touch an account checks a few bits per block instead of decoding every block. It answers which blocks might
+// touch the account; the caller decodes those few to rule out false positives. It is safe for concurrent use.
+type BloomIndex struct {
+    mu     sync.Mutex
+    bits   int
+    hashes int
+    blooms []*Bloom // Filter of each block, by index.
+}
+
+// NewBloomIndex returns an empty index whose filters have the given size; zero selects BloomBits and BloomHashes.
+func NewBloomIndex(bits, hashes int) *BloomIndex {
+    return &BloomIndex{bits: bits, hashes: hashes}
+}
+
+// Add indexes the data of the next block, whose index is Len, and returns that index.
+func (x *BloomIndex) Add(data string) int64 {
+    bloom := BlockBloom(data, x.bits, x.hashes)
+    x.mu.Lock()
+    defer x.mu.Unlock()
+    x.blooms = append(x.blooms, bloom)
+    return int64(len(x.blooms) - 1)
+}
+
+// Len returns the number of blocks indexed.
+func (x *BloomIndex) Len() int {
+    x.mu.Lock()
+    defer x.mu.Unlock()
+    return len(x.blooms)
+}
+
+// Query returns the indexes of the blocks that might touch address, in ascending order. Every block that does
+// touch it is included; a few that do not may be too.
+func (x *BloomIndex) Query(address string) []int64 {
+    x.mu.Lock()
+    defer x.mu.Unlock()
+    var candidates []int64
+    for i, bloom := range x.blooms {
+        if bloom.MayContain(address) {
+            candidates = append(candidates, int64(i))
+        }
+    }
+    return candidates
+}
+
+// Touches reports whether a block's data names address, which rules out the false positives of Query.
+func Touches(data, address string) bool {
+    return slices.Contains(Addresses(data), address)
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Per Block, Fixed Size**: Each block gets its own filter of the same size, as in Ethereum's block headers,
+//    so a node can index a chain as it grows and a light client could receive the filters with the headers.
+//    A full filter only answers "maybe" more often; it never misses a block.
+//
+// 2. **Candidates, Then Confirmation**: Query returns candidates and Touches confirms them by decoding the
+//    block. Splitting the two makes the probabilistic step visible: the difference between the candidates and
+//    the confirmed blocks is exactly the false positives.
+//
+// 3. **Addresses From the Data Alone**: A filter is built from what a block's data names, without a state to
+//    resolve lock IDs or outpoints to accounts. The index therefore does not depend on which State, if any, a
+//    chain applies its blocks to.
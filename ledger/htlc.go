@@ -0,0 +1,202 @@
+package ledger
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "strings"
+)
+
+// HTLCPrefix starts the data of a block that carries a hash time-locked contract operation.
+const HTLCPrefix = "htlc:"
+
+// Lock is a hash time-locked contract: an amount taken from one account and held until either the account it is
+// for claims it by revealing the secret whose hash locks it, before the lock expires, or the account it came from
+// takes it back once the lock has expired. Expiry is a block height, which every node agrees on, rather than a
+// time, which no two nodes read alike.
+type Lock struct {
+    ID     string `json:"id"`     // Names the lock, which claims and refunds refer to; never reused.
+    From   string `json:"from"`   // Account the amount is taken from, and returned to by a refund.
+    To     string `json:"to"`     // Account a claim pays.
+    Amount uint64 `json:"amount"` // Amount locked.
+    Hash   string `json:"hash"`   // HashLock of the secret that claims the amount.
+    Expiry uint64 `json:"expiry"` // Height of the first block that can no longer claim the amount, and can refund it.
+}
+
+// LockStatus is where a Lock stands.
+type LockStatus int
+
+const (
+    Locked   LockStatus = iota // Holding the amount.
+    Claimed                    // Paid to To, which revealed the secret.
+    Refunded                   // Returned to From after expiring.
+)
+
+// String returns the status in lower case.
+func (s LockStatus) String() string {
+    switch s {
+    case Locked:
+        return "locked"
+    case Claimed:
+        return "claimed"
+    case Refunded:
+        return "refunded"
+    }
+    return fmt.Sprintf("LockStatus(%d)", int(s))
+}
+
+// HTLC is a Lock and where it stands.
+type HTLC struct {
+    Lock
+    Status LockStatus
+    Secret string // Secret the claim revealed, once Claimed.
+}
+
+// HTLCOp is a block's hash time-locked contract operation: it creates a Lock, claims one or refunds one.
+type HTLCOp struct {
+    Lock   *Lock  `json:"lock,omitempty"`   // Lock to create.
+    Claim  string `json:"claim,omitempty"`  // ID of the lock to claim with Secret.
+    Secret string `json:"secret,omitempty"` // Secret that claims the lock.
+    Refund string `json:"refund,omitempty"` // ID of the lock to refund.
+}
+
+// HashLock returns the hash that locks an amount to secret: its SHA-256, in hex.
+func HashLock(secret string) string {
+    sum := sha256.Sum256([]byte(secret))
+    return hex.EncodeToString(sum[:])
+}
+
+// EncodeLock returns the data of a block that creates l.
+func EncodeLock(l Lock) string {
+    return encodeHTLC(HTLCOp{Lock: &l})
+}
+
+// EncodeClaim returns the data of a block that claims the lock named id by revealing secret. Once the block is
+// committed, the secret is public to anyone who reads the chain.
+func EncodeClaim(id, secret string) string {
+    return encodeHTLC(HTLCOp{Claim: id, Secret: secret})
+}
+
+// EncodeRefund returns the data of a block that returns the amount of the expired lock named id to its sender.
+func EncodeRefund(id string) string {
+    return encodeHTLC(HTLCOp{Refund: id})
+}
+
+// encodeHTLC returns the data of a block that carries op.
+func encodeHTLC(op HTLCOp) string {
+    data, _ := json.Marshal(op)
+    return HTLCPrefix + string(data)
+}
+
+// DecodeHTLC returns the operation a block's data carries: nil if the data does not start with HTLCPrefix, and an
+// error wrapping ErrMalformed if it does but the rest does not decode as exactly one operation.
+func DecodeHTLC(data string) (*HTLCOp, error) {
+    rest, ok := strings.CutPrefix(data, HTLCPrefix)
+    if !ok {
+        return nil, nil
+    }
+    var op HTLCOp
+    if err := json.Unmarshal([]byte(rest), &op); err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+    }
+    ops := 0
+    for _, set := range []bool{op.Lock != nil, op.Claim != "", op.Refund != ""} {
+        if set {
+            ops++
+        }
+    }
+    if ops != 1 {
+        return nil, fmt.Errorf("%w: %d operations in one block", ErrMalformed, ops)
+    }
+    return &op, nil
+}
+
+// HTLC returns the lock named id and where it stands, if it was ever created.
+func (a *Accounts) HTLC(id string) (HTLC, bool) {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    h, ok := a.locks[id]
+    return h, ok
+}
+
+// Height returns the number of blocks applied, which is the height of the next block.
+func (a *Accounts) Height() uint64 {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    return a.height
+}
+
+// htlc returns what the operation in data changes, without changing a.
+func (a *Accounts) htlc(data string) (changes, error) {
+    op, err := DecodeHTLC(data)
+    if err != nil {
+        return changes{}, err
+    }
+    var h HTLC
+    var pay string // Account the amount goes to.
+    switch {
+    case op.Lock != nil:
+        l := *op.Lock
+        from := a.accounts[l.From]
+        switch _, exists := a.locks[l.ID]; {
+        case exists:
+            return changes{}, fmt.Errorf("%w: lock %s already exists", ErrReplay, l.ID)
+        case len(l.Hash) != sha256.Size*2 || strings.Trim(l.Hash, "0123456789abcdef") != "":
+            return changes{}, fmt.Errorf("%w: lock %s: hash is not a hex SHA-256", ErrMalformed, l.ID)
+        case l.Expiry <= a.height:
+            return changes{}, fmt.Errorf("%w: lock %s expires at %d, before block %d", ErrExpired, l.ID, l.Expiry, a.height)
+        case l.Amount > from.Balance:
+            return changes{}, fmt.Errorf("%w: %s holds %d and locks %d", ErrOverdraft, l.From, from.Balance, l.Amount)
+        }
+        from.Balance -= l.Amount
+        return changes{accounts: map[string]Account{l.From: from}, locks: map[string]HTLC{l.ID: {Lock: l}}}, nil
+    case op.Claim != "":
+        if h, err = a.settle(op.Claim); err != nil {
+            return changes{}, err
+        }
+        switch {
+        case a.height >= h.Expiry:
+            return changes{}, fmt.Errorf("%w: lock %s expired at %d", ErrExpired, h.ID, h.Expiry)
+        case HashLock(op.Secret) != h.Hash:
+            return changes{}, fmt.Errorf("%w: lock %s", ErrWrongSecret, h.ID)
+        }
+        h.Status, h.Secret, pay = Claimed, op.Secret, h.To
+    default:
+        if h, err = a.settle(op.Refund); err != nil {
+            return changes{}, err
+        }
+        if a.height < h.Expiry {
+            return changes{}, fmt.Errorf("%w: lock %s expires at %d, not %d", ErrNotExpired, h.ID, h.Expiry, a.height)
+        }
+        h.Status, pay = Refunded, h.From
+    }
+    account := a.accounts[pay]
+    account.Balance += h.Amount
+    return changes{accounts: map[string]Account{pay: account}, locks: map[string]HTLC{h.ID: h}}, nil
+}
+
+// settle returns the lock named id if it still holds its amount.
+func (a *Accounts) settle(id string) (HTLC, error) {
+    h, ok := a.locks[id]
+    switch {
+    case !ok:
+        return HTLC{}, fmt.Errorf("%w: %s", ErrNoLock, id)
+    case h.Status != Locked:
+        return HTLC{}, fmt.Errorf("%w: lock %s was %s", ErrSettled, id, h.Status)
+    }
+    return h, nil
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Heights, Not Clocks**: A lock expires at a block height. Every node agrees on which block a claim or a
+//    refund is in, and so on whether it came in time; nodes reading their own clocks could disagree and apply
+//    the same block differently.
+//
+// 2. **Part of Accounts**: Locks take money from account balances and pay it back into them, so they are a kind
+//    of transaction Accounts understands rather than a State of their own, and a chain with locks still carries
+//    ordinary transfers.
+//
+// 3. **One Operation per Block**: Each HTLC block creates, claims or refunds one lock. An atomic swap needs no
+//    more, and whether a claim beat the expiry is then simply a question of which block it is in.
@@ -0,0 +1,70 @@
+// Package ledger gives the data in blocks a meaning. The simulations treat a block's data as an opaque string,
+// so any block that extends the chain is valid; a real chain also rejects blocks whose transactions break the
+// rules of its state, such as spending money an account does not have or spending the same money twice. A State
+// holds that state and decides which blocks break its rules, and the Proof of Work, Proof of Stake and PBFT
+// chains consult one, once attached, before they append a block.
+//
+// Transactions travel in a block's data, encoded by the State that understands them behind a prefix of its own,
+// so the plain strings the simulations submit stay valid blocks that no State interprets.
+package ledger
+
+import (
+    "errors"
+    "fmt"
+)
+
+var (
+    // ErrInvalid is wrapped by every error reporting a transaction that breaks a State's rules, so a chain can
+    // tell a rejected block from a failure such as a broken block store.
+    ErrInvalid = errors.New("ledger: invalid transaction")
+
+    // ErrMalformed is returned for block data that carries the prefix of a State's transactions but does not
+    // decode as them.
+    ErrMalformed = fmt.Errorf("%w: malformed transactions", ErrInvalid)
+
+    // ErrOverdraft is returned for a transaction that spends more than its sender holds, or that creates more
+    // than the outputs it spends.
+    ErrOverdraft = fmt.Errorf("%w: insufficient balance", ErrInvalid)
+
+    // ErrReplay is returned for a transaction whose nonce its sender has already used: a transaction that was
+    // already applied, submitted again.
+    ErrReplay = fmt.Errorf("%w: nonce already used", ErrInvalid)
+
+    // ErrNonceGap is returned for a transaction whose nonce skips some of its sender's earlier nonces.
+    ErrNonceGap = fmt.Errorf("%w: nonce out of order", ErrInvalid)
+
+    // ErrSpent is returned for a transaction that spends an output which is not unspent: one that an earlier
+    // transaction already spent, or one that never existed.
+    ErrSpent = fmt.Errorf("%w: output already spent or unknown", ErrInvalid)
+
+    // ErrNoLock is returned for a claim or refund of a hash time-locked contract that was never created.
+    ErrNoLock = fmt.Errorf("%w: no such lock", ErrInvalid)
+
+    // ErrSettled is returned for a claim or refund of a lock that was already claimed or refunded.
+    ErrSettled = fmt.Errorf("%w: lock already settled", ErrInvalid)
+
+    // ErrExpired is returned for a claim of a lock that has expired, or a lock that expires before its block.
+    ErrExpired = fmt.Errorf("%w: lock expired", ErrInvalid)
+
+    // ErrNotExpired is returned for a refund of a lock that can still be claimed.
+    ErrNotExpired = fmt.Errorf("%w: lock not yet expired", ErrInvalid)
+
+    // ErrWrongSecret is returned for a claim whose secret does not hash to the lock's hash.
+    ErrWrongSecret = fmt.Errorf("%w: secret does not match the lock", ErrInvalid)
+)
+
+// State is the state a chain's blocks update. A chain calls Check before it appends a block, refusing the
+// block if it fails, and Apply once the block is appended. Implementations are safe for concurrent use.
+type State interface {
+    Check(data string) error // Report why a block carrying data would break the rules, or nil if it would not.
+    Apply(data string) error // Apply the transactions of a block carrying data: all of them, or none and an error.
+    Reset()                  // Return to the state before the first block, to replay a chain that replaced another.
+    Clone() State            // Return an independent copy, for a cloned chain.
+}
+
+// Replay resets s and applies the data of every block of a chain to it in order. It returns an error wrapping
+// ErrInvalid, with the index of the first block that breaks the rules, if the chain could never have been built.
+func Replay(s State, data []string) error {
+    _, err := ReplayRoots(s, data, nil)
+    return err
+}
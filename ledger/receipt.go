@@ -0,0 +1,288 @@
+package ledger
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "slices"
+    "strconv"
+    "strings"
+
+    "consensus-algorithms-edu/trie"
+    "consensus-algorithms-edu/wire"
+)
+
+// ErrReceiptsRoot is returned for a block whose header commits to a receipts root other than the root of the
+// receipts its transactions produce.
+var ErrReceiptsRoot = fmt.Errorf("%w: receipts root mismatch", ErrInvalid)
+
+// Status is whether a transaction was executed.
+type Status int
+
+const (
+    Succeeded Status = iota // Executed: its changes are part of the state.
+    Failed                  // Broke the rules, which makes its block invalid; see Receipt.Error.
+)
+
+// String returns the status in lower case.
+func (s Status) String() string {
+    switch s {
+    case Succeeded:
+        return "succeeded"
+    case Failed:
+        return "failed"
+    }
+    return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// MarshalText writes the status as String does, so receipts read well as JSON.
+func (s Status) MarshalText() ([]byte, error) {
+    return []byte(s.String()), nil
+}
+
+// UnmarshalText reads a status written by MarshalText.
+func (s *Status) UnmarshalText(text []byte) error {
+    switch string(text) {
+    case "succeeded":
+        *s = Succeeded
+    case "failed":
+        *s = Failed
+    default:
+        return fmt.Errorf("ledger: unknown status %q", text)
+    }
+    return nil
+}
+
+// Change is one entry of the state a transaction changed, such as an account or an output, as it was before and
+// after. An entry that did not exist before, or no longer exists after, is written as "".
+type Change struct {
+    Key    string `json:"key"`    // Entry that changed, e.g. "account alice" or "output genesis:0".
+    Before string `json:"before"` // The entry before the transaction.
+    After  string `json:"after"`  // The entry after it.
+}
+
+// Receipt records what executing one transaction of a block did. The receipts of a block are committed to by
+// the receipts root in its header, so a client that trusts the header can trust what a receipt reports.
+type Receipt struct {
+    Tx      string   `json:"tx"`               // TxHash of the transaction.
+    Index   int      `json:"index"`            // Position of the transaction in its block.
+    Status  Status   `json:"status"`           // Whether it was executed.
+    Error   string   `json:"error,omitempty"`  // Why it failed, if it did.
+    Changes []Change `json:"changes"`          // What it changed, ordered by key.
+    Events  []string `json:"events,omitempty"` // What happened, in words, e.g. "alice paid bob 30".
+    Block   int64    `json:"-"`                // Index of the block that carried it, set by the chain; not committed to.
+}
+
+// Executor is implemented by States that can report what each transaction of a block does. A chain whose
+// attached state is an Executor keeps the receipts of the blocks it appends, and commits to them in each header.
+type Executor interface {
+    State
+    Execute(data string) ([]Receipt, error) // Return the receipts of a block carrying data, without applying it.
+}
+
+// TxHash returns the hash that names a transaction: the SHA-256 of its JSON encoding, in hex. For a UTXO Tx it
+// is the transaction's ID.
+func TxHash(tx any) string {
+    data, _ := json.Marshal(tx)
+    sum := sha256.Sum256(data)
+    return hex.EncodeToString(sum[:])
+}
+
+// Execute returns the receipts of the transactions of a block carrying data, without changing s: nil if s is not
+// an Executor or the data carries no transactions. For data that breaks the rules, it returns the receipts up to
+// and including the first transaction that fails, with the error Check would.
+func Execute(s State, data string) ([]Receipt, error) {
+    if e, ok := s.(Executor); ok {
+        return e.Execute(data)
+    }
+    return nil, s.Check(data)
+}
+
+// ReceiptsRoot returns the root of a trie holding receipts by their index, as Ethereum's receipts trie does, or
+// the zero Hash if there are none.
+func ReceiptsRoot(receipts []Receipt) wire.Hash {
+    if len(receipts) == 0 {
+        return wire.Hash{}
+    }
+    t := trie.New()
+    for _, r := range receipts {
+        data, _ := json.Marshal(r)
+        t.Put([]byte(strconv.Itoa(r.Index)), data)
+    }
+    return t.Root()
+}
+
+// ExecuteEach builds the receipts of a block's transactions, named by hashes, for a State that executes a block
+// as a whole. execute(k) executes the first k transactions, as the state's Check would, and returns every entry
+// they leave changed, formatted; an entry missing from it is as original returns. The changes of transaction i
+// are the entries execute(i+1) formats differently from execute(i). describe returns the events of transaction i.
+//
+// Executing every prefix costs time in the square of the number of transactions, which keeps the state's rules
+// in one place: a receipt reports exactly what Apply does.
+func ExecuteEach(hashes []string, original func(key string) string, execute func(k int) (map[string]string, error), describe func(i int) []string) ([]Receipt, error) {
+    var receipts []Receipt
+    before := map[string]string{}
+    value := func(entries map[string]string, key string) string {
+        if v, ok := entries[key]; ok {
+            return v
+        }
+        return original(key)
+    }
+    for i, tx := range hashes {
+        after, err := execute(i + 1)
+        if err != nil {
+            receipts = append(receipts, Receipt{Tx: tx, Index: i, Status: Failed, Error: err.Error()})
+            return receipts, err
+        }
+        r := Receipt{Tx: tx, Index: i, Status: Succeeded, Changes: []Change{}, Events: describe(i)}
+        var keys []string
+        for key := range after {
+            keys = append(keys, key)
+        }
+        for key := range before {
+            if _, ok := after[key]; !ok {
+                keys = append(keys, key)
+            }
+        }
+        slices.Sort(keys)
+        for _, key := range keys {
+            if b, a := value(before, key), value(after, key); b != a {
+                r.Changes = append(r.Changes, Change{Key: key, Before: b, After: a})
+            }
+        }
+        receipts = append(receipts, r)
+        before = after
+    }
+    return receipts, nil
+}
+
+// formatAccount formats an account for a Change; an empty account is "".
+func formatAccount(a Account) string {
+    if a == (Account{}) {
+        return ""
+    }
+    return fmt.Sprintf("balance %d, nonce %d", a.Balance, a.Nonce)
+}
+
+// formatLock formats a lock for a Change.
+func formatLock(h HTLC) string {
+    return fmt.Sprintf("%d from %s to %s, %s", h.Amount, h.From, h.To, h.Status)
+}
+
+// Execute returns a receipt for each transfer of a block carrying data, or a single one for its lock operation.
+func (a *Accounts) Execute(data string) ([]Receipt, error) {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    original := func(key string) string {
+        if id, ok := strings.CutPrefix(key, "lock "); ok {
+            if h, ok := a.locks[id]; ok {
+                return formatLock(h)
+            }
+            return ""
+        }
+        return formatAccount(a.accounts[strings.TrimPrefix(key, "account ")])
+    }
+    entries := func(c changes) map[string]string {
+        m := make(map[string]string, len(c.accounts)+len(c.locks))
+        for name, account := range c.accounts {
+            m["account "+name] = formatAccount(account)
+        }
+        for id, h := range c.locks {
+            m["lock "+id] = formatLock(h)
+        }
+        return m
+    }
+
+    if strings.HasPrefix(data, HTLCPrefix) {
+        op, err := DecodeHTLC(data)
+        if err != nil {
+            return nil, err
+        }
+        return ExecuteEach([]string{TxHash(op)}, original, func(int) (map[string]string, error) {
+            c, err := a.htlc(data)
+            return entries(c), err
+        }, func(int) []string {
+            switch {
+            case op.Lock != nil:
+                return []string{fmt.Sprintf("%s locked %d for %s as %s", op.Lock.From, op.Lock.Amount, op.Lock.To, op.Lock.ID)}
+            case op.Claim != "":
+                return []string{fmt.Sprintf("claimed %s", op.Claim)}
+            }
+            return []string{fmt.Sprintf("refunded %s", op.Refund)}
+        })
+    }
+
+    transfers, err := DecodeTransfers(data)
+    if err != nil || transfers == nil {
+        return nil, err
+    }
+    hashes := make([]string, len(transfers))
+    for i, t := range transfers {
+        hashes[i] = TxHash(t)
+    }
+    return ExecuteEach(hashes, original, func(k int) (map[string]string, error) {
+        changed, err := a.transfer(EncodeTransfers(transfers[:k]...))
+        return entries(changes{accounts: changed}), err
+    }, func(i int) []string {
+        t := transfers[i]
+        if t.Fee > 0 {
+            return []string{fmt.Sprintf("%s paid %s %d with a fee of %d", t.From, t.To, t.Amount, t.Fee)}
+        }
+        return []string{fmt.Sprintf("%s paid %s %d", t.From, t.To, t.Amount)}
+    })
+}
+
+// formatOutput formats an output for a Change.
+func formatOutput(out Output) string {
+    return fmt.Sprintf("%d to %s", out.Amount, out.Owner)
+}
+
+// Execute returns a receipt for each transaction of a block carrying data. A transaction's changes are the
+// outputs it spends, which no longer exist after it, and those it creates.
+func (u *UTXOSet) Execute(data string) ([]Receipt, error) {
+    u.mu.Lock()
+    defer u.mu.Unlock()
+    txs, err := DecodeTxs(data)
+    if err != nil || txs == nil {
+        return nil, err
+    }
+    original := func(key string) string {
+        tx, index, _ := strings.Cut(strings.TrimPrefix(key, "output "), ":")
+        i, _ := strconv.Atoi(index)
+        if out, ok := u.unspent[OutPoint{Tx: tx, Index: i}]; ok {
+            return formatOutput(out)
+        }
+        return ""
+    }
+    hashes := make([]string, len(txs))
+    for i, tx := range txs {
+        hashes[i] = tx.ID()
+    }
+    return ExecuteEach(hashes, original, func(k int) (map[string]string, error) {
+        spent, created, err := u.spend(EncodeTxs(txs[:k]...))
+        m := make(map[string]string, len(spent)+len(created))
+        for _, op := range spent {
+            m["output "+op.Tx+":"+strconv.Itoa(op.Index)] = ""
+        }
+        for op, out := range created {
+            m["output "+op.Tx+":"+strconv.Itoa(op.Index)] = formatOutput(out)
+        }
+        return m, err
+    }, func(i int) []string {
+        return []string{fmt.Sprintf("spent %d outputs and created %d", len(txs[i].Inputs), len(txs[i].Outputs))}
+    })
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Prefixes, Not a Second Interpreter**: A receipt is the difference between executing a block's first i
+//    transactions and its first i+1, with the same code Check and Apply use. A state that recorded receipts as it
+//    went would have to keep a second account of its rules in step with the first.
+//
+// 2. **Failed Receipts Are Never Committed**: A block applies entirely or not at all, so every receipt of a
+//    committed block succeeded. Execute still reports the transaction that failed, which is what a client
+//    asking why its block was refused wants to know.
+//
+// 3. **Block Outside the Root**: The receipts root is computed before the block is hashed, so a receipt cannot
+//    name its block's hash; the chain records the block index alongside it when it indexes the receipt.
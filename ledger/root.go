@@ -0,0 +1,169 @@
+package ledger
+
+import (
+    "crypto/sha256"
+    "encoding/json"
+    "fmt"
+    "strconv"
+
+    "consensus-algorithms-edu/trie"
+    "consensus-algorithms-edu/wire"
+)
+
+// ErrStateRoot is returned for a block whose header commits to a state root other than the one its transactions
+// leave the state at.
+var ErrStateRoot = fmt.Errorf("%w: state root mismatch", ErrInvalid)
+
+// Rooted is implemented by States that commit to their contents with the root of a trie.Trie. A chain whose
+// attached state is Rooted puts the root the state has after each block in that block's header, so the header
+// commits to the whole state as well as to the block's data.
+type Rooted interface {
+    State
+    Root() wire.Hash // Return the root of the state's trie: it changes whenever an account, lock or output does.
+}
+
+// Roots are what a block header commits to about the state its block leaves: the root of the state, if it is
+// Rooted, and the ReceiptsRoot of the block's transactions, if it is an Executor. A zero root commits to nothing.
+type Roots struct {
+    State    wire.Hash
+    Receipts wire.Hash
+}
+
+// RootsAfter returns the roots of a block carrying data, with the receipts they commit to, without changing s.
+// It returns the error Apply would for data that breaks the rules.
+func RootsAfter(s State, data string) (Roots, []Receipt, error) {
+    receipts, err := Execute(s, data)
+    if err != nil {
+        return Roots{}, nil, err
+    }
+    roots := Roots{Receipts: ReceiptsRoot(receipts)}
+    if _, ok := s.(Rooted); ok {
+        next := s.Clone()
+        if err := next.Apply(data); err != nil {
+            return Roots{}, nil, err
+        }
+        roots.State = next.(Rooted).Root()
+    }
+    return roots, receipts, nil
+}
+
+// CheckRoots is Check for a block that commits to roots: it also returns an error wrapping ErrStateRoot or
+// ErrReceiptsRoot if either root differs from the one the block leads to. Zero roots, as in a block appended
+// before any state was attached, are not compared. It returns the receipts of the block's transactions.
+func CheckRoots(s State, data string, roots Roots) ([]Receipt, error) {
+    after, receipts, err := RootsAfter(s, data)
+    switch {
+    case err != nil:
+        return nil, err
+    case !roots.State.IsZero() && after.State != roots.State:
+        return nil, fmt.Errorf("%w: block commits to %.12s, state would be at %.12s", ErrStateRoot, roots.State, after.State)
+    case !roots.Receipts.IsZero() && after.Receipts != roots.Receipts:
+        return nil, fmt.Errorf("%w: block commits to %.12s, receipts are at %.12s", ErrReceiptsRoot, roots.Receipts, after.Receipts)
+    }
+    return receipts, nil
+}
+
+// ReplayRoots is Replay for blocks that commit to roots, roots[i] being those of block i: it also checks each
+// block's non-zero roots with CheckRoots, and returns the receipts of every block, by index. A nil roots checks
+// nothing.
+func ReplayRoots(s State, data []string, roots []Roots) ([][]Receipt, error) {
+    s.Reset()
+    receipts := make([][]Receipt, len(data))
+    for i, d := range data {
+        var want Roots
+        if i < len(roots) {
+            want = roots[i]
+        }
+        r, err := CheckRoots(s, d, want)
+        if err == nil {
+            err = s.Apply(d)
+        }
+        if err != nil {
+            return nil, fmt.Errorf("block %d: %w", i, err)
+        }
+        receipts[i] = r
+    }
+    return receipts, nil
+}
+
+// stateKey returns the key of an entry of a state's trie: the SHA-256 of its kind and name, as Ethereum hashes
+// addresses, so that keys are spread evenly and all have the same length.
+func stateKey(kind, name string) []byte {
+    sum := sha256.Sum256([]byte(kind + ":" + name))
+    return sum[:]
+}
+
+// put adds an entry to a state's trie, its value encoded as JSON.
+func put(t *trie.Trie, kind, name string, value any) {
+    data, _ := json.Marshal(value)
+    t.Put(stateKey(kind, name), data)
+}
+
+// trie returns a trie of every account and lock. The caller must hold a.mu.
+func (a *Accounts) trie() *trie.Trie {
+    t := trie.New()
+    for name, account := range a.accounts {
+        put(t, "account", name, account)
+    }
+    for id, lock := range a.locks {
+        put(t, "lock", id, lock)
+    }
+    return t
+}
+
+// Root returns the root of a trie holding every account and every lock, which a chain commits to in the header
+// of each block.
+func (a *Accounts) Root() wire.Hash {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    return a.trie().Root()
+}
+
+// Prove returns the named account together with a proof that it is the account under Root, which VerifyAccount
+// checks. For an account nobody has paid yet, the proof shows that the trie holds no such account.
+func (a *Accounts) Prove(name string) (Account, trie.Proof) {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    return a.accounts[name], a.trie().Prove(stateKey("account", name))
+}
+
+// VerifyAccount checks a proof returned by Accounts.Prove against a state root, such as the one in a block
+// header, and returns the account it proves: an empty one if it proves that the account does not exist. A proof
+// that does not match root returns an error wrapping trie.ErrBadProof.
+func VerifyAccount(root wire.Hash, name string, proof trie.Proof) (Account, error) {
+    value, ok, err := trie.VerifyProof(root, stateKey("account", name), proof)
+    if err != nil || !ok {
+        return Account{}, err
+    }
+    var account Account
+    if err := json.Unmarshal(value, &account); err != nil {
+        return Account{}, fmt.Errorf("%w: account %q: %w", trie.ErrBadProof, name, err)
+    }
+    return account, nil
+}
+
+// Root returns the root of a trie holding every unspent output, which a chain commits to in the header of each
+// block. Spending an output removes it from the trie.
+func (u *UTXOSet) Root() wire.Hash {
+    u.mu.Lock()
+    defer u.mu.Unlock()
+    t := trie.New()
+    for op, out := range u.unspent {
+        put(t, "utxo", op.Tx+":"+strconv.Itoa(op.Index), out)
+    }
+    return t.Root()
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Roots From Clones**: A block's state root is the root of its state after the block, so the producer
+//    computes it on a clone before the block exists, and the block's hash covers it. Every node that applies
+//    the block reaches the same root or rejects the block with ErrStateRoot: the header is a commitment to the
+//    state, not a claim about it.
+//
+// 2. **Hashed Keys**: Accounts, locks and outputs share one trie, keyed by the hash of their kind and name. The
+//    kind keeps an account and a lock of the same name apart; hashing gives every key the same length, so no
+//    key is a prefix of another.
+//
+// 3. **Height Not Committed**: The number of blocks applied, which locks expire by, is not in the trie: it is the
+//    index the header already carries.
@@ -0,0 +1,233 @@
+package ledger
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "maps"
+    "slices"
+    "strings"
+    "sync"
+)
+
+// TxPrefix starts the data of a block that carries UTXO transactions.
+const TxPrefix = "utxo:"
+
+// GenesisTx is the transaction identifier of the genesis outputs a UTXOSet starts with.
+const GenesisTx = "genesis"
+
+// OutPoint names an output: the transaction that created it and its position among that transaction's outputs.
+type OutPoint struct {
+    Tx    string `json:"tx"`    // ID of the transaction, or GenesisTx.
+    Index int    `json:"index"` // Position of the output in the transaction's outputs.
+}
+
+// String formats the outpoint as the transaction's ID and the output's index, e.g. "genesis:0".
+func (op OutPoint) String() string {
+    return fmt.Sprintf("%.16s:%d", op.Tx, op.Index)
+}
+
+// Output is an amount that belongs to an owner until a transaction spends it.
+type Output struct {
+    Owner  string `json:"owner"`  // Who the amount belongs to.
+    Amount uint64 `json:"amount"` // Amount the output holds.
+}
+
+// Tx is a UTXO transaction: it spends whole outputs and creates new ones. The amounts it creates may not add up to
+// more than those it spends; what they leave over is a fee, which nobody collects in this model.
+type Tx struct {
+    Inputs  []OutPoint `json:"inputs"`  // Unspent outputs the transaction spends, each in full.
+    Outputs []Output   `json:"outputs"` // Outputs the transaction creates.
+}
+
+// ID returns the transaction's identifier, the hex SHA-256 of its encoding, which its outputs are named by.
+// Two valid transactions never share an ID, since no output can be spent by both.
+func (tx Tx) ID() string {
+    data, _ := json.Marshal(tx)
+    sum := sha256.Sum256(data)
+    return hex.EncodeToString(sum[:])
+}
+
+// Out returns the outpoint that names the transaction's i-th output.
+func (tx Tx) Out(i int) OutPoint {
+    return OutPoint{Tx: tx.ID(), Index: i}
+}
+
+// EncodeTxs returns the data of a block carrying txs, to be submitted like any other block data.
+func EncodeTxs(txs ...Tx) string {
+    data, _ := json.Marshal(txs)
+    return TxPrefix + string(data)
+}
+
+// DecodeTxs returns the transactions a block's data carries: none if the data does not start with TxPrefix, and
+// an error wrapping ErrMalformed if it does but the rest does not decode.
+func DecodeTxs(data string) ([]Tx, error) {
+    rest, ok := strings.CutPrefix(data, TxPrefix)
+    if !ok {
+        return nil, nil
+    }
+    var txs []Tx
+    if err := json.Unmarshal([]byte(rest), &txs); err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+    }
+    return txs, nil
+}
+
+// UTXOSet is the UTXO-based State: money exists only as unspent outputs, and blocks carry Txs that spend some
+// and create others. Where Accounts asks whether a sender's balance covers a transfer, a UTXOSet asks whether
+// every output a transaction spends is still unspent, which is all it takes to stop a double spend: there is no
+// nonce, because an output can be spent only once.
+type UTXOSet struct {
+    mu      sync.Mutex
+    genesis []Output            // Outputs before the first block, named GenesisTx:0, GenesisTx:1 and so on.
+    unspent map[OutPoint]Output // Outputs created and not yet spent.
+}
+
+// NewUTXOSet returns the state before the first block, holding the genesis outputs.
+func NewUTXOSet(genesis ...Output) *UTXOSet {
+    u := &UTXOSet{genesis: slices.Clone(genesis)}
+    u.Reset()
+    return u
+}
+
+// Output returns the unspent output op names, if it is unspent.
+func (u *UTXOSet) Output(op OutPoint) (Output, bool) {
+    u.mu.Lock()
+    defer u.mu.Unlock()
+    out, ok := u.unspent[op]
+    return out, ok
+}
+
+// Unspent returns the outputs the owner can spend, in a stable order.
+func (u *UTXOSet) Unspent(owner string) []OutPoint {
+    u.mu.Lock()
+    defer u.mu.Unlock()
+    var ops []OutPoint
+    for op, out := range u.unspent {
+        if out.Owner == owner {
+            ops = append(ops, op)
+        }
+    }
+    slices.SortFunc(ops, func(a, b OutPoint) int {
+        if c := strings.Compare(a.Tx, b.Tx); c != 0 {
+            return c
+        }
+        return a.Index - b.Index
+    })
+    return ops
+}
+
+// Balance returns the total of the owner's unspent outputs, which is all an account balance is in this model.
+func (u *UTXOSet) Balance(owner string) uint64 {
+    u.mu.Lock()
+    defer u.mu.Unlock()
+    var total uint64
+    for _, out := range u.unspent {
+        if out.Owner == owner {
+            total += out.Amount
+        }
+    }
+    return total
+}
+
+// Check reports whether the transactions in a block carrying data could be applied: every input is unspent
+// and spent once, and no transaction creates more than it spends. Transactions in one block apply in order, so
+// one may spend the outputs of an earlier one.
+func (u *UTXOSet) Check(data string) error {
+    u.mu.Lock()
+    defer u.mu.Unlock()
+    _, _, err := u.spend(data)
+    return err
+}
+
+// Apply applies the transactions in a block carrying data, or none of them if Check fails.
+func (u *UTXOSet) Apply(data string) error {
+    u.mu.Lock()
+    defer u.mu.Unlock()
+    spent, created, err := u.spend(data)
+    if err != nil {
+        return err
+    }
+    for _, op := range spent {
+        delete(u.unspent, op)
+    }
+    maps.Copy(u.unspent, created)
+    return nil
+}
+
+// Reset returns to the genesis outputs.
+func (u *UTXOSet) Reset() {
+    u.mu.Lock()
+    defer u.mu.Unlock()
+    u.unspent = make(map[OutPoint]Output, len(u.genesis))
+    for i, out := range u.genesis {
+        u.unspent[OutPoint{Tx: GenesisTx, Index: i}] = out
+    }
+}
+
+// Clone returns an independent copy of the set.
+func (u *UTXOSet) Clone() State {
+    u.mu.Lock()
+    defer u.mu.Unlock()
+    return &UTXOSet{genesis: u.genesis, unspent: maps.Clone(u.unspent)} // The genesis outputs never change.
+}
+
+// spend returns the outputs the transactions in data spend, from the set as it stood before the block, and
+// the outputs they create that are still unspent at its end, without changing u.
+func (u *UTXOSet) spend(data string) (spent []OutPoint, created map[OutPoint]Output, err error) {
+    txs, err := DecodeTxs(data)
+    if err != nil {
+        return nil, nil, err
+    }
+    created = make(map[OutPoint]Output)
+    used := make(map[OutPoint]bool) // Outputs spent earlier in the block.
+    for i, tx := range txs {
+        if len(tx.Inputs) == 0 {
+            return nil, nil, fmt.Errorf("tx %d: %w: no inputs; money is only created at genesis", i, ErrMalformed)
+        }
+        var in, out uint64
+        for _, op := range tx.Inputs {
+            prev, ok := created[op]
+            if !ok && !used[op] {
+                prev, ok = u.unspent[op]
+            }
+            if !ok {
+                return nil, nil, fmt.Errorf("tx %d: %w: %s", i, ErrSpent, op)
+            }
+            if _, inBlock := created[op]; inBlock {
+                delete(created, op)
+            } else {
+                spent = append(spent, op)
+            }
+            used[op] = true
+            in += prev.Amount
+        }
+        for _, o := range tx.Outputs {
+            if out+o.Amount < out {
+                return nil, nil, fmt.Errorf("tx %d: %w: outputs overflow", i, ErrMalformed)
+            }
+            out += o.Amount
+        }
+        if out > in {
+            return nil, nil, fmt.Errorf("tx %d: %w: spends %d and creates %d", i, ErrOverdraft, in, out)
+        }
+        id := tx.ID()
+        for j, o := range tx.Outputs {
+            created[OutPoint{Tx: id, Index: j}] = o
+        }
+    }
+    return spent, created, nil
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Same Interface, Other Model**: A UTXOSet is a State like Accounts, so the chains check and apply either
+//    without knowing which; only the encoding of transactions in block data differs, by its prefix.
+//
+// 2. **Spending Is Deleting**: The set holds only unspent outputs. Spending one removes it, so a second
+//    transaction that names it finds nothing to spend, whether it comes in the same block or a later one. The
+//    set cannot tell a spent output from one that never existed, and reports both with ErrSpent.
+//
+// 3. **IDs From Content**: A transaction's ID hashes its encoding, as in Bitcoin, so anyone can name the
+//    outputs of a transaction before it is committed and spend them later in the same block.
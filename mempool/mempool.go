@@ -0,0 +1,222 @@
+// Package mempool holds the transfers a block producer has received and not yet included, and chooses which of
+// them its next block carries. Blocks are limited in size and in gas, so when more transfers wait than a block
+// holds, the producer takes those paying the highest fees; the pool itself holds a limited number, and a full
+// pool turns away transfers that pay less than any it holds. Under congestion, senders who want their transfers
+// included soon must therefore outbid the others, which is the fee market of deployed chains.
+//
+// The pool works on ledger.Transfers. blocktree.ReplicaConfig.Mempool hands it the transfers proposed to a Proof
+// of Work, Proof of Stake or Delegated Proof of Stake replica, which then builds its blocks with Select.
+package mempool
+
+import (
+    "cmp"
+    "errors"
+    "slices"
+
+    "consensus-algorithms-edu/ledger"
+)
+
+var (
+    // ErrFull is returned for a transfer a full pool turns away, because it pays no more than any transfer the
+    // pool holds.
+    ErrFull = errors.New("mempool: full, and the transfer pays no more than any it holds")
+
+    // ErrUnderpriced is returned for a transfer that replaces one with the same sender and nonce without paying a
+    // higher fee.
+    ErrUnderpriced = errors.New("mempool: replacement pays no higher fee")
+
+    // ErrTooLarge is returned for a transfer that would not fit in a block even alone.
+    ErrTooLarge = errors.New("mempool: transfer exceeds the block limits")
+)
+
+// TransferGas is the gas a transfer uses, as a plain Ethereum transfer does.
+const TransferGas = 21000
+
+// DefaultCapacity is the number of transfers a pool holds if Config.Capacity is 0.
+const DefaultCapacity = 1000
+
+// Limits bound what one block carries.
+type Limits struct {
+    Bytes int    // Largest block data, in bytes; unlimited if 0.
+    Gas   uint64 // Most gas a block's transfers use together, TransferGas each; unlimited if 0.
+}
+
+// Allow reports whether a block carrying data keeps to the limits. Data that carries no transfers uses no gas.
+func (l Limits) Allow(data string) bool {
+    transfers, err := ledger.DecodeTransfers(data)
+    if err != nil {
+        return true // Malformed data is the state's to refuse, not the limits'.
+    }
+    return (l.Bytes == 0 || len(data) <= l.Bytes) && (l.Gas == 0 || Gas(transfers) <= l.Gas)
+}
+
+// Gas returns the gas transfers use together.
+func Gas(transfers []ledger.Transfer) uint64 {
+    return uint64(len(transfers)) * TransferGas
+}
+
+// Config describes a pool.
+type Config struct {
+    Capacity int    // Most transfers the pool holds; DefaultCapacity if 0.
+    Limits   Limits // Limits of every block, which Select keeps to and replicas check blocks from peers against.
+}
+
+// key names a transfer in the pool: a sender has at most one transfer per nonce waiting.
+type key struct {
+    from  string
+    nonce uint64
+}
+
+// entry is a transfer waiting in the pool.
+type entry struct {
+    transfer ledger.Transfer
+    seq      uint64 // Order of arrival, which breaks ties between equal fees in favor of the earlier transfer.
+}
+
+// Pool holds transfers waiting to be included, at most one per sender and nonce. It is not safe for concurrent
+// use; it belongs to the replica that produces blocks from it.
+type Pool struct {
+    capacity int
+    limits   Limits
+    entries  map[key]entry
+    seq      uint64
+}
+
+// New returns an empty pool.
+func New(cfg Config) *Pool {
+    if cfg.Capacity <= 0 {
+        cfg.Capacity = DefaultCapacity
+    }
+    return &Pool{capacity: cfg.Capacity, limits: cfg.Limits, entries: make(map[key]entry)}
+}
+
+// Limits returns the limits of every block.
+func (p *Pool) Limits() Limits {
+    return p.limits
+}
+
+// Len returns the number of transfers waiting.
+func (p *Pool) Len() int {
+    return len(p.entries)
+}
+
+// Add puts a transfer in the pool. A transfer with the sender and nonce of one already waiting replaces it if it
+// pays a higher fee, and fails with ErrUnderpriced otherwise. A full pool evicts the transfer paying the lowest
+// fee to make room, or fails with ErrFull if the new transfer pays no more than that.
+func (p *Pool) Add(t ledger.Transfer) error {
+    if !p.limits.Allow(ledger.EncodeTransfers(t)) {
+        return ErrTooLarge
+    }
+    k := key{t.From, t.Nonce}
+    if old, ok := p.entries[k]; ok {
+        if t.Fee <= old.transfer.Fee {
+            return ErrUnderpriced
+        }
+    } else if len(p.entries) >= p.capacity {
+        cheapest := p.cheapest()
+        if t.Fee <= p.entries[cheapest].transfer.Fee {
+            return ErrFull
+        }
+        delete(p.entries, cheapest)
+    }
+    p.seq++
+    p.entries[k] = entry{transfer: t, seq: p.seq}
+    return nil
+}
+
+// Remove drops the transfers with the senders and nonces of transfers, typically those of a block the chain
+// adopted, whether the pool holds the same transfers or others they replaced.
+func (p *Pool) Remove(transfers ...ledger.Transfer) {
+    for _, t := range transfers {
+        delete(p.entries, key{t.From, t.Nonce})
+    }
+}
+
+// Transfers returns the waiting transfers, the highest fee first.
+func (p *Pool) Transfers() []ledger.Transfer {
+    entries := p.sorted()
+    transfers := make([]ledger.Transfer, len(entries))
+    for i, e := range entries {
+        transfers[i] = e.transfer
+    }
+    return transfers
+}
+
+// Select chooses the transfers of the next block: the highest fees first, as many as the limits allow, each
+// sender's in the order of their nonces. valid, if not nil, is asked about the block so far with each candidate
+// appended, and returning false skips the candidate along with the sender's later transfers, which cannot apply
+// without it; a replica passes a check against its state, which refuses nonces already used or money a sender
+// lacks. The pool is not changed: transfers leave it through Remove once a block carrying them is adopted.
+func (p *Pool) Select(valid func(transfers []ledger.Transfer) bool) []ledger.Transfer {
+    queues := make(map[string][]entry) // Each sender's transfers by nonce.
+    for _, e := range p.entries {
+        queues[e.transfer.From] = append(queues[e.transfer.From], e)
+    }
+    for _, queue := range queues {
+        slices.SortFunc(queue, func(a, b entry) int { return cmp.Compare(a.transfer.Nonce, b.transfer.Nonce) })
+    }
+
+    var selected []ledger.Transfer
+    for len(queues) > 0 && (p.limits.Gas == 0 || Gas(selected)+TransferGas <= p.limits.Gas) {
+        var best *entry
+        for _, queue := range queues {
+            if head := &queue[0]; best == nil || before(*head, *best) {
+                best = head
+            }
+        }
+        from := best.transfer.From
+        candidate := append(slices.Clip(selected), best.transfer)
+        if !p.limits.Allow(ledger.EncodeTransfers(candidate...)) || (valid != nil && !valid(candidate)) {
+            delete(queues, from)
+            continue
+        }
+        selected = candidate
+        if queues[from] = queues[from][1:]; len(queues[from]) == 0 {
+            delete(queues, from)
+        }
+    }
+    return selected
+}
+
+// before reports whether a is chosen before b: it pays a higher fee, or the same fee and arrived first.
+func before(a, b entry) bool {
+    return a.transfer.Fee > b.transfer.Fee || a.transfer.Fee == b.transfer.Fee && a.seq < b.seq
+}
+
+// sorted returns the waiting entries in the order before gives them.
+func (p *Pool) sorted() []entry {
+    entries := make([]entry, 0, len(p.entries))
+    for _, e := range p.entries {
+        entries = append(entries, e)
+    }
+    slices.SortFunc(entries, func(a, b entry) int {
+        return cmp.Or(cmp.Compare(b.transfer.Fee, a.transfer.Fee), cmp.Compare(a.seq, b.seq))
+    })
+    return entries
+}
+
+// cheapest returns the key of the transfer a full pool evicts: the lowest fee, and of those the latest arrival.
+func (p *Pool) cheapest() key {
+    var worst key
+    var found *entry
+    for k, e := range p.entries {
+        if found == nil || before(*found, e) {
+            worst, found = k, &e
+        }
+    }
+    return worst
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Priority by Fee, Order by Nonce**: A sender's transfers must apply in nonce order, so Select only ever
+//    considers the lowest nonce of each sender, picking the highest fee among those. A high fee behind a low one
+//    waits for it, as in Ethereum's transaction pool, which is why senders raise the fee of a stuck transfer
+//    by replacing it rather than by sending another.
+//
+// 2. **One Gas Price**: Every transfer uses TransferGas, so ranking by fee is ranking by fee per gas. A pool
+//    holding transactions of different sizes would rank by fee per gas unit, or per byte in Bitcoin.
+//
+// 3. **Selection Without Removal**: Select leaves the pool unchanged, because a block a producer builds may never
+//    be adopted. Transfers leave the pool when a block carrying them joins the followed chain, and the replica
+//    returns those of abandoned blocks, so a reorganization never loses a transfer the pool had room for.
@@ -0,0 +1,136 @@
+package modelcheck
+
+import (
+    "bytes"
+    "crypto/sha256"
+    "encoding/binary"
+    "encoding/hex"
+    "log/slog"
+    "math"
+    "reflect"
+    "sort"
+    "sync"
+    "unsafe"
+
+    "google.golang.org/protobuf/proto"
+)
+
+var (
+    messageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+    loggerType  = reflect.TypeOf((*slog.Logger)(nil))
+    isMessage   sync.Map // Caches whether a pointer type implements proto.Message; Implements is slow.
+)
+
+// fingerprinter encodes the complete state of a value, including unexported fields, by walking it with
+// reflection. Two replicas that went through different events but ended up in the same state encode alike.
+type fingerprinter struct {
+    out   []byte
+    stack map[uintptr]bool // Pointers being walked, so that cycles terminate.
+}
+
+// fingerprint appends the encoded state of v to out.
+func fingerprint(out []byte, v any) []byte {
+    f := &fingerprinter{out: out, stack: make(map[uintptr]bool)}
+    f.value(reflect.ValueOf(v))
+    return f.out
+}
+
+// Fingerprint returns a short digest of the complete state of v, such as a replica, including its unexported
+// fields. Values in the same state have the same fingerprint however they got there, which lets the trace
+// package check that a replay reproduces every state it recorded.
+func Fingerprint(v any) string {
+    sum := sha256.Sum256(fingerprint(nil, v))
+    return hex.EncodeToString(sum[:8])
+}
+
+func (f *fingerprinter) uint(n uint64) {
+    f.out = binary.AppendUvarint(f.out, n)
+}
+
+func (f *fingerprinter) bytes(b []byte) {
+    f.uint(uint64(len(b)))
+    f.out = append(f.out, b...)
+}
+
+// value hashes one value. Protocol Buffers messages are hashed by their deterministic encoding, since their
+// internal bookkeeping, such as a cached size, changes when they are merely marshaled. Loggers, functions and
+// channels carry no protocol state and are skipped.
+func (f *fingerprinter) value(v reflect.Value) {
+    f.uint(uint64(v.Kind()))
+    switch v.Kind() {
+    case reflect.Bool:
+        if v.Bool() {
+            f.uint(1)
+        } else {
+            f.uint(0)
+        }
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+        f.uint(uint64(v.Int()))
+    case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+        f.uint(v.Uint())
+    case reflect.Float32, reflect.Float64:
+        f.uint(math.Float64bits(v.Float()))
+    case reflect.String:
+        f.bytes([]byte(v.String()))
+    case reflect.Array, reflect.Slice:
+        f.uint(uint64(v.Len()))
+        for i := 0; i < v.Len(); i++ {
+            f.value(v.Index(i))
+        }
+    case reflect.Struct:
+        for i := 0; i < v.NumField(); i++ {
+            f.value(v.Field(i))
+        }
+    case reflect.Map:
+        f.mapValue(v)
+    case reflect.Interface:
+        if v.IsNil() {
+            f.uint(0)
+            return
+        }
+        f.bytes([]byte(v.Elem().Type().String()))
+        f.value(v.Elem())
+    case reflect.Pointer:
+        f.pointer(v)
+    }
+}
+
+// pointer hashes what a pointer points to. A pointer shared by several fields is hashed in full each time, so
+// that the result does not depend on which field was walked first.
+func (f *fingerprinter) pointer(v reflect.Value) {
+    if v.IsNil() || v.Type() == loggerType || f.stack[v.Pointer()] {
+        f.uint(0)
+        return
+    }
+    f.stack[v.Pointer()] = true
+    defer delete(f.stack, v.Pointer())
+    message, ok := isMessage.Load(v.Type())
+    if !ok {
+        message, _ = isMessage.LoadOrStore(v.Type(), v.Type().Implements(messageType))
+    }
+    if message.(bool) {
+        // The field may be unexported, which reflection refuses to turn back into an interface value.
+        m := reflect.NewAt(v.Type().Elem(), unsafe.Pointer(v.Pointer())).Interface().(proto.Message)
+        payload, _ := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+        f.bytes(payload)
+        return
+    }
+    f.value(v.Elem())
+}
+
+// mapValue hashes the entries of a map in an order that does not depend on Go's randomized map iteration.
+func (f *fingerprinter) mapValue(v reflect.Value) {
+    entries := make([][]byte, 0, v.Len())
+    iter := v.MapRange()
+    for iter.Next() {
+        entry := &fingerprinter{stack: f.stack}
+        entry.value(iter.Key())
+        entry.value(iter.Value())
+        entries = append(entries, entry.out)
+    }
+    sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i], entries[j]) < 0 })
+    f.uint(uint64(len(entries)))
+    for _, entry := range entries {
+        f.bytes(entry)
+    }
+}
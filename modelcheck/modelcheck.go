@@ -0,0 +1,336 @@
+// Package modelcheck explores every way a tiny cluster of replicas can run, rather than the few a simulation
+// happens to sample. A run is a sequence of events — delivering one of the messages in flight, proposing the
+// next command at some replica, or letting a replica's timer expire — and Check tries each event that is
+// possible in each state, depth first, checking safety invariants in every state it reaches. Messages can be
+// delivered in any order and to any replica first, so interleavings a random simulation would need millions of
+// seeds to stumble upon, such as a vote arriving just after a competing election started, are all covered.
+//
+// The replicas are the same node.Replica state machines that run in production and in package sim. Check
+// fingerprints the state of every replica it reaches, so an interleaving that ends where another one already
+// did is not explored twice. Many orderings lead to the same state, such as acknowledgements from two followers
+// arriving in either order; recognizing them shrinks the search of a three-node Raft cluster that elects a leader
+// and commits one command from about two hundred thousand states to nine thousand.
+package modelcheck
+
+import (
+    "context"
+    "crypto/sha256"
+    "errors"
+    "fmt"
+    "math/rand"
+    "sort"
+    "strings"
+
+    "google.golang.org/protobuf/proto"
+
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+)
+
+// Config describes the cluster to explore and how far to explore it.
+type Config struct {
+    Nodes     int                                                    // Replicas in the cluster, with identifiers 0 to Nodes-1.
+    Commands  []string                                               // Data proposed during a run, in this order, each at any replica.
+    New       func(id int32, peers []int32, env Env) node.Replica    // Builds a fresh replica; called again for every run replayed.
+    Timeouts  int                                                    // Timer expirations allowed in one run.
+    MaxTicks  int                                                    // Ticks a timer expiration may take before the replica sends something; defaults to 100.
+    MaxDepth  int                                                    // Longest run explored, in events; defaults to 64.
+    MaxStates int                                                    // Distinct states explored before giving up; defaults to 100000.
+    Invariant func(replicas []node.Replica, commands []string) error // Checked in every state; Safety if nil.
+}
+
+// Env holds the deterministic time and randomness a replica must be built with, so that replaying a run
+// reproduces it exactly.
+type Env struct {
+    Clock clock.Clock // Fixed clock, so blocks proposed in different runs get the same timestamp.
+    Rand  *rand.Rand  // Random source seeded from the replica's identifier.
+}
+
+// source is a small random source, a SplitMix64 generator. Its whole state is one word, which keeps replaying a
+// run and fingerprinting the replicas that draw from it cheap.
+type source struct {
+    state uint64
+}
+
+func (s *source) Uint64() uint64 {
+    s.state += 0x9e3779b97f4a7c15
+    z := s.state
+    z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+    z = (z ^ z>>27) * 0x94d049bb133111eb
+    return z ^ z>>31
+}
+
+func (s *source) Int63() int64 {
+    return int64(s.Uint64() >> 1)
+}
+
+func (s *source) Seed(seed int64) {
+    s.state = uint64(seed)
+}
+
+// Result summarizes an exploration.
+type Result struct {
+    States   int  // Distinct states visited.
+    Depth    int  // Length of the longest run explored.
+    Complete bool // Set if every state reachable within MaxDepth was visited, so the invariant holds in all of them.
+}
+
+// Violation is returned by Check when the invariant fails. Trace lists the events that lead from the initial
+// state to the violation, so it can be replayed by hand.
+type Violation struct {
+    Err   error
+    Trace []string
+}
+
+func (v *Violation) Error() string {
+    return fmt.Sprintf("modelcheck: %v after %d events:\n  %s", v.Err, len(v.Trace), strings.Join(v.Trace, "\n  "))
+}
+
+func (v *Violation) Unwrap() error {
+    return v.Err
+}
+
+// Safety is the default invariant. It requires agreement (no two replicas commit different blocks at the same
+// height), validity (every committed block after the genesis block carries one of the commands) and integrity
+// (no replica commits the same command twice).
+func Safety(replicas []node.Replica, commands []string) error {
+    proposed := make(map[string]bool, len(commands))
+    for _, command := range commands {
+        proposed[command] = true
+    }
+    chains := make([][]*wire.Block, len(replicas))
+    for i, r := range replicas {
+        chains[i] = r.Committed()
+        seen := make(map[string]bool)
+        for _, block := range chains[i][min(1, len(chains[i])):] {
+            if !proposed[block.GetData()] {
+                return fmt.Errorf("validity: node %d committed %q, which was never proposed", r.ID(), block.GetData())
+            }
+            if seen[block.GetData()] {
+                return fmt.Errorf("integrity: node %d committed %q twice", r.ID(), block.GetData())
+            }
+            seen[block.GetData()] = true
+        }
+    }
+    for i := range chains {
+        for j := i + 1; j < len(chains); j++ {
+            for height := 0; height < min(len(chains[i]), len(chains[j])); height++ {
+                if a, b := chains[i][height], chains[j][height]; a.GetHash() != b.GetHash() {
+                    return fmt.Errorf("agreement: nodes %d and %d committed %q and %q at height %d",
+                        replicas[i].ID(), replicas[j].ID(), a.GetData(), b.GetData(), height)
+                }
+            }
+        }
+    }
+    return nil
+}
+
+// ErrNoReplica is returned when Config.New or Config.Nodes is missing.
+var ErrNoReplica = errors.New("modelcheck: Config.New and Config.Nodes are required")
+
+// Check explores the runs of the cluster described by cfg. It returns a *Violation as soon as a state breaks the
+// invariant; otherwise Result tells how much was explored and whether the search was exhaustive.
+func Check(cfg Config) (Result, error) {
+    return CheckContext(context.Background(), cfg)
+}
+
+// CheckContext is Check that stops once ctx is done. It then returns what it explored so far, marked as
+// incomplete, with the context's error, so a search too large to finish can still be given a time budget.
+func CheckContext(ctx context.Context, cfg Config) (Result, error) {
+    if cfg.New == nil || cfg.Nodes < 1 {
+        return Result{}, ErrNoReplica
+    }
+    if cfg.MaxTicks <= 0 {
+        cfg.MaxTicks = 100
+    }
+    if cfg.MaxDepth <= 0 {
+        cfg.MaxDepth = 64
+    }
+    if cfg.MaxStates <= 0 {
+        cfg.MaxStates = 100000
+    }
+    if cfg.Invariant == nil {
+        cfg.Invariant = Safety
+    }
+
+    initial := replay(cfg, nil, false)
+    if err := cfg.Invariant(initial.replicas, cfg.Commands); err != nil {
+        return Result{States: 1}, &Violation{Err: err}
+    }
+    result := Result{States: 1, Complete: true}
+    visited := map[string]bool{initial.key(): true}
+    stack := [][]event{nil}
+    for len(stack) > 0 {
+        if err := ctx.Err(); err != nil {
+            result.Complete = false
+            return result, err
+        }
+        path := stack[len(stack)-1]
+        stack = stack[:len(stack)-1]
+        enabled := replay(cfg, path, false).enabled(cfg)
+        if len(path) >= cfg.MaxDepth {
+            result.Complete = result.Complete && len(enabled) == 0
+            continue
+        }
+        for i := len(enabled) - 1; i >= 0; i-- { // Push in reverse so the first event is explored first.
+            next := append(path[:len(path):len(path)], enabled[i])
+            r := replay(cfg, next, false)
+            if visited[r.key()] {
+                continue // Reached before through another interleaving, or a rejected proposal changed nothing.
+            }
+            visited[r.key()] = true
+            result.States = len(visited)
+            result.Depth = max(result.Depth, len(next))
+            if err := cfg.Invariant(r.replicas, cfg.Commands); err != nil {
+                return result, &Violation{Err: err, Trace: replay(cfg, next, true).trace}
+            }
+            if len(visited) >= cfg.MaxStates {
+                result.Complete = false
+                return result, nil
+            }
+            stack = append(stack, next)
+        }
+    }
+    return result, nil
+}
+
+// eventKind is what an event does to the cluster.
+type eventKind int
+
+const (
+    deliver eventKind = iota // Deliver the message at position index of the messages in flight.
+    propose                  // Propose the next command at node.
+    timeout                  // Tick node until its timer expires and it sends something.
+)
+
+// event is one step of a run.
+type event struct {
+    kind  eventKind
+    node  int32
+    index int
+}
+
+// run is a cluster replayed along a path of events.
+type run struct {
+    replicas []node.Replica
+    inflight []*wire.Envelope
+    proposed int      // Commands proposed so far.
+    timeouts int      // Timer expirations so far.
+    tracing  bool     // Set to describe every event in trace, which only a violation needs.
+    trace    []string
+}
+
+// replay builds a fresh cluster and applies path to it.
+func replay(cfg Config, path []event, tracing bool) *run {
+    peers := make([]int32, cfg.Nodes)
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    r := &run{tracing: tracing}
+    for _, id := range peers {
+        env := Env{Clock: clock.NewMock(sim.Epoch), Rand: rand.New(&source{state: uint64(id)})}
+        r.replicas = append(r.replicas, cfg.New(id, peers, env))
+    }
+    for _, e := range path {
+        r.apply(cfg, e)
+    }
+    return r
+}
+
+// apply performs one event and queues the messages it produces.
+func (r *run) apply(cfg Config, e event) {
+    replica := r.replicas[e.node]
+    var out []*wire.Envelope
+    switch e.kind {
+    case deliver:
+        env := r.inflight[e.index]
+        r.inflight = append(r.inflight[:e.index:e.index], r.inflight[e.index+1:]...)
+        out = replica.Step(env)
+        r.describe("deliver %s from %d to %d", env.Kind(), env.GetFrom(), env.GetTo())
+    case propose:
+        command := cfg.Commands[r.proposed]
+        var err error
+        if out, err = replica.Propose(command); err != nil {
+            return // Rejected, e.g. by a Raft follower; the replica is unchanged and the command still waits.
+        }
+        r.proposed++
+        r.describe("propose %q at %d", command, e.node)
+    case timeout:
+        for tick := 0; tick < cfg.MaxTicks && len(out) == 0; tick++ {
+            out = replica.Tick()
+        }
+        r.timeouts++
+        r.describe("timeout at %d", e.node)
+    }
+    for _, env := range out {
+        if env.GetTo() >= 0 && int(env.GetTo()) < len(r.replicas) {
+            r.inflight = append(r.inflight, env)
+        }
+    }
+}
+
+// describe adds an event to the trace if the run is traced.
+func (r *run) describe(format string, args ...any) {
+    if r.tracing {
+        r.trace = append(r.trace, fmt.Sprintf(format, args...))
+    }
+}
+
+// enabled lists the events possible in the current state: every message in flight can be delivered, the next
+// command can be proposed at every replica, and every replica's timer can expire while the budget lasts.
+func (r *run) enabled(cfg Config) []event {
+    var events []event
+    for i, env := range r.inflight {
+        events = append(events, event{kind: deliver, node: env.GetTo(), index: i})
+    }
+    for id := range r.replicas {
+        if r.proposed < len(cfg.Commands) {
+            events = append(events, event{kind: propose, node: int32(id)})
+        }
+        if r.timeouts < cfg.Timeouts {
+            events = append(events, event{kind: timeout, node: int32(id)})
+        }
+    }
+    return events
+}
+
+// key identifies the state of the run: what every replica holds, which messages are in flight regardless of
+// their order, and how much of the command and timeout budgets is used.
+func (r *run) key() string {
+    state := fmt.Appendf(nil, "%d %d", r.proposed, r.timeouts)
+    for _, replica := range r.replicas {
+        state = fingerprint(state, replica)
+    }
+    inflight := make([]string, len(r.inflight))
+    for i, env := range r.inflight {
+        payload, _ := proto.MarshalOptions{Deterministic: true}.Marshal(env)
+        inflight[i] = string(payload)
+    }
+    sort.Strings(inflight)
+    for _, payload := range inflight {
+        state = fmt.Appendf(state, "%d:%s", len(payload), payload)
+    }
+    sum := sha256.Sum256(state)
+    return string(sum[:])
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Replay Instead of Copying**: Replicas keep their state private and cannot be cloned, so Check stores
+//    each state as the path of events that leads to it and rebuilds the cluster from scratch to continue from
+//    there. Replaying a few dozen events is cheap next to the bookkeeping a snapshot of every replica would need.
+//
+// 2. **Fingerprinting Private State**: Replicas do not expose their state, and asking every algorithm to
+//    serialize it for the checker would couple them to it. fingerprint walks a replica with reflection instead,
+//    reading unexported fields the way a debugger would and encoding Protocol Buffers messages canonically. A
+//    replica therefore needs nothing extra to be checked; the price is that its state must be plain data, which
+//    the deterministic replicas in this repository already are.
+//
+// 3. **Bounded Timeouts**: A timer can always expire again, so without a bound the runs are infinite.
+//    Config.Timeouts limits how many expirations one run may contain. Every ordering of messages is still
+//    explored; only runs with more elections, view changes or heartbeats than the budget are left out.
+//
+// 4. **Honest Results**: When a bound cuts the search short, Result.Complete is false. A test can then insist on
+//    an exhaustive search or accept a bounded one knowingly, instead of mistaking a partial search for a proof.
@@ -0,0 +1,45 @@
+package node
+
+import (
+    "errors"
+)
+
+// Status is where a node stands in the crash-recovery lifecycle.
+type Status int
+
+const (
+    Running    Status = iota // Taking part in consensus; the zero value, so nodes start out running.
+    Crashed                  // Stopped: it answers nothing and keeps only the state it had persisted.
+    Recovering               // Restarting: it restores its state and answers nothing until it is done.
+)
+
+// String returns the lower-case name of the status, e.g. "crashed".
+func (s Status) String() string {
+    switch s {
+    case Running:
+        return "running"
+    case Crashed:
+        return "crashed"
+    case Recovering:
+        return "recovering"
+    default:
+        return "unknown"
+    }
+}
+
+var (
+    // ErrCrashed is returned when a crashed or recovering node is asked to lead a round.
+    ErrCrashed = errors.New("node: crashed")
+
+    // ErrNotCrashed is returned by Recover for a node that is running or already recovering.
+    ErrNotCrashed = errors.New("node: not crashed")
+)
+
+// Lifecycle is implemented by nodes that can crash and come back: the nodes of the Raft, Paxos and PBFT
+// networks. A failure scenario written against it — crash the leader, crash a quorum, bring them back — reads
+// the same whichever of the three algorithms it runs on.
+type Lifecycle interface {
+    Status() Status // Where the node stands.
+    Crash()         // Stop the node; crashing a node that is not running does nothing.
+    Recover() error // Restore the node's state and let it rejoin; see each algorithm's Node.Restore.
+}
@@ -0,0 +1,13 @@
+package node
+
+import (
+    "errors"
+)
+
+var (
+    // ErrUnknownNode is returned when a node to be removed from a network is not a member of it.
+    ErrUnknownNode = errors.New("node: unknown node")
+
+    // ErrLastNode is returned when removing a node would leave a network with nobody to reach consensus.
+    ErrLastNode = errors.New("node: cannot remove the last node")
+)
@@ -0,0 +1,110 @@
+// Package options holds the functional options accepted by the constructors of every consensus network in this
+// repository. A constructor such as pbft.NewPBFTNetwork takes any number of Options instead of a fixed list of
+// positional parameters, so a new parameter — a number of faulty nodes, a random seed, a timeout — can be
+// introduced without changing the signature every existing caller depends on. Callers name only what they care
+// about, and everything else keeps its default:
+//
+//  pbft.NewPBFTNetwork(options.WithNodes(7), options.WithFaulty(2))
+//
+// The options are shared rather than redefined by each algorithm, so one vocabulary describes every network.
+// Each constructor documents which options it honors and ignores the rest, since not every parameter means
+// something to every algorithm: Proof of Work has no faulty nodes, and Raft draws no random numbers.
+package options
+
+import (
+    "time"
+
+    "consensus-algorithms-edu/quorum"
+    "consensus-algorithms-edu/transport"
+)
+
+// DefaultNodes is the number of nodes a network has unless WithNodes says otherwise.
+const DefaultNodes = 4
+
+// Options is the configuration a constructor assembles from its Option arguments.
+type Options struct {
+    Nodes     int                 // Number of nodes in the network.
+    Faulty    int                 // Number of nodes, counted from the end, that misbehave.
+    Timeout   time.Duration       // How long an operation may take before it is abandoned; no limit if 0.
+    Seed      int64               // Seed of the network's random choices.
+    Seeded    bool                // Whether Seed was set; unseeded networks use the shared random source.
+    Transport transport.Transport // Transport messages are sent through; nil if none was given.
+    Quorum    quorum.Builder      // Decides which votes suffice; the algorithm's own quorum if nil.
+    Alpha     int                 // Slots between choosing a change of members and its taking effect; 1 if 0.
+}
+
+// Option sets one field of Options.
+type Option func(*Options)
+
+// New returns the defaults with opts applied in order, so a later option overrides an earlier one.
+func New(opts ...Option) Options {
+    o := Options{Nodes: DefaultNodes}
+    for _, opt := range opts {
+        opt(&o)
+    }
+    o.Faulty = min(max(o.Faulty, 0), o.Nodes) // A network cannot have more faulty nodes than nodes.
+    return o
+}
+
+// WithNodes sets the number of nodes. Values below one are ignored.
+func WithNodes(n int) Option {
+    return func(o *Options) {
+        if n > 0 {
+            o.Nodes = n
+        }
+    }
+}
+
+// WithFaulty makes the last f nodes of the network faulty. What a faulty node does depends on the algorithm:
+// a Byzantine PBFT replica rejects every proposal, while a crashed Raft or Paxos node stays silent.
+func WithFaulty(f int) Option {
+    return func(o *Options) { o.Faulty = f }
+}
+
+// WithTimeout bounds how long an operation may take, such as mining a Proof of Work block.
+func WithTimeout(d time.Duration) Option {
+    return func(o *Options) { o.Timeout = d }
+}
+
+// WithSeed makes the network's random choices, such as which validator proposes next, repeat from run to run.
+func WithSeed(seed int64) Option {
+    return func(o *Options) {
+        o.Seed = seed
+        o.Seeded = true
+    }
+}
+
+// WithTransport sets the transport messages are sent through.
+func WithTransport(t transport.Transport) Option {
+    return func(o *Options) { o.Transport = t }
+}
+
+// WithQuorum replaces the quorum an algorithm's votes must reach, such as Raft's majority or PBFT's 2f+1, with
+// q built over the current nodes.
+func WithQuorum(q quorum.Builder) Option {
+    return func(o *Options) { o.Quorum = q }
+}
+
+// WithAlpha delays every change of members chosen at a slot of the log until slot+alpha, the α of Lamport's
+// reconfiguration for Paxos, so that up to alpha slots can be decided under a known set of members. Values below
+// one are ignored.
+func WithAlpha(alpha int) Option {
+    return func(o *Options) {
+        if alpha > 0 {
+            o.Alpha = alpha
+        }
+    }
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **One Struct Behind the Options**: Options are plain functions over a single exported struct. Adding a
+//    parameter means adding a field and a With function; no constructor signature changes, and constructors
+//    that do not need the new parameter never notice it.
+//
+// 2. **Shared Across Algorithms**: Every network takes the same Option type, so code that builds networks
+//    generically, like the engine package, passes the same options to all of them.
+//
+// 3. **Ignored, Not Rejected**: An option that means nothing to a network is ignored rather than reported as an
+//    error. This keeps constructors free of error returns they would otherwise only need for misuse, and lets
+//    one list of options be handed to several algorithms.
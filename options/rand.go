@@ -0,0 +1,52 @@
+package options
+
+import (
+    "math/rand"
+)
+
+// Rand is the random number generator of a network seeded with WithSeed. It draws the same numbers as
+// rand.New(rand.NewSource(seed)), and also counts them, so that Clone can give a copy of the network the same
+// random future as the original without drawing from, and so changing, the original's generator.
+type Rand struct {
+    *rand.Rand
+    src *countingSource
+}
+
+// NewRand returns a generator seeded with seed.
+func NewRand(seed int64) *Rand {
+    src := &countingSource{seed: seed, src: rand.NewSource(seed).(rand.Source64)}
+    return &Rand{Rand: rand.New(src), src: src}
+}
+
+// Clone returns a generator in the same state as r: it draws the numbers r would draw next, and drawing from
+// either leaves the other unchanged. Cloning replays every number r has drawn, so it takes time in proportion
+// to them.
+func (r *Rand) Clone() *Rand {
+    c := NewRand(r.src.seed)
+    for c.src.draws < r.src.draws {
+        c.src.Uint64()
+    }
+    return c
+}
+
+// countingSource is a seeded source that counts the numbers drawn from it.
+type countingSource struct {
+    seed  int64
+    draws uint64
+    src   rand.Source64
+}
+
+func (s *countingSource) Int63() int64 {
+    s.draws++
+    return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+    s.draws++
+    return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+    s.seed, s.draws = seed, 0
+    s.src.Seed(seed)
+}
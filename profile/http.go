@@ -0,0 +1,28 @@
+package profile
+
+import (
+    "net/http"
+    "net/http/pprof"
+)
+
+// Handler returns a handler that serves the net/http/pprof endpoints under /debug/pprof/, for `go tool pprof`
+// to take CPU, heap and goroutine profiles of the running process, and the report of the active profiler as a
+// table under /debug/phases. Unlike importing net/http/pprof, it registers nothing on http.DefaultServeMux.
+func Handler() http.Handler {
+    mux := http.NewServeMux()
+    mux.HandleFunc("/debug/pprof/", pprof.Index) // Also serves the named profiles, such as /debug/pprof/heap.
+    mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+    mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+    mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+    mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+    mux.HandleFunc("/debug/phases", func(w http.ResponseWriter, r *http.Request) {
+        p := active.Load()
+        if p == nil {
+            http.Error(w, "no profiler is active", http.StatusNotFound)
+            return
+        }
+        w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+        p.Report().Print(w)
+    })
+    return mux
+}
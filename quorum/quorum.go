@@ -0,0 +1,247 @@
+// Package quorum decides whether a set of votes is enough: enough replicas acknowledging a Raft entry to commit
+// it, enough PBFT commits to execute a block, enough stake behind a Casper checkpoint to justify it. Every
+// algorithm of this repository that counts votes asks a Quorum, so the rule can be swapped without editing the
+// algorithm, and the consequences compared:
+//
+//   - Majority: More than half of the members, the quorum of Raft and Paxos. Any two majorities share a member,
+//     so two of them never decide differently, and a majority survives the crash of any minority.
+//   - Byzantine: 2f+1 of 3f+1 members, the quorum of PBFT. Any two such quorums share f+1 members, at least one
+//     of them correct, which is what it takes when f members may lie.
+//   - MoreThan and AtLeast: A share of the stake rather than of the members, as Proof of Stake finality and
+//     Casper weigh their validators.
+//   - Count: Any given number of members. Flexible Paxos showed that the quorums of leader election and those
+//     of replication need only intersect each other, not themselves: with five replicas, elections by four
+//     and commits by two are safe, and commit faster.
+//   - Grid, GridRow and GridColumn: Members laid out in rows. A full row and one member of every row always
+//     intersect, so a row can commit while a member of each row elects, with quorums of about √n members.
+//
+// A Builder builds the quorum for a set of members, so algorithms whose members change can build it again, and
+// Intersect checks whether two quorums are safe to pair.
+package quorum
+
+import (
+    "errors"
+    "slices"
+)
+
+// ErrDisjoint is returned by Check for two quorums that can both be reached by disjoint sets of members.
+var ErrDisjoint = errors.New("quorum: two quorums may not intersect")
+
+// Quorum decides whether a set of voters suffices. Voters that are not members, and voters listed twice, count
+// once at most. Every quorum of this package is monotone: adding voters to a sufficient set keeps it sufficient.
+type Quorum interface {
+    Reached(voters []int32) bool
+}
+
+// Builder builds the quorum of a set of members. An algorithm calls it again whenever its members change.
+type Builder func(members []int32) Quorum
+
+// Weighted is reached once the voters' weights add up to Need. Majority, Byzantine, Count, MoreThan and AtLeast
+// all build one.
+type Weighted struct {
+    weights map[int32]int // Weight of each member; members of weight 0 never count.
+    total   int           // Sum of all weights.
+    need    int           // Weight the voters must reach.
+}
+
+// NewWeighted returns the quorum reached once the voters' weights add up to need.
+func NewWeighted(weights map[int32]int, need int) *Weighted {
+    w := &Weighted{weights: make(map[int32]int, len(weights)), need: need}
+    for id, weight := range weights {
+        if weight > 0 {
+            w.weights[id] = weight
+            w.total += weight
+        }
+    }
+    return w
+}
+
+// Reached reports whether the distinct voters' weights add up to the weight needed.
+func (w *Weighted) Reached(voters []int32) bool {
+    return w.Weight(voters) >= w.need
+}
+
+// Weight returns the weight of the distinct voters together.
+func (w *Weighted) Weight(voters []int32) int {
+    weight := 0
+    for i, id := range voters {
+        if !slices.Contains(voters[:i], id) {
+            weight += w.weights[id]
+        }
+    }
+    return weight
+}
+
+// Need returns the weight the voters must reach.
+func (w *Weighted) Need() int {
+    return w.need
+}
+
+// Total returns the weight of every member together.
+func (w *Weighted) Total() int {
+    return w.total
+}
+
+// Majority returns the quorum of more than half of members.
+func Majority(members []int32) Quorum {
+    return NewWeighted(ones(members), len(members)/2+1)
+}
+
+// Byzantine returns the quorum of 2f+1 of members, where f = (n-1)/3 is the number of faulty members n members
+// tolerate. With exactly 3f+1 members, that is every member but f.
+func Byzantine(members []int32) Quorum {
+    return NewWeighted(ones(members), 2*((len(members)-1)/3)+1)
+}
+
+// Count returns the builder of quorums of any size members, and of every member if there are fewer.
+func Count(size int) Builder {
+    return func(members []int32) Quorum {
+        return NewWeighted(ones(members), min(size, len(members)))
+    }
+}
+
+// MoreThan returns the builder of quorums of more than num/den of the members' stake. MoreThan(stakes, 1, 2) is
+// a majority of the stake, and MoreThan(stakes, 2, 3) the supermajority Byzantine fault tolerance needs. Members
+// without stake never count; every member weighs the same if stakes is nil.
+func MoreThan(stakes map[int32]int, num, den int) Builder {
+    return func(members []int32) Quorum {
+        weights := weigh(stakes, members)
+        return NewWeighted(weights, sum(weights)*num/den+1)
+    }
+}
+
+// AtLeast returns the builder of quorums of at least num/den of the members' stake, such as Casper's two thirds.
+// Members without stake never count; every member weighs the same if stakes is nil.
+func AtLeast(stakes map[int32]int, num, den int) Builder {
+    return func(members []int32) Quorum {
+        weights := weigh(stakes, members)
+        return NewWeighted(weights, (sum(weights)*num+den-1)/den)
+    }
+}
+
+// grid is the quorum Grid, GridRow and GridColumn build.
+type grid struct {
+    rows  [][]int32 // Members in rows, in the order given; the last row may be shorter.
+    row   bool      // Whether some row must vote in full.
+    cover bool      // Whether every row must have a voter.
+}
+
+// Grid returns the builder of grid quorums: the members, in the order given, fill rows of columns members, and a
+// quorum is a full row together with a member of every row. Any two such quorums intersect, since each has a
+// member in the row the other holds in full.
+func Grid(columns int) Builder {
+    return func(members []int32) Quorum { return newGrid(members, columns, true, true) }
+}
+
+// GridRow returns the builder of quorums of a full row of the grid Grid describes. Two rows do not intersect,
+// so a GridRow quorum is only safe paired with a GridColumn quorum.
+func GridRow(columns int) Builder {
+    return func(members []int32) Quorum { return newGrid(members, columns, true, false) }
+}
+
+// GridColumn returns the builder of quorums of a member of every row of the grid Grid describes, which intersect
+// every GridRow quorum.
+func GridColumn(columns int) Builder {
+    return func(members []int32) Quorum { return newGrid(members, columns, false, true) }
+}
+
+// newGrid lays members out in rows of columns members.
+func newGrid(members []int32, columns int, row, cover bool) *grid {
+    g := &grid{row: row, cover: cover}
+    for chunk := range slices.Chunk(members, max(columns, 1)) {
+        g.rows = append(g.rows, slices.Clone(chunk))
+    }
+    return g
+}
+
+// Reached reports whether the voters hold a full row, a member of every row, or both, as the grid requires.
+func (g *grid) Reached(voters []int32) bool {
+    if len(g.rows) == 0 {
+        return false
+    }
+    full, covered := false, true
+    for _, row := range g.rows {
+        voted := 0
+        for _, id := range row {
+            if slices.Contains(voters, id) {
+                voted++
+            }
+        }
+        full = full || voted == len(row)
+        covered = covered && voted > 0
+    }
+    return (full || !g.row) && (covered || !g.cover)
+}
+
+// Intersect reports whether every set of members that reaches a also shares a member with every set that reaches
+// b, which is what makes a decision by an a quorum and a decision by a b quorum see each other. It tries every
+// subset of members, 2^n of them, so it suits clusters of up to about twenty.
+func Intersect(members []int32, a, b Quorum) bool {
+    for mask := range uint64(1) << len(members) {
+        var in, out []int32
+        for i, id := range members {
+            if mask&(1<<i) != 0 {
+                in = append(in, id)
+            } else {
+                out = append(out, id)
+            }
+        }
+        // Quorums are monotone: if some b quorum avoided in, the rest of the members would reach b too.
+        if a.Reached(in) && b.Reached(out) {
+            return false
+        }
+    }
+    return true
+}
+
+// Check returns ErrDisjoint unless every quorum a builds for members intersects every quorum b builds. Decisions
+// that must see each other are checked pairwise: a single quorum used for every decision is checked against
+// itself.
+func Check(members []int32, a, b Builder) error {
+    if !Intersect(members, a(members), b(members)) {
+        return ErrDisjoint
+    }
+    return nil
+}
+
+// ones weighs every member 1.
+func ones(members []int32) map[int32]int {
+    return weigh(nil, members)
+}
+
+// weigh returns the stake of each member, or 1 for each if stakes is nil.
+func weigh(stakes map[int32]int, members []int32) map[int32]int {
+    weights := make(map[int32]int, len(members))
+    for _, id := range members {
+        weights[id] = 1
+        if stakes != nil {
+            weights[id] = stakes[id]
+        }
+    }
+    return weights
+}
+
+// sum returns the total of weights.
+func sum(weights map[int32]int) int {
+    total := 0
+    for _, w := range weights {
+        total += max(w, 0)
+    }
+    return total
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Sets, Not Counts**: A Quorum sees who voted, not how many. Counting suffices for majorities, but stake
+//    weighs voters differently and a grid cares which rows they sit in, so algorithms hand over the voters and
+//    the quorum does the arithmetic. Voters are deduplicated on the way, so an algorithm that collects votes in
+//    a list cannot count one twice.
+//
+// 2. **Intersection Is the Caller's Choice**: Nothing stops an algorithm from being built with quorums that do
+//    not intersect, such as elections and commits by two replicas of five each. That is deliberate: running a
+//    cluster with unsafe quorums and watching it diverge is one of the experiments this package exists for.
+//    Check says in advance whether a pair is safe.
+//
+// 3. **Brute Force for Intersection**: Intersect tries every subset of the members rather than reasoning about
+//    each kind of quorum, so it checks any monotone quorum, including ones defined outside this package, at a
+//    cost that stays small for the cluster sizes consensus algorithms run with.
@@ -0,0 +1,250 @@
+// Package rotation decides who leads each round of a consensus algorithm: the Raft leader of a term, the PBFT
+// primary of a view, the PoS proposer or DPoS producer of a slot. Each of those algorithms takes its Policy as a
+// parameter, so the same algorithm can run under different choices and be compared:
+//
+//   - RoundRobin: Candidates take turns in a fixed order. Predictable, and fair by count.
+//   - StakeWeighted: A draw seeded with the round number picks each candidate with a chance proportional to its
+//     stake, as Proof of Stake schedules proposers. Fair by stake, and still computed by everyone alike.
+//   - VRF: Each candidate evaluates a verifiable random function of the round and the candidate with the lowest
+//     output, scaled by its stake, leads. Nobody can predict the leader of a future round from public data alone.
+//   - Sticky: A candidate keeps leading until it fails a round, then the next one takes over. Leaders change
+//     only when they must, as Raft's and PBFT's do.
+//
+// A policy gives the same answer on every node asked the same question, so nodes agree on the leader without
+// exchanging messages; a node reports to its own policy each round whose leader failed it (Fail), which only
+// Sticky acts on. Algorithms whose candidates change, as DPoS delegates do at every election, take a Builder and
+// build a new policy for each set of candidates.
+package rotation
+
+import (
+    "crypto/sha256"
+    "encoding/binary"
+    "math"
+    "slices"
+)
+
+// Policy picks the leader of each round among a fixed set of candidates.
+type Policy interface {
+    Leader(round uint64) int32 // Candidate that leads round, or -1 if there is none.
+    Fail(round uint64)         // Reports that the leader of round failed to lead it, e.g. by producing no block.
+}
+
+// Builder builds the policy for a set of candidates. An algorithm calls it again whenever its candidates change.
+type Builder func(candidates []int32) Policy
+
+// Names of the policies Named returns.
+const (
+    NameRoundRobin    = "round-robin"
+    NameStakeWeighted = "stake-weighted"
+    NameVRF           = "vrf"
+    NameSticky        = "sticky"
+)
+
+// Names returns the name of every policy Named returns.
+func Names() []string {
+    return []string{NameRoundRobin, NameStakeWeighted, NameVRF, NameSticky}
+}
+
+// Named returns the policy with the given name, weighing candidates by stakes if it weighs them at all; every
+// candidate weighs the same if stakes is nil. It returns nil for an unknown name.
+func Named(name string, stakes map[int32]int) Builder {
+    switch name {
+    case NameRoundRobin:
+        return RoundRobin
+    case NameStakeWeighted:
+        return StakeWeighted(stakes)
+    case NameVRF:
+        return VRF(nil, stakes)
+    case NameSticky:
+        return Sticky
+    }
+    return nil
+}
+
+// roundRobin is the policy RoundRobin returns.
+type roundRobin []int32
+
+// RoundRobin returns the policy under which candidates take turns in the order given: round r is led by
+// candidates[r mod n].
+func RoundRobin(candidates []int32) Policy {
+    return roundRobin(slices.Clone(candidates))
+}
+
+// Leader returns the candidate whose turn round is.
+func (p roundRobin) Leader(round uint64) int32 {
+    if len(p) == 0 {
+        return -1
+    }
+    return p[round%uint64(len(p))]
+}
+
+// Fail does nothing: turns do not depend on failures.
+func (p roundRobin) Fail(uint64) {}
+
+// Weighted is the policy StakeWeighted builds: a stake-weighted draw seeded with the hash of the round number.
+// It never changes once built, so many nodes can share one.
+type Weighted struct {
+    ids  []int32 // Candidates with stake, sorted, in the order the draw walks them.
+    upTo []int   // Sum of the stakes of ids[0] through ids[i], which the draw searches.
+}
+
+// NewWeighted returns the stake-weighted policy over the given stakes; candidates without stake never lead.
+func NewWeighted(stakes map[int32]int) *Weighted {
+    w := &Weighted{}
+    for id, stake := range stakes {
+        if stake > 0 {
+            w.ids = append(w.ids, id)
+        }
+    }
+    slices.Sort(w.ids)
+    total := 0
+    w.upTo = make([]int, len(w.ids))
+    for i, id := range w.ids {
+        total += stakes[id]
+        w.upTo[i] = total
+    }
+    return w
+}
+
+// StakeWeighted returns the builder of stake-weighted policies: each candidate leads a round with a chance
+// proportional to its stake, and candidates without stake never do.
+func StakeWeighted(stakes map[int32]int) Builder {
+    return func(candidates []int32) Policy {
+        weights := make(map[int32]int, len(candidates))
+        for _, id := range candidates {
+            weights[id] = weight(stakes, id)
+        }
+        return NewWeighted(weights)
+    }
+}
+
+// Candidates returns the candidates with stake, sorted.
+func (w *Weighted) Candidates() []int32 {
+    return slices.Clone(w.ids)
+}
+
+// Total returns the sum of all stakes.
+func (w *Weighted) Total() int {
+    if len(w.upTo) == 0 {
+        return 0
+    }
+    return w.upTo[len(w.upTo)-1]
+}
+
+// Leader returns the candidate the draw seeded with the hash of round picks.
+func (w *Weighted) Leader(round uint64) int32 {
+    return w.Draw(sha256.Sum256(binary.BigEndian.AppendUint64(nil, round)))
+}
+
+// Draw returns the candidate a stake-weighted draw seeded with seed picks, or -1 if no candidate holds stake.
+func (w *Weighted) Draw(seed [32]byte) int32 {
+    if w.Total() == 0 {
+        return -1
+    }
+    pick := int(binary.BigEndian.Uint64(seed[:8]) % uint64(w.Total()))
+    i, _ := slices.BinarySearch(w.upTo, pick+1) // The first candidate whose stakes so far exceed pick.
+    return w.ids[i]
+}
+
+// Fail does nothing: the draw does not depend on failures.
+func (w *Weighted) Fail(uint64) {}
+
+// vrf is the policy VRF builds.
+type vrf struct {
+    seed       []byte
+    candidates []int32
+    stakes     map[int32]int
+}
+
+// VRF returns the builder of policies under which every candidate evaluates a verifiable random function of
+// seed and the round, and the lowest output, scaled by the candidate's stake, leads: each candidate leads with a
+// chance proportional to its stake, and every candidate with the same stake if stakes is nil. A real VRF is keyed
+// with the candidate's private key, so only the candidate knows its output until it reveals it with a proof
+// anyone can check. Here the output is a hash of the candidate's identifier that every node computes, which
+// gives the same leaders without the secrecy.
+func VRF(seed []byte, stakes map[int32]int) Builder {
+    return func(candidates []int32) Policy {
+        return &vrf{seed: slices.Clone(seed), candidates: slices.Clone(candidates), stakes: stakes}
+    }
+}
+
+// Leader returns the candidate with the lowest output for round, scaled by its stake.
+func (p *vrf) Leader(round uint64) int32 {
+    leader, best := int32(-1), math.Inf(1)
+    for _, id := range p.candidates {
+        stake := weight(p.stakes, id)
+        if stake <= 0 {
+            continue
+        }
+        // An exponential race: -ln(u)/stake is lowest for each candidate with a chance proportional to its stake.
+        if score := -math.Log(Output(p.seed, id, round)) / float64(stake); score < best {
+            leader, best = id, score
+        }
+    }
+    return leader
+}
+
+// Fail does nothing: outputs do not depend on failures.
+func (p *vrf) Fail(uint64) {}
+
+// Output returns candidate's VRF output for round, as a number in (0, 1].
+func Output(seed []byte, candidate int32, round uint64) float64 {
+    input := binary.BigEndian.AppendUint32(slices.Clone(seed), uint32(candidate))
+    sum := sha256.Sum256(binary.BigEndian.AppendUint64(input, round))
+    return (float64(binary.BigEndian.Uint64(sum[:8])>>11) + 1) / (1 << 53)
+}
+
+// sticky is the policy Sticky returns.
+type sticky struct {
+    candidates []int32
+    failed     []uint64 // Rounds whose leader failed, sorted.
+}
+
+// Sticky returns the policy under which the first candidate leads until it fails a round, then the next one in
+// the order given leads until it fails, and so on, wrapping around. The leader of a round depends on the
+// failures reported for the rounds before it, so nodes agree on it as long as they agree on which rounds
+// failed.
+func Sticky(candidates []int32) Policy {
+    return &sticky{candidates: slices.Clone(candidates)}
+}
+
+// Leader returns the candidate that took over after the failures reported before round.
+func (p *sticky) Leader(round uint64) int32 {
+    if len(p.candidates) == 0 {
+        return -1
+    }
+    before, _ := slices.BinarySearch(p.failed, round) // Failed rounds before round.
+    return p.candidates[before%len(p.candidates)]
+}
+
+// Fail makes the leader of round hand over to the next candidate from the following round on.
+func (p *sticky) Fail(round uint64) {
+    if i, found := slices.BinarySearch(p.failed, round); !found {
+        p.failed = slices.Insert(p.failed, i, round)
+    }
+}
+
+// weight returns the stake of candidate, or 1 if stakes is nil.
+func weight(stakes map[int32]int, candidate int32) int {
+    if stakes == nil {
+        return 1
+    }
+    return stakes[candidate]
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Rounds, Not Algorithms**: A policy only ever sees a round number and a set of candidates. Each algorithm
+//    decides what a round is (a Raft term, a PBFT view, a slot) and what failing it means, so one policy serves
+//    all four, and the differences between them stay in the algorithms.
+//
+// 2. **Agreement Without Messages**: Every policy is a pure function of its configuration, the round and, for
+//    Sticky, the failures reported so far. Nodes built with the same builder and candidates compute the same
+//    leaders independently, the way pos.Schedule always has. Sticky is the exception that proves the rule: nodes
+//    that disagree on which rounds failed disagree on the leader, and the algorithm's own recovery (a view change,
+//    an election, a fork choice) has to sort it out.
+//
+// 3. **The Exponential Race for VRF Sortition**: Drawing -ln(u)/stake per candidate and taking the lowest picks each
+//    candidate with a chance proportional to its stake, needs no shared total, and keeps each draw a function of
+//    the candidate's own output. That is the property a VRF needs: a candidate can prove its own result without
+//    knowing anyone else's.
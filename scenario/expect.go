@@ -0,0 +1,383 @@
+package scenario
+
+import (
+    "errors"
+    "fmt"
+    "slices"
+    "strconv"
+    "strings"
+    "time"
+
+    "consensus-algorithms-edu/compare"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/wire"
+)
+
+// Check is the outcome of one expectation of a scenario.
+type Check struct {
+    Expectation string // As written in the scenario.
+    Passed      bool
+    Detail      string // Why the expectation failed.
+}
+
+// check evaluates every expectation of the scenario against the run, in the order they are written.
+func (r *Result) check() []Check {
+    checks := make([]Check, 0, len(r.Scenario.Expect))
+    for _, text := range r.Scenario.Expect {
+        expect, err := parseExpectation(text, r.Scenario.Nodes)
+        detail := ""
+        if err != nil {
+            detail = err.Error() // Validate rejects such a scenario before it runs; this only guards a changed field.
+        } else {
+            detail = expect(r)
+        }
+        checks = append(checks, Check{Expectation: text, Passed: detail == "", Detail: detail})
+    }
+    return checks
+}
+
+// expectation checks a run and returns why it failed, or "" if it held.
+type expectation func(r *Result) string
+
+// nodeSet picks the replicas an expectation is about, given the run.
+type nodeSet func(r *Result) []int32
+
+// comparison is a relation between a measured number and the number an expectation names.
+type comparison struct {
+    op   string
+    want int
+}
+
+// holds reports whether got stands in the relation to the number wanted.
+func (c comparison) holds(got int) bool {
+    switch c.op {
+    case ">=":
+        return got >= c.want
+    case ">":
+        return got > c.want
+    case "<=":
+        return got <= c.want
+    case "<":
+        return got < c.want
+    }
+    return got == c.want
+}
+
+// String writes the relation as it is written in an expectation, e.g. ">= 10".
+func (c comparison) String() string {
+    return c.op + " " + strconv.Itoa(c.want)
+}
+
+// window is a span of virtual time, from its start up to but excluding its end.
+type window struct {
+    from, to time.Duration
+}
+
+// contains reports whether at falls in the window.
+func (w window) contains(at time.Duration) bool {
+    return at >= w.from && at < w.to
+}
+
+// String writes the window as it is written in an expectation, e.g. " from 5s to 10s", or "" for the whole run.
+func (w window) String() string {
+    switch {
+    case w.from == 0 && w.to == forever:
+        return ""
+    case w.from == 0:
+        return " before " + w.to.String()
+    case w.to == forever:
+        return " after " + w.from.String()
+    }
+    return fmt.Sprintf(" from %v to %v", w.from, w.to)
+}
+
+// forever ends the window of an expectation that names no end.
+const forever = time.Duration(1<<63 - 1)
+
+// parser reads an expectation word by word.
+type parser struct {
+    words []string
+    pos   int
+    nodes int // Replicas in the cluster, which every replica an expectation names must be one of.
+}
+
+// parseExpectation parses one expectation of a cluster of n replicas. The grammar is documented in the
+// package README; in short, an expectation names some replicas and what must hold of them,
+//
+//  all nodes agree
+//  honest nodes agree on height >= 10
+//  nodes {0,1} commit nothing from 5s to 10s
+//  node 3 commits >= 5 blocks after 10s
+//  all nodes follow the same leader
+//
+// or a number about the whole run: "confirmed >= 40" or "diverged heights == 0".
+func parseExpectation(text string, n int) (expectation, error) {
+    spaced := strings.NewReplacer("≥", " >= ", "≤", " <= ", ">=", " >= ", "<=", " <= ", "==", " == ").Replace(text)
+    p := &parser{words: strings.Fields(spaced), nodes: n}
+    check, err := p.expectation()
+    if err == nil && p.pos < len(p.words) {
+        err = fmt.Errorf("unexpected %q", strings.Join(p.words[p.pos:], " "))
+    }
+    return check, err
+}
+
+// expectation parses a whole expectation.
+func (p *parser) expectation() (expectation, error) {
+    switch {
+    case p.accept("confirmed"):
+        c, err := p.comparison()
+        return func(r *Result) string {
+            if got := r.Confirmed(); !c.holds(got) {
+                return fmt.Sprintf("%d transactions confirmed", got)
+            }
+            return ""
+        }, err
+    case p.accept("diverged", "heights"):
+        c, err := p.comparison()
+        return func(r *Result) string {
+            if got := r.Divergence(); !c.holds(got) {
+                return fmt.Sprintf("%d heights diverged", got)
+            }
+            return ""
+        }, err
+    }
+    nodes, err := p.nodeSet()
+    if err != nil {
+        return nil, err
+    }
+    switch {
+    case p.accept("agree", "on", "height"):
+        c, err := p.comparison()
+        return func(r *Result) string {
+            if detail := agree(r, nodes(r)); detail != "" {
+                return detail
+            }
+            return reach(r, nodes(r), c)
+        }, err
+    case p.accept("agree"):
+        return func(r *Result) string { return agree(r, nodes(r)) }, nil
+    case p.accept("reach", "height"), p.accept("reaches", "height"):
+        c, err := p.comparison()
+        return func(r *Result) string { return reach(r, nodes(r), c) }, err
+    case p.accept("commit", "nothing"), p.accept("commits", "nothing"):
+        w, err := p.window()
+        return func(r *Result) string { return commits(r, nodes(r), comparison{"==", 0}, w) }, err
+    case p.accept("commit"), p.accept("commits"):
+        c, err := p.comparison()
+        if err != nil {
+            return nil, err
+        }
+        if !p.accept("blocks") && !p.accept("block") {
+            return nil, errors.New(`want "blocks" after the number of blocks`)
+        }
+        w, err := p.window()
+        return func(r *Result) string { return commits(r, nodes(r), c, w) }, err
+    case p.accept("follow", "the", "same", "leader"):
+        return func(r *Result) string { return follow(r, nodes(r), -1) }, nil
+    case p.accept("follow", "node"), p.accept("follows", "node"):
+        id, err := p.node()
+        return func(r *Result) string { return follow(r, nodes(r), id) }, err
+    }
+    return nil, fmt.Errorf(`unknown expectation %q, want "agree", "agree on height", "reach height", "commit", "commit nothing" or "follow"`, strings.Join(p.words[p.pos:], " "))
+}
+
+// accept consumes words if they come next, and reports whether they did.
+func (p *parser) accept(words ...string) bool {
+    if p.pos+len(words) > len(p.words) || !slices.Equal(p.words[p.pos:p.pos+len(words)], words) {
+        return false
+    }
+    p.pos += len(words)
+    return true
+}
+
+// next consumes the next word, or returns "" at the end.
+func (p *parser) next() string {
+    if p.pos == len(p.words) {
+        return ""
+    }
+    p.pos++
+    return p.words[p.pos-1]
+}
+
+// nodeSet parses the replicas an expectation is about: "all nodes", "honest nodes", "node 2" or "nodes {0,1}".
+// Honest nodes are those the timeline never crashes.
+func (p *parser) nodeSet() (nodeSet, error) {
+    switch {
+    case p.accept("all", "nodes"):
+        return func(r *Result) []int32 { return r.nodes(nil) }, nil
+    case p.accept("honest", "nodes"):
+        return func(r *Result) []int32 { return r.nodes(r.crashed()) }, nil
+    case p.accept("node"):
+        id, err := p.node()
+        return func(*Result) []int32 { return []int32{id} }, err
+    case p.accept("nodes"):
+        list := p.next()
+        for strings.HasPrefix(list, "{") && !strings.HasSuffix(list, "}") && p.pos < len(p.words) {
+            list += p.next() // "{0, 1}" is split into words at its spaces.
+        }
+        var ids []int32
+        for _, field := range strings.Split(strings.Trim(list, "{}"), ",") {
+            id, err := p.id(field)
+            if err != nil {
+                return nil, err
+            }
+            ids = append(ids, id)
+        }
+        return func(*Result) []int32 { return ids }, nil
+    }
+    return nil, fmt.Errorf(`want "all nodes", "honest nodes", "node N" or "nodes {N,M}", got %q`, strings.Join(p.words[p.pos:], " "))
+}
+
+// node parses a replica number.
+func (p *parser) node() (int32, error) {
+    return p.id(p.next())
+}
+
+// id parses a replica number and checks that it is in the cluster.
+func (p *parser) id(word string) (int32, error) {
+    id, err := strconv.Atoi(strings.TrimSpace(word))
+    if err != nil {
+        return 0, fmt.Errorf("want a replica number, got %q", word)
+    }
+    if id < 0 || id >= p.nodes {
+        return 0, fmt.Errorf("replica %d is not one of the %d in the cluster", id, p.nodes)
+    }
+    return int32(id), nil
+}
+
+// comparison parses a relation and a number, such as ">= 10".
+func (p *parser) comparison() (comparison, error) {
+    op := p.next()
+    if op == "=" {
+        op = "=="
+    }
+    if !slices.Contains([]string{">=", ">", "<=", "<", "=="}, op) {
+        return comparison{}, fmt.Errorf("want one of >=, >, <=, < or == before a number, got %q", op)
+    }
+    word := p.next()
+    n, err := strconv.Atoi(word)
+    if err != nil {
+        return comparison{}, fmt.Errorf("want a number after %s, got %q", op, word)
+    }
+    return comparison{op, n}, nil
+}
+
+// window parses an optional span of time: "from 5s to 10s", "before 5s" or "after 10s". Without one, the
+// expectation covers the whole run.
+func (p *parser) window() (window, error) {
+    w := window{0, forever}
+    var err error
+    switch {
+    case p.accept("from"):
+        if w.from, err = p.duration(); err != nil {
+            return w, err
+        }
+        if !p.accept("to") {
+            return w, errors.New(`want "to" after the start of the span`)
+        }
+        w.to, err = p.duration()
+    case p.accept("before"):
+        w.to, err = p.duration()
+    case p.accept("after"):
+        w.from, err = p.duration()
+    }
+    return w, err
+}
+
+// duration parses a virtual time, written "5s" or "t=5s".
+func (p *parser) duration() (time.Duration, error) {
+    return time.ParseDuration(strings.TrimPrefix(p.next(), "t="))
+}
+
+// nodes returns every replica of the run but those excluded, in order.
+func (r *Result) nodes(excluded []int32) []int32 {
+    var ids []int32
+    for id := range int32(len(r.replicas)) {
+        if !slices.Contains(excluded, id) {
+            ids = append(ids, id)
+        }
+    }
+    return ids
+}
+
+// crashed returns the replicas the timeline crashes.
+func (r *Result) crashed() []int32 {
+    var ids []int32
+    for _, event := range r.Script {
+        if event.Kind == compare.Isolate {
+            ids = append(ids, event.Node)
+        }
+    }
+    return ids
+}
+
+// agree returns why the chains of ids conflict, or "" if no two hold different blocks at the same height.
+func agree(r *Result, ids []int32) string {
+    chains := r.Chains()
+    for i, a := range ids {
+        for _, b := range ids[i+1:] {
+            if d := wire.Diff(chains[a], chains[b]); d.Conflicting() {
+                return fmt.Sprintf("%s and %s hold different blocks at height %d", node.Name(a), node.Name(b), d.Height)
+            }
+        }
+    }
+    return ""
+}
+
+// reach returns which of ids ended at a height outside c, or "" if none did.
+func reach(r *Result, ids []int32, c comparison) string {
+    states := r.States()
+    for _, id := range ids {
+        if height := states[id].Height; !c.holds(height) {
+            return fmt.Sprintf("%s is at height %d", node.Name(id), height)
+        }
+    }
+    return ""
+}
+
+// commits returns which of ids committed a number of blocks outside c within w, or "" if none did.
+func commits(r *Result, ids []int32, c comparison, w window) string {
+    counts := make(map[int32]int)
+    first := make(map[int32]Commit)
+    for _, commit := range r.Commits {
+        if slices.Contains(ids, commit.Node) && w.contains(commit.At) {
+            if counts[commit.Node] == 0 {
+                first[commit.Node] = commit
+            }
+            counts[commit.Node]++
+        }
+    }
+    for _, id := range ids {
+        if !c.holds(counts[id]) {
+            if c.want == 0 && c.op == "==" {
+                return fmt.Sprintf("%s committed height %d at %v", node.Name(id), first[id].Height, first[id].At)
+            }
+            return fmt.Sprintf("%s committed %d blocks%s", node.Name(id), counts[id], w)
+        }
+    }
+    return ""
+}
+
+// follow returns which of ids ended following another leader than leader, or a different leader from the
+// others if leader is -1, or "" if none did.
+func follow(r *Result, ids []int32, leader int32) string {
+    states := r.States()
+    for _, id := range ids {
+        want := leader
+        if want < 0 {
+            want = states[ids[0]].Leader
+        }
+        if got := states[id].Leader; got < 0 || got != want {
+            return fmt.Sprintf("%s follows %s", node.Name(id), leaderName(got))
+        }
+    }
+    return ""
+}
+
+// leaderName names a leader, or returns "no leader" for -1.
+func leaderName(id int32) string {
+    if id < 0 {
+        return "no leader"
+    }
+    return node.Name(id)
+}
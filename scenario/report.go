@@ -0,0 +1,148 @@
+package scenario
+
+import (
+    "fmt"
+    "io"
+    "text/tabwriter"
+    "time"
+
+    "consensus-algorithms-edu/compare"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/trace"
+    "consensus-algorithms-edu/wire"
+)
+
+// Result is what a run of a scenario left behind.
+type Result struct {
+    Scenario Scenario       // The scenario, with defaults filled in.
+    Script   compare.Script // Every event played, transactions and faults, in order of time.
+    Commits  []Commit       // Every block every replica committed, in the order they did, genesis blocks excluded.
+    Messages sim.Stats      // Messages the replicas sent, and what the network did with them.
+    Checks   []Check        // Outcome of every expectation of the scenario, in the order written.
+    replicas []node.Replica
+}
+
+// Commit is a block one replica committed.
+type Commit struct {
+    At     time.Duration // Virtual time of the commit.
+    Node   int32         // Replica that committed the block.
+    Height int64
+    Hash   string
+    Data   string
+}
+
+// States returns the state every replica ended the run in, in the order of their identifiers.
+func (r *Result) States() []trace.State {
+    states := make([]trace.State, len(r.replicas))
+    for i, replica := range r.replicas {
+        states[i] = trace.Describe(replica)
+    }
+    return states
+}
+
+// Chains returns the blocks every replica had committed at the end of the run, in the order of their
+// identifiers.
+func (r *Result) Chains() [][]*wire.Block {
+    chains := make([][]*wire.Block, len(r.replicas))
+    for i, replica := range r.replicas {
+        chains[i] = replica.Committed()
+    }
+    return chains
+}
+
+// Submitted returns the number of transactions the script submitted.
+func (r *Result) Submitted() int {
+    n := 0
+    for _, event := range r.Script {
+        if event.Kind == compare.Submit {
+            n++
+        }
+    }
+    return n
+}
+
+// Confirmed returns the number of submitted transactions some replica committed.
+func (r *Result) Confirmed() int {
+    submitted := make(map[string]bool)
+    for _, event := range r.Script {
+        if event.Kind == compare.Submit {
+            submitted[event.Data] = true
+        }
+    }
+    n := 0
+    for _, c := range r.Commits {
+        if submitted[c.Data] {
+            n++
+            delete(submitted, c.Data)
+        }
+    }
+    return n
+}
+
+// Divergence returns the number of heights at which two replicas committed different blocks at some point of
+// the run, including blocks a fork switch later rolled back.
+func (r *Result) Divergence() int {
+    heights := make(map[int64]string)
+    diverged := make(map[int64]bool)
+    for _, c := range r.Commits {
+        if hash, ok := heights[c.Height]; ok && hash != c.Hash {
+            diverged[c.Height] = true
+        }
+        heights[c.Height] = c.Hash
+    }
+    return len(diverged)
+}
+
+// Failed returns the number of expectations the run did not meet.
+func (r *Result) Failed() int {
+    n := 0
+    for _, c := range r.Checks {
+        if !c.Passed {
+            n++
+        }
+    }
+    return n
+}
+
+// Print writes the scenario's parameters, its timeline as written, the state every replica ended in, and
+// whether each expectation was met.
+func (r *Result) Print(w io.Writer) error {
+    sc := r.Scenario
+    name := sc.Name
+    if name == "" {
+        name = sc.Algorithm
+    }
+    fmt.Fprintf(w, "%s: %s, %d nodes, seed %d, %v, latency %s\n", name, sc.Algorithm, sc.Nodes, sc.Seed, sc.Duration, sc.Network.Latency)
+    for _, line := range sc.Timeline {
+        fmt.Fprintf(w, "  %s\n", line)
+    }
+    fmt.Fprintln(w)
+    tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintln(tw, "node\theight\thead\tleader\t")
+    for _, state := range r.States() {
+        leader := "-"
+        if state.Leader >= 0 {
+            leader = node.Name(state.Leader)
+        }
+        fmt.Fprintf(tw, "%s\t%d\t%.12s\t%s\t\n", node.Name(state.Node), state.Height, state.Head, leader)
+    }
+    if err := tw.Flush(); err != nil {
+        return err
+    }
+    _, err := fmt.Fprintf(w, "\n%d transactions submitted, %d confirmed, %d diverged heights, %d messages sent, %d dropped\n",
+        r.Submitted(), r.Confirmed(), r.Divergence(), r.Messages.Sent, r.Messages.Dropped)
+    if err != nil || len(r.Checks) == 0 {
+        return err
+    }
+    fmt.Fprintln(w)
+    for _, c := range r.Checks {
+        if c.Passed {
+            fmt.Fprintf(w, "  ok      %s\n", c.Expectation)
+        } else {
+            fmt.Fprintf(w, "  FAILED  %s: %s\n", c.Expectation, c.Detail)
+        }
+    }
+    _, err = fmt.Fprintf(w, "%d of %d expectations met\n", len(r.Checks)-r.Failed(), len(r.Checks))
+    return err
+}
@@ -0,0 +1,330 @@
+// Package shell drives a simulated cluster from typed commands. Where `consensus step` only walks forward and
+// proposes, a shell lets its user poke the cluster from every side while it runs: propose data, crash a
+// replica and bring it back, split the network, advance time by a step or by a second, and look at any
+// replica's chain, all without writing a program:
+//
+//  raft> run 1s
+//  raft> partition 0,1 / 2,3,4
+//  raft> propose pay bob 5
+//  raft> step 3
+//  raft> show chain 3
+//
+// A Shell wraps a sim.Simulator whose replicas are already added. Exec carries out one command and Run reads
+// them from a terminal or a file until the input ends. The faults are the events of package compare, so a
+// session can be turned into a scenario file afterwards.
+package shell
+
+import (
+    "bufio"
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "slices"
+    "strconv"
+    "strings"
+    "text/tabwriter"
+    "time"
+
+    "consensus-algorithms-edu/compare"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/tui"
+)
+
+// ErrQuit is returned by Exec for the command that ends the session.
+var ErrQuit = errors.New("shell: quit")
+
+// stepLimit is how far in virtual time step looks for something to happen before giving up.
+const stepLimit = time.Minute
+
+// Shell carries out commands on a simulated cluster.
+type Shell struct {
+    sim       *sim.Simulator
+    algorithm string
+    out       io.Writer
+    proposals int
+    stepping  bool             // Whether transitions are being collected for step.
+    taken     []sim.Transition // Significant transitions taken by the current step.
+}
+
+// New returns a shell for s, whose replicas must have been added already, writing its answers to out. An
+// OnTransition hook already set on s is still called.
+func New(s *sim.Simulator, algorithm string, out io.Writer) *Shell {
+    sh := &Shell{sim: s, algorithm: algorithm, out: out}
+    previous := s.OnTransition
+    s.OnTransition = func(t sim.Transition) {
+        if previous != nil {
+            previous(t)
+        }
+        if sh.stepping && sim.Significant(t) {
+            sh.taken = append(sh.taken, t)
+        }
+    }
+    return sh
+}
+
+// Run reads commands from in, one per line, and carries them out until the input ends, quit is typed or ctx is
+// done. A command that fails prints why and the session goes on.
+func (sh *Shell) Run(ctx context.Context, in io.Reader) error {
+    fmt.Fprintf(sh.out, "%s with %d nodes. Type help for the commands.\n", sh.algorithm, len(sh.sim.Nodes()))
+    input := bufio.NewScanner(in)
+    for {
+        fmt.Fprintf(sh.out, "[%v] %s> ", sh.sim.Now().Round(time.Millisecond), sh.algorithm)
+        if !input.Scan() || ctx.Err() != nil {
+            fmt.Fprintln(sh.out)
+            return input.Err()
+        }
+        err := sh.Exec(input.Text())
+        if errors.Is(err, ErrQuit) {
+            return nil
+        }
+        if err != nil {
+            fmt.Fprintln(sh.out, err)
+        }
+    }
+}
+
+// commands describes every command, in the order help lists them.
+var commands = [][2]string{
+    {"propose [DATA]", "propose data, or the next \"Block N data\", at the leader most nodes follow"},
+    {"crash N", "cut node N off from every other node, which to them looks like a crash"},
+    {"recover N", "reconnect node N to every other node"},
+    {"partition A / B [/ C...]", "split the nodes into groups, e.g. partition 0,1 / 2,3,4"},
+    {"lossy RATE", "lose a share of all messages, e.g. lossy 0.1"},
+    {"heal", "end every partition, crash and loss"},
+    {"step [N]", "run until N messages, proposals or timeouts have happened (default 1)"},
+    {"run DURATION", "run the cluster for a span of virtual time, e.g. run 500ms"},
+    {"show [nodes]", "print every node's role, term, log, height and leader"},
+    {"show chain N", "print the blocks node N committed"},
+    {"show network", "print which nodes cannot reach which"},
+    {"quit", "end the session"},
+}
+
+// Exec carries out one command. Blank lines and lines starting with # do nothing, so a session can be read
+// from a commented file.
+func (sh *Shell) Exec(line string) error {
+    fields := strings.Fields(line)
+    if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+        return nil
+    }
+    name, args := fields[0], fields[1:]
+    switch name {
+    case "help", "?":
+        tw := tabwriter.NewWriter(sh.out, 0, 0, 2, ' ', 0)
+        for _, c := range commands {
+            fmt.Fprintf(tw, "  %s\t%s\n", c[0], c[1])
+        }
+        return tw.Flush()
+    case "propose", "p":
+        return sh.propose(strings.Join(args, " "))
+    case "crash":
+        id, err := sh.node(args)
+        if err != nil {
+            return err
+        }
+        compare.Event{Kind: compare.Isolate, Node: id}.Apply(sh.sim)
+        fmt.Fprintf(sh.out, "%s is cut off\n", node.Name(id))
+    case "recover":
+        id, err := sh.node(args)
+        if err != nil {
+            return err
+        }
+        for _, other := range sh.sim.Nodes() {
+            sh.sim.Network.Connect(id, other)
+        }
+        fmt.Fprintf(sh.out, "%s is reconnected\n", node.Name(id))
+    case "partition":
+        groups, err := sh.groups(strings.Join(args, " "))
+        if err != nil {
+            return err
+        }
+        compare.Event{Kind: compare.Partition, Groups: groups}.Apply(sh.sim)
+        fmt.Fprintf(sh.out, "partitioned into %d groups\n", len(groups))
+    case "lossy":
+        if len(args) != 1 {
+            return errors.New("usage: lossy RATE")
+        }
+        rate, err := strconv.ParseFloat(args[0], 64)
+        if err != nil || rate < 0 || rate > 1 {
+            return fmt.Errorf("lossy: want a rate between 0 and 1, got %q", args[0])
+        }
+        compare.Event{Kind: compare.Lossy, Rate: rate}.Apply(sh.sim)
+        fmt.Fprintf(sh.out, "losing %g of all messages\n", rate)
+    case "heal":
+        compare.Event{Kind: compare.Heal}.Apply(sh.sim)
+        fmt.Fprintln(sh.out, "healed every fault")
+    case "step", "s", "n":
+        count := 1
+        if len(args) == 1 {
+            n, err := strconv.Atoi(args[0])
+            if err != nil || n <= 0 {
+                return fmt.Errorf("step: want a positive number, got %q", args[0])
+            }
+            count = n
+        }
+        sh.step(count)
+    case "run":
+        if len(args) != 1 {
+            return errors.New("usage: run DURATION")
+        }
+        d, err := time.ParseDuration(args[0])
+        if err != nil || d <= 0 {
+            return fmt.Errorf("run: want a positive duration such as 500ms, got %q", args[0])
+        }
+        heights := sh.heights()
+        sh.sim.RunFor(d)
+        fmt.Fprintf(sh.out, "ran to %v, %d blocks committed across the nodes\n", sh.sim.Now().Round(time.Millisecond), sum(sh.heights())-sum(heights))
+    case "show":
+        return sh.show(args)
+    case "quit", "exit", "q":
+        return ErrQuit
+    default:
+        return fmt.Errorf("unknown command %q; type help for the commands", name)
+    }
+    return nil
+}
+
+// propose proposes data, or the next numbered block, at the leader most nodes follow.
+func (sh *Shell) propose(data string) error {
+    if data == "" {
+        sh.proposals++
+        data = fmt.Sprintf("Block %d data", sh.proposals)
+    }
+    target := compare.Target(sh.sim)
+    if err := sh.sim.Propose(target, data); err != nil {
+        return fmt.Errorf("%s refused %q: %w", node.Name(target), data, err)
+    }
+    fmt.Fprintf(sh.out, "proposed %q at %s\n", data, node.Name(target))
+    return nil
+}
+
+// step runs the simulation until count significant transitions have happened, printing each with the state of
+// the node that took it, or until nothing happens within stepLimit.
+func (sh *Shell) step(count int) {
+    sh.stepping, sh.taken = true, nil
+    defer func() { sh.stepping = false }()
+    limit := sh.sim.Now() + stepLimit
+    for len(sh.taken) < count && sh.sim.Now() <= limit && sh.sim.Step() {
+    }
+    for _, t := range sh.taken[:min(count, len(sh.taken))] {
+        row := tui.Describe(sh.sim.Replica(t.Node))
+        fmt.Fprintf(sh.out, "%v\n    %s now %s at height %d, leader %s\n", t, node.Name(t.Node), row.Role, row.Height, row.Leader)
+    }
+    if len(sh.taken) < count {
+        fmt.Fprintf(sh.out, "nothing happened for %v of virtual time\n", stepLimit)
+    }
+}
+
+// show prints the nodes, one node's chain or the state of the network.
+func (sh *Shell) show(args []string) error {
+    switch {
+    case len(args) == 0 || (len(args) == 1 && args[0] == "nodes"):
+        tw := tabwriter.NewWriter(sh.out, 0, 0, 2, ' ', tabwriter.AlignRight)
+        fmt.Fprintln(tw, "node\trole\tterm\tlog\theight\tleader\t")
+        for _, id := range sh.sim.Nodes() {
+            row := tui.Describe(sh.sim.Replica(id))
+            fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%s\t\n", node.Name(id), row.Role, row.Term, row.Log, row.Height, row.Leader)
+        }
+        return tw.Flush()
+    case len(args) == 2 && args[0] == "chain":
+        id, err := sh.node(args[1:])
+        if err != nil {
+            return err
+        }
+        tw := tabwriter.NewWriter(sh.out, 0, 0, 2, ' ', 0)
+        fmt.Fprintf(tw, "  height\thash\tdata\n")
+        for _, block := range sh.sim.Replica(id).Committed() {
+            fmt.Fprintf(tw, "  %d\t%.12s\t%s\n", block.GetIndex(), block.GetHash(), block.GetData())
+        }
+        return tw.Flush()
+    case len(args) == 1 && args[0] == "network":
+        cut := false
+        for _, id := range sh.sim.Nodes() {
+            var unreachable []string
+            for _, other := range sh.sim.Nodes() {
+                if other != id && !sh.sim.Network.Connected(id, other) {
+                    unreachable = append(unreachable, strconv.Itoa(int(other)))
+                }
+            }
+            if len(unreachable) > 0 {
+                fmt.Fprintf(sh.out, "  %s cannot reach %s\n", node.Name(id), strings.Join(unreachable, ","))
+                cut = true
+            }
+        }
+        if !cut {
+            fmt.Fprintln(sh.out, "  every node reaches every other")
+        }
+        return nil
+    }
+    return errors.New("usage: show [nodes], show chain N or show network")
+}
+
+// node parses the single argument of a command as a node of the cluster.
+func (sh *Shell) node(args []string) (int32, error) {
+    if len(args) != 1 {
+        return 0, errors.New("want one node number")
+    }
+    return sh.id(args[0])
+}
+
+// id parses a node number and checks that it is in the cluster.
+func (sh *Shell) id(word string) (int32, error) {
+    id, err := strconv.Atoi(strings.TrimSpace(word))
+    if err != nil || !slices.Contains(sh.sim.Nodes(), int32(id)) {
+        return 0, fmt.Errorf("no node %q; nodes are numbered 0 to %d", word, len(sh.sim.Nodes())-1)
+    }
+    return int32(id), nil
+}
+
+// groups parses the groups of a partition, "0,1 / 2,3,4". "|" separates groups too, as in compare scripts.
+func (sh *Shell) groups(text string) ([][]int32, error) {
+    parts := strings.FieldsFunc(text, func(r rune) bool { return r == '/' || r == '|' })
+    if len(parts) < 2 {
+        return nil, errors.New("usage: partition A / B, e.g. partition 0,1 / 2,3,4")
+    }
+    var groups [][]int32
+    for _, part := range parts {
+        var group []int32
+        for _, word := range strings.FieldsFunc(part, func(r rune) bool { return r == ',' || r == ' ' }) {
+            id, err := sh.id(word)
+            if err != nil {
+                return nil, err
+            }
+            group = append(group, id)
+        }
+        groups = append(groups, group)
+    }
+    return groups, nil
+}
+
+// heights returns the height every node has committed, in the order of their identifiers.
+func (sh *Shell) heights() []int {
+    var heights []int
+    for _, id := range sh.sim.Nodes() {
+        heights = append(heights, tui.Describe(sh.sim.Replica(id)).Height)
+    }
+    return heights
+}
+
+// sum adds up a list of numbers.
+func sum(values []int) int {
+    total := 0
+    for _, v := range values {
+        total += v
+    }
+    return total
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **One Command, One Call**: Exec carries out a single line and Run only adds the prompt and the loop, so a
+//    session can be typed, piped from a file or driven from a test the same way, and every answer goes to the
+//    writer the shell was given.
+//
+// 2. **Faults Are compare Events**: crash, partition, lossy and heal apply the events of compare scripts and
+//    scenario timelines, so a fault tried out in the shell behaves exactly like the same fault in a file. Only
+//    recover, which reconnects one node, has no event of its own.
+//
+// 3. **Steps Are Significant Transitions**: step stops at what sim.Significant selects, as `consensus step`
+//    does, so ticks that only count down a timer do not need to be stepped through one by one.
@@ -0,0 +1,60 @@
+package sim
+
+import (
+    "time"
+
+    "consensus-algorithms-edu/clock"
+)
+
+// Epoch is the wall-clock time at which every simulation starts.
+var Epoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Clock returns a clock.Clock that follows the simulation's virtual time, counted from Epoch. Pass it to
+// replicas as their clock (for example Raft's ReplicaConfig.Clock) so that the blocks they create do not
+// depend on when the simulation was run. Its timers are events of the simulation: they fire while the
+// simulation runs, on the goroutine running it.
+func (s *Simulator) Clock() clock.Clock {
+    return simClock{s}
+}
+
+// simClock is the virtual clock of a Simulator.
+type simClock struct{ s *Simulator }
+
+func (c simClock) Now() time.Time {
+    return Epoch.Add(c.s.now)
+}
+
+func (c simClock) After(d time.Duration) <-chan time.Time {
+    ch := make(chan time.Time, 1)
+    c.s.schedule(c.s.now+max(d, 0), func() { ch <- c.Now() })
+    return ch
+}
+
+func (c simClock) NewTicker(d time.Duration) clock.Ticker {
+    if d <= 0 {
+        panic("sim: non-positive interval for NewTicker")
+    }
+    t := &simTicker{c: make(chan time.Time, 1)}
+    var tick func()
+    tick = func() {
+        if t.stopped {
+            return
+        }
+        select {
+        case t.c <- c.Now():
+        default: // The previous tick was not received yet; skip this one.
+        }
+        c.s.schedule(c.s.now+d, tick)
+    }
+    c.s.schedule(c.s.now+d, tick)
+    return t
+}
+
+// simTicker is a clock.Ticker driven by simulation events.
+type simTicker struct {
+    c       chan time.Time
+    stopped bool
+}
+
+func (t *simTicker) C() <-chan time.Time { return t.c }
+func (t *simTicker) Stop()               { t.stopped = true }
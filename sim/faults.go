@@ -0,0 +1,155 @@
+package sim
+
+import (
+    "time"
+
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/wire"
+)
+
+// defaultReorderWindow is how far a reordered message is held back when Fault.ReorderWindow is not set.
+const defaultReorderWindow = 50 * time.Millisecond
+
+// Fault is a rule that disturbs the delivery of selected messages, on top of the behavior of their link.
+// A rule selects messages by type and, optionally, by a predicate; every rule that selects a message is
+// applied to it, in the order the rules were injected.
+type Fault struct {
+    Kind          string                    // Envelope.Kind() of the affected messages, e.g. "Prepare"; empty selects every type.
+    Match         func(*wire.Envelope) bool // Further narrows the affected messages, e.g. to heartbeats; nil selects all of Kind.
+    Drop          float64                   // Probability in [0, 1] that a message is lost.
+    Delay         Latency                   // Extra delay added to every message; nil adds none.
+    Duplicate     float64                   // Probability in [0, 1] that a message is delivered twice.
+    Reorder       float64                   // Probability in [0, 1] that a message is held back so that later ones overtake it.
+    ReorderWindow time.Duration             // Longest hold-back of a reordered message; defaults to 50ms.
+}
+
+// Heartbeat selects Raft AppendEntries messages that carry no entries, the leader's heartbeats.
+func Heartbeat(env *wire.Envelope) bool {
+    m := env.GetAppendEntries()
+    return m != nil && len(m.GetEntries()) == 0
+}
+
+// applies reports whether the rule selects env.
+func (f Fault) applies(env *wire.Envelope) bool {
+    if f.Kind != "" && f.Kind != env.Kind() {
+        return false
+    }
+    return f.Match == nil || f.Match(env)
+}
+
+// Inject adds a fault rule to the network. It affects messages sent from now on; messages already in flight
+// are not touched.
+func (n *Network) Inject(f Fault) {
+    n.faults = append(n.faults, f)
+}
+
+// ClearFaults removes every injected fault rule.
+func (n *Network) ClearFaults() {
+    n.faults = nil
+}
+
+// Slow makes a replica fail slow, the gray failure a crash-only model misses: it stays up, but takes processing
+// for every envelope and proposal it handles, one at a time, so its inputs queue up behind each other and its
+// replies and votes go out late, or ever later if they arrive faster than it works through them. Its ticks are
+// not delayed: its timers keep running and its heartbeats go out on time, as a node's heartbeat thread often
+// does while its disk or request handling crawls, so a failure detector listening for heartbeats sees nothing
+// wrong. A nil processing makes the replica healthy again; inputs already queued are still handled when due.
+// It returns ErrUnknownNode for a replica never added.
+func (s *Simulator) Slow(id int32, processing Latency) error {
+    n, ok := s.nodes[id]
+    if !ok {
+        return ErrUnknownNode
+    }
+    n.slow = processing
+    return nil
+}
+
+// queueFor returns when a slow replica will be done with a new input: after the inputs it has queued so far and
+// the time it takes for this one.
+func (s *Simulator) queueFor(n *simNode) time.Duration {
+    n.busy = max(n.busy, s.now) + n.slow.Sample(s.rng)
+    return n.busy
+}
+
+// hold queues an envelope for a slow replica, to be handled by queueFor, and reports whether it did. An envelope
+// that was already held, or one for a healthy replica, is left to be handled at once.
+func (s *Simulator) hold(id int32, ev event) bool {
+    n, ok := s.nodes[id]
+    if !ok || n.slow == nil || ev.held {
+        return false
+    }
+    ev.held = true
+    s.push(s.queueFor(n), ev)
+    return true
+}
+
+// Crash stops a replica, the failure a crash-only model does capture: it takes no ticks, every envelope
+// reaching it is dropped, proposals to it fail with node.ErrCrashed, and it sends nothing. Unlike a replica cut
+// off with Network.Disconnect, which keeps running and may go on believing it leads, a crashed one does nothing
+// at all. Envelopes it sent before crashing are still delivered. Crashing a crashed replica does nothing; it
+// returns ErrUnknownNode for a replica never added.
+func (s *Simulator) Crash(id int32) error {
+    n, ok := s.nodes[id]
+    if !ok {
+        return ErrUnknownNode
+    }
+    n.crashed = true
+    return nil
+}
+
+// Recover restarts a crashed replica where it stopped. Its state is kept in full, as if it had persisted
+// everything before crashing and restored it on restart; Raft requires that much of its term, vote and log, and
+// the rest is state the protocol corrects, such as a leader that does not yet know it has been replaced. It
+// returns node.ErrNotCrashed for a replica that is running, and ErrUnknownNode for one never added.
+func (s *Simulator) Recover(id int32) error {
+    n, ok := s.nodes[id]
+    if !ok {
+        return ErrUnknownNode
+    }
+    if !n.crashed {
+        return node.ErrNotCrashed
+    }
+    n.crashed = false
+    return nil
+}
+
+// Crashed reports whether a replica has crashed and not recovered.
+func (s *Simulator) Crashed(id int32) bool {
+    n, ok := s.nodes[id]
+    return ok && n.crashed
+}
+
+// disturb applies the injected faults to an envelope about to be sent over a link with the given delay.
+// It returns the delay of every copy to deliver: none if the envelope is dropped, two if it is duplicated.
+func (s *Simulator) disturb(env *wire.Envelope, delay time.Duration) []time.Duration {
+    copies := []time.Duration{delay}
+    for _, f := range s.Network.faults {
+        if !f.applies(env) {
+            continue
+        }
+        if f.Drop > 0 && s.rng.Float64() < f.Drop {
+            return nil
+        }
+        for i := range copies {
+            if f.Delay != nil {
+                copies[i] += f.Delay.Sample(s.rng)
+            }
+            if f.Reorder > 0 && s.rng.Float64() < f.Reorder {
+                window := f.ReorderWindow
+                if window <= 0 {
+                    window = defaultReorderWindow
+                }
+                copies[i] += Uniform(0, window).Sample(s.rng)
+            }
+        }
+        if f.Duplicate > 0 && s.rng.Float64() < f.Duplicate {
+            link := s.Network.Link(env.GetFrom(), env.GetTo())
+            duplicate := copies[0]
+            if link.Latency != nil {
+                duplicate += link.Latency.Sample(s.rng) - delay // The copy travels the link independently.
+            }
+            copies = append(copies, duplicate)
+        }
+    }
+    return copies
+}
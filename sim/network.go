@@ -0,0 +1,134 @@
+package sim
+
+import (
+    "math"
+    "math/rand"
+    "time"
+)
+
+// Latency is a distribution of one-way message delays.
+type Latency interface {
+    Sample(rng *rand.Rand) time.Duration // Draw one delay; never negative.
+}
+
+// constant is a Latency that always returns the same delay.
+type constant time.Duration
+
+// Constant returns a latency distribution that always yields d.
+func Constant(d time.Duration) Latency { return constant(d) }
+
+func (c constant) Sample(rng *rand.Rand) time.Duration { return time.Duration(c) }
+
+// uniform is a Latency drawn uniformly from [min, max].
+type uniform struct{ min, max time.Duration }
+
+// Uniform returns a latency distribution drawn uniformly from [min, max].
+func Uniform(min, max time.Duration) Latency { return uniform{min, max} }
+
+func (u uniform) Sample(rng *rand.Rand) time.Duration {
+    if u.max <= u.min {
+        return u.min
+    }
+    return u.min + time.Duration(rng.Int63n(int64(u.max-u.min)+1))
+}
+
+// normal is a Latency drawn from a normal distribution, truncated at zero.
+type normal struct{ mean, stddev time.Duration }
+
+// Normal returns a latency distribution with the given mean and standard deviation. Negative samples are
+// clamped to zero, so a large deviation makes some messages arrive instantly rather than before they were sent.
+func Normal(mean, stddev time.Duration) Latency { return normal{mean, stddev} }
+
+func (n normal) Sample(rng *rand.Rand) time.Duration {
+    return max(0, n.mean+time.Duration(rng.NormFloat64()*float64(n.stddev)))
+}
+
+// exponential is a Latency drawn from an exponential distribution.
+type exponential struct{ mean time.Duration }
+
+// Exponential returns a latency distribution with the given mean. Most messages are fast but a long tail of
+// slow ones remains, which is a common shape for congested networks.
+func Exponential(mean time.Duration) Latency { return exponential{mean} }
+
+func (e exponential) Sample(rng *rand.Rand) time.Duration {
+    return time.Duration(math.Round(rng.ExpFloat64() * float64(e.mean)))
+}
+
+// Link describes the behavior of messages sent from one node to another.
+type Link struct {
+    Latency Latency // Delay of each message; a link without latency delivers instantly.
+    Drop    float64 // Probability in [0, 1] that a message is lost.
+}
+
+// linkKey identifies a directed link.
+type linkKey struct{ from, to int32 }
+
+// Network is the model messages pass through in a simulation. Every directed link uses the default Link
+// unless it was configured individually, and links can be cut to model partitions: a message crossing a cut
+// link is dropped, whether it is sent after the cut or was already in flight when the cut happened.
+type Network struct {
+    Default Link                 // Behavior of every link that has not been configured with SetLink.
+    links   map[linkKey]Link     // Individually configured links.
+    cut     map[linkKey]struct{} // Links that currently deliver nothing.
+    faults  []Fault              // Injected per-message fault rules, applied in order.
+}
+
+// NewNetwork creates a network whose links all behave like def.
+func NewNetwork(def Link) *Network {
+    return &Network{
+        Default: def,
+        links:   make(map[linkKey]Link),
+        cut:     make(map[linkKey]struct{}),
+    }
+}
+
+// SetLink configures the directed link from one node to another.
+func (n *Network) SetLink(from, to int32, link Link) {
+    n.links[linkKey{from, to}] = link
+}
+
+// Link returns the behavior of the directed link from one node to another.
+func (n *Network) Link(from, to int32) Link {
+    if link, ok := n.links[linkKey{from, to}]; ok {
+        return link
+    }
+    return n.Default
+}
+
+// Disconnect cuts the links between a and b in both directions.
+func (n *Network) Disconnect(a, b int32) {
+    n.cut[linkKey{a, b}] = struct{}{}
+    n.cut[linkKey{b, a}] = struct{}{}
+}
+
+// Connect restores the links between a and b in both directions.
+func (n *Network) Connect(a, b int32) {
+    delete(n.cut, linkKey{a, b})
+    delete(n.cut, linkKey{b, a})
+}
+
+// Partition splits the network into groups that cannot reach each other: every link between nodes in
+// different groups is cut, while nodes in the same group keep talking. Nodes not listed in any group keep all
+// their links. Links cut earlier stay cut.
+func (n *Network) Partition(groups ...[]int32) {
+    for i, group := range groups {
+        for _, other := range groups[i+1:] {
+            for _, a := range group {
+                for _, b := range other {
+                    n.Disconnect(a, b)
+                }
+            }
+        }
+    }
+}
+
+// Heal restores every cut link, ending all partitions and undoing every Disconnect.
+func (n *Network) Heal() {
+    clear(n.cut)
+}
+
+// Connected reports whether messages from one node currently reach the other.
+func (n *Network) Connected(from, to int32) bool {
+    _, cut := n.cut[linkKey{from, to}]
+    return !cut
+}
@@ -0,0 +1,549 @@
+// Package sim runs consensus replicas in a discrete-event simulation.
+// Instead of real goroutines, sockets and clocks, a Simulator keeps a queue of future events — message
+// deliveries, clock ticks and scheduled actions — ordered by virtual time, and executes them one at a time.
+// Messages pass through a Network model with per-link latency distributions, loss rates and partitions, so
+// an algorithm's behavior on slow, lossy or split networks can be studied in milliseconds of real time. Every
+// random choice is drawn from a single seeded source, so a run can be repeated exactly.
+package sim
+
+import (
+    "container/heap"
+    "context"
+    "errors"
+    "fmt"
+    "math/rand"
+    "sort"
+    "strings"
+    "time"
+
+    "consensus-algorithms-edu/events"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/profile"
+    "consensus-algorithms-edu/wire"
+)
+
+// ErrUnknownNode is returned when an operation names a node that was never added to the simulator.
+var ErrUnknownNode = errors.New("sim: unknown node")
+
+// Config describes a simulation.
+type Config struct {
+    Seed         int64         // Seed of every random choice the simulator makes; the same seed replays the same run.
+    TickInterval time.Duration // Virtual time between two ticks of a replica; defaults to 10ms.
+    Network      Link          // Default behavior of every link; defaults to a constant 1ms latency without loss.
+    Gossip       *Gossip       // Spreads broadcasts hop by hop; nil sends every message over a direct link.
+    Discovery    *Discovery    // Builds a sparse topology that Gossip spreads over; nil connects every node to every other.
+    Start        time.Duration // Virtual time the simulation starts at, e.g. to continue a recorded run; defaults to 0.
+}
+
+// Stats counts what happened to the messages of a simulation.
+type Stats struct {
+    Sent       int // Envelopes produced by replicas.
+    Delivered  int // Envelopes handed to their recipient.
+    Dropped    int // Envelopes lost to link loss, injected faults, cut links or unknown recipients.
+    Duplicated int // Extra copies created by injected faults.
+    Redundant  int // Gossiped copies that reached a node which already had the message.
+}
+
+// Simulator executes replicas against a simulated network on a virtual clock. It is not safe for concurrent
+// use; the whole simulation runs on the goroutine that calls its methods.
+type Simulator struct {
+    Network *Network // Model every message passes through; may be reconfigured while the simulation runs.
+    Gossip  *Gossip  // Spreads broadcasts hop by hop if set; may be changed while the simulation runs.
+
+    // OnCommit, if set, is called once for every block a replica commits, in chain order.
+    OnCommit func(id int32, block *wire.Block)
+
+    // Events, if set, receives the activity of every replica as node.Runner reports it — proposals, votes,
+    // elections, leader changes, view changes and commits — stamped with virtual time. Algorithm labels them.
+    Events    events.Publisher
+    Algorithm string
+
+    // OnTransition, if set, is called every time a replica takes an input — a tick, a delivered envelope or a
+    // proposal — with the envelopes the replica sent in response. Package trace records runs through it.
+    OnTransition func(t Transition)
+
+    stepper      *Stepper  // Stepper driving the simulation, if any; it queues transitions for Next.
+    topology     *topology // Peer discovery's topology, or nil for a full mesh.
+    tickInterval time.Duration
+    rng          *rand.Rand
+    now          time.Duration
+    seq          uint64 // Tie-breaker that keeps events scheduled for the same instant in scheduling order.
+    queue        eventQueue
+    free         []*event // Executed events, reused by the next ones scheduled.
+    nodes        map[int32]*simNode
+    stats        Stats
+}
+
+// Input is the kind of input a replica takes in a Transition.
+type Input string
+
+const (
+    TickInput    Input = "tick"    // The replica's logical clock advanced.
+    DeliverInput Input = "deliver" // An envelope reached the replica.
+    ProposeInput Input = "propose" // Data was proposed at the replica.
+)
+
+// Transition is one input a replica took, at a point in virtual time, and what it sent in response.
+type Transition struct {
+    At       time.Duration    // Virtual time of the input.
+    Node     int32            // Replica that took the input.
+    Input    Input            // Kind of input.
+    Envelope *wire.Envelope   // Envelope delivered, for DeliverInput.
+    Data     string           // Data proposed, for ProposeInput.
+    Err      error            // Error the replica returned, for a rejected ProposeInput.
+    Output   []*wire.Envelope // Envelopes the replica produced, before the network decides their fate.
+}
+
+// String describes the transition in one line, e.g. "1.2s node-2 deliver AppendEntries from node-0, sent
+// [AppendEntriesResponse to 0]".
+func (t Transition) String() string {
+    var b strings.Builder
+    fmt.Fprintf(&b, "%v %s %s", t.At, node.Name(t.Node), t.Input)
+    switch t.Input {
+    case DeliverInput:
+        fmt.Fprintf(&b, " %s from %s", t.Envelope.Kind(), node.Name(t.Envelope.GetFrom()))
+    case ProposeInput:
+        fmt.Fprintf(&b, " %q", t.Data)
+    }
+    if t.Err != nil {
+        fmt.Fprintf(&b, ", rejected: %v", t.Err)
+    }
+    if len(t.Output) > 0 {
+        sent := make([]string, len(t.Output))
+        for i, env := range t.Output {
+            sent[i] = fmt.Sprintf("%s to %d", env.Kind(), env.GetTo())
+        }
+        fmt.Fprintf(&b, ", sent [%s]", strings.Join(sent, ", "))
+    }
+    return b.String()
+}
+
+// simNode is a replica together with the simulator's bookkeeping for it.
+type simNode struct {
+    replica   node.Replica
+    delivered int           // Number of committed blocks already passed to OnCommit.
+    leader    string        // Name of the last leader reported to Events, or "" if none was.
+    slow      Latency       // Time the replica takes for each input if it fails slow (see Slow); nil if healthy.
+    busy      time.Duration // Virtual time by which a slow replica will have handled every input queued so far.
+    crashed   bool          // The replica is down (see Crash): it takes no input and sends nothing.
+}
+
+// New creates an empty simulation.
+func New(cfg Config) *Simulator {
+    if cfg.TickInterval <= 0 {
+        cfg.TickInterval = 10 * time.Millisecond
+    }
+    if cfg.Network.Latency == nil {
+        cfg.Network.Latency = Constant(time.Millisecond)
+    }
+    s := &Simulator{
+        Network:      NewNetwork(cfg.Network),
+        Gossip:       cfg.Gossip,
+        tickInterval: cfg.TickInterval,
+        rng:          rand.New(rand.NewSource(cfg.Seed)),
+        now:          max(cfg.Start, 0),
+        nodes:        make(map[int32]*simNode),
+    }
+    if cfg.Discovery != nil {
+        s.topology = newTopology(*cfg.Discovery)
+        if s.Gossip == nil {
+            s.Gossip = &Gossip{} // A topology means nothing to broadcasts sent over direct links.
+        }
+    }
+    return s
+}
+
+// NewRand returns a random source derived from the simulation's seed. Replicas that need randomness, such as
+// Raft's election timeouts, should be given one so that the whole run depends on the seed alone.
+func (s *Simulator) NewRand() *rand.Rand {
+    return rand.New(rand.NewSource(s.rng.Int63()))
+}
+
+// Add puts a replica into the simulation. Its first tick happens after a random fraction of the tick
+// interval, so replicas do not tick in lockstep. With Discovery, its first discovery round is offset likewise.
+func (s *Simulator) Add(replica node.Replica) {
+    id := replica.ID()
+    s.nodes[id] = &simNode{replica: replica}
+    s.push(s.now+time.Duration(s.rng.Int63n(int64(s.tickInterval))), event{kind: tickEvent, node: id})
+    if s.topology != nil {
+        s.topology.join(id)
+        s.push(s.now+time.Duration(s.rng.Int63n(int64(s.topology.cfg.Interval))), event{kind: discoverEvent, node: id})
+    }
+}
+
+// Now returns the current virtual time, measured from the start of the simulation.
+func (s *Simulator) Now() time.Duration {
+    return s.now
+}
+
+// Nodes returns the IDs of every replica in the simulation, in ascending order.
+func (s *Simulator) Nodes() []int32 {
+    ids := make([]int32, 0, len(s.nodes))
+    for id := range s.nodes {
+        ids = append(ids, id)
+    }
+    sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+    return ids
+}
+
+// Replica returns the replica with the given ID, or nil if there is none.
+func (s *Simulator) Replica(id int32) node.Replica {
+    if n, ok := s.nodes[id]; ok {
+        return n.replica
+    }
+    return nil
+}
+
+// Stats returns message statistics for the simulation so far.
+func (s *Simulator) Stats() Stats {
+    return s.stats
+}
+
+// Propose submits data to a replica at the current virtual time and sends the resulting envelopes. A replica
+// that fails slow (see Slow) takes the data once it has worked through its earlier inputs; the error it may
+// return is then reported to OnTransition only. A crashed replica (see Crash) refuses the data with
+// node.ErrCrashed.
+func (s *Simulator) Propose(id int32, data string) error {
+    n, ok := s.nodes[id]
+    if !ok {
+        return ErrUnknownNode
+    }
+    if n.slow != nil && !n.crashed {
+        s.schedule(s.queueFor(n), func() { s.propose(id, n, data) })
+        return nil
+    }
+    return s.propose(id, n, data)
+}
+
+// propose submits data to a replica now.
+func (s *Simulator) propose(id int32, n *simNode, data string) error {
+    if n.crashed {
+        return node.ErrCrashed
+    }
+    span := profile.Begin(profile.Handling)
+    out, err := n.replica.Propose(data)
+    span.End()
+    s.transition(Transition{Node: id, Input: ProposeInput, Data: data, Err: err, Output: out})
+    if err == nil {
+        s.emit(events.Event{Type: events.Proposal, Node: node.Name(id), Data: data})
+    }
+    s.handle(id, out)
+    return err
+}
+
+// At schedules fn to run when the virtual clock reaches at. Actions scheduled in the past run next.
+// Use it to script a scenario: proposals, partitions or link changes at chosen moments.
+func (s *Simulator) At(at time.Duration, fn func()) {
+    s.schedule(max(at, s.now), fn)
+}
+
+// Deliver schedules env to reach its recipient at virtual time at, as if it had already been sent and the
+// network had decided its latency. It is not counted as sent and passes no link or fault rule, but a link cut
+// by then still drops it. Use it to put the messages of a recorded run that were in flight back on the network.
+func (s *Simulator) Deliver(env *wire.Envelope, at time.Duration) {
+    s.push(max(at, s.now), event{kind: deliverEvent, env: env})
+}
+
+// Step executes the next event and reports whether there was one.
+func (s *Simulator) Step() bool {
+    if s.queue.Len() == 0 {
+        return false
+    }
+    defer profile.Begin(profile.Simulation).End()
+    next := heap.Pop(&s.queue).(*event)
+    ev := *next
+    *next = event{}
+    s.free = append(s.free, next)
+    s.now = ev.at
+    switch ev.kind {
+    case callEvent:
+        ev.fn()
+    case tickEvent:
+        s.tick(ev.node)
+    case discoverEvent:
+        s.discover(ev.node)
+    case deliverEvent:
+        if !s.hold(ev.env.GetTo(), ev) {
+            s.deliver(ev.env)
+        }
+    case hearEvent:
+        if !s.hold(ev.env.GetTo(), ev) {
+            s.hear(ev.rumor, ev.env)
+        }
+    case relayEvent:
+        if !s.nodes[ev.env.GetTo()].crashed {
+            s.relay(ev.rumor, ev.env.GetTo(), ev.env.GetFrom())
+        }
+    }
+    return true
+}
+
+// RunFor executes every event scheduled within the next d of virtual time and then advances the clock to
+// the end of that window.
+func (s *Simulator) RunFor(d time.Duration) {
+    s.RunForContext(context.Background(), d)
+}
+
+// RunForContext is RunFor that stops once ctx is done, returning the context's error. A simulation runs far
+// faster than real time, so the context bounds the real time spent on it, not the virtual time simulated. The
+// clock is left at the last event executed, and the simulation can be resumed from there.
+func (s *Simulator) RunForContext(ctx context.Context, d time.Duration) error {
+    end := s.now + d
+    for s.queue.Len() > 0 && s.queue[0].at <= end {
+        if err := ctx.Err(); err != nil {
+            return err
+        }
+        s.Step()
+    }
+    s.now = end
+    return nil
+}
+
+// RunUntil executes events until cond returns true or the virtual clock passes limit, and reports whether
+// cond was met. cond is checked before the first event and after every event.
+func (s *Simulator) RunUntil(cond func() bool, limit time.Duration) bool {
+    met, _ := s.RunUntilContext(context.Background(), cond, limit)
+    return met
+}
+
+// RunUntilContext is RunUntil that stops once ctx is done, reporting that cond was not met together with the
+// context's error.
+func (s *Simulator) RunUntilContext(ctx context.Context, cond func() bool, limit time.Duration) (bool, error) {
+    for !cond() {
+        if err := ctx.Err(); err != nil {
+            return false, err
+        }
+        if s.queue.Len() == 0 || s.queue[0].at > limit {
+            return false, nil
+        }
+        s.Step()
+    }
+    return true, nil
+}
+
+// tick advances a replica's logical clock and schedules its next tick. A crashed replica's clock stands still.
+func (s *Simulator) tick(id int32) {
+    if s.nodes[id].crashed {
+        s.push(s.now+s.tickInterval, event{kind: tickEvent, node: id})
+        return
+    }
+    span := profile.Begin(profile.Handling)
+    out := s.nodes[id].replica.Tick()
+    span.End()
+    s.transition(Transition{Node: id, Input: TickInput, Output: out})
+    s.handle(id, out)
+    s.push(s.now+s.tickInterval, event{kind: tickEvent, node: id})
+}
+
+// handle reports the activity and new commits of a replica and puts the envelopes it produced on the network.
+func (s *Simulator) handle(id int32, out []*wire.Envelope) {
+    s.publish(id, out)
+    s.notify(id)
+    if s.Gossip != nil {
+        var rumors []*rumor
+        rumors, out = s.rumors(id, out)
+        for _, r := range rumors {
+            s.relay(r, id, id)
+        }
+    }
+    for _, env := range out {
+        s.transmit(env, event{kind: deliverEvent, env: env})
+    }
+}
+
+// transmit passes an envelope through the network model and its injected faults, and schedules arrive for the
+// time each copy reaches the recipient.
+func (s *Simulator) transmit(env *wire.Envelope, arrive event) {
+    s.stats.Sent++
+    from, to := env.GetFrom(), env.GetTo()
+    link := s.Network.Link(from, to)
+    if _, ok := s.nodes[to]; !ok || !s.Network.Connected(from, to) || s.rng.Float64() < link.Drop {
+        s.stats.Dropped++
+        return
+    }
+
+    delay := time.Duration(0)
+    if link.Latency != nil {
+        delay = link.Latency.Sample(s.rng)
+    }
+    copies := s.disturb(env, delay)
+    if len(copies) == 0 {
+        s.stats.Dropped++
+        return
+    }
+    s.stats.Duplicated += len(copies) - 1
+    for _, delay := range copies {
+        s.push(s.now+delay, arrive)
+    }
+}
+
+// deliver hands an envelope to its recipient, unless the link was cut while the envelope was in flight or the
+// recipient has crashed.
+func (s *Simulator) deliver(env *wire.Envelope) {
+    if !s.Network.Connected(env.GetFrom(), env.GetTo()) || s.nodes[env.GetTo()].crashed {
+        s.stats.Dropped++
+        return
+    }
+    s.step(env)
+}
+
+// step hands an envelope that reached its recipient to the replica.
+func (s *Simulator) step(env *wire.Envelope) {
+    s.stats.Delivered++
+    span := profile.Begin(profile.Handling)
+    out := s.nodes[env.GetTo()].replica.Step(env)
+    span.End()
+    s.transition(Transition{Node: env.GetTo(), Input: DeliverInput, Envelope: env, Output: out})
+    s.handle(env.GetTo(), out)
+}
+
+// transition stamps t with the current virtual time and passes it to OnTransition and the stepper, if set.
+func (s *Simulator) transition(t Transition) {
+    t.At = s.now
+    if s.OnTransition != nil {
+        s.OnTransition(t)
+    }
+    if s.stepper != nil {
+        s.stepper.queue(t)
+    }
+}
+
+// notify passes a replica's newly committed blocks to OnCommit and Events.
+func (s *Simulator) notify(id int32) {
+    n := s.nodes[id]
+    committed := n.replica.Committed()
+    for ; n.delivered < len(committed); n.delivered++ {
+        block := committed[n.delivered]
+        if s.OnCommit != nil {
+            s.OnCommit(id, block)
+        }
+        s.emit(events.Event{Type: events.Commit, Node: node.Name(id), Height: block.GetIndex(), Hash: block.GetHash(), Data: block.GetData()})
+    }
+}
+
+// publish reports the events found in a replica's outgoing envelopes, and a change of its leader, to Events.
+func (s *Simulator) publish(id int32, out []*wire.Envelope) {
+    if s.Events == nil {
+        return
+    }
+    for _, event := range events.FromEnvelopes(s.Algorithm, node.Name(id), out) {
+        s.emit(event)
+    }
+    n := s.nodes[id]
+    if replica, ok := n.replica.(node.Leaderful); ok {
+        if leader := replica.Leader(); leader >= 0 && node.Name(leader) != n.leader {
+            n.leader = node.Name(leader)
+            s.emit(events.Event{Type: events.LeaderChange, Node: node.Name(id), Leader: n.leader})
+        }
+    }
+}
+
+// emit labels an event with the algorithm and the current virtual time and publishes it to Events, if set.
+func (s *Simulator) emit(event events.Event) {
+    if s.Events == nil {
+        return
+    }
+    event.Algorithm = s.Algorithm
+    event.Time = s.Clock().Now()
+    s.Events.Publish(event)
+}
+
+// schedule queues fn to run at virtual time at.
+func (s *Simulator) schedule(at time.Duration, fn func()) {
+    s.push(at, event{kind: callEvent, fn: fn})
+}
+
+// push queues ev to run at virtual time at, in the storage of an executed event if one is free.
+func (s *Simulator) push(at time.Duration, ev event) {
+    var next *event
+    if n := len(s.free); n > 0 {
+        next, s.free = s.free[n-1], s.free[:n-1]
+    } else {
+        next = new(event)
+    }
+    s.seq++
+    ev.at, ev.seq = at, s.seq
+    *next = ev
+    heap.Push(&s.queue, next)
+}
+
+// eventKind is what an event does when it runs.
+type eventKind int
+
+const (
+    callEvent     eventKind = iota // Call fn.
+    tickEvent                      // Tick the replica node.
+    discoverEvent                  // Run a discovery round at node.
+    deliverEvent                   // Deliver env over a direct link.
+    hearEvent                      // Let env's recipient hear rumor over one hop.
+    relayEvent                     // Forward rumor from env's recipient, which heard it from env's sender.
+)
+
+// event is an action scheduled at a point in virtual time. The simulator's own events are data rather than
+// closures, and their storage is reused once they have run, so a large simulation does not allocate an event
+// for every message it sends.
+type event struct {
+    at    time.Duration
+    seq   uint64
+    kind  eventKind
+    node  int32          // Replica of a tickEvent or discoverEvent.
+    env   *wire.Envelope // Envelope of a deliverEvent, or hop of a hearEvent or relayEvent.
+    rumor *rumor         // Rumor of a hearEvent or relayEvent.
+    fn    func()         // Action of a callEvent.
+    held  bool           // Set once a slow replica has been held back for the input (see Simulator.Slow).
+}
+
+// eventQueue is a min-heap of events ordered by time, then by scheduling order.
+type eventQueue []*event
+
+func (q eventQueue) Len() int { return len(q) }
+
+func (q eventQueue) Less(i, j int) bool {
+    if q[i].at != q[j].at {
+        return q[i].at < q[j].at
+    }
+    return q[i].seq < q[j].seq
+}
+
+func (q eventQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *eventQueue) Push(x any) { *q = append(*q, x.(*event)) }
+
+func (q *eventQueue) Pop() any {
+    old := *q
+    ev := old[len(old)-1]
+    *q = old[:len(old)-1]
+    return ev
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Virtual Time**: Nothing in a simulation waits. The clock jumps straight to the next scheduled event, so a
+//    minute of simulated elections and heartbeats takes milliseconds, and timing never depends on how busy the
+//    machine running the simulation is.
+//
+// 2. **One Seeded Source**: Link latencies, message loss, tick offsets and the random sources handed to replicas
+//    through NewRand are all drawn from the generator created from Config.Seed, and events scheduled for the
+//    same instant run in the order they were scheduled. Replicas that also take their block timestamps from Clock
+//    are deterministic, so the same seed produces a bit-identical run.
+//
+// 3. **Same Replicas as Production**: The simulator drives the node.Replica interface, exactly like node.Runner
+//    does over real transports. Behavior observed in a simulation is therefore the behavior of the code that
+//    runs across processes, not of a separate model.
+//
+// 4. **Partitions Drop In-Flight Messages**: Cutting a link also discards messages already travelling over it.
+//    This is the behavior most scenarios expect — after a split, the two sides stop hearing from each other
+//    immediately — and it keeps partitions easy to reason about.
+//
+// 5. **Faults Are Decided at Send Time**: Injected Fault rules are applied when a message is sent: whether it is
+//    lost, how long it is held back and whether a copy follows are all drawn from the seeded source right away.
+//    Injecting or clearing a rule therefore never changes the fate of messages already in flight.
+//
+// 6. **Events as Data**: A tick or a delivery is a record of what to do, not a closure, and executed events go to a
+//    free list that the next ones are taken from. A simulation of thousands of nodes schedules millions of events,
+//    and recycling them keeps the garbage collector out of the way; At still takes any function.
+//
+// 7. **Slowness Is a Queue**: A slow replica is a single server with a queue, not a longer link. Its inputs wait
+//    for the ones before it, so a node that receives faster than it works falls further and further behind, which
+//    is how a gray failure turns a small slowdown into an outage. Its ticks bypass the queue, because the point of
+//    the model is a node that looks healthy to a failure detector.
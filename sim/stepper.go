@@ -0,0 +1,55 @@
+package sim
+
+import (
+    "time"
+)
+
+// Stepper runs a simulation one transition at a time, pausing whenever a replica takes an input worth looking
+// at. It turns a simulation into something that can be walked through live: each call to Next shows one
+// vote, acknowledgement or view change, and the cluster stands still until the next call. The simulation can
+// still be scripted and run directly in between; transitions that happen meanwhile are queued for Next.
+type Stepper struct {
+    // Pause selects the transitions Next stops at; Significant if nil. Return true for every transition to
+    // also stop at ticks that send nothing.
+    Pause func(t Transition) bool
+
+    s       *Simulator
+    pending []Transition // Transitions that happened but were not returned by Next yet.
+}
+
+// NewStepper attaches a stepper to s. A simulation has at most one stepper; a second replaces the first.
+func NewStepper(s *Simulator) *Stepper {
+    st := &Stepper{s: s}
+    s.stepper = st
+    return st
+}
+
+// Significant reports whether a transition changes something another replica can see: every delivery and
+// proposal, and the ticks on which a replica sends something, such as a timeout that starts an election or a
+// leader's heartbeat. Ticks that only count down a timer are not significant.
+func Significant(t Transition) bool {
+    return t.Input != TickInput || len(t.Output) > 0
+}
+
+// Next returns the next transition the stepper pauses at, executing events until one happens. It gives up
+// and reports false once the virtual clock would pass within from now; the clock then stays at the last
+// event executed, so Next can be called again.
+func (st *Stepper) Next(within time.Duration) (Transition, bool) {
+    if !st.s.RunUntil(func() bool { return len(st.pending) > 0 }, st.s.Now()+within) {
+        return Transition{}, false
+    }
+    t := st.pending[0]
+    st.pending = st.pending[1:]
+    return t, true
+}
+
+// queue keeps a transition for Next if the stepper pauses at it.
+func (st *Stepper) queue(t Transition) {
+    pause := st.Pause
+    if pause == nil {
+        pause = Significant
+    }
+    if pause(t) {
+        st.pending = append(st.pending, t)
+    }
+}
@@ -0,0 +1,150 @@
+// Package stats measures how fast a run commits blocks: the latency of every block, from the moment its data
+// was submitted to the moment it was committed, and the throughput sustained over the whole run. A Recorder
+// collects the measurements as blocks are committed, and its Summary reduces them to percentiles, which say
+// more about a consensus algorithm than an average: a Raft round is usually one heartbeat away, but the one
+// that waits for an election is what a client notices.
+//
+// engine.Measure records every block an engine commits, `consensus run` prints the summary after a run and
+// `consensus bench` prints one row of it per algorithm. Code that drives replicas itself can call Record
+// directly with the times it observed, real or virtual.
+package stats
+
+import (
+    "fmt"
+    "math"
+    "slices"
+    "sync"
+    "time"
+)
+
+// Percentiles are the percentiles a Latency reports.
+var Percentiles = []float64{50, 90, 99}
+
+// Recorder collects the commit latency of blocks. A nil *Recorder ignores every observation, so callers can
+// record unconditionally. Recorder is safe for concurrent use.
+type Recorder struct {
+    mu        sync.Mutex
+    latencies []time.Duration
+    first     time.Time // Earliest submission recorded.
+    last      time.Time // Latest commit recorded.
+}
+
+// NewRecorder creates an empty recorder.
+func NewRecorder() *Recorder {
+    return &Recorder{}
+}
+
+// Record counts a block whose data was submitted at submitted and committed at committed.
+func (r *Recorder) Record(submitted, committed time.Time) {
+    if r == nil {
+        return
+    }
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.latencies = append(r.latencies, committed.Sub(submitted))
+    if r.first.IsZero() || submitted.Before(r.first) {
+        r.first = submitted
+    }
+    if committed.After(r.last) {
+        r.last = committed
+    }
+}
+
+// Reset forgets every block recorded so far, so the next Summary covers only what follows.
+func (r *Recorder) Reset() {
+    if r == nil {
+        return
+    }
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.latencies, r.first, r.last = nil, time.Time{}, time.Time{}
+}
+
+// Summary reduces the blocks recorded so far to their latency percentiles and throughput.
+func (r *Recorder) Summary() Summary {
+    if r == nil {
+        return Summary{}
+    }
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    s := Summary{Blocks: len(r.latencies), Latency: Summarize(r.latencies)}
+    if s.Blocks > 0 {
+        s.Elapsed = r.last.Sub(r.first)
+    }
+    if s.Elapsed > 0 {
+        s.Throughput = float64(s.Blocks) / s.Elapsed.Seconds()
+    }
+    return s
+}
+
+// Summary describes the blocks of a run.
+type Summary struct {
+    Blocks     int           // Blocks committed.
+    Elapsed    time.Duration // From the first submission to the last commit.
+    Throughput float64       // Blocks committed per second of Elapsed; 0 if Elapsed is.
+    Latency    Latency
+}
+
+// String writes the summary on one line, e.g. "10 blocks in 1.2s (8.3 blocks/s), latency p50 110ms ...".
+func (s Summary) String() string {
+    return fmt.Sprintf("%d blocks in %v (%.1f blocks/s), latency %v", s.Blocks, s.Elapsed.Round(time.Microsecond), s.Throughput, s.Latency)
+}
+
+// Latency is the distribution of commit latencies.
+type Latency struct {
+    Min         time.Duration
+    Mean        time.Duration
+    Percentiles []time.Duration // One per entry of Percentiles, in the same order.
+    Max         time.Duration
+}
+
+// Summarize computes the distribution of latencies, which it leaves unchanged. Percentiles use the
+// nearest-rank method, so each one is a latency that was actually observed. The zero Latency describes no
+// latencies.
+func Summarize(latencies []time.Duration) Latency {
+    if len(latencies) == 0 {
+        return Latency{}
+    }
+    sorted := slices.Sorted(slices.Values(latencies))
+    var total time.Duration
+    for _, latency := range sorted {
+        total += latency
+    }
+    l := Latency{Min: sorted[0], Mean: total / time.Duration(len(sorted)), Max: sorted[len(sorted)-1]}
+    for _, p := range Percentiles {
+        rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1 // The smallest latency at or above p percent of them.
+        l.Percentiles = append(l.Percentiles, sorted[max(rank, 0)])
+    }
+    return l
+}
+
+// Percentile returns the latency at percentile p, which must be one of Percentiles, or 0 if it is not.
+func (l Latency) Percentile(p float64) time.Duration {
+    i := slices.Index(Percentiles, p)
+    if i < 0 || i >= len(l.Percentiles) {
+        return 0
+    }
+    return l.Percentiles[i]
+}
+
+// String writes the distribution on one line, e.g. "p50 1ms p90 4ms p99 9ms (min 800µs, mean 2ms, max 12ms)".
+func (l Latency) String() string {
+    line := ""
+    for _, p := range Percentiles {
+        line += fmt.Sprintf("p%g %v ", p, l.Percentile(p).Round(time.Microsecond))
+    }
+    return line + fmt.Sprintf("(min %v, mean %v, max %v)", l.Min.Round(time.Microsecond), l.Mean.Round(time.Microsecond), l.Max.Round(time.Microsecond))
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Percentiles Over Averages**: Consensus latency has a long tail — elections, view changes and unlucky
+//    mining — and a mean hides it. The summary leads with p50, p90 and p99 and keeps the mean only for
+//    comparison.
+//
+// 2. **Every Latency Kept**: The recorder keeps each latency rather than a histogram, so percentiles are exact.
+//    Runs in this repository commit thousands of blocks at most; a histogram, as package metrics keeps for
+//    Prometheus, would only be needed for runs that never end.
+//
+// 3. **Times Passed In**: Record takes the submission and commit times instead of reading a clock, so the same
+//    recorder measures real runs in wall-clock time and simulations in virtual time.
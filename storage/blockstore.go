@@ -0,0 +1,82 @@
+package storage
+
+import (
+    "errors"
+    "sync"
+
+    "consensus-algorithms-edu/wire"
+)
+
+// ErrBlockNotFound is returned by a BlockStore when no block matches the requested index or hash.
+var ErrBlockNotFound = errors.New("storage: block not found")
+
+// BlockStore keeps individual blocks addressable by index and by hash.
+// Unlike Store, which saves whole chains at once, a BlockStore is written block by block as the chain grows,
+// so a large chain does not have to be held in memory to be queried and hash lookups do not require a scan.
+//
+// Put indexes a block under its hash and, in the index map, replaces whatever block previously occupied the
+// same index. Blocks that are replaced at an index stay reachable by hash, which keeps fork branches retrievable.
+type BlockStore interface {
+    Put(block *wire.Block) error                   // Store the block under its index and its hash.
+    GetByIndex(index int64) (*wire.Block, error)   // Block currently at the given index, or ErrBlockNotFound.
+    GetByHash(hash string) (*wire.Block, error)    // Block with the given hash, or ErrBlockNotFound.
+    Len() (int, error)                             // Number of indexes currently occupied.
+    Close() error                                  // Release any resources held by the store.
+}
+
+// MemoryBlockStore is a BlockStore backed by two Go maps.
+// It is safe for concurrent use and is mostly useful in tests and short simulations.
+type MemoryBlockStore struct {
+    mu      sync.RWMutex
+    byIndex map[int64]*wire.Block  // Index -> block currently at that index.
+    byHash  map[string]*wire.Block // Hash -> block, including blocks replaced at their index.
+}
+
+// NewMemoryBlockStore creates an empty in-memory block store.
+func NewMemoryBlockStore() *MemoryBlockStore {
+    return &MemoryBlockStore{
+        byIndex: make(map[int64]*wire.Block),
+        byHash:  make(map[string]*wire.Block),
+    }
+}
+
+// Put stores the block under its index and hash.
+func (s *MemoryBlockStore) Put(block *wire.Block) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.byIndex[block.GetIndex()] = block
+    s.byHash[block.GetHash()] = block
+    return nil
+}
+
+// GetByIndex returns the block currently stored at index.
+func (s *MemoryBlockStore) GetByIndex(index int64) (*wire.Block, error) {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    if block, ok := s.byIndex[index]; ok {
+        return block, nil
+    }
+    return nil, ErrBlockNotFound
+}
+
+// GetByHash returns the block with the given hash.
+func (s *MemoryBlockStore) GetByHash(hash string) (*wire.Block, error) {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    if block, ok := s.byHash[hash]; ok {
+        return block, nil
+    }
+    return nil, ErrBlockNotFound
+}
+
+// Len returns the number of occupied indexes.
+func (s *MemoryBlockStore) Len() (int, error) {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    return len(s.byIndex), nil
+}
+
+// Close is a no-op for the in-memory store.
+func (s *MemoryBlockStore) Close() error {
+    return nil
+}
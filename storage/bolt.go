@@ -0,0 +1,125 @@
+//go:build !js
+
+// The bbolt database needs memory-mapped files, which a browser does not have, so the WebAssembly build leaves
+// BoltBlockStore out; MemoryBlockStore and FileStore remain.
+
+package storage
+
+import (
+    "encoding/binary"
+    "fmt"
+
+    bolt "go.etcd.io/bbolt"
+    "google.golang.org/protobuf/proto"
+
+    "consensus-algorithms-edu/wire"
+)
+
+var (
+    blocksBucket = []byte("blocks") // Hash -> protobuf-encoded wire.Block.
+    indexBucket  = []byte("index")  // Big-endian index -> hash of the block at that index.
+)
+
+// BoltBlockStore is a BlockStore backed by a bbolt key-value database file.
+// Blocks are kept on disk in one bucket keyed by hash, and a second bucket maps each index to a hash,
+// so both kinds of lookup are a single B+tree search no matter how long the chain grows.
+type BoltBlockStore struct {
+    db *bolt.DB
+}
+
+// OpenBoltBlockStore opens (or creates) the bbolt database at path and prepares its buckets.
+func OpenBoltBlockStore(path string) (*BoltBlockStore, error) {
+    db, err := bolt.Open(path, 0o600, nil)
+    if err != nil {
+        return nil, fmt.Errorf("storage: open %s: %w", path, err)
+    }
+
+    err = db.Update(func(tx *bolt.Tx) error {
+        if _, err := tx.CreateBucketIfNotExists(blocksBucket); err != nil {
+            return err
+        }
+        _, err := tx.CreateBucketIfNotExists(indexBucket)
+        return err
+    })
+    if err != nil {
+        db.Close()
+        return nil, fmt.Errorf("storage: init %s: %w", path, err)
+    }
+    return &BoltBlockStore{db: db}, nil
+}
+
+// Put stores the block under its hash and points its index at it, in a single transaction.
+func (s *BoltBlockStore) Put(block *wire.Block) error {
+    data, err := proto.Marshal(block)
+    if err != nil {
+        return fmt.Errorf("storage: encode block %d: %w", block.GetIndex(), err)
+    }
+    return s.db.Update(func(tx *bolt.Tx) error {
+        hash := []byte(block.GetHash())
+        if err := tx.Bucket(blocksBucket).Put(hash, data); err != nil {
+            return err
+        }
+        return tx.Bucket(indexBucket).Put(indexKey(block.GetIndex()), hash)
+    })
+}
+
+// GetByIndex returns the block currently stored at index.
+func (s *BoltBlockStore) GetByIndex(index int64) (*wire.Block, error) {
+    var block *wire.Block
+    err := s.db.View(func(tx *bolt.Tx) error {
+        hash := tx.Bucket(indexBucket).Get(indexKey(index))
+        if hash == nil {
+            return ErrBlockNotFound
+        }
+        var err error
+        block, err = decodeBlock(tx.Bucket(blocksBucket).Get(hash))
+        return err
+    })
+    return block, err
+}
+
+// GetByHash returns the block with the given hash.
+func (s *BoltBlockStore) GetByHash(hash string) (*wire.Block, error) {
+    var block *wire.Block
+    err := s.db.View(func(tx *bolt.Tx) error {
+        var err error
+        block, err = decodeBlock(tx.Bucket(blocksBucket).Get([]byte(hash)))
+        return err
+    })
+    return block, err
+}
+
+// Len returns the number of occupied indexes.
+func (s *BoltBlockStore) Len() (int, error) {
+    n := 0
+    err := s.db.View(func(tx *bolt.Tx) error {
+        n = tx.Bucket(indexBucket).Stats().KeyN
+        return nil
+    })
+    return n, err
+}
+
+// Close closes the underlying database file.
+func (s *BoltBlockStore) Close() error {
+    return s.db.Close()
+}
+
+// indexKey encodes an index as a big-endian key so that bbolt iterates blocks in chain order.
+func indexKey(index int64) []byte {
+    key := make([]byte, 8)
+    binary.BigEndian.PutUint64(key, uint64(index))
+    return key
+}
+
+// decodeBlock decodes a stored block. The byte slice is only valid inside the transaction,
+// so it is unmarshalled into a fresh message before the transaction ends.
+func decodeBlock(data []byte) (*wire.Block, error) {
+    if data == nil {
+        return nil, ErrBlockNotFound
+    }
+    block, err := wire.DecodeBlock(data)
+    if err != nil {
+        return nil, fmt.Errorf("storage: decode block: %w", err)
+    }
+    return block, nil
+}
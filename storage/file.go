@@ -0,0 +1,95 @@
+package storage
+
+import (
+    "errors"
+    "fmt"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "strings"
+
+    "google.golang.org/protobuf/encoding/protojson"
+
+    "consensus-algorithms-edu/wire"
+)
+
+// FileStore is a Store that keeps each chain in its own JSON file inside a directory.
+// The files are human-readable, which makes them convenient for inspecting runs and handing them out as fixtures.
+type FileStore struct {
+    Dir string // Directory that holds one <name>.json file per saved chain.
+}
+
+// NewFileStore creates a FileStore rooted at dir, creating the directory if it does not exist yet.
+func NewFileStore(dir string) (*FileStore, error) {
+    if err := os.MkdirAll(dir, 0o755); err != nil {
+        return nil, fmt.Errorf("storage: create %s: %w", dir, err)
+    }
+    return &FileStore{Dir: dir}, nil
+}
+
+// Save writes the chain to <Dir>/<name>.json.
+// The data is first written to a temporary file, synced, and then renamed over the old file,
+// so a crash in the middle of Save never leaves a truncated chain behind.
+func (s *FileStore) Save(name string, chain *wire.Chain) error {
+    path, err := s.path(name)
+    if err != nil {
+        return err
+    }
+
+    data, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(chain)
+    if err != nil {
+        return fmt.Errorf("storage: encode %s: %w", name, err)
+    }
+
+    tmp, err := os.CreateTemp(s.Dir, name+".*.tmp") // Temporary file in the same directory so the rename is atomic.
+    if err != nil {
+        return fmt.Errorf("storage: save %s: %w", name, err)
+    }
+    defer os.Remove(tmp.Name()) // No-op once the rename has succeeded.
+
+    if _, err := tmp.Write(data); err != nil {
+        tmp.Close()
+        return fmt.Errorf("storage: save %s: %w", name, err)
+    }
+    if err := tmp.Sync(); err != nil { // Make sure the bytes reach the disk before the file becomes visible.
+        tmp.Close()
+        return fmt.Errorf("storage: save %s: %w", name, err)
+    }
+    if err := tmp.Close(); err != nil {
+        return fmt.Errorf("storage: save %s: %w", name, err)
+    }
+    if err := os.Rename(tmp.Name(), path); err != nil {
+        return fmt.Errorf("storage: save %s: %w", name, err)
+    }
+    return nil
+}
+
+// Load reads the chain saved under name. It returns ErrNotFound if no such chain exists.
+func (s *FileStore) Load(name string) (*wire.Chain, error) {
+    path, err := s.path(name)
+    if err != nil {
+        return nil, err
+    }
+
+    data, err := os.ReadFile(path)
+    if errors.Is(err, fs.ErrNotExist) {
+        return nil, ErrNotFound
+    }
+    if err != nil {
+        return nil, fmt.Errorf("storage: load %s: %w", name, err)
+    }
+
+    chain, err := wire.DecodeChainJSON(data)
+    if err != nil {
+        return nil, fmt.Errorf("storage: decode %s: %w", name, err)
+    }
+    return chain, nil
+}
+
+// path maps a chain name to its file, rejecting names that would escape the store's directory.
+func (s *FileStore) path(name string) (string, error) {
+    if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+        return "", fmt.Errorf("storage: invalid chain name %q", name)
+    }
+    return filepath.Join(s.Dir, name+".json"), nil
+}
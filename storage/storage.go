@@ -0,0 +1,33 @@
+// Package storage persists blockchains so they survive process restarts.
+// Any algorithm in this repository can save its chain through the Store interface and reload it later,
+// which makes crash-recovery demonstrations and long-running experiments possible. Chains are stored in
+// the algorithm-neutral wire format, so a chain written by one tool can be inspected by another.
+package storage
+
+import (
+    "errors"
+
+    "consensus-algorithms-edu/wire"
+)
+
+// ErrNotFound is returned by Load when no chain has been saved under the requested name.
+var ErrNotFound = errors.New("storage: chain not found")
+
+// Store persists complete chains under a name chosen by the caller.
+// Implementations must make Save atomic: after a crash, Load returns either the previous chain or the new one,
+// never a partially written mix of both.
+type Store interface {
+    Save(name string, chain *wire.Chain) error // Persist the chain, replacing any chain saved under the same name.
+    Load(name string) (*wire.Chain, error)     // Load a previously saved chain, or return ErrNotFound.
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Whole-Chain Snapshots**: A Store saves and loads a chain as a single unit. The chains in this repository are
+//    small enough that rewriting the whole file on every save is cheap, and it keeps crash recovery trivial to reason about.
+//
+// 2. **Wire Format on Disk**: Chains are stored as wire.Chain rather than as each package's own Block type, so the
+//    storage layer does not depend on any algorithm and every algorithm gets persistence through the same interface.
+//
+// 3. **Verification Belongs to the Loader**: A Store returns exactly what was written. Checking that the hashes and links
+//    are still valid is left to the algorithm that loads the chain, because only it knows how its blocks are hashed.
@@ -0,0 +1,64 @@
+package sybil
+
+import (
+    "fmt"
+    "io"
+    "text/tabwriter"
+)
+
+// Report is the outcome of an attack.
+type Report struct {
+    Config  Config   // The attack, with defaults filled in.
+    Results []Result // One result per scheme and number of identities, scheme by scheme.
+}
+
+// Result is what the attacker gained against one scheme with one number of identities.
+type Result struct {
+    Scheme     Scheme
+    Identities int     // Identities the attacker ran.
+    Nodes      int     // Honest nodes and attacker identities together.
+    Share      float64 // Attacker's share of all hash power or stake.
+    Weight     float64 // Attacker's share of what the scheme weighs: hash power or stake, or identities if it counts them.
+    Control    float64 // Attacker's share of the outcome: of the blocks honest node 0 committed, those it produced; for PBFT, of the blocks submitted, those it kept from being committed.
+    Blocks     int     // Blocks honest node 0 committed after the genesis block.
+}
+
+// Amplification returns how many times its share of the resources the attacker's share of the outcome is.
+func (r Result) Amplification() float64 {
+    return r.Control / r.Share
+}
+
+// Resists reports whether the attacker's share of the outcome stayed below twice its share of the resources for
+// every number of identities it tried against scheme: a margin that the randomness of block production stays
+// within, and that any scheme counting identities overshoots as they are added.
+func (r *Report) Resists(scheme Scheme) bool {
+    for _, res := range r.Results {
+        if res.Scheme == scheme && res.Amplification() > 2 {
+            return false
+        }
+    }
+    return true
+}
+
+// Print writes the report as a table with a row per scheme and number of identities, followed by a verdict on
+// each scheme.
+func (r *Report) Print(w io.Writer) error {
+    fmt.Fprintf(w, "%d honest nodes, attacker holds %.0f%% of the resources, %v per run, seed %d\n\n", r.Config.Honest, 100*r.Config.Share, r.Config.Duration, r.Config.Seed)
+    tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintln(tw, "scheme\tidentities\tnodes\tweight\tcontrol\tamplification\tblocks\t")
+    for _, res := range r.Results {
+        fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f%%\t%.1f%%\t%.2fx\t%d\t\n", res.Scheme, res.Identities, res.Nodes, 100*res.Weight, 100*res.Control, res.Amplification(), res.Blocks)
+    }
+    if err := tw.Flush(); err != nil {
+        return err
+    }
+    fmt.Fprintln(w)
+    for _, scheme := range r.Config.Schemes {
+        verdict := "collapses"
+        if r.Resists(scheme) {
+            verdict = "resists"
+        }
+        fmt.Fprintf(w, "%s %s\n", scheme, verdict)
+    }
+    return nil
+}
@@ -0,0 +1,268 @@
+// Package sybil floods a network with identities that one attacker controls, and measures what the attacker
+// gains by it. Creating an identity costs nothing in a simulation, as it costs little on an open network, so
+// an algorithm is only as strong against a Sybil attack as the thing it weighs its participants by. Proof of
+// Work weighs them by hash power and Proof of Stake by stake: an attacker who splits the same resources across
+// a hundred identities wins the same share of the blocks as one who keeps them in one. A scheme that gives
+// every identity one vote collapses instead: each identity the attacker adds is worth as much as an honest
+// node, whatever resources stand behind it.
+//
+// Run plays the same attack against every scheme, once for each number of attacker identities, and reports
+// the attacker's share of the outcome next to its share of the resources, which is the quantity a lecture on
+// Sybil resistance is about. Every run is a message-driven simulation of package sim, so a report is repeatable
+// from its seed and takes no real time.
+package sybil
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "consensus-algorithms-edu/adversary"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+)
+
+// ErrUnknownScheme is returned by Run for a scheme it has no scenario for.
+var ErrUnknownScheme = errors.New("sybil: unknown scheme")
+
+// Scheme is a way of deciding whose block or vote counts, which an attack is played against.
+type Scheme string
+
+const (
+    PoW   Scheme = "pow"   // Proof of Work: a miner's chance to produce the next block is its share of the hash power.
+    PoS   Scheme = "pos"   // Proof of Stake: a validator's chance to propose the next block is its share of the stake.
+    Equal Scheme = "equal" // Proof of Stake with one unit of stake per identity: one node, one chance to propose.
+    PBFT  Scheme = "pbft"  // PBFT with open membership: one node, one vote, and a block needs the votes of two thirds.
+)
+
+// Schemes returns every scheme Run can play, resource-weighted ones first.
+func Schemes() []Scheme {
+    return []Scheme{PoW, PoS, Equal, PBFT}
+}
+
+// Defaults used by Run for the zero values of Config.
+const (
+    DefaultHonest   = 10
+    DefaultShare    = 0.1
+    DefaultDuration = 2 * time.Minute
+)
+
+// DefaultIdentities are the numbers of identities Run gives the attacker if Config.Identities is empty.
+var DefaultIdentities = []int{1, 3, 10, 30}
+
+// blocksPerSecond is how often a block is produced, or submitted to PBFT, on average in every scheme.
+const blocksPerSecond = 1
+
+// tick is the virtual time between two ticks of a node.
+const tick = 10 * time.Millisecond
+
+// confirmations is how many blocks must follow a PoW or PoS block before honest node 0 counts it.
+const confirmations = 6
+
+// Config describes the attack.
+type Config struct {
+    Schemes    []Scheme      // Schemes to attack; every scheme of Schemes() if empty.
+    Honest     int           // Honest nodes, each holding the same resources; DefaultHonest if 0.
+    Share      float64       // Attacker's share of all hash power or stake, below 1; DefaultShare if 0.
+    Identities []int         // Numbers of identities the attacker splits its share across, one run each; DefaultIdentities if empty.
+    Seed       int64         // Seed of every run, so each scheme meets the same latencies and random draws.
+    Duration   time.Duration // Virtual time each run lasts; DefaultDuration if 0.
+}
+
+// Run plays the attack against every scheme in cfg, once for each number of identities, and reports the
+// results scheme by scheme. It stops early with ctx's error once ctx is done.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+    if len(cfg.Schemes) == 0 {
+        cfg.Schemes = Schemes()
+    }
+    for _, scheme := range cfg.Schemes {
+        if _, ok := scenarios[scheme]; !ok {
+            return nil, fmt.Errorf("%w %q", ErrUnknownScheme, scheme)
+        }
+    }
+    if cfg.Honest <= 0 {
+        cfg.Honest = DefaultHonest
+    }
+    if cfg.Share <= 0 || cfg.Share >= 1 {
+        cfg.Share = DefaultShare
+    }
+    if len(cfg.Identities) == 0 {
+        cfg.Identities = DefaultIdentities
+    }
+    if cfg.Duration <= 0 {
+        cfg.Duration = DefaultDuration
+    }
+
+    report := &Report{Config: cfg}
+    for _, scheme := range cfg.Schemes {
+        for _, identities := range cfg.Identities {
+            res, err := play(ctx, cfg, scheme, max(identities, 1))
+            if err != nil {
+                return nil, err
+            }
+            report.Results = append(report.Results, res)
+        }
+    }
+    return report, nil
+}
+
+// network is a simulated network of honest nodes, numbered from 0, followed by the attacker's identities.
+type network struct {
+    sim      *sim.Simulator
+    honest   int
+    sybils   int
+    peers    []int32         // Every node, honest ones first.
+    attacker map[string]bool // Names of the attacker's identities, as blocks carry them.
+}
+
+// scenario adds the nodes of one scheme to n, with the attacker's share of the resources split across its
+// identities, and returns the share of the scheme's weight the attacker holds.
+type scenario func(n *network, share float64) float64
+
+// scenarios holds the scenario of every scheme.
+var scenarios = map[Scheme]scenario{
+    PoW:   powScenario,
+    PoS:   posScenario,
+    Equal: equalScenario,
+    PBFT:  pbftScenario,
+}
+
+// play runs one scheme against an attacker with the given number of identities.
+func play(ctx context.Context, cfg Config, scheme Scheme, identities int) (Result, error) {
+    n := &network{
+        sim:      sim.New(sim.Config{Seed: cfg.Seed, TickInterval: tick, Network: sim.Link{Latency: sim.Constant(5 * time.Millisecond)}}),
+        honest:   cfg.Honest,
+        sybils:   identities,
+        attacker: make(map[string]bool),
+    }
+    for id := range int32(cfg.Honest + identities) {
+        n.peers = append(n.peers, id)
+        if int(id) >= cfg.Honest {
+            n.attacker[node.Name(id)] = true
+        }
+    }
+    weight := scenarios[scheme](n, cfg.Share)
+
+    submitted := 0
+    if scheme == PBFT {
+        // A block producer makes blocks of its own accord; PBFT orders the requests its clients submit.
+        interval := time.Second / blocksPerSecond
+        for at := time.Duration(0); at < cfg.Duration-interval; at += interval {
+            data := fmt.Sprintf("Block %d", submitted+1)
+            n.sim.At(at, func() { n.sim.Propose(0, data) })
+            submitted++
+        }
+    }
+    if err := n.sim.RunForContext(ctx, cfg.Duration); err != nil {
+        return Result{}, err
+    }
+
+    res := Result{Scheme: scheme, Identities: identities, Nodes: len(n.peers), Weight: weight, Share: cfg.Share}
+    committed := n.sim.Replica(0).Committed()[1:]
+    res.Blocks = len(committed)
+    if scheme == PBFT {
+        if submitted > 0 {
+            res.Control = 1 - float64(res.Blocks)/float64(submitted) // The attacker decides by withholding votes.
+        }
+        return res, nil
+    }
+    captured := 0
+    for _, block := range committed {
+        if n.attacker[block.GetProducer()] {
+            captured++
+        }
+    }
+    if res.Blocks > 0 {
+        res.Control = float64(captured) / float64(res.Blocks)
+    }
+    return res, nil
+}
+
+// powScenario gives the honest miners and the attacker's identities together one block a second on average:
+// the attacker's hash power is its share of that rate, divided evenly among its identities.
+func powScenario(n *network, share float64) float64 {
+    total := blocksPerSecond * tick.Seconds() // Chance that anyone finds a block on a tick.
+    for _, id := range n.peers {
+        probability := total * (1 - share) / float64(n.honest)
+        if int(id) >= n.honest {
+            probability = total * share / float64(n.sybils)
+        }
+        n.sim.Add(pow.NewMiner(pow.MinerConfig{ID: id, Peers: n.peers, MineProbability: probability, Confirmations: confirmations, Rand: n.sim.NewRand(), Clock: n.sim.Clock()}))
+    }
+    return share
+}
+
+// posScenario stakes 100 on every honest validator and the attacker's share of the total across its
+// identities, the remainder of the division going to its first identity.
+func posScenario(n *network, share float64) float64 {
+    honest := 100 * n.honest
+    attacker := int(float64(honest)*share/(1-share) + 0.5)
+    stakes := make(map[int32]int, len(n.peers))
+    for _, id := range n.peers {
+        stakes[id] = 100
+        if int(id) >= n.honest {
+            stakes[id] = attacker / n.sybils
+        }
+    }
+    stakes[int32(n.honest)] += attacker % n.sybils
+    addValidators(n, stakes)
+    return float64(attacker) / float64(honest+attacker)
+}
+
+// equalScenario gives every node, honest or not, one unit of stake, so the attacker's resources do not count.
+func equalScenario(n *network, share float64) float64 {
+    stakes := make(map[int32]int, len(n.peers))
+    for _, id := range n.peers {
+        stakes[id] = 1
+    }
+    addValidators(n, stakes)
+    return float64(n.sybils) / float64(len(n.peers))
+}
+
+// addValidators adds a Proof of Stake validator for every node, proposing a block a second between them.
+func addValidators(n *network, stakes map[int32]int) {
+    schedule := pos.NewSchedule(stakes)
+    slotTicks := int(time.Second / blocksPerSecond / tick)
+    for _, id := range n.peers {
+        n.sim.Add(pos.NewValidator(pos.ValidatorConfig{ID: id, Peers: n.peers, Stakes: stakes, Schedule: schedule, SlotTicks: slotTicks, Confirmations: confirmations, Clock: n.sim.Clock()}))
+    }
+}
+
+// pbftScenario admits the attacker's identities as PBFT replicas, after the honest ones so that the first
+// primary is honest. The identities take part in nothing: they withhold every vote, which costs the attacker
+// nothing and leaves the honest replicas short of a quorum once the identities outnumber the f faults the group
+// is sized for.
+func pbftScenario(n *network, share float64) float64 {
+    silent := adversary.StrategyFunc(func(adversary.Output) []*wire.Envelope { return nil })
+    for _, id := range n.peers {
+        replica := pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: n.peers, Clock: n.sim.Clock()})
+        if int(id) >= n.honest {
+            n.sim.Add(adversary.Wrap(replica, silent))
+        } else {
+            n.sim.Add(replica)
+        }
+    }
+    return float64(n.sybils) / float64(len(n.peers))
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Same Resources, More Identities**: The attacker's resources are fixed by Config.Share and only the number of
+//    identities varies, so any change in what the attacker wins across a scheme's rows is the effect of the
+//    identities alone: the definition of a Sybil attack.
+//
+// 2. **Share of the Outcome**: Every scheme is measured by the same quantity, the attacker's share of what was
+//    decided — the blocks it produced on a block producer's chain, the blocks it stopped in PBFT — so one table
+//    compares schemes that fail in different ways.
+//
+// 3. **Silence, Not Lies**: The PBFT identities only withhold their votes. That is the cheapest attack there is,
+//    and enough to show the collapse; what identities beyond two thirds can do by lying is shown by package
+//    adversary's collusion.
+//
+// 4. **Equal Stake for One Vote**: The one-node-one-vote block producer is Proof of Stake with equal stakes, so it
+//    differs from the PoS row in nothing but what it weighs, and the comparison between the two is exact.
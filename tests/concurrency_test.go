@@ -0,0 +1,158 @@
+package tests
+
+import (
+    "strconv"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "consensus-algorithms-edu/algorithms/dpos"
+    "consensus-algorithms-edu/algorithms/paxos"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/wire"
+)
+
+// concurrently runs propose from several goroutines while another goroutine keeps reading the chain with
+// snapshot, and returns how many proposals succeeded. Run it with -race to catch unguarded state.
+func concurrently(propose func(worker, i int) error, snapshot func() int) int {
+    const workers, proposals = 4, 5
+    var committed atomic.Int32
+    var wg sync.WaitGroup
+    done := make(chan struct{})
+    go func() {
+        for {
+            select {
+            case <-done:
+                return
+            default:
+                snapshot()
+            }
+        }
+    }()
+    for w := 0; w < workers; w++ {
+        wg.Add(1)
+        go func(w int) {
+            defer wg.Done()
+            for i := 0; i < proposals; i++ {
+                if propose(w, i) == nil {
+                    committed.Add(1)
+                }
+            }
+        }(w)
+    }
+    wg.Wait()
+    close(done)
+    return int(committed.Load())
+}
+
+// linked reports whether every block of a chain follows its predecessor, given as index, hash and previous hash.
+func linked(n int, block func(i int) (int, wire.Hash, wire.Hash)) bool {
+    for i := 1; i < n; i++ {
+        index, _, prevHash := block(i)
+        _, hash, _ := block(i - 1)
+        if index != i || prevHash != hash {
+            return false
+        }
+    }
+    return true
+}
+
+func TestConcurrentRaftProposals(t *testing.T) {
+    chain := raft.NewRaftNetwork(options.WithNodes(5))
+    committed := concurrently(func(w, i int) error {
+        _, err := chain.Leader.Lead("Block " + strconv.Itoa(w) + "." + strconv.Itoa(i))
+        return err
+    }, func() int { return len(chain.Snapshot()) })
+    blocks := chain.Snapshot()
+    if committed != 20 || len(blocks) != 21 {
+        t.Errorf("Expected 20 committed blocks, got %d of %d", committed, len(blocks)-1)
+    }
+    if !linked(len(blocks), func(i int) (int, wire.Hash, wire.Hash) { return blocks[i].Index, blocks[i].Hash, blocks[i].PrevHash }) {
+        t.Errorf("Expected the chain to stay linked")
+    }
+}
+
+func TestConcurrentPBFTAndPaxosRounds(t *testing.T) {
+    replicated := pbft.NewPBFTNetwork(options.WithNodes(4))
+    committed := concurrently(func(w, i int) error {
+        _, err := replicated.RunPBFT("Block " + strconv.Itoa(w) + "." + strconv.Itoa(i))
+        return err
+    }, func() int { return len(replicated.Snapshot()) })
+    blocks := replicated.Snapshot()
+    if committed != 20 || len(blocks) != 21 {
+        t.Errorf("Expected 20 PBFT blocks, got %d of %d", committed, len(blocks)-1)
+    }
+    if !linked(len(blocks), func(i int) (int, wire.Hash, wire.Hash) { return blocks[i].Index, blocks[i].Hash, blocks[i].PrevHash }) {
+        t.Errorf("Expected the PBFT chain to stay linked")
+    }
+
+    // Proposal numbers are drawn before the runs are serialized, so a run holding an older number than one
+    // that went first is rejected; the chain must still hold exactly the accepted runs.
+    acceptors := paxos.NewPaxosNetwork(options.WithNodes(3))
+    var proposalID atomic.Int32
+    committed = concurrently(func(w, i int) error {
+        _, err := acceptors.RunPaxos("Block "+strconv.Itoa(w)+"."+strconv.Itoa(i), int(proposalID.Add(1)))
+        return err
+    }, func() int { return len(acceptors.Snapshot()) })
+    entries := acceptors.Snapshot()
+    if committed == 0 || len(entries) != committed+1 {
+        t.Errorf("Expected every accepted proposal to be committed once, got %d of %d", committed, len(entries)-1)
+    }
+    if !linked(len(entries), func(i int) (int, wire.Hash, wire.Hash) { return entries[i].Index, entries[i].Hash, entries[i].PrevHash }) {
+        t.Errorf("Expected the Paxos chain to stay linked")
+    }
+}
+
+func TestConcurrentStakeAndDelegateBlocks(t *testing.T) {
+    staked := pos.NewBlockchain([]string{"validator-0", "validator-1"}, map[string]int{"validator-0": 3, "validator-1": 1}, options.WithSeed(1))
+    committed := concurrently(func(w, i int) error {
+        _, err := staked.AddBlock("Block " + strconv.Itoa(w) + "." + strconv.Itoa(i))
+        return err
+    }, func() int { return len(staked.Snapshot()) })
+    if blocks := staked.Snapshot(); committed != 20 || len(blocks) != 21 {
+        t.Errorf("Expected 20 PoS blocks, got %d of %d", committed, len(blocks)-1)
+    }
+
+    elected := dpos.NewBlockchain([]string{"delegate-0", "delegate-1"}, map[string]string{"voter-0": "delegate-0"}, options.WithSeed(1))
+    elected.CountVotes()
+    committed = concurrently(func(w, i int) error {
+        if w == 0 {
+            elected.Vote("voter-"+strconv.Itoa(i), "delegate-"+strconv.Itoa(i%2)) // One goroutine keeps voting.
+            elected.CountVotes()
+            return nil
+        }
+        _, err := elected.AddBlock("Block " + strconv.Itoa(w) + "." + strconv.Itoa(i))
+        return err
+    }, func() int { return len(elected.Snapshot()) })
+    blocks := elected.Snapshot()
+    if committed != 20 || len(blocks) != 16 {
+        t.Errorf("Expected 15 DPoS blocks and 5 votes, got %d successes and %d blocks", committed, len(blocks)-1)
+    }
+    if !linked(len(blocks), func(i int) (int, wire.Hash, wire.Hash) { return blocks[i].Index, blocks[i].Hash, blocks[i].PrevHash }) {
+        t.Errorf("Expected the DPoS chain to stay linked")
+    }
+}
+
+func TestParallelMinersExtendOneChain(t *testing.T) {
+    chain := pow.NewBlockchain()
+    chain.Difficulty = 2 // Keep mining quick under the race detector.
+    committed := concurrently(func(w, i int) error {
+        _, err := chain.AddBlock("Block " + strconv.Itoa(w) + "." + strconv.Itoa(i))
+        return err
+    }, func() int { return len(chain.Snapshot()) })
+    blocks := chain.Snapshot()
+    if committed != 20 || len(blocks) != 21 {
+        t.Errorf("Expected every miner's blocks to be appended, got %d of %d", committed, len(blocks)-1)
+    }
+    for i := 1; i < len(blocks); i++ {
+        if blocks[i].Hash != blocks[i].CalculateHash() || !blocks[i].MeetsDifficulty() {
+            t.Errorf("Expected block %d to be validly mined", i)
+        }
+    }
+    if !linked(len(blocks), func(i int) (int, wire.Hash, wire.Hash) { return blocks[i].Index, blocks[i].Hash, blocks[i].PrevHash }) {
+        t.Errorf("Expected the mined chain to stay linked")
+    }
+}
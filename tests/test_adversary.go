@@ -0,0 +1,103 @@
+package tests
+
+import (
+    "testing"
+    "time"
+    "consensus-algorithms-edu/adversary"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+)
+
+// byzantinePBFT builds a cluster of four PBFT replicas, f = 1, in which the replicas listed in faulty are
+// wrapped with the given strategies. It returns the simulator and the honest code of every replica.
+func byzantinePBFT(faulty map[int32][]adversary.Strategy) (*sim.Simulator, []*pbft.Replica) {
+    s := sim.New(sim.Config{Seed: 1, Network: pbftLink})
+    peers := []int32{0, 1, 2, 3}
+    replicas := make([]*pbft.Replica, len(peers))
+    for _, id := range peers {
+        replicas[id] = pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: peers, Clock: s.Clock()})
+        if strategies, ok := faulty[id]; ok {
+            s.Add(adversary.Wrap(replicas[id], strategies...))
+        } else {
+            s.Add(replicas[id])
+        }
+    }
+    return s, replicas
+}
+
+// blockAt returns the data of the block a replica committed at height, or "" if it has not committed one.
+func blockAt(r *pbft.Replica, height int) string {
+    if chain := r.Committed(); len(chain) > height {
+        return chain[height].GetData()
+    }
+    return ""
+}
+
+func TestAdversaryToleratesFByzantineReplicas(t *testing.T) {
+    hash := adversary.Hashes["pbft"]
+    attacks := map[string]map[int32][]adversary.Strategy{
+        "equivocating primary": {0: {adversary.Equivocate(adversary.NewForger(hash), 2)}},
+        "lying backup":         {3: {adversary.Lie(adversary.NewForger(hash))}},
+        "delaying backup":      {1: {adversary.Delay(5)}},
+    }
+    for name, faulty := range attacks {
+        s, replicas := byzantinePBFT(faulty)
+        s.Propose(0, "Test block 1")
+        s.RunFor(5 * time.Second)
+
+        committed := ""
+        for id, r := range replicas {
+            if _, bad := faulty[int32(id)]; bad {
+                continue
+            }
+            data := blockAt(r, 1)
+            if data != "" && committed != "" && data != committed {
+                t.Errorf("%s: Expected honest replicas to agree, got %q and %q", name, committed, data)
+            }
+            if data != "" {
+                committed = data
+            }
+        }
+        if committed != "Test block 1" {
+            t.Errorf("%s: Expected honest replicas to commit 'Test block 1', got '%s'", name, committed)
+        }
+    }
+}
+
+func TestAdversaryCollusionBeyondFSplitsPBFT(t *testing.T) {
+    // Two colluders out of four exceed f = 1: the primary shows node 2 a forged block and the other colluder
+    // backs it with forged votes, while node 3 sees an honest run.
+    collusion := adversary.NewCollusion(adversary.Hashes["pbft"], 2)
+    s, replicas := byzantinePBFT(map[int32][]adversary.Strategy{0: {collusion.Member()}, 1: {collusion.Member()}})
+    s.Propose(0, "Test block 1")
+    s.RunUntil(func() bool { return blockAt(replicas[2], 1) != "" && blockAt(replicas[3], 1) != "" }, 5*time.Second)
+
+    if got := blockAt(replicas[3], 1); got != "Test block 1" {
+        t.Errorf("Expected node 3 to commit 'Test block 1', got '%s'", got)
+    }
+    if got := blockAt(replicas[2], 1); got != "forged: Test block 1" {
+        t.Errorf("Expected node 2 to commit the forged block, got '%s'", got)
+    }
+}
+
+func TestAdversaryDelayReleasesAfterTicks(t *testing.T) {
+    replica := adversary.Wrap(pbft.NewReplica(pbft.ReplicaConfig{ID: 0, Peers: []int32{0, 1, 2, 3}}), adversary.Delay(3))
+    out, err := replica.Propose("Test block 1")
+    if err != nil || len(out) != 0 {
+        t.Fatalf("Expected the pre-prepares to be held, got %d envelopes and error %v", len(out), err)
+    }
+    var released []*wire.Envelope
+    for i := 1; i <= 3; i++ {
+        released = replica.Tick()
+        if i < 3 && len(released) != 0 {
+            t.Errorf("Expected nothing to be sent on tick %d, got %d envelopes", i, len(released))
+        }
+    }
+    if len(released) != 3 {
+        t.Fatalf("Expected 3 pre-prepares on the third tick, got %d envelopes", len(released))
+    }
+    if released[0].GetPrePrepare().GetBlock().GetData() != "Test block 1" {
+        t.Errorf("Expected the delayed pre-prepare to carry 'Test block 1', got %v", released[0])
+    }
+}
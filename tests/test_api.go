@@ -0,0 +1,159 @@
+package tests
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "consensus-algorithms-edu/api"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/ledger"
+)
+
+// newAPIServer starts an HTTP test server for a fresh engine of the given algorithm.
+func newAPIServer(t *testing.T, algorithm string) *httptest.Server {
+    e, err := engine.New(algorithm, engine.Config{Nodes: 4})
+    if err != nil {
+        t.Fatalf("Failed to create engine: %v", err)
+    }
+    server := httptest.NewServer(api.NewServer(e))
+    t.Cleanup(server.Close)
+    return server
+}
+
+// getJSON fetches url and decodes the JSON response into v, returning the status code.
+func getJSON(t *testing.T, url string, v any) int {
+    resp, err := http.Get(url)
+    if err != nil {
+        t.Fatalf("GET %s failed: %v", url, err)
+    }
+    defer resp.Body.Close()
+    if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+        t.Fatalf("Failed to decode %s: %v", url, err)
+    }
+    return resp.StatusCode
+}
+
+func TestAPISubmitAndQuery(t *testing.T) {
+    server := newAPIServer(t, "raft")
+
+    resp, err := http.Post(server.URL+"/submit", "application/json", strings.NewReader(`{"data":"Test block 1"}`))
+    if err != nil {
+        t.Fatalf("Submit failed: %v", err)
+    }
+    var submitted api.Block
+    json.NewDecoder(resp.Body).Decode(&submitted)
+    resp.Body.Close()
+    if resp.StatusCode != http.StatusCreated || submitted.Data != "Test block 1" {
+        t.Fatalf("Expected 201 with the new block, got %d %+v", resp.StatusCode, submitted)
+    }
+
+    var blocks []api.Block
+    getJSON(t, server.URL+"/blocks", &blocks)
+    if len(blocks) != 2 {
+        t.Errorf("Expected 2 blocks, got %d", len(blocks))
+    }
+
+    var byHash api.Block
+    if code := getJSON(t, server.URL+"/blocks/"+submitted.Hash, &byHash); code != http.StatusOK || byHash.Index != 1 {
+        t.Errorf("Expected block 1 by hash, got %d %+v", code, byHash)
+    }
+
+    var head api.Block
+    getJSON(t, server.URL+"/head", &head)
+    if head.Hash != submitted.Hash {
+        t.Errorf("Expected head %s, got %s", submitted.Hash, head.Hash)
+    }
+
+    var status engine.Status
+    getJSON(t, server.URL+"/status", &status)
+    if status.Height != 1 || status.Leader == "" {
+        t.Errorf("Expected height 1 with a leader, got %+v", status)
+    }
+}
+
+func TestAPIParticipantsAndErrors(t *testing.T) {
+    server := newAPIServer(t, "pos")
+
+    var participants []engine.Participant
+    getJSON(t, server.URL+"/participants", &participants)
+    if len(participants) != 4 || participants[0].Stake == 0 {
+        t.Errorf("Expected 4 validators with stakes, got %+v", participants)
+    }
+
+    var apiErr api.Error
+    if code := getJSON(t, server.URL+"/blocks/42", &apiErr); code != http.StatusNotFound {
+        t.Errorf("Expected 404 for a missing block, got %d", code)
+    }
+
+    resp, err := http.Post(server.URL+"/submit", "application/json", strings.NewReader(`{"data":""}`))
+    if err != nil {
+        t.Fatalf("Submit failed: %v", err)
+    }
+    resp.Body.Close()
+    if resp.StatusCode != http.StatusBadRequest {
+        t.Errorf("Expected 400 for empty data, got %d", resp.StatusCode)
+    }
+}
+
+func TestAPIFindsBlocksByAddress(t *testing.T) {
+    server := newAPIServer(t, "raft")
+    for _, data := range []string{
+        ledger.EncodeTransfers(ledger.Transfer{From: "alice", To: "bob", Amount: 5}),
+        "Unrelated block",
+        ledger.EncodeTransfers(ledger.Transfer{From: "carol", To: "alice", Amount: 2}),
+    } {
+        body, _ := json.Marshal(api.SubmitRequest{Data: data})
+        resp, err := http.Post(server.URL+"/submit", "application/json", bytes.NewReader(body))
+        if err != nil {
+            t.Fatalf("Submit failed: %v", err)
+        }
+        resp.Body.Close()
+    }
+
+    var found api.AddressBlocks
+    if code := getJSON(t, server.URL+"/addresses/alice/blocks", &found); code != http.StatusOK {
+        t.Fatalf("Expected 200, got %d", code)
+    }
+    if len(found.Blocks) != 2 || found.Blocks[0].Index != 1 || found.Blocks[1].Index != 3 {
+        t.Errorf("Expected blocks 1 and 3 to touch alice, got %+v", found.Blocks)
+    }
+    if len(found.Candidates) < len(found.Blocks) {
+        t.Errorf("Expected every block among the candidates %v", found.Candidates)
+    }
+    getJSON(t, server.URL+"/addresses/dave/blocks", &found)
+    if len(found.Blocks) != 0 {
+        t.Errorf("Expected no blocks for dave, got %+v", found.Blocks)
+    }
+}
+
+func TestAPIServesReceipts(t *testing.T) {
+    utxos := ledger.NewUTXOSet(ledger.Output{Owner: "alice", Amount: 100})
+    e, _ := engine.New("pos", engine.Config{Nodes: 3, Ledger: utxos})
+    server := httptest.NewServer(api.NewServer(e))
+    t.Cleanup(server.Close)
+
+    pay := ledger.Tx{
+        Inputs:  []ledger.OutPoint{{Tx: ledger.GenesisTx, Index: 0}},
+        Outputs: []ledger.Output{{Owner: "bob", Amount: 70}, {Owner: "alice", Amount: 30}},
+    }
+    body, _ := json.Marshal(api.SubmitRequest{Data: ledger.EncodeTxs(pay)})
+    resp, err := http.Post(server.URL+"/submit", "application/json", bytes.NewReader(body))
+    if err != nil {
+        t.Fatalf("Submit failed: %v", err)
+    }
+    resp.Body.Close()
+
+    var receipt api.Receipt
+    if code := getJSON(t, server.URL+"/receipts/"+pay.ID(), &receipt); code != http.StatusOK {
+        t.Fatalf("Expected 200, got %d", code)
+    }
+    if receipt.Block != 1 || receipt.Status != ledger.Succeeded || len(receipt.Changes) != 3 {
+        t.Errorf("Expected a receipt spending one output and creating two in block 1, got %+v", receipt)
+    }
+    if code := getJSON(t, server.URL+"/receipts/unknown", &receipt); code != http.StatusNotFound {
+        t.Errorf("Expected 404 for an unknown transaction, got %d", code)
+    }
+}
@@ -0,0 +1,51 @@
+//go:build !js
+
+package tests
+
+import (
+    "path/filepath"
+    "testing"
+    "consensus-algorithms-edu/algorithms/dpos"
+    "consensus-algorithms-edu/storage"
+)
+
+func TestBoltBlockStore(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "blocks.db")
+    store, err := storage.OpenBoltBlockStore(path)
+    if err != nil {
+        t.Fatalf("OpenBoltBlockStore failed: %v", err)
+    }
+
+    blockchain := dpos.NewBlockchain([]string{"Alice", "Bob"}, map[string]string{})
+    if err := blockchain.AttachBlockStore(store); err != nil {
+        t.Fatalf("AttachBlockStore failed: %v", err)
+    }
+    blockchain.AddBlock("Test block 1")
+    blockchain.AddBlock("Test block 2")
+    store.Close()
+
+    // Reopen the database to make sure the blocks were written to disk.
+    store, err = storage.OpenBoltBlockStore(path)
+    if err != nil {
+        t.Fatalf("Reopening the store failed: %v", err)
+    }
+    defer store.Close()
+
+    if n, _ := store.Len(); n != 3 {
+        t.Errorf("Expected 3 stored blocks, got %d", n)
+    }
+
+    byIndex, err := store.GetByIndex(2)
+    if err != nil || byIndex.GetData() != "Test block 2" {
+        t.Errorf("Expected 'Test block 2' at index 2, got %v (%v)", byIndex, err)
+    }
+
+    byHash, err := store.GetByHash(blockchain.Blocks[1].Hash.String())
+    if err != nil || byHash.GetData() != "Test block 1" {
+        t.Errorf("Expected 'Test block 1' by hash, got %v (%v)", byHash, err)
+    }
+
+    if _, err := store.GetByHash("missing"); err != storage.ErrBlockNotFound {
+        t.Errorf("Expected ErrBlockNotFound, got %v", err)
+    }
+}
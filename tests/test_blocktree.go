@@ -0,0 +1,467 @@
+package tests
+
+import (
+    "errors"
+    "slices"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/dpos"
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/blocktree"
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+)
+
+// child returns a block extending parent. The tree does not check hashes, so any unique string will do.
+func child(hash string, parent *wire.Block, producer string) *wire.Block {
+    return &wire.Block{Index: parent.GetIndex() + 1, PrevHash: parent.GetHash(), Hash: hash, Producer: producer}
+}
+
+// sameHead reports whether every replica follows the same head.
+func sameHead(replicas []*blocktree.Replica) func() bool {
+    return func() bool {
+        for _, r := range replicas[1:] {
+            if r.Head().GetHash() != replicas[0].Head().GetHash() {
+                return false
+            }
+        }
+        return true
+    }
+}
+
+// onChain reports whether the block with the given hash is part of the chain the replica follows.
+func onChain(r *blocktree.Replica, hash string) bool {
+    for _, block := range r.Chain() {
+        if block.GetHash() == hash {
+            return true
+        }
+    }
+    return false
+}
+
+func TestTreeConnectsOrphans(t *testing.T) {
+    genesis := &wire.Block{Hash: "g"}
+    a1 := child("a1", genesis, "")
+    a2 := child("a2", a1, "")
+    a3 := child("a3", a2, "")
+    tree := blocktree.New(genesis, nil)
+
+    if result := tree.Add(a3); !result.Orphaned || result.Head != nil {
+        t.Fatalf("Expected a block without a parent to be orphaned, got %+v", result)
+    }
+    tree.Add(a2)
+    if !tree.Waiting("a1") {
+        t.Errorf("Expected orphans to be waiting for the missing parent")
+    }
+    result := tree.Add(a1)
+    if len(result.Connected) != 3 {
+        t.Fatalf("Expected the parent to connect both orphans, got %d blocks", len(result.Connected))
+    }
+    if tree.Head() != a3 || result.Head == nil || len(result.Head.Adopted) != 3 || result.Head.Reorg() {
+        t.Errorf("Expected the head to extend to a3 without a reorganization, got %+v", result.Head)
+    }
+    if tree.Len() != 4 || tree.Waiting("a1") {
+        t.Errorf("Expected 4 connected blocks and no orphans, got %d", tree.Len())
+    }
+}
+
+func TestTreeSwitchesToLongerCompetingBranch(t *testing.T) {
+    genesis := &wire.Block{Hash: "g"}
+    a1 := child("a1", genesis, "")
+    a2 := child("a2", a1, "")
+    b1 := child("b1", genesis, "")
+    b2 := child("b2", b1, "")
+    b3 := child("b3", b2, "")
+    tree := blocktree.New(genesis, blocktree.LongestChain)
+    for _, block := range []*wire.Block{a1, a2, b1, b2} {
+        tree.Add(block)
+    }
+
+    if tree.Head() != a2 {
+        t.Fatalf("Expected an equally long branch to keep the head, got %s", tree.Head().GetHash())
+    }
+    if competing := tree.Competing(); len(competing) != 1 || competing[0] != b2 {
+        t.Errorf("Expected b2 to be the only competing tip, got %v", competing)
+    }
+
+    change := tree.Add(b3).Head
+    if change == nil || !change.Reorg() {
+        t.Fatalf("Expected the longer branch to cause a reorganization")
+    }
+    if change.Fork != genesis || len(change.Abandoned) != 2 || len(change.Adopted) != 3 {
+        t.Errorf("Expected to abandon 2 blocks and adopt 3 after genesis, got %d and %d after %s",
+            len(change.Abandoned), len(change.Adopted), change.Fork.GetHash())
+    }
+    if tips := tree.Tips(); len(tips) != 2 || tips[0] != b3 {
+        t.Errorf("Expected tips b3 and a2, got %v", tips)
+    }
+}
+
+func TestHeaviestBranchCountsEachProducerOnce(t *testing.T) {
+    genesis := &wire.Block{Hash: "g"}
+    stakes := map[string]int{"rich": 10, "poor": 3}
+    tree := blocktree.New(genesis, pos.ForkChoice(stakes))
+    rich := child("r1", genesis, "rich")
+    tree.Add(rich)
+
+    poor := genesis
+    for _, hash := range []string{"p1", "p2", "p3", "p4"} {
+        poor = child(hash, poor, "poor")
+        tree.Add(poor)
+    }
+    if tree.Head() != rich {
+        t.Errorf("Expected a single block backed by more stake to beat a longer branch, got %s", tree.Head().GetHash())
+    }
+
+    tree.Add(child("r2", poor, "rich"))
+    if tree.Head().GetHash() != "r2" {
+        t.Errorf("Expected the branch backed by both producers to win, got %s", tree.Head().GetHash())
+    }
+}
+
+func TestPartitionPoSFollowsMajorityStake(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 5, Network: sim.Link{Latency: sim.Constant(10 * time.Millisecond)}})
+    peers := []int32{0, 1, 2, 3}
+    stakes := map[int32]int{0: 50, 1: 30, 2: 15, 3: 5}
+    validators := make([]*pos.Validator, len(peers))
+    replicas := make([]*blocktree.Replica, len(peers))
+    for i, id := range peers {
+        validators[i] = pos.NewValidator(pos.ValidatorConfig{ID: id, Peers: peers, Stakes: stakes, Clock: s.Clock()})
+        replicas[i] = validators[i].Replica
+        s.Add(validators[i])
+    }
+    s.RunFor(time.Second)
+    if !s.RunUntil(sameHead(replicas), s.Now()+time.Second) || len(validators[0].Chain()) < 2 {
+        t.Fatalf("Expected the validators to build one chain while connected")
+    }
+
+    s.Network.Partition([]int32{0, 1}, []int32{2, 3})
+    s.RunFor(4 * time.Second)
+    majority, minority := validators[0].Head(), validators[2].Head()
+    if majority.GetHash() == minority.GetHash() {
+        t.Fatalf("Expected the two sides to fork")
+    }
+
+    s.Network.Heal()
+    if !s.RunUntil(sameHead(replicas), s.Now()+30*time.Second) {
+        t.Fatalf("The validators did not converge on one chain after healing")
+    }
+    if !onChain(validators[2].Replica, majority.GetHash()) {
+        t.Errorf("Expected the branch built by 80%% of the stake to win")
+    }
+    if validators[2].Reorgs() == 0 || len(validators[2].Tree().Competing()) == 0 {
+        t.Errorf("Expected the minority to reorganize and keep its branch as a competing tip")
+    }
+}
+
+func TestPartitionDPoSFollowsMostDelegates(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 6, Network: sim.Link{Latency: sim.Constant(10 * time.Millisecond)}})
+    peers := []int32{0, 1, 2, 3, 4}
+    delegates := make([]*dpos.Delegate, len(peers))
+    replicas := make([]*blocktree.Replica, len(peers))
+    for i, id := range peers {
+        delegates[i] = dpos.NewDelegate(dpos.DelegateConfig{ID: id, Peers: peers, Clock: s.Clock()})
+        replicas[i] = delegates[i].Replica
+        s.Add(delegates[i])
+    }
+    s.Network.Partition([]int32{0, 1, 2}, []int32{3, 4})
+    s.Propose(3, "Minority data")
+    s.RunFor(3 * time.Second)
+    majority, minority := delegates[0].Head(), delegates[3].Head()
+    if majority.GetHash() == minority.GetHash() {
+        t.Fatalf("Expected the two sides to fork")
+    }
+
+    s.Network.Heal()
+    if !s.RunUntil(sameHead(replicas), s.Now()+30*time.Second) {
+        t.Fatalf("The delegates did not converge on one chain after healing")
+    }
+    if !onChain(delegates[3].Replica, majority.GetHash()) {
+        t.Errorf("Expected the branch produced by three of five delegates to win")
+    }
+
+    // The abandoned block's data goes back into the queue and makes it onto the winning chain.
+    included := func() bool {
+        for _, block := range delegates[0].Chain() {
+            if block.GetData() == "Minority data" {
+                return true
+            }
+        }
+        return false
+    }
+    if !s.RunUntil(included, s.Now()+10*time.Second) {
+        t.Errorf("Expected data from an abandoned block to be included in the winning chain")
+    }
+}
+
+func TestReplicaServesHeadersALightClientCanVerify(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 3})
+    peers := []int32{0, 1, 2}
+    stakes := map[int32]int{0: 10, 1: 10, 2: 10}
+    validators := make([]*pos.Validator, len(peers))
+    for i, id := range peers {
+        validators[i] = pos.NewValidator(pos.ValidatorConfig{ID: id, Peers: peers, Stakes: stakes, Clock: s.Clock()})
+        s.Add(validators[i])
+    }
+    validators[0].Propose("Test block 1")
+    s.RunFor(3 * time.Second)
+    chain := validators[0].Chain()
+    if len(chain) < 3 {
+        t.Fatalf("Expected the validators to build a chain, got %d blocks", len(chain))
+    }
+
+    // A light client that trusts only the genesis hash asks for every header and checks that they link up.
+    out := validators[0].Step(&wire.Envelope{From: 9, To: 0, Body: &wire.Envelope_HeaderRequest{HeaderRequest: &wire.HeaderRequest{}}})
+    if len(out) != 1 || out[0].GetTo() != 9 || out[0].Validate() != nil {
+        t.Fatalf("Expected one well-formed Headers reply, got %v", out)
+    }
+    headers, err := wire.DecodeHeaders(out[0].GetHeaders())
+    if err != nil || len(headers) != len(chain) {
+        t.Fatalf("Expected %d headers, got %d (%v)", len(chain), len(headers), err)
+    }
+    if err := wire.VerifyHeaders(headers); err != nil {
+        t.Errorf("Expected the headers to form a chain, got %v", err)
+    }
+    if headers[0].Hash().String() != chain[0].GetHash() || headers[len(headers)-1].Hash().String() != validators[0].Head().GetHash() {
+        t.Errorf("Expected the headers to hash to the blocks of the chain")
+    }
+
+    // Bodies fetched later are checked against the header they belong to.
+    for i, block := range chain {
+        if headers[i].Root != wire.BodyRoot(block.GetData()) {
+            t.Errorf("Expected block %d's data to match its header's root", i)
+        }
+    }
+    if headers[1].Root == wire.BodyRoot("Forged data") {
+        t.Errorf("Expected forged data not to match the header's root")
+    }
+
+    // A header that was tampered with no longer links to the one before it.
+    headers[1].Timestamp++
+    if err := wire.VerifyHeaders(headers); !errors.Is(err, wire.ErrBrokenHeaders) {
+        t.Errorf("Expected a tampered header to break the chain, got %v", err)
+    }
+    if part := validators[0].Headers(1, 1); len(part) != 1 || part[0].Index != 1 {
+        t.Errorf("Expected a limited request to return only header 1, got %v", part)
+    }
+}
+
+func TestAntiEntropyRepairsAfterPartitionHeals(t *testing.T) {
+    // Only miner 0 finds blocks. Miner 2 is cut off while miner 1 receives them; then miner 0 is cut off, so no new
+    // block will ever be announced to miner 2 once it can hear miner 1 again.
+    run := func(antiEntropy int) (behind, ahead *pow.Miner) {
+        s := sim.New(sim.Config{Seed: 3, Network: sim.Link{Latency: sim.Constant(10 * time.Millisecond)}})
+        peers := []int32{0, 1, 2}
+        miners := make([]*pow.Miner, len(peers))
+        for i, id := range peers {
+            probability := 1e-9
+            if id == 0 {
+                probability = 0.05
+            }
+            miners[i] = pow.NewMiner(pow.MinerConfig{ID: id, Peers: peers, MineProbability: probability, AntiEntropy: antiEntropy, Rand: s.NewRand(), Clock: s.Clock()})
+            s.Add(miners[i])
+        }
+        s.Network.Partition([]int32{0, 1}, []int32{2})
+        s.RunUntil(func() bool { return len(miners[1].Chain()) >= 8 }, 30*time.Second)
+        s.Network.Heal()
+        s.Network.Partition([]int32{0}, []int32{1, 2})
+        s.RunFor(5 * time.Second)
+        return miners[2], miners[1]
+    }
+
+    if behind, _ := run(0); len(behind.Chain()) != 1 {
+        t.Fatalf("Expected miner 2 to learn nothing without anti-entropy, got %d blocks", len(behind.Chain()))
+    }
+    behind, ahead := run(20)
+    if behind.Head().GetHash() != ahead.Head().GetHash() {
+        t.Errorf("Expected anti-entropy to bring miner 2 to block %d, got block %d", ahead.Head().GetIndex(), behind.Head().GetIndex())
+    }
+
+    // Roots of the same range agree exactly when the blocks do.
+    chain := ahead.Chain()
+    whole, _ := blocktree.RangeRoot(chain, 0, int64(len(chain)))
+    forked := append(slices.Clone(chain[:len(chain)-1]), child(strings.Repeat("f", 64), chain[len(chain)-2], ""))
+    if other, _ := blocktree.RangeRoot(forked, 0, int64(len(forked))); other == whole {
+        t.Errorf("Expected a different last block to change the root")
+    }
+    if prefix, _ := blocktree.RangeRoot(forked, 0, int64(len(forked)-1)); prefix != mustRoot(chain, int64(len(chain)-1)) {
+        t.Errorf("Expected the common prefix to have the same root")
+    }
+}
+
+// mustRoot returns the Merkle root of the first n blocks of chain.
+func mustRoot(chain []*wire.Block, n int64) wire.Hash {
+    root, _ := blocktree.RangeRoot(chain, 0, n)
+    return root
+}
+
+func TestPrunedMinersReachConsensusWithArchiveMiners(t *testing.T) {
+    // Miners 0 and 1 keep every block; miners 2 to 6 keep the data of the last 4 blocks only. Miner 6 joins late.
+    s := sim.New(sim.Config{Seed: 5, Network: sim.Link{Latency: sim.Constant(10 * time.Millisecond)}})
+    peers := []int32{0, 1, 2, 3, 4, 5, 6}
+    var miners []*pow.Miner
+    var replicas []*blocktree.Replica
+    for _, id := range peers {
+        prune := 0
+        if id >= 2 {
+            prune = 4
+        }
+        m := pow.NewMiner(pow.MinerConfig{ID: id, Peers: peers, MineProbability: 0.01, Prune: prune, AntiEntropy: 20, Rand: s.NewRand(), Clock: s.Clock()})
+        miners = append(miners, m)
+        replicas = append(replicas, m.Replica)
+    }
+    for _, m := range miners[:6] {
+        s.Add(m)
+    }
+    for i := range 10 {
+        s.Propose(int32(i%6), "Test block "+strconv.Itoa(i))
+    }
+    s.RunUntil(func() bool { return len(miners[0].Chain()) >= 16 }, time.Minute)
+    s.RunUntil(sameHead(replicas[:6]), 10*time.Second)
+    if !sameHead(replicas[:6])() {
+        t.Fatalf("Expected archive and pruned miners to agree on the head")
+    }
+
+    archive, pruned := miners[0], miners[2]
+    if !archive.Archive() || archive.Pruned() != 0 || pruned.Archive() || pruned.Pruned() == 0 {
+        t.Errorf("Expected only miner 2 to prune, got %d and %d pruned blocks", archive.Pruned(), pruned.Pruned())
+    }
+    full := archive.Chain()
+    for i, block := range pruned.Chain() {
+        recent := int64(i) >= pruned.Head().GetIndex()-4
+        if kept := block.GetData() == full[i].GetData(); recent != kept && full[i].GetData() != "" {
+            t.Errorf("Expected block %d's data to be kept %v, got %v", i, recent, kept)
+        }
+    }
+
+    // Headers outlive the data: a pruned miner still serves every header, identical to the archive miner's.
+    headers := pruned.Headers(0, 0)
+    if err := wire.VerifyHeaders(headers); err != nil || !slices.Equal(headers, archive.Headers(0, 0)) {
+        t.Errorf("Expected the pruned miner to serve the archive miner's headers, got %v", err)
+    }
+    request := &wire.Envelope{From: 9, To: 2, Body: &wire.Envelope_BlockRequest{BlockRequest: &wire.BlockRequest{Hash: full[1].GetHash()}}}
+    if _, ok := pruned.Block(1); ok || len(pruned.Step(request)) != 0 {
+        t.Errorf("Expected the pruned miner not to serve block 1")
+    }
+    if _, ok := archive.Block(1); !ok || len(archive.Step(request)) != 1 {
+        t.Errorf("Expected the archive miner to serve block 1")
+    }
+
+    // A miner joining late fetches the old blocks from the archive miners, then prunes them in turn.
+    s.Add(miners[6])
+    s.RunUntil(sameHead(replicas), 30*time.Second)
+    if !sameHead(replicas)() {
+        t.Fatalf("Expected the late miner to catch up to block %d, got block %d", archive.Head().GetIndex(), miners[6].Head().GetIndex())
+    }
+    if miners[6].Pruned() == 0 {
+        t.Errorf("Expected the late miner to prune the blocks it fetched")
+    }
+}
+
+// transfer returns a block carrying a single transfer, extending parent.
+func transfer(hash string, parent *wire.Block, from, to string, amount uint64) *wire.Block {
+    block := child(hash, parent, "")
+    block.Data = ledger.EncodeTransfers(ledger.Transfer{From: from, To: to, Amount: amount})
+    return block
+}
+
+func TestChainRollsBackAndSwitchesForks(t *testing.T) {
+    genesis := &wire.Block{Hash: "g", Data: "Genesis Block"}
+    chain, err := blocktree.NewChain(genesis, ledger.NewAccounts(map[string]uint64{"alice": 100}))
+    if err != nil {
+        t.Fatalf("Expected a chain, got %v", err)
+    }
+    balance := func(name string) uint64 { return chain.State().(*ledger.Accounts).Balance(name) }
+    a1 := transfer("a1", genesis, "alice", "bob", 60)
+    b1 := transfer("b1", genesis, "alice", "carol", 70)
+    if err := chain.Append(a1); err != nil || balance("bob") != 60 {
+        t.Fatalf("Expected bob to be paid 60, got %d (%v)", balance("bob"), err)
+    }
+    if err := chain.Append(b1); !errors.Is(err, blocktree.ErrNotOnChain) {
+        t.Errorf("Expected a block that does not extend the head to be refused, got %v", err)
+    }
+
+    // Switching to the competing branch unwinds alice's payment to bob before paying carol.
+    abandoned, err := chain.ApplyForkSwitch([]*wire.Block{b1})
+    if err != nil || len(abandoned) != 1 || abandoned[0] != a1 {
+        t.Fatalf("Expected a1 to be abandoned, got %v (%v)", abandoned, err)
+    }
+    if balance("alice") != 30 || balance("bob") != 0 || balance("carol") != 70 {
+        t.Errorf("Expected only carol to be paid, got alice %d, bob %d, carol %d", balance("alice"), balance("bob"), balance("carol"))
+    }
+
+    // A branch that overdraws halfway is refused as a whole, and the chain stays on b1.
+    a2 := transfer("a2", a1, "alice", "dave", 50)
+    if _, err := chain.ApplyForkSwitch([]*wire.Block{a1, a2}); !errors.Is(err, ledger.ErrInvalid) {
+        t.Errorf("Expected an overdrawing branch to be refused, got %v", err)
+    }
+    if chain.Head() != b1 || balance("carol") != 70 || balance("dave") != 0 {
+        t.Errorf("Expected a refused branch to leave the chain unchanged")
+    }
+
+    if removed, err := chain.Rollback(0); err != nil || len(removed) != 1 || balance("alice") != 100 {
+        t.Errorf("Expected a rollback to genesis to restore alice's 100, got %d (%v)", balance("alice"), err)
+    }
+    if _, err := chain.Rollback(3); !errors.Is(err, blocktree.ErrHeight) {
+        t.Errorf("Expected a rollback above the head to be refused, got %v", err)
+    }
+
+    // Pruning drops the states kept below the depth, so the chain can no longer unwind that far.
+    chain.Append(a1)
+    chain.Append(child("a2", a1, ""))
+    chain.Prune(1)
+    if _, err := chain.Rollback(0); !errors.Is(err, blocktree.ErrPrunedState) {
+        t.Errorf("Expected a rollback below the pruned depth to be refused, got %v", err)
+    }
+    if _, err := chain.Rollback(1); err != nil || balance("bob") != 60 {
+        t.Errorf("Expected a rollback within the depth to keep bob's 60, got %d (%v)", balance("bob"), err)
+    }
+}
+
+func TestPartitionedDoubleSpendIsUnwoundByReorg(t *testing.T) {
+    // Alice pays bob on the side with most of the stake, and the same 100 to carol on the other side.
+    s := sim.New(sim.Config{Seed: 5, Network: sim.Link{Latency: sim.Constant(10 * time.Millisecond)}})
+    peers := []int32{0, 1, 2, 3}
+    stakes := map[int32]int{0: 50, 1: 30, 2: 15, 3: 5}
+    validators := make([]*pos.Validator, len(peers))
+    replicas := make([]*blocktree.Replica, len(peers))
+    for i, id := range peers {
+        state := ledger.NewAccounts(map[string]uint64{"alice": 100})
+        validators[i] = pos.NewValidator(pos.ValidatorConfig{ID: id, Peers: peers, Stakes: stakes, State: state, Clock: s.Clock()})
+        replicas[i] = validators[i].Replica
+        s.Add(validators[i])
+    }
+    s.Network.Partition([]int32{0, 1}, []int32{2, 3})
+    validators[0].Propose(ledger.EncodeTransfers(ledger.Transfer{From: "alice", To: "bob", Amount: 100}))
+    validators[2].Propose(ledger.EncodeTransfers(ledger.Transfer{From: "alice", To: "carol", Amount: 100}))
+    s.RunFor(4 * time.Second)
+    accounts := func(v *pos.Validator) *ledger.Accounts { return v.State().(*ledger.Accounts) }
+    if accounts(validators[3]).Balance("carol") != 100 || accounts(validators[0]).Balance("bob") != 100 {
+        t.Fatalf("Expected each side to apply its own payment")
+    }
+
+    s.Network.Heal()
+    if !s.RunUntil(sameHead(replicas), s.Now()+30*time.Second) {
+        t.Fatalf("The validators did not converge on one chain after healing")
+    }
+    for _, v := range validators {
+        if a := accounts(v); a.Balance("bob") != 100 || a.Balance("carol") != 0 || a.Balance("alice") != 0 {
+            t.Errorf("Expected validator %d to keep only the payment to bob, got bob %d, carol %d", v.ID(), a.Balance("bob"), a.Balance("carol"))
+        }
+    }
+    if validators[3].Reorgs() == 0 {
+        t.Errorf("Expected the minority to reorganize")
+    }
+
+    // The payment to carol is pending again on the minority, but no longer valid, so no block carries it.
+    s.RunFor(2 * time.Second)
+    for _, block := range validators[0].Chain() {
+        if strings.Contains(block.GetData(), "carol") {
+            t.Errorf("Expected the double spend never to be included once the partition healed")
+        }
+    }
+}
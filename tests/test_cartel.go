@@ -0,0 +1,51 @@
+package tests
+
+import (
+    "context"
+    "slices"
+    "strings"
+    "testing"
+    "consensus-algorithms-edu/algorithms/dpos"
+    "consensus-algorithms-edu/cartel"
+)
+
+func TestDPoSTallyWeighsApprovalsByStake(t *testing.T) {
+    ballots := []dpos.Ballot{
+        {Voter: "whale", Stake: 100, Approve: []string{"carol", "dave"}},
+        {Voter: "minnow", Stake: 10, Approve: []string{"alice", "bob", "bob"}},
+        {Voter: "shrimp", Stake: 10, Approve: []string{"alice"}},
+        {Voter: "idle", Stake: 0, Approve: []string{"eve"}},
+    }
+    if got, want := dpos.Tally(ballots, 3), []string{"carol", "dave", "alice"}; !slices.Equal(got, want) {
+        t.Errorf("Expected active set %v, got %v", want, got)
+    }
+    if got := dpos.Tally(ballots, 10); len(got) != 4 || got[3] != "bob" {
+        t.Errorf("Expected only the four approved candidates, ending with bob, got %v", got)
+    }
+}
+
+func TestCartelCapturesFasterWhenFewerVote(t *testing.T) {
+    report, err := cartel.Run(context.Background(), cartel.Config{Participation: []float64{0.1, 0.5, 0.9}, Rounds: 60, Seed: 1})
+    if err != nil {
+        t.Fatalf("Run failed: %v", err)
+    }
+    if len(report.Results) != 3 {
+        t.Fatalf("Expected a result per participation rate, got %d", len(report.Results))
+    }
+    for i, res := range report.Results {
+        if !res.Captured() || res.Majority > res.Capture {
+            t.Errorf("Expected the cartel to take a majority and then two thirds at %.0f%% turnout, got rounds %d and %d", 100*res.Participation, res.Majority, res.Capture)
+        }
+        if i > 0 && res.Capture <= report.Results[i-1].Capture {
+            t.Errorf("Expected higher turnout to hold the cartel off longer, got round %d after round %d", res.Capture, report.Results[i-1].Capture)
+        }
+        if i > 0 && res.Bribed <= report.Results[i-1].Bribed {
+            t.Errorf("Expected higher turnout to cost the briber more stake, got %.3f after %.3f", res.Bribed, report.Results[i-1].Bribed)
+        }
+    }
+
+    var out strings.Builder
+    if err := report.Print(&out); err != nil || !strings.Contains(out.String(), "capture") {
+        t.Errorf("Expected the report to print a table, got %v:\n%s", err, out.String())
+    }
+}
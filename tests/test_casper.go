@@ -0,0 +1,82 @@
+package tests
+
+import (
+    "errors"
+    "slices"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/blocktree"
+    "consensus-algorithms-edu/casper"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+)
+
+func TestTreeReselectsWhenTheRuleTightens(t *testing.T) {
+    genesis := &wire.Block{Hash: "g"}
+    a1 := child("a1", genesis, "")
+    b1 := child("b1", genesis, "")
+    b2 := child("b2", b1, "")
+    refused := ""
+    tree := blocktree.New(genesis, func(t *blocktree.Tree, a, b *wire.Block) bool {
+        refuses := slices.ContainsFunc(t.Branch(a), func(block *wire.Block) bool { return block.GetHash() == refused })
+        return !refuses && blocktree.LongestChain(t, a, b)
+    })
+    for _, block := range []*wire.Block{a1, b1, b2} {
+        tree.Add(block)
+    }
+    if tree.Reselect() != nil || tree.Head() != b2 {
+        t.Fatalf("Expected an unchanged rule to keep head b2, got %s", tree.Head().GetHash())
+    }
+
+    refused = "b1"
+    change := tree.Reselect()
+    if change == nil || tree.Head() != a1 || change.Fork != genesis || len(change.Abandoned) != 2 {
+        t.Fatalf("Expected to abandon b1 and b2 for a1, got head %s", tree.Head().GetHash())
+    }
+}
+
+func TestFinalityGadgetRefusesReorgsPastCheckpoints(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 1})
+    peers := []int32{0, 1, 2, 3}
+    committee := map[int32]int{0: 1, 1: 1, 2: 1} // The attacker, node 3, holds no stake.
+    var miners []*pow.Miner
+    var overlays []*casper.Replica
+    for _, id := range peers {
+        p := 0.001
+        if id == 3 {
+            p = 0.006 // Twice the hash power of the honest miners together.
+        }
+        m := pow.NewMiner(pow.MinerConfig{ID: id, Peers: peers, MineProbability: p, Rand: s.NewRand(), Clock: s.Clock()})
+        o := casper.Overlay(m, casper.Config{Committee: committee, Peers: peers, Epoch: 5})
+        miners, overlays = append(miners, m), append(overlays, o)
+        s.Add(o)
+    }
+    s.Network.Partition([]int32{0, 1, 2}, []int32{3})
+    s.RunUntil(func() bool { return miners[0].Finalized().GetIndex() >= 10 }, 10*time.Minute)
+
+    finalized := miners[0].Finalized()
+    if finalized.GetIndex() < 10 || finalized.GetIndex()%5 != 0 || !overlays[0].Justified(finalized) {
+        t.Fatalf("Expected a justified checkpoint at block 10 or above to be finalized, got block %d", finalized.GetIndex())
+    }
+    if len(miners[3].Chain()) <= len(miners[0].Chain()) {
+        t.Fatalf("Expected the attacker's branch to be longer, got %d blocks against %d", len(miners[3].Chain()), len(miners[0].Chain()))
+    }
+
+    s.Network.Heal()
+    s.RunFor(10 * time.Second)
+    for _, m := range miners[:3] {
+        if m.Chain()[finalized.GetIndex()].GetHash() != finalized.GetHash() {
+            t.Errorf("Expected miner %d to keep the finalized checkpoint at block %d", m.ID(), finalized.GetIndex())
+        }
+        if len(m.Committed()) <= int(finalized.GetIndex()) {
+            t.Errorf("Expected miner %d to report the finalized checkpoint committed", m.ID())
+        }
+    }
+    if attack := miners[3].Head(); miners[0].Tree().Get(attack.GetHash()) == nil || onChain(miners[0].Replica, attack.GetHash()) {
+        t.Errorf("Expected the honest miners to hold the attacker's branch and refuse it")
+    }
+    if err := miners[0].Finalize(miners[3].Chain()[5]); !errors.Is(err, blocktree.ErrConflictingCheckpoint) {
+        t.Errorf("Expected a checkpoint of the attacker's branch to conflict, got %v", err)
+    }
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/adversary"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/censorship"
+    "consensus-algorithms-edu/sim"
+)
+
+func TestCensorshipDetectionDeposesTheCensor(t *testing.T) {
+    report, err := censorship.Run(context.Background(), censorship.Config{Duration: 30 * time.Second, Seed: 1})
+    if err != nil {
+        t.Fatalf("Run failed: %v", err)
+    }
+    if len(report.Results) != 2*len(censorship.Schemes()) {
+        t.Fatalf("Expected a result per scheme with and without detection, got %d", len(report.Results))
+    }
+    for _, res := range report.Results {
+        if res.Censored == 0 || res.Served != res.Others {
+            t.Errorf("Expected %s to serve every other client while censoring, got %d of %d", res.Scheme, res.Served, res.Others)
+        }
+        switch {
+        case res.Detection && (!res.Resists() || !res.Deposed):
+            t.Errorf("Expected detection to include every censored transaction in %s and depose the censor, got %d of %d, deposed %v", res.Scheme, res.Included, res.Censored, res.Deposed)
+        case !res.Detection && res.Scheme != censorship.DPoS && res.Included != 0:
+            t.Errorf("Expected the %s leader to censor every transaction without detection, got %d of %d included", res.Scheme, res.Included, res.Censored)
+        case !res.Detection && res.Scheme == censorship.DPoS && (!res.Resists() || res.Deposed):
+            t.Errorf("Expected rotating delegates to only delay censored transactions, got %d of %d, deposed %v", res.Included, res.Censored, res.Deposed)
+        }
+    }
+
+    var out strings.Builder
+    if err := report.Print(&out); err != nil || !strings.Contains(out.String(), "raft: censored without detection") {
+        t.Errorf("Expected the report to give a verdict per scheme, got %v:\n%s", err, out.String())
+    }
+}
+
+func TestCensorshipRejectsUnknownScheme(t *testing.T) {
+    if _, err := censorship.Run(context.Background(), censorship.Config{Schemes: []censorship.Scheme{"pow"}}); !errors.Is(err, censorship.ErrUnknownScheme) {
+        t.Errorf("Expected ErrUnknownScheme, got %v", err)
+    }
+}
+
+func TestRaftFollowersReplaceALeaderThatIgnoresAProposal(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 1, TickInterval: 10 * time.Millisecond, Network: sim.Link{Latency: sim.Constant(time.Millisecond)}})
+    peers := []int32{0, 1, 2}
+    replicas := make([]*raft.Replica, len(peers))
+    var censor *adversary.Censor
+    for _, id := range peers {
+        cfg := raft.ReplicaConfig{ID: id, Peers: peers, ElectionTicks: 20, RequestTimeout: 50, Rand: s.NewRand(), Clock: s.Clock()}
+        if id == 0 {
+            cfg.ElectionTicks = 3 // Node 0 wins the first election.
+        }
+        replicas[id] = raft.NewReplica(cfg)
+        if id == 0 {
+            censor = adversary.NewCensor(replicas[id], func(data string) bool { return strings.HasPrefix(data, "alice:") })
+            s.Add(censor)
+        } else {
+            s.Add(replicas[id])
+        }
+    }
+    s.RunFor(time.Second)
+    if replicas[1].Leader() != 0 {
+        t.Fatalf("Expected node 0 to lead, got %d", replicas[1].Leader())
+    }
+
+    if _, err := censor.Propose("alice: payment"); err != nil {
+        t.Fatalf("Expected the censor to accept the transaction silently, got %v", err)
+    }
+    s.RunFor(time.Second)
+    if censor.Dropped != 1 || len(replicas[1].Committed()) != 1 {
+        t.Fatalf("Expected the transaction to be dropped, got %d dropped and %d blocks", censor.Dropped, len(replicas[1].Committed()))
+    }
+
+    for _, id := range peers {
+        s.Propose(id, "alice: payment") // The client suspects censorship and tells everyone.
+    }
+    s.RunFor(3 * time.Second)
+    if leader := replicas[1].Leader(); leader <= 0 {
+        t.Errorf("Expected a follower to replace the censor, got leader %d", leader)
+    }
+    if chain := replicas[2].Committed(); len(chain) != 2 || chain[1].GetData() != "alice: payment" {
+        t.Errorf("Expected the new leader to commit the censored transaction once, got %d blocks", len(chain))
+    }
+}
@@ -0,0 +1,176 @@
+package tests
+
+import (
+    "context"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+)
+
+var mockStart = time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func TestMockClockFiresTimersWhenAdvanced(t *testing.T) {
+    mock := clock.NewMock(mockStart)
+    after := mock.After(10 * time.Millisecond)
+
+    mock.Advance(9 * time.Millisecond)
+    select {
+    case <-after:
+        t.Fatalf("Expected the timer not to fire before its deadline")
+    default:
+    }
+
+    mock.Advance(time.Millisecond)
+    select {
+    case at := <-after:
+        if !at.Equal(mockStart.Add(10 * time.Millisecond)) {
+            t.Errorf("Expected the timer to fire at %v, got %v", mockStart.Add(10*time.Millisecond), at)
+        }
+    default:
+        t.Fatalf("Expected the timer to fire at its deadline")
+    }
+    if got := mock.Now(); !got.Equal(mockStart.Add(10 * time.Millisecond)) {
+        t.Errorf("Expected the clock to show %v, got %v", mockStart.Add(10*time.Millisecond), got)
+    }
+}
+
+func TestDriftingClockRunsAtItsRate(t *testing.T) {
+    mock := clock.NewMock(mockStart)
+    fast := clock.Drift(mock, 1.5)
+    after := fast.After(3 * time.Second) // Three seconds of the drifting clock are two of the mock's.
+
+    mock.Advance(2 * time.Second)
+    if got := fast.Now(); !got.Equal(mockStart.Add(3 * time.Second)) {
+        t.Errorf("Expected the drifting clock to show %v, got %v", mockStart.Add(3*time.Second), got)
+    }
+    select {
+    case <-after:
+    default:
+        t.Fatalf("Expected the timer to fire once the drifting clock reached its deadline")
+    }
+}
+
+func TestMockClockTicker(t *testing.T) {
+    mock := clock.NewMock(mockStart)
+    ticker := mock.NewTicker(10 * time.Millisecond)
+
+    ticks := 0
+    for i := 0; i < 5; i++ {
+        mock.Advance(10 * time.Millisecond)
+        select {
+        case <-ticker.C():
+            ticks++
+        default:
+        }
+    }
+    if ticks != 5 {
+        t.Errorf("Expected 5 ticks, got %d", ticks)
+    }
+
+    ticker.Stop()
+    mock.Advance(time.Second)
+    select {
+    case <-ticker.C():
+        t.Errorf("Expected no ticks after Stop")
+    default:
+    }
+}
+
+func TestRunnerTicksOnMockClock(t *testing.T) {
+    mock := clock.NewMock(mockStart)
+    peers := []int32{0, 1, 2}
+    replicas := make([]node.Replica, len(peers))
+    for i, id := range peers {
+        replicas[i] = raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: peers, Clock: mock})
+    }
+    runners := startCluster(t, replicas, func(r *node.Runner) { r.Clock = mock })
+
+    // Nothing happens until the mock clock moves: advance it until a leader accepts a proposal.
+    deadline := time.Now().Add(5 * time.Second)
+    for accepted := false; !accepted; {
+        if time.Now().After(deadline) {
+            t.Fatalf("No leader was elected")
+        }
+        mock.Advance(2 * time.Millisecond)
+        time.Sleep(time.Millisecond)
+        for _, runner := range runners {
+            if runner.Propose("Test block 1") == nil {
+                accepted = true
+                break
+            }
+        }
+    }
+    proposed := mock.Now()
+
+    for len(runners[0].Committed()) < 2 || len(runners[1].Committed()) < 2 || len(runners[2].Committed()) < 2 {
+        if time.Now().After(deadline) {
+            t.Fatalf("The block was not committed")
+        }
+        mock.Advance(2 * time.Millisecond) // Replication is driven by the leader's heartbeat ticks.
+        time.Sleep(time.Millisecond)
+    }
+    if ts := wire.Timestamp(runners[0].Committed()[1].GetTimestamp()); !ts.Time().Equal(proposed) {
+        t.Errorf("Expected the block to be stamped with the mock time %s, got %s", proposed, ts)
+    }
+}
+
+func TestSimClockFollowsVirtualTime(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 1})
+    c := s.Clock()
+    if !c.Now().Equal(sim.Epoch) {
+        t.Errorf("Expected the simulation to start at %v, got %v", sim.Epoch, c.Now())
+    }
+
+    after := c.After(50 * time.Millisecond)
+    s.RunFor(50 * time.Millisecond)
+    select {
+    case at := <-after:
+        if !at.Equal(sim.Epoch.Add(50 * time.Millisecond)) {
+            t.Errorf("Expected the timer to fire at %v, got %v", sim.Epoch.Add(50*time.Millisecond), at)
+        }
+    default:
+        t.Errorf("Expected the timer to fire once the virtual clock reached it")
+    }
+}
+
+func TestEngineUsesInjectedClock(t *testing.T) {
+    mock := clock.NewMock(mockStart)
+    for _, name := range engine.Algorithms() {
+        e, err := engine.New(name, engine.Config{Clock: mock})
+        if err != nil {
+            t.Fatalf("New(%q) failed: %v", name, err)
+        }
+        if err := e.Submit(context.Background(), "Test block 1"); err != nil {
+            t.Fatalf("%s: Submit failed: %v", name, err)
+        }
+        blocks := e.Blocks()
+        if ts := wire.Timestamp(blocks[len(blocks)-1].GetTimestamp()); !ts.Time().Equal(mockStart) {
+            t.Errorf("%s: Expected the block to be stamped %s, got %s", name, mockStart, ts)
+        }
+    }
+}
+
+func TestBlockTimestampsDoNotDependOnTheTimeZone(t *testing.T) {
+    local := mockStart.In(time.FixedZone("UTC+5", 5*60*60))
+    utc := raft.NewBlockAt("Test block 1", raft.GenesisBlock().Hash, 1, mockStart)
+    zoned := raft.NewBlockAt("Test block 1", raft.GenesisBlock().Hash, 1, local)
+    if utc.Hash != zoned.Hash {
+        t.Errorf("Expected the same instant to hash the same in every time zone, got %s and %s", utc.Hash, zoned.Hash)
+    }
+    if got := utc.Timestamp.String(); got != "2030-01-01T00:00:00Z" {
+        t.Errorf("Expected the timestamp to display as 2030-01-01T00:00:00Z, got %s", got)
+    }
+    if ts := wire.TimestampOf(time.Time{}); ts != 0 {
+        t.Errorf("Expected the zero time to become the zero timestamp, got %d", ts)
+    }
+
+    monotonic := time.Now()
+    if wire.TimestampOf(monotonic) != wire.TimestampOf(monotonic.Round(0)) {
+        t.Errorf("Expected the monotonic clock reading to be ignored")
+    }
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/compare"
+)
+
+func TestCompareSameScript(t *testing.T) {
+    script := compare.Transactions(5, 500*time.Millisecond).With(
+        compare.Event{At: 1200 * time.Millisecond, Kind: compare.Partition, Groups: [][]int32{{0, 1}, {2, 3, 4}}},
+        compare.Event{At: 4 * time.Second, Kind: compare.Heal},
+    )
+    report, err := compare.Run(context.Background(), script, compare.Config{Algorithms: []string{"raft", "pbft", "pos"}, Nodes: 5, Seed: 1})
+    if err != nil {
+        t.Fatalf("Failed to run the comparison: %v", err)
+    }
+    if len(report.Results) != 3 {
+        t.Fatalf("Expected 3 results, got %d", len(report.Results))
+    }
+    for _, res := range report.Results {
+        if res.Submitted != 5 || res.Confirmed == 0 {
+            t.Errorf("%s: Expected some of 5 transactions to be confirmed, got %d of %d", res.Algorithm, res.Confirmed, res.Submitted)
+        }
+        if res.Finalized < res.Confirmed || res.Messages.Sent == 0 {
+            t.Errorf("%s: Expected at least %d finalized blocks and some messages, got %d and %d", res.Algorithm, res.Confirmed, res.Finalized, res.Messages.Sent)
+        }
+        if res.Algorithm != "pos" && res.Divergence != 0 {
+            t.Errorf("%s: Expected no divergence from an algorithm with finality, got %d", res.Algorithm, res.Divergence)
+        }
+    }
+
+    var out strings.Builder
+    if err := report.Print(&out); err != nil || !strings.Contains(out.String(), "pbft") {
+        t.Errorf("Expected a table with a row for pbft, got %q and error %v", out.String(), err)
+    }
+}
+
+func TestCompareParseScript(t *testing.T) {
+    text := "# A lecture script.\n500ms submit Transaction 1\n\n2s partition 0,1 | 2,3\n1s isolate 2\n3s lossy 0.25\n4s heal\n"
+    script, err := compare.ParseScript(strings.NewReader(text))
+    if err != nil {
+        t.Fatalf("Failed to parse the script: %v", err)
+    }
+    want := []string{"500ms submit Transaction 1", "1s isolate 2", "2s partition 0,1 | 2,3", "3s lossy 0.25", "4s heal"}
+    if len(script) != len(want) {
+        t.Fatalf("Expected %d events, got %d", len(want), len(script))
+    }
+    for i, event := range script {
+        if event.String() != want[i] {
+            t.Errorf("Expected event %d to be '%s', got '%s'", i+1, want[i], event)
+        }
+    }
+
+    if _, err := compare.ParseScript(strings.NewReader("1s explode 3\n")); err == nil {
+        t.Errorf("Expected an error for an unknown event")
+    }
+    if err := script.Validate(2); err == nil {
+        t.Errorf("Expected an error for a script naming replica 3 of 2")
+    }
+}
+
+func TestCompareUnknownAlgorithm(t *testing.T) {
+    _, err := compare.Run(context.Background(), compare.Transactions(1, time.Second), compare.Config{Algorithms: []string{"paxos"}})
+    if !errors.Is(err, compare.ErrUnknownAlgorithm) {
+        t.Errorf("Expected ErrUnknownAlgorithm for paxos, got %v", err)
+    }
+}
+
+func TestCompareRetriesRefusedTransaction(t *testing.T) {
+    script := compare.Script{{At: 0, Kind: compare.Submit, Data: "Transaction 1"}} // Before Raft has a leader.
+    report, err := compare.Run(context.Background(), script, compare.Config{Algorithms: []string{"raft"}, Nodes: 4, Seed: 1})
+    if err != nil {
+        t.Fatalf("Failed to run the comparison: %v", err)
+    }
+    if res := report.Results[0]; res.Confirmed != 1 {
+        t.Errorf("Expected the transaction refused before the election to be confirmed once retried, got %d of %d", res.Confirmed, res.Submitted)
+    }
+}
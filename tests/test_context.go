@@ -0,0 +1,141 @@
+package tests
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "log/slog"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/paxos"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/api"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/logging"
+    "consensus-algorithms-edu/modelcheck"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/testutil"
+)
+
+func TestMiningStopsWhenContextIsDone(t *testing.T) {
+    chain := pow.NewBlockchain()
+    chain.Difficulty = 64 // No hash has 64 leading zeros, so only the context can end the search.
+    ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+    defer cancel()
+    if _, err := chain.AddBlockContext(ctx, "Never mined"); !errors.Is(err, context.DeadlineExceeded) {
+        t.Errorf("Expected mining to stop at the deadline, got %v", err)
+    }
+    if len(chain.Blocks) != 1 {
+        t.Errorf("Expected the chain to be unchanged, got %d blocks", len(chain.Blocks))
+    }
+
+    limited := pow.NewBlockchain(options.WithTimeout(20 * time.Millisecond))
+    limited.Difficulty = 64
+    if _, err := limited.AddBlockContext(context.Background(), "Never mined"); !errors.Is(err, pow.ErrMiningTimeout) {
+        t.Errorf("Expected the chain's own timeout to be reported as ErrMiningTimeout, got %v", err)
+    }
+}
+
+func TestCancelledRoundsCommitNothing(t *testing.T) {
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+
+    replicated := raft.NewRaftNetwork(options.WithNodes(3))
+    if _, err := replicated.Leader.LeadContext(ctx, "Test block 1"); !errors.Is(err, context.Canceled) {
+        t.Errorf("Expected Raft to stop, got %v", err)
+    }
+    if won, err := replicated.Nodes[1].RequestVoteContext(ctx); won || !errors.Is(err, context.Canceled) {
+        t.Errorf("Expected the election to be abandoned, got %v, %v", won, err)
+    }
+    byzantine := pbft.NewPBFTNetwork(options.WithNodes(4))
+    if _, err := byzantine.RunPBFTContext(ctx, "Test block 1"); !errors.Is(err, context.Canceled) {
+        t.Errorf("Expected PBFT to stop, got %v", err)
+    }
+    acceptors := paxos.NewPaxosNetwork(options.WithNodes(3))
+    if _, err := acceptors.RunPaxosContext(ctx, "Test block 1", 1); !errors.Is(err, context.Canceled) {
+        t.Errorf("Expected Paxos to stop, got %v", err)
+    }
+    if len(replicated.Blocks) != 1 || len(byzantine.Blocks) != 1 || len(acceptors.Blocks) != 1 {
+        t.Errorf("Expected no block to be committed")
+    }
+    if replicated.Nodes[1].IsLeader {
+        t.Errorf("Expected the abandoned election not to make a leader")
+    }
+
+    for _, algorithm := range engine.Algorithms() {
+        e, err := engine.New(algorithm, engine.Config{Nodes: 4})
+        if err != nil {
+            t.Fatalf("Failed to create the %s engine: %v", algorithm, err)
+        }
+        err = e.Submit(ctx, "Test block 1")
+        if !errors.Is(err, context.Canceled) || errors.Is(err, engine.ErrRejected) {
+            t.Errorf("Expected %s to report the cancellation rather than a rejection, got %v", algorithm, err)
+        }
+        if len(e.Blocks()) != 1 {
+            t.Errorf("Expected %s to commit nothing, got %d blocks", algorithm, len(e.Blocks()))
+        }
+    }
+}
+
+func TestTraceLabelsEveryRecordOfARound(t *testing.T) {
+    var buf bytes.Buffer
+    logger := slog.New(slog.NewJSONHandler(&buf, nil))
+    e, err := engine.New("pbft", engine.Config{Nodes: 4, Logger: logger})
+    if err != nil {
+        t.Fatalf("New failed: %v", err)
+    }
+    if err := e.Submit(logging.WithTrace(context.Background(), "request-1"), "Test block 1"); err != nil {
+        t.Fatalf("Submit failed: %v", err)
+    }
+    if err := e.Submit(context.Background(), "Test block 2"); err != nil {
+        t.Fatalf("Submit failed: %v", err)
+    }
+    traced := 0
+    for _, record := range logRecords(t, &buf) {
+        if record[logging.TraceKey] == "request-1" {
+            traced++
+        } else if record[logging.TraceKey] != nil {
+            t.Errorf("Expected only the first round to be traced, got %v", record)
+        }
+    }
+    if traced == 0 {
+        t.Errorf("Expected the records of the first round to carry its trace")
+    }
+
+    buf.Reset()
+    server := api.NewServer(e)
+    req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(`{"data":"Test block 3"}`))
+    req.Header.Set(api.RequestIDHeader, "request-2")
+    rec := httptest.NewRecorder()
+    server.ServeHTTP(rec, req)
+    if rec.Code != http.StatusCreated || !strings.Contains(buf.String(), `"trace":"request-2"`) {
+        t.Errorf("Expected the request ID to trace the round, got %d and %q", rec.Code, buf.String())
+    }
+}
+
+func TestSimulationsAndSearchesStopWhenContextIsDone(t *testing.T) {
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+
+    c := testutil.Raft(sim.Link{Latency: sim.Constant(time.Millisecond)}, options.WithNodes(3), options.WithSeed(1))
+    if err := c.RunForContext(ctx, time.Second); !errors.Is(err, context.Canceled) {
+        t.Errorf("Expected the simulation to stop, got %v", err)
+    }
+    if c.Now() != 0 {
+        t.Errorf("Expected the virtual clock to stay put, got %v", c.Now())
+    }
+    if met, err := c.RunUntilContext(ctx, func() bool { return false }, time.Second); met || !errors.Is(err, context.Canceled) {
+        t.Errorf("Expected RunUntilContext to stop, got %v, %v", met, err)
+    }
+
+    result, err := modelcheck.CheckContext(ctx, modelcheck.Config{Nodes: 3, Commands: []string{"Test block 1"}, Timeouts: 1, New: newModelRaft})
+    if !errors.Is(err, context.Canceled) || result.Complete {
+        t.Errorf("Expected an incomplete search and the context's error, got %+v, %v", result, err)
+    }
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/compare"
+    "consensus-algorithms-edu/cost"
+    "consensus-algorithms-edu/evidence"
+    "consensus-algorithms-edu/wire"
+)
+
+func TestMeterCountsMarkedOperations(t *testing.T) {
+    header := wire.Header{Index: 1}
+    header.Hash() // Not counted: no meter is active.
+
+    meter := cost.New()
+    if err := cost.Start(meter); err != nil {
+        t.Fatalf("Failed to start the meter: %v", err)
+    }
+    defer cost.Stop()
+    if err := cost.Start(cost.New()); !errors.Is(err, cost.ErrActive) {
+        t.Errorf("Expected a second meter to be refused with ErrActive, got %v", err)
+    }
+    header.Hash()
+    wire.BodyRoot("data")
+    keys, ring := evidence.GenerateKeys(1, []int32{0})
+    st := &wire.Statement{Signer: 0, Height: 1, Value: "a"}
+    evidence.Sign(keys[0], st)
+    ring.Verify(st)
+    ring.Verify(st)
+
+    if got := meter.Counts(); got != (cost.Counts{Hashes: 2, Signatures: 1, Verifications: 2}) {
+        t.Errorf("Expected 2 hashes, 1 signature and 2 verifications, got %+v", got)
+    }
+    meter.Reset()
+    if got := meter.Counts(); got != (cost.Counts{}) {
+        t.Errorf("Expected Reset to clear the counts, got %+v", got)
+    }
+}
+
+func TestCompareCostProfile(t *testing.T) {
+    report, err := compare.Run(context.Background(), compare.Transactions(5, 500*time.Millisecond),
+        compare.Config{Algorithms: []string{"raft", "pbft", "pos"}, Nodes: 4, Seed: 1, Cost: true})
+    if err != nil {
+        t.Fatalf("Failed to run the comparison: %v", err)
+    }
+    byAlgorithm := make(map[string]compare.Result)
+    for _, res := range report.Results {
+        byAlgorithm[res.Algorithm] = res
+        if res.Finalized == 0 || res.Cost.Hashes == 0 {
+            t.Errorf("%s: Expected finalized blocks and hashes, got %d blocks and %+v", res.Algorithm, res.Finalized, res.Cost)
+        }
+    }
+    raft, pbft, pos := byAlgorithm["raft"], byAlgorithm["pbft"], byAlgorithm["pos"]
+    if raft.Cost.Signatures != 0 || raft.Cost.Verifications != 0 {
+        t.Errorf("Expected Raft to sign nothing, got %+v", raft.Cost)
+    }
+    // Every PBFT block takes a Prepare from each backup and a Commit from each replica: 7 signed broadcasts for 4 replicas.
+    if perBlock := pbft.Cost.Signatures / int64(pbft.Finalized); perBlock < 7 {
+        t.Errorf("Expected PBFT to sign at least 7 messages per block, got %d", perBlock)
+    }
+    // A PoS block is signed by its proposer and checked by each of the 3 other validators, the last ones perhaps not yet.
+    if s, v := pos.Cost.Signatures, pos.Cost.Verifications; s < int64(pos.Finalized) || v < 3*int64(pos.Finalized) || v > 3*s {
+        t.Errorf("Expected one signature and 3 verifications per PoS block, got %+v for %d blocks", pos.Cost, pos.Finalized)
+    }
+
+    var out strings.Builder
+    if err := report.PrintCost(&out); err != nil || !strings.Contains(out.String(), "signatures/block") {
+        t.Errorf("Expected a cost table, got %q and error %v", out.String(), err)
+    }
+}
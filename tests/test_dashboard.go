@@ -0,0 +1,52 @@
+package tests
+
+import (
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "consensus-algorithms-edu/api"
+    "consensus-algorithms-edu/dashboard"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/events"
+)
+
+func TestDashboardServedNextToAPI(t *testing.T) {
+    stream := events.NewStream()
+    e, _ := engine.New("raft", engine.Config{Nodes: 3, Events: stream})
+    server := api.NewServer(e)
+    server.Handle("GET /{$}", dashboard.Handler())
+    server.Handle("GET /events", api.EventsHandler(stream))
+    mux := http.NewServeMux()
+    mux.Handle("/sim/", http.StripPrefix("/sim", server))
+    httpServer := httptest.NewServer(mux)
+    defer httpServer.Close()
+
+    resp, err := http.Get(httpServer.URL + "/sim/")
+    if err != nil {
+        t.Fatalf("GET failed: %v", err)
+    }
+    body, _ := io.ReadAll(resp.Body)
+    resp.Body.Close()
+    if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+        t.Fatalf("Expected an HTML page, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+    }
+    // The page must address the API relative to itself to work under a prefix.
+    for _, path := range []string{`url("events")`, `getJSON("status")`, `getJSON("participants")`, `url("submit")`} {
+        if !strings.Contains(string(body), path) {
+            t.Errorf("Expected the page to request %s", path)
+        }
+    }
+
+    for path, want := range map[string]int{"/sim/status": http.StatusOK, "/sim/unknown": http.StatusNotFound} {
+        resp, err := http.Get(httpServer.URL + path)
+        if err != nil {
+            t.Fatalf("GET %s failed: %v", path, err)
+        }
+        resp.Body.Close()
+        if resp.StatusCode != want {
+            t.Errorf("GET %s: Expected status %d, got %d", path, want, resp.StatusCode)
+        }
+    }
+}
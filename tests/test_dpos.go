@@ -22,7 +22,7 @@ func TestDPoS(t *testing.T) {
         t.Errorf("Expected 3 blocks, got %d", len(blockchain.Blocks))
     }
 
-    lastBlock := blockchain.Blocks[len(blockchain.Blocks)-1]
+    lastBlock := blockchain.Head()
     if lastBlock.Data != "Test block 2" {
         t.Errorf("Expected 'Test block 2', got '%s'", lastBlock.Data)
     }
@@ -0,0 +1,71 @@
+package tests
+
+import (
+    "bytes"
+    "context"
+    "encoding/csv"
+    "errors"
+    "math"
+    "testing"
+    "consensus-algorithms-edu/economics"
+)
+
+func TestGiniCoefficient(t *testing.T) {
+    for _, tc := range []struct {
+        values []float64
+        want   float64
+    }{
+        {[]float64{5, 5, 5, 5}, 0},
+        {[]float64{0, 0, 0, 8}, 0.75},
+        {[]float64{1, 2, 3, 4}, 0.25},
+        {nil, 0},
+    } {
+        if got := economics.Gini(tc.values); math.Abs(got-tc.want) > 1e-9 {
+            t.Errorf("Gini(%v) = %v, expected %v", tc.values, got, tc.want)
+        }
+    }
+}
+
+func TestCommissionMovesStakeToValidators(t *testing.T) {
+    report, err := economics.Run(context.Background(), economics.Config{
+        Validators: 20, Delegators: 300, Seats: 7, Blocks: 2000, Every: 500, Rewards: []float64{0.0005}, Commissions: []float64{0, 0.3}, Seed: 1,
+    })
+    if err != nil {
+        t.Fatalf("Expected the runs to complete, got %v", err)
+    }
+    if len(report.Results) != 4 {
+        t.Fatalf("Expected a run per scheme and commission, got %d", len(report.Results))
+    }
+    for _, res := range report.Results {
+        first, last := res.Samples[0], res.Samples[len(res.Samples)-1]
+        if len(res.Samples) != 5 || last.Block != 2000 {
+            t.Errorf("%s at %.0f%%: expected samples at blocks 0 to 2000 every 500, got %d ending at %d", res.Scheme, 100*res.Commission, len(res.Samples), last.Block)
+        }
+        if last.Total <= first.Total {
+            t.Errorf("%s at %.0f%%: expected rewards to add stake, got %d → %d", res.Scheme, 100*res.Commission, first.Total, last.Total)
+        }
+        moved := last.SelfShare - first.SelfShare
+        if res.Commission == 0 && math.Abs(moved) > 0.01 || res.Commission > 0 && moved < 0.05 {
+            t.Errorf("%s at %.0f%%: validators' own share moved from %.3f to %.3f", res.Scheme, 100*res.Commission, first.SelfShare, last.SelfShare)
+        }
+    }
+    pos, dpos := report.Results[0], report.Results[2]
+    if pos.Samples[len(pos.Samples)-1].Gini >= dpos.Samples[len(dpos.Samples)-1].Gini {
+        t.Errorf("Expected DPoS, which pays nothing to stake outside the active set, to end less equal than PoS")
+    }
+
+    var buf bytes.Buffer
+    if err := report.WriteCSV(&buf); err != nil {
+        t.Fatalf("Expected the CSV to be written, got %v", err)
+    }
+    rows, err := csv.NewReader(&buf).ReadAll()
+    if err != nil || len(rows) != 1+4*5 || rows[0][0] != "scheme" || rows[1][0] != "pos" {
+        t.Errorf("Expected a header and a row per sample, got %d rows (%v)", len(rows), err)
+    }
+}
+
+func TestEconomicsRejectsUnknownSchemes(t *testing.T) {
+    if _, err := economics.Run(context.Background(), economics.Config{Schemes: []economics.Scheme{"pow"}}); !errors.Is(err, economics.ErrUnknownScheme) {
+        t.Errorf("Expected ErrUnknownScheme, got %v", err)
+    }
+}
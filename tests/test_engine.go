@@ -0,0 +1,227 @@
+package tests
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/testutil"
+    "consensus-algorithms-edu/wire"
+)
+
+func TestEngineSubmitAllAlgorithms(t *testing.T) {
+    for _, algorithm := range engine.Algorithms() {
+        e, err := engine.New(algorithm, engine.Config{Nodes: 4})
+        if err != nil {
+            t.Fatalf("Failed to create %s engine: %v", algorithm, err)
+        }
+
+        if err := e.Submit(context.Background(), "Test block 1"); err != nil {
+            t.Errorf("%s: failed to submit block 1: %v", algorithm, err)
+        }
+        if err := e.Submit(context.Background(), "Test block 2"); err != nil {
+            t.Errorf("%s: failed to submit block 2: %v", algorithm, err)
+        }
+
+        blocks := e.Blocks()
+        if len(blocks) != 3 {
+            t.Errorf("%s: expected 3 blocks, got %d", algorithm, len(blocks))
+            continue
+        }
+        if blocks[2].GetData() != "Test block 2" {
+            t.Errorf("%s: expected 'Test block 2', got '%s'", algorithm, blocks[2].GetData())
+        }
+
+        status := e.Status()
+        if status.Algorithm != algorithm || status.Height != 2 || status.Head != blocks[2].GetHash() {
+            t.Errorf("%s: unexpected status %+v", algorithm, status)
+        }
+        if len(e.Participants()) == 0 {
+            t.Errorf("%s: expected participants", algorithm)
+        }
+    }
+}
+
+func TestEngineUnknownAlgorithm(t *testing.T) {
+    if _, err := engine.New("proof-of-nothing", engine.Config{}); err == nil {
+        t.Errorf("Expected an error for an unknown algorithm")
+    }
+}
+
+func TestValidateChainAllAlgorithms(t *testing.T) {
+    for _, algorithm := range engine.Algorithms() {
+        e, _ := engine.New(algorithm, engine.Config{Nodes: 4})
+        for i := 0; i < 3; i++ {
+            e.Submit(context.Background(), fmt.Sprintf("Test block %d", i+1))
+        }
+        if err := engine.ValidateChain(e); err != nil {
+            t.Errorf("%s: Expected a valid chain, got %v", algorithm, err)
+        }
+
+        // Editing a block's data breaks its hash under every algorithm's rules.
+        blocks := e.Blocks()
+        blocks[2].Data = "Tampered"
+        var chainErr *engine.ChainError
+        if err := engine.Validate(algorithm, blocks, e.Participants()); !errors.As(err, &chainErr) || chainErr.Index != 2 {
+            t.Errorf("%s: Expected block 2 to be rejected, got %v", algorithm, err)
+        }
+    }
+}
+
+func TestValidateGenericChecks(t *testing.T) {
+    e, _ := engine.New("raft", engine.Config{Nodes: 3})
+    e.Submit(context.Background(), "Test block 1")
+    e.Submit(context.Background(), "Test block 2")
+
+    blocks := e.Blocks()
+    unlinked := e.Blocks()
+    unlinked[2].PrevHash = blocks[0].GetHash()
+    cases := map[string][]*wire.Block{
+        "does not link to its predecessor": unlinked,
+        "has index 2":                      {blocks[0], blocks[2], blocks[1]},
+        "is missing":                       nil,
+    }
+    for reason, chain := range cases {
+        err := engine.Validate("raft", chain, nil)
+        if err == nil || !strings.Contains(err.Error(), reason) {
+            t.Errorf("Expected an error containing %q, got %v", reason, err)
+        }
+    }
+    if err := engine.Validate("tendermint", blocks, nil); !errors.Is(err, engine.ErrUnknownAlgorithm) {
+        t.Errorf("Expected ErrUnknownAlgorithm, got %v", err)
+    }
+}
+
+func TestValidateAlgorithmRules(t *testing.T) {
+    // A PoW block with a consistent hash that was never mined.
+    powEngine, _ := engine.New("pow", engine.Config{})
+    powEngine.Submit(context.Background(), "Test block 1")
+    blocks := powEngine.Blocks()
+    unmined := pow.BlockFromWire(blocks[1])
+    unmined.Nonce++
+    unmined.Hash = unmined.CalculateHash()
+    if unmined.MeetsDifficulty() {
+        t.Skip("The altered nonce happens to meet the difficulty target")
+    }
+    blocks[1] = unmined.ToWire()
+    if err := engine.Validate("pow", blocks, nil); err == nil || !strings.Contains(err.Error(), "difficulty") {
+        t.Errorf("Expected the unmined block to be rejected, got %v", err)
+    }
+
+    // A PoS block produced by someone who is no longer a validator.
+    posEngine, _ := engine.New("pos", engine.Config{Nodes: 3})
+    posEngine.Submit(context.Background(), "Test block 1")
+    var others []engine.Participant
+    for _, p := range posEngine.Participants() {
+        if p.ID != posEngine.Blocks()[1].GetProducer() {
+            others = append(others, p)
+        }
+    }
+    if err := engine.Validate("pos", posEngine.Blocks(), others); err == nil || !strings.Contains(err.Error(), "not a validator") {
+        t.Errorf("Expected an ineligible validator to be rejected, got %v", err)
+    }
+
+    // A PBFT block whose certificate lacks a quorum: 2 of 4 replicas, where 3 are needed.
+    pbftEngine, _ := engine.New("pbft", engine.Config{Nodes: 4})
+    pbftEngine.Submit(context.Background(), "Test block 1")
+    blocks = pbftEngine.Blocks()
+    if len(blocks[1].GetCertificate()) != 4 {
+        t.Errorf("Expected every replica to certify the block, got %v", blocks[1].GetCertificate())
+    }
+    blocks[1].Certificate = blocks[1].Certificate[:2]
+    if err := engine.Validate("pbft", blocks, pbftEngine.Participants()); err == nil || !strings.Contains(err.Error(), "quorum of 3") {
+        t.Errorf("Expected the certificate to be rejected, got %v", err)
+    }
+}
+
+func TestEngineHashDetectsTampering(t *testing.T) {
+    for _, algorithm := range engine.Algorithms() {
+        e, _ := engine.New(algorithm, engine.Config{Nodes: 4})
+        e.Submit(context.Background(), "Test block")
+        block := e.Blocks()[1]
+        if hash, err := engine.Hash(algorithm, block); err != nil || hash != block.GetHash() {
+            t.Errorf("%s: Expected the recomputed hash to match, got %.16s and error %v", algorithm, hash, err)
+        }
+        block.Data = "Tampered block"
+        if hash, _ := engine.Hash(algorithm, block); hash == block.GetHash() {
+            t.Errorf("%s: Expected a different hash for altered data", algorithm)
+        }
+    }
+    if _, err := engine.Hash("tendermint", &wire.Block{}); !errors.Is(err, engine.ErrUnknownAlgorithm) {
+        t.Errorf("Expected ErrUnknownAlgorithm, got %v", err)
+    }
+}
+
+func TestValidatePBFTReplicaCertificates(t *testing.T) {
+    c := testutil.PBFT(pbftLink, options.WithSeed(5))
+    c.Propose(0, "Test block 1")
+    if !c.RunUntil(c.AllCommitted(2), 10*time.Second) {
+        t.Fatalf("The block was not committed")
+    }
+    var participants []engine.Participant
+    for _, id := range c.Nodes() {
+        participants = append(participants, engine.Participant{ID: node.Name(id), Role: "replica"})
+    }
+    for _, id := range c.Nodes() {
+        if err := engine.Validate("pbft", c.Replica(id).Committed(), participants); err != nil {
+            t.Errorf("Replica %d: Expected a certified chain, got %v", id, err)
+        }
+    }
+}
+
+func TestEngineFinality(t *testing.T) {
+    for _, algorithm := range engine.Algorithms() {
+        e, _ := testutil.Engine(t, algorithm, options.WithNodes(4))
+        for i := range 8 {
+            if err := e.Submit(context.Background(), fmt.Sprintf("Block %d", i+1)); err != nil {
+                t.Fatalf("%s: failed to submit block %d: %v", algorithm, i+1, err)
+            }
+        }
+        blocks, finalized := e.Blocks(), e.FinalizedHeight()
+        switch algorithm {
+        case "pbft", "raft", "paxos":
+            if finalized != 8 {
+                t.Errorf("%s: expected every committed block to be final, got height %d", algorithm, finalized)
+            }
+        case "pow":
+            if finalized != 8-engine.Confirmations {
+                t.Errorf("pow: expected blocks %d deep to be final, got height %d", engine.Confirmations, finalized)
+            }
+        case "dpos":
+            // A block is irreversible once three of the four delegates produced it or a block after it. The order
+            // of the delegates comes from their election, so count them on the chain.
+            want, producers := int64(0), make(map[string]bool)
+            for i := len(blocks) - 1; i > 0 && want == 0; i-- {
+                if producers[blocks[i].GetProducer()] = true; len(producers) >= 3 {
+                    want = int64(i)
+                }
+            }
+            if finalized != want {
+                t.Errorf("dpos: expected height %d to be irreversible, got %d", want, finalized)
+            }
+        case "pos":
+            if finalized <= 0 || finalized >= 8 {
+                t.Errorf("pos: expected attestations to finalize some blocks but not the head, got height %d", finalized)
+            }
+        }
+        if e.Status().Finalized != finalized {
+            t.Errorf("%s: expected Status to report finalized height %d, got %d", algorithm, finalized, e.Status().Finalized)
+        }
+        if !e.IsFinal(blocks[0]) || !e.IsFinal(blocks[finalized]) {
+            t.Errorf("%s: expected the genesis block and block %d to be final", algorithm, finalized)
+        }
+        if finalized < 8 && e.IsFinal(blocks[finalized+1]) {
+            t.Errorf("%s: expected block %d to be pending", algorithm, finalized+1)
+        }
+        forged := &wire.Block{Index: blocks[0].GetIndex(), Hash: "forged"}
+        if e.IsFinal(forged) {
+            t.Errorf("%s: expected a block the chain does not hold not to be final", algorithm)
+        }
+    }
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "consensus-algorithms-edu/algorithms/dpos"
+    "consensus-algorithms-edu/algorithms/paxos"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/wire"
+)
+
+func TestRaftLeadReportsFailures(t *testing.T) {
+    blockchain := raft.NewRaftNetwork(options.WithNodes(5))
+    block, err := blockchain.Leader.Lead("Test block 1")
+    if err != nil || block.Hash != blockchain.Blocks[1].Hash {
+        t.Fatalf("Expected the committed block to be returned, got %+v, %v", block, err)
+    }
+    if _, err := blockchain.Nodes[1].Lead("Test block 2"); !errors.Is(err, raft.ErrNotLeader) {
+        t.Errorf("Expected a follower to refuse to lead, got %v", err)
+    }
+    for i := 2; i < 5; i++ {
+        blockchain.Nodes[i].Faulty = true
+    }
+    if _, err := blockchain.Leader.Lead("Test block 2"); !errors.Is(err, raft.ErrNoQuorum) {
+        t.Errorf("Expected a block without a majority to be rejected, got %v", err)
+    }
+    if len(blockchain.Blocks) != 2 {
+        t.Errorf("Expected the chain to be unchanged, got %d blocks", len(blockchain.Blocks))
+    }
+}
+
+func TestAddBlockRefusesBlocksThatDoNotExtendTheChain(t *testing.T) {
+    blockchain := raft.NewBlockchain()
+    genesis := blockchain.Blocks[0]
+    if err := blockchain.AddBlock(raft.NewBlock("Orphan", wire.Hash{1}, 1)); !errors.Is(err, raft.ErrInvalidBlock) {
+        t.Errorf("Expected a block with an unknown parent to be refused, got %v", err)
+    }
+    tampered := raft.NewBlock("Test block 1", genesis.Hash, 1)
+    tampered.Data = "Tampered"
+    if err := blockchain.AddBlock(tampered); !errors.Is(err, raft.ErrInvalidBlock) {
+        t.Errorf("Expected a block with a wrong hash to be refused, got %v", err)
+    }
+    if err := blockchain.AddBlock(raft.NewBlock("Test block 1", genesis.Hash, 1)); err != nil {
+        t.Errorf("Expected a block extending the chain to be added, got %v", err)
+    }
+
+    chain := pbft.NewBlockchain()
+    if err := chain.AddBlock(pbft.NewBlock("Skipped", chain.Blocks[0].Hash, 2)); !errors.Is(err, pbft.ErrInvalidBlock) {
+        t.Errorf("Expected a block skipping a height to be refused, got %v", err)
+    }
+}
+
+func TestConsensusRoundsReportMissingQuorum(t *testing.T) {
+    network := pbft.NewPBFTNetwork(options.WithNodes(4), options.WithFaulty(2))
+    if _, err := network.RunPBFT("Test block 1"); !errors.Is(err, pbft.ErrNoQuorum) {
+        t.Errorf("Expected PBFT with two faulty nodes out of four to lack a quorum, got %v", err)
+    }
+
+    acceptors := paxos.NewPaxosNetwork(options.WithNodes(3))
+    if block, err := acceptors.RunPaxos("Test block 1", 2); err != nil || block.Data != "Test block 1" {
+        t.Fatalf("Expected the first proposal to be committed, got %+v, %v", block, err)
+    }
+    if _, err := acceptors.RunPaxos("Test block 2", 1); !errors.Is(err, paxos.ErrNoQuorum) {
+        t.Errorf("Expected an outdated proposal to be rejected, got %v", err)
+    }
+
+    staked := pos.NewBlockchain([]string{"validator-0"}, map[string]int{"validator-0": 0})
+    if _, err := staked.AddBlock("Test block 1"); !errors.Is(err, pos.ErrNoStake) {
+        t.Errorf("Expected PoS without stake to refuse blocks, got %v", err)
+    }
+    elected := dpos.NewBlockchain([]string{"delegate-0"}, map[string]string{})
+    elected.CountVotes()
+    if _, err := elected.AddBlock("Test block 1"); !errors.Is(err, dpos.ErrNoDelegates) {
+        t.Errorf("Expected DPoS without votes to refuse blocks, got %v", err)
+    }
+}
+
+func TestEngineWrapsRejections(t *testing.T) {
+    e, err := engine.New("pbft", engine.Config{Nodes: 4, Options: []options.Option{options.WithFaulty(2)}})
+    if err != nil {
+        t.Fatalf("Failed to create the engine: %v", err)
+    }
+    err = e.Submit(context.Background(), "Test block 1")
+    if !errors.Is(err, engine.ErrRejected) || !errors.Is(err, pbft.ErrNoQuorum) {
+        t.Errorf("Expected the rejection to carry PBFT's reason, got %v", err)
+    }
+}
@@ -0,0 +1,287 @@
+package tests
+
+import (
+    "context"
+    "fmt"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+    "golang.org/x/net/websocket"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/api"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/events"
+    "consensus-algorithms-edu/feed"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/testutil"
+    "consensus-algorithms-edu/wire"
+)
+
+func TestFromEnvelopesCollapsesBroadcasts(t *testing.T) {
+    var out []*wire.Envelope
+    for to := int32(1); to <= 3; to++ {
+        out = append(out, &wire.Envelope{From: 0, To: to, Body: &wire.Envelope_Prepare{Prepare: &wire.Prepare{View: 2, Sequence: 5, Digest: "abc"}}})
+    }
+    out = append(out, &wire.Envelope{From: 0, To: 1, Body: &wire.Envelope_AppendEntries{AppendEntries: &wire.AppendEntries{}}})
+
+    got := events.FromEnvelopes("pbft", "node-0", out)
+    if len(got) != 1 {
+        t.Fatalf("Expected 1 event, got %d", len(got))
+    }
+    if got[0].Type != events.Vote || got[0].Round != 2 || got[0].Height != 5 || got[0].Message != "Prepare" {
+        t.Errorf("Unexpected event %+v", got[0])
+    }
+}
+
+func TestStreamDropsForSlowSubscribers(t *testing.T) {
+    stream := events.NewStream()
+    sub := stream.Subscribe(1)
+    defer sub.Close()
+
+    stream.Publish(events.Event{Type: events.Commit})
+    stream.Publish(events.Event{Type: events.Commit}) // Dropped: the buffer is full.
+    stream.Publish(events.Event{Type: events.Commit}) // Dropped as well.
+
+    if event := <-sub.Events(); event.Seq != 1 {
+        t.Errorf("Expected seq 1, got %d", event.Seq)
+    }
+    select {
+    case event := <-sub.Events():
+        t.Errorf("Expected no further events, got %+v", event)
+    default:
+    }
+}
+
+func TestRunnerPublishesVotesAndCommits(t *testing.T) {
+    stream := events.NewStream()
+    sub := stream.Subscribe(1024)
+    defer sub.Close()
+
+    peers := []int32{0, 1, 2, 3}
+    replicas := make([]node.Replica, len(peers))
+    for i, id := range peers {
+        replicas[i] = pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: peers})
+    }
+    runners := startCluster(t, replicas, func(r *node.Runner) { r.Events, r.Algorithm = stream, "pbft" })
+    runners[0].Propose("Test block 1")
+    waitForCommits(t, runners, 2)
+
+    seen := make(map[events.Type]bool)
+    for len(sub.Events()) > 0 {
+        event := <-sub.Events()
+        if event.Algorithm != "pbft" {
+            t.Errorf("Expected algorithm 'pbft', got '%s'", event.Algorithm)
+        }
+        seen[event.Type] = true
+    }
+    for _, want := range []events.Type{events.Proposal, events.Vote, events.Commit} {
+        if !seen[want] {
+            t.Errorf("Expected a %s event", want)
+        }
+    }
+}
+
+func TestWebSocketStreamsEngineEvents(t *testing.T) {
+    stream := events.NewStream()
+    e, _ := engine.New("raft", engine.Config{Nodes: 3})
+    e = engine.Observe(e, stream)
+    server := api.NewServer(e)
+    server.Handle("GET /events", api.EventsHandler(stream))
+    httpServer := httptest.NewServer(server)
+    defer httpServer.Close()
+
+    wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/events?type=commit"
+    conn, err := websocket.Dial(wsURL, "", httpServer.URL)
+    if err != nil {
+        t.Fatalf("Failed to connect: %v", err)
+    }
+    defer conn.Close()
+
+    // The subscription is registered asynchronously, so keep submitting until an event arrives.
+    received := make(chan events.Event, 1)
+    go func() {
+        var event events.Event
+        if websocket.JSON.Receive(conn, &event) == nil {
+            received <- event
+        }
+    }()
+    deadline := time.After(5 * time.Second)
+    for {
+        e.Submit(context.Background(), "Test block")
+        select {
+        case event := <-received:
+            if event.Type != events.Commit || event.Data != "Test block" || event.Node != "node-0" {
+                t.Errorf("Unexpected event %+v", event)
+            }
+            return
+        case <-deadline:
+            t.Fatalf("Timed out waiting for an event")
+        case <-time.After(10 * time.Millisecond):
+        }
+    }
+}
+
+func TestBusCallsTypedHooks(t *testing.T) {
+    bus := events.NewBus()
+    var votes, commits, all []events.Event
+    bus.OnVote(func(e events.Event) { votes = append(votes, e) })
+    removeCommit := bus.OnCommit(func(e events.Event) { commits = append(commits, e) })
+    bus.OnEvent(func(e events.Event) { all = append(all, e) })
+
+    bus.Publish(events.Event{Type: events.Vote, Node: "node-1"})
+    bus.Publish(events.Event{Type: events.Commit, Node: "node-1"})
+    removeCommit()
+    bus.Publish(events.Event{Type: events.Commit, Node: "node-2"})
+
+    if len(votes) != 1 || votes[0].Seq != 1 || votes[0].Time.IsZero() {
+        t.Errorf("Expected one stamped vote, got %+v", votes)
+    }
+    if len(commits) != 1 || commits[0].Node != "node-1" {
+        t.Errorf("Expected only the commit published before the hook was removed, got %+v", commits)
+    }
+    if len(all) != 3 {
+        t.Errorf("Expected OnEvent to see 3 events, got %d", len(all))
+    }
+}
+
+func TestSimPublishesLeaderChanges(t *testing.T) {
+    bus := events.NewBus()
+    leaders := make(map[string]string)
+    bus.OnLeaderChange(func(e events.Event) { leaders[e.Node] = e.Leader })
+    elections := 0
+    bus.OnElection(func(e events.Event) { elections++ })
+    var commits []events.Event
+    bus.OnCommit(func(e events.Event) {
+        if e.Height > 0 { // Each replica reports the genesis block as its first commit.
+            commits = append(commits, e)
+        }
+    })
+
+    s := testutil.Raft(sim.Link{Latency: sim.Uniform(time.Millisecond, 5*time.Millisecond)}, options.WithNodes(3), options.WithSeed(1))
+    s.Events, s.Algorithm = bus, "raft"
+    s.RunFor(time.Second)
+
+    if elections == 0 {
+        t.Errorf("Expected at least one election")
+    }
+    if len(leaders) != 3 || leaders["node-0"] != leaders["node-1"] || leaders["node-1"] != leaders["node-2"] {
+        t.Fatalf("Expected every node to report the same leader, got %v", leaders)
+    }
+
+    var leader int32
+    for _, id := range s.Nodes() {
+        if s.Replica(id).(*raft.Replica).Role() == raft.Leader {
+            leader = id
+        }
+    }
+    if leaders["node-0"] != node.Name(leader) {
+        t.Errorf("Expected leader %s, got %s", node.Name(leader), leaders["node-0"])
+    }
+    if err := s.Propose(leader, "Test block 1"); err != nil {
+        t.Fatalf("Propose failed: %v", err)
+    }
+    s.RunFor(time.Second)
+    if len(commits) != 3 || commits[0].Time.Before(sim.Epoch) || commits[0].Data != "Test block 1" {
+        t.Errorf("Expected 3 commits stamped with virtual time, got %+v", commits)
+    }
+}
+
+func TestEnginePublishesAlgorithmEvents(t *testing.T) {
+    want := map[string][]events.Type{
+        "pow":   {events.Proposal, events.Commit},
+        "pos":   {events.Proposal, events.LeaderChange, events.Commit},
+        "dpos":  {events.Proposal, events.LeaderChange, events.Commit},
+        "pbft":  {events.Proposal, events.Vote, events.LeaderChange, events.Commit},
+        "raft":  {events.Proposal, events.LeaderChange, events.Commit},
+        "paxos": {events.Proposal, events.Vote, events.Commit},
+    }
+    for _, name := range engine.Algorithms() {
+        bus := events.NewBus()
+        seen := make(map[events.Type]int)
+        bus.OnEvent(func(e events.Event) {
+            if e.Algorithm != name {
+                t.Errorf("%s: Expected algorithm %q, got %q", name, name, e.Algorithm)
+            }
+            seen[e.Type]++
+        })
+        e, err := engine.New(name, engine.Config{Nodes: 4, Events: bus})
+        if err != nil {
+            t.Fatalf("New(%q) failed: %v", name, err)
+        }
+        for i := 0; i < 10; i++ {
+            if err := e.Submit(context.Background(), "Test block"); err != nil {
+                t.Fatalf("%s: Submit failed: %v", name, err)
+            }
+        }
+        for _, typ := range want[name] {
+            if seen[typ] == 0 {
+                t.Errorf("%s: Expected a %s event, got %v", name, typ, seen)
+            }
+        }
+        if seen[events.Commit] != 10 {
+            t.Errorf("%s: Expected 10 commit events, got %d", name, seen[events.Commit])
+        }
+    }
+}
+
+func TestFeedDeliversEverythingToSlowSubscribers(t *testing.T) {
+    var f feed.Feed[int]
+    slow, fast := f.Subscribe(), f.Subscribe()
+    for i := 1; i <= 100; i++ {
+        f.Publish(i) // Nobody reads yet; Publish must not wait.
+    }
+    for _, ch := range []<-chan int{fast, slow} {
+        for want := 1; want <= 100; want++ {
+            if got := <-ch; got != want {
+                t.Fatalf("Expected %d, got %d", want, got)
+            }
+        }
+    }
+
+    f.Unsubscribe(slow)
+    if _, open := <-slow; open {
+        t.Errorf("Expected Unsubscribe to close the channel")
+    }
+    if f.Len() != 1 {
+        t.Errorf("Expected 1 subscriber left, got %d", f.Len())
+    }
+    f.Unsubscribe(fast)
+}
+
+func TestChainAndEngineSubscribersReceiveCommittedBlocks(t *testing.T) {
+    blockchain := raft.NewRaftNetwork(options.WithNodes(3))
+    blocks := blockchain.Subscribe()
+    blockchain.Leader.Lead("Test block 1")
+    blockchain.Leader.Lead("Test block 2")
+    for want := 1; want <= 2; want++ {
+        if block := <-blocks; block.Index != want || block.Data != fmt.Sprintf("Test block %d", want) {
+            t.Errorf("Expected block %d, got %+v", want, block)
+        }
+    }
+    blockchain.Unsubscribe(blocks)
+    if _, open := <-blocks; open {
+        t.Errorf("Expected Unsubscribe to close the channel")
+    }
+
+    e, err := engine.New("pbft", engine.Config{Nodes: 4, Events: events.NewStream()}) // Subscribe works through Observe too.
+    if err != nil {
+        t.Fatal(err)
+    }
+    committed := e.Subscribe()
+    defer e.Unsubscribe(committed)
+    if err := e.Submit(context.Background(), "Subscribed"); err != nil {
+        t.Fatal(err)
+    }
+    select {
+    case block := <-committed:
+        if block.GetData() != "Subscribed" || block.GetHash() != e.Blocks()[1].GetHash() {
+            t.Errorf("Expected the committed block, got %v", block)
+        }
+    case <-time.After(time.Second):
+        t.Fatal("Expected a block on the engine's channel")
+    }
+}
@@ -0,0 +1,236 @@
+package tests
+
+import (
+    "errors"
+    "fmt"
+    "slices"
+    "strings"
+    "testing"
+    "time"
+    "google.golang.org/protobuf/proto"
+    "consensus-algorithms-edu/adversary"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/evidence"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+)
+
+// watched adds every replica to s behind an evidence watcher, relaying statements if relay is set, and wraps the
+// replicas listed in faulty with the given strategies first. It returns the watchers by ID.
+func watched(s *sim.Simulator, replicas []node.Replica, relay bool, faulty map[int32][]adversary.Strategy) []*evidence.Replica {
+    peers := make([]int32, len(replicas))
+    for i := range replicas {
+        peers[i] = int32(i)
+    }
+    private, keys := evidence.GenerateKeys(1, peers)
+    watchers := make([]*evidence.Replica, len(replicas))
+    for i, r := range replicas {
+        if strategies, ok := faulty[int32(i)]; ok {
+            r = adversary.Wrap(r, strategies...)
+        }
+        watchers[i] = evidence.Watch(r, evidence.Config{Key: private[int32(i)], Keys: keys, Peers: peers, Relay: relay})
+        s.Add(watchers[i])
+    }
+    return watchers
+}
+
+func TestEvidenceConvictsAndSlashesEquivocatingProposer(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 1, Network: sim.Link{Latency: sim.Constant(10 * time.Millisecond)}})
+    peers := []int32{0, 1, 2, 3}
+    stakes := map[int32]int{0: 25, 1: 25, 2: 25, 3: 25}
+    validators := make([]node.Replica, len(peers))
+    for i, id := range peers {
+        validators[i] = pos.NewValidator(pos.ValidatorConfig{ID: id, Peers: peers, Stakes: stakes, Clock: s.Clock()})
+    }
+    forger := adversary.NewForger(adversary.Hashes["pos"])
+    watchers := watched(s, validators, true, map[int32][]adversary.Strategy{0: {adversary.Equivocate(forger, 3)}})
+    s.RunFor(5 * time.Second)
+
+    for _, w := range watchers[1:] {
+        if !w.Detector().Convicted(0) {
+            t.Fatalf("Expected node %d to convict the equivocating proposer", w.ID())
+        }
+        for _, other := range peers[1:] {
+            if w.Detector().Convicted(other) {
+                t.Errorf("Expected honest node %d not to be convicted by node %d", other, w.ID())
+            }
+        }
+        for slot := 0; slot < 100; slot++ {
+            if validators[w.ID()].(*pos.Validator).Proposer(slot) == 0 {
+                t.Errorf("Expected node %d to slash the offender out of the schedule, but it proposes in slot %d", w.ID(), slot)
+                break
+            }
+        }
+    }
+
+    // Evidence is portable: after a round trip over the wire, anyone with the keys can check it.
+    ev := watchers[1].Detector().Evidence()[0]
+    data, err := proto.Marshal(&wire.Envelope{From: 1, To: 2, Body: &wire.Envelope_Evidence{Evidence: ev}})
+    if err != nil {
+        t.Fatalf("Marshal failed: %v", err)
+    }
+    env, err := wire.DecodeEnvelope(data)
+    if err != nil {
+        t.Fatalf("DecodeEnvelope failed: %v", err)
+    }
+    _, keys := evidence.GenerateKeys(1, peers)
+    if err := keys.Check(env.GetEvidence()); err != nil {
+        t.Errorf("Expected decoded evidence to verify, got %v", err)
+    }
+    if got := evidence.Offender(env.GetEvidence()); got != 0 {
+        t.Errorf("Expected the evidence to name node 0, got %d", got)
+    }
+}
+
+func TestEvidenceConvictsEquivocatingPrimary(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 1, Network: pbftLink})
+    peers := []int32{0, 1, 2, 3}
+    replicas := make([]node.Replica, len(peers))
+    for i, id := range peers {
+        replicas[i] = pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: peers, Clock: s.Clock()})
+    }
+    forger := adversary.NewForger(adversary.Hashes["pbft"])
+    watchers := watched(s, replicas, true, map[int32][]adversary.Strategy{0: {adversary.Equivocate(forger, 2)}})
+    s.Propose(0, "Test block 1")
+    s.RunFor(5 * time.Second)
+
+    for _, w := range watchers[1:] {
+        if !w.Detector().Convicted(0) {
+            t.Errorf("Expected node %d to convict the equivocating primary", w.ID())
+        }
+        if n := len(w.Detector().Evidence()); n != 1 {
+            t.Errorf("Expected evidence against one node at node %d, got %d", w.ID(), n)
+        }
+    }
+}
+
+func TestEvidenceHonestRunsConvictNobody(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 1, Network: pbftLink})
+    peers := []int32{0, 1, 2, 3}
+    replicas := make([]node.Replica, len(peers))
+    for i, id := range peers {
+        replicas[i] = pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: peers, Clock: s.Clock()})
+    }
+    watchers := watched(s, replicas, true, nil)
+    for i := 1; i <= 5; i++ {
+        s.Propose(0, fmt.Sprintf("Test block %d", i))
+    }
+    s.RunFor(5 * time.Second)
+    for _, w := range watchers {
+        if n := len(w.Detector().Evidence()); n != 0 {
+            t.Errorf("Expected no evidence at node %d, got %d", w.ID(), n)
+        }
+        if n := len(w.Committed()); n != 6 {
+            t.Errorf("Expected node %d to commit 5 signed blocks, got %d", w.ID(), n-1)
+        }
+    }
+
+    s = sim.New(sim.Config{Seed: 1, Network: sim.Link{Latency: sim.Constant(10 * time.Millisecond)}})
+    stakes := map[int32]int{0: 40, 1: 30, 2: 20, 3: 10}
+    for i, id := range peers {
+        replicas[i] = pos.NewValidator(pos.ValidatorConfig{ID: id, Peers: peers, Stakes: stakes, Clock: s.Clock()})
+    }
+    watchers = watched(s, replicas, true, nil)
+    s.RunFor(10 * time.Second)
+    for _, w := range watchers {
+        if n := len(w.Detector().Evidence()); n != 0 {
+            t.Errorf("Expected no evidence at validator %d, got %d", w.ID(), n)
+        }
+    }
+    if n := len(replicas[0].(*pos.Validator).Chain()); n < 10 {
+        t.Errorf("Expected signed validators to keep building a chain, got %d blocks", n)
+    }
+}
+
+func TestEvidenceForensicsConvictsMoreThanFColluders(t *testing.T) {
+    // Two colluders out of four exceed f = 1 and split the honest replicas; nobody relays, so nobody notices.
+    s := sim.New(sim.Config{Seed: 1, Network: pbftLink})
+    peers := []int32{0, 1, 2, 3}
+    replicas := make([]node.Replica, len(peers))
+    for i, id := range peers {
+        replicas[i] = pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: peers, Clock: s.Clock()})
+    }
+    collusion := adversary.NewCollusion(adversary.Hashes["pbft"], 2)
+    watchers := watched(s, replicas, false, map[int32][]adversary.Strategy{0: {collusion.Member()}, 1: {collusion.Member()}})
+    s.Propose(0, "Test block 1")
+    s.RunFor(5 * time.Second)
+    if len(watchers[2].Detector().Evidence())+len(watchers[3].Detector().Evidence()) != 0 {
+        t.Fatalf("Expected the honest replicas to see no equivocation on their own")
+    }
+
+    _, keys := evidence.GenerateKeys(1, peers)
+    report := evidence.Investigate(keys, watchers[2], watchers[3])
+    if report.Safe() || len(report.Violations) != 1 || report.Violations[0].Height != 1 {
+        t.Fatalf("Expected one safety violation at height 1, got %+v", report.Violations)
+    }
+    if got := report.Culprits(); !slices.Equal(got, []int32{0, 1}) {
+        t.Errorf("Expected the colluders 0 and 1 to be convicted, got %v", got)
+    }
+    if !report.Accountable(1) {
+        t.Errorf("Expected at least f+1 culprits for a violation with f = 1")
+    }
+    for _, ev := range report.Evidence {
+        if err := keys.Check(ev); err != nil {
+            t.Errorf("Expected every piece of evidence to verify, got %v", err)
+        }
+    }
+    var out strings.Builder
+    if err := report.Print(&out); err != nil || !strings.Contains(out.String(), "safety violation at height 1") || !strings.Contains(out.String(), "2 culprits") {
+        t.Errorf("Expected the report to name the violation and its culprits, got %v:\n%s", err, out.String())
+    }
+
+    // Investigating the honest replicas of an honest run finds nothing.
+    s = sim.New(sim.Config{Seed: 1, Network: pbftLink})
+    for i, id := range peers {
+        replicas[i] = pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: peers, Clock: s.Clock()})
+    }
+    watchers = watched(s, replicas, false, nil)
+    s.Propose(0, "Test block 1")
+    s.RunFor(5 * time.Second)
+    if report := evidence.Investigate(keys, watchers...); !report.Safe() || len(report.Evidence) != 0 {
+        t.Errorf("Expected an honest run to be safe and blame nobody, got %d violations and %d culprits", len(report.Violations), len(report.Evidence))
+    }
+}
+
+func TestEvidenceRejectsTamperedOrFramingEvidence(t *testing.T) {
+    private, keys := evidence.GenerateKeys(1, []int32{0, 1})
+    statement := func(signer int32, value string) *wire.Statement {
+        st := &wire.Statement{Signer: signer, Kind: evidence.Prepare, Height: 1, Round: 0, Value: value}
+        evidence.Sign(private[signer], st)
+        return st
+    }
+    d := evidence.NewDetector(keys)
+    if ev, err := d.Observe(statement(0, "a")); ev != nil || err != nil {
+        t.Fatalf("Expected a first statement to prove nothing, got %v, %v", ev, err)
+    }
+    if ev, err := d.Observe(statement(0, "a")); ev != nil || err != nil {
+        t.Errorf("Expected a repeated statement to prove nothing, got %v, %v", ev, err)
+    }
+    ev, err := d.Observe(statement(0, "b"))
+    if err != nil || ev == nil || evidence.Offender(ev) != 0 {
+        t.Fatalf("Expected a conflicting statement to convict node 0, got %v, %v", ev, err)
+    }
+    if err := keys.Check(ev); err != nil {
+        t.Errorf("Expected the evidence to verify, got %v", err)
+    }
+
+    tampered := proto.Clone(ev).(*wire.Evidence)
+    tampered.Second.Value = "c"
+    if err := keys.Check(tampered); !errors.Is(err, evidence.ErrUnsigned) {
+        t.Errorf("Expected ErrUnsigned for an altered statement, got %v", err)
+    }
+    // Node 1 tries to frame node 0 with a statement it signed itself.
+    framed := &wire.Evidence{First: statement(0, "a"), Second: statement(1, "b")}
+    framed.Second.Signer = 0
+    if _, err := evidence.NewDetector(keys).Convict(framed); !errors.Is(err, evidence.ErrUnsigned) {
+        t.Errorf("Expected ErrUnsigned for a statement signed by another node, got %v", err)
+    }
+    if err := keys.Check(&wire.Evidence{First: statement(0, "a"), Second: statement(0, "a")}); !errors.Is(err, evidence.ErrNoConflict) {
+        t.Errorf("Expected ErrNoConflict for two equal statements, got %v", err)
+    }
+    if _, err := d.Observe(&wire.Statement{Signer: 2, Kind: evidence.Commit, Value: "a"}); !errors.Is(err, evidence.ErrUnknown) {
+        t.Errorf("Expected ErrUnknown for a statement by a node without a key, got %v", err)
+    }
+}
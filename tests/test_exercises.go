@@ -0,0 +1,100 @@
+package tests
+
+import (
+    "errors"
+    "strings"
+    "testing"
+    "consensus-algorithms-edu/exercises"
+    "consensus-algorithms-edu/exercises/student"
+    "consensus-algorithms-edu/wire"
+)
+
+// reference solves every exercise the way the algorithm packages implement the rules.
+func reference() exercises.Solution {
+    return exercises.Solution{
+        RaftVote: func(v exercises.Voter, req *wire.RequestVote) bool {
+            if req.GetTerm() < v.Term {
+                return false
+            }
+            if req.GetTerm() == v.Term && v.VotedFor != exercises.NoVote && v.VotedFor != req.GetCandidateId() {
+                return false
+            }
+            return req.GetLastLogTerm() > v.LastLogTerm || (req.GetLastLogTerm() == v.LastLogTerm && req.GetLastLogIndex() >= v.LastLogIndex)
+        },
+        PBFTQuorum: func(n int) int { return 2*((n-1)/3) + 1 },
+        PoWMeetsDifficulty: func(hash string, difficulty int) bool {
+            return len(hash) >= difficulty && strings.Count(hash[:difficulty], "0") == difficulty
+        },
+        PoSSelect: func(stakes []int, pick int) int {
+            total := 0
+            for i, stake := range stakes {
+                if total += stake; total > pick {
+                    return i
+                }
+            }
+            return -1
+        },
+        PaxosPrepare: func(a *exercises.Acceptor, m *wire.PaxosPrepare) *wire.PaxosPromise {
+            if m.GetBallot() <= a.Promised {
+                return nil
+            }
+            a.Promised = m.GetBallot()
+            return &wire.PaxosPromise{Ballot: m.GetBallot(), Slot: m.GetSlot(), AcceptedBallot: a.AcceptedBallot, AcceptedValue: a.AcceptedValue}
+        },
+    }
+}
+
+func TestExercisesReferenceScoresFull(t *testing.T) {
+    report, err := exercises.Grade(reference())
+    if err != nil {
+        t.Fatalf("Failed to grade: %v", err)
+    }
+    if report.Score != report.Max || report.Max == 0 {
+        var out strings.Builder
+        report.Print(&out)
+        t.Errorf("Expected full marks for the reference solution, got:\n%s", out.String())
+    }
+}
+
+func TestExercisesSkeletonNotAttempted(t *testing.T) {
+    report, _ := exercises.Grade(student.Solution())
+    if report.Score != 0 || len(report.Results) != len(exercises.Exercises) {
+        t.Fatalf("Expected a score of 0 for every exercise, got %d", report.Score)
+    }
+    for _, result := range report.Results {
+        if result.Attempted {
+            t.Errorf("%s: Expected the unchanged skeleton to count as not attempted", result.Exercise.Name)
+        }
+    }
+}
+
+func TestExercisesGradePartialSolution(t *testing.T) {
+    s := reference()
+    s.PBFTQuorum = func(n int) int { return n/2 + 1 } // A crash-fault majority, not a Byzantine quorum.
+    s.RaftVote = func(v exercises.Voter, req *wire.RequestVote) bool {
+        return req.GetTerm() >= v.Term // Forgets the one vote per term and the election restriction.
+    }
+    s.PoSSelect = nil
+    report, err := exercises.Grade(s, "pbft-quorum", "raft-vote", "pos-select")
+    if err != nil {
+        t.Fatalf("Failed to grade: %v", err)
+    }
+    results := make(map[string]exercises.Result)
+    for _, result := range report.Results {
+        results[result.Exercise.Name] = result
+    }
+    quorum, vote, pos := results["pbft-quorum"], results["raft-vote"], results["pos-select"]
+    if quorum.Score == 0 || quorum.Score == quorum.Max {
+        t.Errorf("Expected a majority quorum to pass some PBFT scenarios but not all, got %d/%d", quorum.Score, quorum.Max)
+    }
+    if vote.Score == vote.Max {
+        t.Errorf("Expected a vote rule without the election restriction to fail, got %d/%d", vote.Score, vote.Max)
+    }
+    if pos.Attempted || pos.Score != 0 {
+        t.Errorf("Expected a missing PoS solution to count as not attempted")
+    }
+
+    if _, err := exercises.Grade(s, "tendermint-vote"); !errors.Is(err, exercises.ErrUnknownExercise) {
+        t.Errorf("Expected ErrUnknownExercise, got %v", err)
+    }
+}
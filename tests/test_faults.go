@@ -0,0 +1,167 @@
+package tests
+
+import (
+    "fmt"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/testutil"
+)
+
+// pbftLink is the network the PBFT tests run on: fast, but uneven enough to interleave the phases.
+var pbftLink = sim.Link{Latency: sim.Uniform(time.Millisecond, 5*time.Millisecond)}
+
+func TestFaultDropByMessageType(t *testing.T) {
+    c := testutil.Raft(sim.Link{Latency: sim.Constant(time.Millisecond)}, options.WithNodes(3), options.WithSeed(1))
+    c.Network.Inject(sim.Fault{Kind: "RequestVote", Drop: 1})
+
+    c.RunFor(5 * time.Second)
+    if leader, ok := c.Leader(); ok {
+        t.Errorf("Expected no leader while every RequestVote is dropped, got node %d", leader.ID())
+    }
+
+    c.Network.ClearFaults()
+    if _, ok := c.WaitForLeader(5 * time.Second); !ok {
+        t.Errorf("Expected a leader once the fault was cleared")
+    }
+}
+
+func TestFaultDelayedHeartbeatsCauseElections(t *testing.T) {
+    c := testutil.Raft(sim.Link{Latency: sim.Constant(time.Millisecond)}, options.WithNodes(3), options.WithSeed(1))
+    leader, ok := c.WaitForLeader(5 * time.Second)
+    if !ok {
+        t.Fatalf("No leader elected")
+    }
+    term := leader.Term()
+
+    c.Network.Inject(sim.Fault{Kind: "AppendEntries", Match: sim.Heartbeat, Delay: sim.Constant(500 * time.Millisecond)})
+    c.RunFor(2 * time.Second)
+    elected := false
+    for _, r := range c.Replicas {
+        elected = elected || r.Term() > term
+    }
+    if !elected {
+        t.Errorf("Expected new elections while heartbeats are delayed, still in term %d", term)
+    }
+}
+
+func TestFaultDuplicationAndReordering(t *testing.T) {
+    c := testutil.PBFT(pbftLink, options.WithSeed(3))
+    c.Network.Inject(sim.Fault{Duplicate: 0.5, Reorder: 0.5, ReorderWindow: 20 * time.Millisecond})
+
+    for i := 1; i <= 5; i++ {
+        c.Propose(0, fmt.Sprintf("Test block %d", i))
+    }
+    if !c.RunUntil(c.AllCommitted(6), 30*time.Second) {
+        t.Fatalf("Blocks were not committed on every replica")
+    }
+    if c.Stats().Duplicated == 0 {
+        t.Errorf("Expected duplicated messages, got %+v", c.Stats())
+    }
+
+    for _, r := range c.Replicas {
+        testutil.AssertSameChain(t, c.Replicas[0].Committed(), r.Committed())
+    }
+}
+
+func TestFaultDroppedPreparesStillCommit(t *testing.T) {
+    c := testutil.PBFT(pbftLink, options.WithSeed(4))
+    c.Network.Inject(sim.Fault{Kind: "Prepare", Drop: 0.2})
+
+    c.Propose(0, "Test block 1")
+    if !c.RunUntil(c.AllCommitted(2), 30*time.Second) {
+        t.Errorf("Expected the block to be committed despite dropped Prepare messages")
+    }
+}
+
+func TestSlowLeaderKeepsLeadershipButStallsCommits(t *testing.T) {
+    c := testutil.Raft(sim.Link{Latency: sim.Constant(time.Millisecond)}, options.WithNodes(3), options.WithSeed(1))
+    leader, ok := c.WaitForLeader(5 * time.Second)
+    if !ok {
+        t.Fatalf("No leader elected")
+    }
+    if err := c.Slow(leader.ID(), sim.Constant(300*time.Millisecond)); err != nil {
+        t.Fatalf("Slow failed: %v", err)
+    }
+    if err := c.Slow(99, sim.Constant(time.Millisecond)); err != sim.ErrUnknownNode {
+        t.Errorf("Expected ErrUnknownNode for a replica never added, got %v", err)
+    }
+
+    c.Propose(leader.ID(), "Test block 1")
+    c.RunFor(2 * time.Second)
+    if now, ok := c.Leader(); !ok || now.ID() != leader.ID() || now.Term() != leader.Term() {
+        t.Errorf("Expected the slow leader to keep its leadership with heartbeats on time")
+    }
+    for _, r := range c.Replicas {
+        if n := len(r.Committed()); n != 1 {
+            t.Errorf("Expected the block to wait behind the slow leader's backlog, node %d committed %d blocks", r.ID(), n)
+        }
+    }
+}
+
+func TestSlowPrimaryIsReplacedByViewChange(t *testing.T) {
+    c := testutil.PBFT(pbftLink, options.WithSeed(5))
+    c.Slow(0, sim.Constant(300*time.Millisecond))
+    for _, r := range c.Replicas {
+        c.Propose(r.ID(), "Test block 1") // Backups forward the request and suspect the primary if it lags.
+    }
+    backups := func() bool {
+        return len(c.Replicas[1].Committed()) == 2 && len(c.Replicas[2].Committed()) == 2 && len(c.Replicas[3].Committed()) == 2
+    }
+    if !c.RunUntil(backups, 5*time.Second) {
+        t.Fatalf("Expected the backups to commit the block despite the slow primary")
+    }
+    if view := c.Replicas[1].View(); view == 0 {
+        t.Errorf("Expected the backups to replace the slow primary, still in view %d", view)
+    }
+}
+
+func TestCrashedLeaderIsReplacedAndCatchesUp(t *testing.T) {
+    c := testutil.Raft(sim.Link{Latency: sim.Constant(time.Millisecond)}, options.WithNodes(3), options.WithSeed(1))
+    old, ok := c.WaitForLeader(5 * time.Second)
+    if !ok {
+        t.Fatalf("No leader elected")
+    }
+    c.Propose(old.ID(), "Test block 1")
+    c.RunUntil(c.AllCommitted(2), c.Now()+5*time.Second)
+
+    if err := c.Crash(old.ID()); err != nil {
+        t.Fatalf("Crash failed: %v", err)
+    }
+    if err := c.Crash(99); err != sim.ErrUnknownNode {
+        t.Errorf("Expected ErrUnknownNode for a replica never added, got %v", err)
+    }
+    if err := c.Propose(old.ID(), "Lost block"); err != node.ErrCrashed {
+        t.Errorf("Expected the crashed leader to refuse proposals with ErrCrashed, got %v", err)
+    }
+    term := old.Term()
+    replaced := func() bool {
+        leader, ok := c.Leader()
+        return ok && leader != old
+    }
+    if !c.RunUntil(replaced, c.Now()+5*time.Second) {
+        t.Fatalf("The survivors did not elect a new leader")
+    }
+    leader, _ := c.Leader()
+    c.Propose(leader.ID(), "Test block 2")
+    c.RunFor(time.Second)
+    if n := len(old.Committed()); n != 2 || old.Term() != term {
+        t.Errorf("Expected the crashed leader to stand still, got %d blocks in term %d", n, old.Term())
+    }
+
+    if err := c.Recover(leader.ID()); err != node.ErrNotCrashed {
+        t.Errorf("Expected ErrNotCrashed for a running replica, got %v", err)
+    }
+    if err := c.Recover(old.ID()); err != nil {
+        t.Fatalf("Recover failed: %v", err)
+    }
+    if !c.RunUntil(c.AllCommitted(3), c.Now()+5*time.Second) {
+        t.Fatalf("The recovered leader did not catch up")
+    }
+    if c.Crashed(old.ID()) || old.Leader() != leader.ID() {
+        t.Errorf("Expected the recovered replica to follow node %d, follows %d", leader.ID(), old.Leader())
+    }
+    testutil.AssertAgreement(t, c.Chains())
+}
@@ -0,0 +1,184 @@
+package tests
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "testing"
+    "time"
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/proto"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/testutil"
+    "consensus-algorithms-edu/transport"
+    "consensus-algorithms-edu/wire"
+)
+
+// fuzzBlocks returns well-formed blocks to seed the decoding targets with.
+func fuzzBlocks() []*wire.Block {
+    genesis := pbft.GenesisBlock()
+    block := pbft.NewBlock("Test block 1", genesis.Hash, 1)
+    block.Certificate = []string{"node-0", "node-1", "node-2"}
+    return []*wire.Block{genesis.ToWire(), block.ToWire(), {Index: 2, PrevHash: block.Hash.String(), Hash: "00ab", Nonce: 7, Difficulty: 2}}
+}
+
+// fuzzEnvelopes returns one well-formed envelope of each algorithm's messages to seed the envelope targets with.
+func fuzzEnvelopes() []*wire.Envelope {
+    block := fuzzBlocks()[1]
+    pp := &wire.PrePrepare{View: 0, Sequence: 1, Digest: block.GetHash(), Block: block}
+    return []*wire.Envelope{
+        {From: 1, To: 0, Body: &wire.Envelope_RequestVote{RequestVote: &wire.RequestVote{Term: 2, CandidateId: 1}}},
+        {From: 1, To: 0, Body: &wire.Envelope_AppendEntries{AppendEntries: &wire.AppendEntries{Term: 1, LeaderId: 1, Entries: []*wire.Entry{{Term: 1, Block: block}}, LeaderCommit: 1}}},
+        {From: 1, To: 0, Body: &wire.Envelope_AppendEntriesResponse{AppendEntriesResponse: &wire.AppendEntriesResponse{Term: 1, Success: true, MatchIndex: 1}}},
+        {From: 0, To: 1, Body: &wire.Envelope_PrePrepare{PrePrepare: pp}},
+        {From: 2, To: 1, Body: &wire.Envelope_Commit{Commit: &wire.Commit{Sequence: 1, Digest: block.GetHash()}}},
+        {From: 2, To: 1, Body: &wire.Envelope_ViewChange{ViewChange: &wire.ViewChange{NewView: 1, LastSequence: 0, Prepared: []*wire.PrePrepare{pp}, ReplicaId: 2}}},
+        {From: 0, To: 1, Body: &wire.Envelope_PaxosAccept{PaxosAccept: &wire.PaxosAccept{Ballot: 3, Slot: 1, Value: block}}},
+        {From: 0, To: 1, Body: &wire.Envelope_BlockProposal{BlockProposal: &wire.BlockProposal{Block: block}}},
+    }
+}
+
+func FuzzDecodeBlock(f *testing.F) {
+    for _, block := range fuzzBlocks() {
+        data, _ := proto.Marshal(block)
+        f.Add(data)
+    }
+    f.Add([]byte{0x08, 0x7f}) // A negative index.
+    f.Fuzz(func(t *testing.T, data []byte) {
+        block, err := wire.DecodeBlock(data)
+        if err != nil {
+            if !errors.Is(err, wire.ErrMalformed) {
+                t.Errorf("Expected a malformed block to be reported as ErrMalformed, got %v", err)
+            }
+            return
+        }
+        encoded, err := proto.Marshal(block)
+        if err != nil {
+            t.Fatalf("Failed to encode a decoded block: %v", err)
+        }
+        again, err := wire.DecodeBlock(encoded)
+        if err != nil || !proto.Equal(block, again) {
+            t.Errorf("Expected a decoded block to survive re-encoding, got %v, %v", again, err)
+        }
+    })
+}
+
+func FuzzDecodeBlockJSON(f *testing.F) {
+    for _, block := range fuzzBlocks() {
+        data, _ := protojson.Marshal(block)
+        f.Add(data)
+    }
+    f.Add([]byte(`{"index": "1", "hash": "ab"}`)) // No parent hash.
+    f.Fuzz(func(t *testing.T, data []byte) {
+        block, err := wire.DecodeBlockJSON(data)
+        if err != nil {
+            if !errors.Is(err, wire.ErrMalformed) {
+                t.Errorf("Expected malformed JSON to be reported as ErrMalformed, got %v", err)
+            }
+            return
+        }
+        encoded, err := protojson.Marshal(block)
+        if err != nil {
+            t.Fatalf("Failed to encode a decoded block: %v", err)
+        }
+        again, err := wire.DecodeBlockJSON(encoded)
+        if err != nil || !proto.Equal(block, again) {
+            t.Errorf("Expected a decoded block to survive re-encoding, got %v, %v", again, err)
+        }
+    })
+}
+
+// FuzzDecodeEnvelope feeds every envelope that decodes to a replica of each message-driven algorithm. Whatever a
+// peer sends, a replica may ignore it or answer it, but it must never crash.
+func FuzzDecodeEnvelope(f *testing.F) {
+    for _, env := range fuzzEnvelopes() {
+        data, _ := proto.Marshal(env)
+        f.Add(data)
+    }
+    link := sim.Link{Latency: sim.Constant(time.Millisecond)}
+    f.Fuzz(func(t *testing.T, data []byte) {
+        env, err := wire.DecodeEnvelope(data)
+        if err != nil {
+            if !errors.Is(err, wire.ErrMalformed) {
+                t.Errorf("Expected a malformed envelope to be reported as ErrMalformed, got %v", err)
+            }
+            return
+        }
+        if env.Kind() == "Unknown" {
+            t.Errorf("Expected a decoded envelope to carry a message, got %v", env)
+        }
+        replicas := []node.Replica{
+            testutil.Raft(link, options.WithNodes(4)).Replicas[1],
+            testutil.PBFT(link, options.WithNodes(4)).Replicas[1],
+            testutil.PoW(link, options.WithNodes(4)).Replicas[1],
+            testutil.PoS(link, nil, options.WithNodes(4)).Replicas[1],
+            testutil.DPoS(link, options.WithNodes(4)).Replicas[1],
+        }
+        for _, r := range replicas {
+            r.Step(env)
+            r.Step(env) // Networks duplicate messages.
+            r.Tick()
+            r.Committed()
+        }
+    })
+}
+
+func FuzzReadFrame(f *testing.F) {
+    for _, env := range fuzzEnvelopes() {
+        var frame bytes.Buffer
+        transport.WriteFrame(&frame, env)
+        f.Add(frame.Bytes())
+    }
+    f.Add([]byte{0xff, 0xff, 0xff, 0xff}) // A length prefix far beyond the largest frame.
+    f.Fuzz(func(t *testing.T, data []byte) {
+        env, err := transport.ReadFrame(bytes.NewReader(data))
+        if err != nil {
+            return
+        }
+        var frame bytes.Buffer
+        if err := transport.WriteFrame(&frame, env); err != nil {
+            t.Fatalf("Failed to write a frame that was read: %v", err)
+        }
+        again, err := transport.ReadFrame(&frame)
+        if err != nil || !proto.Equal(env, again) {
+            t.Errorf("Expected a frame to survive being written again, got %v, %v", again, err)
+        }
+    })
+}
+
+// FuzzValidateDetectsTampering changes the data of one block in a valid chain of each algorithm and expects
+// engine.Validate to notice, since every algorithm's hash covers the block's data.
+func FuzzValidateDetectsTampering(f *testing.F) {
+    chains := make(map[string][]*wire.Block)
+    for _, algorithm := range engine.Algorithms() {
+        e, _ := testutil.Engine(f, algorithm, options.WithNodes(4))
+        e.Submit(context.Background(), "Test block 1")
+        e.Submit(context.Background(), "Test block 2")
+        chains[algorithm] = e.Blocks()
+        if err := engine.ValidateChain(e); err != nil {
+            f.Fatalf("Expected the %s chain to be valid, got %v", algorithm, err)
+        }
+    }
+    f.Add(uint8(1), "Tampered")
+    f.Add(uint8(0), "")
+    f.Fuzz(func(t *testing.T, index uint8, data string) {
+        for algorithm, chain := range chains {
+            blocks := make([]*wire.Block, len(chain))
+            for i, block := range chain {
+                blocks[i] = proto.Clone(block).(*wire.Block)
+            }
+            tampered := blocks[int(index)%len(blocks)]
+            if tampered.GetData() == data {
+                continue
+            }
+            tampered.Data = data
+            if err := engine.Validate(algorithm, blocks, nil); err == nil {
+                t.Errorf("%s: expected changing the data of block %d to %q to invalidate the chain", algorithm, tampered.GetIndex(), data)
+            }
+        }
+    })
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+    "context"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/genesis"
+)
+
+func TestGenesisParsesJSONAndYAMLAlike(t *testing.T) {
+    fromYAML, err := genesis.Parse([]byte("algorithm: pos\ntimestamp: 2024-01-01T00:00:00Z\nvalidators:\n  - {id: alice, stake: 70}\n  - {id: bob, stake: 30}\n"), "yaml")
+    if err != nil {
+        t.Fatalf("Failed to parse YAML: %v", err)
+    }
+    fromJSON, err := genesis.Parse([]byte(`{"algorithm": "pos", "timestamp": "2024-01-01T00:00:00Z",
+        "validators": [{"id": "alice", "stake": 70}, {"id": "bob", "stake": 30}]}`), "json")
+    if err != nil {
+        t.Fatalf("Failed to parse JSON: %v", err)
+    }
+
+    for _, spec := range []*genesis.Spec{fromYAML, fromJSON} {
+        names, stakes := spec.Stakes()
+        if len(names) != 2 || names[0] != "alice" || stakes["bob"] != 30 {
+            t.Errorf("Expected validators alice and bob with their stakes, got %v %v", names, stakes)
+        }
+        if !spec.Timestamp.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+            t.Errorf("Expected the timestamp to be parsed, got %v", spec.Timestamp)
+        }
+        if spec.GenesisData() != genesis.DefaultData {
+            t.Errorf("Expected the default genesis data, got %q", spec.GenesisData())
+        }
+    }
+}
+
+func TestGenesisRejectsInvalidSpecs(t *testing.T) {
+    cases := map[string]string{
+        "unknown field":       "algorithm: pos\nstakes: 10\n",
+        "validator no stake":  "validators:\n  - {id: alice, stake: 0}\n",
+        "duplicate validator": "validators:\n  - {id: alice, stake: 1}\n  - {id: alice, stake: 2}\n",
+        "vote for outsider":   "delegates: [a, b]\nvotes: {voter-1: c}\n",
+        "negative difficulty": "difficulty: -1\n",
+        "impossible target":   "difficulty: 65\n",
+    }
+    for name, spec := range cases {
+        if _, err := genesis.Parse([]byte(spec), "yaml"); err == nil {
+            t.Errorf("%s: expected the spec to be rejected", name)
+        }
+    }
+    if _, err := genesis.Parse([]byte("nodes: 3"), "toml"); err == nil {
+        t.Errorf("Expected an unknown format to be rejected")
+    }
+}
+
+func TestGenesisExampleSpecsLoad(t *testing.T) {
+    paths, err := filepath.Glob("../examples/genesis/*")
+    if err != nil || len(paths) == 0 {
+        t.Fatalf("Expected example specs, got %v (%v)", paths, err)
+    }
+    for _, path := range paths {
+        if _, err := genesis.Load(path); err != nil {
+            t.Errorf("Failed to load %s: %v", path, err)
+        }
+    }
+}
+
+func TestEngineStartsFromGenesisSpec(t *testing.T) {
+    spec := &genesis.Spec{
+        Timestamp:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+        Data:       "Lecture 3",
+        Validators: []genesis.Validator{{ID: "alice", Stake: 70}, {ID: "bob", Stake: 30}},
+    }
+    first, err := engine.New("pos", engine.Config{Genesis: spec})
+    if err != nil {
+        t.Fatalf("Failed to start from the spec: %v", err)
+    }
+    second, _ := engine.New("pos", engine.Config{Genesis: spec})
+    if first.Blocks()[0].GetHash() != second.Blocks()[0].GetHash() {
+        t.Errorf("Expected two engines started from one spec to share the genesis block")
+    }
+    if g := first.Blocks()[0]; g.GetData() != "Lecture 3" || g.GetProducer() != "alice" {
+        t.Errorf("Expected the spec's genesis block, got %q by %q", g.GetData(), g.GetProducer())
+    }
+    participants := first.Participants()
+    if len(participants) != 2 || participants[0].ID != "alice" || participants[0].Stake != 70 {
+        t.Errorf("Expected the spec's validators, got %+v", participants)
+    }
+
+    spec.Algorithm = "pos"
+    if _, err := engine.New("raft", engine.Config{Genesis: spec}); err == nil || !strings.Contains(err.Error(), `"pos"`) {
+        t.Errorf("Expected a spec for pos to be rejected by raft, got %v", err)
+    }
+}
+
+func TestEngineGenesisSetsNodesAndVotes(t *testing.T) {
+    e, err := engine.New("pbft", engine.Config{Nodes: 4, Genesis: &genesis.Spec{Nodes: 7}})
+    if err != nil {
+        t.Fatalf("Failed to start from the spec: %v", err)
+    }
+    if n := e.Status().Nodes; n != 7 {
+        t.Errorf("Expected the spec's 7 nodes to override the caller's 4, got %d", n)
+    }
+
+    e, err = engine.New("dpos", engine.Config{Genesis: &genesis.Spec{
+        Delegates: []string{"a", "b", "c"},
+        Votes:     map[string]string{"v1": "a", "v2": "a", "v3": "b"},
+    }})
+    if err != nil {
+        t.Fatalf("Failed to start from the spec: %v", err)
+    }
+    votes := make(map[string]int)
+    for _, p := range e.Participants() {
+        votes[p.ID] = p.Votes
+    }
+    if len(votes) != 2 || votes["a"] != 2 || votes["b"] != 1 {
+        t.Errorf("Expected a and b to be elected with 2 and 1 votes, got %v", votes)
+    }
+}
+
+func TestEngineGenesisSetsDifficulty(t *testing.T) {
+    e, err := engine.New("pow", engine.Config{Genesis: &genesis.Spec{Difficulty: 2}})
+    if err != nil {
+        t.Fatalf("Failed to start from the spec: %v", err)
+    }
+    if err := e.Submit(context.Background(), "Easy block"); err != nil {
+        t.Fatalf("Failed to mine: %v", err)
+    }
+    blocks := e.Blocks()
+    for _, block := range blocks {
+        if block.GetDifficulty() != 2 {
+            t.Errorf("Expected every block to record difficulty 2, got %d", block.GetDifficulty())
+        }
+    }
+    if err := engine.ValidateChain(e); err != nil {
+        t.Errorf("Expected the chain to be valid at its own difficulty, got %v", err)
+    }
+
+    // Claiming a lower difficulty changes the hash, and a remined block still breaks the chain's difficulty.
+    lowered := pow.BlockFromWire(blocks[1])
+    lowered.Difficulty = 1
+    if lowered.CalculateHash() == blocks[1].Sum() {
+        t.Errorf("Expected the difficulty to be covered by the hash")
+    }
+    remined := pow.NewBlockWithDifficulty("Easier block", blocks[1].ParentSum(), 1, 1, time.Now())
+    blocks[1] = remined.ToWire()
+    if err := engine.Validate("pow", blocks, nil); err == nil || !strings.Contains(err.Error(), "difficulty") {
+        t.Errorf("Expected a block mined at another difficulty to be rejected, got %v", err)
+    }
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+    "fmt"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/testutil"
+)
+
+// staleBlocks runs eight miners for 30 seconds of virtual time and returns the blocks they mined that lost a race.
+func staleBlocks(gossip *sim.Gossip) int {
+    s := sim.New(sim.Config{Seed: 5, Network: sim.Link{Latency: sim.Constant(5 * time.Millisecond)}, Gossip: gossip})
+    peers := []int32{0, 1, 2, 3, 4, 5, 6, 7}
+    var miners []*pow.Miner
+    for _, id := range peers {
+        m := pow.NewMiner(pow.MinerConfig{ID: id, Peers: peers, MineProbability: 0.002, Rand: s.NewRand(), Clock: s.Clock()})
+        miners = append(miners, m)
+        s.Add(m)
+    }
+    s.RunFor(30 * time.Second)
+    stale := 0
+    for _, m := range miners {
+        stale = max(stale, m.Stale())
+    }
+    return stale
+}
+
+func TestGossipDelaysRaiseStaleBlockRate(t *testing.T) {
+    direct := staleBlocks(nil)
+    gossiped := staleBlocks(&sim.Gossip{Kinds: []string{"BlockProposal"}, Fanout: 2, Validate: sim.Constant(150 * time.Millisecond)})
+    if gossiped <= direct {
+        t.Errorf("Expected more stale blocks when blocks take several validated hops, got %d against %d sent directly", gossiped, direct)
+    }
+}
+
+func TestGossipCarriesPBFTVotes(t *testing.T) {
+    c := testutil.PBFT(pbftLink, options.WithSeed(2))
+    c.Gossip = &sim.Gossip{Fanout: 2, Validate: sim.Constant(2 * time.Millisecond)}
+
+    for i := 1; i <= 3; i++ {
+        c.Propose(0, fmt.Sprintf("Test block %d", i))
+    }
+    if !c.RunUntil(c.AllCommitted(4), 30*time.Second) {
+        t.Fatalf("Blocks were not committed on every replica with gossiped votes")
+    }
+    if c.Stats().Redundant == 0 {
+        t.Errorf("Expected some votes to reach a replica twice, got %+v", c.Stats())
+    }
+}
+
+// connected reports whether every node of a topology can reach every other over its links.
+func connected(topology map[int32][]int32) bool {
+    reached := map[int32]bool{0: true}
+    queue := []int32{0}
+    for len(queue) > 0 {
+        id := queue[0]
+        queue = queue[1:]
+        for _, peer := range topology[id] {
+            if !reached[peer] {
+                reached[peer] = true
+                queue = append(queue, peer)
+            }
+        }
+    }
+    return len(reached) == len(topology)
+}
+
+func TestDiscoveryBuildsSparseTopologyThatFollowsPartitions(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 4, Discovery: &sim.Discovery{MaxPeers: 4}})
+    var peers, left, right []int32
+    for id := int32(0); id < 16; id++ {
+        peers = append(peers, id)
+        if id < 8 {
+            left = append(left, id)
+        } else {
+            right = append(right, id)
+        }
+    }
+    for _, id := range peers {
+        s.Add(pow.NewMiner(pow.MinerConfig{ID: id, Peers: peers, MineProbability: 1e-12, Rand: s.NewRand(), Clock: s.Clock()}))
+    }
+
+    s.RunFor(20 * time.Second)
+    topology := s.Topology()
+    for id, neighbors := range topology {
+        if len(neighbors) > 4 {
+            t.Errorf("Expected node %d to keep at most 4 peers, got %v", id, neighbors)
+        }
+    }
+    if !connected(topology) {
+        t.Errorf("Expected every node to be reachable from node 0, got %v", topology)
+    }
+
+    s.Network.Partition(left, right)
+    s.RunFor(3 * time.Second)
+    for _, id := range left {
+        for _, peer := range s.Neighbors(id) {
+            if peer >= 8 {
+                t.Errorf("Expected node %d to drop peer %d across the partition", id, peer)
+            }
+        }
+    }
+    s.Network.Heal()
+    s.RunFor(20 * time.Second)
+    if !connected(s.Topology()) {
+        t.Errorf("Expected the topology to reconnect after the partition healed, got %v", s.Topology())
+    }
+}
+
+func TestLargeGossipNetworkSharesBlocksAndSchedule(t *testing.T) {
+    const n = 300
+    peers := make([]int32, n)
+    stakes := make(map[int32]int, n)
+    for i := range peers {
+        peers[i] = int32(i)
+        stakes[int32(i)] = 1 + i%7
+    }
+    schedule := pos.NewSchedule(stakes)
+    own := pos.NewValidator(pos.ValidatorConfig{ID: 0, Peers: peers, Stakes: stakes})
+    for slot := 0; slot < 100; slot++ {
+        if own.Proposer(slot) != schedule.Proposer(slot) {
+            t.Fatalf("Expected a shared schedule to pick the proposer a validator's own picks in slot %d", slot)
+        }
+    }
+
+    s := sim.New(sim.Config{
+        Seed:         3,
+        TickInterval: 100 * time.Millisecond,
+        Gossip:       &sim.Gossip{Kinds: []string{"BlockProposal"}},
+        Discovery:    &sim.Discovery{Bootstrap: []int32{0, 100, 200}},
+    })
+    validators := make([]*pos.Validator, n)
+    for i, id := range peers {
+        validators[i] = pos.NewValidator(pos.ValidatorConfig{ID: id, Peers: peers, Stakes: stakes, Schedule: schedule, SlotTicks: 10, Confirmations: 2, AntiEntropy: 20, Clock: s.Clock()})
+        s.Add(validators[i])
+    }
+    s.RunFor(40 * time.Second)
+
+    first := validators[0].Committed()
+    if len(first) < 10 {
+        t.Fatalf("Expected at least 10 committed blocks, got %d", len(first))
+    }
+    for _, v := range validators[1:] {
+        committed := v.Committed()
+        if len(committed) < 10 || committed[9] != first[9] {
+            t.Fatalf("Expected validator %d to hold the same block 9 object as validator 0", v.ID())
+        }
+    }
+}
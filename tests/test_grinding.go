@@ -0,0 +1,46 @@
+package tests
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "testing"
+    "consensus-algorithms-edu/grinding"
+)
+
+func TestGrindingBiasesPredictableDrawsButNotBeacon(t *testing.T) {
+    report, err := grinding.Run(context.Background(), grinding.Config{Attempts: []int{1, 20}, Slots: 4000, Seed: 1})
+    if err != nil {
+        t.Fatalf("Run failed: %v", err)
+    }
+    if len(report.Results) != 2*len(grinding.Schemes()) {
+        t.Fatalf("Expected a result per scheme and number of attempts, got %d", len(report.Results))
+    }
+    for _, res := range report.Results {
+        switch {
+        case res.Attempts == 1 && (res.Advantage() < 0.8 || res.Advantage() > 1.2):
+            t.Errorf("Expected an honest validator of %s to win about its share, got %.2fx", res.Scheme, res.Advantage())
+        case res.Scheme == grinding.Chain && res.Attempts == 20 && res.Advantage() < 3:
+            t.Errorf("Expected 20 attempts per block to compound in the chain scheme, got %.2fx", res.Advantage())
+        }
+    }
+    for _, scheme := range []grinding.Scheme{grinding.Slot, grinding.Chain} {
+        if report.Resists(scheme) {
+            t.Errorf("Expected %s to be grindable", scheme)
+        }
+    }
+    if !report.Resists(grinding.Beacon) {
+        t.Errorf("Expected the beacon to leave nothing to grind")
+    }
+
+    var out strings.Builder
+    if err := report.Print(&out); err != nil || !strings.Contains(out.String(), "beacon resists grinding") || !strings.Contains(out.String(), "chain is grindable") {
+        t.Errorf("Expected the report to give a verdict per scheme, got %v:\n%s", err, out.String())
+    }
+}
+
+func TestGrindingRejectsUnknownScheme(t *testing.T) {
+    if _, err := grinding.Run(context.Background(), grinding.Config{Schemes: []grinding.Scheme{"vdf"}}); !errors.Is(err, grinding.ErrUnknownScheme) {
+        t.Errorf("Expected ErrUnknownScheme, got %v", err)
+    }
+}
@@ -0,0 +1,330 @@
+package tests
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "slices"
+    "testing"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/options"
+)
+
+func TestAccountsRejectOverdraftsAndReplays(t *testing.T) {
+    accounts := ledger.NewAccounts(map[string]uint64{"alice": 100})
+    pay := ledger.EncodeTransfers(
+        ledger.Transfer{From: "alice", To: "bob", Amount: 60, Nonce: 0},
+        ledger.Transfer{From: "bob", To: "carol", Amount: 10, Nonce: 0}, // Bob spends what the first transfer paid him.
+    )
+    if err := accounts.Apply(pay); err != nil {
+        t.Fatalf("Expected the transfers to apply, got %v", err)
+    }
+    if accounts.Balance("alice") != 40 || accounts.Balance("bob") != 50 || accounts.Balance("carol") != 10 {
+        t.Errorf("Expected balances 40, 50 and 10, got %d, %d and %d", accounts.Balance("alice"), accounts.Balance("bob"), accounts.Balance("carol"))
+    }
+
+    cases := []struct {
+        data string
+        want error
+    }{
+        {pay, ledger.ErrReplay},
+        {ledger.EncodeTransfers(ledger.Transfer{From: "alice", To: "bob", Amount: 41, Nonce: 1}), ledger.ErrOverdraft},
+        {ledger.EncodeTransfers(ledger.Transfer{From: "alice", To: "bob", Amount: 1, Nonce: 2}), ledger.ErrNonceGap},
+        {ledger.TransferPrefix + "[{", ledger.ErrMalformed},
+    }
+    for _, c := range cases {
+        if err := accounts.Apply(c.data); !errors.Is(err, c.want) || !errors.Is(err, ledger.ErrInvalid) {
+            t.Errorf("Expected %v for %q, got %v", c.want, c.data, err)
+        }
+    }
+    if accounts.Account("alice") != (ledger.Account{Balance: 40, Nonce: 1}) {
+        t.Errorf("Expected rejected blocks to leave alice unchanged, got %+v", accounts.Account("alice"))
+    }
+    if err := accounts.Apply("Test block 1"); err != nil {
+        t.Errorf("Expected data without transfers to apply as a no-op, got %v", err)
+    }
+}
+
+func TestPBFTRefusesDoubleSpends(t *testing.T) {
+    blockchain := pbft.NewPBFTNetwork(options.WithNodes(4))
+    accounts := ledger.NewAccounts(map[string]uint64{"alice": 100})
+    if err := blockchain.AttachState(accounts); err != nil {
+        t.Fatal(err)
+    }
+
+    spend := ledger.EncodeTransfers(ledger.Transfer{From: "alice", To: "bob", Amount: 70})
+    if _, err := blockchain.RunPBFT(spend); err != nil {
+        t.Fatalf("Expected the first spend to commit, got %v", err)
+    }
+    if _, err := blockchain.RunPBFT(spend); !errors.Is(err, ledger.ErrReplay) {
+        t.Errorf("Expected the same spend again to be refused as a replay, got %v", err)
+    }
+
+    // A Byzantine primary that proposes an overdraft anyway gets no honest approvals, and the block cannot be
+    // committed directly either.
+    overdraft := blockchain.Nodes[0].ProposeBlock(ledger.EncodeTransfers(ledger.Transfer{From: "alice", To: "carol", Amount: 70, Nonce: 1}))
+    if blockchain.Nodes[1].VerifyBlock(overdraft) {
+        t.Errorf("Expected an honest replica to reject an overdraft")
+    }
+    if err := blockchain.AddBlock(overdraft); !errors.Is(err, pbft.ErrInvalidBlock) || !errors.Is(err, ledger.ErrOverdraft) {
+        t.Errorf("Expected an overdraft to be an invalid block, got %v", err)
+    }
+    if blockchain.Height() != 1 || accounts.Balance("alice") != 30 || accounts.Balance("bob") != 70 {
+        t.Errorf("Expected one committed spend, got height %d and balances %d and %d", blockchain.Height(), accounts.Balance("alice"), accounts.Balance("bob"))
+    }
+
+    // A chain that replaces this one rebuilds the state from its blocks.
+    var snapshot bytes.Buffer
+    blockchain.Export(&snapshot)
+    resumed := pbft.NewPBFTNetwork(options.WithNodes(4))
+    rebuilt := ledger.NewAccounts(map[string]uint64{"alice": 100})
+    resumed.AttachState(rebuilt)
+    if err := resumed.Import(&snapshot); err != nil {
+        t.Fatal(err)
+    }
+    if rebuilt.Account("alice") != accounts.Account("alice") {
+        t.Errorf("Expected the imported chain to rebuild alice's account %+v, got %+v", accounts.Account("alice"), rebuilt.Account("alice"))
+    }
+}
+
+func TestProofOfWorkAndStakeRefuseOverdrafts(t *testing.T) {
+    blockchain := pow.NewBlockchain()
+    if err := blockchain.AttachState(ledger.NewAccounts(map[string]uint64{"alice": 5})); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := blockchain.AddBlock(ledger.EncodeTransfers(ledger.Transfer{From: "alice", To: "bob", Amount: 6})); !errors.Is(err, ledger.ErrOverdraft) {
+        t.Errorf("Expected PoW to refuse an overdraft before mining, got %v", err)
+    }
+
+    e, _ := engine.New("pos", engine.Config{Nodes: 3, Ledger: ledger.NewAccounts(map[string]uint64{"alice": 5})})
+    err := e.Submit(context.Background(), ledger.EncodeTransfers(ledger.Transfer{From: "alice", To: "bob", Amount: 6}))
+    if !errors.Is(err, engine.ErrRejected) || !errors.Is(err, ledger.ErrOverdraft) {
+        t.Errorf("Expected the engine to reject an overdraft, got %v", err)
+    }
+    if len(e.Blocks()) != 1 {
+        t.Errorf("Expected only the genesis block, got %d blocks", len(e.Blocks()))
+    }
+}
+
+func TestUTXOSetRejectsDoubleSpends(t *testing.T) {
+    utxos := ledger.NewUTXOSet(ledger.Output{Owner: "alice", Amount: 100})
+    pay := ledger.Tx{
+        Inputs:  []ledger.OutPoint{{Tx: ledger.GenesisTx, Index: 0}},
+        Outputs: []ledger.Output{{Owner: "bob", Amount: 60}, {Owner: "alice", Amount: 40}},
+    }
+    forward := ledger.Tx{ // Bob spends what the first transaction paid him, in the same block, less a fee of 5.
+        Inputs:  []ledger.OutPoint{pay.Out(0)},
+        Outputs: []ledger.Output{{Owner: "carol", Amount: 55}},
+    }
+    if err := utxos.Apply(ledger.EncodeTxs(pay, forward)); err != nil {
+        t.Fatalf("Expected the transactions to apply, got %v", err)
+    }
+    if utxos.Balance("alice") != 40 || utxos.Balance("bob") != 0 || utxos.Balance("carol") != 55 {
+        t.Errorf("Expected balances 40, 0 and 55, got %d, %d and %d", utxos.Balance("alice"), utxos.Balance("bob"), utxos.Balance("carol"))
+    }
+    if unspent := utxos.Unspent("alice"); len(unspent) != 1 || unspent[0] != pay.Out(1) {
+        t.Errorf("Expected alice's change to be her only unspent output, got %v", unspent)
+    }
+
+    change := pay.Out(1)
+    cases := []struct {
+        data string
+        want error
+    }{
+        {ledger.EncodeTxs(pay), ledger.ErrSpent},
+        {ledger.EncodeTxs(ledger.Tx{Inputs: []ledger.OutPoint{change}, Outputs: []ledger.Output{{Owner: "bob", Amount: 41}}}), ledger.ErrOverdraft},
+        {ledger.EncodeTxs(ledger.Tx{Inputs: []ledger.OutPoint{change, change}, Outputs: []ledger.Output{{Owner: "bob", Amount: 80}}}), ledger.ErrSpent},
+        {ledger.EncodeTxs(ledger.Tx{Outputs: []ledger.Output{{Owner: "bob", Amount: 1}}}), ledger.ErrMalformed},
+        {ledger.TxPrefix + "[{", ledger.ErrMalformed},
+    }
+    for _, c := range cases {
+        if err := utxos.Apply(c.data); !errors.Is(err, c.want) || !errors.Is(err, ledger.ErrInvalid) {
+            t.Errorf("Expected %v for %q, got %v", c.want, c.data, err)
+        }
+    }
+    if out, ok := utxos.Output(change); !ok || out.Amount != 40 {
+        t.Errorf("Expected rejected blocks to leave alice's change unspent, got %+v", out)
+    }
+
+    // Attached to a chain, the set refuses a block that spends the same output twice.
+    blockchain := pbft.NewPBFTNetwork(options.WithNodes(4))
+    if err := blockchain.AttachState(ledger.NewUTXOSet(ledger.Output{Owner: "alice", Amount: 100})); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := blockchain.RunPBFT(ledger.EncodeTxs(pay)); err != nil {
+        t.Fatalf("Expected the first spend to commit, got %v", err)
+    }
+    if _, err := blockchain.RunPBFT(ledger.EncodeTxs(pay)); !errors.Is(err, ledger.ErrSpent) {
+        t.Errorf("Expected the same spend again to be refused, got %v", err)
+    }
+}
+
+func TestHashTimeLocksClaimOrRefund(t *testing.T) {
+    accounts := ledger.NewAccounts(map[string]uint64{"alice": 100})
+    accounts.Apply("Genesis Block") // Height 1: locks below expire by the blocks applied after it.
+    lock := ledger.Lock{ID: "swap", From: "alice", To: "bob", Amount: 60, Hash: ledger.HashLock("secret"), Expiry: 3}
+    if err := accounts.Apply(ledger.EncodeLock(lock)); err != nil {
+        t.Fatalf("Expected the lock to apply, got %v", err)
+    }
+    if accounts.Balance("alice") != 40 {
+        t.Errorf("Expected the locked amount to leave alice's balance, got %d", accounts.Balance("alice"))
+    }
+
+    cases := []struct {
+        data string
+        want error
+    }{
+        {ledger.EncodeLock(lock), ledger.ErrReplay},
+        {ledger.EncodeLock(ledger.Lock{ID: "late", From: "alice", To: "bob", Amount: 1, Hash: lock.Hash, Expiry: 2}), ledger.ErrExpired},
+        {ledger.EncodeLock(ledger.Lock{ID: "big", From: "alice", To: "bob", Amount: 41, Hash: lock.Hash, Expiry: 9}), ledger.ErrOverdraft},
+        {ledger.EncodeClaim("swap", "guess"), ledger.ErrWrongSecret},
+        {ledger.EncodeClaim("other", "secret"), ledger.ErrNoLock},
+        {ledger.EncodeRefund("swap"), ledger.ErrNotExpired},
+        {ledger.HTLCPrefix + `{"claim":"swap","refund":"swap"}`, ledger.ErrMalformed},
+    }
+    for _, c := range cases {
+        if err := accounts.Check(c.data); !errors.Is(err, c.want) || !errors.Is(err, ledger.ErrInvalid) {
+            t.Errorf("Expected %v for %q, got %v", c.want, c.data, err)
+        }
+    }
+
+    // Claimed in time, the lock pays bob and publishes the secret; it cannot be settled again.
+    claimed := accounts.Clone().(*ledger.Accounts)
+    if err := claimed.Apply(ledger.EncodeClaim("swap", "secret")); err != nil {
+        t.Fatalf("Expected the claim to apply, got %v", err)
+    }
+    if h, _ := claimed.HTLC("swap"); h.Status != ledger.Claimed || h.Secret != "secret" || claimed.Balance("bob") != 60 {
+        t.Errorf("Expected a claim to pay bob and reveal the secret, got %+v", h)
+    }
+    if err := claimed.Apply(ledger.EncodeRefund("swap")); !errors.Is(err, ledger.ErrSettled) {
+        t.Errorf("Expected a claimed lock to refuse a refund, got %v", err)
+    }
+
+    // Unclaimed until it expires, the lock can only be refunded.
+    accounts.Apply("Unrelated block")
+    if err := accounts.Apply(ledger.EncodeClaim("swap", "secret")); !errors.Is(err, ledger.ErrExpired) {
+        t.Errorf("Expected a claim at height %d to be too late, got %v", accounts.Height(), err)
+    }
+    if err := accounts.Apply(ledger.EncodeRefund("swap")); err != nil || accounts.Balance("alice") != 100 {
+        t.Errorf("Expected the refund to return the 60, got balance %d and %v", accounts.Balance("alice"), err)
+    }
+}
+
+func TestBloomIndexNeverMissesABlock(t *testing.T) {
+    index := ledger.NewBloomIndex(256, 3) // Small filters, so that some false positives occur.
+    var data []string
+    for i := 0; i < 300; i++ {
+        from, to := fmt.Sprintf("acct%d", i%40), fmt.Sprintf("acct%d", (i*7+3)%40)
+        data = append(data, ledger.EncodeTransfers(ledger.Transfer{From: from, To: to, Amount: 1, Nonce: uint64(i)}))
+        if got := index.Add(data[i]); got != int64(i) {
+            t.Fatalf("Expected block %d to be indexed at %d, got %d", i, i, got)
+        }
+    }
+
+    falsePositives := 0
+    for a := 0; a < 40; a++ {
+        address := fmt.Sprintf("acct%d", a)
+        candidates := index.Query(address)
+        touching := 0
+        for i, d := range data {
+            if ledger.Touches(d, address) {
+                touching++
+                if !slices.Contains(candidates, int64(i)) {
+                    t.Errorf("Expected block %d among the candidates for %s", i, address)
+                }
+            }
+        }
+        falsePositives += len(candidates) - touching
+    }
+    if len(index.Query("nobody")) > 30 {
+        t.Errorf("Expected few candidates for an unknown account, got %d", len(index.Query("nobody")))
+    }
+    if rate := float64(falsePositives) / (40 * 300); rate > 5*ledger.NewBloom(256, 3).FalsePositiveRate(2) {
+        t.Errorf("Expected about %.4f false positives per query and block, got %.4f", ledger.NewBloom(256, 3).FalsePositiveRate(2), rate)
+    }
+
+    lock := ledger.EncodeLock(ledger.Lock{ID: "l", From: "alice", To: "bob", Amount: 1, Hash: ledger.HashLock("s"), Expiry: 5})
+    if got := ledger.Addresses(lock); !slices.Equal(got, []string{"alice", "bob"}) {
+        t.Errorf("Expected a lock to name alice and bob, got %v", got)
+    }
+}
+
+func TestStateRootsCommitToAccounts(t *testing.T) {
+    accounts := ledger.NewAccounts(map[string]uint64{"alice": 100})
+    blockchain := pbft.NewPBFTNetwork(options.WithNodes(4))
+    if err := blockchain.AttachState(accounts); err != nil {
+        t.Fatal(err)
+    }
+    block, err := blockchain.RunPBFT(ledger.EncodeTransfers(ledger.Transfer{From: "alice", To: "bob", Amount: 30}))
+    if err != nil {
+        t.Fatal(err)
+    }
+    header := block.Header()
+    if header.State.IsZero() || header.State != accounts.Root() {
+        t.Fatalf("Expected the header to commit to the state after the block, got %s", header.State)
+    }
+
+    account, proof := accounts.Prove("bob")
+    got, err := ledger.VerifyAccount(header.State, "bob", proof)
+    if err != nil || got != account || got.Balance != 30 {
+        t.Errorf("Expected the proof to show bob holding 30, got %+v, %v", got, err)
+    }
+    _, proof = accounts.Prove("carol")
+    if got, err := ledger.VerifyAccount(header.State, "carol", proof); err != nil || got != (ledger.Account{}) {
+        t.Errorf("Expected a proof that carol has no account, got %+v, %v", got, err)
+    }
+
+    // A block that claims a state its transactions do not lead to is refused.
+    forged := pbft.NewBlock(ledger.EncodeTransfers(ledger.Transfer{From: "bob", To: "carol", Amount: 10}), block.Hash, block.Index+1)
+    forged.StateRoot = header.State
+    forged.Hash = forged.CalculateHash()
+    if err := blockchain.AddBlock(forged); !errors.Is(err, ledger.ErrStateRoot) {
+        t.Errorf("Expected a block with the wrong state root to be refused, got %v", err)
+    }
+}
+
+func TestReceiptsRecordEachTransaction(t *testing.T) {
+    accounts := ledger.NewAccounts(map[string]uint64{"alice": 100})
+    blockchain := pbft.NewPBFTNetwork(options.WithNodes(4))
+    if err := blockchain.AttachState(accounts); err != nil {
+        t.Fatal(err)
+    }
+    first := ledger.Transfer{From: "alice", To: "bob", Amount: 30, Nonce: 0}
+    second := ledger.Transfer{From: "bob", To: "carol", Amount: 10, Nonce: 0}
+    block, err := blockchain.RunPBFT(ledger.EncodeTransfers(first, second))
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    a, ok := blockchain.GetReceipt(ledger.TxHash(first))
+    b, _ := blockchain.GetReceipt(ledger.TxHash(second))
+    if !ok || a.Status != ledger.Succeeded || a.Block != 1 || b.Index != 1 {
+        t.Fatalf("Expected receipts for both transfers of block 1, got %+v and %+v", a, b)
+    }
+    want := []ledger.Change{
+        {Key: "account alice", Before: "balance 100, nonce 0", After: "balance 70, nonce 1"},
+        {Key: "account bob", Before: "", After: "balance 30, nonce 0"},
+    }
+    if !slices.Equal(a.Changes, want) {
+        t.Errorf("Expected the first transfer to change %+v, got %+v", want, a.Changes)
+    }
+    if len(b.Changes) != 2 || b.Changes[0].Before != "balance 30, nonce 0" {
+        t.Errorf("Expected the second transfer to start from the first's result, got %+v", b.Changes)
+    }
+    if root := block.Header().Receipts; root.IsZero() || root != ledger.ReceiptsRoot([]ledger.Receipt{a, b}) {
+        t.Errorf("Expected the header to commit to the receipts, got %s", root)
+    }
+
+    overdraft := ledger.Transfer{From: "carol", To: "alice", Amount: 50, Nonce: 0}
+    receipts, err := accounts.Execute(ledger.EncodeTransfers(ledger.Transfer{From: "alice", To: "carol", Amount: 5, Nonce: 1}, overdraft))
+    if !errors.Is(err, ledger.ErrOverdraft) || len(receipts) != 2 || receipts[1].Status != ledger.Failed {
+        t.Errorf("Expected a failed receipt for the overdraft, got %+v, %v", receipts, err)
+    }
+    if _, ok := blockchain.GetReceipt(ledger.TxHash(overdraft)); ok {
+        t.Errorf("Expected no receipt for a transaction no block carries")
+    }
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "consensus-algorithms-edu/algorithms/paxos"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/node"
+)
+
+func TestLifecycleCrashAndRecoverEverywhere(t *testing.T) {
+    // Each network has four nodes and a round needs three of them, so crashing two stops it.
+    raftChain := raft.NewRaftNetwork()
+    pbftChain := pbft.NewPBFTNetwork()
+    paxosChain := paxos.NewPaxosNetwork()
+    proposalID := 0
+    networks := map[string]struct {
+        nodes []node.Lifecycle
+        round func() error
+    }{
+        "raft": {
+            nodes: []node.Lifecycle{raftChain.Nodes[2], raftChain.Nodes[3]},
+            round: func() error { _, err := raftChain.Nodes[0].Lead("Test block"); return err },
+        },
+        "pbft": {
+            nodes: []node.Lifecycle{pbftChain.Nodes[2], pbftChain.Nodes[3]},
+            round: func() error { _, err := pbftChain.RunPBFT("Test block"); return err },
+        },
+        "paxos": {
+            nodes: []node.Lifecycle{paxosChain.Nodes[2], paxosChain.Nodes[3]},
+            round: func() error { proposalID++; _, err := paxosChain.RunPaxos("Test block", proposalID); return err },
+        },
+    }
+    for name, network := range networks {
+        for _, n := range network.nodes {
+            n.Crash()
+            if n.Status() != node.Crashed {
+                t.Errorf("%s: Expected a crashed node, got %v", name, n.Status())
+            }
+        }
+        if err := network.round(); err == nil {
+            t.Errorf("%s: Expected the round to fail with two of four nodes crashed", name)
+        }
+        for _, n := range network.nodes {
+            if err := n.Recover(); err != nil {
+                t.Errorf("%s: Expected the node to recover, got %v", name, err)
+            }
+        }
+        if err := network.round(); err != nil {
+            t.Errorf("%s: Expected the round to succeed after recovery, got %v", name, err)
+        }
+    }
+}
+
+func TestLifecycleRaftLeaderCrash(t *testing.T) {
+    chain := raft.NewRaftNetwork()
+    leader := chain.Nodes[0]
+    leader.Crash()
+    if chain.Leader != nil || leader.IsLeader {
+        t.Fatalf("Expected a crashed leader to lose its leadership")
+    }
+    if _, err := leader.RequestVoteContext(context.Background()); !errors.Is(err, node.ErrCrashed) {
+        t.Errorf("Expected a crashed node to be unable to stand for election, got %v", err)
+    }
+    if !chain.Nodes[1].RequestVote() {
+        t.Fatalf("Expected node 1 to win the election among the three running nodes")
+    }
+    if err := leader.Recover(); err != nil {
+        t.Fatalf("Expected the old leader to recover, got %v", err)
+    }
+    if leader.IsLeader || chain.Leader.ID != 1 {
+        t.Errorf("Expected the old leader to rejoin as a follower of node 1, got leader %d", chain.Leader.ID)
+    }
+}
+
+func TestLifecycleRestoreHook(t *testing.T) {
+    chain := paxos.NewPaxosNetwork()
+    acceptor := chain.Nodes[1]
+    broken := errors.New("disk unreadable")
+    var during node.Status
+    acceptor.Restore = func(n *paxos.Node) error {
+        during = n.Status()
+        return broken
+    }
+
+    if err := acceptor.Recover(); !errors.Is(err, node.ErrNotCrashed) {
+        t.Errorf("Expected ErrNotCrashed for a running node, got %v", err)
+    }
+    acceptor.Crash()
+    if err := acceptor.Recover(); !errors.Is(err, broken) {
+        t.Errorf("Expected the restore error, got %v", err)
+    }
+    if during != node.Recovering || acceptor.Status() != node.Crashed {
+        t.Errorf("Expected recovering during the restore and crashed after it failed, got %v and %v", during, acceptor.Status())
+    }
+
+    acceptor.Restore = func(n *paxos.Node) error {
+        n.Proposals = nil // An acceptor that lost its disk forgets what it accepted.
+        return nil
+    }
+    chain.Nodes[0].Crash()
+    if _, err := chain.RunPaxos("Test block", 1); !errors.Is(err, node.ErrCrashed) {
+        t.Errorf("Expected ErrCrashed while the proposer is down, got %v", err)
+    }
+    chain.Nodes[0].Recover()
+    if _, err := chain.RunPaxos("Test block", 1); err != nil {
+        t.Fatalf("Expected the proposal to be accepted, got %v", err)
+    }
+    acceptor.Crash()
+    acceptor.Recover()
+    if !acceptor.AcceptProposal(paxos.Proposal{ProposalID: 1, Data: "Other block"}) {
+        t.Errorf("Expected an acceptor that forgot its proposals to accept proposal 1 again")
+    }
+}
@@ -0,0 +1,128 @@
+package tests
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "log/slog"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/logging"
+    "consensus-algorithms-edu/sim"
+)
+
+// logRecords decodes the records written by a JSON slog handler.
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+    var records []map[string]any
+    decoder := json.NewDecoder(buf)
+    for decoder.More() {
+        var record map[string]any
+        if err := decoder.Decode(&record); err != nil {
+            t.Fatalf("Invalid log record: %v", err)
+        }
+        records = append(records, record)
+    }
+    return records
+}
+
+// withMessage returns the records whose message is msg.
+func withMessage(records []map[string]any, msg string) []map[string]any {
+    var matching []map[string]any
+    for _, record := range records {
+        if record["msg"] == msg {
+            matching = append(matching, record)
+        }
+    }
+    return matching
+}
+
+func TestLoggingRaftElection(t *testing.T) {
+    var buf bytes.Buffer
+    logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+    s := sim.New(sim.Config{Seed: 1, Network: sim.Link{Latency: sim.Uniform(time.Millisecond, 2*time.Millisecond)}})
+    peers := []int32{0, 1, 2}
+    for _, id := range peers {
+        s.Add(raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: peers, Rand: s.NewRand(), Clock: s.Clock(), Logger: logger}))
+    }
+    s.RunFor(time.Second)
+
+    records := logRecords(t, &buf)
+    leaders := withMessage(records, "became leader")
+    if len(leaders) == 0 {
+        t.Fatalf("Expected a \"became leader\" record, got %d records", len(records))
+    }
+    leader := leaders[0]
+
+    votes := 0
+    for _, vote := range withMessage(records, "granted vote") {
+        if vote["algorithm"] != "raft" {
+            t.Errorf("Expected votes to be labelled with algorithm raft, got %v", vote["algorithm"])
+        }
+        if vote["candidate"] == leader["node"] && vote["term"] == leader["term"] {
+            if vote["node"] == leader["node"] {
+                t.Errorf("Expected candidates not to log votes for themselves")
+            }
+            votes++
+        }
+    }
+    if votes == 0 {
+        t.Errorf("Expected a vote for node %v in term %v", leader["node"], leader["term"])
+    }
+    if len(withMessage(records, "started election")) == 0 {
+        t.Errorf("Expected a \"started election\" record")
+    }
+}
+
+func TestLoggingEngineScopesNodes(t *testing.T) {
+    var buf bytes.Buffer
+    logger := slog.New(slog.NewJSONHandler(&buf, nil))
+    e, err := engine.New("paxos", engine.Config{Nodes: 3, Logger: logger})
+    if err != nil {
+        t.Fatalf("New failed: %v", err)
+    }
+    if err := e.Submit(context.Background(), "Test block 1"); err != nil {
+        t.Fatalf("Submit failed: %v", err)
+    }
+
+    records := logRecords(t, &buf)
+    nodes := make(map[float64]bool)
+    for _, record := range withMessage(records, "accepted proposal") {
+        if record["algorithm"] != "paxos" {
+            t.Errorf("Expected algorithm paxos, got %v", record["algorithm"])
+        }
+        if node, ok := record["node"].(float64); ok {
+            nodes[node] = true
+        }
+    }
+    if len(nodes) != 3 {
+        t.Errorf("Expected every node to log its acceptance, got %v", nodes)
+    }
+    if committed := withMessage(records, "committed block"); len(committed) != 1 || committed[0]["index"] != float64(1) {
+        t.Errorf("Expected one \"committed block\" record for index 1, got %v", committed)
+    }
+}
+
+func TestLoggingLevels(t *testing.T) {
+    var buf bytes.Buffer
+    logger, err := logging.New(&buf, "warn")
+    if err != nil {
+        t.Fatalf("New failed: %v", err)
+    }
+    e, _ := engine.New("pow", engine.Config{Logger: logger})
+    if err := e.Submit(context.Background(), "Test block 1"); err != nil {
+        t.Fatalf("Submit failed: %v", err)
+    }
+    if buf.Len() != 0 {
+        t.Errorf("Expected info records to be dropped at level warn, got %q", buf.String())
+    }
+
+    if _, err := logging.New(&buf, "verbose"); err == nil {
+        t.Errorf("Expected an error for an unknown level")
+    }
+    if logger, err := logging.New(&buf, "off"); err != nil || logger != logging.Discard {
+        t.Errorf("Expected level off to discard records, got %v, %v", logger, err)
+    }
+}
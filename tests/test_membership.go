@@ -0,0 +1,125 @@
+package tests
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/events"
+    "consensus-algorithms-edu/node"
+)
+
+func TestMembershipRaftCommitsChanges(t *testing.T) {
+    ctx := context.Background()
+    chain := raft.NewRaftNetwork()
+    id, err := chain.AddNode(ctx)
+    if err != nil || id != 4 || len(chain.Nodes) != 5 {
+        t.Fatalf("Expected node 4 to join a network of 5, got node %d, %d nodes and error %v", id, len(chain.Nodes), err)
+    }
+    if data := chain.Head().Data; data != "config: add node-4" {
+        t.Errorf("Expected the change to be committed as a block, got '%s'", data)
+    }
+
+    // The leader removes itself and steps down; the remaining nodes elect a new one and carry on.
+    if err := chain.RemoveNode(ctx, 0); err != nil {
+        t.Fatalf("Expected the leader to be removed, got %v", err)
+    }
+    if chain.Leader != nil {
+        t.Fatalf("Expected no leader after the leader removed itself, got node %d", chain.Leader.ID)
+    }
+    if _, err := chain.AddNode(ctx); !errors.Is(err, raft.ErrNoLeader) {
+        t.Errorf("Expected ErrNoLeader without a leader, got %v", err)
+    }
+    if !chain.Nodes[0].RequestVote() {
+        t.Fatalf("Expected node %d to win the election", chain.Nodes[0].ID)
+    }
+    if _, err := chain.Leader.Lead("Test block"); err != nil {
+        t.Errorf("Expected the new leader to commit a block, got %v", err)
+    }
+    if err := chain.RemoveNode(ctx, 0); !errors.Is(err, node.ErrUnknownNode) {
+        t.Errorf("Expected ErrUnknownNode for a removed node, got %v", err)
+    }
+}
+
+func TestMembershipPBFTQuorumFollowsSize(t *testing.T) {
+    ctx := context.Background()
+    chain := pbft.NewPBFTNetwork()
+    for i := 0; i < 3; i++ {
+        if _, err := chain.AddNode(ctx); err != nil {
+            t.Fatalf("Failed to add a replica: %v", err)
+        }
+    }
+    if chain.Quorum() != 5 {
+        t.Errorf("Expected a quorum of 5 out of 7 replicas, got %d", chain.Quorum())
+    }
+
+    if err := chain.RemoveNode(ctx, 0); err != nil {
+        t.Fatalf("Expected the primary to be removed, got %v", err)
+    }
+    if !chain.Nodes[0].IsPrimary || chain.Nodes[0].ID != 1 {
+        t.Errorf("Expected node 1 to become primary, got node %d", chain.Nodes[0].ID)
+    }
+    block, err := chain.RunPBFT("Test block")
+    if err != nil {
+        t.Fatalf("Expected the new primary to commit a block, got %v", err)
+    }
+    if len(block.Certificate) != 6 {
+        t.Errorf("Expected approvals from all 6 remaining replicas, got %d", len(block.Certificate))
+    }
+}
+
+func TestMembershipEngines(t *testing.T) {
+    ctx := context.Background()
+    for _, algorithm := range engine.Algorithms() {
+        e, err := engine.New(algorithm, engine.Config{Nodes: 4, Events: events.NewBus()})
+        if err != nil {
+            t.Fatalf("Failed to create %s engine: %v", algorithm, err)
+        }
+        joined, err := engine.AddNode(ctx, e)
+        if algorithm == "pow" {
+            if !errors.Is(err, engine.ErrFixedMembership) {
+                t.Errorf("pow: expected ErrFixedMembership, got %v", err)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("%s: failed to add a node: %v", algorithm, err)
+            continue
+        }
+        if n := len(e.Participants()); n != 5 {
+            t.Errorf("%s: expected 5 participants, got %d", algorithm, n)
+        }
+        if err := e.Submit(ctx, "Test block"); err != nil {
+            t.Errorf("%s: failed to submit after adding %s: %v", algorithm, joined.ID, err)
+        }
+        if err := engine.RemoveNode(ctx, e, joined.ID); err != nil {
+            t.Errorf("%s: failed to remove %s: %v", algorithm, joined.ID, err)
+        }
+        if n := len(e.Participants()); n != 4 {
+            t.Errorf("%s: expected 4 participants, got %d", algorithm, n)
+        }
+        if err := engine.ValidateChain(e); err != nil {
+            t.Errorf("%s: Expected a valid chain across the membership changes, got %v", algorithm, err)
+        }
+        if err := engine.RemoveNode(ctx, e, joined.ID); !errors.Is(err, node.ErrUnknownNode) {
+            t.Errorf("%s: expected ErrUnknownNode removing %s twice, got %v", algorithm, joined.ID, err)
+        }
+    }
+}
+
+func TestMembershipValidateCountsQuorumAtEachBlock(t *testing.T) {
+    ctx := context.Background()
+    e, _ := engine.New("pbft", engine.Config{Nodes: 4})
+    e.Submit(ctx, "Test block 1") // Certified by 4 replicas, a quorum when there were 4.
+    for i := 0; i < 3; i++ {
+        if _, err := engine.AddNode(ctx, e); err != nil {
+            t.Fatalf("Failed to add a replica: %v", err)
+        }
+    }
+    e.Submit(ctx, "Test block 2")
+    if err := engine.ValidateChain(e); err != nil {
+        t.Errorf("Expected early blocks to be checked against the 4 replicas of the time, got %v", err)
+    }
+}
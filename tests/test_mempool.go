@@ -0,0 +1,109 @@
+package tests
+
+import (
+    "errors"
+    "slices"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/mempool"
+    "consensus-algorithms-edu/sim"
+)
+
+func TestTransferFeesAreChargedOnTopOfTheAmount(t *testing.T) {
+    accounts := ledger.NewAccounts(map[string]uint64{"alice": 100})
+    if err := accounts.Apply(ledger.EncodeTransfers(ledger.Transfer{From: "alice", To: "bob", Amount: 60, Fee: 5})); err != nil {
+        t.Fatalf("Expected the transfer to apply, got %v", err)
+    }
+    if accounts.Balance("alice") != 35 || accounts.Balance("bob") != 60 {
+        t.Errorf("Expected balances 35 and 60, got %d and %d", accounts.Balance("alice"), accounts.Balance("bob"))
+    }
+    overdraft := ledger.EncodeTransfers(ledger.Transfer{From: "alice", To: "bob", Amount: 35, Nonce: 1, Fee: 1})
+    if err := accounts.Check(overdraft); !errors.Is(err, ledger.ErrOverdraft) {
+        t.Errorf("Expected a fee the sender cannot pay to be an overdraft, got %v", err)
+    }
+}
+
+func TestPoolSelectsByFeeWithinLimitsAndNonceOrder(t *testing.T) {
+    pool := mempool.New(mempool.Config{Capacity: 4, Limits: mempool.Limits{Gas: 3 * mempool.TransferGas}})
+    for _, tr := range []ledger.Transfer{
+        {From: "alice", To: "shop", Amount: 1, Nonce: 0, Fee: 1},
+        {From: "alice", To: "shop", Amount: 1, Nonce: 1, Fee: 90}, // Waits for alice's cheap first transfer.
+        {From: "bob", To: "shop", Amount: 1, Fee: 50},
+        {From: "carol", To: "shop", Amount: 1, Fee: 20},
+    } {
+        if err := pool.Add(tr); err != nil {
+            t.Fatalf("Expected %+v to be added, got %v", tr, err)
+        }
+    }
+    if err := pool.Add(ledger.Transfer{From: "dave", To: "shop", Amount: 1, Fee: 1}); !errors.Is(err, mempool.ErrFull) {
+        t.Errorf("Expected a full pool to turn away a fee no higher than its cheapest, got %v", err)
+    }
+    if err := pool.Add(ledger.Transfer{From: "bob", To: "shop", Amount: 1, Fee: 50}); !errors.Is(err, mempool.ErrUnderpriced) {
+        t.Errorf("Expected a replacement paying no more to be underpriced, got %v", err)
+    }
+    if err := pool.Add(ledger.Transfer{From: "erin", To: "shop", Amount: 1, Fee: 30}); err != nil {
+        t.Fatalf("Expected a higher fee to evict the cheapest transfer, got %v", err)
+    }
+    if pool.Len() != 4 {
+        t.Errorf("Expected the pool to stay at its capacity of 4, got %d", pool.Len())
+    }
+
+    // Alice's first transfer was evicted, so her second cannot apply and is left out.
+    valid := func(transfers []ledger.Transfer) bool {
+        for _, tr := range transfers {
+            if tr.From == "alice" && tr.Nonce > 0 && !slices.ContainsFunc(transfers, func(u ledger.Transfer) bool { return u.From == "alice" && u.Nonce == 0 }) {
+                return false
+            }
+        }
+        return true
+    }
+    var fees []uint64
+    for _, tr := range pool.Select(valid) {
+        fees = append(fees, tr.Fee)
+    }
+    if !slices.Equal(fees, []uint64{50, 30, 20}) {
+        t.Errorf("Expected the block to carry fees 50, 30 and 20, got %v", fees)
+    }
+    if !pool.Limits().Allow(ledger.EncodeTransfers(pool.Select(nil)...)) || pool.Limits().Allow(ledger.EncodeTransfers(pool.Transfers()...)) {
+        t.Errorf("Expected a selected block within the gas limit and the whole pool beyond it")
+    }
+}
+
+func TestMinersFillBlocksWithTheHighestFees(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 1})
+    peers := []int32{0, 1}
+    var miners []*pow.Miner
+    for _, id := range peers {
+        m := pow.NewMiner(pow.MinerConfig{ID: id, Peers: peers, MineProbability: 0.002, Rand: s.NewRand(), Clock: s.Clock(),
+            Mempool: mempool.New(mempool.Config{Limits: mempool.Limits{Gas: 2 * mempool.TransferGas}})})
+        miners = append(miners, m)
+        s.Add(m)
+    }
+    for i, fee := range []uint64{3, 9, 1, 7} {
+        for _, id := range peers {
+            s.Propose(id, ledger.EncodeTransfers(ledger.Transfer{From: string(rune('a' + i)), To: "shop", Amount: 1, Fee: fee}))
+        }
+    }
+    s.RunUntil(func() bool { return miners[0].Head().GetIndex() >= 2 }, time.Minute)
+    s.RunFor(100 * time.Millisecond) // Lets the last block reach the other miner.
+
+    chain := miners[0].Chain()
+    if len(chain) < 3 {
+        t.Fatalf("Expected two blocks, got %d", len(chain)-1)
+    }
+    var fees []uint64
+    for _, block := range chain[1:3] {
+        transfers, _ := ledger.DecodeTransfers(block.GetData())
+        for _, tr := range transfers {
+            fees = append(fees, tr.Fee)
+        }
+    }
+    if !slices.Equal(fees, []uint64{9, 7, 3, 1}) {
+        t.Errorf("Expected the first block to carry fees 9 and 7 and the second 3 and 1, got %v", fees)
+    }
+    if miners[0].Mempool().Len() != 0 || miners[1].Mempool().Len() != 0 {
+        t.Errorf("Expected included transfers to leave every pool, got %d and %d waiting", miners[0].Mempool().Len(), miners[1].Mempool().Len())
+    }
+}
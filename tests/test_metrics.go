@@ -0,0 +1,126 @@
+package tests
+
+import (
+    "context"
+    "io"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/metrics"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/wire"
+)
+
+// scrape fetches the metrics page served by m.
+func scrape(t *testing.T, m *metrics.Metrics) string {
+    server := httptest.NewServer(m)
+    defer server.Close()
+    resp, err := server.Client().Get(server.URL)
+    if err != nil {
+        t.Fatalf("Scrape failed: %v", err)
+    }
+    defer resp.Body.Close()
+    if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+        t.Errorf("Expected the Prometheus text format, got %q", ct)
+    }
+    body, _ := io.ReadAll(resp.Body)
+    return string(body)
+}
+
+func TestMetricsTextFormat(t *testing.T) {
+    m := metrics.New()
+    m.BlockCommitted("raft", "node-0")
+    m.BlockCommitted("raft", "node-0")
+    m.MessageSent("pbft", &wire.Envelope{Body: &wire.Envelope_Prepare{Prepare: &wire.Prepare{}}})
+    m.ElectionStarted("raft")
+    m.RoundCompleted("raft", 20*time.Millisecond)
+    m.RoundCompleted("raft", 3*time.Second)
+    m.TimeoutChanged("pbft", "node-1", 20)
+    m.TimeoutChanged("pbft", "node-1", 40)
+
+    page := scrape(t, m)
+    for _, line := range []string{
+        "# TYPE consensus_blocks_committed_total counter",
+        `consensus_blocks_committed_total{algorithm="raft",node="node-0"} 2`,
+        `consensus_messages_sent_total{algorithm="pbft",type="Prepare"} 1`,
+        `consensus_elections_total{algorithm="raft"} 1`,
+        "# TYPE consensus_round_duration_seconds histogram",
+        `consensus_round_duration_seconds_bucket{algorithm="raft",le="0.01"} 0`,
+        `consensus_round_duration_seconds_bucket{algorithm="raft",le="0.025"} 1`,
+        `consensus_round_duration_seconds_bucket{algorithm="raft",le="5"} 2`,
+        `consensus_round_duration_seconds_bucket{algorithm="raft",le="+Inf"} 2`,
+        `consensus_round_duration_seconds_sum{algorithm="raft"} 3.02`,
+        `consensus_round_duration_seconds_count{algorithm="raft"} 2`,
+        "# TYPE consensus_timeout_ticks gauge",
+        `consensus_timeout_ticks{algorithm="pbft",node="node-1"} 40`,
+    } {
+        if !strings.Contains(page, line+"\n") {
+            t.Errorf("Expected line %q in:\n%s", line, page)
+        }
+    }
+}
+
+func TestMetricsFromRunner(t *testing.T) {
+    m := metrics.New()
+    peers := []int32{0, 1, 2}
+    replicas := make([]node.Replica, len(peers))
+    for i, id := range peers {
+        replicas[i] = raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: peers})
+    }
+    runners := startCluster(t, replicas, func(r *node.Runner) {
+        r.Algorithm = "raft"
+        r.Metrics = m
+    })
+
+    deadline := time.Now().Add(5 * time.Second)
+    for accepted := false; !accepted; time.Sleep(5 * time.Millisecond) {
+        if time.Now().After(deadline) {
+            t.Fatalf("No leader was elected")
+        }
+        for _, runner := range runners {
+            if runner.Propose("Test block 1") == nil {
+                accepted = true
+                break
+            }
+        }
+    }
+    waitForCommits(t, runners, 2)
+
+    page := scrape(t, m)
+    for _, want := range []string{
+        `consensus_messages_sent_total{algorithm="raft",type="RequestVote"}`,
+        `consensus_messages_sent_total{algorithm="raft",type="AppendEntries"}`,
+        `consensus_elections_total{algorithm="raft"}`,
+        `consensus_blocks_committed_total{algorithm="raft",node="node-1"}`,
+        `consensus_round_duration_seconds_count{algorithm="raft"} 1`,
+    } {
+        if !strings.Contains(page, want) {
+            t.Errorf("Expected %q in:\n%s", want, page)
+        }
+    }
+}
+
+func TestMetricsFromEngine(t *testing.T) {
+    m := metrics.New()
+    e, err := engine.New("pbft", engine.Config{})
+    if err != nil {
+        t.Fatalf("New failed: %v", err)
+    }
+    e = engine.Instrument(e, m)
+    for _, data := range []string{"Test block 1", "Test block 2", "Test block 3"} {
+        if err := e.Submit(context.Background(), data); err != nil {
+            t.Fatalf("Submit failed: %v", err)
+        }
+    }
+
+    page := scrape(t, m)
+    if !strings.Contains(page, `consensus_blocks_committed_total{algorithm="pbft",node="pbft"} 3`) {
+        t.Errorf("Expected 3 committed blocks in:\n%s", page)
+    }
+    if !strings.Contains(page, `consensus_round_duration_seconds_count{algorithm="pbft"} 3`) {
+        t.Errorf("Expected 3 rounds in:\n%s", page)
+    }
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+    "errors"
+    "strings"
+    "testing"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/modelcheck"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/wire"
+)
+
+func newModelRaft(id int32, peers []int32, env modelcheck.Env) node.Replica {
+    return raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: peers, Rand: env.Rand, Clock: env.Clock})
+}
+
+func newModelPBFT(id int32, peers []int32, env modelcheck.Env) node.Replica {
+    return pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: peers, Clock: env.Clock})
+}
+
+// doubleVoter is a deliberately broken replica. A proposer commits a block once a majority has acknowledged it,
+// but a replica acknowledges every proposal for the next height instead of only the first, so two proposers can
+// both win the same height when their proposals reach the same replica before either commits.
+type doubleVoter struct {
+    id    int32
+    peers []int32
+    chain []*wire.Block
+    seen  map[string]*wire.Block // Proposals received, by hash.
+    acks  map[string]int         // Acknowledgements of this replica's proposals, by hash.
+}
+
+func newDoubleVoter(id int32, peers []int32, env modelcheck.Env) node.Replica {
+    return &doubleVoter{id: id, peers: peers, chain: []*wire.Block{{Hash: "genesis"}}, seen: map[string]*wire.Block{}, acks: map[string]int{}}
+}
+
+func (r *doubleVoter) ID() int32                { return r.id }
+func (r *doubleVoter) Tick() []*wire.Envelope   { return nil }
+func (r *doubleVoter) Committed() []*wire.Block { return r.chain }
+
+func (r *doubleVoter) Propose(data string) ([]*wire.Envelope, error) {
+    block := &wire.Block{Index: int64(len(r.chain)), PrevHash: r.chain[len(r.chain)-1].GetHash(), Hash: data, Data: data}
+    r.seen[block.GetHash()] = block
+    r.acks[block.GetHash()] = 1
+    return r.broadcast(&wire.Envelope{Body: &wire.Envelope_BlockProposal{BlockProposal: &wire.BlockProposal{Block: block}}}), nil
+}
+
+func (r *doubleVoter) Step(env *wire.Envelope) []*wire.Envelope {
+    switch body := env.GetBody().(type) {
+    case *wire.Envelope_BlockProposal:
+        block := body.BlockProposal.GetBlock()
+        r.seen[block.GetHash()] = block
+        if block.GetIndex() == int64(len(r.chain)) { // The bug: nothing stops a second acknowledgement.
+            ack := &wire.Prepare{Sequence: block.GetIndex(), Digest: block.GetHash()}
+            return []*wire.Envelope{{From: r.id, To: env.GetFrom(), Body: &wire.Envelope_Prepare{Prepare: ack}}}
+        }
+    case *wire.Envelope_Prepare:
+        r.acks[body.Prepare.GetDigest()]++
+        if r.acks[body.Prepare.GetDigest()] == len(r.peers)/2+1 && r.commit(body.Prepare.GetDigest()) {
+            commit := &wire.Commit{Sequence: body.Prepare.GetSequence(), Digest: body.Prepare.GetDigest()}
+            return r.broadcast(&wire.Envelope{Body: &wire.Envelope_Commit{Commit: commit}})
+        }
+    case *wire.Envelope_Commit:
+        r.commit(body.Commit.GetDigest())
+    }
+    return nil
+}
+
+// commit appends the proposal with the given hash if it extends the chain.
+func (r *doubleVoter) commit(hash string) bool {
+    block, ok := r.seen[hash]
+    if !ok || block.GetIndex() != int64(len(r.chain)) {
+        return false
+    }
+    r.chain = append(r.chain, block)
+    return true
+}
+
+func (r *doubleVoter) broadcast(env *wire.Envelope) []*wire.Envelope {
+    var out []*wire.Envelope
+    for _, peer := range r.peers {
+        if peer != r.id {
+            out = append(out, &wire.Envelope{From: r.id, To: peer, Body: env.GetBody()})
+        }
+    }
+    return out
+}
+
+func TestModelCheckRaftElectionIsExhaustive(t *testing.T) {
+    result, err := modelcheck.Check(modelcheck.Config{Nodes: 3, Commands: []string{"Test block 1"}, Timeouts: 1, New: newModelRaft})
+    if err != nil {
+        t.Fatalf("Expected Raft to be safe, got %v", err)
+    }
+    if !result.Complete {
+        t.Errorf("Expected an election and one command to be explored exhaustively, got %+v", result)
+    }
+}
+
+// TestModelCheckTwoCommands checks three Raft replicas and four PBFT replicas with two commands racing. The
+// exhaustive Raft search visits about one and a half million states, so the test bounds both searches.
+func TestModelCheckTwoCommands(t *testing.T) {
+    commands := []string{"Test block 1", "Test block 2"}
+    for name, cfg := range map[string]modelcheck.Config{
+        "raft": {Nodes: 3, Commands: commands, Timeouts: 1, New: newModelRaft, MaxStates: 10000},
+        "pbft": {Nodes: 4, Commands: commands, New: newModelPBFT, MaxStates: 4000},
+    } {
+        result, err := modelcheck.Check(cfg)
+        if err != nil {
+            t.Errorf("Expected %s to be safe, got %v", name, err)
+        } else if result.States != cfg.MaxStates {
+            t.Errorf("Expected the %s search to reach its bound of %d states, got %+v", name, cfg.MaxStates, result)
+        }
+    }
+}
+
+func TestModelCheckFindsDoubleVote(t *testing.T) {
+    cfg := modelcheck.Config{Nodes: 3, Commands: []string{"Test block 1", "Test block 2"}, New: newDoubleVoter}
+    _, err := modelcheck.Check(cfg)
+    var violation *modelcheck.Violation
+    if !errors.As(err, &violation) {
+        t.Fatalf("Expected a violation, got %v", err)
+    }
+    if !strings.HasPrefix(violation.Err.Error(), "agreement") {
+        t.Errorf("Expected an agreement violation, got %v", violation.Err)
+    }
+    if len(violation.Trace) == 0 || !strings.HasPrefix(violation.Trace[0], "propose") {
+        t.Errorf("Expected a trace starting with a proposal, got %v", violation.Trace)
+    }
+}
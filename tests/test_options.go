@@ -0,0 +1,112 @@
+package tests
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/dpos"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/options"
+)
+
+func TestOptionsDefaultsAndClamping(t *testing.T) {
+    o := options.New()
+    if o.Nodes != options.DefaultNodes || o.Faulty != 0 || o.Seeded {
+        t.Errorf("Expected the defaults, got %+v", o)
+    }
+    o = options.New(options.WithNodes(3), options.WithNodes(0), options.WithFaulty(9))
+    if o.Nodes != 3 || o.Faulty != 3 {
+        t.Errorf("Expected 3 nodes, all faulty, got %+v", o)
+    }
+}
+
+func TestPBFTToleratesUpToFFaultyNodes(t *testing.T) {
+    tolerated := pbft.NewPBFTNetwork(options.WithNodes(7), options.WithFaulty(2))
+    tolerated.RunPBFT("Tolerated")
+    if len(tolerated.Blocks) != 2 {
+        t.Errorf("Expected 7 nodes to commit despite 2 faulty ones, got %d blocks", len(tolerated.Blocks))
+    }
+
+    broken := pbft.NewPBFTNetwork(options.WithNodes(7), options.WithFaulty(3))
+    broken.RunPBFT("Not tolerated")
+    if len(broken.Blocks) != 1 {
+        t.Errorf("Expected 7 nodes with 3 faulty ones to reject the block, got %d blocks", len(broken.Blocks))
+    }
+}
+
+func TestRaftNeedsAMajorityOfLiveNodes(t *testing.T) {
+    healthy := raft.NewRaftNetwork(options.WithNodes(5), options.WithFaulty(2))
+    if healthy.Leader == nil {
+        t.Fatalf("Expected a leader with 3 of 5 nodes alive")
+    }
+    healthy.Leader.Lead("Replicated")
+    if len(healthy.Blocks) != 2 {
+        t.Errorf("Expected the block to be committed, got %d blocks", len(healthy.Blocks))
+    }
+
+    if crashed := raft.NewRaftNetwork(options.WithNodes(5), options.WithFaulty(3)); crashed.Leader != nil {
+        t.Errorf("Expected no leader with 2 of 5 nodes alive")
+    }
+}
+
+func TestSeedMakesStakeSelectionRepeatable(t *testing.T) {
+    validators := []string{"Alice", "Bob", "Carol"}
+    stakes := map[string]int{"Alice": 50, "Bob": 30, "Carol": 20}
+    picks := func(seed int64) []string {
+        chain := pos.NewBlockchain(validators, stakes, options.WithSeed(seed))
+        var out []string
+        for i := 0; i < 20; i++ {
+            out = append(out, chain.SelectValidator())
+        }
+        return out
+    }
+    first, second := picks(42), picks(42)
+    for i := range first {
+        if first[i] != second[i] {
+            t.Fatalf("Expected the same validators from the same seed, got %v and %v", first, second)
+        }
+    }
+
+    elect := func() []string {
+        chain := dpos.NewBlockchain([]string{"a"}, map[string]string{"v1": "a", "v2": "b", "v3": "c", "v4": "d"}, options.WithSeed(7))
+        chain.CountVotes()
+        return chain.Delegates
+    }
+    if a, b := elect(), elect(); len(a) != 4 || a[0] != b[0] || a[1] != b[1] || a[2] != b[2] || a[3] != b[3] {
+        t.Errorf("Expected the same delegate order from the same seed, got %v and %v", a, b)
+    }
+}
+
+func TestPoWMiningTimeout(t *testing.T) {
+    chain := pow.NewBlockchain(options.WithTimeout(time.Nanosecond))
+    chain.Difficulty = 64 // No hash will ever have this many leading zeros.
+    if _, err := chain.AddBlock("Never mined"); !errors.Is(err, pow.ErrMiningTimeout) {
+        t.Errorf("Expected mining to time out, got %v", err)
+    }
+    if len(chain.Blocks) != 1 {
+        t.Errorf("Expected the chain to be unchanged, got %d blocks", len(chain.Blocks))
+    }
+
+    patient := pow.NewBlockchain(options.WithTimeout(time.Minute))
+    if block, err := patient.AddBlock("Mined in time"); err != nil || !block.MeetsDifficulty() {
+        t.Errorf("Expected the block to be mined within a minute, got %v", err)
+    }
+}
+
+func TestEnginePassesOptions(t *testing.T) {
+    e, err := engine.New("raft", engine.Config{Nodes: 3, Options: []options.Option{options.WithFaulty(2), options.WithNodes(9)}})
+    if err != nil {
+        t.Fatalf("Failed to create the engine: %v", err)
+    }
+    if n := e.Status().Nodes; n != 3 {
+        t.Errorf("Expected Config.Nodes to win over options.WithNodes, got %d nodes", n)
+    }
+    if err := e.Submit(context.Background(), "Nobody to lead"); !errors.Is(err, engine.ErrRejected) {
+        t.Errorf("Expected a network without a majority to reject data, got %v", err)
+    }
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/testutil"
+)
+
+func TestPartitionRaftMinorityStalls(t *testing.T) {
+    c := testutil.Raft(sim.Link{Latency: sim.Constant(5 * time.Millisecond)}, options.WithNodes(5), options.WithSeed(11))
+    old, _ := c.WaitForLeader(10 * time.Second)
+
+    // Put the leader in a minority of two.
+    minority := []int32{old.ID()}
+    var majority []int32
+    for _, r := range c.Replicas {
+        if r == old {
+            continue
+        }
+        if len(minority) < 2 {
+            minority = append(minority, r.ID())
+        } else {
+            majority = append(majority, r.ID())
+        }
+    }
+    c.Network.Partition(minority, majority)
+
+    c.Propose(old.ID(), "Minority block")
+    c.RunFor(2 * time.Second)
+    if n := len(old.Committed()); n != 1 {
+        t.Errorf("Expected the minority to commit nothing, got %d blocks", n)
+    }
+    leader, _ := c.Leader()
+    if leader == old {
+        t.Fatalf("Expected the majority to elect a new leader")
+    }
+    c.Propose(leader.ID(), "Majority block")
+    c.RunFor(time.Second)
+
+    c.Network.Heal()
+    if !c.RunUntil(c.AllCommitted(2), c.Now()+10*time.Second) {
+        t.Fatalf("The cluster did not converge after healing")
+    }
+    for _, r := range c.Replicas {
+        if data := r.Committed()[1].GetData(); data != "Majority block" {
+            t.Errorf("Replica %d: expected 'Majority block', got '%s'", r.ID(), data)
+        }
+    }
+}
+
+func TestPartitionPBFTWithoutQuorumStalls(t *testing.T) {
+    c := testutil.PBFT(sim.Link{Latency: sim.Constant(5 * time.Millisecond)}, options.WithSeed(5))
+    c.Network.Partition([]int32{0, 1}, []int32{2, 3})
+
+    c.Propose(0, "Test block 1")
+    c.RunFor(2 * time.Second)
+    for _, r := range c.Replicas {
+        if n := len(r.Committed()); n != 1 {
+            t.Errorf("Replica %d committed %d blocks without a quorum", r.ID(), n)
+        }
+    }
+
+    c.Network.Heal()
+    if !c.RunUntil(c.AllCommitted(2), c.Now()+30*time.Second) {
+        t.Fatalf("The block was not committed after healing")
+    }
+}
+
+func TestPartitionPoWForksAndReorganizes(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 9, Network: sim.Link{Latency: sim.Constant(10 * time.Millisecond)}})
+    peers := []int32{0, 1, 2, 3}
+    miners := make([]*pow.Miner, len(peers))
+    for i, id := range peers {
+        miners[i] = pow.NewMiner(pow.MinerConfig{ID: id, Peers: peers, MineProbability: 0.02, Rand: s.NewRand(), Clock: s.Clock()})
+        s.Add(miners[i])
+    }
+    s.Network.Partition([]int32{0, 1}, []int32{2, 3})
+    s.RunFor(time.Second)
+
+    if miners[0].Head().GetHash() == miners[2].Head().GetHash() {
+        t.Fatalf("Expected the two sides to fork")
+    }
+
+    s.Network.Heal()
+    converged := func() bool {
+        head := miners[0].Head().GetHash()
+        for _, m := range miners[1:] {
+            if m.Head().GetHash() != head {
+                return false
+            }
+        }
+        return true
+    }
+    if !s.RunUntil(converged, s.Now()+30*time.Second) {
+        t.Fatalf("The miners did not converge on one chain after healing")
+    }
+    reorgs := 0
+    for _, m := range miners {
+        reorgs += m.Reorgs()
+    }
+    if reorgs == 0 {
+        t.Errorf("Expected at least one miner to reorganize onto the winning branch")
+    }
+}
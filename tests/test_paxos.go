@@ -1,12 +1,19 @@
 package tests
 
 import (
+    "context"
+    "errors"
+    "fmt"
+    "slices"
     "testing"
     "consensus-algorithms-edu/algorithms/paxos"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/options"
 )
 
 func TestPaxos(t *testing.T) {
-    blockchain := paxos.NewPaxosNetwork(5)
+    blockchain := paxos.NewPaxosNetwork(options.WithNodes(5))
 
     blockchain.RunPaxos("Test block 1", 1)
     blockchain.RunPaxos("Test block 2", 2)
@@ -15,8 +22,85 @@ func TestPaxos(t *testing.T) {
         t.Errorf("Expected 3 blocks, got %d", len(blockchain.Blocks))
     }
 
-    lastBlock := blockchain.Blocks[len(blockchain.Blocks)-1]
+    lastBlock := blockchain.Head()
     if lastBlock.Data != "Test block 2" {
         t.Errorf("Expected 'Test block 2', got '%s'", lastBlock.Data)
     }
 }
+
+func TestPaxosReconfigurationTakesEffectAlphaSlotsLater(t *testing.T) {
+    ctx := context.Background()
+    chain := paxos.NewPaxosNetwork(options.WithNodes(3), options.WithAlpha(3))
+
+    id, err := chain.AddNode(ctx, 1) // Chosen at slot 1, so node-3 accepts from slot 4 on.
+    if err != nil || id != 3 {
+        t.Fatalf("Expected node 3 to be added, got %d, %v", id, err)
+    }
+    for slot, want := range map[int][]int{2: {0, 1, 2}, 3: {0, 1, 2}, 4: {0, 1, 2, 3}} {
+        if got := chain.Configuration(slot); !slices.Equal(got, want) {
+            t.Errorf("Expected acceptors %v at slot %d, got %v", want, slot, got)
+        }
+    }
+    for slot := 2; slot <= 4; slot++ {
+        if _, err := chain.RunPaxos(fmt.Sprintf("Test block %d", slot), slot); err != nil {
+            t.Fatalf("Slot %d was not chosen: %v", slot, err)
+        }
+    }
+    joined := chain.Nodes[3]
+    if len(joined.Proposals) != 1 || joined.Proposals[0].ProposalID != 4 {
+        t.Errorf("Expected node 3 to accept only the proposal for slot 4, got %v", joined.Proposals)
+    }
+
+    proposer := chain.Nodes[0]
+    if err := chain.RemoveNode(ctx, 0, 5); err != nil { // Chosen at slot 5, so node-0 leaves at slot 8.
+        t.Fatalf("Failed to remove node 0: %v", err)
+    }
+    if err := chain.RemoveNode(ctx, 0, 6); !errors.Is(err, node.ErrUnknownNode) {
+        t.Errorf("Expected ErrUnknownNode removing node 0 twice, got %v", err)
+    }
+    for slot := 6; slot <= 8; slot++ {
+        if _, err := chain.RunPaxos(fmt.Sprintf("Test block %d", slot), slot); err != nil {
+            t.Fatalf("Slot %d was not chosen: %v", slot, err)
+        }
+    }
+    var accepted []int
+    for _, p := range proposer.Proposals {
+        accepted = append(accepted, p.ProposalID)
+    }
+    if !slices.Contains(accepted, 7) || slices.Contains(accepted, 8) {
+        t.Errorf("Expected node 0 to take part up to slot 7 and not in slot 8, got proposals %v", accepted)
+    }
+    if len(chain.Nodes) != 3 || chain.Nodes[0].ID != 1 {
+        t.Errorf("Expected node 1 to propose once node 0 left, got nodes starting with %d", chain.Nodes[0].ID)
+    }
+}
+
+func TestPaxosClientDataCannotChangeAcceptors(t *testing.T) {
+    chain := paxos.NewPaxosNetwork(options.WithNodes(3))
+    for i, data := range []string{"config: add node-7", "config: remove node-0", "config: remove node-1"} {
+        if _, err := chain.RunPaxos(data, i+1); !errors.Is(err, paxos.ErrReservedData) {
+            t.Errorf("Expected %q to be refused with ErrReservedData, got %v", data, err)
+        }
+    }
+    proposal := chain.Nodes[0].Propose("config: remove node-2", 4)
+    if _, err := chain.Nodes[0].CommitProposal(proposal); !errors.Is(err, paxos.ErrReservedData) {
+        t.Errorf("Expected a committed config proposal to be refused with ErrReservedData, got %v", err)
+    }
+
+    e, err := engine.New("paxos", engine.Config{Nodes: 3})
+    if err != nil {
+        t.Fatalf("Failed to create the engine: %v", err)
+    }
+    if err := e.Submit(context.Background(), "config: remove node-0"); !errors.Is(err, paxos.ErrReservedData) {
+        t.Errorf("Expected a submitted config block to be refused with ErrReservedData, got %v", err)
+    }
+
+    for _, data := range []string{"Test block 1", "Test block 2", "Test block 3"} {
+        if _, err := chain.RunPaxos(data, len(chain.Blocks)+4); err != nil {
+            t.Fatalf("%q was not chosen: %v", data, err)
+        }
+    }
+    if got := chain.Configuration(len(chain.Blocks) + 1); !slices.Equal(got, []int{0, 1, 2}) || len(chain.Nodes) != 3 {
+        t.Errorf("Expected the acceptors to stay 0, 1 and 2, got %v with %d nodes", got, len(chain.Nodes))
+    }
+}
@@ -3,10 +3,11 @@ package tests
 import (
     "testing"
     "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/options"
 )
 
 func TestPBFT(t *testing.T) {
-    blockchain := pbft.NewPBFTNetwork(5)
+    blockchain := pbft.NewPBFTNetwork(options.WithNodes(5))
 
     blockchain.RunPBFT("Test block 1")
     blockchain.RunPBFT("Test block 2")
@@ -15,7 +16,7 @@ func TestPBFT(t *testing.T) {
         t.Errorf("Expected 3 blocks, got %d", len(blockchain.Blocks))
     }
 
-    lastBlock := blockchain.Blocks[len(blockchain.Blocks)-1]
+    lastBlock := blockchain.Head()
     if lastBlock.Data != "Test block 2" {
         t.Errorf("Expected 'Test block 2', got '%s'", lastBlock.Data)
     }
@@ -1,8 +1,15 @@
 package tests
 
 import (
+    "errors"
+    "fmt"
     "testing"
+    "time"
     "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/evidence"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
 )
 
 func TestPoS(t *testing.T) {
@@ -18,8 +25,140 @@ func TestPoS(t *testing.T) {
         t.Errorf("Expected 3 blocks, got %d", len(blockchain.Blocks))
     }
 
-    lastBlock := blockchain.Blocks[len(blockchain.Blocks)-1]
+    lastBlock := blockchain.Head()
     if lastBlock.Data != "Test block 2" {
         t.Errorf("Expected 'Test block 2', got '%s'", lastBlock.Data)
     }
 }
+
+func TestPoSCloneSelectsTheValidatorsTheOriginalWould(t *testing.T) {
+    validators := []string{"Alice", "Bob", "Carol"}
+    stakes := map[string]int{"Alice": 40, "Bob": 30, "Carol": 30}
+    blockchain := pos.NewBlockchain(validators, stakes, options.WithSeed(7))
+    blockchain.AddBlock("Test block 1")
+
+    clone := blockchain.Clone()
+    for i := 2; i <= 6; i++ {
+        want, _ := blockchain.AddBlock(fmt.Sprintf("Test block %d", i))
+        got, _ := clone.AddBlock(fmt.Sprintf("Test block %d", i))
+        if got.Validator != want.Validator {
+            t.Errorf("Expected the clone to select %s for block %d, got %s", want.Validator, i, got.Validator)
+        }
+    }
+
+    clone.Stakes["Bob"] = 99
+    if blockchain.Stakes["Bob"] != 30 || blockchain.Height() != 6 {
+        t.Errorf("Expected the original to be unchanged by its clone, got stake %d and height %d", blockchain.Stakes["Bob"], blockchain.Height())
+    }
+}
+
+func TestPoSValidatorSlashJailsUntilUnjailed(t *testing.T) {
+    peers := []int32{0, 1, 2}
+    stakes := map[int32]int{0: 40, 1: 40}
+    v := pos.NewValidator(pos.ValidatorConfig{ID: 1, Peers: peers, Stakes: stakes, SlotTicks: 1, SlashPercent: 25, JailSlots: 5})
+
+    if err := v.Bond(2, 20); err != nil {
+        t.Fatalf("Bond failed: %v", err)
+    }
+    if err := v.Bond(2, 0); err == nil {
+        t.Errorf("Expected Bond to refuse a stake that is not positive")
+    }
+    v.Slash(doubleSigned(0, time.Now()))
+    if got := v.Stake(0); got != 30 {
+        t.Errorf("Expected the slashed validator to keep 30 of its 40, got %d", got)
+    }
+    if !v.Jailed(0) {
+        t.Fatalf("Expected the slashed validator to be jailed")
+    }
+    v.Slash(doubleSigned(0, time.Now()))
+    if got := v.Stake(0); got != 30 {
+        t.Errorf("Expected slashing a jailed validator to do nothing, got a stake of %d", got)
+    }
+    proposes := func(id int32) bool {
+        for slot := 0; slot < 100; slot++ {
+            if v.Proposer(slot) == id {
+                return true
+            }
+        }
+        return false
+    }
+    if proposes(0) {
+        t.Errorf("Expected a jailed validator never to be scheduled")
+    }
+    if !proposes(2) {
+        t.Errorf("Expected a bonded validator to be scheduled")
+    }
+
+    if err := v.Unjail(0); !errors.Is(err, pos.ErrJailed) {
+        t.Errorf("Expected ErrJailed before the jail time ran out, got %v", err)
+    }
+    for range 5 {
+        v.Tick()
+    }
+    if err := v.Unjail(0); err != nil {
+        t.Fatalf("Unjail failed: %v", err)
+    }
+    if v.Jailed(0) || !proposes(0) {
+        t.Errorf("Expected the unjailed validator to be scheduled again")
+    }
+    if err := v.Unjail(0); !errors.Is(err, pos.ErrNotJailed) {
+        t.Errorf("Expected ErrNotJailed, got %v", err)
+    }
+}
+
+func TestPoSValidatorSlashedWithDefaultsIsJailedForGood(t *testing.T) {
+    v := pos.NewValidator(pos.ValidatorConfig{ID: 1, Peers: []int32{0, 1}, Stakes: map[int32]int{0: 50, 1: 50}, SlotTicks: 1})
+    v.Slash(doubleSigned(0, time.Now()))
+    for range 100 {
+        v.Tick()
+    }
+    if got := v.Stake(0); got != 0 {
+        t.Errorf("Expected the whole stake to be slashed, got %d left", got)
+    }
+    if err := v.Unjail(0); !errors.Is(err, pos.ErrJailed) {
+        t.Errorf("Expected ErrJailed for a validator jailed for good, got %v", err)
+    }
+}
+
+// doubleSigned returns evidence that validator id proposed two blocks timestamped at.
+func doubleSigned(id int32, at time.Time) *wire.Evidence {
+    ts := uint64(wire.TimestampOf(at))
+    return &wire.Evidence{
+        First:  &wire.Statement{Signer: id, Kind: evidence.Block, Height: 2, Round: ts, Value: "a"},
+        Second: &wire.Statement{Signer: id, Kind: evidence.Block, Height: 2, Round: ts, Value: "b"},
+    }
+}
+
+func TestPoSValidatorsReleaseAtTheSameSlotWhenEvidenceArrivesLate(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 1, Network: sim.Link{Latency: sim.Constant(5 * time.Millisecond)}})
+    peers := []int32{0, 1, 2}
+    var validators []*pos.Validator
+    for _, id := range peers {
+        v := pos.NewValidator(pos.ValidatorConfig{ID: id, Peers: peers, Stakes: map[int32]int{0: 20, 1: 40, 2: 40}, JailSlots: 10, Clock: s.Clock()})
+        validators = append(validators, v)
+        s.Add(v)
+    }
+    slot := 100 * time.Millisecond // 10 ticks of 10ms.
+    s.RunFor(5 * slot)
+    offence := validators[1].Chain()[2] // Proposed as slot 2 started.
+
+    // Validator 1 holds the proof at slot 5, validator 2 only at slot 9: both jail node-0 until slot 12.
+    ev := doubleSigned(0, wire.Timestamp(offence.GetTimestamp()).Time())
+    validators[1].Slash(ev)
+    s.RunFor(4 * slot)
+    validators[2].Slash(ev)
+    for range 2 {
+        s.RunFor(slot)
+        for _, v := range validators[1:] {
+            if err := v.Unjail(0); !errors.Is(err, pos.ErrJailed) {
+                t.Fatalf("Expected validator %d to keep node-0 jailed before slot 12, got %v", v.ID(), err)
+            }
+        }
+    }
+    s.RunFor(slot)
+    for _, v := range validators[1:] {
+        if err := v.Unjail(0); err != nil {
+            t.Errorf("Expected validator %d to release node-0 at slot 12, got %v", v.ID(), err)
+        }
+    }
+}
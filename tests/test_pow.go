@@ -1,8 +1,16 @@
 package tests
 
 import (
+    "errors"
+    "fmt"
+    "math"
+    "strings"
     "testing"
+    "time"
     "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/blocktree"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
 )
 
 func TestPoW(t *testing.T) {
@@ -15,8 +23,247 @@ func TestPoW(t *testing.T) {
         t.Errorf("Expected 3 blocks, got %d", len(blockchain.Blocks))
     }
 
-    lastBlock := blockchain.Blocks[len(blockchain.Blocks)-1]
+    lastBlock := blockchain.Head()
     if lastBlock.Data != "Test block 2" {
         t.Errorf("Expected 'Test block 2', got '%s'", lastBlock.Data)
     }
 }
+
+func TestHashLeadingZerosMatchesHexPrefix(t *testing.T) {
+    blockchain := pow.NewBlockchain()
+    blockchain.AddBlock("Test block 1")
+    block := blockchain.Blocks[1]
+
+    hex := block.Hash.String()
+    zeros := len(hex) - len(strings.TrimLeft(hex, "0"))
+    if block.Hash.LeadingZeros() != zeros || zeros < pow.Difficulty {
+        t.Errorf("Expected %d leading zeros in %s, got %d", zeros, hex, block.Hash.LeadingZeros())
+    }
+    if odd := (wire.Hash{0, 0x0f}); odd.LeadingZeros() != 3 {
+        t.Errorf("Expected 3 leading zeros in %s, got %d", odd, odd.LeadingZeros())
+    }
+
+    parsed, err := wire.ParseHash(hex)
+    if err != nil || parsed != block.Hash {
+        t.Errorf("Expected %s to parse back to the block's hash, got %s (%v)", hex, parsed, err)
+    }
+    if w := block.ToWire(); w.Sum() != block.Hash || w.ParentSum() != block.PrevHash {
+        t.Errorf("Expected the wire block to carry the same hashes")
+    }
+    if _, err := wire.ParseHash(hex[:10]); !errors.Is(err, wire.ErrMalformed) {
+        t.Errorf("Expected a truncated hash to be malformed, got %v", err)
+    }
+    if genesis := blockchain.Blocks[0]; !genesis.PrevHash.IsZero() || genesis.ToWire().GetPrevHash() != "" {
+        t.Errorf("Expected the genesis block to have no parent hash")
+    }
+}
+
+func TestDifficultyBombDoublesAndForksPushItBack(t *testing.T) {
+    bomb := pow.Bomb{Start: 100, Period: 10, Delays: []pow.Delay{{Height: 120, Blocks: 50}}}
+    for _, c := range []struct {
+        height int
+        want   float64
+    }{{100, 2}, {109, 2}, {110, 3}, {119, 3}, {120, 1.125}, {150, 2}} {
+        if got := bomb.Factor(c.height); got != c.want {
+            t.Errorf("Expected a factor of %v at height %d, got %v", c.want, c.height, got)
+        }
+    }
+    if got := (pow.Bomb{}).Factor(1000); got != 1 {
+        t.Errorf("Expected no bomb to leave the difficulty alone, got a factor of %v", got)
+    }
+
+    heights := map[bool]int64{}
+    for _, armed := range []bool{false, true} {
+        s := sim.New(sim.Config{Seed: 1})
+        peers := []int32{0, 1}
+        var miner *pow.Miner
+        for _, id := range peers {
+            cfg := pow.MinerConfig{ID: id, Peers: peers, MineProbability: 0.005, Rand: s.NewRand(), Clock: s.Clock()}
+            if armed {
+                cfg.Bomb = pow.Bomb{Start: 10, Period: 5}
+            }
+            miner = pow.NewMiner(cfg)
+            s.Add(miner)
+        }
+        s.RunFor(time.Minute)
+        heights[armed] = miner.Head().GetIndex()
+    }
+    if heights[true] >= heights[false]/2 {
+        t.Errorf("Expected the bomb to slow the chain to well under half its height, got %d blocks against %d", heights[true], heights[false])
+    }
+}
+
+func TestTimestampRulesBoundMedianTimePastAndFuture(t *testing.T) {
+    var chain []*wire.Block
+    for _, seconds := range []int64{0, 10, 20, 30, 400, 50, 60} { // One block lies about being far ahead.
+        chain = append(chain, &wire.Block{Index: int64(len(chain)), Timestamp: seconds * int64(time.Second)})
+    }
+    if got := pow.MedianTimePast(chain, 5); got != wire.Timestamp(50*time.Second) {
+        t.Errorf("Expected the median of the last 5 blocks to be 50s, got %v", got)
+    }
+    rules := pow.TimestampRules{MedianSpan: 5, MaxFuture: time.Minute}
+    now := time.Unix(70, 0)
+    for _, c := range []struct {
+        seconds int64
+        want    error
+    }{{50, pow.ErrTimestampTooEarly}, {51, nil}, {130, nil}, {131, pow.ErrTimestampTooLate}} {
+        block := &wire.Block{Index: int64(len(chain)), Timestamp: c.seconds * int64(time.Second)}
+        if err := rules.Check(chain, block, now); !errors.Is(err, c.want) || (c.want == nil) != (err == nil) {
+            t.Errorf("Expected a block at %ds to give %v, got %v", c.seconds, c.want, err)
+        }
+    }
+    if err := (pow.TimestampRules{}).Check(chain, &wire.Block{Index: 7}, now); err != nil {
+        t.Errorf("Expected no rules to accept any timestamp, got %v", err)
+    }
+}
+
+func TestRetargetScalesWorkByPeriodDuration(t *testing.T) {
+    chain := func(spacing time.Duration) []*wire.Block {
+        blocks := []*wire.Block{{}}
+        for i := 1; i <= 2*pow.RetargetWindow; i++ {
+            blocks = append(blocks, &wire.Block{Index: int64(i), Timestamp: int64(time.Duration(i) * spacing)})
+        }
+        return blocks
+    }
+    retarget := pow.Retarget{Interval: 10 * time.Second}
+    for _, c := range []struct {
+        spacing time.Duration
+        want    float64
+    }{{10 * time.Second, 1}, {5 * time.Second, 2}, {time.Second, pow.MaxRetargetFactor}, {time.Minute, 1.0 / pow.MaxRetargetFactor}} {
+        blocks := chain(c.spacing)
+        if got := retarget.Work(blocks[:pow.RetargetWindow]); got != 1 {
+            t.Errorf("Expected the first period to take the base work, got %v", got)
+        }
+        if got := retarget.Work(blocks[:pow.RetargetWindow+1]); math.Abs(got-c.want) > 1e-9 {
+            t.Errorf("Expected blocks %v apart to make the next period take %v times the work, got %v", c.spacing, c.want, got)
+        }
+    }
+    if got := (pow.Retarget{}).Work(chain(time.Second)); got != 1 {
+        t.Errorf("Expected no retargeting to leave the work alone, got %v", got)
+    }
+}
+
+func TestRetargetWindowAndBoundAreConfigurable(t *testing.T) {
+    blocks := []*wire.Block{{}}
+    for i := 1; i <= 15; i++ {
+        blocks = append(blocks, &wire.Block{Index: int64(i), Timestamp: int64(time.Duration(i) * time.Second)})
+    }
+    retarget := pow.Retarget{Interval: 10 * time.Second, Window: 5, MaxFactor: 2}
+    for _, c := range []struct {
+        next int
+        want float64
+    }{{5, 1}, {6, 2}, {10, 2}, {11, 4}, {16, 8}} {
+        if got := retarget.Work(blocks[:c.next]); got != c.want {
+            t.Errorf("Expected block %d to take %v times the work, got %v", c.next, c.want, got)
+        }
+    }
+    if got := (pow.Retarget{Interval: 10 * time.Second, Window: 5, MaxFactor: 0.5}).Work(blocks); got != 1 {
+        t.Errorf("Expected a bound below 1 to keep the work fixed, got %v", got)
+    }
+    if got := pow.BitcoinRetarget.Work(blocks); got != 1 {
+        t.Errorf("Expected Bitcoin's 2016-block window not to have retargeted yet, got %v", got)
+    }
+}
+
+func TestForkChoiceFollowsMostWorkNotLongestChain(t *testing.T) {
+    genesis := &wire.Block{Hash: "g"}
+    branch := func(name string, n int, stamp func(i int) time.Duration) []*wire.Block {
+        blocks, parent := []*wire.Block{}, genesis
+        for i := 1; i <= n; i++ {
+            block := &wire.Block{Index: int64(i), PrevHash: parent.GetHash(), Hash: fmt.Sprintf("%s%d", name, i), Timestamp: int64(stamp(i))}
+            blocks, parent = append(blocks, block), block
+        }
+        return blocks
+    }
+    // Honest blocks come a second apart, so the second period takes 4 times the work. The liar dates the first
+    // and last block of the first period an hour out, so its second period takes a quarter of the work, and it
+    // mines one block more.
+    honest := branch("a", 21, func(i int) time.Duration { return time.Duration(i) * time.Second })
+    liar := branch("b", 22, func(i int) time.Duration {
+        switch i {
+        case 1:
+            return -time.Hour
+        case 20:
+            return time.Hour
+        }
+        return time.Duration(i) * time.Second
+    })
+    for _, c := range []struct {
+        retarget pow.Retarget
+        want     string
+    }{
+        {pow.Retarget{Interval: 10 * time.Second}, "a21"},
+        {pow.Retarget{Interval: 10 * time.Second, Window: 5, MaxFactor: 1.25}, "a21"}, // Each period of the honest branch takes 1.25 times the work.
+        {pow.Retarget{}, "b22"},
+    } {
+        tree := blocktree.New(genesis, pow.ForkChoice(pow.Bomb{}, c.retarget))
+        for _, block := range append(honest, liar...) {
+            tree.Add(block)
+        }
+        if got := tree.Head().GetHash(); got != c.want {
+            t.Errorf("Expected %v to follow %s, got %s", c.retarget, c.want, got)
+        }
+    }
+}
+
+func TestMinersRefuseBlocksDatedTooFarAhead(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 2})
+    peers := []int32{0, 1, 2}
+    var miners []*pow.Miner
+    for _, id := range peers {
+        cfg := pow.MinerConfig{ID: id, Peers: peers, MineProbability: 0.01, Timestamps: pow.BitcoinTimestamps, Rand: s.NewRand(), Clock: s.Clock()}
+        if id == 2 {
+            cfg.Timestamps = pow.TimestampRules{} // Checks nothing, not even its own lies.
+            cfg.Stamp = func(index int, now time.Time) time.Time { return now.Add(3 * time.Hour) }
+        }
+        miners = append(miners, pow.NewMiner(cfg))
+        s.Add(miners[len(miners)-1])
+    }
+    s.RunFor(time.Minute)
+
+    refused := 0
+    for _, block := range miners[0].Blocks() {
+        if block.GetProducer() == "node-2" {
+            refused++
+        }
+    }
+    if refused == 0 {
+        t.Fatalf("Expected node-2 to have mined blocks")
+    }
+    latest := wire.TimestampOf(s.Clock().Now().Add(2 * time.Hour))
+    for _, block := range miners[0].Chain() {
+        if block.GetProducer() == "node-2" || wire.Timestamp(block.GetTimestamp()) > latest {
+            t.Fatalf("Expected the honest chain to refuse blocks dated 3 hours ahead, got block %d from %s", block.GetIndex(), block.GetProducer())
+        }
+    }
+}
+
+func TestMinersRefuseBlocksBelowTheirDifficulty(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 2})
+    peers := []int32{0, 1, 2}
+    var miners []*pow.Miner
+    for _, id := range peers {
+        cfg := pow.MinerConfig{ID: id, Peers: peers, MineProbability: 0.01, Difficulty: 2, Rand: s.NewRand(), Clock: s.Clock()}
+        if id == 2 {
+            cfg.Difficulty = 1
+        }
+        miners = append(miners, pow.NewMiner(cfg))
+        s.Add(miners[len(miners)-1])
+    }
+    s.RunFor(time.Minute)
+
+    mined := 0
+    for _, block := range miners[2].Blocks() {
+        if block.GetProducer() == "node-2" {
+            mined++
+        }
+    }
+    if mined == 0 || len(miners[0].Chain()) < 2 {
+        t.Fatalf("Expected every miner to have mined blocks")
+    }
+    for _, block := range miners[0].Chain()[1:] {
+        if block.GetProducer() == "node-2" || block.GetDifficulty() != 2 || !strings.HasPrefix(block.GetHash(), "00") {
+            t.Fatalf("Expected the chain to hold only blocks mined at difficulty 2, got block %d from %s with hash %s", block.GetIndex(), block.GetProducer(), block.GetHash())
+        }
+    }
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+    "errors"
+    "io"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/profile"
+    "consensus-algorithms-edu/sim"
+)
+
+func TestProfilerChargesNestedPhasesExclusively(t *testing.T) {
+    p := profile.New()
+    if err := profile.Start(p); err != nil {
+        t.Fatalf("Start failed: %v", err)
+    }
+    defer profile.Stop()
+    if err := profile.Start(profile.New()); !errors.Is(err, profile.ErrActive) {
+        t.Errorf("Expected a second profiler to be refused, got %v", err)
+    }
+
+    handling := profile.Begin(profile.Handling)
+    time.Sleep(20 * time.Millisecond)
+    for range 2 {
+        hashing := profile.Begin(profile.Hashing)
+        time.Sleep(20 * time.Millisecond)
+        hashing.End()
+    }
+    handling.End()
+    profile.Stop()
+    profile.Begin(profile.State).End() // Not counted: no profiler is active.
+
+    report := p.Report()
+    if h := report.Phase(profile.Hashing); h.Calls != 2 || h.Time < 40*time.Millisecond {
+        t.Errorf("Expected 2 calls and at least 40ms of hashing, got %+v", h)
+    }
+    if h := report.Phase(profile.Handling); h.Calls != 1 || h.Time < 20*time.Millisecond || h.Time >= 40*time.Millisecond {
+        t.Errorf("Expected handling to be charged its own 20ms only, got %+v", h)
+    }
+    if s := report.Phase(profile.State); s.Calls != 0 {
+        t.Errorf("Expected no state phase after Stop, got %+v", s)
+    }
+    if report[0].Phase != profile.Hashing {
+        t.Errorf("Expected the report to start with the phase that took longest, got %s", report[0].Phase)
+    }
+}
+
+func TestSimulationIsProfiledByPhase(t *testing.T) {
+    p := profile.New()
+    if err := profile.Start(p); err != nil {
+        t.Fatalf("Start failed: %v", err)
+    }
+    defer profile.Stop()
+
+    s := sim.New(sim.Config{Seed: 2})
+    peers := []int32{0, 1, 2}
+    stakes := map[int32]int{0: 1, 1: 1, 2: 1}
+    var validators []*pos.Validator
+    for _, id := range peers {
+        v := pos.NewValidator(pos.ValidatorConfig{ID: id, Peers: peers, Stakes: stakes, State: ledger.NewAccounts(map[string]uint64{"alice": 100}), Clock: s.Clock()})
+        validators = append(validators, v)
+        s.Add(v)
+    }
+    validators[0].Propose(ledger.EncodeTransfers(ledger.Transfer{From: "alice", To: "bob", Amount: 10}))
+    s.RunFor(2 * time.Second)
+
+    report := p.Report()
+    for _, phase := range []profile.Phase{profile.Simulation, profile.Handling, profile.Hashing, profile.State} {
+        if u := report.Phase(phase); u.Calls == 0 || u.Time <= 0 {
+            t.Errorf("Expected the simulation to spend time in phase %s, got %+v", phase, u)
+        }
+    }
+    if total := report.Total(); total.Time <= 0 || total.Calls != report.Phase(profile.Simulation).Calls+report.Phase(profile.Handling).Calls+report.Phase(profile.Hashing).Calls+report.Phase(profile.State).Calls {
+        t.Errorf("Expected the total to add up the phases, got %+v", total)
+    }
+
+    server := httptest.NewServer(profile.Handler())
+    defer server.Close()
+    for path, want := range map[string]string{"/debug/phases": "handling", "/debug/pprof/": "goroutine"} {
+        resp, err := server.Client().Get(server.URL + path)
+        if err != nil {
+            t.Fatalf("GET %s failed: %v", path, err)
+        }
+        body, _ := io.ReadAll(resp.Body)
+        resp.Body.Close()
+        if !strings.Contains(string(body), want) {
+            t.Errorf("Expected %s to mention %q, got %q", path, want, body)
+        }
+    }
+}
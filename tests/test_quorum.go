@@ -0,0 +1,97 @@
+package tests
+
+import (
+    "errors"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/quorum"
+    "consensus-algorithms-edu/sim"
+)
+
+func TestQuorumThresholds(t *testing.T) {
+    members := []int32{0, 1, 2, 3, 4, 5, 6}
+    cases := []struct {
+        name    string
+        q       quorum.Quorum
+        reached []int32 // Smallest set of voters that suffices.
+        short   []int32 // Largest set of voters that does not.
+    }{
+        {"majority", quorum.Majority(members), []int32{0, 1, 2, 3}, []int32{0, 1, 2, 2, 2}},
+        {"byzantine", quorum.Byzantine(members), []int32{0, 1, 2, 3, 4}, []int32{0, 1, 2, 3, 9}},
+        {"count", quorum.Count(2)(members), []int32{5, 6}, []int32{6, 6}},
+        {"more than half the stake", quorum.MoreThan(map[int32]int{0: 5, 1: 3, 2: 2}, 1, 2)([]int32{0, 1, 2}), []int32{0, 2}, []int32{0}},
+        {"two thirds of the stake", quorum.AtLeast(map[int32]int{0: 2, 1: 2, 2: 2}, 2, 3)([]int32{0, 1, 2}), []int32{0, 1}, []int32{2}},
+        {"grid", quorum.Grid(3)(members), []int32{0, 1, 2, 3, 6}, []int32{0, 1, 2, 3, 4, 5}},
+        {"grid row", quorum.GridRow(3)(members), []int32{6}, []int32{0, 1, 3, 4}},
+        {"grid column", quorum.GridColumn(3)(members), []int32{2, 4, 6}, []int32{0, 1, 2, 3, 4, 5}},
+    }
+    for _, c := range cases {
+        if !c.q.Reached(c.reached) {
+            t.Errorf("%s: Expected %v to reach the quorum", c.name, c.reached)
+        }
+        if c.q.Reached(c.short) {
+            t.Errorf("%s: Expected %v to fall short of the quorum", c.name, c.short)
+        }
+    }
+}
+
+func TestQuorumIntersection(t *testing.T) {
+    members := []int32{0, 1, 2, 3, 4}
+    if err := quorum.Check(members, quorum.Majority, quorum.Majority); err != nil {
+        t.Errorf("Expected majorities to intersect, got %v", err)
+    }
+    if err := quorum.Check(members, quorum.Count(4), quorum.Count(2)); err != nil {
+        t.Errorf("Expected quorums of 4 and 2 of 5 to intersect, got %v", err)
+    }
+    if err := quorum.Check(members, quorum.Count(3), quorum.Count(2)); !errors.Is(err, quorum.ErrDisjoint) {
+        t.Errorf("Expected quorums of 3 and 2 of 5 to be disjoint, got %v", err)
+    }
+    grid := []int32{0, 1, 2, 3, 4, 5, 6, 7, 8}
+    if err := quorum.Check(grid, quorum.GridRow(3), quorum.GridColumn(3)); err != nil {
+        t.Errorf("Expected a grid row to intersect a member of every row, got %v", err)
+    }
+    if err := quorum.Check(grid, quorum.GridRow(3), quorum.GridRow(3)); !errors.Is(err, quorum.ErrDisjoint) {
+        t.Errorf("Expected two grid rows to be disjoint, got %v", err)
+    }
+}
+
+func TestRaftCommitsWithFlexibleQuorums(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 1})
+    peers := []int32{0, 1, 2, 3, 4}
+    var replicas []*raft.Replica
+    for _, id := range peers {
+        r := raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: peers, Quorum: quorum.Count(2), ElectionQuorum: quorum.Count(4), Rand: s.NewRand(), Clock: s.Clock()})
+        replicas = append(replicas, r)
+        s.Add(r)
+    }
+    s.RunUntil(func() bool { return replicas[0].Leader() >= 0 }, time.Minute)
+    leader := replicas[0].Leader()
+    // Only the leader and one follower stay up: a majority is lost, but a commit quorum of two is not.
+    follower := (leader + 1) % 5
+    for _, id := range peers {
+        if id != leader && id != follower {
+            for _, other := range peers {
+                s.Network.Disconnect(id, other)
+            }
+        }
+    }
+    s.Propose(leader, "tx")
+    s.RunUntil(func() bool { return len(replicas[leader].Committed()) > 1 }, time.Minute)
+    if len(replicas[leader].Committed()) < 2 {
+        t.Errorf("Expected the leader to commit with a copy on 2 of 5 replicas, got %d blocks", len(replicas[leader].Committed()))
+    }
+}
+
+func TestPBFTNetworkTakesAQuorumOption(t *testing.T) {
+    lenient := pbft.NewPBFTNetwork(options.WithNodes(7), options.WithFaulty(3), options.WithQuorum(quorum.Count(4)))
+    lenient.RunPBFT("Accepted by four")
+    if len(lenient.Blocks) != 2 {
+        t.Errorf("Expected 4 correct nodes of 7 to reach a quorum of 4, got %d blocks", len(lenient.Blocks))
+    }
+    if lenient.Quorum() != 4 {
+        t.Errorf("Expected a quorum of 4, got %d", lenient.Quorum())
+    }
+}
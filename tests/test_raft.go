@@ -1,12 +1,19 @@
 package tests
 
 import (
+    "errors"
+    "strings"
     "testing"
+    "time"
     "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/testutil"
+    "consensus-algorithms-edu/wire"
 )
 
 func TestRaft(t *testing.T) {
-    blockchain := raft.NewRaftNetwork(5)
+    blockchain := raft.NewRaftNetwork(options.WithNodes(5))
 
     blockchain.Leader.Lead("Test block 1")
     blockchain.Leader.Lead("Test block 2")
@@ -15,8 +22,258 @@ func TestRaft(t *testing.T) {
         t.Errorf("Expected 3 blocks, got %d", len(blockchain.Blocks))
     }
 
-    lastBlock := blockchain.Blocks[len(blockchain.Blocks)-1]
+    lastBlock := blockchain.Head()
     if lastBlock.Data != "Test block 2" {
         t.Errorf("Expected 'Test block 2', got '%s'", lastBlock.Data)
     }
 }
+
+func TestRaftBlockQueries(t *testing.T) {
+    blockchain := raft.NewRaftNetwork(options.WithNodes(3))
+    for _, data := range []string{"Test block 1", "Test block 2", "Test block 3"} {
+        blockchain.Leader.Lead(data)
+    }
+
+    if blockchain.Height() != 3 || blockchain.Head().Data != "Test block 3" {
+        t.Errorf("Expected height 3 with 'Test block 3' at the head, got %d and '%s'", blockchain.Height(), blockchain.Head().Data)
+    }
+    second, ok := blockchain.GetBlockByIndex(2)
+    if !ok || second.Data != "Test block 2" {
+        t.Errorf("Expected 'Test block 2' at index 2, got '%s'", second.Data)
+    }
+    if _, ok := blockchain.GetBlockByIndex(4); ok {
+        t.Errorf("Expected no block past the head")
+    }
+    if byHash, ok := blockchain.GetBlockByHash(second.Hash); !ok || byHash.Index != 2 {
+        t.Errorf("Expected to find block 2 by its hash, got %+v", byHash)
+    }
+    if _, ok := blockchain.GetBlockByHash(wire.Hash{1}); ok {
+        t.Errorf("Expected no block with an unknown hash")
+    }
+
+    var data []string
+    for i, block := range blockchain.Range(1, 2) {
+        if block.Index != i {
+            t.Errorf("Expected the iterator to yield block %d at index %d", block.Index, i)
+        }
+        data = append(data, block.Data)
+    }
+    if len(data) != 2 || data[0] != "Test block 1" || data[1] != "Test block 2" {
+        t.Errorf("Expected blocks 1 and 2, got %v", data)
+    }
+    count := 0
+    for range blockchain.All() {
+        if count++; count == 2 {
+            break
+        }
+    }
+    if count != 2 {
+        t.Errorf("Expected the iteration to stop when the loop breaks, got %d blocks", count)
+    }
+}
+
+func TestRaftDumpAndDiff(t *testing.T) {
+    mock := testutil.NewClock()
+    a, b := raft.NewRaftNetwork(options.WithNodes(3)), raft.NewRaftNetwork(options.WithNodes(3))
+    b.Blocks[0] = a.Blocks[0]
+    a.AttachClock(mock)
+    b.AttachClock(mock)
+    if d := raft.Diff(a, b); !d.Same() {
+        t.Fatalf("Expected identical chains, got %s", d)
+    }
+
+    a.Leader.Lead("Test block 1")
+    if d := raft.Diff(a, b); d.Height != 1 || d.Conflicting() || d.B != nil {
+        t.Errorf("Expected b to end where a continues at height 1, got %s", d)
+    }
+    b.Leader.Lead("Another block 1")
+    d := raft.Diff(a, b)
+    if !d.Conflicting() || d.A.GetData() != "Test block 1" || d.B.GetData() != "Another block 1" {
+        t.Errorf("Expected the chains to diverge at height 1, got %s", d)
+    }
+
+    dump := strings.Split(strings.TrimSuffix(a.Dump(), "\n"), "\n")
+    if len(dump) != 2 || !strings.HasPrefix(dump[1], "#1 ") || !strings.HasSuffix(dump[1], `"Test block 1"`) {
+        t.Errorf("Expected one line per block, got %q", dump)
+    }
+    if s := a.String(); s != "raft chain of 2 blocks, head #1 "+a.Head().Hash.String()[:16] {
+        t.Errorf("Expected a one-line summary, got %q", s)
+    }
+}
+
+func TestRaftCloneForksTheScenario(t *testing.T) {
+    blockchain := raft.NewRaftNetwork(options.WithNodes(3))
+    blockchain.Leader.Lead("Test block 1")
+
+    follower := blockchain.Nodes[2].Clone() // What happens if the leader crashes now?
+    fork := follower.Blockchain
+    crashed := fork.Leader
+    crashed.Crash()
+    if _, err := crashed.Lead("Lost block"); err == nil {
+        t.Errorf("Expected a crashed leader to commit nothing")
+    }
+    if !follower.RequestVote() {
+        t.Fatalf("Expected the follower to win an election in the fork")
+    }
+    fork.Leader.Lead("Fork block")
+
+    if _, err := blockchain.Leader.Lead("Test block 2"); err != nil {
+        t.Errorf("Expected the original leader to be unaffected by the fork, got %v", err)
+    }
+    if d := raft.Diff(blockchain, fork); !d.Conflicting() || d.Height != 2 {
+        t.Errorf("Expected the fork to diverge from the original at height 2, got %s", d)
+    }
+    if blockchain.Leader.ID != 0 || fork.Leader.ID != follower.ID {
+        t.Errorf("Expected leaders 0 and %d, got %d and %d", follower.ID, blockchain.Leader.ID, fork.Leader.ID)
+    }
+}
+
+// witnessCluster runs two Raft replicas, 0 and 1, and a witness, 2, until the witness follows a leader, and
+// commits one entry while the replica that does not lead is down: the leader and the witness are a majority.
+// Messages take a few milliseconds, so some are always in flight.
+func witnessCluster(t *testing.T) (s *sim.Simulator, replicas []*raft.Replica, leader, other int32) {
+    s = sim.New(sim.Config{Seed: 2, Network: sim.Link{Latency: sim.Uniform(2*time.Millisecond, 10*time.Millisecond)}})
+    peers := []int32{0, 1, 2}
+    for _, id := range peers {
+        r := raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: peers, Witnesses: []int32{2}, Rand: s.NewRand(), Clock: s.Clock()})
+        replicas = append(replicas, r)
+        s.Add(r)
+    }
+    witness := replicas[2]
+    if !s.RunUntil(func() bool { return witness.Leader() >= 0 }, 10*time.Second) {
+        t.Fatalf("Expected a leader to be elected")
+    }
+    leader = witness.Leader()
+    if leader == 2 || witness.Role() != raft.Follower {
+        t.Fatalf("Expected the witness to follow a replica, got leader %d", leader)
+    }
+    other = 1 - leader
+    s.Crash(other)
+    s.Propose(leader, "Only on the leader")
+    if !s.RunUntil(func() bool { return len(replicas[leader].Committed()) == 2 }, s.Now()+time.Second) {
+        t.Fatalf("Expected the leader and the witness to commit without the other replica")
+    }
+    return s, replicas, leader, other
+}
+
+func TestRaftWitnessVotesAndAcknowledgesWithoutBlocks(t *testing.T) {
+    _, replicas, leader, _ := witnessCluster(t)
+    witness := replicas[2]
+    if !witness.Witness() || replicas[leader].Witness() {
+        t.Errorf("Expected only replica 2 to be a witness")
+    }
+    if witness.LogLength() != replicas[leader].LogLength() {
+        t.Errorf("Expected the witness to track the leader's %d entries, got %d", replicas[leader].LogLength(), witness.LogLength())
+    }
+    if n := len(witness.Committed()); n != 1 {
+        t.Errorf("Expected the witness to hold no block but the genesis block, got %d", n)
+    }
+    if _, err := witness.Propose("To the witness"); !errors.Is(err, raft.ErrNotLeader) {
+        t.Errorf("Expected the witness to refuse proposals, got %v", err)
+    }
+}
+
+func TestRaftWitnessRefusesVoteToReplicaMissingCommittedEntry(t *testing.T) {
+    s, replicas, leader, other := witnessCluster(t)
+    // The only copy of the committed entry goes down; the other replica lacks it and must not lead. What the
+    // leader sent before crashing is lost with it, not delivered once the other replica is back.
+    s.Network.Disconnect(leader, other)
+    s.Crash(leader)
+    s.Recover(other)
+    s.RunFor(3 * time.Second)
+    if replicas[other].LogLength() >= replicas[leader].LogLength() {
+        t.Fatalf("Expected the recovered replica to lack the committed entry")
+    }
+    if replicas[other].Role() == raft.Leader {
+        t.Fatalf("Expected the witness to refuse its vote to a replica missing a committed entry")
+    }
+    s.Network.Connect(leader, other)
+    s.Recover(leader)
+    caughtUp := func() bool { return len(replicas[other].Committed()) == 2 }
+    if !s.RunUntil(caughtUp, s.Now()+5*time.Second) {
+        t.Fatalf("Expected the cluster to recover once the replica holding the entry returns")
+    }
+    if data := replicas[other].Committed()[1].GetData(); data != "Only on the leader" {
+        t.Errorf("Expected the committed entry to survive, got %q", data)
+    }
+}
+
+func TestRaftLeaseReadServesLocallyUntilLeaseExpires(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 3, Network: sim.Link{Latency: sim.Constant(5 * time.Millisecond)}})
+    peers := []int32{0, 1, 2}
+    var replicas []*raft.Replica
+    for _, id := range peers {
+        r := raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: peers, LeaseDuration: 500 * time.Millisecond, MaxDrift: 0.05, Rand: s.NewRand(), Clock: s.Clock()})
+        replicas = append(replicas, r)
+        s.Add(r)
+    }
+    if !s.RunUntil(func() bool { return replicas[0].Leader() >= 0 }, 10*time.Second) {
+        t.Fatalf("Expected a leader to be elected")
+    }
+    leader := replicas[replicas[0].Leader()]
+    if _, err := leader.LeaseRead(); !errors.Is(err, raft.ErrNoLease) {
+        t.Errorf("Expected no lease read before the leader commits in its term, got %v", err)
+    }
+    s.Propose(leader.ID(), "x = 1")
+    s.RunFor(100 * time.Millisecond)
+    blocks, err := leader.LeaseRead()
+    if err != nil || blocks[len(blocks)-1].GetData() != "x = 1" {
+        t.Fatalf("Expected a lease read of x = 1, got %v", err)
+    }
+    follower := replicas[(leader.ID()+1)%3]
+    if _, err := follower.LeaseRead(); !errors.Is(err, raft.ErrNotLeader) {
+        t.Errorf("Expected a follower to refuse lease reads, got %v", err)
+    }
+
+    // Cut the leader off: its lease must run out before the others can elect a new leader.
+    s.Network.Partition([]int32{leader.ID()}, []int32{(leader.ID() + 1) % 3, (leader.ID() + 2) % 3})
+    lease := leader.Lease()
+    expired := func() bool { _, err := leader.LeaseRead(); return err != nil }
+    if !s.RunUntil(expired, s.Now()+time.Second) {
+        t.Fatalf("Expected the isolated leader's lease to expire")
+    }
+    if now := s.Clock().Now(); now.Before(lease) {
+        t.Errorf("Expected the lease to hold until %v, it ended at %v", lease, now)
+    }
+    for _, r := range replicas {
+        if r != leader && r.Role() == raft.Leader {
+            t.Fatalf("Expected no new leader while the old one's lease held, got %d", r.ID())
+        }
+    }
+    elected := func() bool { return follower.Leader() >= 0 && follower.Leader() != leader.ID() }
+    if !s.RunUntil(elected, s.Now()+5*time.Second) {
+        t.Errorf("Expected the majority to elect a new leader once the promises ran out")
+    }
+}
+
+func TestRaftLeaseIgnoresDuplicatedAnswers(t *testing.T) {
+    const latency = 100 * time.Millisecond
+    s := sim.New(sim.Config{Seed: 3, Network: sim.Link{Latency: sim.Constant(latency)}})
+    peers := []int32{0, 1, 2}
+    var replicas []*raft.Replica
+    for _, id := range peers {
+        r := raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: peers, ElectionTicks: 50, HeartbeatTicks: 1, LeaseDuration: 2 * time.Second, Rand: s.NewRand(), Clock: s.Clock()})
+        replicas = append(replicas, r)
+        s.Add(r)
+    }
+    if !s.RunUntil(func() bool { return replicas[0].Leader() >= 0 }, 10*time.Second) {
+        t.Fatalf("Expected a leader to be elected")
+    }
+    leader := replicas[replicas[0].Leader()]
+    s.Propose(leader.ID(), "x = 1")
+
+    // A heartbeat goes out every tick and takes 100ms, so several are in flight to each follower; every answer
+    // arrives twice, and the second must not be taken for the answer to one of them.
+    s.Network.Inject(sim.Fault{Kind: "AppendEntriesResponse", Duplicate: 1})
+    s.RunFor(time.Second)
+    if _, err := leader.LeaseRead(); err != nil {
+        t.Fatalf("Expected the leader to hold a lease, got %v", err)
+    }
+    // Cut the leader off: the followers last heard heartbeats sent at least 100ms before, so their promises end
+    // 2s after that, and the lease must not outlast them.
+    cut := s.Clock().Now()
+    s.Network.Partition([]int32{leader.ID()}, []int32{(leader.ID() + 1) % 3, (leader.ID() + 2) % 3})
+    if promised, lease := cut.Add(2*time.Second-latency), leader.Lease(); lease.After(promised) {
+        t.Errorf("Expected the lease to end by %v, when the followers' promises do, got %v", promised, lease)
+    }
+}
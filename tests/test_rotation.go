@@ -0,0 +1,117 @@
+package tests
+
+import (
+    "math"
+    "slices"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/dpos"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/rotation"
+    "consensus-algorithms-edu/sim"
+)
+
+func TestRotationPolicies(t *testing.T) {
+    candidates := []int32{4, 2, 9}
+    rr := rotation.RoundRobin(candidates)
+    var turns []int32
+    for round := range uint64(4) {
+        turns = append(turns, rr.Leader(round))
+    }
+    if !slices.Equal(turns, []int32{4, 2, 9, 4}) {
+        t.Errorf("Expected round-robin turns 4, 2, 9, 4, got %v", turns)
+    }
+
+    sticky := rotation.Sticky(candidates)
+    sticky.Fail(5)
+    sticky.Fail(5) // Reported twice, handed over once.
+    if sticky.Leader(5) != 4 || sticky.Leader(6) != 2 || sticky.Leader(100) != 2 {
+        t.Errorf("Expected 4 to lead until it failed round 5 and 2 after, got %d, %d and %d", sticky.Leader(5), sticky.Leader(6), sticky.Leader(100))
+    }
+
+    if rotation.Named("dictator", nil) != nil {
+        t.Errorf("Expected no policy for an unknown name")
+    }
+    if rotation.RoundRobin(nil).Leader(0) != -1 || rotation.Sticky(nil).Leader(0) != -1 {
+        t.Errorf("Expected no leader without candidates")
+    }
+}
+
+func TestWeightedPoliciesFollowStake(t *testing.T) {
+    stakes := map[int32]int{0: 1, 1: 3, 2: 0}
+    candidates := []int32{0, 1, 2}
+    for _, name := range []string{rotation.NameStakeWeighted, rotation.NameVRF} {
+        policy := rotation.Named(name, stakes)(candidates)
+        led := make(map[int32]int)
+        for round := range uint64(4000) {
+            led[policy.Leader(round)]++
+        }
+        if led[2] != 0 {
+            t.Errorf("%s: Expected a candidate without stake never to lead, got %d rounds", name, led[2])
+        }
+        if share := float64(led[1]) / 4000; math.Abs(share-0.75) > 0.03 {
+            t.Errorf("%s: Expected the candidate with 3 of 4 stake to lead about 75%% of rounds, got %.1f%%", name, 100*share)
+        }
+    }
+}
+
+func TestStickyProducerHandsOverAfterCrashing(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 1})
+    peers := []int32{0, 1, 2, 3}
+    var delegates []*dpos.Delegate
+    for _, id := range peers {
+        d := dpos.NewDelegate(dpos.DelegateConfig{ID: id, Peers: peers, Rotation: rotation.Sticky, Clock: s.Clock()})
+        delegates = append(delegates, d)
+        s.Add(d)
+    }
+    s.RunFor(2 * time.Second)
+    if len(delegates[1].Chain()) < 19 {
+        t.Fatalf("Expected a block in nearly every one of 20 slots, got %d", len(delegates[1].Chain())-1)
+    }
+    for _, block := range delegates[1].Chain()[1:] {
+        if block.GetProducer() != node.Name(0) {
+            t.Fatalf("Expected delegate 0 to produce every block before it crashed, got one from %s", block.GetProducer())
+        }
+    }
+
+    for _, other := range peers[1:] {
+        s.Network.Disconnect(0, other)
+    }
+    before := len(delegates[1].Chain())
+    s.RunFor(2 * time.Second)
+    chain := delegates[1].Chain()
+    if produced := len(chain) - before; produced < 18 {
+        t.Errorf("Expected at most two empty slots in 20 after the crash, got %d blocks", produced)
+    }
+    for _, block := range chain[before:] {
+        if block.GetProducer() != node.Name(1) {
+            t.Errorf("Expected delegate 1 to take over and keep producing, got a block from %s", block.GetProducer())
+            break
+        }
+    }
+}
+
+func TestPBFTPrimaryFollowsRotation(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 1})
+    peers := []int32{0, 1, 2, 3}
+    policy := func([]int32) rotation.Policy { return rotation.RoundRobin([]int32{2, 0, 1, 3}) }
+    var replicas []*pbft.Replica
+    for _, id := range peers {
+        r := pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: peers, Rotation: policy, Clock: s.Clock()})
+        replicas = append(replicas, r)
+        s.Add(r)
+    }
+    if replicas[0].Leader() != 2 {
+        t.Fatalf("Expected the policy's first candidate 2 to be primary of view 0, got %d", replicas[0].Leader())
+    }
+    for _, other := range peers {
+        s.Network.Disconnect(2, other)
+    }
+    s.Propose(0, "tx")
+    s.Propose(1, "tx")
+    s.RunUntil(func() bool { return len(replicas[1].Committed()) > 1 }, time.Minute)
+    if replicas[1].View() != 1 || replicas[1].Leader() != 0 {
+        t.Errorf("Expected view 1 with primary 0 after 2 failed, got view %d with primary %d", replicas[1].View(), replicas[1].Leader())
+    }
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+    "fmt"
+    "math/rand"
+    "testing"
+    "testing/quick"
+    "time"
+    "consensus-algorithms-edu/algorithms/paxos"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/testutil"
+    "consensus-algorithms-edu/wire"
+)
+
+// safetyConfig checks a property against 25 random inputs. Each test gets a generator of its own with a fixed
+// seed, so a failure repeats on the next run whichever tests run with it, and the failing input is printed.
+func safetyConfig() *quick.Config {
+    return &quick.Config{MaxCount: 25, Rand: rand.New(rand.NewSource(1))}
+}
+
+// safetyLink is the network the schedules run on: uneven enough that messages overtake each other.
+var safetyLink = sim.Link{Latency: sim.Uniform(time.Millisecond, 20*time.Millisecond)}
+
+func TestSafetyRaftUnderRandomFaults(t *testing.T) {
+    property := func(s testutil.Schedule) bool {
+        c := testutil.Raft(safetyLink, options.WithNodes(5), options.WithSeed(s.Seed))
+        for _, violation := range testutil.Play(c, s) {
+            t.Log(violation)
+            return false
+        }
+        return true
+    }
+    if err := quick.Check(property, safetyConfig()); err != nil {
+        t.Error(err)
+    }
+}
+
+func TestSafetyPBFTUnderRandomFaults(t *testing.T) {
+    property := func(s testutil.Schedule) bool {
+        c := testutil.PBFT(safetyLink, options.WithNodes(4), options.WithSeed(s.Seed))
+        for _, violation := range testutil.Play(c, s) {
+            t.Log(violation)
+            return false
+        }
+        return true
+    }
+    if err := quick.Check(property, safetyConfig()); err != nil {
+        t.Error(err)
+    }
+}
+
+// TestSafetyPaxosProposalsOnlyMoveForward checks the shared Paxos network against random proposal numbers and
+// crashed acceptors: a proposal is committed only if a majority of acceptors is alive and it is newer than
+// every proposal committed before it, and the chain stays valid throughout.
+func TestSafetyPaxosProposalsOnlyMoveForward(t *testing.T) {
+    property := func(faulty uint8, numbers []uint8) bool {
+        const nodes = 5
+        crashed := int(faulty) % (nodes + 1)
+        chain := paxos.NewPaxosNetwork(options.WithNodes(nodes), options.WithFaulty(crashed))
+        highest := -1
+        for i, n := range numbers {
+            before := len(chain.Blocks)
+            chain.RunPaxos(fmt.Sprintf("Proposal %d", i), int(n))
+            if len(chain.Blocks) == before {
+                continue
+            }
+            if crashed > nodes/2 || int(n) <= highest {
+                t.Logf("proposal %d committed with %d of %d acceptors crashed after proposal %d", n, crashed, nodes, highest)
+                return false
+            }
+            highest = int(n)
+        }
+        blocks := make([]*wire.Block, len(chain.Blocks))
+        for i := range chain.Blocks {
+            blocks[i] = chain.Blocks[i].ToWire()
+        }
+        if err := engine.Validate("paxos", blocks, nil); err != nil {
+            t.Log(err)
+            return false
+        }
+        return true
+    }
+    if err := quick.Check(property, safetyConfig()); err != nil {
+        t.Error(err)
+    }
+}
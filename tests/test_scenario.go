@@ -0,0 +1,186 @@
+package tests
+
+import (
+    "context"
+    "errors"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/compare"
+    "consensus-algorithms-edu/scenario"
+    "consensus-algorithms-edu/testutil"
+)
+
+func TestScenarioParsesTimeline(t *testing.T) {
+    sc, err := scenario.Parse([]byte(`
+algorithm: raft
+nodes: 5
+duration: 4s
+workload:
+  every: 1s
+timeline:
+  - at t=2s partition {0,1} from {2,3,4}
+  - at 1500ms crash 4
+  - at 3s lossy 0.5
+  - at 3s heal
+`))
+    if err != nil {
+        t.Fatalf("Failed to parse the scenario: %v", err)
+    }
+    script, err := sc.Script()
+    if err != nil {
+        t.Fatalf("Failed to build the script: %v", err)
+    }
+    var lines []string
+    for _, event := range script {
+        lines = append(lines, event.String())
+    }
+    want := []string{"1s submit Transaction 1", "1.5s isolate 4", "2s partition 0,1 | 2,3,4", "2s submit Transaction 2", "3s lossy 0.5", "3s heal", "3s submit Transaction 3"}
+    if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+        t.Errorf("Expected the events\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(lines, "\n"))
+    }
+}
+
+func TestScenarioRejectsMistakes(t *testing.T) {
+    for _, text := range []string{
+        "algorithm: paxos",                                     // No message-driven replica.
+        "algorithm: raft\nnode: 3",                             // Misspelt field.
+        "algorithm: raft\nnodes: 3\ntimeline: [at 1s crash 3]", // Replica outside the cluster.
+        "algorithm: raft\ntimeline: [1s heal]",                 // No "at".
+        "algorithm: raft\ntimeline: [at 1s reboot 2]",          // Unknown event.
+        "algorithm: raft\nnetwork: {latency: gamma 5ms}",       // Unknown distribution.
+        "algorithm: raft\nexpect: [all nodes elect]",           // Unknown expectation.
+        "algorithm: raft\nexpect: [node 7 agree]",              // Replica outside the cluster.
+        "algorithm: raft\nexpect: [confirmed 10]",              // No relation.
+        "algorithm: raft\nexpect: [node 1 commits >= 2]",       // No "blocks".
+        "algorithm: raft\nexpect: [all nodes agree today]",     // Trailing words.
+    } {
+        if _, err := scenario.Parse([]byte(text)); err == nil {
+            t.Errorf("Expected an error for %q", text)
+        }
+    }
+    if _, err := scenario.Parse([]byte("algorithm: paxos")); !errors.Is(err, scenario.ErrUnknownAlgorithm) {
+        t.Errorf("Expected ErrUnknownAlgorithm, got %v", err)
+    }
+}
+
+func TestScenarioMinorityCommitsNothing(t *testing.T) {
+    sc := &scenario.Scenario{
+        Algorithm: "raft",
+        Nodes:     5,
+        Seed:      1,
+        Duration:  15 * time.Second,
+        Workload:  scenario.Workload{Every: 200 * time.Millisecond},
+        Timeline:  []string{"at 5s partition {0,1} from {2,3,4}", "at 10s heal"},
+    }
+    res, err := scenario.Run(context.Background(), sc)
+    if err != nil {
+        t.Fatalf("Failed to run the scenario: %v", err)
+    }
+    for _, c := range res.Commits {
+        if c.Node < 2 && c.At > 5*time.Second+time.Second && c.At < 10*time.Second {
+            t.Errorf("Expected the minority to commit nothing while cut off, got height %d on %d at %v", c.Height, c.Node, c.At)
+        }
+    }
+    if res.Confirmed() < res.Submitted()-5 {
+        t.Errorf("Expected nearly every transaction confirmed, got %d of %d", res.Confirmed(), res.Submitted())
+    }
+    testutil.AssertAgreement(t, res.Chains())
+    if res.Divergence() != 0 {
+        t.Errorf("Expected no diverged heights, got %d", res.Divergence())
+    }
+}
+
+func TestScenarioRunsLikeComparison(t *testing.T) {
+    sc := &scenario.Scenario{Algorithm: "pbft", Seed: 3, Duration: 5 * time.Second, Timeline: []string{"at 1s submit a", "at 2s submit b"}}
+    res, err := scenario.Run(context.Background(), sc)
+    if err != nil {
+        t.Fatalf("Failed to run the scenario: %v", err)
+    }
+    script, _ := sc.Script()
+    report, err := compare.Run(context.Background(), script, compare.Config{Algorithms: []string{"pbft"}, Nodes: 4, Seed: 3, Settle: 3 * time.Second})
+    if err != nil {
+        t.Fatalf("Failed to run the comparison: %v", err)
+    }
+    if res.Confirmed() != report.Results[0].Confirmed || res.Messages != report.Results[0].Messages {
+        t.Errorf("Expected the scenario to play as the comparison does, got %d confirmed and %+v against %d and %+v",
+            res.Confirmed(), res.Messages, report.Results[0].Confirmed, report.Results[0].Messages)
+    }
+}
+
+func TestScenarioExamplesRun(t *testing.T) {
+    paths, err := filepath.Glob("../examples/scenarios/*.yaml")
+    if err != nil || len(paths) == 0 {
+        t.Fatalf("Expected example scenarios, got %v (%v)", paths, err)
+    }
+    for _, path := range paths {
+        sc, err := scenario.Load(path)
+        if err != nil {
+            t.Errorf("Failed to load %s: %v", path, err)
+            continue
+        }
+        res, err := scenario.Run(context.Background(), sc)
+        if err != nil {
+            t.Errorf("Failed to run %s: %v", path, err)
+            continue
+        }
+        if len(res.Checks) == 0 {
+            t.Errorf("Expected %s to state its expectations", path)
+        }
+        for _, c := range res.Checks {
+            if !c.Passed {
+                t.Errorf("Expected %s to meet %q, got %s", path, c.Expectation, c.Detail)
+            }
+        }
+    }
+}
+
+func TestScenarioChecksExpectations(t *testing.T) {
+    sc := &scenario.Scenario{
+        Algorithm: "raft",
+        Nodes:     5,
+        Seed:      1,
+        Duration:  15 * time.Second,
+        Workload:  scenario.Workload{Every: 200 * time.Millisecond},
+        Timeline:  []string{"at 5s partition {0,1} from {2,3,4}", "at 10s heal"},
+        Expect: []string{
+            "nodes {0,1} commit nothing from t=6s to t=10s",
+            "nodes { 2, 3, 4 } commit ≥ 20 blocks from 5s to 10s",
+            "all nodes agree on height >= 50",
+            "all nodes follow the same leader",
+            "diverged heights == 0",
+            "all nodes commit nothing",
+            "node 0 reaches height < 10",
+            "confirmed > 1000",
+        },
+    }
+    res, err := scenario.Run(context.Background(), sc)
+    if err != nil {
+        t.Fatalf("Failed to run the scenario: %v", err)
+    }
+    if len(res.Checks) != len(sc.Expect) {
+        t.Fatalf("Expected %d checks, got %d", len(sc.Expect), len(res.Checks))
+    }
+    for i, c := range res.Checks {
+        if want := i < 5; c.Passed != want {
+            t.Errorf("Expected %q to pass: %v, got %v (%s)", c.Expectation, want, c.Passed, c.Detail)
+        }
+        if !c.Passed && c.Detail == "" {
+            t.Errorf("Expected %q to say why it failed", c.Expectation)
+        }
+    }
+    if !strings.HasPrefix(res.Checks[5].Detail, "node-0 committed height 1 at ") {
+        t.Errorf("Expected the first commit of node-0 as the reason, got %q", res.Checks[5].Detail)
+    }
+    if res.Failed() != 3 {
+        t.Errorf("Expected 3 failed expectations, got %d", res.Failed())
+    }
+    var out strings.Builder
+    if err := res.Print(&out); err != nil {
+        t.Fatalf("Failed to print the result: %v", err)
+    }
+    if !strings.Contains(out.String(), "  FAILED  confirmed > 1000: ") || !strings.HasSuffix(out.String(), "5 of 8 expectations met\n") {
+        t.Errorf("Expected the checks in the summary, got\n%s", out.String())
+    }
+}
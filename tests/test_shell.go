@@ -0,0 +1,93 @@
+package tests
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "testing"
+    "consensus-algorithms-edu/compare"
+    "consensus-algorithms-edu/shell"
+)
+
+func TestShellPartitionsAndShowsChains(t *testing.T) {
+    s := newTUICluster("raft", 5)
+    var out strings.Builder
+    sh := shell.New(s, "raft", &out)
+    if err := sh.Exec("run 1s"); err != nil {
+        t.Fatal(err)
+    }
+    // Keep the leader on the majority side, with two followers, and leave the other two on their own.
+    leader := compare.Target(s)
+    var minority, majority []string
+    for _, id := range s.Nodes() {
+        if id != leader && len(minority) < 2 {
+            minority = append(minority, fmt.Sprint(id))
+        } else {
+            majority = append(majority, fmt.Sprint(id))
+        }
+    }
+    partition := "partition " + strings.Join(minority, ",") + " / " + strings.Join(majority, ",")
+    for _, line := range []string{partition, "propose pay bob 5", "step 2", "run 2s", "show network"} {
+        if err := sh.Exec(line); err != nil {
+            t.Fatalf("Expected %q to succeed, got %v", line, err)
+        }
+    }
+    cut := fmt.Sprintf("node-%s cannot reach %s", minority[0], strings.Join(majority, ","))
+    for _, want := range []string{"partitioned into 2 groups", `proposed "pay bob 5"`, "deliver", cut} {
+        if !strings.Contains(out.String(), want) {
+            t.Errorf("Expected the output to contain %q, got\n%s", want, out.String())
+        }
+    }
+
+    // The majority side holds the block; the minority side does not.
+    out.Reset()
+    sh.Exec("show chain " + majority[0])
+    if !strings.Contains(out.String(), "pay bob 5") {
+        t.Errorf("Expected the majority to have committed the block, got\n%s", out.String())
+    }
+    out.Reset()
+    sh.Exec("show chain " + minority[0])
+    if strings.Contains(out.String(), "pay bob 5") {
+        t.Errorf("Expected the minority not to have committed the block, got\n%s", out.String())
+    }
+
+    out.Reset()
+    sh.Exec("heal")
+    sh.Exec("run 2s")
+    sh.Exec("show chain " + minority[0])
+    if !strings.Contains(out.String(), "pay bob 5") {
+        t.Errorf("Expected the minority to catch up after heal, got\n%s", out.String())
+    }
+}
+
+func TestShellRejectsBadCommands(t *testing.T) {
+    sh := shell.New(newTUICluster("pbft", 4), "pbft", &strings.Builder{})
+    for _, line := range []string{"bogus", "crash 7", "crash", "partition 0,1", "lossy 2", "step 0", "run soon", "show chain x", "show everything"} {
+        if err := sh.Exec(line); err == nil {
+            t.Errorf("Expected %q to be rejected", line)
+        }
+    }
+    for _, line := range []string{"", "# a comment", "help"} {
+        if err := sh.Exec(line); err != nil {
+            t.Errorf("Expected %q to succeed, got %v", line, err)
+        }
+    }
+}
+
+func TestShellRunReadsUntilQuit(t *testing.T) {
+    var out strings.Builder
+    sh := shell.New(newTUICluster("raft", 3), "raft", &out)
+    err := sh.Run(context.Background(), strings.NewReader("run 1s\nbogus\nshow\nquit\nrun 1s\n"))
+    if err != nil {
+        t.Fatalf("Expected the session to end cleanly, got %v", err)
+    }
+    text := out.String()
+    for _, want := range []string{"raft with 3 nodes", "[1s] raft> ", `unknown command "bogus"`, "leader"} {
+        if !strings.Contains(text, want) {
+            t.Errorf("Expected the session to show %q, got\n%s", want, text)
+        }
+    }
+    if strings.Contains(text, "ran to 2s") {
+        t.Errorf("Expected the session to stop at quit, got\n%s", text)
+    }
+}
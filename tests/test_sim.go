@@ -0,0 +1,259 @@
+package tests
+
+import (
+    "bytes"
+    "fmt"
+    "math/rand"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/testutil"
+    "consensus-algorithms-edu/wire"
+    "google.golang.org/protobuf/proto"
+)
+
+func TestSimRaftOnLossyNetwork(t *testing.T) {
+    c := testutil.Raft(sim.Link{Latency: sim.Uniform(time.Millisecond, 20*time.Millisecond), Drop: 0.1}, options.WithNodes(5), options.WithSeed(1))
+
+    leader, ok := c.WaitForLeader(10 * time.Second)
+    if !ok {
+        t.Fatalf("No leader elected")
+    }
+    for i := 1; i <= 3; i++ {
+        if err := c.Propose(leader.ID(), fmt.Sprintf("Test block %d", i)); err != nil {
+            t.Fatalf("Proposal failed: %v", err)
+        }
+    }
+    if !c.RunUntil(c.AllCommitted(4), c.Now()+10*time.Second) {
+        t.Fatalf("Blocks were not committed on every replica")
+    }
+    testutil.AssertAgreement(t, c.Chains())
+    if stats := c.Stats(); stats.Dropped == 0 || stats.Delivered == 0 {
+        t.Errorf("Expected both delivered and dropped messages, got %+v", stats)
+    }
+}
+
+func TestSimIsDeterministic(t *testing.T) {
+    run := func() []string {
+        c := testutil.Raft(sim.Link{Latency: sim.Exponential(5 * time.Millisecond), Drop: 0.05}, options.WithNodes(3), options.WithSeed(42))
+        var trace []string
+        c.OnCommit = func(id int32, block *wire.Block) {
+            trace = append(trace, fmt.Sprintf("%v node %d committed %d %s", c.Now(), id, block.GetIndex(), block.GetData()))
+        }
+        leader, _ := c.WaitForLeader(10 * time.Second)
+        c.Propose(leader.ID(), "Test block 1")
+        c.RunFor(time.Second)
+        return trace
+    }
+
+    first, second := run(), run()
+    if len(first) == 0 || fmt.Sprint(first) != fmt.Sprint(second) {
+        t.Errorf("Expected identical runs, got %v and %v", first, second)
+    }
+}
+
+func TestSimDisconnectedLeaderIsReplaced(t *testing.T) {
+    c := testutil.Raft(sim.Link{Latency: sim.Constant(5 * time.Millisecond)}, options.WithNodes(3), options.WithSeed(7))
+    old, _ := c.WaitForLeader(10 * time.Second)
+
+    c.Isolate(old.ID())
+    replaced := func() bool {
+        leader, ok := c.Leader()
+        return ok && leader != old && leader.Term() > old.Term()
+    }
+    if !c.RunUntil(replaced, c.Now()+10*time.Second) {
+        t.Fatalf("The majority did not elect a new leader")
+    }
+    if err := c.Propose(old.ID(), "Stale block"); err != nil {
+        t.Fatalf("The isolated leader should still accept proposals: %v", err)
+    }
+    c.RunFor(time.Second)
+    if n := len(old.Committed()); n != 1 {
+        t.Errorf("Expected the isolated leader to commit nothing, got %d blocks", n)
+    }
+}
+
+func TestSimPBFT(t *testing.T) {
+    c := testutil.PBFT(sim.Link{Latency: sim.Normal(10*time.Millisecond, 3*time.Millisecond)}, options.WithSeed(3))
+    c.Propose(1, "Test block 1") // Forwarded to the primary.
+    if !c.RunUntil(c.AllCommitted(2), 10*time.Second) {
+        t.Fatalf("The block was not committed on every replica")
+    }
+}
+
+func TestSimPBFTTimeoutBacksOffAndDecays(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 2, Network: sim.Link{Latency: sim.Constant(40 * time.Millisecond)}})
+    peers := []int32{0, 1, 2, 3}
+    var replicas []*pbft.Replica
+    for _, id := range peers {
+        r := pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: peers, ViewChangeTicks: 5, Backoff: 2, Clock: s.Clock()})
+        replicas = append(replicas, r)
+        s.Add(r)
+    }
+    propose := func(data string) {
+        for _, id := range peers {
+            s.Propose(id, data)
+        }
+    }
+    executed := func(n int) func() bool {
+        return func() bool { return len(replicas[0].Committed()) == n+1 }
+    }
+
+    // A request takes four 40ms hops, longer than the 50ms timeout: only a grown timeout lets a view execute it.
+    propose("slow")
+    if !s.RunUntil(executed(1), 10*time.Second) {
+        t.Fatalf("The request was not executed, view %d, timeout %d ticks", replicas[0].View(), replicas[0].Timeout())
+    }
+    if replicas[0].View() == 0 || replicas[0].Timeout() <= 5 {
+        t.Errorf("Expected failed views and a grown timeout, got view %d and %d ticks", replicas[0].View(), replicas[0].Timeout())
+    }
+
+    s.Network.Default = sim.Link{Latency: sim.Constant(time.Millisecond)}
+    for i := range 40 {
+        propose(fmt.Sprintf("fast %d", i))
+        if !s.RunUntil(executed(i+2), s.Now()+time.Second) {
+            t.Fatalf("Request %d was not executed on the fast network", i)
+        }
+    }
+    for _, r := range replicas {
+        if r.Timeout() != 5 {
+            t.Errorf("Expected replica %d's timeout to decay back to 5 ticks, got %d", r.ID(), r.Timeout())
+        }
+    }
+}
+
+func TestSimPBFTTimeoutAdaptsFromTheMinimum(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 2, Network: sim.Link{Latency: sim.Constant(40 * time.Millisecond)}})
+    peers := []int32{0, 1, 2, 3}
+    var replicas []*pbft.Replica
+    for _, id := range peers {
+        r := pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: peers, ViewChangeTicks: 2, Backoff: 1.2, Clock: s.Clock()})
+        replicas = append(replicas, r)
+        s.Add(r)
+    }
+    propose := func(data string) {
+        for _, id := range peers {
+            s.Propose(id, data)
+        }
+    }
+    executed := func(n int) func() bool {
+        return func() bool { return len(replicas[0].Committed()) == n+1 }
+    }
+
+    // 2.4 ticks rounds back to 2: the timeout only grows past the 160ms a request takes if backoff rounds up.
+    propose("slow")
+    if !s.RunUntil(executed(1), 10*time.Second) {
+        t.Fatalf("The request was not executed, view %d, timeout %d ticks", replicas[0].View(), replicas[0].Timeout())
+    }
+
+    // 2.7 ticks rounds back to 3: the timeout only returns to 2 if decay rounds down.
+    s.Network.Default = sim.Link{Latency: sim.Constant(time.Millisecond)}
+    for i := range 40 {
+        propose(fmt.Sprintf("fast %d", i))
+        if !s.RunUntil(executed(i+2), s.Now()+time.Second) {
+            t.Fatalf("Request %d was not executed on the fast network", i)
+        }
+    }
+    for _, r := range replicas {
+        if r.Timeout() != 2 {
+            t.Errorf("Expected replica %d's timeout to decay back to 2 ticks, got %d", r.ID(), r.Timeout())
+        }
+    }
+}
+
+func TestUniformLatencyStaysInBounds(t *testing.T) {
+    latency := sim.Uniform(2*time.Millisecond, 4*time.Millisecond)
+    rng := rand.New(rand.NewSource(1))
+    for i := 0; i < 1000; i++ {
+        if d := latency.Sample(rng); d < 2*time.Millisecond || d > 4*time.Millisecond {
+            t.Fatalf("Sample %v is out of bounds", d)
+        }
+    }
+}
+
+func TestSimSeedReplaysBitIdentical(t *testing.T) {
+    run := func(seed int64) []byte {
+        s := sim.New(sim.Config{Seed: seed, Network: sim.Link{Latency: sim.Exponential(5 * time.Millisecond), Drop: 0.05}})
+        s.Network.Inject(sim.Fault{Duplicate: 0.1, Reorder: 0.2})
+        peers := []int32{0, 1, 2, 3}
+        for _, id := range peers {
+            s.Add(pow.NewMiner(pow.MinerConfig{ID: id, Peers: peers, MineProbability: 0.01, Rand: s.NewRand(), Clock: s.Clock()}))
+        }
+        for _, id := range []int32{10, 11, 12} {
+            s.Add(raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: []int32{10, 11, 12}, Rand: s.NewRand(), Clock: s.Clock()}))
+        }
+        s.Add(pbft.NewReplica(pbft.ReplicaConfig{ID: 20, Peers: []int32{20}, Clock: s.Clock()}))
+        for i := 1; i <= 3; i++ {
+            s.At(time.Duration(i)*500*time.Millisecond, func() {
+                for _, id := range s.Nodes() {
+                    s.Propose(id, fmt.Sprintf("Test block %d", i))
+                }
+            })
+        }
+        s.RunFor(3 * time.Second)
+
+        var out []byte
+        for _, id := range s.Nodes() {
+            for _, block := range s.Replica(id).Committed() {
+                b, err := proto.MarshalOptions{Deterministic: true}.Marshal(block)
+                if err != nil {
+                    t.Fatalf("Marshal failed: %v", err)
+                }
+                out = append(out, b...)
+            }
+        }
+        return append(out, fmt.Sprintf("%+v", s.Stats())...)
+    }
+
+    first, second := run(11), run(11)
+    if !bytes.Equal(first, second) {
+        t.Errorf("Expected bit-identical runs for the same seed")
+    }
+    if bytes.Equal(first, run(12)) {
+        t.Errorf("Expected a different seed to produce a different run")
+    }
+}
+
+func TestStepperPausesAtEveryMessage(t *testing.T) {
+    c := testutil.Raft(sim.Link{Latency: sim.Uniform(time.Millisecond, 10*time.Millisecond)}, options.WithNodes(3), options.WithSeed(1))
+    stepper := sim.NewStepper(c.Simulator)
+    first, ok := stepper.Next(time.Minute)
+    if !ok || first.Input != sim.TickInput || len(first.Output) != 2 || first.Output[0].Kind() != "RequestVote" {
+        t.Fatalf("Expected to pause first at the timeout that starts an election, got %v", first)
+    }
+    last := first.At
+    for i := 0; i < 20; i++ {
+        step, ok := stepper.Next(time.Minute)
+        if !ok || !sim.Significant(step) || step.At < last {
+            t.Fatalf("Expected a significant transition after %v, got %v", last, step)
+        }
+        if c.Now() != step.At {
+            t.Errorf("Expected the simulation to pause at %v, got %v", step.At, c.Now())
+        }
+        last = step.At
+    }
+
+    leader, ok := c.Leader()
+    if !ok {
+        t.Fatalf("Expected a leader after the election")
+    }
+    c.Propose(leader.ID(), "Test block 1")
+    if step, _ := stepper.Next(time.Minute); step.Input != sim.ProposeInput || step.Data != "Test block 1" {
+        t.Errorf("Expected the proposal to be returned next, got %v", step)
+    }
+
+    stepper.Pause = func(sim.Transition) bool { return true }
+    quiet := 0
+    for i := 0; i < 50; i++ {
+        if step, _ := stepper.Next(time.Minute); step.Input == sim.TickInput && len(step.Output) == 0 {
+            quiet++
+        }
+    }
+    if quiet == 0 {
+        t.Errorf("Expected ticks that send nothing once every transition pauses")
+    }
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+    "testing"
+    "consensus-algorithms-edu/algorithms/dpos"
+    "consensus-algorithms-edu/algorithms/paxos"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/wire"
+)
+
+func TestSnapshotResumesEveryEngine(t *testing.T) {
+    ctx := context.Background()
+    for _, algorithm := range engine.Algorithms() {
+        e, _ := engine.New(algorithm, engine.Config{Nodes: 4})
+        for i := 1; i <= 2; i++ {
+            e.Submit(ctx, fmt.Sprintf("Test block %d", i))
+        }
+        var buf bytes.Buffer
+        if err := e.Export(&buf); err != nil {
+            t.Fatalf("%s: failed to export: %v", algorithm, err)
+        }
+
+        resumed, err := engine.Import(&buf, engine.Config{})
+        if err != nil {
+            t.Fatalf("%s: failed to import: %v", algorithm, err)
+        }
+        if resumed.Algorithm() != algorithm || resumed.Status() != e.Status() {
+            t.Errorf("%s: Expected status %+v, got %+v", algorithm, e.Status(), resumed.Status())
+        }
+        if fmt.Sprint(resumed.Participants()) != fmt.Sprint(e.Participants()) {
+            t.Errorf("%s: Expected participants %v, got %v", algorithm, e.Participants(), resumed.Participants())
+        }
+        if err := resumed.Submit(ctx, "Test block 3"); err != nil {
+            t.Errorf("%s: Expected the resumed run to accept a block, got %v", algorithm, err)
+        }
+        if err := engine.ValidateChain(resumed); err != nil {
+            t.Errorf("%s: Expected the resumed chain to be valid, got %v", algorithm, err)
+        }
+    }
+}
+
+func TestSnapshotKeepsAlgorithmState(t *testing.T) {
+    chain := dpos.NewBlockchain([]string{"Alice", "Bob", "Carol"}, map[string]string{})
+    chain.Vote("voter-1", "Alice")
+    chain.Vote("voter-2", "Carol")
+    chain.CountVotes()
+    var buf bytes.Buffer
+    if err := chain.Export(&buf); err != nil {
+        t.Fatalf("Failed to export: %v", err)
+    }
+    restored := dpos.NewBlockchain([]string{"Dave"}, map[string]string{})
+    if err := restored.Import(&buf); err != nil {
+        t.Fatalf("Failed to import: %v", err)
+    }
+    if fmt.Sprint(restored.Delegates) != fmt.Sprint(chain.Delegates) || restored.Voters["voter-2"] != "Carol" {
+        t.Errorf("Expected delegates %v and the votes cast, got %v and %v", chain.Delegates, restored.Delegates, restored.Voters)
+    }
+
+    // A crashed Raft follower and the current leader survive the round trip.
+    network := raft.NewRaftNetwork()
+    network.Nodes[3].Crash()
+    buf.Reset()
+    network.Export(&buf)
+    resumed := raft.NewBlockchain()
+    if err := resumed.Import(&buf); err != nil {
+        t.Fatalf("Failed to import the Raft network: %v", err)
+    }
+    if resumed.Leader == nil || resumed.Leader.ID != 0 || resumed.Leader.Blockchain != resumed {
+        t.Errorf("Expected node 0 to lead the imported network")
+    }
+    if _, err := resumed.Leader.Lead("Test block"); err != nil {
+        t.Errorf("Expected the imported leader to commit with three running nodes, got %v", err)
+    }
+    if resumed.Nodes[3].Status() != node.Crashed {
+        t.Errorf("Expected node 3 to still be crashed, got %v", resumed.Nodes[3].Status())
+    }
+}
+
+func TestSnapshotImportRejectsTampering(t *testing.T) {
+    network := paxos.NewPaxosNetwork()
+    network.RunPaxos("Test block", 1)
+    var buf bytes.Buffer
+    network.Export(&buf)
+    tampered := strings.Replace(buf.String(), "Test block", "Tampered", 1)
+
+    before := len(network.Blocks)
+    if err := network.Import(strings.NewReader(tampered)); err == nil || !strings.Contains(err.Error(), "invalid hash") {
+        t.Errorf("Expected a tampered block to be rejected, got %v", err)
+    }
+    if len(network.Blocks) != before {
+        t.Errorf("Expected the chain to be left unchanged, got %d blocks", len(network.Blocks))
+    }
+    if _, err := engine.Import(strings.NewReader("{"), engine.Config{}); !errors.Is(err, wire.ErrMalformed) {
+        t.Errorf("Expected ErrMalformed for a broken snapshot, got %v", err)
+    }
+    if err := network.Import(strings.NewReader(`{"chain": {"algorithm": "raft"}}`)); err == nil {
+        t.Errorf("Expected a snapshot of another algorithm to be rejected")
+    }
+}
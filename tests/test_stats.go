@@ -0,0 +1,59 @@
+package tests
+
+import (
+    "context"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/stats"
+)
+
+func TestStatsPercentiles(t *testing.T) {
+    recorder := stats.NewRecorder()
+    start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    for i := 1; i <= 100; i++ {
+        submitted := start.Add(time.Duration(i) * time.Second)
+        recorder.Record(submitted, submitted.Add(time.Duration(i)*time.Millisecond))
+    }
+
+    summary := recorder.Summary()
+    if summary.Blocks != 100 {
+        t.Errorf("Expected 100 blocks, got %d", summary.Blocks)
+    }
+    if want := 99*time.Second + 100*time.Millisecond; summary.Elapsed != want {
+        t.Errorf("Expected %v from the first submission to the last commit, got %v", want, summary.Elapsed)
+    }
+    latency := summary.Latency
+    if latency.Percentile(50) != 50*time.Millisecond || latency.Percentile(90) != 90*time.Millisecond || latency.Percentile(99) != 99*time.Millisecond {
+        t.Errorf("Expected p50, p90 and p99 of 50ms, 90ms and 99ms, got %v", latency)
+    }
+    if latency.Min != time.Millisecond || latency.Max != 100*time.Millisecond || latency.Mean != 50500*time.Microsecond {
+        t.Errorf("Expected min 1ms, mean 50.5ms and max 100ms, got %v", latency)
+    }
+
+    recorder.Reset()
+    if recorder.Summary().Blocks != 0 {
+        t.Errorf("Expected no blocks after a reset")
+    }
+    var none *stats.Recorder
+    none.Record(start, start) // A nil recorder ignores observations.
+}
+
+func TestStatsMeasureEngine(t *testing.T) {
+    recorder := stats.NewRecorder()
+    e, _ := engine.New("raft", engine.Config{Nodes: 4})
+    e = engine.Measure(e, recorder)
+    for i := 0; i < 10; i++ {
+        if err := e.Submit(context.Background(), "Test block"); err != nil {
+            t.Fatalf("Failed to submit: %v", err)
+        }
+    }
+
+    summary := recorder.Summary()
+    if summary.Blocks != 10 {
+        t.Errorf("Expected 10 measured blocks, got %d", summary.Blocks)
+    }
+    if summary.Latency.Max <= 0 || summary.Throughput <= 0 {
+        t.Errorf("Expected positive latencies and throughput, got %v", summary)
+    }
+}
@@ -0,0 +1,64 @@
+package tests
+
+import (
+    "testing"
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/storage"
+)
+
+func TestFileStoreRoundTrip(t *testing.T) {
+    store, err := storage.NewFileStore(t.TempDir())
+    if err != nil {
+        t.Fatalf("NewFileStore failed: %v", err)
+    }
+
+    validators := []string{"Alice", "Bob"}
+    stakes := map[string]int{"Alice": 70, "Bob": 30}
+
+    original := pos.NewBlockchain(validators, stakes)
+    original.AddBlock("Test block 1")
+    original.AddBlock("Test block 2")
+
+    if err := original.Save(store, "pos-chain"); err != nil {
+        t.Fatalf("Save failed: %v", err)
+    }
+
+    restored := pos.NewBlockchain(validators, stakes)
+    if err := restored.Load(store, "pos-chain"); err != nil {
+        t.Fatalf("Load failed: %v", err)
+    }
+
+    if len(restored.Blocks) != 3 {
+        t.Errorf("Expected 3 blocks, got %d", len(restored.Blocks))
+    }
+    for i := range original.Blocks {
+        if restored.Blocks[i] != original.Blocks[i] {
+            t.Errorf("Block %d differs after reload", i)
+        }
+    }
+}
+
+func TestFileStoreRejectsTamperedChain(t *testing.T) {
+    store, err := storage.NewFileStore(t.TempDir())
+    if err != nil {
+        t.Fatalf("NewFileStore failed: %v", err)
+    }
+
+    validators := []string{"Alice"}
+    stakes := map[string]int{"Alice": 100}
+
+    blockchain := pos.NewBlockchain(validators, stakes)
+    blockchain.AddBlock("Test block 1")
+    blockchain.Blocks[1].Data = "Tampered"
+
+    if err := blockchain.Save(store, "tampered"); err != nil {
+        t.Fatalf("Save failed: %v", err)
+    }
+    if err := pos.NewBlockchain(validators, stakes).Load(store, "tampered"); err == nil {
+        t.Errorf("Expected Load to reject a tampered chain")
+    }
+
+    if err := blockchain.Load(store, "missing"); err != storage.ErrNotFound {
+        t.Errorf("Expected ErrNotFound, got %v", err)
+    }
+}
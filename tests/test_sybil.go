@@ -0,0 +1,51 @@
+package tests
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/sybil"
+)
+
+func TestSybilIdentitiesOnlyPayOffWhereIdentitiesCount(t *testing.T) {
+    report, err := sybil.Run(context.Background(), sybil.Config{Identities: []int{1, 20}, Seed: 1, Duration: time.Minute})
+    if err != nil {
+        t.Fatalf("Run failed: %v", err)
+    }
+    if len(report.Results) != 2*len(sybil.Schemes()) {
+        t.Fatalf("Expected a result per scheme and number of identities, got %d", len(report.Results))
+    }
+    for _, scheme := range []sybil.Scheme{sybil.PoW, sybil.PoS} {
+        if !report.Resists(scheme) {
+            t.Errorf("Expected %s to resist splitting the same resources across identities", scheme)
+        }
+    }
+    for _, scheme := range []sybil.Scheme{sybil.Equal, sybil.PBFT} {
+        if report.Resists(scheme) {
+            t.Errorf("Expected %s to collapse once identities are counted", scheme)
+        }
+    }
+    for _, res := range report.Results {
+        switch {
+        case res.Scheme == sybil.Equal && res.Identities == 20 && res.Control < 0.5:
+            t.Errorf("Expected 20 equal identities to produce most blocks, got %.2f", res.Control)
+        case res.Scheme == sybil.PBFT && res.Identities == 1 && res.Control != 0:
+            t.Errorf("Expected PBFT to commit every block with a single silent identity, got control %.2f", res.Control)
+        case res.Scheme == sybil.PBFT && res.Identities == 20 && res.Blocks != 0:
+            t.Errorf("Expected PBFT to stall with 20 of 30 replicas silent, got %d blocks", res.Blocks)
+        }
+    }
+
+    var out strings.Builder
+    if err := report.Print(&out); err != nil || !strings.Contains(out.String(), "equal collapses") || !strings.Contains(out.String(), "pow resists") {
+        t.Errorf("Expected the report to give a verdict per scheme, got %v:\n%s", err, out.String())
+    }
+}
+
+func TestSybilRejectsUnknownScheme(t *testing.T) {
+    if _, err := sybil.Run(context.Background(), sybil.Config{Schemes: []sybil.Scheme{"raft"}}); !errors.Is(err, sybil.ErrUnknownScheme) {
+        t.Errorf("Expected ErrUnknownScheme, got %v", err)
+    }
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "testing"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/wire"
+    "google.golang.org/protobuf/proto"
+)
+
+// forgingPeer serves a peer's chain with one header altered.
+type forgingPeer struct {
+    engine.Peer
+    forged int64 // Index of the header to alter.
+}
+
+func (p forgingPeer) Headers(from int64, limit int) []wire.Header {
+    headers := p.Peer.Headers(from, limit)
+    for i := range headers {
+        if headers[i].Index == p.forged {
+            headers[i].Root = wire.BodyRoot("forged")
+        }
+    }
+    return headers
+}
+
+func TestFastSyncFromACertifiedCheckpoint(t *testing.T) {
+    e, _ := engine.New("pbft", engine.Config{Nodes: 4})
+    for i := 1; i <= 10; i++ {
+        if err := e.Submit(context.Background(), fmt.Sprintf("Test block %d", i)); err != nil {
+            t.Fatal(err)
+        }
+    }
+    checkpoint, err := engine.NewCheckpoint(e, 6)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    synced, err := engine.FastSync(checkpoint, e.Participants(), engine.PeerOf(e))
+    if err != nil {
+        t.Fatalf("Expected the sync to succeed, got %v", err)
+    }
+    if synced.Height() != 11 || len(synced.Headers) != 5 || synced.Head.GetHash() != e.Blocks()[10].GetHash() {
+        t.Errorf("Expected 5 headers up to block 10, got %d headers and height %d", len(synced.Headers), synced.Height())
+    }
+    if err := synced.VerifyBlock(e.Blocks()[8]); err != nil {
+        t.Errorf("Expected a block fetched later to match its header, got %v", err)
+    }
+    tampered := proto.Clone(e.Blocks()[8]).(*wire.Block)
+    tampered.Data = "Tampered"
+    if err := synced.VerifyBlock(tampered); !errors.Is(err, engine.ErrUnverified) {
+        t.Errorf("Expected a tampered block to fail verification, got %v", err)
+    }
+    if err := synced.VerifyBlock(e.Blocks()[2]); !errors.Is(err, engine.ErrUnverified) {
+        t.Errorf("Expected a block before the checkpoint to have no header, got %v", err)
+    }
+
+    // A checkpoint certified by too few of the known replicas, or by strangers, earns no trust.
+    weak := &engine.Checkpoint{Algorithm: "pbft", Block: proto.Clone(checkpoint.Block).(*wire.Block)}
+    weak.Block.Certificate = weak.Block.Certificate[:1]
+    if _, err := engine.FastSync(weak, e.Participants(), engine.PeerOf(e)); !errors.Is(err, engine.ErrUnverified) {
+        t.Errorf("Expected a checkpoint without a quorum to be refused, got %v", err)
+    }
+    strangers := []engine.Participant{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+    if _, err := engine.FastSync(checkpoint, strangers, engine.PeerOf(e)); !errors.Is(err, engine.ErrUnverified) {
+        t.Errorf("Expected a checkpoint certified by unknown replicas to be refused, got %v", err)
+    }
+    if _, err := engine.FastSync(checkpoint, e.Participants(), forgingPeer{engine.PeerOf(e), 8}); !errors.Is(err, engine.ErrUnverified) || !errors.Is(err, wire.ErrBrokenHeaders) {
+        t.Errorf("Expected a forged header to break the chain of headers, got %v", err)
+    }
+}
+
+func TestFastSyncChecksProofOfWork(t *testing.T) {
+    e, _ := engine.New("pow", engine.Config{})
+    for i := 1; i <= 4; i++ {
+        if err := e.Submit(context.Background(), fmt.Sprintf("Test block %d", i)); err != nil {
+            t.Fatal(err)
+        }
+    }
+    checkpoint, _ := engine.NewCheckpoint(e, 1)
+    if synced, err := engine.FastSync(checkpoint, nil, engine.PeerOf(e)); err != nil || synced.Height() != 5 {
+        t.Fatalf("Expected to sync to height 5, got %v", err)
+    }
+    // The last header has no successor to break the link to, but forging it undoes its work.
+    if _, err := engine.FastSync(checkpoint, nil, forgingPeer{engine.PeerOf(e), 4}); !errors.Is(err, engine.ErrUnverified) {
+        t.Errorf("Expected a forged header to fail, got %v", err)
+    }
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+    "bytes"
+    "context"
+    "encoding/binary"
+    "errors"
+    "fmt"
+    "net"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/transport"
+    "consensus-algorithms-edu/wire"
+)
+
+// freeAddrs reserves n loopback addresses by briefly listening on port 0.
+func freeAddrs(t *testing.T, n int) []string {
+    addrs := make([]string, n)
+    for i := range addrs {
+        listener, err := net.Listen("tcp", "127.0.0.1:0")
+        if err != nil {
+            t.Fatalf("Failed to reserve a port: %v", err)
+        }
+        addrs[i] = listener.Addr().String()
+        listener.Close()
+    }
+    return addrs
+}
+
+func TestFrameRoundTrip(t *testing.T) {
+    var buf bytes.Buffer
+    sent := &wire.Envelope{From: 1, To: 2, Body: &wire.Envelope_Request{Request: &wire.Request{Data: "Test data"}}}
+    if err := transport.WriteFrame(&buf, sent); err != nil {
+        t.Fatalf("Failed to write frame: %v", err)
+    }
+    if err := transport.WriteFrame(&buf, sent); err != nil {
+        t.Fatalf("Failed to write frame: %v", err)
+    }
+
+    for i := 0; i < 2; i++ {
+        received, err := transport.ReadFrame(&buf)
+        if err != nil {
+            t.Fatalf("Failed to read frame %d: %v", i, err)
+        }
+        if received.GetRequest().GetData() != "Test data" || received.GetFrom() != 1 || received.GetTo() != 2 {
+            t.Errorf("Expected the sent envelope, got %v", received)
+        }
+    }
+}
+
+func TestReadFrameRejectsOversizedFrame(t *testing.T) {
+    var header [4]byte
+    binary.BigEndian.PutUint32(header[:], 1<<31)
+    _, err := transport.ReadFrame(bytes.NewReader(header[:]))
+    if !errors.Is(err, transport.ErrFrameTooLarge) {
+        t.Errorf("Expected ErrFrameTooLarge, got %v", err)
+    }
+}
+
+func TestTCPHandshakeRejectsUnknownPeer(t *testing.T) {
+    tr, err := transport.NewTCPTransport(transport.TCPConfig{ID: 0, Listen: "127.0.0.1:0", Peers: map[int32]string{1: "127.0.0.1:1"}}, func(*wire.Envelope) {})
+    if err != nil {
+        t.Fatalf("Failed to start transport: %v", err)
+    }
+    defer tr.Close()
+
+    conn, err := net.Dial("tcp", tr.Addr().String())
+    if err != nil {
+        t.Fatalf("Failed to dial: %v", err)
+    }
+    defer conn.Close()
+    conn.Write([]byte{'C', 'A', 'E', '1', 0, 0, 0, 7}) // Node 7 is not a member of the cluster.
+
+    conn.SetReadDeadline(time.Now().Add(time.Second))
+    if n, err := conn.Read(make([]byte, 8)); err == nil {
+        t.Errorf("Expected the connection to be closed, got %d bytes", n)
+    }
+}
+
+func TestPBFTOverTCPTransport(t *testing.T) {
+    ids := []int32{0, 1, 2, 3}
+    addrs := freeAddrs(t, len(ids))
+    peers := make(map[int32]string)
+    for i, id := range ids {
+        peers[id] = addrs[i]
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    t.Cleanup(cancel)
+    runners := make([]*node.Runner, len(ids))
+    for i, id := range ids {
+        runners[i] = node.NewRunner(pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: ids}))
+        tr, err := transport.NewTCPTransport(transport.TCPConfig{ID: id, Listen: peers[id], Peers: peers}, runners[i].Handle)
+        if err != nil {
+            t.Fatalf("Failed to start transport %d: %v", id, err)
+        }
+        t.Cleanup(func() { tr.Close() })
+        runners[i].Attach(tr)
+        go runners[i].Run(ctx, 2*time.Millisecond)
+    }
+
+    for i := 1; i <= 3; i++ {
+        runners[i%len(runners)].Propose(fmt.Sprintf("Test block %d", i))
+    }
+
+    waitForCommits(t, runners, 4)
+    first := runners[0].Committed()
+    for _, runner := range runners[1:] {
+        for i, block := range runner.Committed()[:4] {
+            if block.GetHash() != first[i].GetHash() {
+                t.Errorf("Replicas disagree on block %d", i)
+            }
+        }
+    }
+}
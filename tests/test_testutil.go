@@ -0,0 +1,96 @@
+package tests
+
+import (
+    "context"
+    "fmt"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/testutil"
+    "consensus-algorithms-edu/wire"
+)
+
+// failures records the failures an assertion reports instead of failing the test running it.
+type failures struct {
+    testing.TB
+    messages []string
+}
+
+func (f *failures) Helper() {}
+
+func (f *failures) Errorf(format string, args ...any) {
+    f.messages = append(f.messages, fmt.Sprintf(format, args...))
+}
+
+func TestTestutilBuildsEveryAlgorithm(t *testing.T) {
+    link := sim.Link{Latency: sim.Constant(5 * time.Millisecond)}
+    pow := testutil.PoW(link, options.WithNodes(3), options.WithSeed(1))
+    pos := testutil.PoS(link, nil, options.WithSeed(1))
+    dpos := testutil.DPoS(link, options.WithNodes(5), options.WithSeed(1))
+    if len(pow.Replicas) != 3 || len(pos.Replicas) != options.DefaultNodes || len(dpos.Replicas) != 5 {
+        t.Fatalf("Expected 3, %d and 5 replicas, got %d, %d and %d", options.DefaultNodes, len(pow.Replicas), len(pos.Replicas), len(dpos.Replicas))
+    }
+    pow.RunFor(5 * time.Second)
+    pos.RunFor(5 * time.Second)
+    dpos.RunFor(5 * time.Second)
+    for name, chain := range map[string][]*wire.Block{"pow": pow.Replicas[0].Chain(), "pos": pos.Replicas[0].Chain(), "dpos": dpos.Replicas[0].Chain()} {
+        if len(chain) < 2 {
+            t.Errorf("Expected the %s network to grow its chain, got %d blocks", name, len(chain))
+        }
+    }
+}
+
+func TestTestutilFaultyNodesAreIsolated(t *testing.T) {
+    c := testutil.Raft(sim.Link{Latency: sim.Constant(time.Millisecond)}, options.WithNodes(5), options.WithFaulty(2), options.WithSeed(2))
+    leader, ok := c.WaitForLeader(10 * time.Second)
+    if !ok {
+        t.Fatalf("Expected the three healthy replicas to elect a leader")
+    }
+    if leader.ID() >= 3 {
+        t.Errorf("Expected an isolated replica never to lead, got node %d", leader.ID())
+    }
+    c.Propose(leader.ID(), "Test block 1")
+    c.RunFor(time.Second)
+    for _, r := range c.Replicas {
+        want := 2
+        if r.ID() >= 3 {
+            want = 1 // Isolated replicas only hold the genesis block.
+        }
+        if n := len(r.Committed()); n != want {
+            t.Errorf("Replica %d: expected %d blocks, got %d", r.ID(), want, n)
+        }
+    }
+    testutil.AssertAgreement(t, c.Chains())
+}
+
+func TestTestutilAssertionsReportDisagreement(t *testing.T) {
+    genesis := &wire.Block{Hash: "genesis"}
+    a := []*wire.Block{genesis, {Index: 1, Hash: "a"}}
+    b := []*wire.Block{genesis, {Index: 1, Hash: "b"}}
+
+    f := &failures{TB: t}
+    testutil.AssertAgreement(f, [][]*wire.Block{a, a[:1]})
+    if len(f.messages) != 0 {
+        t.Errorf("Expected a shorter chain with the same prefix to agree, got %v", f.messages)
+    }
+    testutil.AssertAgreement(f, [][]*wire.Block{a, b})
+    testutil.AssertSameChain(f, a, a[:1])
+    if len(f.messages) != 2 {
+        t.Errorf("Expected a fork and a missing block to be reported, got %v", f.messages)
+    }
+}
+
+func TestTestutilEngineUsesFakeClock(t *testing.T) {
+    e, mock := testutil.Engine(t, "paxos", options.WithNodes(3))
+    mock.Advance(time.Hour)
+    if err := e.Submit(context.Background(), "Test block 1"); err != nil {
+        t.Fatalf("Submit failed: %v", err)
+    }
+    if n := e.Status().Nodes; n != 3 {
+        t.Errorf("Expected 3 nodes, got %d", n)
+    }
+    if want, got := sim.Epoch.Add(time.Hour), wire.Timestamp(e.Blocks()[1].GetTimestamp()); !got.Time().Equal(want) {
+        t.Errorf("Expected the block to be stamped %s, got %s", want, got)
+    }
+}
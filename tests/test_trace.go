@@ -0,0 +1,360 @@
+package tests
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/trace"
+    "consensus-algorithms-edu/wire"
+)
+
+// recordRun records a run of algorithm on a lossy network: the cluster settles, then three blocks are proposed
+// at the replica node 0 follows, or at node 0 itself if its algorithm has no leader.
+func recordRun(t *testing.T, algorithm string) (*trace.Trace, []node.Replica) {
+    t.Helper()
+    s := sim.New(sim.Config{Seed: 3, Network: sim.Link{Latency: sim.Uniform(time.Millisecond, 15*time.Millisecond), Drop: 0.05}})
+    recorder, err := trace.Record(s, trace.Config{Algorithm: algorithm, Nodes: 4, Seed: 3})
+    if err != nil {
+        t.Fatalf("Record failed: %v", err)
+    }
+    s.RunFor(time.Second)
+    for i := 1; i <= 3; i++ {
+        target := int32(0)
+        if l, ok := recorder.Replicas()[0].(node.Leaderful); ok && l.Leader() >= 0 {
+            target = l.Leader()
+        }
+        s.Propose(target, fmt.Sprintf("Test block %d", i))
+        s.RunFor(200 * time.Millisecond)
+    }
+    return recorder.Trace(), recorder.Replicas()
+}
+
+func TestTraceReplaysEveryAlgorithm(t *testing.T) {
+    for _, algorithm := range []string{"raft", "pbft", "pow", "pos", "dpos"} {
+        recorded, replicas := recordRun(t, algorithm)
+        if len(recorded.Steps) == 0 {
+            t.Fatalf("Expected %s steps to be recorded", algorithm)
+        }
+        var buf bytes.Buffer
+        if err := recorded.Write(&buf); err != nil {
+            t.Fatalf("Write failed: %v", err)
+        }
+        loaded, err := trace.Read(&buf)
+        if err != nil {
+            t.Fatalf("Read failed: %v", err)
+        }
+        replay, err := trace.NewReplayer(loaded, nil)
+        if err != nil {
+            t.Fatalf("NewReplayer failed: %v", err)
+        }
+        if err := replay.Seek(replay.Len()); err != nil {
+            t.Errorf("Expected %s to replay without diverging, got %v", algorithm, err)
+            continue
+        }
+        for i, state := range replay.States() {
+            if want := trace.Describe(replicas[i]); state != want {
+                t.Errorf("Expected %s node %d to end in %+v, got %+v", algorithm, i, want, state)
+            }
+        }
+    }
+}
+
+func TestTraceStepsBackward(t *testing.T) {
+    recorded, _ := recordRun(t, "raft")
+    replay, err := trace.NewReplayer(recorded, nil)
+    if err != nil {
+        t.Fatalf("NewReplayer failed: %v", err)
+    }
+    middle := replay.Len() / 2
+    if err := replay.Seek(middle); err != nil {
+        t.Fatalf("Seek failed: %v", err)
+    }
+    before := replay.States()
+    next, err := replay.Forward()
+    if err != nil || next.State != replay.States()[next.Node] {
+        t.Fatalf("Expected the step's recorded state to be reached, got %v", err)
+    }
+    back, err := replay.Back()
+    if err != nil || back.String() != next.String() || replay.Position() != middle {
+        t.Fatalf("Expected Back to undo step %d, got %v at %d", middle, err, replay.Position())
+    }
+    for i, state := range replay.States() {
+        if state != before[i] {
+            t.Errorf("Expected node %d to return to %+v, got %+v", i, before[i], state)
+        }
+    }
+    if _, err := replay.Forward(); err != nil {
+        t.Errorf("Expected the step to apply again, got %v", err)
+    }
+    if err := replay.Seek(0); err != nil || replay.Position() != 0 {
+        t.Errorf("Expected to rewind to the start, got %v", err)
+    }
+    if _, err := replay.Back(); !errors.Is(err, trace.ErrOutOfRange) {
+        t.Errorf("Expected no step before the first, got %v", err)
+    }
+}
+
+func TestTraceReplayDetectsDivergence(t *testing.T) {
+    recorded, _ := recordRun(t, "raft")
+    tampered := -1
+    for i, step := range recorded.Steps {
+        if step.Input == sim.DeliverInput {
+            tampered = i
+            break
+        }
+    }
+    if tampered < 0 {
+        t.Fatalf("Expected a delivery in the trace")
+    }
+    recorded.Steps[tampered].State.Fingerprint = "tampered"
+    replay, err := trace.NewReplayer(recorded, nil)
+    if err != nil {
+        t.Fatalf("NewReplayer failed: %v", err)
+    }
+    if err := replay.Seek(replay.Len()); !errors.Is(err, trace.ErrDiverged) || replay.Position() != tampered+1 {
+        t.Errorf("Expected the replay to stop at step %d, got %v at %d", tampered, err, replay.Position())
+    }
+
+    if _, err := trace.NewReplayer(&trace.Trace{Algorithm: "paxos", Nodes: 3}, nil); !errors.Is(err, trace.ErrUnknownAlgorithm) {
+        t.Errorf("Expected an algorithm without replicas to need a constructor, got %v", err)
+    }
+}
+
+func TestTraceExportPairsSendsAndReceives(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 5, Network: sim.Link{Latency: sim.Uniform(time.Millisecond, 15*time.Millisecond), Drop: 0.1}})
+    s.Network.Inject(sim.Fault{Duplicate: 0.1})
+    recorder, err := trace.Record(s, trace.Config{Algorithm: "raft", Nodes: 3, Seed: 5})
+    if err != nil {
+        t.Fatalf("Record failed: %v", err)
+    }
+    s.RunFor(2 * time.Second)
+    recorded := recorder.Trace()
+    export, err := recorded.Export()
+    if err != nil {
+        t.Fatalf("Export failed: %v", err)
+    }
+    if len(export.Transitions) != len(recorded.Steps) {
+        t.Fatalf("Expected a transition per step, got %d of %d", len(export.Transitions), len(recorded.Steps))
+    }
+
+    sent, delivered := 0, 0
+    for _, step := range recorded.Steps {
+        sent += len(step.Output)
+        if step.Envelope != nil {
+            delivered++
+        }
+    }
+    lost, duplicates, received := 0, 0, 0
+    for _, m := range export.Messages {
+        switch {
+        case m.SentAt == nil:
+            t.Errorf("Expected every delivery tied to its send, got message %d received unsent", m.ID)
+        case m.ReceivedAt == nil:
+            lost++
+        case *m.ReceivedAt-*m.SentAt < 0.001 || *m.ReceivedAt-*m.SentAt > 0.015:
+            t.Errorf("Expected message %d to take as long as the network makes it, got %vs", m.ID, *m.ReceivedAt-*m.SentAt)
+        }
+        if m.ReceivedAt != nil {
+            received++
+        }
+        if m.Copy > 0 {
+            duplicates++
+        }
+    }
+    if len(export.Messages)-duplicates != sent || received != delivered {
+        t.Errorf("Expected %d messages sent and %d received, got %d and %d", sent, delivered, len(export.Messages)-duplicates, received)
+    }
+    if lost == 0 || duplicates == 0 {
+        t.Errorf("Expected lost and duplicated messages on a faulty network, got %d and %d", lost, duplicates)
+    }
+
+    last := append([]trace.State(nil), recorded.Initial...)
+    for _, tr := range export.Transitions {
+        if tr.Before != last[tr.Node] {
+            t.Fatalf("Expected step %d to start from the state node %d was left in, got %+v", tr.Step, tr.Node, tr.Before)
+        }
+        last[tr.Node] = tr.After
+    }
+
+    var file bytes.Buffer
+    if err := recorded.Write(&file); err != nil {
+        t.Fatalf("Write failed: %v", err)
+    }
+    loaded, err := trace.Read(&file)
+    if err != nil {
+        t.Fatalf("Read failed: %v", err)
+    }
+    var before, after bytes.Buffer
+    reloaded, _ := loaded.Export()
+    export.Write(&before)
+    reloaded.Write(&after)
+    if before.String() != after.String() {
+        t.Errorf("Expected a trace file to export as the recording does")
+    }
+}
+
+func TestTraceExportFollowsSchema(t *testing.T) {
+    var schema struct {
+        Required []string `json:"required"`
+        Defs     map[string]struct {
+            Required   []string       `json:"required"`
+            Properties map[string]any `json:"properties"`
+        } `json:"$defs"`
+    }
+    if err := json.Unmarshal(trace.Schema, &schema); err != nil {
+        t.Fatalf("Expected the schema to be JSON, got %v", err)
+    }
+    recorded, _ := recordRun(t, "pbft")
+    export, err := recorded.Export()
+    if err != nil {
+        t.Fatalf("Export failed: %v", err)
+    }
+    var buf bytes.Buffer
+    if err := export.Write(&buf); err != nil {
+        t.Fatalf("Write failed: %v", err)
+    }
+    var doc map[string]any
+    if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+        t.Fatalf("Expected the export to be JSON, got %v", err)
+    }
+    // requireFields checks that object has every field the schema requires, and no field the schema omits.
+    requireFields := func(name string, object map[string]any, required []string, properties map[string]any) {
+        for _, field := range required {
+            if _, ok := object[field]; !ok {
+                t.Errorf("Expected %s to have the field %q", name, field)
+            }
+        }
+        for field := range object {
+            if _, ok := properties[field]; properties != nil && !ok {
+                t.Errorf("Expected the schema to document the field %q of %s", field, name)
+            }
+        }
+    }
+    requireFields("the export", doc, schema.Required, nil)
+    for _, table := range []struct{ field, def string }{{"messages", "message"}, {"transitions", "transition"}, {"initial", "state"}} {
+        rows := doc[table.field].([]any)
+        if len(rows) == 0 {
+            t.Fatalf("Expected %s in the export", table.field)
+        }
+        def := schema.Defs[table.def]
+        for _, row := range rows[:min(len(rows), 50)] {
+            requireFields(table.def, row.(map[string]any), def.Required, def.Properties)
+        }
+    }
+}
+
+func TestTraceSeeksToAnInstant(t *testing.T) {
+    recorded, _ := recordRun(t, "raft")
+    replay, err := trace.NewReplayer(recorded, nil)
+    if err != nil {
+        t.Fatalf("NewReplayer failed: %v", err)
+    }
+    at := 1100 * time.Millisecond
+    if err := replay.SeekTime(at); err != nil {
+        t.Fatalf("SeekTime failed: %v", err)
+    }
+    n := replay.Position()
+    if n == 0 || n == replay.Len() || recorded.Steps[n-1].At > at || recorded.Steps[n].At <= at {
+        t.Errorf("Expected the replay to stop after the last step at or before %v, got position %d", at, n)
+    }
+    if replay.At() != recorded.Steps[n-1].At {
+        t.Errorf("Expected At to return %v, got %v", recorded.Steps[n-1].At, replay.At())
+    }
+    if err := replay.SeekTime(-time.Second); !errors.Is(err, trace.ErrOutOfRange) {
+        t.Errorf("Expected a negative time to be out of range, got %v", err)
+    }
+}
+
+func TestTraceBranchesIntoWhatIf(t *testing.T) {
+    recorded, _ := recordRun(t, "raft")
+    replay, err := trace.NewReplayer(recorded, nil)
+    if err != nil {
+        t.Fatalf("NewReplayer failed: %v", err)
+    }
+    if err := replay.SeekTime(1100 * time.Millisecond); err != nil {
+        t.Fatalf("SeekTime failed: %v", err)
+    }
+    position, states := replay.Position(), replay.States()
+    branch, err := replay.Branch(trace.BranchConfig{Sim: sim.Config{Seed: 9, Network: sim.Link{Latency: sim.Uniform(time.Millisecond, 15*time.Millisecond)}}})
+    if err != nil {
+        t.Fatalf("Branch failed: %v", err)
+    }
+    if replay.Position() != position {
+        t.Errorf("Expected the replay to stay at %d, got %d", position, replay.Position())
+    }
+    if branch.Sim.Now() != replay.At() {
+        t.Errorf("Expected the branch to start at %v, got %v", replay.At(), branch.Sim.Now())
+    }
+    for i, replica := range branch.Replicas() {
+        if got := trace.Describe(replica); got != states[i] {
+            t.Errorf("Expected node %d to start the branch in %+v, got %+v", i, states[i], got)
+        }
+    }
+
+    // What if the leader had crashed here? The others elect a new one, which the recording never did.
+    leader := states[0].Leader
+    for _, id := range branch.Sim.Nodes() {
+        if id != leader {
+            branch.Sim.Network.Disconnect(leader, id)
+        }
+    }
+    branch.Sim.RunFor(2 * time.Second)
+    for _, replica := range branch.Replicas() {
+        if state := trace.Describe(replica); replica.ID() != leader && (state.Leader < 0 || state.Leader == leader) {
+            t.Errorf("Expected node %d to follow a new leader, got %+v", replica.ID(), state)
+        }
+    }
+
+    // The branch is a trace of its own: the replayed steps followed by the new ones.
+    continued := branch.Trace()
+    if len(continued.Steps) <= position {
+        t.Fatalf("Expected the branch to record new steps, got %d", len(continued.Steps))
+    }
+    again, err := trace.NewReplayer(continued, nil)
+    if err != nil {
+        t.Fatalf("NewReplayer failed: %v", err)
+    }
+    if err := again.Seek(again.Len()); err != nil {
+        t.Errorf("Expected the branch to replay without diverging, got %v", err)
+    }
+    e, err := continued.Export()
+    if err != nil {
+        t.Fatalf("Export failed: %v", err)
+    }
+    for _, m := range e.Messages {
+        if m.SentAt == nil {
+            t.Errorf("Expected every delivery of the branch to be tied to its send, got message %d from %d to %d", m.ID, m.From, m.To)
+            break
+        }
+    }
+}
+
+func TestTraceBranchLosesInFlightMessages(t *testing.T) {
+    recorded, _ := recordRun(t, "raft")
+    replay, err := trace.NewReplayer(recorded, nil)
+    if err != nil {
+        t.Fatalf("NewReplayer failed: %v", err)
+    }
+    if err := replay.SeekTime(1100 * time.Millisecond); err != nil {
+        t.Fatalf("SeekTime failed: %v", err)
+    }
+    kept, err := replay.Branch(trace.BranchConfig{})
+    if err != nil {
+        t.Fatalf("Branch failed: %v", err)
+    }
+    lost, err := replay.Branch(trace.BranchConfig{Lose: func(*wire.Envelope) bool { return true }})
+    if err != nil {
+        t.Fatalf("Branch failed: %v", err)
+    }
+    if kept.InFlight == 0 || kept.Lost != 0 {
+        t.Errorf("Expected messages in flight at the branch point, got %d in flight and %d lost", kept.InFlight, kept.Lost)
+    }
+    if lost.InFlight != 0 || lost.Lost != kept.InFlight {
+        t.Errorf("Expected all %d messages in flight to be lost, got %d in flight and %d lost", kept.InFlight, lost.InFlight, lost.Lost)
+    }
+}
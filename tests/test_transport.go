@@ -0,0 +1,103 @@
+package tests
+
+import (
+    "context"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/transport"
+)
+
+// startCluster runs one runner per replica on an in-memory network and returns them.
+// Each configure function is applied to every runner before it starts.
+func startCluster(t *testing.T, replicas []node.Replica, configure ...func(*node.Runner)) []*node.Runner {
+    network := transport.NewMemoryNetwork()
+    ctx, cancel := context.WithCancel(context.Background())
+    t.Cleanup(cancel)
+
+    runners := make([]*node.Runner, len(replicas))
+    for i, replica := range replicas {
+        runners[i] = node.NewRunner(replica)
+        for _, apply := range configure {
+            apply(runners[i])
+        }
+        endpoint := network.Join(replica.ID(), runners[i].Handle)
+        t.Cleanup(func() { endpoint.Close() })
+        runners[i].Attach(endpoint)
+        go runners[i].Run(ctx, 2*time.Millisecond)
+    }
+    return runners
+}
+
+// waitForCommits polls until every runner has committed want blocks (including genesis).
+func waitForCommits(t *testing.T, runners []*node.Runner, want int) {
+    deadline := time.Now().Add(5 * time.Second)
+    for time.Now().Before(deadline) {
+        done := true
+        for _, runner := range runners {
+            if len(runner.Committed()) < want {
+                done = false
+            }
+        }
+        if done {
+            return
+        }
+        time.Sleep(5 * time.Millisecond)
+    }
+    t.Fatalf("Timed out waiting for %d committed blocks", want)
+}
+
+func TestRaftOverMemoryTransport(t *testing.T) {
+    peers := []int32{0, 1, 2}
+    replicas := make([]node.Replica, len(peers))
+    for i, id := range peers {
+        replicas[i] = raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: peers})
+    }
+    runners := startCluster(t, replicas)
+
+    // Keep proposing to every node until one of them is the leader and accepts the block.
+    deadline := time.Now().Add(5 * time.Second)
+    for accepted := false; !accepted; {
+        if time.Now().After(deadline) {
+            t.Fatalf("No leader was elected")
+        }
+        for _, runner := range runners {
+            if runner.Propose("Test block 1") == nil {
+                accepted = true
+                break
+            }
+        }
+        time.Sleep(5 * time.Millisecond)
+    }
+
+    waitForCommits(t, runners, 2)
+    for _, runner := range runners {
+        if data := runner.Committed()[1].GetData(); data != "Test block 1" {
+            t.Errorf("Expected 'Test block 1', got '%s'", data)
+        }
+    }
+}
+
+func TestPBFTOverMemoryTransport(t *testing.T) {
+    peers := []int32{0, 1, 2, 3}
+    replicas := make([]node.Replica, len(peers))
+    for i, id := range peers {
+        replicas[i] = pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: peers})
+    }
+    runners := startCluster(t, replicas)
+
+    runners[0].Propose("Test block 1")
+    runners[2].Propose("Test block 2") // Submitted to a backup, which forwards it to the primary.
+
+    waitForCommits(t, runners, 3)
+    first := runners[0].Committed()
+    for _, runner := range runners[1:] {
+        for i, block := range runner.Committed()[:3] {
+            if block.GetHash() != first[i].GetHash() {
+                t.Errorf("Replicas disagree on block %d", i)
+            }
+        }
+    }
+}
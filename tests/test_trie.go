@@ -0,0 +1,49 @@
+package tests
+
+import (
+    "errors"
+    "fmt"
+    "testing"
+    "consensus-algorithms-edu/trie"
+)
+
+func TestTrieProvesPresenceAndAbsence(t *testing.T) {
+    forward, backward := trie.New(), trie.New()
+    if forward.Root() != trie.EmptyRoot {
+        t.Errorf("Expected an empty trie to have the empty root, got %s", forward.Root())
+    }
+    const n = 200
+    for i := 0; i < n; i++ {
+        forward.Put([]byte(fmt.Sprintf("key-%d", i)), []byte(fmt.Sprint(i)))
+        backward.Put([]byte(fmt.Sprintf("key-%d", n-1-i)), []byte(fmt.Sprint(n-1-i)))
+    }
+    root := forward.Root()
+    if backward.Root() != root {
+        t.Errorf("Expected the root not to depend on the order keys were added in")
+    }
+
+    for i := 0; i < n; i++ {
+        key := []byte(fmt.Sprintf("key-%d", i))
+        value, ok, err := trie.VerifyProof(root, key, forward.Prove(key))
+        if err != nil || !ok || string(value) != fmt.Sprint(i) {
+            t.Fatalf("Expected the proof of %s to verify to %d, got %q, %v, %v", key, i, value, ok, err)
+        }
+    }
+    missing := []byte("key-missing")
+    if _, ok, err := trie.VerifyProof(root, missing, forward.Prove(missing)); err != nil || ok {
+        t.Errorf("Expected a proof of absence, got %v, %v", ok, err)
+    }
+
+    key := []byte("key-7")
+    proof := forward.Prove(key)
+    forward.Put(key, []byte("700"))
+    if forward.Root() == root {
+        t.Errorf("Expected changing a value to change the root")
+    }
+    if _, _, err := trie.VerifyProof(forward.Root(), key, proof); !errors.Is(err, trie.ErrBadProof) {
+        t.Errorf("Expected a proof against the old root to be rejected, got %v", err)
+    }
+    if _, _, err := trie.VerifyProof(root, key, proof[:len(proof)-1]); !errors.Is(err, trie.ErrBadProof) {
+        t.Errorf("Expected a truncated proof to be rejected, got %v", err)
+    }
+}
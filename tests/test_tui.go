@@ -0,0 +1,92 @@
+package tests
+
+import (
+    "math/rand"
+    "strings"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/modelcheck"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/trace"
+    "consensus-algorithms-edu/tui"
+    tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTUICluster builds a simulated cluster of n replicas of algorithm, as consensus tui does.
+func newTUICluster(algorithm string, n int) *sim.Simulator {
+    s := sim.New(sim.Config{Seed: 1, Network: sim.Link{Latency: sim.Uniform(time.Millisecond, 10*time.Millisecond)}})
+    peers := make([]int32, n)
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    for _, id := range peers {
+        s.Add(trace.Builders[algorithm](id, peers, modelcheck.Env{Clock: s.Clock(), Rand: rand.New(rand.NewSource(int64(id) + 1))}))
+    }
+    return s
+}
+
+// press sends a key to the model and returns the command it answered with.
+func press(m tea.Model, key string) tea.Cmd {
+    _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+    return cmd
+}
+
+func TestTUIShowsRolesAndMessages(t *testing.T) {
+    s := newTUICluster("raft", 3)
+    m := tui.New(s, tui.Config{Algorithm: "raft"})
+    s.RunFor(time.Second)
+    view := m.View()
+    for _, want := range []string{"raft, 3 nodes", "leader", "follower", "AppendEntries from node-", "→"} {
+        if !strings.Contains(view, want) {
+            t.Errorf("Expected the view to show %q, got\n%s", want, view)
+        }
+    }
+
+    press(m, "p")
+    s.RunFor(time.Second)
+    for _, id := range s.Nodes() {
+        if row := tui.Describe(s.Replica(id)); row.Height != 1 || row.Log != 2 || row.Term == "-" {
+            t.Errorf("Expected node %d to show the proposed block in its log, got %+v", id, row)
+        }
+    }
+
+    press(m, "1")
+    if view := m.View(); !strings.Contains(view, "follower, cut off") || !strings.Contains(view, "node-1 off from the others") {
+        t.Errorf("Expected node 1 to show as cut off, got\n%s", view)
+    }
+    press(m, "h")
+    if view := m.View(); strings.Contains(view, ", cut off") {
+        t.Errorf("Expected the heal to reconnect node 1, got\n%s", view)
+    }
+
+    press(m, "n")
+    if view := m.View(); !strings.Contains(view, "paused") || !strings.Contains(view, "stepped to "+s.Now().String()) {
+        t.Errorf("Expected stepping to pause the display at the event taken, got\n%s", view)
+    }
+    if cmd := press(m, "q"); cmd == nil {
+        t.Errorf("Expected q to quit")
+    } else if _, ok := cmd().(tea.QuitMsg); !ok {
+        t.Errorf("Expected q to quit, got %T", cmd())
+    }
+}
+
+func TestTUIDescribesEveryAlgorithm(t *testing.T) {
+    roles := map[string][]string{"raft": {"leader", "follower"}, "pbft": {"primary", "backup"}, "pow": {"producer"}, "pos": {"producer"}, "dpos": {"producer"}}
+    for algorithm, want := range roles {
+        s := newTUICluster(algorithm, 4)
+        s.RunFor(2 * time.Second)
+        seen := make(map[string]bool)
+        for _, id := range s.Nodes() {
+            row := tui.Describe(s.Replica(id))
+            seen[row.Role] = true
+            if row.Log <= row.Height {
+                t.Errorf("Expected %s node %d to hold more blocks than its height, got %+v", algorithm, id, row)
+            }
+        }
+        for _, role := range want {
+            if !seen[role] {
+                t.Errorf("Expected a %s %s, got %v", algorithm, role, seen)
+            }
+        }
+    }
+}
@@ -0,0 +1,218 @@
+package tests
+
+import (
+    "bytes"
+    "context"
+    "encoding/xml"
+    "io"
+    "strings"
+    "testing"
+    "time"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/viz"
+    "consensus-algorithms-edu/wire"
+)
+
+// forkedBlocks returns a genesis block with two competing children, the first of which is extended once.
+func forkedBlocks() (canonical, fork []*wire.Block) {
+    genesis := &wire.Block{Index: 0, Hash: "genesis"}
+    a1 := &wire.Block{Index: 1, Hash: "a1", PrevHash: "genesis", Producer: "alice"}
+    a2 := &wire.Block{Index: 2, Hash: "a2", PrevHash: "a1", Producer: "bob"}
+    b1 := &wire.Block{Index: 1, Hash: "b1", PrevHash: "genesis", Producer: "carol"}
+    return []*wire.Block{genesis, a1, a2}, []*wire.Block{genesis, b1}
+}
+
+// svgBlocks parses an SVG drawing and returns the hash of every block group, in document order.
+func svgBlocks(t *testing.T, data []byte) []string {
+    var hashes []string
+    decoder := xml.NewDecoder(bytes.NewReader(data))
+    for {
+        token, err := decoder.Token()
+        if err == io.EOF {
+            return hashes
+        }
+        if err != nil {
+            t.Fatalf("Invalid SVG: %v", err)
+        }
+        if start, ok := token.(xml.StartElement); ok && start.Name.Local == "g" {
+            for _, attr := range start.Attr {
+                if attr.Name.Local == "data-hash" {
+                    hashes = append(hashes, attr.Value)
+                }
+            }
+        }
+    }
+}
+
+func TestVizDOTMarksCanonicalChainAndForks(t *testing.T) {
+    canonical, fork := forkedBlocks()
+    var buf bytes.Buffer
+    if err := viz.DOT(&buf, viz.Merge(canonical, fork), viz.Options{Title: "Fork"}); err != nil {
+        t.Fatalf("DOT failed: %v", err)
+    }
+    out := buf.String()
+
+    if !strings.HasPrefix(out, "digraph chain {") || !strings.Contains(out, `label="Fork"`) {
+        t.Errorf("Expected a titled digraph, got:\n%s", out)
+    }
+    for _, hash := range []string{"genesis", "a1", "a2"} {
+        if !strings.Contains(out, `"`+hash+`" [label=`) || !strings.Contains(lineOf(out, `"`+hash+`" [label=`), "penwidth=2") {
+            t.Errorf("Expected block %s to be drawn as canonical", hash)
+        }
+    }
+    if line := lineOf(out, `"b1" [label=`); !strings.Contains(line, "dashed") {
+        t.Errorf("Expected the fork block to be dashed, got %q", line)
+    }
+    if strings.Count(out, `"genesis" -> `) != 2 {
+        t.Errorf("Expected two edges out of the genesis block, got:\n%s", out)
+    }
+}
+
+// lineOf returns the first line of s containing substr.
+func lineOf(s, substr string) string {
+    for _, line := range strings.Split(s, "\n") {
+        if strings.Contains(line, substr) {
+            return line
+        }
+    }
+    return ""
+}
+
+func TestVizSVGDrawsEveryBlockOnce(t *testing.T) {
+    canonical, fork := forkedBlocks()
+    var buf bytes.Buffer
+    if err := viz.SVG(&buf, append(canonical, fork...), viz.Options{Title: "<fork> & more"}); err != nil {
+        t.Fatalf("SVG failed: %v", err)
+    }
+    hashes := svgBlocks(t, buf.Bytes())
+    if strings.Join(hashes, ",") != "genesis,a1,b1,a2" {
+        t.Errorf("Expected each block once, ordered by index, got %v", hashes)
+    }
+}
+
+func TestVizTextDrawsForkBesideCanonicalChain(t *testing.T) {
+    canonical, fork := forkedBlocks()
+    var buf bytes.Buffer
+    if err := viz.Text(&buf, viz.Merge(canonical, fork), viz.Options{Title: "Fork"}); err != nil {
+        t.Fatalf("Text failed: %v", err)
+    }
+    want := strings.Join([]string{
+        "Fork",
+        "#0  genesis",
+        "    ├──────────┐",
+        "#1  a1 alice   b1 carol",
+        "#2  a2 bob",
+        "",
+    }, "\n")
+    if out := buf.String(); out != want {
+        t.Errorf("Expected the fork in a column of its own, joined to the genesis block, got:\n%s", out)
+    }
+}
+
+func TestVizColorsProducersConsistently(t *testing.T) {
+    e, _ := engine.New("pos", engine.Config{Nodes: 3})
+    for i := 0; i < 10; i++ {
+        e.Submit(context.Background(), "Test block")
+    }
+    var first, second bytes.Buffer
+    viz.DOT(&first, e.Blocks(), viz.Options{})
+    viz.DOT(&second, e.Blocks(), viz.Options{})
+    if first.String() != second.String() {
+        t.Errorf("Expected drawings of the same blocks to be identical")
+    }
+
+    colors := make(map[string]string)
+    for _, block := range e.Blocks()[1:] {
+        line := lineOf(first.String(), `"`+block.GetHash()+`" [label=`)
+        color := line[strings.Index(line, "fillcolor="):]
+        if previous, ok := colors[block.GetProducer()]; ok && previous != color {
+            t.Errorf("Expected %s to keep one color, got %s and %s", block.GetProducer(), previous, color)
+        }
+        colors[block.GetProducer()] = color
+    }
+    distinct := make(map[string]bool)
+    for _, color := range colors {
+        distinct[color] = true
+    }
+    if len(distinct) != len(colors) {
+        t.Errorf("Expected every producer to get its own color, got %v", colors)
+    }
+}
+
+func TestVizDrawsPoWForkFromPartition(t *testing.T) {
+    s := sim.New(sim.Config{Seed: 9, Network: sim.Link{Latency: sim.Constant(10 * time.Millisecond)}})
+    peers := []int32{0, 1, 2, 3}
+    miners := make([]*pow.Miner, len(peers))
+    for i, id := range peers {
+        miners[i] = pow.NewMiner(pow.MinerConfig{ID: id, Peers: peers, MineProbability: 0.02, Rand: s.NewRand(), Clock: s.Clock()})
+        s.Add(miners[i])
+    }
+    s.Network.Partition([]int32{0, 1}, []int32{2, 3})
+    s.RunFor(time.Second)
+    s.Network.Heal()
+    s.RunFor(10 * time.Second)
+
+    var blocks []*wire.Block
+    for _, m := range miners {
+        blocks = viz.Merge(blocks, m.Blocks())
+    }
+    var buf bytes.Buffer
+    if err := viz.SVG(&buf, blocks, viz.Options{Canonical: miners[0].Chain()}); err != nil {
+        t.Fatalf("SVG failed: %v", err)
+    }
+    if drawn := svgBlocks(t, buf.Bytes()); len(drawn) != len(blocks) || len(blocks) <= len(miners[0].Chain()) {
+        t.Fatalf("Expected %d blocks including abandoned ones, got %d drawn for a chain of %d", len(blocks), len(drawn), len(miners[0].Chain()))
+    }
+    if !strings.Contains(buf.String(), "stroke-dasharray") || !strings.Contains(buf.String(), "node-") {
+        t.Errorf("Expected dashed fork blocks labelled with their miners")
+    }
+}
+
+func TestVizDrawsTopologyEdgesOnce(t *testing.T) {
+    topology := map[int32][]int32{0: {1, 2}, 1: {0}, 2: {0}, 3: nil}
+    var dot, svg strings.Builder
+    if err := viz.TopologyDOT(&dot, topology, viz.Options{}); err != nil {
+        t.Fatalf("TopologyDOT failed: %v", err)
+    }
+    if n := strings.Count(dot.String(), " -- "); n != 2 {
+        t.Errorf("Expected 2 edges, got %d in %s", n, dot.String())
+    }
+    if err := viz.TopologySVG(&svg, topology, viz.Options{}); err != nil {
+        t.Fatalf("TopologySVG failed: %v", err)
+    }
+    if n := strings.Count(svg.String(), "<circle"); n != 4 {
+        t.Errorf("Expected 4 nodes, got %d", n)
+    }
+    if !strings.Contains(svg.String(), `data-id="3"`) || strings.Count(svg.String(), "stroke-dasharray") != 1 {
+        t.Errorf("Expected the unconnected node 3 drawn once, dashed")
+    }
+}
+
+func TestVizFrameDrawsMembersByRoleAndArrows(t *testing.T) {
+    frame := viz.Frame{
+        Caption: "node-1 asks for votes",
+        Members: []viz.Member{{ID: 0, Role: "follower", Label: "term 1"}, {ID: 1, Role: "candidate", Label: "term 1"}, {ID: 2, Role: "follower", Down: true}},
+        Arrows:  []viz.Arrow{{From: 1, To: 0, Label: "RequestVote"}, {From: 1, To: 2, Label: "RequestVote"}, {From: 1, To: 7, Label: "RequestVote"}},
+    }
+    var svg, text strings.Builder
+    if err := viz.FrameSVG(&svg, frame, viz.Options{Title: "Election"}); err != nil {
+        t.Fatalf("FrameSVG failed: %v", err)
+    }
+    if n := strings.Count(svg.String(), `class="member"`); n != 3 {
+        t.Errorf("Expected 3 members, got %d", n)
+    }
+    if n := strings.Count(svg.String(), `class="arrow"`); n != 2 {
+        t.Errorf("Expected 2 arrows, the one to an unknown node left out, got %d", n)
+    }
+    if !strings.Contains(svg.String(), `fill="`+viz.RoleColors["candidate"]+`"`) || strings.Count(svg.String(), "stroke-dasharray") != 1 {
+        t.Errorf("Expected the candidate filled by role and the crashed node dashed")
+    }
+    if err := viz.FrameText(&text, frame, viz.Options{}); err != nil {
+        t.Fatalf("FrameText failed: %v", err)
+    }
+    if !strings.Contains(text.String(), "→ RequestVote to node-0, node-2, node-7") || !strings.Contains(text.String(), "down") {
+        t.Errorf("Expected node-1's requests and node-2 shown down, got:\n%s", text.String())
+    }
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+    "bytes"
+    "errors"
+    "testing"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/ledger"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/vm"
+)
+
+func TestRunComputesDeterministically(t *testing.T) {
+    // Sums 1..n with a loop: total is kept in storage, n counts down on the stack.
+    program, err := vm.Assemble(`
+        arg 0                      # 0: n
+        dup; not; jumpi 11         # 1-3: done when n is 0
+        dup; load total; add       # 4-6
+        store total                # 7: total += n
+        push 1; sub; jump 1        # 8-10: n--
+        load total                 # 11
+    `)
+    if err != nil {
+        t.Fatal(err)
+    }
+    storage := vm.Storage{}
+    if result, err := vm.Run(program, []int64{10}, storage, vm.DefaultGas); err != nil || result != 55 {
+        t.Errorf("Expected 55, got %d and %v", result, err)
+    }
+    if _, err := vm.Run(program, []int64{1 << 40}, vm.Storage{}, vm.DefaultGas); !errors.Is(err, vm.ErrOutOfGas) || !errors.Is(err, ledger.ErrInvalid) {
+        t.Errorf("Expected a long loop to run out of gas, got %v", err)
+    }
+    if _, err := vm.Run(vm.Program{{Op: vm.OpPush, N: 1}, {Op: vm.OpPush}, {Op: vm.OpDiv}}, nil, vm.Storage{}, vm.DefaultGas); !errors.Is(err, vm.ErrFault) {
+        t.Errorf("Expected division by zero to fault, got %v", err)
+    }
+    if _, err := vm.Assemble("push one"); err == nil {
+        t.Errorf("Expected an operand that is not an integer to be refused")
+    }
+    if again, _ := vm.Assemble(program.String()); again.String() != program.String() {
+        t.Errorf("Expected a program to assemble from its own listing, got %v", again)
+    }
+}
+
+func TestContractsExecuteIdenticallyOnEveryReplica(t *testing.T) {
+    blockchain := pbft.NewPBFTNetwork(options.WithNodes(4))
+    contracts := vm.NewContracts(100)
+    if err := blockchain.AttachState(contracts); err != nil {
+        t.Fatal(err)
+    }
+    counter := "load count; arg 0; add; dup; store count"
+    if _, err := blockchain.RunPBFT(vm.EncodeCalls(vm.Call{Contract: "counter", Code: counter}, vm.Call{Contract: "counter", Args: []int64{5}})); err != nil {
+        t.Fatalf("Expected the deployment and invocation to commit, got %v", err)
+    }
+    if _, err := blockchain.RunPBFT(vm.EncodeCalls(vm.Call{Contract: "counter", Args: []int64{2}})); err != nil {
+        t.Fatal(err)
+    }
+
+    cases := []struct {
+        call vm.Call
+        want error
+    }{
+        {vm.Call{Contract: "counter", Code: counter}, vm.ErrDeployed},
+        {vm.Call{Contract: "missing"}, vm.ErrNoContract},
+        {vm.Call{Contract: "broken", Code: "jump"}, ledger.ErrMalformed},
+    }
+    for _, c := range cases {
+        if _, err := blockchain.RunPBFT(vm.EncodeCalls(c.call)); !errors.Is(err, c.want) {
+            t.Errorf("Expected %v for %+v, got %v", c.want, c.call, err)
+        }
+    }
+    // A block that deploys a looping contract and invokes it is refused as a whole: not even the deployment stays.
+    loop := vm.EncodeCalls(vm.Call{Contract: "loop", Code: "jump 0"}, vm.Call{Contract: "loop"})
+    if _, err := blockchain.RunPBFT(loop); !errors.Is(err, vm.ErrOutOfGas) {
+        t.Errorf("Expected an endless loop to run out of gas, got %v", err)
+    }
+    if contracts.Storage("loop") != nil || contracts.Storage("counter")["count"] != 7 || blockchain.Height() != 2 {
+        t.Errorf("Expected only the counter's two blocks, got height %d and storage %v", blockchain.Height(), contracts.Storage("counter"))
+    }
+    if count, err := contracts.Query("counter", 1); err != nil || count != 8 || contracts.Storage("counter")["count"] != 7 {
+        t.Errorf("Expected a query to return 8 without storing it, got %d and %v", count, err)
+    }
+
+    // Another node that replays the same blocks ends with the same contract state.
+    var snapshot bytes.Buffer
+    blockchain.Export(&snapshot)
+    replica := pbft.NewPBFTNetwork(options.WithNodes(4))
+    replayed := vm.NewContracts(100)
+    replica.AttachState(replayed)
+    if err := replica.Import(&snapshot); err != nil {
+        t.Fatal(err)
+    }
+    if replayed.Digest() != contracts.Digest() {
+        t.Errorf("Expected the replayed contracts to match, got %v and %v", replayed.Storage("counter"), contracts.Storage("counter"))
+    }
+}
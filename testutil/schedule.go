@@ -0,0 +1,166 @@
+package testutil
+
+import (
+    "fmt"
+    "math/rand"
+    "reflect"
+    "strings"
+    "time"
+
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+)
+
+// OpKind is what one step of a Schedule does to a cluster.
+type OpKind int
+
+const (
+    OpPropose   OpKind = iota // Submit new data at a node.
+    OpPartition               // Split the nodes in two groups that cannot reach each other.
+    OpIsolate                 // Cut one node off from everyone else.
+    OpHeal                    // Restore every cut link and clear injected faults.
+    OpLossy                   // Drop, duplicate and reorder a share of all messages until the next OpHeal.
+    opKinds                   // Number of kinds; not an operation.
+)
+
+// Op is one step of a Schedule. Node and Side name nodes by position, taken modulo the size of the cluster, so
+// a schedule applies to a cluster of any size.
+type Op struct {
+    Kind OpKind
+    Node int           // Node that proposes or is isolated.
+    Side uint          // For OpPartition, the nodes whose bit is set form one group and the rest the other.
+    Rate float64       // For OpLossy, the probability a message is dropped; duplication and reordering follow it.
+    Wait time.Duration // Virtual time the cluster runs after the step.
+}
+
+// String describes the step, so a failing schedule reads as a script.
+func (op Op) String() string {
+    switch op.Kind {
+    case OpPropose:
+        return fmt.Sprintf("propose at %d, run %v", op.Node, op.Wait)
+    case OpPartition:
+        return fmt.Sprintf("partition %b, run %v", op.Side, op.Wait)
+    case OpIsolate:
+        return fmt.Sprintf("isolate %d, run %v", op.Node, op.Wait)
+    case OpHeal:
+        return fmt.Sprintf("heal, run %v", op.Wait)
+    default:
+        return fmt.Sprintf("lose %.0f%%, run %v", op.Rate*100, op.Wait)
+    }
+}
+
+// Schedule is a random run: the seed a cluster is built with and the operations and faults it goes through.
+// It implements quick.Generator, so testing/quick can produce schedules and report the one that broke a
+// property.
+type Schedule struct {
+    Seed int64
+    Ops  []Op
+}
+
+// GoString lists the seed and the steps of the schedule. testing/quick prints failing inputs with %#v, so this is
+// what a test reports when a property breaks.
+func (s Schedule) GoString() string {
+    var b strings.Builder
+    fmt.Fprintf(&b, "seed %d", s.Seed)
+    for _, op := range s.Ops {
+        b.WriteString("; ")
+        b.WriteString(op.String())
+    }
+    return b.String()
+}
+
+// maxWait is the longest virtual time a generated step runs for.
+const maxWait = 500 * time.Millisecond
+
+// Generate returns a random schedule of up to size steps, about half of them proposals.
+func (Schedule) Generate(r *rand.Rand, size int) reflect.Value {
+    s := Schedule{Seed: r.Int63(), Ops: make([]Op, 1+r.Intn(max(size, 1)))}
+    for i := range s.Ops {
+        kind := OpPropose
+        if r.Intn(2) == 0 {
+            kind = OpKind(r.Intn(int(opKinds)))
+        }
+        s.Ops[i] = Op{
+            Kind: kind,
+            Node: r.Intn(64),
+            Side: uint(r.Uint32()),
+            Rate: r.Float64() * 0.3,
+            Wait: time.Duration(r.Int63n(int64(maxWait))),
+        }
+    }
+    return reflect.ValueOf(s)
+}
+
+// settleTime is how long Play lets a cluster run once the schedule is over and the network has been healed.
+const settleTime = 5 * time.Second
+
+// Play runs the schedule on c, then heals the network and lets the cluster settle, and returns every
+// violation of the safety properties a finality-providing algorithm such as Raft or PBFT must keep:
+//
+//   - Agreement: no two replicas commit different blocks at the same height.
+//   - Validity: every committed block, other than the genesis block, carries data that was proposed.
+//   - Integrity: no replica commits the same proposal twice.
+//
+// Proposals are numbered, so each one is distinct. Play takes over c.OnCommit while it runs.
+func Play[R node.Replica](c *Cluster[R], s Schedule) []string {
+    var violations []string
+    proposed := make(map[string]bool)
+    first := make(map[int64]*wire.Block)    // First block committed at each height, by any replica.
+    seen := make(map[int32]map[string]bool) // Data each replica has committed.
+    c.OnCommit = func(id int32, block *wire.Block) {
+        if prev, ok := first[block.GetIndex()]; !ok {
+            first[block.GetIndex()] = block
+        } else if prev.GetHash() != block.GetHash() {
+            violations = append(violations, fmt.Sprintf("agreement: node %d committed %.12s at height %d, another node %.12s",
+                id, block.GetHash(), block.GetIndex(), prev.GetHash()))
+        }
+        if block.GetIndex() == 0 {
+            return
+        }
+        if !proposed[block.GetData()] {
+            violations = append(violations, fmt.Sprintf("validity: node %d committed %q, which was never proposed", id, block.GetData()))
+        }
+        if seen[id] == nil {
+            seen[id] = make(map[string]bool)
+        }
+        if seen[id][block.GetData()] {
+            violations = append(violations, fmt.Sprintf("integrity: node %d committed %q twice", id, block.GetData()))
+        }
+        seen[id][block.GetData()] = true
+    }
+    defer func() { c.OnCommit = nil }()
+
+    nodes := c.Nodes()
+    for i, op := range s.Ops {
+        at := nodes[op.Node%len(nodes)]
+        switch op.Kind {
+        case OpPropose:
+            data := fmt.Sprintf("Proposal %d", i)
+            proposed[data] = true
+            c.Simulator.Propose(at, data) // Rejections, such as by a follower, are part of the run.
+        case OpPartition:
+            var side, rest []int32
+            for j, id := range nodes {
+                if op.Side&(1<<j) != 0 {
+                    side = append(side, id)
+                } else {
+                    rest = append(rest, id)
+                }
+            }
+            c.Network.Partition(side, rest)
+        case OpIsolate:
+            c.Isolate(at)
+        case OpHeal:
+            c.Network.Heal()
+            c.Network.ClearFaults()
+        case OpLossy:
+            c.Network.Inject(sim.Fault{Drop: op.Rate, Duplicate: op.Rate, Reorder: op.Rate})
+        }
+        c.RunFor(op.Wait)
+    }
+    c.Network.Heal()
+    c.Network.ClearFaults()
+    c.RunFor(settleTime)
+    return violations
+}
@@ -0,0 +1,217 @@
+// Package testutil gathers the setup and checks that tests of this repository kept writing by hand: building a
+// small simulated cluster of any algorithm, driving it on virtual time, cutting nodes off to model faults, and
+// comparing the chains the nodes end up with. A test describes the network it needs in a line,
+//
+//  c := testutil.Raft(sim.Link{Latency: sim.Constant(5 * time.Millisecond)}, options.WithNodes(5), options.WithSeed(11))
+//  leader, ok := c.WaitForLeader(10 * time.Second)
+//
+// and spends the rest of its length on the behavior under test. Clusters are simulations (see package sim), so
+// a test built from the same seed runs the same way every time and never sleeps. Engine and NewClock cover the
+// other style of test, which drives an engine.Engine directly and controls its timestamps with a fake clock.
+package testutil
+
+import (
+    "testing"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/dpos"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/engine"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/options"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+)
+
+// NewClock returns a fake clock standing still at sim.Epoch until it is advanced, so engines driven by hand and
+// simulations start their chains at the same moment.
+func NewClock() *clock.Mock {
+    return clock.NewMock(sim.Epoch)
+}
+
+// Engine builds an engine for algorithm on a fake clock and fails the test if it cannot. options.WithNodes sets
+// the number of participants; every option is also handed on through engine.Config.Options.
+func Engine(t testing.TB, algorithm string, opts ...options.Option) (engine.Engine, *clock.Mock) {
+    t.Helper()
+    mock := NewClock()
+    e, err := engine.New(algorithm, engine.Config{Nodes: options.New(opts...).Nodes, Clock: mock, Options: opts})
+    if err != nil {
+        t.Fatalf("testutil: %v", err)
+    }
+    return e, mock
+}
+
+// Cluster is a simulated network of replicas of one algorithm. The embedded Simulator drives it: RunFor,
+// RunUntil and Propose advance and feed the whole cluster, and Network partitions or degrades its links.
+type Cluster[R node.Replica] struct {
+    *sim.Simulator
+    Replicas []R // Every replica, in the order of their identifiers 0, 1, 2 and so on.
+}
+
+// newCluster builds a cluster of the size options.WithNodes asks for, seeded with options.WithSeed, and
+// isolates the last options.WithFaulty replicas. build creates the replica with the given identifier.
+func newCluster[R node.Replica](link sim.Link, opts []options.Option, build func(s *sim.Simulator, id int32, peers []int32) R) *Cluster[R] {
+    o := options.New(opts...)
+    c := &Cluster[R]{Simulator: sim.New(sim.Config{Seed: o.Seed, Network: link})}
+    peers := make([]int32, o.Nodes)
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    for _, id := range peers {
+        replica := build(c.Simulator, id, peers)
+        c.Replicas = append(c.Replicas, replica)
+        c.Add(replica)
+    }
+    c.Isolate(peers[o.Nodes-o.Faulty:]...)
+    return c
+}
+
+// Raft builds a cluster of Raft replicas whose election timeouts are drawn from the simulation's seed.
+func Raft(link sim.Link, opts ...options.Option) *Cluster[*raft.Replica] {
+    return newCluster(link, opts, func(s *sim.Simulator, id int32, peers []int32) *raft.Replica {
+        return raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: peers, Rand: s.NewRand(), Clock: s.Clock()})
+    })
+}
+
+// PBFT builds a PBFT group; replica 0 is the primary of the first view.
+func PBFT(link sim.Link, opts ...options.Option) *Cluster[*pbft.Replica] {
+    return newCluster(link, opts, func(s *sim.Simulator, id int32, peers []int32) *pbft.Replica {
+        return pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: peers, Clock: s.Clock()})
+    })
+}
+
+// PoW builds a network of Proof of Work miners with equal hash power.
+func PoW(link sim.Link, opts ...options.Option) *Cluster[*pow.Miner] {
+    return newCluster(link, opts, func(s *sim.Simulator, id int32, peers []int32) *pow.Miner {
+        return pow.NewMiner(pow.MinerConfig{ID: id, Peers: peers, Rand: s.NewRand(), Clock: s.Clock()})
+    })
+}
+
+// PoS builds a network of Proof of Stake validators holding the given stakes, or a stake of 1 each if stakes
+// is nil. Validators missing from a non-nil stakes map hold nothing and never propose.
+func PoS(link sim.Link, stakes map[int32]int, opts ...options.Option) *Cluster[*pos.Validator] {
+    return newCluster(link, opts, func(s *sim.Simulator, id int32, peers []int32) *pos.Validator {
+        if stakes == nil {
+            stakes = make(map[int32]int, len(peers))
+            for _, peer := range peers {
+                stakes[peer] = 1
+            }
+        }
+        return pos.NewValidator(pos.ValidatorConfig{ID: id, Peers: peers, Stakes: stakes, Clock: s.Clock()})
+    })
+}
+
+// DPoS builds a network in which every node is an elected delegate producing blocks in turn.
+func DPoS(link sim.Link, opts ...options.Option) *Cluster[*dpos.Delegate] {
+    return newCluster(link, opts, func(s *sim.Simulator, id int32, peers []int32) *dpos.Delegate {
+        return dpos.NewDelegate(dpos.DelegateConfig{ID: id, Peers: peers, Clock: s.Clock()})
+    })
+}
+
+// Isolate cuts every link between the given nodes and the rest of the cluster, which to its peers looks the
+// same as the nodes having crashed. Network.Heal brings them back.
+func (c *Cluster[R]) Isolate(ids ...int32) {
+    for _, id := range ids {
+        for _, other := range c.Nodes() {
+            if other != id {
+                c.Network.Disconnect(id, other)
+            }
+        }
+    }
+}
+
+// Leader returns the replica that considers itself the leader. If several do, as a stale leader cut off in a
+// minority still does, the one followed by the most replicas wins. It reports false if no replica leads or the
+// algorithm has no leader.
+func (c *Cluster[R]) Leader() (R, bool) {
+    var leader R
+    found, best := false, 0
+    for _, candidate := range c.Replicas {
+        if l, ok := node.Replica(candidate).(node.Leaderful); !ok || l.Leader() != candidate.ID() {
+            continue
+        }
+        followers := 0
+        for _, r := range c.Replicas {
+            if l, ok := node.Replica(r).(node.Leaderful); ok && l.Leader() == candidate.ID() {
+                followers++
+            }
+        }
+        if followers > best {
+            leader, found, best = candidate, true, followers
+        }
+    }
+    return leader, found
+}
+
+// WaitForLeader runs the cluster until some replica leads, for at most within of virtual time.
+func (c *Cluster[R]) WaitForLeader(within time.Duration) (R, bool) {
+    c.RunUntil(func() bool {
+        _, ok := c.Leader()
+        return ok
+    }, c.Now()+within)
+    return c.Leader()
+}
+
+// AllCommitted returns a condition for RunUntil that holds once every replica has committed at least n blocks,
+// counting the genesis block.
+func (c *Cluster[R]) AllCommitted(n int) func() bool {
+    return func() bool {
+        for _, r := range c.Replicas {
+            if len(r.Committed()) < n {
+                return false
+            }
+        }
+        return true
+    }
+}
+
+// Chains returns the blocks each replica has committed, in the order of Replicas.
+func (c *Cluster[R]) Chains() [][]*wire.Block {
+    chains := make([][]*wire.Block, len(c.Replicas))
+    for i, r := range c.Replicas {
+        chains[i] = r.Committed()
+    }
+    return chains
+}
+
+// AssertAgreement fails the test if two chains hold different blocks at the same height. Chains may have
+// different lengths: a replica that is behind agrees as long as what it has matches the others.
+func AssertAgreement(t testing.TB, chains [][]*wire.Block) {
+    t.Helper()
+    for i, chain := range chains {
+        for j := i + 1; j < len(chains); j++ {
+            if d := wire.Diff(chain, chains[j]); d.Conflicting() {
+                t.Errorf("Chains %d and %d disagree at height %d: %.12s and %.12s", i, j, d.Height, d.A.GetHash(), d.B.GetHash())
+            }
+        }
+    }
+}
+
+// AssertSameChain fails the test unless got holds exactly the blocks of want.
+func AssertSameChain(t testing.TB, want, got []*wire.Block) {
+    t.Helper()
+    if d := wire.Diff(want, got); !d.Same() {
+        t.Errorf("Chains differ (a is the expected chain, b the one got): %s", d)
+    }
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **An Ordinary Package**: The helpers live outside the tests directory, in a package of their own, so tests
+//    of any package can import them and the examples can use the same builders to set up a demonstration.
+//
+// 2. **Built on the Simulator**: Every cluster runs in package sim rather than over goroutines and sockets. A
+//    test gets virtual time, scripted faults and reproducible runs for free, and nothing here needs a timeout.
+//
+// 3. **Typed Clusters**: Cluster is generic over the replica type, so a test reaches the algorithm-specific
+//    methods of its replicas, such as a Raft replica's term or a miner's reorganizations, without assertions.
+//
+// 4. **Assertions Report, Not Stop**: The Assert functions call Errorf rather than Fatalf, so one run reports
+//    every disagreement it finds; tests that cannot continue after a failure check the result themselves.
+//
+// 5. **Schedules as Data**: A random run is a plain Schedule value rather than a generator callback, so
+//    testing/quick can produce it and print it, and a failure found once can be pasted into a test and replayed.
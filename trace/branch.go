@@ -0,0 +1,76 @@
+package trace
+
+import (
+    "slices"
+
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+
+    "google.golang.org/protobuf/proto"
+)
+
+// BranchConfig describes the simulation a branch continues in.
+type BranchConfig struct {
+    Sim  sim.Config                    // Seed and network of the continuation; Start is set to the branch point.
+    Lose func(env *wire.Envelope) bool // Selects envelopes in flight at the branch point to lose; nil loses none.
+}
+
+// Branch is a what-if continuation of a recorded run: the replicas as they were at one point of the trace,
+// moved into a live simulation that can be driven, partitioned and proposed to like any other. The Recorder
+// goes on recording, so its Trace holds the steps replayed up to the branch point followed by the new ones, and
+// replays like a trace recorded in one go.
+type Branch struct {
+    *Recorder
+    Sim      *sim.Simulator // Simulation the replicas continue in, starting at the time of the branch point.
+    InFlight int            // Envelopes sent before the branch point that arrive after it, as recorded.
+    Lost     int            // Envelopes in flight that BranchConfig.Lose chose to lose.
+}
+
+// Branch continues the run from the current position of the replay in a new simulation. The replicas are
+// replayed up to the position once more, so r itself is left where it is, and every envelope the recording
+// shows in flight at that point is delivered at the time it arrived in the recording, unless cfg.Lose selects
+// it; envelopes the recording lost stay lost. From then on the continuation takes its own course: ticks, latencies
+// and losses are drawn from cfg.Sim, so even a branch that changes nothing soon departs from the recording.
+func (r *Replayer) Branch(cfg BranchConfig) (*Branch, error) {
+    replay := &Replayer{trace: r.trace, build: r.build}
+    replay.reset()
+    if err := replay.Seek(r.position); err != nil {
+        return nil, err
+    }
+    export, err := r.trace.Export()
+    if err != nil {
+        return nil, err
+    }
+
+    cfg.Sim.Start = replay.At()
+    s := sim.New(cfg.Sim)
+    replay.live = s
+    prefix := *r.trace
+    prefix.Steps = slices.Clone(r.trace.Steps[:r.position])
+    b := &Branch{Recorder: &Recorder{trace: prefix, replicas: replay.replicas, sent: make(map[*wire.Envelope]int)}, Sim: s}
+    for _, replica := range replay.replicas {
+        s.Add(replica)
+    }
+
+    first := make([]int, r.position+1) // Number of the first envelope each step sent, as Record counts them.
+    for i, step := range prefix.Steps {
+        first[i+1] = first[i] + len(step.Output)
+    }
+    b.outputs = first[r.position]
+    for _, m := range export.Messages {
+        if m.SentStep == nil || *m.SentStep >= r.position || m.ReceivedStep == nil || *m.ReceivedStep < r.position {
+            continue // Received before the branch point, lost, or sent after it.
+        }
+        k := slices.Index(export.Transitions[*m.SentStep].Sent, m.ID)
+        env := proto.Clone(prefix.Steps[*m.SentStep].Output[k]).(*wire.Envelope)
+        if cfg.Lose != nil && cfg.Lose(env) {
+            b.Lost++
+            continue
+        }
+        b.sent[env] = first[*m.SentStep] + k
+        s.Deliver(env, r.trace.Steps[*m.ReceivedStep].At)
+        b.InFlight++
+    }
+    s.OnTransition = b.record
+    return b, nil
+}
@@ -0,0 +1,166 @@
+package trace
+
+import (
+    _ "embed"
+    "encoding/json"
+    "io"
+
+    "consensus-algorithms-edu/wire"
+
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/proto"
+)
+
+// ExportVersion is the version of the export format that Export writes. It changes only when a field is
+// removed or changes meaning; new fields may appear without it changing.
+const ExportVersion = 1
+
+// Schema is the JSON Schema of an export, the file schema.json of this package. It documents every field for
+// tools outside Go and can validate an export before it is analyzed.
+//
+//go:embed schema.json
+var Schema []byte
+
+// Export is a trace arranged for analysis outside Go: every message with the times it was sent and received,
+// and every step as a transition from one state to the next. Times are seconds of virtual time since the
+// start of the run, so tools that know nothing of Go durations can use them as they are.
+type Export struct {
+    Version     int          `json:"version"`     // ExportVersion.
+    Algorithm   string       `json:"algorithm"`
+    Nodes       int          `json:"nodes"`
+    Seed        int64        `json:"seed"`
+    Initial     []State      `json:"initial"`     // State of every replica before the first step.
+    Messages    []Message    `json:"messages"`    // Every copy of every message, in the order sent; duplicates as received.
+    Transitions []Transition `json:"transitions"` // Every step of the trace, in the order the simulation executed them.
+}
+
+// Message is one copy of a message: sent by one step and received by another, or never received. A message the
+// network duplicated appears once per copy received, with the same ID.
+type Message struct {
+    ID           int             `json:"id"`            // Number of the message, from 0 in the order messages were sent.
+    Copy         int             `json:"copy"`          // 0 for the first copy received, 1 and up for duplicates.
+    From         int32           `json:"from"`
+    To           int32           `json:"to"`
+    Kind         string          `json:"kind"`          // Type of the body, e.g. "AppendEntries".
+    Size         int             `json:"size"`          // Bytes of the envelope in the Protocol Buffers encoding.
+    SentAt       *float64        `json:"sent_at"`       // Nil only for a delivery the trace never saw sent.
+    SentStep     *int            `json:"sent_step"`     // Index of the transition that sent it.
+    ReceivedAt   *float64        `json:"received_at"`   // Nil if the message was lost or still in flight when the run ended.
+    ReceivedStep *int            `json:"received_step"` // Index of the transition that received it.
+    Envelope     json.RawMessage `json:"envelope"`      // The envelope in the JSON mapping of Protocol Buffers.
+}
+
+// Transition is one step of the trace: an input a replica took and the state it went from and to.
+type Transition struct {
+    Step    int     `json:"step"`            // Index of the step in the trace.
+    At      float64 `json:"at"`
+    Node    int32   `json:"node"`
+    Input   string  `json:"input"`           // "tick", "deliver" or "propose".
+    Message *int    `json:"message"`         // ID of the message received, for a delivery.
+    Data    string  `json:"data,omitempty"`  // Data proposed, for a proposal.
+    Error   string  `json:"error,omitempty"` // Error the replica rejected a proposal with.
+    Sent    []int   `json:"sent"`            // IDs of the messages the replica sent in response.
+    Before  State   `json:"before"`
+    After   State   `json:"after"`
+    Changed bool    `json:"changed"`         // Whether the step changed the replica's state at all.
+}
+
+// Export arranges the trace for analysis. A delivery is tied to the message it received by the number Record
+// gave it. In traces without one, such as those of gossiped copies, deliveries are matched to sends by sender,
+// recipient and content, the oldest unmatched send first; identical messages between the same two replicas are
+// then indistinguishable, so two of them may be matched in either order.
+func (t *Trace) Export() (*Export, error) {
+    e := &Export{Version: ExportVersion, Algorithm: t.Algorithm, Nodes: t.Nodes, Seed: t.Seed, Messages: []Message{}, Transitions: []Transition{}}
+    e.Initial = append([]State(nil), t.Initial...)
+    states := append([]State(nil), t.Initial...)
+    var sends []int                   // Row of every envelope sent, by its number in the trace.
+    pending := make(map[string][]int) // Rows of envelopes sent, by content, for deliveries without a number.
+    received := make(map[string]int)  // Row of the last envelope received, by content, for duplicates without a number.
+    copies := make(map[int]int)       // Copies of each message received so far, by ID.
+    ids := 0
+    for i, step := range t.Steps {
+        at, index := step.At.Seconds(), i
+        tr := Transition{Step: i, At: at, Node: step.Node, Input: string(step.Input), Data: step.Data, Error: step.Err, Sent: []int{}}
+        if step.Envelope != nil {
+            key, err := messageKey(step.Envelope)
+            if err != nil {
+                return nil, err
+            }
+            row := -1
+            if step.Message >= 0 && step.Message < len(sends) {
+                row = sends[step.Message]
+            } else {
+                queue := pending[key]
+                for len(queue) > 0 && e.Messages[queue[0]].ReceivedAt != nil {
+                    queue = queue[1:] // Received through its number.
+                }
+                if len(queue) > 0 {
+                    row, queue = queue[0], queue[1:]
+                } else if last, ok := received[key]; ok {
+                    row = last
+                }
+                pending[key] = queue
+            }
+            if row < 0 {
+                m, err := newMessage(ids, step.Envelope)
+                if err != nil {
+                    return nil, err
+                }
+                ids++
+                e.Messages = append(e.Messages, m)
+                row = len(e.Messages) - 1
+            } else if e.Messages[row].ReceivedAt != nil {
+                e.Messages = append(e.Messages, e.Messages[row]) // A duplicate, received once already.
+                row = len(e.Messages) - 1
+            }
+            m := &e.Messages[row]
+            m.Copy = copies[m.ID]
+            m.ReceivedAt, m.ReceivedStep = &at, &index
+            copies[m.ID]++
+            received[key] = row
+            id := m.ID
+            tr.Message = &id
+        }
+        for _, env := range step.Output {
+            key, err := messageKey(env)
+            if err != nil {
+                return nil, err
+            }
+            m, err := newMessage(ids, env)
+            if err != nil {
+                return nil, err
+            }
+            ids++
+            m.SentAt, m.SentStep = &at, &index
+            sends = append(sends, len(e.Messages))
+            pending[key] = append(pending[key], len(e.Messages))
+            e.Messages = append(e.Messages, m)
+            tr.Sent = append(tr.Sent, m.ID)
+        }
+        tr.Before, tr.After = states[step.Node], step.State
+        tr.Changed = tr.Before != tr.After
+        states[step.Node] = tr.After
+        e.Transitions = append(e.Transitions, tr)
+    }
+    return e, nil
+}
+
+// Write writes the export as a single JSON document.
+func (e *Export) Write(w io.Writer) error {
+    return json.NewEncoder(w).Encode(e)
+}
+
+// newMessage describes an envelope as message id, not yet sent or received.
+func newMessage(id int, env *wire.Envelope) (Message, error) {
+    encoded, err := protojson.Marshal(env)
+    if err != nil {
+        return Message{}, err
+    }
+    return Message{ID: id, From: env.GetFrom(), To: env.GetTo(), Kind: env.Kind(), Size: proto.Size(env), Envelope: encoded}, nil
+}
+
+// messageKey identifies an envelope by its sender, recipient and content.
+func messageKey(env *wire.Envelope) (string, error) {
+    encoded, err := proto.MarshalOptions{Deterministic: true}.Marshal(env)
+    return string(encoded), err
+}
@@ -0,0 +1,193 @@
+package trace
+
+import (
+    "bufio"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "time"
+
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+
+    "google.golang.org/protobuf/encoding/protojson"
+)
+
+// header is the first line of a trace file.
+type header struct {
+    Algorithm string      `json:"algorithm"`
+    Nodes     int         `json:"nodes"`
+    Seed      int64       `json:"seed"`
+    Initial   []stateJSON `json:"initial"`
+}
+
+// stepJSON is a line of a trace file after the header. Envelopes use the canonical JSON mapping of Protocol
+// Buffers, so a trace file stays readable and other tools can decode it.
+type stepJSON struct {
+    At       time.Duration     `json:"at"`
+    Node     int32             `json:"node"`
+    Input    sim.Input         `json:"input"`
+    Envelope json.RawMessage   `json:"envelope,omitempty"`
+    Message  *int              `json:"message,omitempty"` // Absent if unknown, and in traces written before it was recorded.
+    Data     string            `json:"data,omitempty"`
+    Err      string            `json:"err,omitempty"`
+    Output   []json.RawMessage `json:"output,omitempty"`
+    State    stateJSON         `json:"state"`
+}
+
+// stateJSON is a State in a trace file.
+type stateJSON struct {
+    Node        int32  `json:"node"`
+    Height      int    `json:"height"`
+    Head        string `json:"head"`
+    Leader      int32  `json:"leader"`
+    Fingerprint string `json:"fingerprint"`
+}
+
+// Write writes the trace as JSON lines: a header with the algorithm, cluster size, seed and initial states,
+// followed by one line per step.
+func (t *Trace) Write(w io.Writer) error {
+    enc := json.NewEncoder(w)
+    h := header{Algorithm: t.Algorithm, Nodes: t.Nodes, Seed: t.Seed}
+    for _, state := range t.Initial {
+        h.Initial = append(h.Initial, stateJSON(state))
+    }
+    if err := enc.Encode(h); err != nil {
+        return err
+    }
+    for _, step := range t.Steps {
+        line := stepJSON{At: step.At, Node: step.Node, Input: step.Input, Data: step.Data, Err: step.Err, State: stateJSON(step.State)}
+        if step.Envelope != nil && step.Message >= 0 {
+            line.Message = &step.Message
+        }
+        if step.Envelope != nil {
+            encoded, err := protojson.Marshal(step.Envelope)
+            if err != nil {
+                return err
+            }
+            line.Envelope = encoded
+        }
+        for _, env := range step.Output {
+            encoded, err := protojson.Marshal(env)
+            if err != nil {
+                return err
+            }
+            line.Output = append(line.Output, encoded)
+        }
+        if err := enc.Encode(line); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// Read reads a trace written by Write. Envelopes are validated like those arriving over a transport, and a
+// step that names an unknown replica or input is refused.
+func Read(r io.Reader) (*Trace, error) {
+    dec := json.NewDecoder(r)
+    var h header
+    if err := dec.Decode(&h); err != nil {
+        return nil, fmt.Errorf("trace: header: %w", err)
+    }
+    if h.Nodes <= 0 || len(h.Initial) != h.Nodes {
+        return nil, fmt.Errorf("trace: header: %d nodes with %d initial states", h.Nodes, len(h.Initial))
+    }
+    t := &Trace{Algorithm: h.Algorithm, Nodes: h.Nodes, Seed: h.Seed}
+    for _, state := range h.Initial {
+        t.Initial = append(t.Initial, State(state))
+    }
+    for {
+        var line stepJSON
+        err := dec.Decode(&line)
+        if errors.Is(err, io.EOF) {
+            return t, nil
+        }
+        if err != nil {
+            return nil, fmt.Errorf("trace: step %d: %w", len(t.Steps), err)
+        }
+        step, err := line.decode(h.Nodes)
+        if err != nil {
+            return nil, fmt.Errorf("trace: step %d: %w", len(t.Steps), err)
+        }
+        t.Steps = append(t.Steps, step)
+    }
+}
+
+// decode turns a line of a trace file of a cluster of n replicas back into a Step.
+func (line stepJSON) decode(n int) (Step, error) {
+    step := Step{At: line.At, Node: line.Node, Input: line.Input, Message: -1, Data: line.Data, Err: line.Err, State: State(line.State)}
+    if line.Message != nil {
+        step.Message = *line.Message
+    }
+    if step.Node < 0 || int(step.Node) >= n {
+        return Step{}, fmt.Errorf("unknown node %d", step.Node)
+    }
+    switch step.Input {
+    case sim.TickInput, sim.ProposeInput:
+    case sim.DeliverInput:
+        if line.Envelope == nil {
+            return Step{}, errors.New("delivery without an envelope")
+        }
+        env, err := decodeEnvelope(line.Envelope)
+        if err != nil {
+            return Step{}, err
+        }
+        step.Envelope = env
+    default:
+        return Step{}, fmt.Errorf("unknown input %q", step.Input)
+    }
+    for _, encoded := range line.Output {
+        env, err := decodeEnvelope(encoded)
+        if err != nil {
+            return Step{}, err
+        }
+        step.Output = append(step.Output, env)
+    }
+    return step, nil
+}
+
+// decodeEnvelope parses and validates an envelope in its JSON mapping.
+func decodeEnvelope(data []byte) (*wire.Envelope, error) {
+    env := &wire.Envelope{}
+    if err := protojson.Unmarshal(data, env); err != nil {
+        return nil, err
+    }
+    if err := env.Validate(); err != nil {
+        return nil, err
+    }
+    return env, nil
+}
+
+// Save writes the trace to a file, replacing it if it exists.
+func (t *Trace) Save(path string) error {
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    w := bufio.NewWriter(f)
+    if err := t.Write(w); err != nil {
+        f.Close()
+        return err
+    }
+    if err := w.Flush(); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
+
+// Load reads a trace from a file written by Save.
+func Load(path string) (*Trace, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    t, err := Read(bufio.NewReader(f))
+    if err != nil {
+        return nil, fmt.Errorf("%s: %w", path, err)
+    }
+    return t, nil
+}
@@ -0,0 +1,222 @@
+package trace
+
+import (
+    "errors"
+    "fmt"
+    "slices"
+    "strings"
+    "time"
+
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+
+    "google.golang.org/protobuf/proto"
+)
+
+var (
+    // ErrDiverged is returned when a replayed replica does something other than what the trace recorded:
+    // it sends different envelopes, answers a proposal differently or ends up in a different state.
+    ErrDiverged = errors.New("trace: replay diverged from the recording")
+
+    // ErrOutOfRange is returned when a replay is asked to move before the first or past the last step.
+    ErrOutOfRange = errors.New("trace: no such step")
+)
+
+// Replayer steps a fresh cluster through a recorded trace. It is not safe for concurrent use.
+type Replayer struct {
+    trace    *Trace
+    build    Builder
+    clock    *clock.Mock
+    replicas []node.Replica
+    position int            // Steps applied so far.
+    live     *sim.Simulator // Simulation the replicas continue in once branched; nil while they are replayed.
+}
+
+// NewReplayer prepares a replay of t, positioned before its first step. build creates the replicas; it must be
+// the constructor the run was recorded with, and may be nil for the algorithms in Builders. The initial state
+// of every replica is checked against the trace.
+func NewReplayer(t *Trace, build Builder) (*Replayer, error) {
+    build, err := builder(t.Algorithm, build)
+    if err != nil {
+        return nil, err
+    }
+    r := &Replayer{trace: t, build: build}
+    r.reset()
+    for id, replica := range r.replicas {
+        if err := compare(t.Initial[id], Describe(replica)); err != nil {
+            return nil, fmt.Errorf("%w before the first step: %v", ErrDiverged, err)
+        }
+    }
+    return r, nil
+}
+
+// Len returns the number of steps in the trace.
+func (r *Replayer) Len() int {
+    return len(r.trace.Steps)
+}
+
+// Position returns the number of steps applied so far: 0 before the first step, Len after the last.
+func (r *Replayer) Position() int {
+    return r.position
+}
+
+// Replicas returns the replayed replicas in their current state, in the order of their identifiers. They are
+// rebuilt when the replay moves backward, so do not hold on to them across Back or Seek.
+func (r *Replayer) Replicas() []node.Replica {
+    return r.replicas
+}
+
+// States summarizes the current state of every replica.
+func (r *Replayer) States() []State {
+    states := make([]State, len(r.replicas))
+    for i, replica := range r.replicas {
+        states[i] = Describe(replica)
+    }
+    return states
+}
+
+// Forward applies the next step and returns it. It returns ErrOutOfRange after the last step, and
+// ErrDiverged if the replica did not do what the trace recorded; the step is applied either way.
+func (r *Replayer) Forward() (Step, error) {
+    if r.position >= len(r.trace.Steps) {
+        return Step{}, ErrOutOfRange
+    }
+    step := r.trace.Steps[r.position]
+    r.position++
+    if err := r.apply(step); err != nil {
+        return step, fmt.Errorf("%w at step %d: %v", ErrDiverged, r.position-1, err)
+    }
+    return step, nil
+}
+
+// Back undoes the last step applied and returns it. Replicas cannot be rolled back, so the cluster is rebuilt
+// and replayed up to the step before; this costs as much as replaying the trace that far.
+func (r *Replayer) Back() (Step, error) {
+    if r.position == 0 {
+        return Step{}, ErrOutOfRange
+    }
+    step := r.trace.Steps[r.position-1]
+    return step, r.Seek(r.position - 1)
+}
+
+// Seek moves the replay to just after step n-1, so that Position returns n, replaying forward from where it
+// is or from the start.
+func (r *Replayer) Seek(n int) error {
+    if n < 0 || n > len(r.trace.Steps) {
+        return ErrOutOfRange
+    }
+    if n < r.position {
+        r.reset()
+    }
+    for r.position < n {
+        if _, err := r.Forward(); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// At returns the virtual time of the last step applied, or 0 before the first.
+func (r *Replayer) At() time.Duration {
+    if r.position == 0 {
+        return 0
+    }
+    return r.trace.Steps[r.position-1].At
+}
+
+// SeekTime moves the replay to the instant at of the run: just after the last step taken at or before at, so
+// the replicas are in the state they were in at that moment.
+func (r *Replayer) SeekTime(at time.Duration) error {
+    if at < 0 {
+        return ErrOutOfRange
+    }
+    n, _ := slices.BinarySearchFunc(r.trace.Steps, at, func(step Step, at time.Duration) int {
+        if step.At <= at {
+            return -1
+        }
+        return 1
+    })
+    return r.Seek(n)
+}
+
+// reset rebuilds the cluster in its initial state.
+func (r *Replayer) reset() {
+    r.clock = clock.NewMock(sim.Epoch)
+    r.replicas = newCluster(r.trace.Nodes, r.trace.Seed, r.build, func() clock.Clock {
+        if r.live != nil {
+            return r.live.Clock()
+        }
+        return r.clock
+    })
+    r.position = 0
+}
+
+// apply feeds a recorded input to its replica at the recorded time and compares the outcome with the trace.
+func (r *Replayer) apply(step Step) error {
+    r.clock.Set(sim.Epoch.Add(step.At))
+    replica := r.replicas[step.Node]
+    var got Step
+    switch step.Input {
+    case sim.TickInput:
+        got.Output = replica.Tick()
+    case sim.DeliverInput:
+        got.Output = replica.Step(step.Envelope)
+    case sim.ProposeInput:
+        out, err := replica.Propose(step.Data)
+        got.Output = out
+        if err != nil {
+            got.Err = err.Error()
+        }
+    }
+    if got.Err != step.Err {
+        return fmt.Errorf("proposal answered with %q instead of %q", got.Err, step.Err)
+    }
+    if !sameEnvelopes(got, step) {
+        return fmt.Errorf("sent %s instead of %s", kinds(got), kinds(step))
+    }
+    return compare(step.State, Describe(replica))
+}
+
+// sameEnvelopes reports whether two steps sent the same envelopes in the same order.
+func sameEnvelopes(a, b Step) bool {
+    if len(a.Output) != len(b.Output) {
+        return false
+    }
+    for i := range a.Output {
+        if !proto.Equal(a.Output[i], b.Output[i]) {
+            return false
+        }
+    }
+    return true
+}
+
+// compare reports how the state a replay reached differs from the recorded one, if it does.
+func compare(want, got State) error {
+    if want != got {
+        return fmt.Errorf("node %d at height %d with fingerprint %s instead of height %d with fingerprint %s",
+            got.Node, got.Height, got.Fingerprint, want.Height, want.Fingerprint)
+    }
+    return nil
+}
+
+// kinds lists the kinds of envelopes a step sent, for error messages.
+func kinds(step Step) string {
+    if len(step.Output) == 0 {
+        return "nothing"
+    }
+    names := make([]string, len(step.Output))
+    for i, env := range step.Output {
+        names[i] = fmt.Sprintf("%s to %d", env.Kind(), env.GetTo())
+    }
+    return "[" + strings.Join(names, ", ") + "]"
+}
+
+// String describes a step in one line, the way sim.Transition.String does.
+func (s Step) String() string {
+    t := sim.Transition{At: s.At, Node: s.Node, Input: s.Input, Envelope: s.Envelope, Data: s.Data, Output: s.Output}
+    if s.Err != "" {
+        t.Err = errors.New(s.Err)
+    }
+    return t.String()
+}
@@ -0,0 +1,228 @@
+// Package trace records what every replica of a simulated run did, step by step, and replays it. A Recorder
+// hooks into a sim.Simulator and writes down each input a replica took — a tick, a delivered message or a
+// proposal — together with the messages it sent in response and a summary of the state it was left in. The
+// trace can be saved as a file, one JSON line per step, and read back later.
+//
+// A Replayer rebuilds the cluster from scratch and feeds it the recorded inputs one at a time, forward or
+// backward, so a run can be examined the way a debugger steps through a program: which message made a Raft
+// follower change its term, what a PBFT replica knew when it sent its commit, where two chains diverged. The
+// replicas are the real ones, not a model, and every replayed step is checked against the recording, so a
+// replay that stays quiet reproduced the run exactly. A trace names its algorithm, so the built-in ones replay
+// from the file alone. A replay can also jump to an instant of the run and branch from there into a live
+// simulation, to see what would have happened had a leader crashed or a message been lost. Export arranges a trace as tables of messages and state transitions in a documented JSON
+// format, for analysis with tools outside Go.
+package trace
+
+import (
+    "errors"
+    "math/rand"
+    "time"
+
+    "consensus-algorithms-edu/algorithms/dpos"
+    "consensus-algorithms-edu/algorithms/pbft"
+    "consensus-algorithms-edu/algorithms/pos"
+    "consensus-algorithms-edu/algorithms/pow"
+    "consensus-algorithms-edu/algorithms/raft"
+    "consensus-algorithms-edu/clock"
+    "consensus-algorithms-edu/modelcheck"
+    "consensus-algorithms-edu/node"
+    "consensus-algorithms-edu/sim"
+    "consensus-algorithms-edu/wire"
+
+    "google.golang.org/protobuf/proto"
+)
+
+// ErrUnknownAlgorithm is returned when a trace must be recorded or replayed without a replica constructor
+// and its algorithm is not one of Builders.
+var ErrUnknownAlgorithm = errors.New("trace: unknown algorithm")
+
+// Builder creates a replica of a recorded cluster. It must take its clock and randomness from env, which is
+// what makes a replay draw the same timestamps and random numbers as the run it reproduces.
+type Builder func(id int32, peers []int32, env modelcheck.Env) node.Replica
+
+// Builders holds the replica constructors of the algorithms that run as node.Replica, by name. Traces of
+// these algorithms replay without being given a constructor.
+var Builders = map[string]Builder{
+    "raft": func(id int32, peers []int32, env modelcheck.Env) node.Replica {
+        return raft.NewReplica(raft.ReplicaConfig{ID: id, Peers: peers, Rand: env.Rand, Clock: env.Clock})
+    },
+    "pbft": func(id int32, peers []int32, env modelcheck.Env) node.Replica {
+        return pbft.NewReplica(pbft.ReplicaConfig{ID: id, Peers: peers, Clock: env.Clock})
+    },
+    "pow": func(id int32, peers []int32, env modelcheck.Env) node.Replica {
+        return pow.NewMiner(pow.MinerConfig{ID: id, Peers: peers, Rand: env.Rand, Clock: env.Clock})
+    },
+    "pos": func(id int32, peers []int32, env modelcheck.Env) node.Replica {
+        stakes := make(map[int32]int, len(peers))
+        for _, peer := range peers {
+            stakes[peer] = 1
+        }
+        return pos.NewValidator(pos.ValidatorConfig{ID: id, Peers: peers, Stakes: stakes, Clock: env.Clock})
+    },
+    "dpos": func(id int32, peers []int32, env modelcheck.Env) node.Replica {
+        return dpos.NewDelegate(dpos.DelegateConfig{ID: id, Peers: peers, Clock: env.Clock})
+    },
+}
+
+// Trace is a recorded run.
+type Trace struct {
+    Algorithm string  // Algorithm the replicas ran.
+    Nodes     int     // Replicas in the cluster, with identifiers 0 to Nodes-1.
+    Seed      int64   // Seed of the replicas' random sources.
+    Initial   []State // State of every replica before the first step.
+    Steps     []Step  // Every input a replica took, in the order the simulation executed them.
+}
+
+// Step is one input a replica took, what it sent in response and the state it was left in.
+type Step struct {
+    At       time.Duration    // Virtual time of the input.
+    Node     int32            // Replica that took the input.
+    Input    sim.Input        // Kind of input: a tick, a delivered envelope or a proposal.
+    Envelope *wire.Envelope   // Envelope delivered, for a delivery.
+    Message  int              // For a delivery, the envelope's number among the Output of earlier steps, or -1 if unknown.
+    Data     string           // Data proposed, for a proposal.
+    Err      string           // Error the replica rejected a proposal with, if it did.
+    Output   []*wire.Envelope // Envelopes the replica sent in response, whether or not the network delivered them.
+    State    State            // State of the replica after the step.
+}
+
+// State summarizes the state of one replica.
+type State struct {
+    Node        int32  `json:"node"`        // Identifier of the replica.
+    Height      int    `json:"height"`      // Height of the last committed block; 0 while only the genesis block is committed.
+    Head        string `json:"head"`        // Hash of the last committed block.
+    Leader      int32  `json:"leader"`      // Leader the replica follows, or -1 if it knows none or its algorithm has no leader.
+    Fingerprint string `json:"fingerprint"` // Digest of the replica's complete state, as computed by modelcheck.Fingerprint.
+}
+
+// Describe summarizes the state of replica.
+func Describe(replica node.Replica) State {
+    state := State{Node: replica.ID(), Leader: -1, Fingerprint: modelcheck.Fingerprint(replica)}
+    if committed := replica.Committed(); len(committed) > 0 {
+        head := committed[len(committed)-1]
+        state.Height, state.Head = int(head.GetIndex()), head.GetHash()
+    }
+    if l, ok := replica.(node.Leaderful); ok {
+        state.Leader = l.Leader()
+    }
+    return state
+}
+
+// Config describes the cluster a run is recorded from.
+type Config struct {
+    Algorithm string  // Algorithm stored in the trace; selects the constructor from Builders if New is nil.
+    Nodes     int     // Replicas in the cluster, with identifiers 0 to Nodes-1.
+    Seed      int64   // Seed of the replicas' random sources, stored in the trace for the replay.
+    New       Builder // Builds each replica; Builders[Algorithm] if nil.
+}
+
+// Recorder builds a cluster inside a simulation and records every step its replicas take.
+type Recorder struct {
+    trace    Trace
+    replicas []node.Replica
+    sent     map[*wire.Envelope]int // Number of every envelope sent, counted across the Output of all steps.
+    outputs  int                    // Envelopes sent so far.
+}
+
+// Record builds the cluster cfg describes, adds its replicas to s and records their steps from then on through
+// s.OnTransition. Whatever drives s — RunFor, Propose, partitions — ends up in the trace.
+func Record(s *sim.Simulator, cfg Config) (*Recorder, error) {
+    build, err := builder(cfg.Algorithm, cfg.New)
+    if err != nil {
+        return nil, err
+    }
+    r := &Recorder{trace: Trace{Algorithm: cfg.Algorithm, Nodes: cfg.Nodes, Seed: cfg.Seed}, sent: make(map[*wire.Envelope]int)}
+    r.replicas = newCluster(cfg.Nodes, cfg.Seed, build, s.Clock)
+    for _, replica := range r.replicas {
+        s.Add(replica)
+        r.trace.Initial = append(r.trace.Initial, Describe(replica))
+    }
+    s.OnTransition = r.record
+    return r, nil
+}
+
+// Replicas returns the recorded replicas, in the order of their identifiers.
+func (r *Recorder) Replicas() []node.Replica {
+    return r.replicas
+}
+
+// Trace returns the run recorded so far.
+func (r *Recorder) Trace() *Trace {
+    t := r.trace
+    return &t
+}
+
+// record appends a transition of the simulation to the trace. Envelopes are copied, so the trace does not
+// change if a replica later reuses them. A delivery is tied to the envelope sent by the simulator handing over
+// the very envelope the sender produced; copies a gossip relay rebuilt cannot be told apart and stay unknown.
+func (r *Recorder) record(t sim.Transition) {
+    step := Step{At: t.At, Node: t.Node, Input: t.Input, Message: -1, Data: t.Data, State: Describe(r.replicas[t.Node])}
+    if t.Envelope != nil {
+        step.Envelope = proto.Clone(t.Envelope).(*wire.Envelope)
+        if n, ok := r.sent[t.Envelope]; ok {
+            step.Message = n
+        }
+    }
+    if t.Err != nil {
+        step.Err = t.Err.Error()
+    }
+    for _, env := range t.Output {
+        r.sent[env] = r.outputs
+        r.outputs++
+        step.Output = append(step.Output, proto.Clone(env).(*wire.Envelope))
+    }
+    r.trace.Steps = append(r.trace.Steps, step)
+}
+
+// builder returns build, or the constructor of a built-in algorithm if build is nil.
+func builder(algorithm string, build Builder) (Builder, error) {
+    if build != nil {
+        return build, nil
+    }
+    if build, ok := Builders[algorithm]; ok {
+        return build, nil
+    }
+    return nil, ErrUnknownAlgorithm
+}
+
+// newCluster builds replicas 0 to n-1 with build. Every replica gets a random source seeded from seed and its
+// identifier, and a clock showing the time base returns.
+func newCluster(n int, seed int64, build Builder, base func() clock.Clock) []node.Replica {
+    peers := make([]int32, n)
+    for i := range peers {
+        peers[i] = int32(i)
+    }
+    replicas := make([]node.Replica, n)
+    for _, id := range peers {
+        env := modelcheck.Env{Clock: runClock{base}, Rand: rand.New(rand.NewSource(seed ^ int64(id)<<32))}
+        replicas[id] = build(id, peers, env)
+    }
+    return replicas
+}
+
+// runClock is the clock of a recorded replica. It reads the simulation's virtual time while recording and the
+// time of the step being replayed during a replay. The clock is chosen through a function, which fingerprinting
+// skips, so a replica's fingerprint does not depend on whether it is being recorded or replayed.
+type runClock struct {
+    base func() clock.Clock
+}
+
+func (c runClock) Now() time.Time                         { return c.base().Now() }
+func (c runClock) After(d time.Duration) <-chan time.Time { return c.base().After(d) }
+func (c runClock) NewTicker(d time.Duration) clock.Ticker { return c.base().NewTicker(d) }
+
+// Footer: Architectural Decisions
+//
+// 1. **Record Inputs, Replay by Re-Execution**: A trace stores the inputs each replica took rather than copies
+//    of its memory, and a replay feeds them to freshly built replicas. The states a replay shows are therefore
+//    computed by the real algorithm, and a bug can be reproduced under a debugger by replaying up to the step
+//    before it. The recorded outputs and fingerprints turn every replay into a check that the run is still
+//    reproduced, which it stops being as soon as a replica's code changes behavior.
+//
+// 2. **The Recorder Builds the Cluster**: Replaying a step only reproduces it if the replica draws the same random
+//    numbers and reads the same time as during the run. Record therefore builds the replicas itself, seeding each
+//    one's random source from the trace's seed, the way modelcheck builds its replicas from an Env.
+//
+// 3. **Steps Are the Simulator's Transitions**: Message delays, losses and partitions are not recorded as such;
+//    they show up as the order and timing of the deliveries in the trace. A replay needs no network model, and
+//    a trace from a run with injected faults replays like any other.
@@ -0,0 +1,134 @@
+package transport
+
+import (
+    "context"
+    "fmt"
+    "net"
+    "sync"
+    "time"
+
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/credentials/insecure"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/types/known/emptypb"
+
+    "consensus-algorithms-edu/wire"
+)
+
+// sendTimeout bounds how long a single Deliver call may take before the envelope is considered lost.
+const sendTimeout = time.Second
+
+// GRPCConfig describes a node's gRPC endpoint and the addresses of its peers.
+type GRPCConfig struct {
+    ID     int32            // Identifier of the local node.
+    Listen string           // Address the local node listens on, e.g. ":7000".
+    Peers  map[int32]string // Address of every other node, keyed by node ID. An entry for ID itself is ignored.
+}
+
+// GRPCTransport connects a node to its peers over gRPC, so every node can run in its own process.
+// Incoming envelopes arrive through the Peer service's Deliver method; outgoing envelopes are queued
+// per peer and sent in order by one goroutine per peer.
+type GRPCTransport struct {
+    UnimplementedPeerServer
+
+    id       int32
+    handler  Handler
+    mu       sync.Mutex // Serializes handler calls, as the Handler contract requires.
+    server   *grpc.Server
+    listener net.Listener
+    peers    map[int32]*grpcPeer
+    wg       sync.WaitGroup
+}
+
+// grpcPeer is the outgoing side of the connection to one peer.
+type grpcPeer struct {
+    conn   *grpc.ClientConn
+    client PeerClient
+    queue  chan *wire.Envelope
+}
+
+// NewGRPCTransport starts listening on cfg.Listen, prepares connections to every peer, and delivers
+// incoming envelopes to handler. Connections are established lazily, so peers may start in any order.
+func NewGRPCTransport(cfg GRPCConfig, handler Handler) (*GRPCTransport, error) {
+    listener, err := net.Listen("tcp", cfg.Listen)
+    if err != nil {
+        return nil, fmt.Errorf("transport: listen on %s: %w", cfg.Listen, err)
+    }
+
+    t := &GRPCTransport{
+        id:       cfg.ID,
+        handler:  handler,
+        server:   grpc.NewServer(),
+        listener: listener,
+        peers:    make(map[int32]*grpcPeer),
+    }
+    for id, addr := range cfg.Peers {
+        if id == cfg.ID {
+            continue
+        }
+        conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+        if err != nil {
+            t.Close()
+            return nil, fmt.Errorf("transport: dial peer %d at %s: %w", id, addr, err)
+        }
+        peer := &grpcPeer{conn: conn, client: NewPeerClient(conn), queue: make(chan *wire.Envelope, queueSize)}
+        t.peers[id] = peer
+        t.wg.Add(1)
+        go t.sendLoop(peer)
+    }
+
+    RegisterPeerServer(t.server, t)
+    go t.server.Serve(listener)
+    return t, nil
+}
+
+// Deliver implements the Peer service: it hands an incoming envelope to the local node. A malformed envelope
+// is refused with InvalidArgument and never reaches the node.
+func (t *GRPCTransport) Deliver(ctx context.Context, env *wire.Envelope) (*emptypb.Empty, error) {
+    if err := env.Validate(); err != nil {
+        return nil, status.Error(codes.InvalidArgument, err.Error())
+    }
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    t.handler(env)
+    return &emptypb.Empty{}, nil
+}
+
+// Send queues the envelope for the peer it is addressed to.
+func (t *GRPCTransport) Send(env *wire.Envelope) error {
+    peer, ok := t.peers[env.GetTo()]
+    if !ok {
+        return ErrUnknownPeer
+    }
+    select {
+    case peer.queue <- env:
+        return nil
+    default:
+        return ErrQueueFull // The peer is too far behind; drop the envelope like a congested network would.
+    }
+}
+
+// Close stops the server, drains the send loops and closes every peer connection.
+func (t *GRPCTransport) Close() error {
+    t.server.Stop()
+    for _, peer := range t.peers {
+        close(peer.queue)
+    }
+    t.wg.Wait()
+    for _, peer := range t.peers {
+        peer.conn.Close()
+    }
+    return nil
+}
+
+// sendLoop delivers one peer's envelopes in order. Failed deliveries are dropped: the peer may be down,
+// and the consensus protocol, not the transport, decides whether and when to retry.
+func (t *GRPCTransport) sendLoop(peer *grpcPeer) {
+    defer t.wg.Done()
+    for env := range peer.queue {
+        ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+        peer.client.Deliver(ctx, env)
+        cancel()
+    }
+}
@@ -0,0 +1,304 @@
+package transport
+
+import (
+    "bytes"
+    "encoding/binary"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "sync"
+    "time"
+
+    "google.golang.org/protobuf/proto"
+
+    "consensus-algorithms-edu/wire"
+)
+
+// Framing and handshake parameters of the TCP transport.
+const (
+    handshakeMagic = "CAE1"      // Protocol identifier and version sent at the start of every connection.
+    maxFrameSize   = 4 << 20     // Largest envelope accepted from the network, in bytes.
+    dialTimeout    = time.Second // Time allowed to connect to a peer and complete the handshake.
+)
+
+var (
+    // ErrHandshake is returned when a connection does not open with a valid handshake from the expected peer.
+    ErrHandshake = errors.New("transport: handshake failed")
+
+    // ErrFrameTooLarge is returned when a frame exceeds the maximum envelope size.
+    ErrFrameTooLarge = errors.New("transport: frame too large")
+)
+
+// WriteFrame writes the envelope to w as a single frame: a 4-byte big-endian length followed by the
+// envelope's Protocol Buffers encoding. The whole frame is written with one call, so a frame is never
+// interleaved with another writer's data on the same connection.
+func WriteFrame(w io.Writer, env *wire.Envelope) error {
+    payload, err := proto.Marshal(env)
+    if err != nil {
+        return err
+    }
+    if len(payload) > maxFrameSize {
+        return ErrFrameTooLarge
+    }
+    frame := make([]byte, 4+len(payload))
+    binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+    copy(frame[4:], payload)
+    _, err = w.Write(frame)
+    return err
+}
+
+// ReadFrame reads one frame written by WriteFrame. The length prefix is checked before any payload is
+// read, so a corrupted or malicious prefix cannot make the reader allocate an arbitrarily large buffer, and
+// the payload must decode into a well-formed envelope; otherwise the error wraps wire.ErrMalformed.
+func ReadFrame(r io.Reader) (*wire.Envelope, error) {
+    var header [4]byte
+    if _, err := io.ReadFull(r, header[:]); err != nil {
+        return nil, err
+    }
+    size := binary.BigEndian.Uint32(header[:])
+    if size > maxFrameSize {
+        return nil, ErrFrameTooLarge
+    }
+    payload := make([]byte, size)
+    if _, err := io.ReadFull(r, payload); err != nil {
+        return nil, err
+    }
+    return wire.DecodeEnvelope(payload) // A malformed envelope never reaches the replica.
+}
+
+// writeHello sends the handshake: the protocol magic followed by the local node ID.
+func writeHello(w io.Writer, id int32) error {
+    hello := make([]byte, len(handshakeMagic)+4)
+    copy(hello, handshakeMagic)
+    binary.BigEndian.PutUint32(hello[len(handshakeMagic):], uint32(id))
+    _, err := w.Write(hello)
+    return err
+}
+
+// readHello reads a handshake and returns the remote node ID.
+func readHello(r io.Reader) (int32, error) {
+    hello := make([]byte, len(handshakeMagic)+4)
+    if _, err := io.ReadFull(r, hello); err != nil {
+        return 0, fmt.Errorf("%w: %v", ErrHandshake, err)
+    }
+    if !bytes.Equal(hello[:len(handshakeMagic)], []byte(handshakeMagic)) {
+        return 0, fmt.Errorf("%w: unexpected protocol %q", ErrHandshake, hello[:len(handshakeMagic)])
+    }
+    return int32(binary.BigEndian.Uint32(hello[len(handshakeMagic):])), nil
+}
+
+// TCPConfig describes a node's TCP endpoint and the addresses of its peers.
+type TCPConfig struct {
+    ID     int32            // Identifier of the local node.
+    Listen string           // Address the local node listens on, e.g. ":7000".
+    Peers  map[int32]string // Address of every other node, keyed by node ID. An entry for ID itself is ignored.
+}
+
+// TCPTransport connects a node to its peers with plain TCP connections, without any RPC framework.
+// Every connection starts with a handshake in which both sides exchange the protocol magic and their
+// node IDs, and then carries length-prefixed envelopes in one direction only: each node dials its peers
+// to send, and accepts connections from its peers to receive.
+type TCPTransport struct {
+    id       int32
+    handler  Handler
+    mu       sync.Mutex            // Serializes handler calls, as the Handler contract requires.
+    listener net.Listener
+    peers    map[int32]*tcpPeer
+    wg       sync.WaitGroup
+    connsMu  sync.Mutex            // Guards conns.
+    conns    map[net.Conn]struct{} // Accepted connections, closed when the transport closes.
+    closed   bool                  // Set once Close has started; guarded by connsMu.
+}
+
+// tcpPeer is the outgoing side of the connection to one peer.
+type tcpPeer struct {
+    id    int32
+    addr  string
+    queue chan *wire.Envelope
+    conn  net.Conn // Current connection, or nil until the next envelope triggers a dial. Owned by sendLoop.
+}
+
+// NewTCPTransport starts listening on cfg.Listen and delivers incoming envelopes to handler. Peers are
+// dialled lazily when the first envelope for them is sent, and redialled after a connection fails, so
+// nodes may start, stop and restart in any order.
+func NewTCPTransport(cfg TCPConfig, handler Handler) (*TCPTransport, error) {
+    listener, err := net.Listen("tcp", cfg.Listen)
+    if err != nil {
+        return nil, fmt.Errorf("transport: listen on %s: %w", cfg.Listen, err)
+    }
+
+    t := &TCPTransport{
+        id:       cfg.ID,
+        handler:  handler,
+        listener: listener,
+        peers:    make(map[int32]*tcpPeer),
+        conns:    make(map[net.Conn]struct{}),
+    }
+    for id, addr := range cfg.Peers {
+        if id == cfg.ID {
+            continue
+        }
+        peer := &tcpPeer{id: id, addr: addr, queue: make(chan *wire.Envelope, queueSize)}
+        t.peers[id] = peer
+        t.wg.Add(1)
+        go t.sendLoop(peer)
+    }
+
+    t.wg.Add(1)
+    go t.acceptLoop()
+    return t, nil
+}
+
+// Addr returns the address the transport is listening on. It is useful when Listen used port 0.
+func (t *TCPTransport) Addr() net.Addr {
+    return t.listener.Addr()
+}
+
+// Send queues the envelope for the peer it is addressed to.
+func (t *TCPTransport) Send(env *wire.Envelope) error {
+    peer, ok := t.peers[env.GetTo()]
+    if !ok {
+        return ErrUnknownPeer
+    }
+    select {
+    case peer.queue <- env:
+        return nil
+    default:
+        return ErrQueueFull // The peer is too far behind; drop the envelope like a congested network would.
+    }
+}
+
+// Close stops accepting connections, closes every open connection and waits for all goroutines to exit.
+func (t *TCPTransport) Close() error {
+    t.connsMu.Lock()
+    if t.closed {
+        t.connsMu.Unlock()
+        return nil
+    }
+    t.closed = true
+    for conn := range t.conns {
+        conn.Close()
+    }
+    t.connsMu.Unlock()
+
+    err := t.listener.Close()
+    for _, peer := range t.peers {
+        close(peer.queue)
+    }
+    t.wg.Wait()
+    return err
+}
+
+// acceptLoop accepts incoming connections until the listener is closed.
+func (t *TCPTransport) acceptLoop() {
+    defer t.wg.Done()
+    for {
+        conn, err := t.listener.Accept()
+        if err != nil {
+            return
+        }
+        t.connsMu.Lock()
+        if t.closed {
+            t.connsMu.Unlock()
+            conn.Close()
+            return
+        }
+        t.conns[conn] = struct{}{}
+        t.connsMu.Unlock()
+
+        t.wg.Add(1)
+        go t.receiveLoop(conn)
+    }
+}
+
+// receiveLoop completes the handshake on an accepted connection and then delivers every frame read
+// from it. Envelopes that claim to come from a different node than the one that completed the handshake
+// are discarded, so a peer cannot impersonate another peer on its own connection.
+func (t *TCPTransport) receiveLoop(conn net.Conn) {
+    defer t.wg.Done()
+    defer func() {
+        t.connsMu.Lock()
+        delete(t.conns, conn)
+        t.connsMu.Unlock()
+        conn.Close()
+    }()
+
+    conn.SetDeadline(time.Now().Add(dialTimeout))
+    remote, err := readHello(conn)
+    if err != nil {
+        return
+    }
+    if _, ok := t.peers[remote]; !ok {
+        return // Not a member of this cluster.
+    }
+    if err := writeHello(conn, t.id); err != nil {
+        return
+    }
+    conn.SetDeadline(time.Time{})
+
+    for {
+        env, err := ReadFrame(conn)
+        if err != nil {
+            return // The peer closed the connection or sent a malformed frame.
+        }
+        if env.GetFrom() != remote || env.GetTo() != t.id {
+            continue
+        }
+        t.mu.Lock()
+        t.handler(env)
+        t.mu.Unlock()
+    }
+}
+
+// sendLoop delivers one peer's envelopes in order. Failed deliveries are dropped and the connection is
+// discarded; the next envelope triggers a fresh dial. Whether a lost envelope is retried is decided by
+// the consensus protocol, not the transport.
+func (t *TCPTransport) sendLoop(peer *tcpPeer) {
+    defer t.wg.Done()
+    defer func() {
+        if peer.conn != nil {
+            peer.conn.Close()
+        }
+    }()
+
+    for env := range peer.queue {
+        if peer.conn == nil {
+            conn, err := t.dial(peer)
+            if err != nil {
+                continue
+            }
+            peer.conn = conn
+        }
+        peer.conn.SetWriteDeadline(time.Now().Add(sendTimeout))
+        if err := WriteFrame(peer.conn, env); err != nil {
+            peer.conn.Close()
+            peer.conn = nil
+        }
+    }
+}
+
+// dial connects to a peer and performs the handshake, checking that the node that answers is the one
+// the address is configured for.
+func (t *TCPTransport) dial(peer *tcpPeer) (net.Conn, error) {
+    conn, err := net.DialTimeout("tcp", peer.addr, dialTimeout)
+    if err != nil {
+        return nil, err
+    }
+    conn.SetDeadline(time.Now().Add(dialTimeout))
+    if err := writeHello(conn, t.id); err != nil {
+        conn.Close()
+        return nil, err
+    }
+    remote, err := readHello(conn)
+    if err != nil {
+        conn.Close()
+        return nil, err
+    }
+    if remote != peer.id {
+        conn.Close()
+        return nil, fmt.Errorf("%w: %s belongs to node %d, not node %d", ErrHandshake, peer.addr, remote, peer.id)
+    }
+    conn.SetDeadline(time.Time{})
+    return conn, nil
+}
@@ -0,0 +1,138 @@
+// Package transport moves wire envelopes between consensus nodes.
+// A Transport only delivers messages; it knows nothing about the algorithm that produced them. Three
+// implementations are provided: an in-memory network for running every node inside one process, a gRPC
+// transport, and a plain TCP transport with its own handshake and framing that shows what an RPC framework
+// otherwise hides. Because all of them carry the same wire.Envelope type, a node can be moved from one to
+// another without any change to its logic.
+package transport
+
+//go:generate protoc -I . -I ../wire --go-grpc_out=. --go-grpc_opt=paths=source_relative transport.proto
+
+import (
+    "errors"
+    "sync"
+
+    "google.golang.org/protobuf/proto"
+
+    "consensus-algorithms-edu/wire"
+)
+
+var (
+    // ErrUnknownPeer is returned by Send when the envelope is addressed to a node the transport cannot reach.
+    ErrUnknownPeer = errors.New("transport: unknown peer")
+
+    // ErrQueueFull is returned by Send when the recipient's queue is full and the envelope was dropped.
+    ErrQueueFull = errors.New("transport: queue full")
+)
+
+// Handler is called for every envelope delivered to a node.
+// Each transport calls the handler of a node from at most one goroutine at a time.
+type Handler func(env *wire.Envelope)
+
+// Transport sends envelopes to other nodes. Delivery is best effort: an envelope that cannot be
+// delivered is dropped, exactly as it would be by an unreliable network, and consensus protocols
+// are expected to cope through retransmission and timeouts.
+type Transport interface {
+    Send(env *wire.Envelope) error // Queue the envelope for delivery to env.To.
+    Close() error                  // Stop sending and receiving.
+}
+
+// queueSize bounds the number of envelopes waiting for delivery to a single node.
+const queueSize = 1024
+
+// MemoryNetwork connects nodes that run inside the same process.
+// Every node gets its own inbox and delivery goroutine, so a handler that sends further messages
+// never re-enters another node's handler on the same call stack.
+type MemoryNetwork struct {
+    mu    sync.RWMutex
+    nodes map[int32]*MemoryTransport
+}
+
+// NewMemoryNetwork creates an empty in-memory network.
+func NewMemoryNetwork() *MemoryNetwork {
+    return &MemoryNetwork{nodes: make(map[int32]*MemoryTransport)}
+}
+
+// Join attaches a node to the network and starts delivering its envelopes to handler.
+func (n *MemoryNetwork) Join(id int32, handler Handler) *MemoryTransport {
+    t := &MemoryTransport{
+        id:      id,
+        network: n,
+        inbox:   make(chan *wire.Envelope, queueSize),
+        done:    make(chan struct{}),
+    }
+    n.mu.Lock()
+    n.nodes[id] = t
+    n.mu.Unlock()
+
+    go func() {
+        for {
+            select {
+            case env := <-t.inbox:
+                handler(env)
+            case <-t.done:
+                return
+            }
+        }
+    }()
+    return t
+}
+
+// MemoryTransport is one node's endpoint on a MemoryNetwork.
+type MemoryTransport struct {
+    id      int32
+    network *MemoryNetwork
+    inbox   chan *wire.Envelope
+    done    chan struct{}
+    once    sync.Once
+}
+
+// Send delivers a copy of the envelope to the recipient's inbox. The copy guarantees that sender and
+// receiver never share message memory, just as if the envelope had been serialized onto a real network.
+func (t *MemoryTransport) Send(env *wire.Envelope) error {
+    t.network.mu.RLock()
+    peer, ok := t.network.nodes[env.GetTo()]
+    t.network.mu.RUnlock()
+    if !ok {
+        return ErrUnknownPeer
+    }
+
+    select {
+    case peer.inbox <- proto.Clone(env).(*wire.Envelope):
+        return nil
+    case <-peer.done:
+        return ErrUnknownPeer
+    default:
+        return ErrQueueFull
+    }
+}
+
+// Close detaches the node from the network and stops its delivery goroutine.
+func (t *MemoryTransport) Close() error {
+    t.once.Do(func() {
+        t.network.mu.Lock()
+        delete(t.network.nodes, t.id)
+        t.network.mu.Unlock()
+        close(t.done)
+    })
+    return nil
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Transports Move Envelopes, Nothing Else**: Consensus logic lives in replicas that consume and produce
+//    wire.Envelope values. A transport only routes them by the To field, so it can be swapped without touching
+//    any algorithm, and the same replica runs over memory, gRPC or a simulator.
+//
+// 2. **Best-Effort Delivery**: Send never blocks and never retries. A full queue or an unreachable peer simply
+//    drops the envelope. Real networks lose messages, and Raft and PBFT are designed to recover through
+//    heartbeats, retransmission and timeouts rather than relying on reliable delivery.
+//
+// 3. **No Shared Memory**: The in-memory transport clones every envelope before delivery. This prevents subtle
+//    bugs where a replica mutates a message that another replica still holds, which could never happen over a
+//    real network and would make in-process results misleading.
+//
+// 4. **Explicit Handshake and Framing over TCP**: TCP delivers a byte stream, not messages. The TCP transport
+//    therefore prefixes every envelope with its length and rejects oversized frames before allocating memory,
+//    and it opens every connection with a handshake that identifies the protocol and the peer, so a node that
+//    was started with the wrong address list is detected instead of silently receiving someone else's traffic.
@@ -0,0 +1,390 @@
+// Package trie implements an authenticated key-value map in the style of Ethereum's Merkle Patricia trie. Keys
+// are split into nibbles, half-bytes, which spell a path from the root down to the key's value; nodes that would
+// have a single child are merged into a shared path, so the trie stays shallow however long its keys are. Every
+// node is identified by the hash of its encoding and names its children by their hashes, so the hash of the root
+// commits to every key and value: a chain that puts the root of its state in each block header commits to the
+// whole state, and anyone who trusts a header can check a single account against it with a short proof.
+package trie
+
+import (
+    "bytes"
+    "crypto/sha256"
+    "encoding/binary"
+    "errors"
+    "fmt"
+
+    "consensus-algorithms-edu/cost"
+    "consensus-algorithms-edu/profile"
+    "consensus-algorithms-edu/wire"
+)
+
+// ErrBadProof is wrapped by every error VerifyProof returns for a proof that does not lead from the root to the key.
+var ErrBadProof = errors.New("trie: invalid proof")
+
+// EmptyRoot is the root of a trie that holds no keys: the hash of no bytes.
+var EmptyRoot wire.Hash = sha256.Sum256(nil)
+
+// Kinds of node, written as the first byte of a node's encoding.
+const (
+    leafKind      byte = 0 // The rest of a key's path, and its value.
+    extensionKind byte = 1 // A path shared by every key below, and the one node they continue in.
+    branchKind    byte = 2 // One child per next nibble, and the value of a key that ends here.
+)
+
+// node is a node of a trie held in memory: a *leaf, an *extension or a *branch.
+type node interface {
+    encode() []byte
+}
+
+type leaf struct {
+    path  []byte // Remaining nibbles of the key.
+    value []byte
+}
+
+type extension struct {
+    path  []byte // Nibbles shared by every key below; at least one.
+    child node   // A branch.
+}
+
+type branch struct {
+    children [16]node // Child for each next nibble; nil where no key continues.
+    value    []byte   // Value of the key that ends at this branch; nil if none does.
+}
+
+// Trie is a Merkle Patricia trie. The zero Trie is empty and ready to use. A Trie is not safe for concurrent use.
+type Trie struct {
+    root node
+}
+
+// New returns an empty trie.
+func New() *Trie {
+    return &Trie{}
+}
+
+// Proof is the encodings of the nodes on the path from a trie's root towards a key, root first, which is
+// enough to recompute the root from the key's value, or from the point where the key leaves the trie.
+type Proof [][]byte
+
+// nibbles splits key into half-bytes, high half first.
+func nibbles(key []byte) []byte {
+    path := make([]byte, 0, 2*len(key))
+    for _, b := range key {
+        path = append(path, b>>4, b&0x0f)
+    }
+    return path
+}
+
+// commonPrefix returns the number of leading nibbles a and b share.
+func commonPrefix(a, b []byte) int {
+    n := 0
+    for n < len(a) && n < len(b) && a[n] == b[n] {
+        n++
+    }
+    return n
+}
+
+// Put sets the value of key, replacing any value it had. A nil value is stored as an empty one.
+func (t *Trie) Put(key, value []byte) {
+    if value == nil {
+        value = []byte{}
+    }
+    t.root = insert(t.root, nibbles(key), bytes.Clone(value))
+}
+
+// insert returns n with path set to value.
+func insert(n node, path, value []byte) node {
+    switch n := n.(type) {
+    case nil:
+        return &leaf{path: path, value: value}
+    case *leaf:
+        if bytes.Equal(n.path, path) {
+            return &leaf{path: path, value: value}
+        }
+        shared := commonPrefix(n.path, path)
+        b := &branch{}
+        b.put(n.path[shared:], n.value)
+        b.put(path[shared:], value)
+        return wrap(path[:shared], b)
+    case *extension:
+        shared := commonPrefix(n.path, path)
+        if shared == len(n.path) {
+            return &extension{path: n.path, child: insert(n.child, path[shared:], value)}
+        }
+        // The key leaves the shared path part way: split it at a new branch.
+        b := &branch{}
+        b.children[n.path[shared]] = wrap(n.path[shared+1:], n.child)
+        b.put(path[shared:], value)
+        return wrap(path[:shared], b)
+    case *branch:
+        c := *n
+        if len(path) == 0 {
+            c.value = value
+        } else {
+            c.children[path[0]] = insert(c.children[path[0]], path[1:], value)
+        }
+        return &c
+    }
+    panic(fmt.Sprintf("trie: unknown node %T", n))
+}
+
+// put sets the value of path below a new branch, at the branch itself if path is empty.
+func (b *branch) put(path, value []byte) {
+    if len(path) == 0 {
+        b.value = value
+        return
+    }
+    b.children[path[0]] = insert(b.children[path[0]], path[1:], value)
+}
+
+// wrap returns child behind an extension of path, or child itself if path is empty.
+func wrap(path []byte, child node) node {
+    if len(path) == 0 {
+        return child
+    }
+    return &extension{path: path, child: child}
+}
+
+// Get returns the value of key, and false if the trie holds no such key.
+func (t *Trie) Get(key []byte) ([]byte, bool) {
+    path := nibbles(key)
+    n := t.root
+    for {
+        switch c := n.(type) {
+        case nil:
+            return nil, false
+        case *leaf:
+            if !bytes.Equal(c.path, path) {
+                return nil, false
+            }
+            return bytes.Clone(c.value), true
+        case *extension:
+            if !bytes.HasPrefix(path, c.path) {
+                return nil, false
+            }
+            n, path = c.child, path[len(c.path):]
+        case *branch:
+            if len(path) == 0 {
+                return bytes.Clone(c.value), c.value != nil
+            }
+            n, path = c.children[path[0]], path[1:]
+        }
+    }
+}
+
+// Root returns the hash of the trie's root node, which commits to every key and value: EmptyRoot if it holds
+// none.
+func (t *Trie) Root() wire.Hash {
+    if t.root == nil {
+        return EmptyRoot
+    }
+    defer profile.Begin(profile.Hashing).End()
+    cost.Count(cost.Hash, 1)
+    return sha256.Sum256(t.root.encode())
+}
+
+// Prove returns a proof of the value of key, or, if the trie holds no such key, of its absence.
+func (t *Trie) Prove(key []byte) Proof {
+    var proof Proof
+    path := nibbles(key)
+    n := t.root
+    for n != nil {
+        proof = append(proof, n.encode())
+        switch c := n.(type) {
+        case *leaf:
+            return proof
+        case *extension:
+            if !bytes.HasPrefix(path, c.path) {
+                return proof
+            }
+            n, path = c.child, path[len(c.path):]
+        case *branch:
+            if len(path) == 0 {
+                return proof
+            }
+            n, path = c.children[path[0]], path[1:]
+        }
+    }
+    return proof
+}
+
+// VerifyProof checks a proof returned by Prove against the root of the trie it came from and returns the value
+// of key, or false if the proof shows that the trie holds no such key. A proof that does not hash to root, or
+// that stops before it settles whether the key is present, is rejected with an error wrapping ErrBadProof.
+func VerifyProof(root wire.Hash, key []byte, proof Proof) ([]byte, bool, error) {
+    if root == EmptyRoot && len(proof) == 0 {
+        return nil, false, nil
+    }
+    path := nibbles(key)
+    want := root
+    for i, enc := range proof {
+        if wire.Hash(sha256.Sum256(enc)) != want {
+            return nil, false, fmt.Errorf("%w: node %d does not match its hash", ErrBadProof, i)
+        }
+        d, err := decode(enc)
+        if err != nil {
+            return nil, false, fmt.Errorf("%w: node %d: %w", ErrBadProof, i, err)
+        }
+        last := i == len(proof)-1
+        switch d.kind {
+        case leafKind:
+            if !last {
+                return nil, false, fmt.Errorf("%w: nodes after a leaf", ErrBadProof)
+            }
+            if !bytes.Equal(d.path, path) {
+                return nil, false, nil
+            }
+            return d.value, true, nil
+        case extensionKind:
+            if !bytes.HasPrefix(path, d.path) {
+                return settled(last, nil, false)
+            }
+            want, path = d.children[0], path[len(d.path):]
+        case branchKind:
+            if len(path) == 0 {
+                return settled(last, d.value, d.value != nil)
+            }
+            if d.children[path[0]].IsZero() {
+                return settled(last, nil, false)
+            }
+            want, path = d.children[path[0]], path[1:]
+        }
+    }
+    return nil, false, fmt.Errorf("%w: proof ends before the key is found", ErrBadProof)
+}
+
+// settled returns the outcome of a proof whose key was settled at its last node, and an error if nodes follow.
+func settled(last bool, value []byte, ok bool) ([]byte, bool, error) {
+    if !last {
+        return nil, false, fmt.Errorf("%w: nodes after the key is settled", ErrBadProof)
+    }
+    return value, ok, nil
+}
+
+// Encodings. A node is its kind followed by its fields: a path as a length and one byte per nibble, a value as a
+// length and its bytes, and a child as its 32-byte hash. A branch lists its children after a 16-bit mask of the
+// nibbles that have one. Children are always referenced by hash, never embedded, so every node a proof carries
+// is checked the same way.
+
+func appendBytes(dst, b []byte) []byte {
+    return append(binary.AppendUvarint(dst, uint64(len(b))), b...)
+}
+
+func hashOf(n node) wire.Hash {
+    cost.Count(cost.Hash, 1)
+    return sha256.Sum256(n.encode())
+}
+
+func (l *leaf) encode() []byte {
+    return appendBytes(appendBytes([]byte{leafKind}, l.path), l.value)
+}
+
+func (e *extension) encode() []byte {
+    child := hashOf(e.child)
+    return append(appendBytes([]byte{extensionKind}, e.path), child[:]...)
+}
+
+func (b *branch) encode() []byte {
+    var mask uint16
+    for i, c := range b.children {
+        if c != nil {
+            mask |= 1 << i
+        }
+    }
+    enc := binary.BigEndian.AppendUint16([]byte{branchKind}, mask)
+    for _, c := range b.children {
+        if c != nil {
+            h := hashOf(c)
+            enc = append(enc, h[:]...)
+        }
+    }
+    if b.value == nil {
+        return append(enc, 0) // No value, which differs from an empty one.
+    }
+    return appendBytes(append(enc, 1), b.value)
+}
+
+// decoded is a node read back from its encoding, with its children as hashes. An extension's child is
+// children[0].
+type decoded struct {
+    kind     byte
+    path     []byte
+    value    []byte
+    children [16]wire.Hash
+}
+
+// decode reads a node written by encode.
+func decode(enc []byte) (decoded, error) {
+    var d decoded
+    r := bytes.NewReader(enc)
+    kind, err := r.ReadByte()
+    if err != nil {
+        return d, errors.New("empty node")
+    }
+    d.kind = kind
+    readBytes := func() ([]byte, error) {
+        n, err := binary.ReadUvarint(r)
+        if err != nil || n > uint64(r.Len()) {
+            return nil, errors.New("truncated node")
+        }
+        b := make([]byte, n)
+        r.Read(b)
+        return b, nil
+    }
+    readHash := func() (wire.Hash, error) {
+        var h wire.Hash
+        if r.Len() < len(h) {
+            return h, errors.New("truncated node")
+        }
+        r.Read(h[:])
+        return h, nil
+    }
+    switch kind {
+    case leafKind:
+        if d.path, err = readBytes(); err == nil {
+            d.value, err = readBytes()
+        }
+    case extensionKind:
+        if d.path, err = readBytes(); err == nil {
+            d.children[0], err = readHash()
+        }
+    case branchKind:
+        var mask [2]byte
+        if _, err = r.Read(mask[:]); err != nil {
+            return d, errors.New("truncated node")
+        }
+        for i := range d.children {
+            if binary.BigEndian.Uint16(mask[:])&(1<<i) != 0 && err == nil {
+                d.children[i], err = readHash()
+            }
+        }
+        if err == nil {
+            var has byte
+            if has, err = r.ReadByte(); err == nil && has == 1 {
+                d.value, err = readBytes()
+            }
+        }
+    default:
+        return d, fmt.Errorf("unknown node kind %d", kind)
+    }
+    if err == nil && r.Len() > 0 {
+        err = errors.New("trailing bytes")
+    }
+    return d, err
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Nibble Paths**: Keys are walked a half-byte at a time, so a branch has 16 children, as in Ethereum. Leaves
+//    and extensions collapse runs of single children into a path, so a trie of n random keys is about log16(n)
+//    branches deep, and so is a proof.
+//
+// 2. **Children by Hash**: Every child is referenced by the hash of its encoding. Ethereum embeds nodes shorter
+//    than a hash in their parent to save space; always hashing keeps one rule for every node a proof carries.
+//
+// 3. **Rebuilt, Not Updated**: A Trie can only Put. The ledger's states rebuild theirs from their current
+//    contents whenever a root is asked for, so removing a key, such as a spent output, is simply not putting it.
+//    A long-lived trie that is updated in place would need Delete, which must collapse branches left with one
+//    child to keep the root independent of the order keys were added in.
+//
+// 4. **Proofs of Absence**: A proof stops where the key leaves the trie: at a leaf with another path, or a branch
+//    with no child for the next nibble. Checking it proves that no value is stored under the key at that root.
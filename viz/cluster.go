@@ -0,0 +1,189 @@
+package viz
+
+import (
+    "fmt"
+    "html"
+    "io"
+    "math"
+    "strings"
+    "text/tabwriter"
+
+    "consensus-algorithms-edu/node"
+)
+
+// Dimensions of the SVG drawing of a frame, in pixels.
+const (
+    frameRing   = 1.5 // Radius of a frame's circle relative to a topology's, to leave room for the labels.
+    labelMargin = 60  // Room beside the circle for the labels of the leftmost and rightmost members.
+    arrowOffset = 4   // Distance an arrow is shifted to the right of its direction.
+)
+
+// RoleColors fills the members of a frame by role. Members whose role is not listed are filled like the nodes of
+// a topology.
+var RoleColors = map[string]string{
+    "leader":    "#fb8072",
+    "candidate": "#fdb462",
+    "follower":  "#8dd3c7",
+    "witness":   "#d9d9d9",
+    "primary":   "#fb8072",
+    "backup":    "#8dd3c7",
+}
+
+// Member is one node of a cluster as a Frame shows it.
+type Member struct {
+    ID    int32
+    Role  string // Role the node plays, e.g. "leader", "candidate" or "follower"; picks its color in RoleColors.
+    Label string // Detail drawn under the role, such as "term 2"; none if empty.
+    Down  bool   // The node has crashed; it is drawn unfilled with a dashed outline.
+}
+
+// Arrow is a message sent from one member of a frame to another.
+type Arrow struct {
+    From, To int32
+    Label    string // Kind of the message, e.g. "RequestVote"; none if empty.
+}
+
+// Frame is a cluster at one instant: the role of every member, and the messages sent at that instant. A sequence
+// of frames, one per step of a simulation, animates an election or a view change.
+type Frame struct {
+    Caption string // What happens at this instant, drawn under the title; none if empty.
+    Members []Member
+    Arrows  []Arrow // Arrows from or to a node that is not a member are left out.
+}
+
+// FrameSVG writes a frame as a standalone SVG image: the members on a circle in the order given, filled by role
+// and labelled beneath, and an arrow for every message. Arrows are shifted to the right of their direction, so
+// two members' messages to each other are drawn side by side.
+func FrameSVG(w io.Writer, f Frame, opts Options) error {
+    ids := make([]int32, len(f.Members))
+    for i, m := range f.Members {
+        ids[i] = m.ID
+    }
+    r := frameRing * ring(len(ids))
+    top := margin
+    if opts.Title != "" {
+        top += titleHeight
+    }
+    captionTop := top
+    if f.Caption != "" {
+        top += lineHeight + 10
+    }
+    width := max(2*margin+2*labelMargin+2*int(r)+2*nodeRadius, 2*margin+7*len(f.Caption))
+    cy := float64(top+nodeRadius) + r
+    height := int(cy+r) + nodeRadius + 2*lineHeight + margin // The labels of the lowest member hang below it.
+    pos := onCircle(ids, r, float64(width)/2, cy)
+
+    var b strings.Builder
+    fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif" font-size="11">`+"\n", width, height, width, height)
+    b.WriteString(`  <defs><marker id="arrowhead" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="7" markerHeight="7" orient="auto"><path d="M 0 0 L 10 5 L 0 10 z" fill="#333333"/></marker></defs>` + "\n")
+    if opts.Title != "" {
+        fmt.Fprintf(&b, `  <text x="%d" y="%d" font-size="16" font-weight="bold">%s</text>`+"\n", margin, margin+16, html.EscapeString(opts.Title))
+    }
+    if f.Caption != "" {
+        fmt.Fprintf(&b, `  <text x="%d" y="%d" font-size="12">%s</text>`+"\n", margin, captionTop+lineHeight, html.EscapeString(f.Caption))
+    }
+
+    // Arrows first, so members are drawn over them.
+    for _, a := range f.Arrows {
+        from, ok := pos[a.From]
+        to, known := pos[a.To]
+        if !ok || !known || a.From == a.To {
+            continue
+        }
+        dx, dy := float64(to[0]-from[0]), float64(to[1]-from[1])
+        d := math.Hypot(dx, dy)
+        ux, uy := dx/d, dy/d
+        nx, ny := -uy*arrowOffset, ux*arrowOffset
+        x1, y1 := float64(from[0])+ux*(nodeRadius+2)+nx, float64(from[1])+uy*(nodeRadius+2)+ny
+        x2, y2 := float64(to[0])-ux*(nodeRadius+3)+nx, float64(to[1])-uy*(nodeRadius+3)+ny
+        fmt.Fprintf(&b, `  <g class="arrow" data-from="%d" data-to="%d">`+"\n", a.From, a.To)
+        fmt.Fprintf(&b, `    <line x1="%.0f" y1="%.0f" x2="%.0f" y2="%.0f" stroke="#333333" stroke-width="1.5" marker-end="url(#arrowhead)"/>`+"\n", x1, y1, x2, y2)
+        if a.Label != "" {
+            fmt.Fprintf(&b, `    <text x="%.0f" y="%.0f" text-anchor="middle" font-size="9" fill="#555555">%s</text>`+"\n",
+                (x1+x2)/2+2*nx, (y1+y2)/2+2*ny+3, html.EscapeString(a.Label))
+        }
+        b.WriteString("  </g>\n")
+    }
+
+    for _, m := range f.Members {
+        p := pos[m.ID]
+        fill, style := RoleColors[m.Role], `stroke="#333333" stroke-width="2"`
+        if fill == "" {
+            fill = topologyColor
+        }
+        if m.Down {
+            fill, style = "#ffffff", `stroke="#808080" stroke-width="1" stroke-dasharray="4 3"`
+        }
+        fmt.Fprintf(&b, `  <g class="member" data-id="%d" data-role="%s">`+"\n", m.ID, html.EscapeString(m.Role))
+        fmt.Fprintf(&b, `    <circle cx="%d" cy="%d" r="%d" fill="%s" %s/>`+"\n", p[0], p[1], nodeRadius, fill, style)
+        fmt.Fprintf(&b, `    <text x="%d" y="%d" text-anchor="middle">%d</text>`+"\n", p[0], p[1]+4, m.ID)
+        for i, line := range memberLines(m) {
+            fmt.Fprintf(&b, `    <text x="%d" y="%d" text-anchor="middle">%s</text>`+"\n", p[0], p[1]+nodeRadius+lineHeight*(i+1), html.EscapeString(line))
+        }
+        b.WriteString("  </g>\n")
+    }
+    b.WriteString("</svg>\n")
+
+    _, err := io.WriteString(w, b.String())
+    return err
+}
+
+// FrameText writes a frame as plain text for a terminal: the caption, then one line per member with its role,
+// its label and the messages it sends, grouped by kind.
+//
+//  node-0  follower   term 1
+//  node-1  candidate  term 2  → RequestVote to node-0, node-2
+func FrameText(w io.Writer, f Frame, opts Options) error {
+    var b strings.Builder
+    if opts.Title != "" {
+        b.WriteString(opts.Title + "\n")
+    }
+    if f.Caption != "" {
+        b.WriteString(f.Caption + "\n")
+    }
+    tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+    for _, m := range f.Members {
+        role := m.Role
+        if m.Down {
+            role = "down"
+        }
+        fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", node.Name(m.ID), role, m.Label, sends(f.Arrows, m.ID))
+    }
+    tw.Flush()
+
+    _, err := io.WriteString(w, b.String())
+    return err
+}
+
+// memberLines returns the lines drawn under a member: its role, or "down", and its label.
+func memberLines(m Member) []string {
+    lines := []string{m.Role}
+    if m.Down {
+        lines[0] = "down"
+    }
+    if m.Label != "" {
+        lines = append(lines, m.Label)
+    }
+    return lines
+}
+
+// sends describes the messages a member sends, e.g. "→ RequestVote to node-0, node-2", one kind after another in
+// the order they were first sent.
+func sends(arrows []Arrow, from int32) string {
+    var kinds []string
+    to := make(map[string][]string)
+    for _, a := range arrows {
+        if a.From != from {
+            continue
+        }
+        if _, ok := to[a.Label]; !ok {
+            kinds = append(kinds, a.Label)
+        }
+        to[a.Label] = append(to[a.Label], node.Name(a.To))
+    }
+    parts := make([]string, len(kinds))
+    for i, kind := range kinds {
+        parts[i] = fmt.Sprintf("→ %s to %s", kind, strings.Join(to[kind], ", "))
+    }
+    return strings.Join(parts, "  ")
+}
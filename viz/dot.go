@@ -0,0 +1,73 @@
+package viz
+
+import (
+    "fmt"
+    "io"
+    "strings"
+
+    "consensus-algorithms-edu/wire"
+)
+
+// DOT writes blocks as a Graphviz digraph with one node per block and an edge from every parent to its child.
+// Render it with, for example, `dot -Tpng chain.dot -o chain.png`.
+func DOT(w io.Writer, blocks []*wire.Block, opts Options) error {
+    l := newLayout(blocks, opts)
+    var b strings.Builder
+    b.WriteString("digraph chain {\n")
+    b.WriteString("    rankdir=LR;\n")
+    b.WriteString("    node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\", fontsize=10];\n")
+    b.WriteString("    edge [arrowsize=0.7];\n")
+    if opts.Title != "" {
+        fmt.Fprintf(&b, "    label=%s;\n    labelloc=t;\n", quote(opts.Title))
+    }
+
+    for _, v := range l.vertices {
+        attrs := []string{"label=" + quote(strings.Join(labelLines(v.block, opts), "\n")), "fillcolor=" + quote(v.color)}
+        if v.canonical {
+            attrs = append(attrs, "penwidth=2")
+        } else {
+            attrs = append(attrs, `style="rounded,filled,dashed"`, `color="gray50"`)
+        }
+        fmt.Fprintf(&b, "    %s [%s];\n", quote(v.block.GetHash()), strings.Join(attrs, ", "))
+    }
+    for _, v := range l.vertices {
+        if v.parent == nil {
+            continue
+        }
+        attrs := `[color="gray50", style=dashed]`
+        if v.canonical && v.parent.canonical {
+            attrs = "[penwidth=2]"
+        }
+        fmt.Fprintf(&b, "    %s -> %s %s;\n", quote(v.parent.block.GetHash()), quote(v.block.GetHash()), attrs)
+    }
+
+    if len(l.producers) > 0 {
+        b.WriteString("    subgraph cluster_legend {\n        label=\"Producers\";\n        style=dashed;\n")
+        for i, p := range l.producers {
+            fmt.Fprintf(&b, "        %s [label=%s, fillcolor=%s];\n", quote(fmt.Sprintf("legend-%d", i)), quote(p), quote(l.colors[p]))
+        }
+        b.WriteString("    }\n")
+    }
+    b.WriteString("}\n")
+
+    _, err := io.WriteString(w, b.String())
+    return err
+}
+
+// labelLines returns the lines of text drawn inside a block.
+func labelLines(block *wire.Block, opts Options) []string {
+    lines := []string{fmt.Sprintf("#%d %s", block.GetIndex(), shortHash(block.GetHash()))}
+    if p := block.GetProducer(); p != "" {
+        lines = append(lines, p)
+    }
+    if opts.Data && block.GetData() != "" {
+        lines = append(lines, shortData(block.GetData()))
+    }
+    return lines
+}
+
+// quote returns s as a DOT string literal.
+func quote(s string) string {
+    r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+    return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,96 @@
+package viz
+
+import (
+    "fmt"
+    "html"
+    "io"
+    "strings"
+
+    "consensus-algorithms-edu/wire"
+)
+
+// Dimensions of the SVG drawing, in pixels.
+const (
+    blockWidth  = 110
+    blockHeight = 54
+    columnGap   = 40
+    rowGap      = 30
+    margin      = 20
+    titleHeight = 30
+    lineHeight  = 14
+)
+
+// SVG writes blocks as a standalone SVG image: one rounded box per block, placed in the column of its index and
+// the row of its branch, with a line from every parent to its child and a legend of producer colors below.
+func SVG(w io.Writer, blocks []*wire.Block, opts Options) error {
+    l := newLayout(blocks, opts)
+    top := margin
+    if opts.Title != "" {
+        top += titleHeight
+    }
+    legendTop := top + l.rows*(blockHeight+rowGap)
+    width := 2*margin + max(1, l.columns)*(blockWidth+columnGap) - columnGap
+    height := legendTop + margin
+    legendWidth := margin
+    for _, p := range l.producers {
+        legendWidth += legendEntryWidth(p)
+    }
+    width = max(width, legendWidth+margin)
+    if len(l.producers) > 0 {
+        height += lineHeight + 6
+    }
+
+    var b strings.Builder
+    fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif" font-size="11">`+"\n", width, height, width, height)
+    if opts.Title != "" {
+        fmt.Fprintf(&b, `  <text x="%d" y="%d" font-size="16" font-weight="bold">%s</text>`+"\n", margin, margin+16, html.EscapeString(opts.Title))
+    }
+
+    x := func(v *vertex) int { return margin + v.column*(blockWidth+columnGap) }
+    y := func(v *vertex) int { return top + v.row*(blockHeight+rowGap) }
+
+    // Edges first, so blocks are drawn over them.
+    for _, v := range l.vertices {
+        if v.parent == nil {
+            continue
+        }
+        style := `stroke="#333333" stroke-width="2"`
+        if !v.canonical || !v.parent.canonical {
+            style = `stroke="#808080" stroke-width="1" stroke-dasharray="4 3"`
+        }
+        fmt.Fprintf(&b, `  <line x1="%d" y1="%d" x2="%d" y2="%d" %s/>`+"\n",
+            x(v.parent)+blockWidth, y(v.parent)+blockHeight/2, x(v), y(v)+blockHeight/2, style)
+    }
+
+    for _, v := range l.vertices {
+        style := `stroke="#333333" stroke-width="2"`
+        if !v.canonical {
+            style = `stroke="#808080" stroke-width="1" stroke-dasharray="4 3"`
+        }
+        fmt.Fprintf(&b, `  <g class="block" data-hash="%s">`+"\n", html.EscapeString(v.block.GetHash()))
+        fmt.Fprintf(&b, `    <rect x="%d" y="%d" width="%d" height="%d" rx="6" fill="%s" %s/>`+"\n", x(v), y(v), blockWidth, blockHeight, v.color, style)
+        lines := labelLines(v.block, opts)
+        first := y(v) + (blockHeight-len(lines)*lineHeight)/2 + lineHeight - 3
+        for i, line := range lines {
+            fmt.Fprintf(&b, `    <text x="%d" y="%d" text-anchor="middle">%s</text>`+"\n", x(v)+blockWidth/2, first+i*lineHeight, html.EscapeString(line))
+        }
+        b.WriteString("  </g>\n")
+    }
+
+    lx := margin
+    for _, p := range l.producers {
+        fmt.Fprintf(&b, `  <rect x="%d" y="%d" width="12" height="12" fill="%s" stroke="#333333"/>`+"\n", lx, legendTop, l.colors[p])
+        fmt.Fprintf(&b, `  <text x="%d" y="%d">%s</text>`+"\n", lx+16, legendTop+10, html.EscapeString(p))
+        lx += legendEntryWidth(p)
+    }
+    b.WriteString("</svg>\n")
+
+    _, err := io.WriteString(w, b.String())
+    return err
+}
+
+// legendEntryWidth estimates the space taken by a producer's swatch and name; SVG cannot measure text before it
+// is rendered.
+func legendEntryWidth(producer string) int {
+    return 16 + 7*len(producer) + 20
+}
@@ -0,0 +1,121 @@
+package viz
+
+import (
+    "fmt"
+    "io"
+    "strings"
+    "unicode/utf8"
+
+    "consensus-algorithms-edu/wire"
+)
+
+// Text writes blocks as plain text for a terminal. A terminal is narrow and tall, so the layout is turned on its
+// side: every index gets a line, top to bottom, and every branch a column, the canonical chain first. A line
+// joins each fork to its parent. Producers are named, as a terminal has no fill colors to show them by.
+//
+//  #0  genesis
+//      ├──────────┐
+//  #1  a1 alice   b1 carol
+//  #2  a2 bob
+func Text(w io.Writer, blocks []*wire.Block, opts Options) error {
+    l := newLayout(blocks, opts)
+    var b strings.Builder
+    if opts.Title != "" {
+        b.WriteString(opts.Title + "\n")
+    }
+    if len(l.vertices) == 0 {
+        _, err := io.WriteString(w, b.String())
+        return err
+    }
+
+    // Place every block in the grid, and every branch at its offset in a line.
+    low := l.vertices[0].block.GetIndex()
+    grid := make([][]*vertex, l.columns) // grid[column][row]
+    for column := range grid {
+        grid[column] = make([]*vertex, l.rows)
+    }
+    widths := make([]int, l.rows)
+    gutter := len(fmt.Sprint("#", low+int64(l.columns)-1))
+    for _, v := range l.vertices {
+        grid[v.column][v.row] = v
+        widths[v.row] = max(widths[v.row], utf8.RuneCountInString(cell(v.block, opts)))
+    }
+    offsets := make([]int, l.rows)
+    offsets[0] = gutter + 2
+    for row := 1; row < l.rows; row++ {
+        offsets[row] = offsets[row-1] + widths[row-1] + 3
+    }
+
+    for column, line := range grid {
+        if column > 0 {
+            if joins := forks(grid[column-1], line, offsets); joins != "" {
+                b.WriteString(joins + "\n")
+            }
+        }
+        text := fmt.Sprintf("%-*s", gutter+2, fmt.Sprint("#", low+int64(column)))
+        for row, v := range line {
+            if v != nil {
+                text += strings.Repeat(" ", offsets[row]-utf8.RuneCountInString(text)) + cell(v.block, opts)
+            }
+        }
+        b.WriteString(text + "\n")
+    }
+    _, err := io.WriteString(w, b.String())
+    return err
+}
+
+// forks draws the line between two consecutive indexes that joins each branch starting at the second to its
+// parent at the first, with the branches continuing through it, or returns "" if no branch starts there.
+func forks(above, below []*vertex, offsets []int) string {
+    var line []rune
+    draw := func(at int, r rune) {
+        for len(line) <= at {
+            line = append(line, ' ')
+        }
+        line[at] = join(line[at], r)
+    }
+    for row, v := range below {
+        if v == nil || v.parent == nil || v.parent.row == row {
+            continue
+        }
+        from, to := offsets[v.parent.row], offsets[row]
+        for at := from + 1; at < to; at++ {
+            draw(at, '─')
+        }
+        draw(to, '┐')
+        if below[v.parent.row] != nil && below[v.parent.row].parent == above[v.parent.row] {
+            draw(from, '├')
+        } else {
+            draw(from, '└')
+        }
+    }
+    if line == nil {
+        return ""
+    }
+    for row, v := range below {
+        if v != nil && v.parent != nil && v.parent.row == row {
+            draw(offsets[row], '│')
+        }
+    }
+    return strings.TrimRight(string(line), " ")
+}
+
+// join combines a glyph already drawn at a point of a line with another one drawn over it, e.g. the end of one
+// fork with a later fork from the same parent that reaches further.
+func join(drawn, r rune) rune {
+    switch {
+    case drawn == ' ' || drawn == r:
+        return r
+    case drawn == '┐' && r == '─', drawn == '─' && r == '┐':
+        return '┬'
+    case drawn == '─' && r == '│', drawn == '│' && r == '─':
+        return '┼' // A branch continues across the line joining a fork.
+    }
+    return drawn
+}
+
+// cell returns the text of a block in its column: the index is in the gutter, so it starts with the hash.
+func cell(block *wire.Block, opts Options) string {
+    lines := labelLines(block, opts)
+    return strings.Join(append([]string{shortHash(block.GetHash())}, lines[1:]...), " ")
+}
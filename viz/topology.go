@@ -0,0 +1,129 @@
+package viz
+
+import (
+    "fmt"
+    "html"
+    "io"
+    "math"
+    "slices"
+    "strings"
+)
+
+// Dimensions of the SVG drawing of a topology, in pixels.
+const (
+    nodeRadius  = 16
+    ringSpacing = 60 // Length of the circle's circumference per node.
+    minRing     = 80 // Smallest radius of the circle the nodes sit on.
+)
+
+// topologyColor fills the nodes of a topology, which have no producer to be colored by.
+const topologyColor = "#80b1d3"
+
+// edges returns every link of a topology once, the lower node first, in ascending order. A link listed at only
+// one end is still drawn.
+func edges(neighbors map[int32][]int32) [][2]int32 {
+    var links [][2]int32
+    for a, peers := range neighbors {
+        for _, b := range peers {
+            link := [2]int32{min(a, b), max(a, b)}
+            if !slices.Contains(links, link) {
+                links = append(links, link)
+            }
+        }
+    }
+    slices.SortFunc(links, func(x, y [2]int32) int {
+        if x[0] != y[0] {
+            return int(x[0] - y[0])
+        }
+        return int(x[1] - y[1])
+    })
+    return links
+}
+
+// nodes returns every node of a topology in ascending order, those without neighbors included.
+func nodes(neighbors map[int32][]int32) []int32 {
+    var ids []int32
+    for id, peers := range neighbors {
+        ids = append(append(ids, id), peers...)
+    }
+    slices.Sort(ids)
+    return slices.Compact(ids)
+}
+
+// ring returns the radius of the circle n nodes are drawn on.
+func ring(n int) float64 {
+    return max(minRing, float64(n*ringSpacing)/(2*math.Pi))
+}
+
+// onCircle places nodes in order on a circle of radius r around (cx, cy), the first at the top, and returns the
+// center of each.
+func onCircle(ids []int32, r, cx, cy float64) map[int32][2]int {
+    pos := make(map[int32][2]int, len(ids))
+    for i, id := range ids {
+        angle := 2*math.Pi*float64(i)/float64(len(ids)) - math.Pi/2
+        pos[id] = [2]int{int(math.Round(cx + r*math.Cos(angle))), int(math.Round(cy + r*math.Sin(angle)))}
+    }
+    return pos
+}
+
+// TopologyDOT writes a network topology, such as sim.Simulator.Topology returns, as an undirected Graphviz graph
+// with one node per replica and one edge per connection. Render it with, for example, `neato -Tpng`.
+func TopologyDOT(w io.Writer, neighbors map[int32][]int32, opts Options) error {
+    var b strings.Builder
+    b.WriteString("graph topology {\n")
+    fmt.Fprintf(&b, "    node [shape=circle, style=filled, fillcolor=%s, fontname=\"Helvetica\", fontsize=10];\n", quote(topologyColor))
+    if opts.Title != "" {
+        fmt.Fprintf(&b, "    label=%s;\n    labelloc=t;\n", quote(opts.Title))
+    }
+    for _, id := range nodes(neighbors) {
+        fmt.Fprintf(&b, "    %d;\n", id)
+    }
+    for _, link := range edges(neighbors) {
+        fmt.Fprintf(&b, "    %d -- %d;\n", link[0], link[1])
+    }
+    b.WriteString("}\n")
+
+    _, err := io.WriteString(w, b.String())
+    return err
+}
+
+// TopologySVG writes a network topology as a standalone SVG image: the nodes on a circle in ascending order and a
+// line for every connection. Nodes without connections are drawn with a dashed outline.
+func TopologySVG(w io.Writer, neighbors map[int32][]int32, opts Options) error {
+    ids := nodes(neighbors)
+    r := ring(len(ids))
+    top := margin
+    if opts.Title != "" {
+        top += titleHeight
+    }
+    size := 2*margin + 2*int(r) + 2*nodeRadius
+    height := top - margin + size
+    pos := onCircle(ids, r, float64(size)/2, float64(top-margin)+float64(size)/2)
+
+    var b strings.Builder
+    fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif" font-size="11">`+"\n", size, height, size, height)
+    if opts.Title != "" {
+        fmt.Fprintf(&b, `  <text x="%d" y="%d" font-size="16" font-weight="bold">%s</text>`+"\n", margin, margin+16, html.EscapeString(opts.Title))
+    }
+
+    // Edges first, so nodes are drawn over them.
+    for _, link := range edges(neighbors) {
+        a, c := pos[link[0]], pos[link[1]]
+        fmt.Fprintf(&b, `  <line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#808080" stroke-width="1"/>`+"\n", a[0], a[1], c[0], c[1])
+    }
+    for _, id := range ids {
+        style := `stroke="#333333" stroke-width="2"`
+        if len(neighbors[id]) == 0 {
+            style = `stroke="#808080" stroke-width="1" stroke-dasharray="4 3"`
+        }
+        p := pos[id]
+        fmt.Fprintf(&b, `  <g class="node" data-id="%d">`+"\n", id)
+        fmt.Fprintf(&b, `    <circle cx="%d" cy="%d" r="%d" fill="%s" %s/>`+"\n", p[0], p[1], nodeRadius, topologyColor, style)
+        fmt.Fprintf(&b, `    <text x="%d" y="%d" text-anchor="middle">%d</text>`+"\n", p[0], p[1]+4, id)
+        b.WriteString("  </g>\n")
+    }
+    b.WriteString("</svg>\n")
+
+    _, err := io.WriteString(w, b.String())
+    return err
+}
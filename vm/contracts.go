@@ -0,0 +1,252 @@
+package vm
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "maps"
+    "slices"
+    "strconv"
+    "strings"
+    "sync"
+
+    "consensus-algorithms-edu/ledger"
+)
+
+// CallPrefix starts the data of a block that carries Calls.
+const CallPrefix = "contracts:"
+
+var (
+    // ErrDeployed is returned for a call that deploys a contract under a name that is already taken.
+    ErrDeployed = fmt.Errorf("%w: contract already deployed", ledger.ErrInvalid)
+
+    // ErrNoContract is returned for a call that invokes a contract nobody has deployed.
+    ErrNoContract = fmt.Errorf("%w: no such contract", ledger.ErrInvalid)
+)
+
+// Call deploys a contract, if it carries Code, or invokes one with arguments.
+type Call struct {
+    Contract string  `json:"contract"`       // Name of the contract.
+    Code     string  `json:"code,omitempty"` // Source of the contract to deploy, as Assemble reads it.
+    Args     []int64 `json:"args,omitempty"` // Arguments of an invocation.
+}
+
+// EncodeCalls returns the data of a block carrying calls, to be submitted like any other block data.
+func EncodeCalls(calls ...Call) string {
+    data, _ := json.Marshal(calls)
+    return CallPrefix + string(data)
+}
+
+// DecodeCalls returns the calls a block's data carries: none if the data does not start with CallPrefix, and an
+// error wrapping ledger.ErrMalformed if it does but the rest does not decode.
+func DecodeCalls(data string) ([]Call, error) {
+    rest, ok := strings.CutPrefix(data, CallPrefix)
+    if !ok {
+        return nil, nil
+    }
+    var calls []Call
+    if err := json.Unmarshal([]byte(rest), &calls); err != nil {
+        return nil, fmt.Errorf("%w: %v", ledger.ErrMalformed, err)
+    }
+    return calls, nil
+}
+
+// contract is a deployed program and the storage its invocations have left.
+type contract struct {
+    code    Program
+    storage Storage
+}
+
+// Contracts is the ledger.State of deployed contracts: blocks carry Calls that deploy contracts and invoke them,
+// and every invocation runs on the machine against its contract's storage. An invocation that faults or runs
+// out of gas makes its block invalid, so a committed block's invocations all succeeded, identically on every
+// node that applied it.
+type Contracts struct {
+    mu        sync.Mutex
+    gas       int                  // Instructions each invocation may execute.
+    contracts map[string]*contract // Deployed contracts by name.
+}
+
+// NewContracts returns the state before the first block, in which no contract is deployed and each invocation
+// may execute gas instructions, or DefaultGas if gas is not positive.
+func NewContracts(gas int) *Contracts {
+    if gas <= 0 {
+        gas = DefaultGas
+    }
+    return &Contracts{gas: gas, contracts: make(map[string]*contract)}
+}
+
+// Storage returns a copy of the named contract's storage, or nil if it is not deployed.
+func (c *Contracts) Storage(name string) Storage {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if k, ok := c.contracts[name]; ok {
+        return maps.Clone(k.storage)
+    }
+    return nil
+}
+
+// Query invokes the named contract without changing its storage and returns its result, for reading state
+// without submitting a block.
+func (c *Contracts) Query(name string, args ...int64) (int64, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    k, ok := c.contracts[name]
+    if !ok {
+        return 0, fmt.Errorf("%w: %s", ErrNoContract, name)
+    }
+    return Run(k.code, args, maps.Clone(k.storage), c.gas)
+}
+
+// Digest returns a hash of every deployed contract's code and storage, so nodes can tell in one comparison
+// whether their executions agree.
+func (c *Contracts) Digest() string {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    h := sha256.New()
+    for _, name := range slices.Sorted(maps.Keys(c.contracts)) {
+        k := c.contracts[name]
+        fmt.Fprintf(h, "%q\n%s", name, k.code)
+        for _, key := range slices.Sorted(maps.Keys(k.storage)) {
+            fmt.Fprintf(h, "%s=%d\n", key, k.storage[key])
+        }
+    }
+    return hex.EncodeToString(h.Sum(nil))
+}
+
+// Check reports whether the calls in a block carrying data could be applied: every deployment assembles under a
+// new name and every invocation completes. Calls in one block apply in order, so a block may deploy a contract
+// and invoke it.
+func (c *Contracts) Check(data string) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    _, err := c.execute(data)
+    return err
+}
+
+// Apply applies the calls in a block carrying data, or none of them if Check fails.
+func (c *Contracts) Apply(data string) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    changed, err := c.execute(data)
+    if err != nil {
+        return err
+    }
+    maps.Copy(c.contracts, changed)
+    return nil
+}
+
+// Reset undeploys every contract.
+func (c *Contracts) Reset() {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.contracts = make(map[string]*contract)
+}
+
+// Clone returns an independent copy of the contracts and their storage.
+func (c *Contracts) Clone() ledger.State {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    clone := &Contracts{gas: c.gas, contracts: make(map[string]*contract, len(c.contracts))}
+    for name, k := range c.contracts {
+        clone.contracts[name] = &contract{code: k.code, storage: maps.Clone(k.storage)} // Code never changes.
+    }
+    return clone
+}
+
+// Execute returns a receipt for each call of a block carrying data, without applying it. A deployment changes
+// the entry "contract <name>" and an invocation the entries "storage <name>.<slot>" of the slots it wrote.
+func (c *Contracts) Execute(data string) ([]ledger.Receipt, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    calls, err := DecodeCalls(data)
+    if err != nil || calls == nil {
+        return nil, err
+    }
+    original := func(key string) string {
+        if name, ok := strings.CutPrefix(key, "contract "); ok {
+            if _, deployed := c.contracts[name]; deployed {
+                return "deployed"
+            }
+            return ""
+        }
+        slot := strings.TrimPrefix(key, "storage ")
+        dot := strings.LastIndex(slot, ".")
+        if k, ok := c.contracts[slot[:dot]]; ok {
+            if v, ok := k.storage[slot[dot+1:]]; ok {
+                return strconv.FormatInt(v, 10)
+            }
+        }
+        return ""
+    }
+    hashes := make([]string, len(calls))
+    for i, call := range calls {
+        hashes[i] = ledger.TxHash(call)
+    }
+    return ledger.ExecuteEach(hashes, original, func(n int) (map[string]string, error) {
+        changed, err := c.execute(EncodeCalls(calls[:n]...))
+        entries := make(map[string]string)
+        for name, k := range changed {
+            entries["contract "+name] = "deployed"
+            for slot, v := range k.storage {
+                entries["storage "+name+"."+slot] = strconv.FormatInt(v, 10)
+            }
+        }
+        return entries, err
+    }, func(i int) []string {
+        if calls[i].Code != "" {
+            return []string{"deployed " + calls[i].Contract}
+        }
+        return []string{fmt.Sprintf("invoked %s with %v", calls[i].Contract, calls[i].Args)}
+    })
+}
+
+// execute returns the contracts the calls in data deploy or invoke, with their new storage, without changing c.
+func (c *Contracts) execute(data string) (map[string]*contract, error) {
+    calls, err := DecodeCalls(data)
+    if err != nil {
+        return nil, err
+    }
+    changed := make(map[string]*contract)
+    for i, call := range calls {
+        k, ok := changed[call.Contract]
+        if !ok {
+            k, ok = c.contracts[call.Contract]
+        }
+        if call.Code != "" {
+            if ok {
+                return nil, fmt.Errorf("call %d: %w: %s", i, ErrDeployed, call.Contract)
+            }
+            code, err := Assemble(call.Code)
+            if err != nil {
+                return nil, fmt.Errorf("call %d: %w: %v", i, ledger.ErrMalformed, err)
+            }
+            changed[call.Contract] = &contract{code: code, storage: make(Storage)}
+            continue
+        }
+        if !ok {
+            return nil, fmt.Errorf("call %d: %w: %s", i, ErrNoContract, call.Contract)
+        }
+        storage := maps.Clone(k.storage)
+        if _, err := Run(k.code, call.Args, storage, c.gas); err != nil {
+            return nil, fmt.Errorf("call %d: %s: %w", i, call.Contract, err)
+        }
+        changed[call.Contract] = &contract{code: k.code, storage: storage}
+    }
+    return changed, nil
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Failed Calls Invalidate Blocks**: A real chain includes a failed call and charges for it; here a block
+//    whose call faults is simply invalid, as a block with an overdraft is for Accounts. Both ways are
+//    deterministic, and this one keeps the state's rules the same as every other ledger.State's.
+//
+// 2. **Copy on Write**: An invocation runs against a copy of its contract's storage, and the block's changed
+//    contracts replace the old ones only once every call has succeeded, so a block applies entirely or not at
+//    all, just like the transfers in Accounts.
+//
+// 3. **Gas per Invocation**: Every node gives every invocation the same gas, set when the state is created. A
+//    node configured with less would reject blocks the others accept, which is the same kind of divergence a
+//    nondeterministic instruction would cause.
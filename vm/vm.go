@@ -0,0 +1,291 @@
+// Package vm is a tiny deterministic stack machine whose programs, called contracts, are deployed and invoked
+// by transactions in block data. Every node that applies a committed block runs the same contract code on the
+// same storage with the same arguments, so it must arrive at the same result: a replicated state machine is
+// only as consistent as its execution is deterministic. The machine therefore has no access to clocks,
+// randomness or anything outside its arguments and storage, its arithmetic wraps the same way everywhere, and
+// every program is bounded by gas, so even one that loops forever fails identically on every node.
+package vm
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "unicode"
+
+    "consensus-algorithms-edu/ledger"
+)
+
+// DefaultGas is the number of instructions a call may execute, unless the Contracts it runs in says otherwise.
+const DefaultGas = 10000
+
+// MaxStack is the number of values the stack can hold.
+const MaxStack = 256
+
+var (
+    // ErrFault is returned for a call that cannot complete: it pops an empty stack, overflows it, divides by
+    // zero, jumps outside its program or executes fail. Like every error of a call, it wraps ledger.ErrInvalid,
+    // so a block carrying the call is invalid.
+    ErrFault = fmt.Errorf("%w: contract fault", ledger.ErrInvalid)
+
+    // ErrOutOfGas is returned for a call that executes more instructions than its gas allows.
+    ErrOutOfGas = fmt.Errorf("%w: out of gas", ledger.ErrInvalid)
+)
+
+// Opcode is an instruction of the machine.
+type Opcode byte
+
+const (
+    OpPush  Opcode = iota // Push the immediate N.
+    OpPop                 // Drop the top value.
+    OpDup                 // Push a copy of the top value.
+    OpSwap                // Swap the two top values.
+    OpAdd                 // Pop b and a, push a + b.
+    OpSub                 // Pop b and a, push a - b.
+    OpMul                 // Pop b and a, push a * b.
+    OpDiv                 // Pop b and a, push a / b; faults if b is 0.
+    OpMod                 // Pop b and a, push a % b; faults if b is 0.
+    OpEq                  // Pop b and a, push 1 if a == b and 0 otherwise.
+    OpLt                  // Pop b and a, push 1 if a < b and 0 otherwise.
+    OpNot                 // Pop a, push 1 if a is 0 and 0 otherwise.
+    OpJump                // Continue at instruction N.
+    OpJumpIf              // Pop a, continue at instruction N if a is not 0.
+    OpArg                 // Push the call's argument N, or 0 if it has fewer arguments.
+    OpLoad                // Push the value stored under Key, or 0 if nothing is.
+    OpStore               // Pop a and store it under Key.
+    OpFail                // Fault, discarding everything the call stored.
+    OpHalt                // Stop; the call returns the top value, or 0 if the stack is empty.
+)
+
+var mnemonics = [...]string{
+    OpPush: "push", OpPop: "pop", OpDup: "dup", OpSwap: "swap",
+    OpAdd: "add", OpSub: "sub", OpMul: "mul", OpDiv: "div", OpMod: "mod",
+    OpEq: "eq", OpLt: "lt", OpNot: "not",
+    OpJump: "jump", OpJumpIf: "jumpi",
+    OpArg: "arg", OpLoad: "load", OpStore: "store",
+    OpFail: "fail", OpHalt: "halt",
+}
+
+// pops is the number of values each opcode pops before it pushes any.
+var pops = map[Opcode]int{
+    OpPop: 1, OpDup: 1, OpSwap: 2, OpAdd: 2, OpSub: 2, OpMul: 2, OpDiv: 2, OpMod: 2,
+    OpEq: 2, OpLt: 2, OpNot: 1, OpJumpIf: 1, OpStore: 1,
+}
+
+// String returns the opcode's mnemonic, as Assemble reads it.
+func (op Opcode) String() string {
+    if int(op) < len(mnemonics) {
+        return mnemonics[op]
+    }
+    return fmt.Sprintf("Opcode(%d)", op)
+}
+
+// Instruction is an opcode with its operand, if it takes one.
+type Instruction struct {
+    Op  Opcode
+    N   int64  // Operand of push, jump, jumpi and arg.
+    Key string // Operand of load and store.
+}
+
+// String formats the instruction as Assemble reads it.
+func (in Instruction) String() string {
+    switch in.Op {
+    case OpPush, OpJump, OpJumpIf, OpArg:
+        return fmt.Sprintf("%s %d", in.Op, in.N)
+    case OpLoad, OpStore:
+        return fmt.Sprintf("%s %s", in.Op, in.Key)
+    default:
+        return in.Op.String()
+    }
+}
+
+// Program is a contract's code: instructions numbered from 0, which jumps refer to.
+type Program []Instruction
+
+// String formats the program as Assemble reads it, one instruction per line.
+func (p Program) String() string {
+    var b strings.Builder
+    for _, in := range p {
+        fmt.Fprintln(&b, in)
+    }
+    return b.String()
+}
+
+// Storage is a contract's persistent state: integers under names, kept between calls.
+type Storage map[string]int64
+
+// Assemble reads a program from source: one instruction per line or separated by semicolons, each a mnemonic
+// followed by its operand, and anything from # to the end of the line a comment. For example, a counter that
+// adds its first argument to what it has counted so far and returns the sum:
+//
+//	load count; arg 0; add   # count + args[0]
+//	dup; store count         # keep it, and return it
+func Assemble(source string) (Program, error) {
+    var p Program
+    for n, line := range strings.Split(source, "\n") {
+        line, _, _ = strings.Cut(line, "#")
+        for _, text := range strings.Split(line, ";") {
+            fields := strings.Fields(text)
+            if len(fields) == 0 {
+                continue
+            }
+            in, err := parse(fields)
+            if err != nil {
+                return nil, fmt.Errorf("vm: line %d: %q: %w", n+1, strings.TrimSpace(text), err)
+            }
+            p = append(p, in)
+        }
+    }
+    return p, nil
+}
+
+// parse reads one instruction from its mnemonic and operand.
+func parse(fields []string) (Instruction, error) {
+    op := Opcode(0)
+    for op < Opcode(len(mnemonics)) && mnemonics[op] != fields[0] {
+        op++
+    }
+    if op == Opcode(len(mnemonics)) {
+        return Instruction{}, fmt.Errorf("unknown instruction")
+    }
+    in := Instruction{Op: op}
+    operands := 0
+    switch op {
+    case OpPush, OpJump, OpJumpIf, OpArg:
+        operands = 1
+        if len(fields) == 2 {
+            n, err := strconv.ParseInt(fields[1], 10, 64)
+            if err != nil {
+                return Instruction{}, fmt.Errorf("operand is not an integer")
+            }
+            in.N = n
+        }
+    case OpLoad, OpStore:
+        operands = 1
+        if len(fields) == 2 {
+            if strings.IndexFunc(fields[1], func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' }) >= 0 {
+                return Instruction{}, fmt.Errorf("key is not a name")
+            }
+            in.Key = fields[1]
+        }
+    }
+    if len(fields)-1 != operands {
+        return Instruction{}, fmt.Errorf("takes %d operands, got %d", operands, len(fields)-1)
+    }
+    return in, nil
+}
+
+// Run executes p with args against storage, which it updates in place, and returns what the program leaves on
+// top of its stack. It stops after gas instructions with ErrOutOfGas; a caller that must not keep the stores of
+// a failed call runs it against a copy.
+func Run(p Program, args []int64, storage Storage, gas int) (int64, error) {
+    var stack []int64
+    fault := func(pc int, format string, a ...any) error {
+        return fmt.Errorf("%w: instruction %d (%s): %s", ErrFault, pc, p[pc], fmt.Sprintf(format, a...))
+    }
+    for pc := 0; pc < len(p); pc++ {
+        if gas == 0 {
+            return 0, fmt.Errorf("%w after instruction %d", ErrOutOfGas, pc)
+        }
+        gas--
+
+        in := p[pc]
+        n := pops[in.Op]
+        if len(stack) < n {
+            return 0, fault(pc, "stack underflow")
+        }
+        var a, b int64 // The operands an instruction pops, b from the top.
+        switch n {
+        case 1:
+            a = stack[len(stack)-1]
+        case 2:
+            a, b = stack[len(stack)-2], stack[len(stack)-1]
+        }
+        stack = stack[:len(stack)-n]
+        push := func(v int64) { stack = append(stack, v) }
+
+        switch in.Op {
+        case OpPush:
+            push(in.N)
+        case OpPop:
+        case OpDup:
+            push(a)
+            push(a)
+        case OpSwap:
+            push(b)
+            push(a)
+        case OpAdd:
+            push(a + b)
+        case OpSub:
+            push(a - b)
+        case OpMul:
+            push(a * b)
+        case OpDiv, OpMod:
+            if b == 0 {
+                return 0, fault(pc, "division by zero")
+            }
+            if in.Op == OpDiv {
+                push(a / b)
+            } else {
+                push(a % b)
+            }
+        case OpEq:
+            push(boolean(a == b))
+        case OpLt:
+            push(boolean(a < b))
+        case OpNot:
+            push(boolean(a == 0))
+        case OpJump, OpJumpIf:
+            if in.Op == OpJumpIf && a == 0 {
+                break
+            }
+            if in.N < 0 || in.N > int64(len(p)) {
+                return 0, fault(pc, "jump outside the program")
+            }
+            pc = int(in.N) - 1 // The loop increments it.
+        case OpArg:
+            if in.N >= 0 && in.N < int64(len(args)) {
+                push(args[in.N])
+            } else {
+                push(0)
+            }
+        case OpLoad:
+            push(storage[in.Key])
+        case OpStore:
+            storage[in.Key] = a
+        case OpFail:
+            return 0, fault(pc, "failed")
+        case OpHalt:
+            pc = len(p)
+        default:
+            return 0, fault(pc, "invalid opcode")
+        }
+        if len(stack) > MaxStack {
+            return 0, fault(pc, "stack overflow")
+        }
+    }
+    if len(stack) == 0 {
+        return 0, nil
+    }
+    return stack[len(stack)-1], nil
+}
+
+// boolean returns 1 for true and 0 for false.
+func boolean(v bool) int64 {
+    if v {
+        return 1
+    }
+    return 0
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Nothing From Outside**: A call sees its arguments and its contract's storage and nothing else. An
+//    instruction that read the clock or a random number would let two nodes applying the same block disagree
+//    about its result, and from then on about the state every later block builds on.
+//
+// 2. **Gas Bounds Every Call**: Whether a program halts cannot be decided in general, so every call is given a
+//    number of instructions instead. A call that loops forever fails after exactly that many on every node,
+//    rather than hanging them or stopping wherever each one's patience ran out.
+//
+// 3. **Integers Only**: Values are int64, whose wrapping arithmetic is the same on every platform. Floating
+//    point is left out, as it is from real contract machines, and so is every other type.
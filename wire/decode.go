@@ -0,0 +1,297 @@
+package wire
+
+import (
+    "errors"
+    "fmt"
+
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/proto"
+)
+
+// ErrMalformed is wrapped by every error returned for input that does not decode, or decodes into a message no
+// node could have sent: a block without a hash, an envelope without a body, a negative log index.
+var ErrMalformed = errors.New("wire: malformed message")
+
+// malformed returns an error wrapping ErrMalformed that describes what is wrong with the input.
+func malformed(format string, args ...any) error {
+    return fmt.Errorf("%w: %s", ErrMalformed, fmt.Sprintf(format, args...))
+}
+
+// DecodeBlock decodes a block from its Protocol Buffers encoding and checks that it is well formed.
+func DecodeBlock(data []byte) (*Block, error) {
+    block := &Block{}
+    if err := proto.Unmarshal(data, block); err != nil {
+        return nil, malformed("%v", err)
+    }
+    if err := block.Validate(); err != nil {
+        return nil, err
+    }
+    return block, nil
+}
+
+// DecodeBlockJSON decodes a block from its canonical JSON mapping and checks that it is well formed.
+func DecodeBlockJSON(data []byte) (*Block, error) {
+    block := &Block{}
+    if err := protojson.Unmarshal(data, block); err != nil {
+        return nil, malformed("%v", err)
+    }
+    if err := block.Validate(); err != nil {
+        return nil, err
+    }
+    return block, nil
+}
+
+// DecodeChainJSON decodes a chain from its canonical JSON mapping, as storage.FileStore writes it, and checks
+// every block in it.
+func DecodeChainJSON(data []byte) (*Chain, error) {
+    chain := &Chain{}
+    if err := protojson.Unmarshal(data, chain); err != nil {
+        return nil, malformed("%v", err)
+    }
+    for i, block := range chain.GetBlocks() {
+        if err := block.Validate(); err != nil {
+            return nil, fmt.Errorf("block %d: %w", i, err)
+        }
+    }
+    return chain, nil
+}
+
+// DecodeSnapshotJSON decodes a snapshot from its canonical JSON mapping, as WriteSnapshot writes it, and checks
+// it with Validate.
+func DecodeSnapshotJSON(data []byte) (*Snapshot, error) {
+    snapshot := &Snapshot{}
+    if err := protojson.Unmarshal(data, snapshot); err != nil {
+        return nil, malformed("%v", err)
+    }
+    if err := snapshot.Validate(); err != nil {
+        return nil, err
+    }
+    return snapshot, nil
+}
+
+// DecodeEnvelope decodes an envelope from its Protocol Buffers encoding and checks that it is well formed.
+// Transports decode what they receive with it, so replicas only ever step envelopes that pass Validate.
+func DecodeEnvelope(data []byte) (*Envelope, error) {
+    env := &Envelope{}
+    if err := proto.Unmarshal(data, env); err != nil {
+        return nil, malformed("%v", err)
+    }
+    if err := env.Validate(); err != nil {
+        return nil, err
+    }
+    return env, nil
+}
+
+// Validate checks the fields every algorithm relies on: a non-negative index, a hash, a parent hash unless the
+// block is a genesis block, and a non-negative difficulty. It does not recompute the hash, which depends on the
+// algorithm; engine.Validate does that for whole chains.
+func (b *Block) Validate() error {
+    switch {
+    case b == nil:
+        return malformed("missing block")
+    case b.GetIndex() < 0:
+        return malformed("block has negative index %d", b.GetIndex())
+    case b.GetHash() == "":
+        return malformed("block %d has no hash", b.GetIndex())
+    case b.GetIndex() > 0 && b.GetPrevHash() == "":
+        return malformed("block %d has no parent hash", b.GetIndex())
+    case b.GetDifficulty() < 0:
+        return malformed("block %d has negative difficulty %d", b.GetIndex(), b.GetDifficulty())
+    }
+    return nil
+}
+
+// Validate checks that the snapshot names an algorithm and carries well-formed blocks, and that no stake,
+// difficulty or node ID in it is negative.
+func (s *Snapshot) Validate() error {
+    if s.GetChain().GetAlgorithm() == "" {
+        return malformed("snapshot names no algorithm")
+    }
+    for i, block := range s.GetChain().GetBlocks() {
+        if err := block.Validate(); err != nil {
+            return fmt.Errorf("block %d: %w", i, err)
+        }
+    }
+    if s.GetDifficulty() < 0 {
+        return malformed("negative difficulty %d", s.GetDifficulty())
+    }
+    for _, stake := range s.GetStakes() {
+        if stake.GetAmount() < 0 {
+            return malformed("validator %q has negative stake %d", stake.GetValidator(), stake.GetAmount())
+        }
+    }
+    for _, member := range s.GetMembers() {
+        if member.GetId() < 0 {
+            return malformed("member with negative id %d", member.GetId())
+        }
+    }
+    return nil
+}
+
+// Validate checks that the envelope carries a message, that the node IDs and the indices, sequence numbers and
+// ballots in it are not negative, and that every block and header it carries is well formed. Whether the message makes
+// sense to its recipient, such as a term that is out of date, is left to the replica.
+func (e *Envelope) Validate() error {
+    if e.GetFrom() < 0 || e.GetTo() < 0 {
+        return malformed("envelope from %d to %d", e.GetFrom(), e.GetTo())
+    }
+    var err error
+    switch body := e.GetBody().(type) {
+    case nil:
+        err = malformed("envelope has no body")
+    case *Envelope_RequestVote:
+        err = validateRequestVote(body.RequestVote)
+    case *Envelope_AppendEntries:
+        err = validateAppendEntries(body.AppendEntries)
+    case *Envelope_PrePrepare:
+        err = validatePrePrepare(body.PrePrepare)
+    case *Envelope_Prepare:
+        err = validateSequence(body.Prepare.GetSequence())
+    case *Envelope_Commit:
+        err = validateSequence(body.Commit.GetSequence())
+    case *Envelope_ViewChange:
+        err = validateViewChange(body.ViewChange)
+    case *Envelope_NewView:
+        err = validateNewView(body.NewView)
+    case *Envelope_PaxosPrepare:
+        err = validateBallot(body.PaxosPrepare.GetBallot(), body.PaxosPrepare.GetSlot())
+    case *Envelope_PaxosPromise:
+        err = validatePaxosPromise(body.PaxosPromise)
+    case *Envelope_PaxosAccept:
+        err = validatePaxosAccept(body.PaxosAccept)
+    case *Envelope_PaxosAccepted:
+        err = validateBallot(body.PaxosAccepted.GetBallot(), body.PaxosAccepted.GetSlot())
+    case *Envelope_BlockProposal:
+        err = body.BlockProposal.GetBlock().Validate()
+    case *Envelope_HeaderRequest:
+        if body.HeaderRequest.GetFrom() < 0 || body.HeaderRequest.GetLimit() < 0 {
+            err = malformed("headers from %d, at most %d", body.HeaderRequest.GetFrom(), body.HeaderRequest.GetLimit())
+        }
+    case *Envelope_Headers:
+        _, err = DecodeHeaders(body.Headers)
+    case *Envelope_ChainSummary:
+        for _, r := range body.ChainSummary.GetRanges() {
+            if r.GetFrom() < 0 || r.GetTo() <= r.GetFrom() || (len(r.GetRoot()) != 0 && len(r.GetRoot()) != len(Hash{})) {
+                err = malformed("Merkle range [%d, %d) with a root of %d bytes", r.GetFrom(), r.GetTo(), len(r.GetRoot()))
+                break
+            }
+        }
+    case *Envelope_Evidence:
+        for _, st := range []*Statement{body.Evidence.GetFirst(), body.Evidence.GetSecond()} {
+            if err = validateStatement(st); err != nil {
+                break
+            }
+        }
+    case *Envelope_Statement:
+        err = validateStatement(body.Statement)
+    case *Envelope_CheckpointVote:
+        v := body.CheckpointVote
+        if v.GetValidator() < 0 || v.GetSourceHeight() < 0 || v.GetTargetHeight() <= v.GetSourceHeight() {
+            err = malformed("vote by %d from height %d to %d", v.GetValidator(), v.GetSourceHeight(), v.GetTargetHeight())
+        }
+    }
+    if err != nil {
+        return fmt.Errorf("%s: %w", e.Kind(), err)
+    }
+    return nil
+}
+
+// validateStatement rejects a statement by a negative signer, about a negative height, or without a signature.
+func validateStatement(st *Statement) error {
+    if st.GetSigner() < 0 || st.GetHeight() < 0 || len(st.GetSignature()) == 0 {
+        return malformed("statement by %d at height %d with a signature of %d bytes", st.GetSigner(), st.GetHeight(), len(st.GetSignature()))
+    }
+    return nil
+}
+
+// validateRequestVote rejects a vote request from a negative candidate or for a negative log index.
+func validateRequestVote(m *RequestVote) error {
+    if m.GetCandidateId() < 0 || m.GetLastLogIndex() < 0 {
+        return malformed("candidate %d with last log index %d", m.GetCandidateId(), m.GetLastLogIndex())
+    }
+    return nil
+}
+
+// validateAppendEntries rejects negative log positions and entries without a well-formed block.
+func validateAppendEntries(m *AppendEntries) error {
+    if m.GetLeaderId() < 0 || m.GetPrevLogIndex() < 0 || m.GetLeaderCommit() < 0 {
+        return malformed("leader %d with previous log index %d and commit index %d", m.GetLeaderId(), m.GetPrevLogIndex(), m.GetLeaderCommit())
+    }
+    for i, entry := range m.GetEntries() {
+        if err := entry.GetBlock().Validate(); err != nil {
+            return fmt.Errorf("entry %d: %w", i, err)
+        }
+    }
+    return nil
+}
+
+// validateSequence rejects a negative PBFT sequence number.
+func validateSequence(sequence int64) error {
+    if sequence < 0 {
+        return malformed("negative sequence number %d", sequence)
+    }
+    return nil
+}
+
+// validatePrePrepare checks the sequence number and the block the primary assigned to it.
+func validatePrePrepare(m *PrePrepare) error {
+    if err := validateSequence(m.GetSequence()); err != nil {
+        return err
+    }
+    return m.GetBlock().Validate()
+}
+
+// validateViewChange checks the sender, its last executed sequence and every certificate it carries.
+func validateViewChange(m *ViewChange) error {
+    if m.GetReplicaId() < 0 || m.GetLastSequence() < 0 {
+        return malformed("replica %d with last sequence number %d", m.GetReplicaId(), m.GetLastSequence())
+    }
+    for _, pp := range m.GetPrepared() {
+        if err := validatePrePrepare(pp); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// validateNewView checks the ViewChange messages that justify the view and the PrePrepares it re-issues.
+func validateNewView(m *NewView) error {
+    for _, vc := range m.GetViewChanges() {
+        if err := validateViewChange(vc); err != nil {
+            return err
+        }
+    }
+    for _, pp := range m.GetPrePrepares() {
+        if err := validatePrePrepare(pp); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// validateBallot rejects a negative Paxos ballot or slot.
+func validateBallot(ballot, slot int64) error {
+    if ballot < 0 || slot < 0 {
+        return malformed("ballot %d for slot %d", ballot, slot)
+    }
+    return nil
+}
+
+// validatePaxosPromise checks the ballot and, if the acceptor reported one, the value it accepted.
+func validatePaxosPromise(m *PaxosPromise) error {
+    if err := validateBallot(m.GetBallot(), m.GetSlot()); err != nil {
+        return err
+    }
+    if m.GetAcceptedValue() != nil { // A promise from an acceptor that has accepted nothing carries no value.
+        return m.GetAcceptedValue().Validate()
+    }
+    return nil
+}
+
+// validatePaxosAccept checks the ballot and the value the proposer asks acceptors to accept.
+func validatePaxosAccept(m *PaxosAccept) error {
+    if err := validateBallot(m.GetBallot(), m.GetSlot()); err != nil {
+        return err
+    }
+    return m.GetValue().Validate()
+}
@@ -0,0 +1,84 @@
+package wire
+
+import (
+    "fmt"
+    "strings"
+)
+
+// Dump formats blocks for reading, one line per block: its index, the first 16 hex digits of its hash and its
+// parent's hash, its data, and its producer if it names one.
+func Dump(blocks []*Block) string {
+    var sb strings.Builder
+    for _, block := range blocks {
+        sb.WriteString(line(block))
+        sb.WriteByte('\n')
+    }
+    return sb.String()
+}
+
+// line formats one block as Dump does.
+func line(block *Block) string {
+    producer := ""
+    if block.GetProducer() != "" {
+        producer = " by " + block.GetProducer()
+    }
+    return fmt.Sprintf("#%-4d %.16s  prev %-16.16s  %q%s", block.GetIndex(), block.GetHash(), block.GetPrevHash(), block.GetData(), producer)
+}
+
+// Summarize describes a chain in one line: its algorithm, its length and the block at its head.
+func Summarize(algorithm string, blocks []*Block) string {
+    if len(blocks) == 0 {
+        return algorithm + " chain without blocks"
+    }
+    head := blocks[len(blocks)-1]
+    return fmt.Sprintf("%s chain of %d blocks, head #%d %.16s", algorithm, len(blocks), head.GetIndex(), head.GetHash())
+}
+
+// Divergence is where two chains stop holding the same blocks, as found by Diff.
+type Divergence struct {
+    Height int    // First height at which the chains differ; -1 if they are identical.
+    A, B   *Block // The block each chain holds at Height; nil for a chain that ends before it.
+}
+
+// Diff compares two chains height by height and returns the first height at which they differ, either because
+// they hold different blocks there or because one of them ends. Blocks are compared by hash.
+func Diff(a, b []*Block) Divergence {
+    for height := 0; height < max(len(a), len(b)); height++ {
+        var x, y *Block
+        if height < len(a) {
+            x = a[height]
+        }
+        if height < len(b) {
+            y = b[height]
+        }
+        if x == nil || y == nil || x.GetHash() != y.GetHash() {
+            return Divergence{Height: height, A: x, B: y}
+        }
+    }
+    return Divergence{Height: -1}
+}
+
+// Same reports whether the chains are identical.
+func (d Divergence) Same() bool {
+    return d.Height < 0
+}
+
+// Conflicting reports whether the chains hold different blocks at the same height, rather than one extending
+// the other.
+func (d Divergence) Conflicting() bool {
+    return d.A != nil && d.B != nil
+}
+
+// String describes the divergence for a person reading a test failure or a tool's output.
+func (d Divergence) String() string {
+    switch {
+    case d.Same():
+        return "the chains are identical"
+    case d.Conflicting():
+        return fmt.Sprintf("the chains diverge at height %d:\n  a: %s\n  b: %s", d.Height, line(d.A), line(d.B))
+    case d.A == nil:
+        return fmt.Sprintf("chain a has %d blocks, and b continues with %s", d.Height, line(d.B))
+    default:
+        return fmt.Sprintf("chain b has %d blocks, and a continues with %s", d.Height, line(d.A))
+    }
+}
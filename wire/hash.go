@@ -0,0 +1,92 @@
+package wire
+
+import (
+    "encoding/hex"
+)
+
+// Hash is a SHA-256 block hash. The algorithm packages keep hashes in their blocks as 32 raw bytes, which take
+// half the memory of the hex strings they replace and compare without being parsed. They are written as 64 hex
+// digits only for display and in Block, whose string fields keep saved chains and messages readable.
+//
+// The zero Hash stands for no hash at all, such as the parent of a genesis block, and is written as the empty
+// string, which is what such a parent hash has always been.
+type Hash [32]byte
+
+// String returns the hash as 64 lower-case hex digits, or "" for the zero Hash.
+func (h Hash) String() string {
+    if h.IsZero() {
+        return ""
+    }
+    return hex.EncodeToString(h[:])
+}
+
+// IsZero reports whether h is the zero Hash.
+func (h Hash) IsZero() bool {
+    return h == Hash{}
+}
+
+// AppendHex appends the hash as String writes it to dst, without allocating a string.
+func (h Hash) AppendHex(dst []byte) []byte {
+    if h.IsZero() {
+        return dst
+    }
+    return hex.AppendEncode(dst, h[:])
+}
+
+// LeadingZeros returns the number of leading zero hex digits of the hash, which is how Proof of Work difficulty
+// is measured.
+func (h Hash) LeadingZeros() int {
+    for i, b := range h {
+        switch {
+        case b == 0:
+            continue
+        case b < 0x10:
+            return 2*i + 1
+        default:
+            return 2 * i
+        }
+    }
+    return 2 * len(h)
+}
+
+// ParseHash parses a hash written as String writes it: 64 hex digits, or "" for the zero Hash.
+func ParseHash(s string) (Hash, error) {
+    var h Hash
+    if s == "" {
+        return h, nil
+    }
+    if len(s) != 2*len(h) {
+        return Hash{}, malformed("hash %.16q has %d hex digits, want %d", s, len(s), 2*len(h))
+    }
+    if _, err := hex.Decode(h[:], []byte(s)); err != nil {
+        return Hash{}, malformed("hash %.16q: %v", s, err)
+    }
+    return h, nil
+}
+
+// HashFromHex is ParseHash for hashes that were already checked, such as those of a decoded Block. It returns
+// the zero Hash for a malformed one, which no block hashes to, so a block carrying it fails verification.
+func HashFromHex(s string) Hash {
+    h, _ := ParseHash(s)
+    return h
+}
+
+// Sum returns the block's hash as a Hash; the zero Hash if it is malformed.
+func (b *Block) Sum() Hash {
+    return HashFromHex(b.GetHash())
+}
+
+// ParentSum returns the hash of the block's parent as a Hash; the zero Hash for a genesis block.
+func (b *Block) ParentSum() Hash {
+    return HashFromHex(b.GetPrevHash())
+}
+
+// StateSum returns the block's state root as a Hash; the zero Hash for a block that commits to no state.
+func (b *Block) StateSum() Hash {
+    return HashFromHex(b.GetStateRoot())
+}
+
+// ReceiptsSum returns the block's receipts root as a Hash; the zero Hash for a block that commits to no receipts.
+func (b *Block) ReceiptsSum() Hash {
+    return HashFromHex(b.GetReceiptsRoot())
+}
@@ -0,0 +1,131 @@
+package wire
+
+import (
+    "crypto/sha256"
+    "encoding/binary"
+    "errors"
+    "fmt"
+
+    "consensus-algorithms-edu/cost"
+    "consensus-algorithms-edu/profile"
+)
+
+// HeaderSize is the length of a header's binary encoding, in bytes.
+const HeaderSize = 8 + 8 + 32 + 32 + 8 + 4 + 32 + 32 + 32
+
+// ErrBrokenHeaders is returned by VerifyHeaders for headers that do not form a chain.
+var ErrBrokenHeaders = errors.New("wire: headers do not form a chain")
+
+// Header is the part of a block its hash covers. The body, the data the block carries, is committed to only
+// through Root, so a node can follow a chain from headers alone, check that each links to its parent and, for
+// Proof of Work, that it carries its work, and fetch the bodies it needs later, checking each against its header.
+// That is how light clients follow a chain, and what lets a full node validate a branch's headers before
+// downloading it.
+//
+// A header has the same size whatever the block carries: the producer's name is committed as its ProducerID
+// rather than written out. Fields that do not apply to an algorithm are left at their zero value.
+type Header struct {
+    Index      int64     // Position of the block in the chain.
+    Timestamp  Timestamp // Creation time of the block.
+    Parent     Hash      // Hash of the parent block; the zero Hash for a genesis block.
+    Root       Hash      // BodyRoot of the block's data.
+    Nonce      int64     // PoW only: the nonce that satisfies the difficulty target.
+    Difficulty int32     // PoW only: leading zeros the hash must have; the default difficulty if 0.
+    Producer   Hash      // PoS and DPoS only: ProducerID of the validator or delegate that produced the block.
+    State      Hash      // Root of the chain's ledger state after the block; the zero Hash if no state was attached.
+    Receipts   Hash      // Root of the receipts of the block's transactions; the zero Hash if it has none.
+}
+
+// BodyRoot returns the hash a header commits to for a block carrying data.
+func BodyRoot(data string) Hash {
+    defer profile.Begin(profile.Hashing).End()
+    cost.Count(cost.Hash, 1)
+    return sha256.Sum256([]byte(data))
+}
+
+// ProducerID returns the hash a header commits to for the named producer, or the zero Hash if name is empty.
+func ProducerID(name string) Hash {
+    if name == "" {
+        return Hash{}
+    }
+    return sha256.Sum256([]byte(name))
+}
+
+// AppendBinary appends the header's fixed-size encoding to dst: its fields in order, integers big-endian.
+func (h *Header) AppendBinary(dst []byte) []byte {
+    dst = binary.BigEndian.AppendUint64(dst, uint64(h.Index))
+    dst = binary.BigEndian.AppendUint64(dst, uint64(h.Timestamp))
+    dst = append(dst, h.Parent[:]...)
+    dst = append(dst, h.Root[:]...)
+    dst = binary.BigEndian.AppendUint64(dst, uint64(h.Nonce))
+    dst = binary.BigEndian.AppendUint32(dst, uint32(h.Difficulty))
+    dst = append(dst, h.Producer[:]...)
+    dst = append(dst, h.State[:]...)
+    return append(dst, h.Receipts[:]...)
+}
+
+// MarshalBinary returns the header's fixed-size encoding.
+func (h *Header) MarshalBinary() ([]byte, error) {
+    return h.AppendBinary(make([]byte, 0, HeaderSize)), nil
+}
+
+// UnmarshalBinary decodes a header written by MarshalBinary.
+func (h *Header) UnmarshalBinary(data []byte) error {
+    if len(data) != HeaderSize {
+        return malformed("header of %d bytes, want %d", len(data), HeaderSize)
+    }
+    h.Index = int64(binary.BigEndian.Uint64(data[0:]))
+    h.Timestamp = Timestamp(binary.BigEndian.Uint64(data[8:]))
+    copy(h.Parent[:], data[16:48])
+    copy(h.Root[:], data[48:80])
+    h.Nonce = int64(binary.BigEndian.Uint64(data[80:]))
+    h.Difficulty = int32(binary.BigEndian.Uint32(data[88:]))
+    copy(h.Producer[:], data[92:124])
+    copy(h.State[:], data[124:156])
+    copy(h.Receipts[:], data[156:188])
+    return nil
+}
+
+// Hash returns the hash of the header, which is the hash of its block.
+func (h *Header) Hash() Hash {
+    defer profile.Begin(profile.Hashing).End()
+    cost.Count(cost.Hash, 1)
+    var buf [HeaderSize]byte
+    return sha256.Sum256(h.AppendBinary(buf[:0]))
+}
+
+// VerifyHeaders checks that headers form a chain: each sits at the index after its predecessor's and names the
+// predecessor's hash as its parent. It returns an error wrapping ErrBrokenHeaders for the first that does not.
+// The first header is taken on trust; compare its hash with one obtained elsewhere, such as a genesis block.
+func VerifyHeaders(headers []Header) error {
+    for i := 1; i < len(headers); i++ {
+        prev, h := &headers[i-1], &headers[i]
+        switch {
+        case h.Index != prev.Index+1:
+            return fmt.Errorf("%w: header %d follows index %d", ErrBrokenHeaders, h.Index, prev.Index)
+        case h.Parent != prev.Hash():
+            return fmt.Errorf("%w: header %d does not link to its predecessor", ErrBrokenHeaders, h.Index)
+        }
+    }
+    return nil
+}
+
+// DecodeHeaders decodes the headers of a Headers message.
+func DecodeHeaders(m *Headers) ([]Header, error) {
+    headers := make([]Header, len(m.GetHeaders()))
+    for i, data := range m.GetHeaders() {
+        if err := headers[i].UnmarshalBinary(data); err != nil {
+            return nil, fmt.Errorf("header %d: %w", i, err)
+        }
+    }
+    return headers, nil
+}
+
+// EncodeHeaders builds a Headers message carrying headers.
+func EncodeHeaders(headers []Header) *Headers {
+    m := &Headers{Headers: make([][]byte, len(headers))}
+    for i := range headers {
+        m.Headers[i], _ = headers[i].MarshalBinary()
+    }
+    return m
+}
@@ -0,0 +1,31 @@
+package wire
+
+import (
+    "fmt"
+    "io"
+
+    "google.golang.org/protobuf/encoding/protojson"
+)
+
+// WriteSnapshot writes s to w as indented JSON, the same canonical mapping storage.FileStore uses for chains,
+// so a snapshot can be read, diffed and edited by hand before it is handed out as a fixture.
+func WriteSnapshot(w io.Writer, s *Snapshot) error {
+    data, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(s)
+    if err != nil {
+        return fmt.Errorf("wire: encode snapshot: %w", err)
+    }
+    if _, err := w.Write(append(data, '\n')); err != nil {
+        return fmt.Errorf("wire: write snapshot: %w", err)
+    }
+    return nil
+}
+
+// ReadSnapshot reads a snapshot written by WriteSnapshot from r and checks it with Validate. Input that does
+// not decode is reported with an error wrapping ErrMalformed.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+    data, err := io.ReadAll(r)
+    if err != nil {
+        return nil, fmt.Errorf("wire: read snapshot: %w", err)
+    }
+    return DecodeSnapshotJSON(data)
+}
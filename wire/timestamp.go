@@ -0,0 +1,44 @@
+package wire
+
+import (
+    "time"
+)
+
+// Timestamp is the creation time of a block, in nanoseconds since the Unix epoch. Blocks used to record
+// time.Time.String(), whose output depends on the local time zone and on the monotonic clock reading the runtime
+// happens to attach, so the same block hashed differently on two machines. An integer hashes the same
+// everywhere, and takes a varint in Block rather than a string of forty-odd bytes.
+//
+// The zero Timestamp is both the Unix epoch and what the zero time.Time converts to, which is how a genesis
+// block without a configured time is stamped.
+type Timestamp int64
+
+// TimestampOf converts t to a Timestamp. Times that nanoseconds since the epoch cannot represent, before 1678
+// or after 2262, are clamped to the nearest one that can; the zero time.Time becomes the zero Timestamp.
+func TimestampOf(t time.Time) Timestamp {
+    switch {
+    case t.IsZero():
+        return 0
+    case t.Before(minTime):
+        return Timestamp(minTime.UnixNano())
+    case t.After(maxTime):
+        return Timestamp(maxTime.UnixNano())
+    }
+    return Timestamp(t.UnixNano())
+}
+
+// minTime and maxTime are the earliest and latest times a Timestamp represents.
+var (
+    minTime = time.Unix(0, -1<<63)
+    maxTime = time.Unix(0, 1<<63-1)
+)
+
+// Time returns the timestamp as a time.Time in UTC.
+func (ts Timestamp) Time() time.Time {
+    return time.Unix(0, int64(ts)).UTC()
+}
+
+// String formats the timestamp for display, in RFC 3339 with as many fractional digits as it needs, in UTC.
+func (ts Timestamp) String() string {
+    return ts.Time().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,79 @@
+// Package wire defines the messages that consensus nodes exchange, generated from wire.proto.
+// Raft's RequestVote and AppendEntries, PBFT's three phases and view change, Paxos' prepare and
+// accept rounds, and the block proposals of PoW, PoS and DPoS are all carried inside a single
+// Envelope type. Keeping one schema for every algorithm means the in-process simulation and any
+// real network transport speak exactly the same format, so a message captured from one can be
+// replayed through the other.
+package wire
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative wire.proto
+
+// Kind returns a short, stable name for the message carried by the envelope, such as
+// "AppendEntries" or "Prepare". It is intended for logging, metrics and per-type fault rules.
+func (e *Envelope) Kind() string {
+    switch e.GetBody().(type) {
+    case *Envelope_RequestVote:
+        return "RequestVote"
+    case *Envelope_RequestVoteResponse:
+        return "RequestVoteResponse"
+    case *Envelope_AppendEntries:
+        return "AppendEntries"
+    case *Envelope_AppendEntriesResponse:
+        return "AppendEntriesResponse"
+    case *Envelope_PrePrepare:
+        return "PrePrepare"
+    case *Envelope_Prepare:
+        return "Prepare"
+    case *Envelope_Commit:
+        return "Commit"
+    case *Envelope_ViewChange:
+        return "ViewChange"
+    case *Envelope_NewView:
+        return "NewView"
+    case *Envelope_Request:
+        return "Request"
+    case *Envelope_PaxosPrepare:
+        return "PaxosPrepare"
+    case *Envelope_PaxosPromise:
+        return "PaxosPromise"
+    case *Envelope_PaxosAccept:
+        return "PaxosAccept"
+    case *Envelope_PaxosAccepted:
+        return "PaxosAccepted"
+    case *Envelope_BlockProposal:
+        return "BlockProposal"
+    case *Envelope_DelegateVote:
+        return "DelegateVote"
+    case *Envelope_BlockRequest:
+        return "BlockRequest"
+    case *Envelope_HeaderRequest:
+        return "HeaderRequest"
+    case *Envelope_Headers:
+        return "Headers"
+    case *Envelope_ChainSummary:
+        return "ChainSummary"
+    case *Envelope_Evidence:
+        return "Evidence"
+    case *Envelope_Statement:
+        return "Statement"
+    case *Envelope_CheckpointVote:
+        return "CheckpointVote"
+    }
+    return "Unknown" // An envelope without a body, or one produced by a newer schema.
+}
+
+// Footer: Architectural Decisions
+//
+// 1. **Protocol Buffers as the Schema**: The message definitions live in wire.proto and the Go types are generated
+//    from it. Protobuf gives a compact binary encoding for network transports, a canonical JSON mapping for tooling,
+//    and lets non-Go clients (visualizers, notebooks) decode the same messages.
+//
+// 2. **One Envelope for All Algorithms**: Rather than a schema per package, every message is a case of the Envelope
+//    oneof. Transports only ever move envelopes and never need to know which algorithm they are carrying.
+//
+// 3. **Algorithm-Neutral Blocks**: wire.Block is the union of the block fields used across the repository. Each
+//    algorithm package converts its own Block type to and from it with ToWire and BlockFromWire.
+//
+// 4. **Validation at the Edge**: Decoding and validation live next to the schema, and transports and stores call
+//    them as bytes come in. The replicas behind them can then index logs and chains by the numbers a message
+//    carries without repeating the same bounds checks in every algorithm.
@@ -0,0 +1,2778 @@
+// wire.proto defines the messages exchanged between consensus nodes.
+//
+// Every algorithm in this repository speaks through the same Envelope type, so the in-process
+// simulation and any network transport (gRPC, raw TCP, ...) share one wire format. Regenerate the
+// Go bindings with `go generate ./wire` after editing this file.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: wire.proto
+
+package wire
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Block is the algorithm-neutral encoding of a block. Fields that only make sense for some
+// algorithms (nonce for PoW, producer for PoS/DPoS, certificate for PBFT) are left at their zero value
+// by the others.
+type Block struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int64                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`                                   // Position of the block in the chain.
+	Timestamp     int64                  `protobuf:"varint,10,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                          // Creation time in nanoseconds since the Unix epoch, as hashed (see Timestamp).
+	Data          string                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`                                      // Payload carried by the block: its body, which the header commits to as its root.
+	PrevHash      string                 `protobuf:"bytes,4,opt,name=prev_hash,json=prevHash,proto3" json:"prev_hash,omitempty"`              // Hash of the parent block.
+	Hash          string                 `protobuf:"bytes,5,opt,name=hash,proto3" json:"hash,omitempty"`                                      // Hash of this block's header (see wire.Header).
+	Nonce         int64                  `protobuf:"varint,6,opt,name=nonce,proto3" json:"nonce,omitempty"`                                   // PoW only: the nonce that satisfies the difficulty target.
+	Producer      string                 `protobuf:"bytes,7,opt,name=producer,proto3" json:"producer,omitempty"`                              // PoS validator or DPoS delegate that produced the block.
+	Certificate   []string               `protobuf:"bytes,8,rep,name=certificate,proto3" json:"certificate,omitempty"`                        // PBFT only: replicas whose commit votes made the block final. Not hashed.
+	Difficulty    int32                  `protobuf:"varint,9,opt,name=difficulty,proto3" json:"difficulty,omitempty"`                         // PoW only: leading zeros the hash must have; the default difficulty if 0.
+	StateRoot     string                 `protobuf:"bytes,11,opt,name=state_root,json=stateRoot,proto3" json:"state_root,omitempty"`          // PoW, PoS and PBFT only: root of the ledger state after the block, if one was attached.
+	ReceiptsRoot  string                 `protobuf:"bytes,12,opt,name=receipts_root,json=receiptsRoot,proto3" json:"receipts_root,omitempty"` // PoW, PoS and PBFT only: root of the receipts of the block's transactions, if any.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Block) Reset() {
+	*x = Block{}
+	mi := &file_wire_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Block) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Block) ProtoMessage() {}
+
+func (x *Block) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Block.ProtoReflect.Descriptor instead.
+func (*Block) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Block) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *Block) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Block) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *Block) GetPrevHash() string {
+	if x != nil {
+		return x.PrevHash
+	}
+	return ""
+}
+
+func (x *Block) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Block) GetNonce() int64 {
+	if x != nil {
+		return x.Nonce
+	}
+	return 0
+}
+
+func (x *Block) GetProducer() string {
+	if x != nil {
+		return x.Producer
+	}
+	return ""
+}
+
+func (x *Block) GetCertificate() []string {
+	if x != nil {
+		return x.Certificate
+	}
+	return nil
+}
+
+func (x *Block) GetDifficulty() int32 {
+	if x != nil {
+		return x.Difficulty
+	}
+	return 0
+}
+
+func (x *Block) GetStateRoot() string {
+	if x != nil {
+		return x.StateRoot
+	}
+	return ""
+}
+
+func (x *Block) GetReceiptsRoot() string {
+	if x != nil {
+		return x.ReceiptsRoot
+	}
+	return ""
+}
+
+// Chain is a complete chain of blocks as written to disk or handed between processes.
+type Chain struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Algorithm     string                 `protobuf:"bytes,1,opt,name=algorithm,proto3" json:"algorithm,omitempty"` // Name of the algorithm that produced the chain, e.g. "pow".
+	Blocks        []*Block               `protobuf:"bytes,2,rep,name=blocks,proto3" json:"blocks,omitempty"`       // Blocks in order, starting with the genesis block.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Chain) Reset() {
+	*x = Chain{}
+	mi := &file_wire_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Chain) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Chain) ProtoMessage() {}
+
+func (x *Chain) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Chain.ProtoReflect.Descriptor instead.
+func (*Chain) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Chain) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *Chain) GetBlocks() []*Block {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+// Snapshot is a chain together with the state of the network that produced it, such as stakes or delegate
+// votes, which no block records. Resuming from a snapshot continues the run where it was exported. Fields that
+// do not apply to the chain's algorithm are left empty.
+type Snapshot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chain         *Chain                 `protobuf:"bytes,1,opt,name=chain,proto3" json:"chain,omitempty"`                                                                           // The blocks, and the algorithm that produced them.
+	Difficulty    int32                  `protobuf:"varint,2,opt,name=difficulty,proto3" json:"difficulty,omitempty"`                                                                // PoW only: leading zeros new blocks must have; the default difficulty if 0.
+	Stakes        []*Stake               `protobuf:"bytes,3,rep,name=stakes,proto3" json:"stakes,omitempty"`                                                                         // PoS only: the validators, in selection order, and their stakes.
+	Delegates     []string               `protobuf:"bytes,4,rep,name=delegates,proto3" json:"delegates,omitempty"`                                                                   // DPoS only: the delegates elected by the last vote count, in order.
+	Votes         map[string]string      `protobuf:"bytes,5,rep,name=votes,proto3" json:"votes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // DPoS only: the delegate each voter voted for.
+	Members       []*Member              `protobuf:"bytes,6,rep,name=members,proto3" json:"members,omitempty"`                                                                       // PBFT, Raft and Paxos only: the nodes of the network, in order.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Snapshot) Reset() {
+	*x = Snapshot{}
+	mi := &file_wire_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Snapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snapshot) ProtoMessage() {}
+
+func (x *Snapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snapshot.ProtoReflect.Descriptor instead.
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Snapshot) GetChain() *Chain {
+	if x != nil {
+		return x.Chain
+	}
+	return nil
+}
+
+func (x *Snapshot) GetDifficulty() int32 {
+	if x != nil {
+		return x.Difficulty
+	}
+	return 0
+}
+
+func (x *Snapshot) GetStakes() []*Stake {
+	if x != nil {
+		return x.Stakes
+	}
+	return nil
+}
+
+func (x *Snapshot) GetDelegates() []string {
+	if x != nil {
+		return x.Delegates
+	}
+	return nil
+}
+
+func (x *Snapshot) GetVotes() map[string]string {
+	if x != nil {
+		return x.Votes
+	}
+	return nil
+}
+
+func (x *Snapshot) GetMembers() []*Member {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+// Stake is a Proof of Stake validator and the stake it holds.
+type Stake struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Validator     string                 `protobuf:"bytes,1,opt,name=validator,proto3" json:"validator,omitempty"`
+	Amount        int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Stake) Reset() {
+	*x = Stake{}
+	mi := &file_wire_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stake) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stake) ProtoMessage() {}
+
+func (x *Stake) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stake.ProtoReflect.Descriptor instead.
+func (*Stake) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Stake) GetValidator() string {
+	if x != nil {
+		return x.Validator
+	}
+	return ""
+}
+
+func (x *Stake) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+// Member is a node of a PBFT, Raft or Paxos network.
+type Member struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Leader        bool                   `protobuf:"varint,2,opt,name=leader,proto3" json:"leader,omitempty"`      // Raft leader or PBFT primary.
+	Faulty        bool                   `protobuf:"varint,3,opt,name=faulty,proto3" json:"faulty,omitempty"`      // Started faulty with options.WithFaulty.
+	Crashed       bool                   `protobuf:"varint,4,opt,name=crashed,proto3" json:"crashed,omitempty"`    // Crashed and not yet recovered.
+	Proposals     []*PaxosProposal       `protobuf:"bytes,5,rep,name=proposals,proto3" json:"proposals,omitempty"` // Paxos only: proposals the node made or accepted.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Member) Reset() {
+	*x = Member{}
+	mi := &file_wire_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Member) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Member) ProtoMessage() {}
+
+func (x *Member) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Member.ProtoReflect.Descriptor instead.
+func (*Member) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Member) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Member) GetLeader() bool {
+	if x != nil {
+		return x.Leader
+	}
+	return false
+}
+
+func (x *Member) GetFaulty() bool {
+	if x != nil {
+		return x.Faulty
+	}
+	return false
+}
+
+func (x *Member) GetCrashed() bool {
+	if x != nil {
+		return x.Crashed
+	}
+	return false
+}
+
+func (x *Member) GetProposals() []*PaxosProposal {
+	if x != nil {
+		return x.Proposals
+	}
+	return nil
+}
+
+// PaxosProposal is a proposal a Paxos node made or accepted.
+type PaxosProposal struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProposalId    int64                  `protobuf:"varint,1,opt,name=proposal_id,json=proposalId,proto3" json:"proposal_id,omitempty"`
+	Data          string                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Accepted      bool                   `protobuf:"varint,3,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaxosProposal) Reset() {
+	*x = PaxosProposal{}
+	mi := &file_wire_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaxosProposal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaxosProposal) ProtoMessage() {}
+
+func (x *PaxosProposal) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaxosProposal.ProtoReflect.Descriptor instead.
+func (*PaxosProposal) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PaxosProposal) GetProposalId() int64 {
+	if x != nil {
+		return x.ProposalId
+	}
+	return 0
+}
+
+func (x *PaxosProposal) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *PaxosProposal) GetAccepted() bool {
+	if x != nil {
+		return x.Accepted
+	}
+	return false
+}
+
+// RequestVote is sent by a candidate to ask a peer for its vote in the given term.
+type RequestVote struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Term          uint64                 `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`
+	CandidateId   int32                  `protobuf:"varint,2,opt,name=candidate_id,json=candidateId,proto3" json:"candidate_id,omitempty"`
+	LastLogIndex  int64                  `protobuf:"varint,3,opt,name=last_log_index,json=lastLogIndex,proto3" json:"last_log_index,omitempty"`
+	LastLogTerm   uint64                 `protobuf:"varint,4,opt,name=last_log_term,json=lastLogTerm,proto3" json:"last_log_term,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestVote) Reset() {
+	*x = RequestVote{}
+	mi := &file_wire_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestVote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestVote) ProtoMessage() {}
+
+func (x *RequestVote) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestVote.ProtoReflect.Descriptor instead.
+func (*RequestVote) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RequestVote) GetTerm() uint64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *RequestVote) GetCandidateId() int32 {
+	if x != nil {
+		return x.CandidateId
+	}
+	return 0
+}
+
+func (x *RequestVote) GetLastLogIndex() int64 {
+	if x != nil {
+		return x.LastLogIndex
+	}
+	return 0
+}
+
+func (x *RequestVote) GetLastLogTerm() uint64 {
+	if x != nil {
+		return x.LastLogTerm
+	}
+	return 0
+}
+
+// RequestVoteResponse carries a peer's answer to RequestVote.
+type RequestVoteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Term          uint64                 `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`
+	VoteGranted   bool                   `protobuf:"varint,2,opt,name=vote_granted,json=voteGranted,proto3" json:"vote_granted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestVoteResponse) Reset() {
+	*x = RequestVoteResponse{}
+	mi := &file_wire_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestVoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestVoteResponse) ProtoMessage() {}
+
+func (x *RequestVoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestVoteResponse.ProtoReflect.Descriptor instead.
+func (*RequestVoteResponse) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RequestVoteResponse) GetTerm() uint64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *RequestVoteResponse) GetVoteGranted() bool {
+	if x != nil {
+		return x.VoteGranted
+	}
+	return false
+}
+
+// Entry is a single Raft log entry: a block tagged with the term in which it was appended.
+type Entry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Term          uint64                 `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`
+	Block         *Block                 `protobuf:"bytes,2,opt,name=block,proto3" json:"block,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Entry) Reset() {
+	*x = Entry{}
+	mi := &file_wire_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Entry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entry) ProtoMessage() {}
+
+func (x *Entry) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entry.ProtoReflect.Descriptor instead.
+func (*Entry) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Entry) GetTerm() uint64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *Entry) GetBlock() *Block {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
+// AppendEntries replicates log entries from the leader; an empty entries list is a heartbeat.
+type AppendEntries struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Term          uint64                 `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`
+	LeaderId      int32                  `protobuf:"varint,2,opt,name=leader_id,json=leaderId,proto3" json:"leader_id,omitempty"`
+	PrevLogIndex  int64                  `protobuf:"varint,3,opt,name=prev_log_index,json=prevLogIndex,proto3" json:"prev_log_index,omitempty"`
+	PrevLogTerm   uint64                 `protobuf:"varint,4,opt,name=prev_log_term,json=prevLogTerm,proto3" json:"prev_log_term,omitempty"`
+	Entries       []*Entry               `protobuf:"bytes,5,rep,name=entries,proto3" json:"entries,omitempty"`
+	LeaderCommit  int64                  `protobuf:"varint,6,opt,name=leader_commit,json=leaderCommit,proto3" json:"leader_commit,omitempty"`
+	Sent          int64                  `protobuf:"varint,7,opt,name=sent,proto3" json:"sent,omitempty"` // When the leader sent it, in nanoseconds on the leader's clock; echoed in the response.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppendEntries) Reset() {
+	*x = AppendEntries{}
+	mi := &file_wire_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppendEntries) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppendEntries) ProtoMessage() {}
+
+func (x *AppendEntries) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppendEntries.ProtoReflect.Descriptor instead.
+func (*AppendEntries) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AppendEntries) GetTerm() uint64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *AppendEntries) GetLeaderId() int32 {
+	if x != nil {
+		return x.LeaderId
+	}
+	return 0
+}
+
+func (x *AppendEntries) GetPrevLogIndex() int64 {
+	if x != nil {
+		return x.PrevLogIndex
+	}
+	return 0
+}
+
+func (x *AppendEntries) GetPrevLogTerm() uint64 {
+	if x != nil {
+		return x.PrevLogTerm
+	}
+	return 0
+}
+
+func (x *AppendEntries) GetEntries() []*Entry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *AppendEntries) GetLeaderCommit() int64 {
+	if x != nil {
+		return x.LeaderCommit
+	}
+	return 0
+}
+
+func (x *AppendEntries) GetSent() int64 {
+	if x != nil {
+		return x.Sent
+	}
+	return 0
+}
+
+// AppendEntriesResponse reports whether a follower accepted AppendEntries and how far its log
+// now matches the leader's.
+type AppendEntriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Term          uint64                 `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	MatchIndex    int64                  `protobuf:"varint,3,opt,name=match_index,json=matchIndex,proto3" json:"match_index,omitempty"`
+	Sent          int64                  `protobuf:"varint,4,opt,name=sent,proto3" json:"sent,omitempty"` // The sent time of the AppendEntries answered, so a leader can tell whose answer it is.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppendEntriesResponse) Reset() {
+	*x = AppendEntriesResponse{}
+	mi := &file_wire_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppendEntriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppendEntriesResponse) ProtoMessage() {}
+
+func (x *AppendEntriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppendEntriesResponse.ProtoReflect.Descriptor instead.
+func (*AppendEntriesResponse) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AppendEntriesResponse) GetTerm() uint64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *AppendEntriesResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *AppendEntriesResponse) GetMatchIndex() int64 {
+	if x != nil {
+		return x.MatchIndex
+	}
+	return 0
+}
+
+func (x *AppendEntriesResponse) GetSent() int64 {
+	if x != nil {
+		return x.Sent
+	}
+	return 0
+}
+
+// PrePrepare is broadcast by the primary to assign a sequence number to a block in a view.
+type PrePrepare struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	View          uint64                 `protobuf:"varint,1,opt,name=view,proto3" json:"view,omitempty"`
+	Sequence      int64                  `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Digest        string                 `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"`
+	Block         *Block                 `protobuf:"bytes,4,opt,name=block,proto3" json:"block,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrePrepare) Reset() {
+	*x = PrePrepare{}
+	mi := &file_wire_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrePrepare) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrePrepare) ProtoMessage() {}
+
+func (x *PrePrepare) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrePrepare.ProtoReflect.Descriptor instead.
+func (*PrePrepare) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PrePrepare) GetView() uint64 {
+	if x != nil {
+		return x.View
+	}
+	return 0
+}
+
+func (x *PrePrepare) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *PrePrepare) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *PrePrepare) GetBlock() *Block {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
+// Prepare is broadcast by a replica that accepted the matching PrePrepare.
+type Prepare struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	View          uint64                 `protobuf:"varint,1,opt,name=view,proto3" json:"view,omitempty"`
+	Sequence      int64                  `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Digest        string                 `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Prepare) Reset() {
+	*x = Prepare{}
+	mi := &file_wire_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Prepare) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Prepare) ProtoMessage() {}
+
+func (x *Prepare) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Prepare.ProtoReflect.Descriptor instead.
+func (*Prepare) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Prepare) GetView() uint64 {
+	if x != nil {
+		return x.View
+	}
+	return 0
+}
+
+func (x *Prepare) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *Prepare) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+// Commit is broadcast once a replica has collected a prepared certificate (2f+1 Prepares).
+type Commit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	View          uint64                 `protobuf:"varint,1,opt,name=view,proto3" json:"view,omitempty"`
+	Sequence      int64                  `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Digest        string                 `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Commit) Reset() {
+	*x = Commit{}
+	mi := &file_wire_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Commit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Commit) ProtoMessage() {}
+
+func (x *Commit) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Commit.ProtoReflect.Descriptor instead.
+func (*Commit) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Commit) GetView() uint64 {
+	if x != nil {
+		return x.View
+	}
+	return 0
+}
+
+func (x *Commit) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *Commit) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+// ViewChange asks the other replicas to move to a new view because the primary is suspected.
+// It carries the sequence number of the last block the replica executed, and its executed blocks and
+// the PrePrepares it has prepared in any view since, so the new primary can carry them into the new view.
+type ViewChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NewView       uint64                 `protobuf:"varint,1,opt,name=new_view,json=newView,proto3" json:"new_view,omitempty"`
+	LastSequence  int64                  `protobuf:"varint,2,opt,name=last_sequence,json=lastSequence,proto3" json:"last_sequence,omitempty"`
+	Prepared      []*PrePrepare          `protobuf:"bytes,3,rep,name=prepared,proto3" json:"prepared,omitempty"`
+	ReplicaId     int32                  `protobuf:"varint,4,opt,name=replica_id,json=replicaId,proto3" json:"replica_id,omitempty"` // Replica that sent the ViewChange, so NewView certificates can be checked.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ViewChange) Reset() {
+	*x = ViewChange{}
+	mi := &file_wire_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ViewChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ViewChange) ProtoMessage() {}
+
+func (x *ViewChange) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ViewChange.ProtoReflect.Descriptor instead.
+func (*ViewChange) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ViewChange) GetNewView() uint64 {
+	if x != nil {
+		return x.NewView
+	}
+	return 0
+}
+
+func (x *ViewChange) GetLastSequence() int64 {
+	if x != nil {
+		return x.LastSequence
+	}
+	return 0
+}
+
+func (x *ViewChange) GetPrepared() []*PrePrepare {
+	if x != nil {
+		return x.Prepared
+	}
+	return nil
+}
+
+func (x *ViewChange) GetReplicaId() int32 {
+	if x != nil {
+		return x.ReplicaId
+	}
+	return 0
+}
+
+// NewView is broadcast by the primary of the new view together with the 2f+1 ViewChange
+// messages that justify it and the PrePrepares it re-issues in the new view.
+type NewView struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	View          uint64                 `protobuf:"varint,1,opt,name=view,proto3" json:"view,omitempty"`
+	ViewChanges   []*ViewChange          `protobuf:"bytes,2,rep,name=view_changes,json=viewChanges,proto3" json:"view_changes,omitempty"`
+	PrePrepares   []*PrePrepare          `protobuf:"bytes,3,rep,name=pre_prepares,json=prePrepares,proto3" json:"pre_prepares,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NewView) Reset() {
+	*x = NewView{}
+	mi := &file_wire_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NewView) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewView) ProtoMessage() {}
+
+func (x *NewView) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewView.ProtoReflect.Descriptor instead.
+func (*NewView) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *NewView) GetView() uint64 {
+	if x != nil {
+		return x.View
+	}
+	return 0
+}
+
+func (x *NewView) GetViewChanges() []*ViewChange {
+	if x != nil {
+		return x.ViewChanges
+	}
+	return nil
+}
+
+func (x *NewView) GetPrePrepares() []*PrePrepare {
+	if x != nil {
+		return x.PrePrepares
+	}
+	return nil
+}
+
+// Request is a client operation submitted to a PBFT replica. Backups forward it to the primary
+// and start their view-change timer until it is executed.
+type Request struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          string                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Request) Reset() {
+	*x = Request{}
+	mi := &file_wire_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Request) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Request) ProtoMessage() {}
+
+func (x *Request) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Request.ProtoReflect.Descriptor instead.
+func (*Request) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Request) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+// PaxosPrepare is phase 1a: a proposer asks acceptors to promise a ballot for a slot.
+type PaxosPrepare struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ballot        int64                  `protobuf:"varint,1,opt,name=ballot,proto3" json:"ballot,omitempty"`
+	Slot          int64                  `protobuf:"varint,2,opt,name=slot,proto3" json:"slot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaxosPrepare) Reset() {
+	*x = PaxosPrepare{}
+	mi := &file_wire_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaxosPrepare) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaxosPrepare) ProtoMessage() {}
+
+func (x *PaxosPrepare) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaxosPrepare.ProtoReflect.Descriptor instead.
+func (*PaxosPrepare) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *PaxosPrepare) GetBallot() int64 {
+	if x != nil {
+		return x.Ballot
+	}
+	return 0
+}
+
+func (x *PaxosPrepare) GetSlot() int64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+// PaxosPromise is phase 1b: an acceptor promises the ballot and reports any value it accepted.
+type PaxosPromise struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Ballot         int64                  `protobuf:"varint,1,opt,name=ballot,proto3" json:"ballot,omitempty"`
+	Slot           int64                  `protobuf:"varint,2,opt,name=slot,proto3" json:"slot,omitempty"`
+	AcceptedBallot int64                  `protobuf:"varint,3,opt,name=accepted_ballot,json=acceptedBallot,proto3" json:"accepted_ballot,omitempty"`
+	AcceptedValue  *Block                 `protobuf:"bytes,4,opt,name=accepted_value,json=acceptedValue,proto3" json:"accepted_value,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PaxosPromise) Reset() {
+	*x = PaxosPromise{}
+	mi := &file_wire_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaxosPromise) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaxosPromise) ProtoMessage() {}
+
+func (x *PaxosPromise) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaxosPromise.ProtoReflect.Descriptor instead.
+func (*PaxosPromise) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *PaxosPromise) GetBallot() int64 {
+	if x != nil {
+		return x.Ballot
+	}
+	return 0
+}
+
+func (x *PaxosPromise) GetSlot() int64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *PaxosPromise) GetAcceptedBallot() int64 {
+	if x != nil {
+		return x.AcceptedBallot
+	}
+	return 0
+}
+
+func (x *PaxosPromise) GetAcceptedValue() *Block {
+	if x != nil {
+		return x.AcceptedValue
+	}
+	return nil
+}
+
+// PaxosAccept is phase 2a: the proposer asks acceptors to accept a value under its ballot.
+type PaxosAccept struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ballot        int64                  `protobuf:"varint,1,opt,name=ballot,proto3" json:"ballot,omitempty"`
+	Slot          int64                  `protobuf:"varint,2,opt,name=slot,proto3" json:"slot,omitempty"`
+	Value         *Block                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaxosAccept) Reset() {
+	*x = PaxosAccept{}
+	mi := &file_wire_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaxosAccept) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaxosAccept) ProtoMessage() {}
+
+func (x *PaxosAccept) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaxosAccept.ProtoReflect.Descriptor instead.
+func (*PaxosAccept) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PaxosAccept) GetBallot() int64 {
+	if x != nil {
+		return x.Ballot
+	}
+	return 0
+}
+
+func (x *PaxosAccept) GetSlot() int64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *PaxosAccept) GetValue() *Block {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+// PaxosAccepted is phase 2b: an acceptor reports that it accepted the value.
+type PaxosAccepted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ballot        int64                  `protobuf:"varint,1,opt,name=ballot,proto3" json:"ballot,omitempty"`
+	Slot          int64                  `protobuf:"varint,2,opt,name=slot,proto3" json:"slot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaxosAccepted) Reset() {
+	*x = PaxosAccepted{}
+	mi := &file_wire_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaxosAccepted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaxosAccepted) ProtoMessage() {}
+
+func (x *PaxosAccepted) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaxosAccepted.ProtoReflect.Descriptor instead.
+func (*PaxosAccepted) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *PaxosAccepted) GetBallot() int64 {
+	if x != nil {
+		return x.Ballot
+	}
+	return 0
+}
+
+func (x *PaxosAccepted) GetSlot() int64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+// BlockProposal announces a newly mined (PoW) or produced (PoS/DPoS) block to peers.
+type BlockProposal struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Block         *Block                 `protobuf:"bytes,1,opt,name=block,proto3" json:"block,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlockProposal) Reset() {
+	*x = BlockProposal{}
+	mi := &file_wire_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlockProposal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockProposal) ProtoMessage() {}
+
+func (x *BlockProposal) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockProposal.ProtoReflect.Descriptor instead.
+func (*BlockProposal) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *BlockProposal) GetBlock() *Block {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
+// DelegateVote records a DPoS voter's choice of delegate.
+type DelegateVote struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Voter         string                 `protobuf:"bytes,1,opt,name=voter,proto3" json:"voter,omitempty"`
+	Delegate      string                 `protobuf:"bytes,2,opt,name=delegate,proto3" json:"delegate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DelegateVote) Reset() {
+	*x = DelegateVote{}
+	mi := &file_wire_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DelegateVote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DelegateVote) ProtoMessage() {}
+
+func (x *DelegateVote) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DelegateVote.ProtoReflect.Descriptor instead.
+func (*DelegateVote) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DelegateVote) GetVoter() string {
+	if x != nil {
+		return x.Voter
+	}
+	return ""
+}
+
+func (x *DelegateVote) GetDelegate() string {
+	if x != nil {
+		return x.Delegate
+	}
+	return ""
+}
+
+// BlockRequest asks a peer for the block with the given hash, typically the unknown parent of a block
+// just received. The peer answers with a BlockProposal carrying that block.
+type BlockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          string                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlockRequest) Reset() {
+	*x = BlockRequest{}
+	mi := &file_wire_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockRequest) ProtoMessage() {}
+
+func (x *BlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockRequest.ProtoReflect.Descriptor instead.
+func (*BlockRequest) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *BlockRequest) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+// HeaderRequest asks a peer for the headers of the chain it follows, for a node that follows a chain from its
+// headers alone or checks a branch's headers before fetching its blocks.
+type HeaderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          int64                  `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`   // Index of the first header wanted.
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // Most headers wanted; the peer's own maximum if 0.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeaderRequest) Reset() {
+	*x = HeaderRequest{}
+	mi := &file_wire_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeaderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeaderRequest) ProtoMessage() {}
+
+func (x *HeaderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeaderRequest.ProtoReflect.Descriptor instead.
+func (*HeaderRequest) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *HeaderRequest) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *HeaderRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// Headers answers a HeaderRequest with consecutive headers of the peer's chain, each in the fixed-size binary
+// encoding of wire.Header, which is what the block hash is computed over.
+type Headers struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Headers       [][]byte               `protobuf:"bytes,1,rep,name=headers,proto3" json:"headers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Headers) Reset() {
+	*x = Headers{}
+	mi := &file_wire_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Headers) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Headers) ProtoMessage() {}
+
+func (x *Headers) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Headers.ProtoReflect.Descriptor instead.
+func (*Headers) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *Headers) GetHeaders() [][]byte {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+// MerkleRange is the Merkle root of the blocks at indexes [from, to) of the sender's chain: the hash of a single
+// block for a range of one, and otherwise the SHA-256 of the roots of its two halves, the first half ending at
+// from + (to - from) / 2. The root is empty if the sender's chain does not reach index to - 1.
+type MerkleRange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          int64                  `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            int64                  `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`
+	Root          []byte                 `protobuf:"bytes,3,opt,name=root,proto3" json:"root,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MerkleRange) Reset() {
+	*x = MerkleRange{}
+	mi := &file_wire_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MerkleRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MerkleRange) ProtoMessage() {}
+
+func (x *MerkleRange) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MerkleRange.ProtoReflect.Descriptor instead.
+func (*MerkleRange) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *MerkleRange) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *MerkleRange) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *MerkleRange) GetRoot() []byte {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+// ChainSummary carries Merkle roots of ranges of the sender's chain, for anti-entropy repair. The receiver
+// compares each range with its own chain and answers with the roots of both halves of every range that differs,
+// until the ranges that differ are single blocks, which it then requests with a BlockRequest.
+type ChainSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ranges        []*MerkleRange         `protobuf:"bytes,1,rep,name=ranges,proto3" json:"ranges,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChainSummary) Reset() {
+	*x = ChainSummary{}
+	mi := &file_wire_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChainSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChainSummary) ProtoMessage() {}
+
+func (x *ChainSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChainSummary.ProtoReflect.Descriptor instead.
+func (*ChainSummary) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ChainSummary) GetRanges() []*MerkleRange {
+	if x != nil {
+		return x.Ranges
+	}
+	return nil
+}
+
+// Statement is what a node said about one slot: the block it proposed at a height, or the digest it voted for
+// in a PBFT phase of a view and sequence number, together with its signature (see package evidence).
+type Statement struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Signer        int32                  `protobuf:"varint,1,opt,name=signer,proto3" json:"signer,omitempty"`      // Node that made the statement.
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`           // Message it was made in: "block", "pre-prepare", "prepare" or "commit".
+	Height        int64                  `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`      // Block index, or PBFT sequence number.
+	Round         uint64                 `protobuf:"varint,4,opt,name=round,proto3" json:"round,omitempty"`        // PBFT view, or the timestamp of a block, so a producer may build at a height again later.
+	Value         string                 `protobuf:"bytes,5,opt,name=value,proto3" json:"value,omitempty"`         // Hash of the block proposed, or digest voted for.
+	Signature     []byte                 `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"` // Ed25519 signature of the signer over the fields above.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Statement) Reset() {
+	*x = Statement{}
+	mi := &file_wire_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Statement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Statement) ProtoMessage() {}
+
+func (x *Statement) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Statement.ProtoReflect.Descriptor instead.
+func (*Statement) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *Statement) GetSigner() int32 {
+	if x != nil {
+		return x.Signer
+	}
+	return 0
+}
+
+func (x *Statement) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Statement) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Statement) GetRound() uint64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *Statement) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Statement) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+// Evidence proves that a node equivocated: it carries two statements the node signed for the same slot with
+// different values, which anyone holding the node's public key can check.
+type Evidence struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	First         *Statement             `protobuf:"bytes,1,opt,name=first,proto3" json:"first,omitempty"`
+	Second        *Statement             `protobuf:"bytes,2,opt,name=second,proto3" json:"second,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Evidence) Reset() {
+	*x = Evidence{}
+	mi := &file_wire_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Evidence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Evidence) ProtoMessage() {}
+
+func (x *Evidence) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Evidence.ProtoReflect.Descriptor instead.
+func (*Evidence) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *Evidence) GetFirst() *Statement {
+	if x != nil {
+		return x.First
+	}
+	return nil
+}
+
+func (x *Evidence) GetSecond() *Statement {
+	if x != nil {
+		return x.Second
+	}
+	return nil
+}
+
+// CheckpointVote is a committee validator's Casper FFG vote: a link from a justified checkpoint of its chain, the
+// source, to a later checkpoint of the same chain, the target (see package casper).
+type CheckpointVote struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Validator     int32                  `protobuf:"varint,1,opt,name=validator,proto3" json:"validator,omitempty"`
+	SourceHeight  int64                  `protobuf:"varint,2,opt,name=source_height,json=sourceHeight,proto3" json:"source_height,omitempty"`
+	SourceHash    string                 `protobuf:"bytes,3,opt,name=source_hash,json=sourceHash,proto3" json:"source_hash,omitempty"`
+	TargetHeight  int64                  `protobuf:"varint,4,opt,name=target_height,json=targetHeight,proto3" json:"target_height,omitempty"`
+	TargetHash    string                 `protobuf:"bytes,5,opt,name=target_hash,json=targetHash,proto3" json:"target_hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckpointVote) Reset() {
+	*x = CheckpointVote{}
+	mi := &file_wire_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckpointVote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckpointVote) ProtoMessage() {}
+
+func (x *CheckpointVote) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckpointVote.ProtoReflect.Descriptor instead.
+func (*CheckpointVote) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *CheckpointVote) GetValidator() int32 {
+	if x != nil {
+		return x.Validator
+	}
+	return 0
+}
+
+func (x *CheckpointVote) GetSourceHeight() int64 {
+	if x != nil {
+		return x.SourceHeight
+	}
+	return 0
+}
+
+func (x *CheckpointVote) GetSourceHash() string {
+	if x != nil {
+		return x.SourceHash
+	}
+	return ""
+}
+
+func (x *CheckpointVote) GetTargetHeight() int64 {
+	if x != nil {
+		return x.TargetHeight
+	}
+	return 0
+}
+
+func (x *CheckpointVote) GetTargetHash() string {
+	if x != nil {
+		return x.TargetHash
+	}
+	return ""
+}
+
+// Envelope wraps every message sent between nodes with its sender and recipient.
+type Envelope struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	From      int32                  `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
+	To        int32                  `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`
+	Signature []byte                 `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"` // Sender's signature over the Statement the body makes, if the sender signs (see package evidence).
+	// Types that are valid to be assigned to Body:
+	//
+	//	*Envelope_RequestVote
+	//	*Envelope_RequestVoteResponse
+	//	*Envelope_AppendEntries
+	//	*Envelope_AppendEntriesResponse
+	//	*Envelope_PrePrepare
+	//	*Envelope_Prepare
+	//	*Envelope_Commit
+	//	*Envelope_ViewChange
+	//	*Envelope_NewView
+	//	*Envelope_Request
+	//	*Envelope_PaxosPrepare
+	//	*Envelope_PaxosPromise
+	//	*Envelope_PaxosAccept
+	//	*Envelope_PaxosAccepted
+	//	*Envelope_BlockProposal
+	//	*Envelope_DelegateVote
+	//	*Envelope_BlockRequest
+	//	*Envelope_HeaderRequest
+	//	*Envelope_Headers
+	//	*Envelope_ChainSummary
+	//	*Envelope_Evidence
+	//	*Envelope_Statement
+	//	*Envelope_CheckpointVote
+	Body          isEnvelope_Body `protobuf_oneof:"body"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Envelope) Reset() {
+	*x = Envelope{}
+	mi := &file_wire_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Envelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Envelope) ProtoMessage() {}
+
+func (x *Envelope) ProtoReflect() protoreflect.Message {
+	mi := &file_wire_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return file_wire_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *Envelope) GetFrom() int32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *Envelope) GetTo() int32 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *Envelope) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *Envelope) GetBody() isEnvelope_Body {
+	if x != nil {
+		return x.Body
+	}
+	return nil
+}
+
+func (x *Envelope) GetRequestVote() *RequestVote {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_RequestVote); ok {
+			return x.RequestVote
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetRequestVoteResponse() *RequestVoteResponse {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_RequestVoteResponse); ok {
+			return x.RequestVoteResponse
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetAppendEntries() *AppendEntries {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_AppendEntries); ok {
+			return x.AppendEntries
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetAppendEntriesResponse() *AppendEntriesResponse {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_AppendEntriesResponse); ok {
+			return x.AppendEntriesResponse
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetPrePrepare() *PrePrepare {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_PrePrepare); ok {
+			return x.PrePrepare
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetPrepare() *Prepare {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_Prepare); ok {
+			return x.Prepare
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetCommit() *Commit {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_Commit); ok {
+			return x.Commit
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetViewChange() *ViewChange {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_ViewChange); ok {
+			return x.ViewChange
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetNewView() *NewView {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_NewView); ok {
+			return x.NewView
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetRequest() *Request {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_Request); ok {
+			return x.Request
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetPaxosPrepare() *PaxosPrepare {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_PaxosPrepare); ok {
+			return x.PaxosPrepare
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetPaxosPromise() *PaxosPromise {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_PaxosPromise); ok {
+			return x.PaxosPromise
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetPaxosAccept() *PaxosAccept {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_PaxosAccept); ok {
+			return x.PaxosAccept
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetPaxosAccepted() *PaxosAccepted {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_PaxosAccepted); ok {
+			return x.PaxosAccepted
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetBlockProposal() *BlockProposal {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_BlockProposal); ok {
+			return x.BlockProposal
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetDelegateVote() *DelegateVote {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_DelegateVote); ok {
+			return x.DelegateVote
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetBlockRequest() *BlockRequest {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_BlockRequest); ok {
+			return x.BlockRequest
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetHeaderRequest() *HeaderRequest {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_HeaderRequest); ok {
+			return x.HeaderRequest
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetHeaders() *Headers {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_Headers); ok {
+			return x.Headers
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetChainSummary() *ChainSummary {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_ChainSummary); ok {
+			return x.ChainSummary
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetEvidence() *Evidence {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_Evidence); ok {
+			return x.Evidence
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetStatement() *Statement {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_Statement); ok {
+			return x.Statement
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetCheckpointVote() *CheckpointVote {
+	if x != nil {
+		if x, ok := x.Body.(*Envelope_CheckpointVote); ok {
+			return x.CheckpointVote
+		}
+	}
+	return nil
+}
+
+type isEnvelope_Body interface {
+	isEnvelope_Body()
+}
+
+type Envelope_RequestVote struct {
+	RequestVote *RequestVote `protobuf:"bytes,10,opt,name=request_vote,json=requestVote,proto3,oneof"`
+}
+
+type Envelope_RequestVoteResponse struct {
+	RequestVoteResponse *RequestVoteResponse `protobuf:"bytes,11,opt,name=request_vote_response,json=requestVoteResponse,proto3,oneof"`
+}
+
+type Envelope_AppendEntries struct {
+	AppendEntries *AppendEntries `protobuf:"bytes,12,opt,name=append_entries,json=appendEntries,proto3,oneof"`
+}
+
+type Envelope_AppendEntriesResponse struct {
+	AppendEntriesResponse *AppendEntriesResponse `protobuf:"bytes,13,opt,name=append_entries_response,json=appendEntriesResponse,proto3,oneof"`
+}
+
+type Envelope_PrePrepare struct {
+	PrePrepare *PrePrepare `protobuf:"bytes,20,opt,name=pre_prepare,json=prePrepare,proto3,oneof"`
+}
+
+type Envelope_Prepare struct {
+	Prepare *Prepare `protobuf:"bytes,21,opt,name=prepare,proto3,oneof"`
+}
+
+type Envelope_Commit struct {
+	Commit *Commit `protobuf:"bytes,22,opt,name=commit,proto3,oneof"`
+}
+
+type Envelope_ViewChange struct {
+	ViewChange *ViewChange `protobuf:"bytes,23,opt,name=view_change,json=viewChange,proto3,oneof"`
+}
+
+type Envelope_NewView struct {
+	NewView *NewView `protobuf:"bytes,24,opt,name=new_view,json=newView,proto3,oneof"`
+}
+
+type Envelope_Request struct {
+	Request *Request `protobuf:"bytes,25,opt,name=request,proto3,oneof"`
+}
+
+type Envelope_PaxosPrepare struct {
+	PaxosPrepare *PaxosPrepare `protobuf:"bytes,30,opt,name=paxos_prepare,json=paxosPrepare,proto3,oneof"`
+}
+
+type Envelope_PaxosPromise struct {
+	PaxosPromise *PaxosPromise `protobuf:"bytes,31,opt,name=paxos_promise,json=paxosPromise,proto3,oneof"`
+}
+
+type Envelope_PaxosAccept struct {
+	PaxosAccept *PaxosAccept `protobuf:"bytes,32,opt,name=paxos_accept,json=paxosAccept,proto3,oneof"`
+}
+
+type Envelope_PaxosAccepted struct {
+	PaxosAccepted *PaxosAccepted `protobuf:"bytes,33,opt,name=paxos_accepted,json=paxosAccepted,proto3,oneof"`
+}
+
+type Envelope_BlockProposal struct {
+	BlockProposal *BlockProposal `protobuf:"bytes,40,opt,name=block_proposal,json=blockProposal,proto3,oneof"`
+}
+
+type Envelope_DelegateVote struct {
+	DelegateVote *DelegateVote `protobuf:"bytes,41,opt,name=delegate_vote,json=delegateVote,proto3,oneof"`
+}
+
+type Envelope_BlockRequest struct {
+	BlockRequest *BlockRequest `protobuf:"bytes,42,opt,name=block_request,json=blockRequest,proto3,oneof"`
+}
+
+type Envelope_HeaderRequest struct {
+	HeaderRequest *HeaderRequest `protobuf:"bytes,43,opt,name=header_request,json=headerRequest,proto3,oneof"`
+}
+
+type Envelope_Headers struct {
+	Headers *Headers `protobuf:"bytes,44,opt,name=headers,proto3,oneof"`
+}
+
+type Envelope_ChainSummary struct {
+	ChainSummary *ChainSummary `protobuf:"bytes,45,opt,name=chain_summary,json=chainSummary,proto3,oneof"`
+}
+
+type Envelope_Evidence struct {
+	Evidence *Evidence `protobuf:"bytes,50,opt,name=evidence,proto3,oneof"`
+}
+
+type Envelope_Statement struct {
+	Statement *Statement `protobuf:"bytes,51,opt,name=statement,proto3,oneof"`
+}
+
+type Envelope_CheckpointVote struct {
+	CheckpointVote *CheckpointVote `protobuf:"bytes,60,opt,name=checkpoint_vote,json=checkpointVote,proto3,oneof"`
+}
+
+func (*Envelope_RequestVote) isEnvelope_Body() {}
+
+func (*Envelope_RequestVoteResponse) isEnvelope_Body() {}
+
+func (*Envelope_AppendEntries) isEnvelope_Body() {}
+
+func (*Envelope_AppendEntriesResponse) isEnvelope_Body() {}
+
+func (*Envelope_PrePrepare) isEnvelope_Body() {}
+
+func (*Envelope_Prepare) isEnvelope_Body() {}
+
+func (*Envelope_Commit) isEnvelope_Body() {}
+
+func (*Envelope_ViewChange) isEnvelope_Body() {}
+
+func (*Envelope_NewView) isEnvelope_Body() {}
+
+func (*Envelope_Request) isEnvelope_Body() {}
+
+func (*Envelope_PaxosPrepare) isEnvelope_Body() {}
+
+func (*Envelope_PaxosPromise) isEnvelope_Body() {}
+
+func (*Envelope_PaxosAccept) isEnvelope_Body() {}
+
+func (*Envelope_PaxosAccepted) isEnvelope_Body() {}
+
+func (*Envelope_BlockProposal) isEnvelope_Body() {}
+
+func (*Envelope_DelegateVote) isEnvelope_Body() {}
+
+func (*Envelope_BlockRequest) isEnvelope_Body() {}
+
+func (*Envelope_HeaderRequest) isEnvelope_Body() {}
+
+func (*Envelope_Headers) isEnvelope_Body() {}
+
+func (*Envelope_ChainSummary) isEnvelope_Body() {}
+
+func (*Envelope_Evidence) isEnvelope_Body() {}
+
+func (*Envelope_Statement) isEnvelope_Body() {}
+
+func (*Envelope_CheckpointVote) isEnvelope_Body() {}
+
+var File_wire_proto protoreflect.FileDescriptor
+
+const file_wire_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"wire.proto\x12\x0econsensus.wire\"\xbe\x02\n" +
+	"\x05Block\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x03R\x05index\x12\x1c\n" +
+	"\ttimestamp\x18\n" +
+	" \x01(\x03R\ttimestamp\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\tR\x04data\x12\x1b\n" +
+	"\tprev_hash\x18\x04 \x01(\tR\bprevHash\x12\x12\n" +
+	"\x04hash\x18\x05 \x01(\tR\x04hash\x12\x14\n" +
+	"\x05nonce\x18\x06 \x01(\x03R\x05nonce\x12\x1a\n" +
+	"\bproducer\x18\a \x01(\tR\bproducer\x12 \n" +
+	"\vcertificate\x18\b \x03(\tR\vcertificate\x12\x1e\n" +
+	"\n" +
+	"difficulty\x18\t \x01(\x05R\n" +
+	"difficulty\x12\x1d\n" +
+	"\n" +
+	"state_root\x18\v \x01(\tR\tstateRoot\x12#\n" +
+	"\rreceipts_root\x18\f \x01(\tR\freceiptsRootJ\x04\b\x02\x10\x03\"T\n" +
+	"\x05Chain\x12\x1c\n" +
+	"\talgorithm\x18\x01 \x01(\tR\talgorithm\x12-\n" +
+	"\x06blocks\x18\x02 \x03(\v2\x15.consensus.wire.BlockR\x06blocks\"\xcb\x02\n" +
+	"\bSnapshot\x12+\n" +
+	"\x05chain\x18\x01 \x01(\v2\x15.consensus.wire.ChainR\x05chain\x12\x1e\n" +
+	"\n" +
+	"difficulty\x18\x02 \x01(\x05R\n" +
+	"difficulty\x12-\n" +
+	"\x06stakes\x18\x03 \x03(\v2\x15.consensus.wire.StakeR\x06stakes\x12\x1c\n" +
+	"\tdelegates\x18\x04 \x03(\tR\tdelegates\x129\n" +
+	"\x05votes\x18\x05 \x03(\v2#.consensus.wire.Snapshot.VotesEntryR\x05votes\x120\n" +
+	"\amembers\x18\x06 \x03(\v2\x16.consensus.wire.MemberR\amembers\x1a8\n" +
+	"\n" +
+	"VotesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"=\n" +
+	"\x05Stake\x12\x1c\n" +
+	"\tvalidator\x18\x01 \x01(\tR\tvalidator\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\"\x9f\x01\n" +
+	"\x06Member\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x16\n" +
+	"\x06leader\x18\x02 \x01(\bR\x06leader\x12\x16\n" +
+	"\x06faulty\x18\x03 \x01(\bR\x06faulty\x12\x18\n" +
+	"\acrashed\x18\x04 \x01(\bR\acrashed\x12;\n" +
+	"\tproposals\x18\x05 \x03(\v2\x1d.consensus.wire.PaxosProposalR\tproposals\"`\n" +
+	"\rPaxosProposal\x12\x1f\n" +
+	"\vproposal_id\x18\x01 \x01(\x03R\n" +
+	"proposalId\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\tR\x04data\x12\x1a\n" +
+	"\baccepted\x18\x03 \x01(\bR\baccepted\"\x8e\x01\n" +
+	"\vRequestVote\x12\x12\n" +
+	"\x04term\x18\x01 \x01(\x04R\x04term\x12!\n" +
+	"\fcandidate_id\x18\x02 \x01(\x05R\vcandidateId\x12$\n" +
+	"\x0elast_log_index\x18\x03 \x01(\x03R\flastLogIndex\x12\"\n" +
+	"\rlast_log_term\x18\x04 \x01(\x04R\vlastLogTerm\"L\n" +
+	"\x13RequestVoteResponse\x12\x12\n" +
+	"\x04term\x18\x01 \x01(\x04R\x04term\x12!\n" +
+	"\fvote_granted\x18\x02 \x01(\bR\vvoteGranted\"H\n" +
+	"\x05Entry\x12\x12\n" +
+	"\x04term\x18\x01 \x01(\x04R\x04term\x12+\n" +
+	"\x05block\x18\x02 \x01(\v2\x15.consensus.wire.BlockR\x05block\"\xf4\x01\n" +
+	"\rAppendEntries\x12\x12\n" +
+	"\x04term\x18\x01 \x01(\x04R\x04term\x12\x1b\n" +
+	"\tleader_id\x18\x02 \x01(\x05R\bleaderId\x12$\n" +
+	"\x0eprev_log_index\x18\x03 \x01(\x03R\fprevLogIndex\x12\"\n" +
+	"\rprev_log_term\x18\x04 \x01(\x04R\vprevLogTerm\x12/\n" +
+	"\aentries\x18\x05 \x03(\v2\x15.consensus.wire.EntryR\aentries\x12#\n" +
+	"\rleader_commit\x18\x06 \x01(\x03R\fleaderCommit\x12\x12\n" +
+	"\x04sent\x18\a \x01(\x03R\x04sent\"z\n" +
+	"\x15AppendEntriesResponse\x12\x12\n" +
+	"\x04term\x18\x01 \x01(\x04R\x04term\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x1f\n" +
+	"\vmatch_index\x18\x03 \x01(\x03R\n" +
+	"matchIndex\x12\x12\n" +
+	"\x04sent\x18\x04 \x01(\x03R\x04sent\"\x81\x01\n" +
+	"\n" +
+	"PrePrepare\x12\x12\n" +
+	"\x04view\x18\x01 \x01(\x04R\x04view\x12\x1a\n" +
+	"\bsequence\x18\x02 \x01(\x03R\bsequence\x12\x16\n" +
+	"\x06digest\x18\x03 \x01(\tR\x06digest\x12+\n" +
+	"\x05block\x18\x04 \x01(\v2\x15.consensus.wire.BlockR\x05block\"Q\n" +
+	"\aPrepare\x12\x12\n" +
+	"\x04view\x18\x01 \x01(\x04R\x04view\x12\x1a\n" +
+	"\bsequence\x18\x02 \x01(\x03R\bsequence\x12\x16\n" +
+	"\x06digest\x18\x03 \x01(\tR\x06digest\"P\n" +
+	"\x06Commit\x12\x12\n" +
+	"\x04view\x18\x01 \x01(\x04R\x04view\x12\x1a\n" +
+	"\bsequence\x18\x02 \x01(\x03R\bsequence\x12\x16\n" +
+	"\x06digest\x18\x03 \x01(\tR\x06digest\"\xa3\x01\n" +
+	"\n" +
+	"ViewChange\x12\x19\n" +
+	"\bnew_view\x18\x01 \x01(\x04R\anewView\x12#\n" +
+	"\rlast_sequence\x18\x02 \x01(\x03R\flastSequence\x126\n" +
+	"\bprepared\x18\x03 \x03(\v2\x1a.consensus.wire.PrePrepareR\bprepared\x12\x1d\n" +
+	"\n" +
+	"replica_id\x18\x04 \x01(\x05R\treplicaId\"\x9b\x01\n" +
+	"\aNewView\x12\x12\n" +
+	"\x04view\x18\x01 \x01(\x04R\x04view\x12=\n" +
+	"\fview_changes\x18\x02 \x03(\v2\x1a.consensus.wire.ViewChangeR\vviewChanges\x12=\n" +
+	"\fpre_prepares\x18\x03 \x03(\v2\x1a.consensus.wire.PrePrepareR\vprePrepares\"\x1d\n" +
+	"\aRequest\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\tR\x04data\":\n" +
+	"\fPaxosPrepare\x12\x16\n" +
+	"\x06ballot\x18\x01 \x01(\x03R\x06ballot\x12\x12\n" +
+	"\x04slot\x18\x02 \x01(\x03R\x04slot\"\xa1\x01\n" +
+	"\fPaxosPromise\x12\x16\n" +
+	"\x06ballot\x18\x01 \x01(\x03R\x06ballot\x12\x12\n" +
+	"\x04slot\x18\x02 \x01(\x03R\x04slot\x12'\n" +
+	"\x0faccepted_ballot\x18\x03 \x01(\x03R\x0eacceptedBallot\x12<\n" +
+	"\x0eaccepted_value\x18\x04 \x01(\v2\x15.consensus.wire.BlockR\racceptedValue\"f\n" +
+	"\vPaxosAccept\x12\x16\n" +
+	"\x06ballot\x18\x01 \x01(\x03R\x06ballot\x12\x12\n" +
+	"\x04slot\x18\x02 \x01(\x03R\x04slot\x12+\n" +
+	"\x05value\x18\x03 \x01(\v2\x15.consensus.wire.BlockR\x05value\";\n" +
+	"\rPaxosAccepted\x12\x16\n" +
+	"\x06ballot\x18\x01 \x01(\x03R\x06ballot\x12\x12\n" +
+	"\x04slot\x18\x02 \x01(\x03R\x04slot\"<\n" +
+	"\rBlockProposal\x12+\n" +
+	"\x05block\x18\x01 \x01(\v2\x15.consensus.wire.BlockR\x05block\"@\n" +
+	"\fDelegateVote\x12\x14\n" +
+	"\x05voter\x18\x01 \x01(\tR\x05voter\x12\x1a\n" +
+	"\bdelegate\x18\x02 \x01(\tR\bdelegate\"\"\n" +
+	"\fBlockRequest\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\tR\x04hash\"9\n" +
+	"\rHeaderRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\x03R\x04from\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"#\n" +
+	"\aHeaders\x12\x18\n" +
+	"\aheaders\x18\x01 \x03(\fR\aheaders\"E\n" +
+	"\vMerkleRange\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\x03R\x02to\x12\x12\n" +
+	"\x04root\x18\x03 \x01(\fR\x04root\"C\n" +
+	"\fChainSummary\x123\n" +
+	"\x06ranges\x18\x01 \x03(\v2\x1b.consensus.wire.MerkleRangeR\x06ranges\"\x99\x01\n" +
+	"\tStatement\x12\x16\n" +
+	"\x06signer\x18\x01 \x01(\x05R\x06signer\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x16\n" +
+	"\x06height\x18\x03 \x01(\x03R\x06height\x12\x14\n" +
+	"\x05round\x18\x04 \x01(\x04R\x05round\x12\x14\n" +
+	"\x05value\x18\x05 \x01(\tR\x05value\x12\x1c\n" +
+	"\tsignature\x18\x06 \x01(\fR\tsignature\"n\n" +
+	"\bEvidence\x12/\n" +
+	"\x05first\x18\x01 \x01(\v2\x19.consensus.wire.StatementR\x05first\x121\n" +
+	"\x06second\x18\x02 \x01(\v2\x19.consensus.wire.StatementR\x06second\"\xba\x01\n" +
+	"\x0eCheckpointVote\x12\x1c\n" +
+	"\tvalidator\x18\x01 \x01(\x05R\tvalidator\x12#\n" +
+	"\rsource_height\x18\x02 \x01(\x03R\fsourceHeight\x12\x1f\n" +
+	"\vsource_hash\x18\x03 \x01(\tR\n" +
+	"sourceHash\x12#\n" +
+	"\rtarget_height\x18\x04 \x01(\x03R\ftargetHeight\x12\x1f\n" +
+	"\vtarget_hash\x18\x05 \x01(\tR\n" +
+	"targetHash\"\xd0\f\n" +
+	"\bEnvelope\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\x05R\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\x05R\x02to\x12\x1c\n" +
+	"\tsignature\x18\x03 \x01(\fR\tsignature\x12@\n" +
+	"\frequest_vote\x18\n" +
+	" \x01(\v2\x1b.consensus.wire.RequestVoteH\x00R\vrequestVote\x12Y\n" +
+	"\x15request_vote_response\x18\v \x01(\v2#.consensus.wire.RequestVoteResponseH\x00R\x13requestVoteResponse\x12F\n" +
+	"\x0eappend_entries\x18\f \x01(\v2\x1d.consensus.wire.AppendEntriesH\x00R\rappendEntries\x12_\n" +
+	"\x17append_entries_response\x18\r \x01(\v2%.consensus.wire.AppendEntriesResponseH\x00R\x15appendEntriesResponse\x12=\n" +
+	"\vpre_prepare\x18\x14 \x01(\v2\x1a.consensus.wire.PrePrepareH\x00R\n" +
+	"prePrepare\x123\n" +
+	"\aprepare\x18\x15 \x01(\v2\x17.consensus.wire.PrepareH\x00R\aprepare\x120\n" +
+	"\x06commit\x18\x16 \x01(\v2\x16.consensus.wire.CommitH\x00R\x06commit\x12=\n" +
+	"\vview_change\x18\x17 \x01(\v2\x1a.consensus.wire.ViewChangeH\x00R\n" +
+	"viewChange\x124\n" +
+	"\bnew_view\x18\x18 \x01(\v2\x17.consensus.wire.NewViewH\x00R\anewView\x123\n" +
+	"\arequest\x18\x19 \x01(\v2\x17.consensus.wire.RequestH\x00R\arequest\x12C\n" +
+	"\rpaxos_prepare\x18\x1e \x01(\v2\x1c.consensus.wire.PaxosPrepareH\x00R\fpaxosPrepare\x12C\n" +
+	"\rpaxos_promise\x18\x1f \x01(\v2\x1c.consensus.wire.PaxosPromiseH\x00R\fpaxosPromise\x12@\n" +
+	"\fpaxos_accept\x18  \x01(\v2\x1b.consensus.wire.PaxosAcceptH\x00R\vpaxosAccept\x12F\n" +
+	"\x0epaxos_accepted\x18! \x01(\v2\x1d.consensus.wire.PaxosAcceptedH\x00R\rpaxosAccepted\x12F\n" +
+	"\x0eblock_proposal\x18( \x01(\v2\x1d.consensus.wire.BlockProposalH\x00R\rblockProposal\x12C\n" +
+	"\rdelegate_vote\x18) \x01(\v2\x1c.consensus.wire.DelegateVoteH\x00R\fdelegateVote\x12C\n" +
+	"\rblock_request\x18* \x01(\v2\x1c.consensus.wire.BlockRequestH\x00R\fblockRequest\x12F\n" +
+	"\x0eheader_request\x18+ \x01(\v2\x1d.consensus.wire.HeaderRequestH\x00R\rheaderRequest\x123\n" +
+	"\aheaders\x18, \x01(\v2\x17.consensus.wire.HeadersH\x00R\aheaders\x12C\n" +
+	"\rchain_summary\x18- \x01(\v2\x1c.consensus.wire.ChainSummaryH\x00R\fchainSummary\x126\n" +
+	"\bevidence\x182 \x01(\v2\x18.consensus.wire.EvidenceH\x00R\bevidence\x129\n" +
+	"\tstatement\x183 \x01(\v2\x19.consensus.wire.StatementH\x00R\tstatement\x12I\n" +
+	"\x0fcheckpoint_vote\x18< \x01(\v2\x1e.consensus.wire.CheckpointVoteH\x00R\x0echeckpointVoteB\x06\n" +
+	"\x04bodyB\x1fZ\x1dconsensus-algorithms-edu/wireb\x06proto3"
+
+var (
+	file_wire_proto_rawDescOnce sync.Once
+	file_wire_proto_rawDescData []byte
+)
+
+func file_wire_proto_rawDescGZIP() []byte {
+	file_wire_proto_rawDescOnce.Do(func() {
+		file_wire_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_wire_proto_rawDesc), len(file_wire_proto_rawDesc)))
+	})
+	return file_wire_proto_rawDescData
+}
+
+var file_wire_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
+var file_wire_proto_goTypes = []any{
+	(*Block)(nil),                 // 0: consensus.wire.Block
+	(*Chain)(nil),                 // 1: consensus.wire.Chain
+	(*Snapshot)(nil),              // 2: consensus.wire.Snapshot
+	(*Stake)(nil),                 // 3: consensus.wire.Stake
+	(*Member)(nil),                // 4: consensus.wire.Member
+	(*PaxosProposal)(nil),         // 5: consensus.wire.PaxosProposal
+	(*RequestVote)(nil),           // 6: consensus.wire.RequestVote
+	(*RequestVoteResponse)(nil),   // 7: consensus.wire.RequestVoteResponse
+	(*Entry)(nil),                 // 8: consensus.wire.Entry
+	(*AppendEntries)(nil),         // 9: consensus.wire.AppendEntries
+	(*AppendEntriesResponse)(nil), // 10: consensus.wire.AppendEntriesResponse
+	(*PrePrepare)(nil),            // 11: consensus.wire.PrePrepare
+	(*Prepare)(nil),               // 12: consensus.wire.Prepare
+	(*Commit)(nil),                // 13: consensus.wire.Commit
+	(*ViewChange)(nil),            // 14: consensus.wire.ViewChange
+	(*NewView)(nil),               // 15: consensus.wire.NewView
+	(*Request)(nil),               // 16: consensus.wire.Request
+	(*PaxosPrepare)(nil),          // 17: consensus.wire.PaxosPrepare
+	(*PaxosPromise)(nil),          // 18: consensus.wire.PaxosPromise
+	(*PaxosAccept)(nil),           // 19: consensus.wire.PaxosAccept
+	(*PaxosAccepted)(nil),         // 20: consensus.wire.PaxosAccepted
+	(*BlockProposal)(nil),         // 21: consensus.wire.BlockProposal
+	(*DelegateVote)(nil),          // 22: consensus.wire.DelegateVote
+	(*BlockRequest)(nil),          // 23: consensus.wire.BlockRequest
+	(*HeaderRequest)(nil),         // 24: consensus.wire.HeaderRequest
+	(*Headers)(nil),               // 25: consensus.wire.Headers
+	(*MerkleRange)(nil),           // 26: consensus.wire.MerkleRange
+	(*ChainSummary)(nil),          // 27: consensus.wire.ChainSummary
+	(*Statement)(nil),             // 28: consensus.wire.Statement
+	(*Evidence)(nil),              // 29: consensus.wire.Evidence
+	(*CheckpointVote)(nil),        // 30: consensus.wire.CheckpointVote
+	(*Envelope)(nil),              // 31: consensus.wire.Envelope
+	nil,                           // 32: consensus.wire.Snapshot.VotesEntry
+}
+var file_wire_proto_depIdxs = []int32{
+	0,  // 0: consensus.wire.Chain.blocks:type_name -> consensus.wire.Block
+	1,  // 1: consensus.wire.Snapshot.chain:type_name -> consensus.wire.Chain
+	3,  // 2: consensus.wire.Snapshot.stakes:type_name -> consensus.wire.Stake
+	32, // 3: consensus.wire.Snapshot.votes:type_name -> consensus.wire.Snapshot.VotesEntry
+	4,  // 4: consensus.wire.Snapshot.members:type_name -> consensus.wire.Member
+	5,  // 5: consensus.wire.Member.proposals:type_name -> consensus.wire.PaxosProposal
+	0,  // 6: consensus.wire.Entry.block:type_name -> consensus.wire.Block
+	8,  // 7: consensus.wire.AppendEntries.entries:type_name -> consensus.wire.Entry
+	0,  // 8: consensus.wire.PrePrepare.block:type_name -> consensus.wire.Block
+	11, // 9: consensus.wire.ViewChange.prepared:type_name -> consensus.wire.PrePrepare
+	14, // 10: consensus.wire.NewView.view_changes:type_name -> consensus.wire.ViewChange
+	11, // 11: consensus.wire.NewView.pre_prepares:type_name -> consensus.wire.PrePrepare
+	0,  // 12: consensus.wire.PaxosPromise.accepted_value:type_name -> consensus.wire.Block
+	0,  // 13: consensus.wire.PaxosAccept.value:type_name -> consensus.wire.Block
+	0,  // 14: consensus.wire.BlockProposal.block:type_name -> consensus.wire.Block
+	26, // 15: consensus.wire.ChainSummary.ranges:type_name -> consensus.wire.MerkleRange
+	28, // 16: consensus.wire.Evidence.first:type_name -> consensus.wire.Statement
+	28, // 17: consensus.wire.Evidence.second:type_name -> consensus.wire.Statement
+	6,  // 18: consensus.wire.Envelope.request_vote:type_name -> consensus.wire.RequestVote
+	7,  // 19: consensus.wire.Envelope.request_vote_response:type_name -> consensus.wire.RequestVoteResponse
+	9,  // 20: consensus.wire.Envelope.append_entries:type_name -> consensus.wire.AppendEntries
+	10, // 21: consensus.wire.Envelope.append_entries_response:type_name -> consensus.wire.AppendEntriesResponse
+	11, // 22: consensus.wire.Envelope.pre_prepare:type_name -> consensus.wire.PrePrepare
+	12, // 23: consensus.wire.Envelope.prepare:type_name -> consensus.wire.Prepare
+	13, // 24: consensus.wire.Envelope.commit:type_name -> consensus.wire.Commit
+	14, // 25: consensus.wire.Envelope.view_change:type_name -> consensus.wire.ViewChange
+	15, // 26: consensus.wire.Envelope.new_view:type_name -> consensus.wire.NewView
+	16, // 27: consensus.wire.Envelope.request:type_name -> consensus.wire.Request
+	17, // 28: consensus.wire.Envelope.paxos_prepare:type_name -> consensus.wire.PaxosPrepare
+	18, // 29: consensus.wire.Envelope.paxos_promise:type_name -> consensus.wire.PaxosPromise
+	19, // 30: consensus.wire.Envelope.paxos_accept:type_name -> consensus.wire.PaxosAccept
+	20, // 31: consensus.wire.Envelope.paxos_accepted:type_name -> consensus.wire.PaxosAccepted
+	21, // 32: consensus.wire.Envelope.block_proposal:type_name -> consensus.wire.BlockProposal
+	22, // 33: consensus.wire.Envelope.delegate_vote:type_name -> consensus.wire.DelegateVote
+	23, // 34: consensus.wire.Envelope.block_request:type_name -> consensus.wire.BlockRequest
+	24, // 35: consensus.wire.Envelope.header_request:type_name -> consensus.wire.HeaderRequest
+	25, // 36: consensus.wire.Envelope.headers:type_name -> consensus.wire.Headers
+	27, // 37: consensus.wire.Envelope.chain_summary:type_name -> consensus.wire.ChainSummary
+	29, // 38: consensus.wire.Envelope.evidence:type_name -> consensus.wire.Evidence
+	28, // 39: consensus.wire.Envelope.statement:type_name -> consensus.wire.Statement
+	30, // 40: consensus.wire.Envelope.checkpoint_vote:type_name -> consensus.wire.CheckpointVote
+	41, // [41:41] is the sub-list for method output_type
+	41, // [41:41] is the sub-list for method input_type
+	41, // [41:41] is the sub-list for extension type_name
+	41, // [41:41] is the sub-list for extension extendee
+	0,  // [0:41] is the sub-list for field type_name
+}
+
+func init() { file_wire_proto_init() }
+func file_wire_proto_init() {
+	if File_wire_proto != nil {
+		return
+	}
+	file_wire_proto_msgTypes[31].OneofWrappers = []any{
+		(*Envelope_RequestVote)(nil),
+		(*Envelope_RequestVoteResponse)(nil),
+		(*Envelope_AppendEntries)(nil),
+		(*Envelope_AppendEntriesResponse)(nil),
+		(*Envelope_PrePrepare)(nil),
+		(*Envelope_Prepare)(nil),
+		(*Envelope_Commit)(nil),
+		(*Envelope_ViewChange)(nil),
+		(*Envelope_NewView)(nil),
+		(*Envelope_Request)(nil),
+		(*Envelope_PaxosPrepare)(nil),
+		(*Envelope_PaxosPromise)(nil),
+		(*Envelope_PaxosAccept)(nil),
+		(*Envelope_PaxosAccepted)(nil),
+		(*Envelope_BlockProposal)(nil),
+		(*Envelope_DelegateVote)(nil),
+		(*Envelope_BlockRequest)(nil),
+		(*Envelope_HeaderRequest)(nil),
+		(*Envelope_Headers)(nil),
+		(*Envelope_ChainSummary)(nil),
+		(*Envelope_Evidence)(nil),
+		(*Envelope_Statement)(nil),
+		(*Envelope_CheckpointVote)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_wire_proto_rawDesc), len(file_wire_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   33,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_wire_proto_goTypes,
+		DependencyIndexes: file_wire_proto_depIdxs,
+		MessageInfos:      file_wire_proto_msgTypes,
+	}.Build()
+	File_wire_proto = out.File
+	file_wire_proto_goTypes = nil
+	file_wire_proto_depIdxs = nil
+}